@@ -0,0 +1,80 @@
+// Command goboot-bench measures how long the container takes to wire synthetic
+// component graphs, from registration to started components:
+//
+//	goboot-bench -components 100,1000,5000 -deps 4 -variables 1000
+//
+// Each size is run with testing.Benchmark; logging is discarded so that only the
+// container's own work is measured. Use -cpuprofile to find the hot paths.
+package main
+
+import (
+	"context"
+	"flag"
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"runtime/pprof"
+	"strconv"
+	"strings"
+	"testing"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/containertest"
+)
+
+func main() {
+	sizes := flag.String("components", "100,1000", "comma-separated component counts")
+	deps := flag.Int("deps", 4, "average dependencies per component")
+	variables := flag.Int("variables", 1000, "configuration variables")
+	cpuProfile := flag.String("cpuprofile", "", "write a CPU profile to this file")
+	flag.Parse()
+
+	if *cpuProfile != "" {
+		file, err := os.Create(*cpuProfile)
+		if err != nil {
+			fail(err)
+		}
+		defer file.Close()
+		if err := pprof.StartCPUProfile(file); err != nil {
+			fail(err)
+		}
+		defer pprof.StopCPUProfile()
+	}
+
+	for _, size := range strings.Split(*sizes, ",") {
+		components, err := strconv.Atoi(strings.TrimSpace(size))
+		if err != nil {
+			fail(fmt.Errorf("invalid component count %q", size))
+		}
+		graph := containertest.SyntheticGraph{Components: components, AvgDeps: *deps, Variables: *variables, Seed: 1}
+		result := testing.Benchmark(func(b *testing.B) {
+			benchmarkStartup(b, graph)
+		})
+		if result.N == 0 {
+			fail(fmt.Errorf("benchmark with %d components failed", components))
+		}
+		fmt.Printf("components=%d deps=%d variables=%d\t%s\t%s\n", components, *deps, *variables, result, result.MemString())
+	}
+}
+
+// benchmarkStartup creates, starts and shuts down a container for the graph
+func benchmarkStartup(b *testing.B, graph containertest.SyntheticGraph) {
+	logger := slog.New(slog.NewTextHandler(io.Discard, nil))
+	setup := graph.Setup()
+	b.ReportAllocs()
+	for i := 0; i < b.N; i++ {
+		cfg := containertest.NewConfig(containertest.WithoutDefaultLoaders(), containertest.WithLoaders(graph.Loader()))
+		cfg.Logger = logger
+		_, shutdown, err := container.New(context.Background(), cfg, setup)
+		if err != nil {
+			b.Fatal(err)
+		}
+		shutdown()
+	}
+}
+
+func fail(err error) {
+	fmt.Fprintln(os.Stderr, "goboot-bench:", err)
+	os.Exit(1)
+}
@@ -0,0 +1,45 @@
+// Command goboot-trace converts lifecycle events written by a
+// container.JSONLinesEventWriter to the Chrome trace event format:
+//
+//	goboot-trace events.jsonl > trace.json
+//
+// The events are read from stdin if no file is given. Open the result in
+// chrome://tracing or https://ui.perfetto.dev.
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+func main() {
+	if err := run(os.Args[1:], os.Stdin, os.Stdout); err != nil {
+		fmt.Fprintln(os.Stderr, "goboot-trace:", err)
+		os.Exit(1)
+	}
+}
+
+func run(args []string, stdin io.Reader, stdout io.Writer) error {
+	input := stdin
+	switch len(args) {
+	case 0:
+	case 1:
+		file, err := os.Open(args[0])
+		if err != nil {
+			return err
+		}
+		defer file.Close()
+		input = file
+	default:
+		return fmt.Errorf("usage: goboot-trace [events.jsonl]")
+	}
+
+	events, err := container.ReadLifecycleEvents(input)
+	if err != nil {
+		return err
+	}
+	return container.WriteChromeTrace(stdout, events)
+}
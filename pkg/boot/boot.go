@@ -2,9 +2,11 @@ package boot
 
 import (
 	"context"
+	"fmt"
 	"log/slog"
 	"os"
 	"os/signal"
+	"sync"
 	"syscall"
 
 	"github.com/01fortes/goboot/pkg/container"
@@ -14,24 +16,90 @@ import (
 type Application struct {
 	ctx               context.Context
 	cancel            context.CancelFunc
+	setup             func(container.ContextBuilder)
+	options           []Option
 	container         container.ApplicationContext
-	shutdown          func()
+	shutdown          func(ctx context.Context)
+	platform          *platformNotifier
+	readiness         *readinessIndicator
 	autoConfigEnabled bool
+	restartOnHangup   bool
+	reloadOnHangup    bool
+	// terminating is set once Shutdown began, while it waits for the pre-stop delay
+	terminating  bool
+	shutdownOnce sync.Once
+	mu           sync.Mutex
 }
 
 // Run starts the application and blocks until shutdown
 func (a *Application) Run() {
-	// Wait for termination signal
-	<-a.ctx.Done()
+	var hangup chan os.Signal
+	if a.restartOnHangup || a.reloadOnHangup {
+		hangup = make(chan os.Signal, 1)
+		signal.Notify(hangup, syscall.SIGHUP)
+		defer signal.Stop(hangup)
+	}
 
-	// Perform cleanup
-	a.Shutdown()
+	// Wait for termination signal, restarting on SIGHUP if enabled
+	for {
+		select {
+		case <-a.ctx.Done():
+			// Perform cleanup
+			a.Shutdown()
+			return
+		case <-hangup:
+			if a.reloadOnHangup {
+				slog.Info("Received SIGHUP, reloading variables")
+				if changed, err := a.ReloadVariables(); err != nil {
+					slog.Error("Variables reload failed", "error", err)
+				} else {
+					slog.Info("Variables reloaded", "changed", changed)
+				}
+				continue
+			}
+			slog.Info("Received SIGHUP, restarting application")
+			if err := a.Restart(); err != nil {
+				slog.Error("Application restart failed", "error", err)
+				a.Shutdown()
+				return
+			}
+		}
+	}
 }
 
-// Shutdown gracefully stops the application
+// Shutdown gracefully stops the application. In Kubernetes mode readiness reports
+// DOWN first, then the pre-stop delay elapses and components get the rest of the
+// termination grace period to stop. The application isn't locked during the
+// pre-stop delay, so variables can still be reloaded. Concurrent calls wait for
+// the first one to complete.
 func (a *Application) Shutdown() {
+	a.shutdownOnce.Do(a.terminate)
+}
+
+// terminate runs the shutdown sequence of Shutdown once
+func (a *Application) terminate() {
+	a.mu.Lock()
+	a.terminating = true
+	platform := a.platform
+	a.platform = nil
+	a.readiness.markDown()
+	if platform != nil {
+		platform.Stopping()
+	}
+	a.mu.Unlock()
+
+	stopCtx := a.ctx
+	if platform != nil {
+		var cancel context.CancelFunc
+		stopCtx, cancel = platform.Terminating(a.ctx)
+		defer cancel()
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	if a.shutdown != nil {
-		a.shutdown()
+		a.shutdown(stopCtx)
 		a.shutdown = nil
 	}
 
@@ -41,37 +109,201 @@ func (a *Application) Shutdown() {
 	}
 }
 
-// GetContainer returns the application container
+// Restart stops all components, then re-runs the setup block, variable loaders and
+// starters, re-discovers dependencies and starts the components again without
+// exiting the process. Components obtained from the previous container must be
+// looked up again via GetContainer. If the new container fails to start, the
+// application is shut down and GetContainer returns nil.
+func (a *Application) Restart() error {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancel == nil || a.terminating {
+		return fmt.Errorf("application is shut down")
+	}
+
+	slog.Info("Restarting application")
+	if a.platform != nil {
+		a.platform.Reloading()
+	}
+
+	// Stop components of the current container in reverse dependency order
+	if a.shutdown != nil {
+		a.shutdown(a.ctx)
+		a.shutdown = nil
+	}
+
+	cfg := newConfig(a.options)
+	cont, shutdown, err := startContainer(a.ctx, a.setup, cfg)
+	if err != nil {
+		// The old container is stopped, so the application is shut down
+		a.readiness.markDown()
+		if a.platform != nil {
+			a.platform.Stopping()
+			a.platform = nil
+		}
+		a.container = nil
+		a.cancel()
+		a.cancel = nil
+		return fmt.Errorf("restart failed: %w", err)
+	}
+
+	a.container = cont
+	a.shutdown = shutdown
+
+	// Configuration may have changed, so rebuild the notifier from the new container
+	a.platform = newPlatformNotifier(cont, cfg.Clock, slog.Default())
+	a.platform.Ready(a.ctx)
+
+	slog.Info("Application restarted")
+	return nil
+}
+
+// ReloadVariables reloads the variables of the running container without restarting
+// its components; see container.VariableReloader
+func (a *Application) ReloadVariables() ([]string, error) {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	if a.cancel == nil {
+		return nil, fmt.Errorf("application is shut down")
+	}
+	return a.container.ReloadVariables()
+}
+
+// GetContainer returns the application container, nil once a restart failed
 func (a *Application) GetContainer() container.ApplicationContext {
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
 	return a.container
 }
 
+// EnableRestartOnHangup makes Run restart the application when SIGHUP is received
+func (a *Application) EnableRestartOnHangup() *Application {
+	a.restartOnHangup = true
+	return a
+}
+
+// EnableReloadOnHangup makes Run reload the variables when SIGHUP is received,
+// keeping the components running; it takes precedence over EnableRestartOnHangup
+func (a *Application) EnableReloadOnHangup() *Application {
+	a.reloadOnHangup = true
+	return a
+}
+
 // DisableAutoConfiguration disables auto-configuration
 func (a *Application) DisableAutoConfiguration() *Application {
 	a.autoConfigEnabled = false
 	return a
 }
 
-// New creates a new application with the given configuration
-func New(block func(container.ContextBuilder)) *Application {
+// Option customizes the container configuration of an application
+type Option func(*container.Config)
+
+// WithProfiles activates profiles in addition to the GO_BOOT_ACTIVE_PROFILES
+// environment variable and goboot.profiles.active from application.yml
+// (see container.ApplicationContext.GetActiveProfiles for the precedence)
+func WithProfiles(profiles ...string) Option {
+	return func(cfg *container.Config) {
+		cfg.Profiles = append(cfg.Profiles, profiles...)
+	}
+}
+
+// WithArgs loads variables from command-line arguments, usually os.Args[1:]. With
+// --config=<path> (or --config=- for stdin) that file replaces the configuration
+// files otherwise loaded; environment variables override it and --name=value
+// arguments override both.
+func WithArgs(args []string) Option {
+	var configFile container.VariableLoader
+	if path := container.ConfigFileFromArgs(args); path != "" {
+		// Created once, so that a restart reuses the configuration read from stdin
+		configFile = &container.ConfigFileLoader{Path: path}
+	}
+
+	return func(cfg *container.Config) {
+		var loaders []container.VariableLoader
+		if configFile != nil {
+			loaders = append(loaders, configFile)
+		} else {
+			for _, loader := range cfg.DefaultVariableLoaders {
+				switch loader.(type) {
+				case container.EnvVariableLoader, *container.EnvVariableLoader:
+					// Added again below, overriding the files
+				default:
+					loaders = append(loaders, loader)
+				}
+			}
+		}
+		cfg.DefaultVariableLoaders = append(loaders, container.EnvVariableLoader{}, container.CommandLineLoader{Args: args})
+	}
+}
+
+// New creates a new application with the given setup block and options
+func New(block func(container.ContextBuilder), options ...Option) *Application {
 	// Create a context that can be cancelled
 	ctx, cancel := signal.NotifyContext(context.Background(), os.Interrupt, syscall.SIGTERM)
 
 	// Create container setup function with auto-configuration
+	readiness := &readinessIndicator{}
 	setupFunc := func(builder container.ContextBuilder) {
+		builder.RegisterStarter(kubernetesStarter(readiness))
+
 		// Call user setup function
 		block(builder)
 	}
 
 	// Start the container
 	slog.Info("Starting application")
-	cont, shutdown := container.Start(ctx, setupFunc)
+	cfg := newConfig(options)
+	cont, shutdown, err := startContainer(ctx, setupFunc, cfg)
+	if err != nil {
+		panic(err)
+	}
+
+	// Tell the hosting platform (systemd, Kubernetes) that we are ready
+	platform := newPlatformNotifier(cont, cfg.Clock, slog.Default())
+	platform.Ready(ctx)
 
 	return &Application{
 		ctx:               ctx,
 		cancel:            cancel,
+		setup:             setupFunc,
+		options:           options,
 		container:         cont,
 		shutdown:          shutdown,
+		platform:          platform,
+		readiness:         readiness,
 		autoConfigEnabled: true, // Enabled by default
 	}
 }
+
+// startContainer starts a container whose components run until the returned
+// shutdown function is called, so that a restart doesn't leak background goroutines.
+// Components keep running after ctx is cancelled until they are stopped, with the
+// context passed to the shutdown function.
+func startContainer(ctx context.Context, setup func(container.ContextBuilder), cfg *container.Config) (container.ApplicationContext, func(ctx context.Context), error) {
+	runCtx, cancel := context.WithCancel(context.WithoutCancel(ctx))
+
+	cont, shutdown, err := container.New(runCtx, cfg, setup)
+	if err != nil {
+		cancel()
+		return nil, nil, err
+	}
+
+	return cont, func(stopCtx context.Context) {
+		if err := container.Shutdown(stopCtx, cont); err != nil {
+			shutdown()
+		}
+		cancel()
+	}, nil
+}
+
+// newConfig returns the default container configuration with the options applied
+func newConfig(options []Option) *container.Config {
+	cfg := container.DefaultConfig()
+	for _, option := range options {
+		option(cfg)
+	}
+	return cfg
+}
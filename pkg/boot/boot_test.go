@@ -0,0 +1,62 @@
+package boot
+
+import (
+	"context"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/containertest"
+)
+
+// countingStop counts how many times it is stopped
+type countingStop struct {
+	container.ComponentBase
+	stops atomic.Int32
+}
+
+func (c *countingStop) Start(context.Context) {}
+
+func (c *countingStop) Stop(context.Context) {
+	c.stops.Add(1)
+}
+
+func TestConcurrentShutdownWaitsForTheFirst(t *testing.T) {
+	clock := containertest.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	component := &countingStop{ComponentBase: container.NewComponentBase("component")}
+	app := New(func(builder container.ContextBuilder) {
+		builder.RegisterComponent(component)
+	}, func(cfg *container.Config) {
+		cfg.Clock = clock
+		cfg.DefaultVariableLoaders = []container.VariableLoader{container.MapVariableLoader{Variables: map[string]interface{}{
+			PropertyKubernetesEnabled: "true",
+			PropertyPreStopDelay:      "10s",
+		}}}
+	})
+
+	first := make(chan struct{})
+	go func() {
+		app.Shutdown()
+		close(first)
+	}()
+	clock.BlockUntil(1)
+
+	second := make(chan struct{})
+	go func() {
+		app.Shutdown()
+		close(second)
+	}()
+	select {
+	case <-second:
+		t.Fatal("second Shutdown returned during the pre-stop delay")
+	case <-time.After(50 * time.Millisecond):
+	}
+
+	clock.Advance(10 * time.Second)
+	<-first
+	<-second
+	if stops := component.stops.Load(); stops != 1 {
+		t.Errorf("component stopped %d times, want 1", stops)
+	}
+}
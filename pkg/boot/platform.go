@@ -0,0 +1,270 @@
+package boot
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Platform notification properties
+const (
+	// PropertySystemdEnabled enables sd_notify messages (READY=1, WATCHDOG=1, STOPPING=1)
+	PropertySystemdEnabled = "goboot.platform.systemd.enabled"
+	// PropertyReadinessFile is the path of a file created once the application is ready
+	// and removed on shutdown, suitable for Kubernetes exec readiness probes
+	PropertyReadinessFile = "goboot.platform.readiness-file"
+	// PropertyKubernetesEnabled makes shutdown follow the pod termination: the
+	// readiness health indicator reports DOWN as soon as SIGTERM is received, the
+	// pre-stop delay elapses and only then are the components stopped
+	PropertyKubernetesEnabled = "goboot.platform.kubernetes.enabled"
+	// PropertyPreStopDelay is how long the application keeps serving after readiness
+	// went DOWN, so that the endpoints stop routing traffic to the pod (0 by default).
+	// A second SIGTERM cuts it short; startup fails if it leaves the components less
+	// than a second of the grace period to stop.
+	PropertyPreStopDelay = "goboot.platform.kubernetes.pre-stop-delay"
+	// PropertyTerminationGracePeriod is the pod's terminationGracePeriodSeconds; the
+	// pre-stop delay and stopping the components fit in it (30s by default)
+	PropertyTerminationGracePeriod = "goboot.platform.kubernetes.termination-grace-period"
+)
+
+// Kubernetes defaults
+const (
+	defaultTerminationGracePeriod = 30 * time.Second
+	// terminationMargin is kept from the grace period for exiting before SIGKILL
+	terminationMargin = time.Second
+	// minimumStopBudget is the least time the components must get to stop after
+	// the pre-stop delay
+	minimumStopBudget = time.Second
+)
+
+// platformNotifier reports application readiness to the hosting platform
+type platformNotifier struct {
+	systemd       bool
+	socket        string
+	readinessFile string
+	watchdog      time.Duration
+	stopWatchdog  context.CancelFunc
+	kubernetes    bool
+	preStopDelay  time.Duration
+	gracePeriod   time.Duration
+	clock         container.Clock
+	app           container.ApplicationContext
+	logger        *slog.Logger
+}
+
+func newPlatformNotifier(ctx container.ApplicationContext, clock container.Clock, logger *slog.Logger) *platformNotifier {
+	if clock == nil {
+		clock = container.RealClock()
+	}
+
+	vars := container.NewVariableHelper(ctx)
+
+	n := &platformNotifier{
+		systemd:       vars.GetBool(PropertySystemdEnabled, false),
+		socket:        os.Getenv("NOTIFY_SOCKET"),
+		readinessFile: vars.GetString(PropertyReadinessFile, ""),
+		kubernetes:    vars.GetBool(PropertyKubernetesEnabled, false),
+		preStopDelay:  durationVariable(ctx, PropertyPreStopDelay, 0, logger),
+		gracePeriod:   durationVariable(ctx, PropertyTerminationGracePeriod, defaultTerminationGracePeriod, logger),
+		clock:         clock,
+		app:           ctx,
+		logger:        logger,
+	}
+
+	// systemd passes the watchdog interval in microseconds
+	if usec, err := strconv.ParseInt(os.Getenv("WATCHDOG_USEC"), 10, 64); err == nil && usec > 0 {
+		n.watchdog = time.Duration(usec) * time.Microsecond
+	}
+
+	return n
+}
+
+// Ready notifies the platform that all components have started
+func (n *platformNotifier) Ready(ctx context.Context) {
+	if n.readinessFile != "" {
+		if err := os.WriteFile(n.readinessFile, []byte(strconv.Itoa(os.Getpid())), 0o644); err != nil {
+			n.logger.Error("Failed to write readiness file", "path", n.readinessFile, "error", err)
+		} else {
+			n.logger.Info("Readiness file written", "path", n.readinessFile)
+		}
+	}
+
+	if !n.systemdActive() {
+		return
+	}
+
+	n.notify("READY=1")
+
+	if n.watchdog > 0 {
+		watchdogCtx, cancel := context.WithCancel(ctx)
+		n.stopWatchdog = cancel
+		go n.runWatchdog(watchdogCtx)
+	}
+}
+
+// Stopping notifies the platform that the application is shutting down
+func (n *platformNotifier) Stopping() {
+	n.notReady("STOPPING=1")
+}
+
+// Reloading notifies the platform that the application is restarting;
+// Ready must be called again once the restart completes
+func (n *platformNotifier) Reloading() {
+	n.notReady("RELOADING=1")
+}
+
+// Terminating waits for the pre-stop delay in Kubernetes mode and returns the
+// context for stopping the components, expiring with the rest of the grace period.
+// A second SIGINT or SIGTERM cuts the delay short. Otherwise it returns ctx.
+func (n *platformNotifier) Terminating(ctx context.Context) (context.Context, context.CancelFunc) {
+	if !n.kubernetes {
+		return ctx, func() {}
+	}
+
+	start := n.clock.Now()
+	if n.preStopDelay > 0 {
+		n.logger.Info("Waiting for endpoints to stop routing traffic", "delay", n.preStopDelay.String())
+		n.waitPreStop()
+	}
+
+	waited := n.clock.Now().Sub(start)
+	budget := stopBudget(waited, n.gracePeriod)
+	if budget <= 0 {
+		n.logger.Warn("Pre-stop delay leaves no time to stop components within the termination grace period",
+			"pre_stop_delay", waited.String(),
+			"grace_period", n.gracePeriod.String())
+		budget = 0
+	}
+	n.logger.Info("Stopping components within the termination grace period", "budget", budget.String())
+	return context.WithTimeout(context.WithoutCancel(ctx), budget)
+}
+
+// waitPreStop waits for the pre-stop delay or a second termination signal
+func (n *platformNotifier) waitPreStop() {
+	signals := make(chan os.Signal, 1)
+	signal.Notify(signals, os.Interrupt, syscall.SIGTERM)
+	defer signal.Stop(signals)
+
+	select {
+	case <-n.clock.After(n.preStopDelay):
+	case sig := <-signals:
+		n.logger.Warn("Received second termination signal, skipping the rest of the pre-stop delay", "signal", sig.String())
+	}
+}
+
+// stopBudget returns the time left in the grace period to stop the components
+// after the pre-stop delay
+func stopBudget(preStopDelay, gracePeriod time.Duration) time.Duration {
+	return gracePeriod - preStopDelay - terminationMargin
+}
+
+// checkStopBudget refuses a pre-stop delay that leaves the components less than
+// minimumStopBudget to stop within the termination grace period
+func checkStopBudget(ctx container.ApplicationContext, logger *slog.Logger) error {
+	preStopDelay := durationVariable(ctx, PropertyPreStopDelay, 0, logger)
+	gracePeriod := durationVariable(ctx, PropertyTerminationGracePeriod, defaultTerminationGracePeriod, logger)
+	if budget := stopBudget(preStopDelay, gracePeriod); budget < minimumStopBudget {
+		return container.ConfigurationError(fmt.Sprintf(
+			"%s of %s leaves %s to stop the components within the %s of %s, at least %s is needed",
+			PropertyPreStopDelay, preStopDelay, budget, PropertyTerminationGracePeriod, gracePeriod, minimumStopBudget), nil)
+	}
+	return nil
+}
+
+func (n *platformNotifier) notReady(state string) {
+	if n.stopWatchdog != nil {
+		n.stopWatchdog()
+		n.stopWatchdog = nil
+	}
+
+	if n.systemdActive() {
+		n.notify(state)
+	}
+
+	if n.readinessFile != "" {
+		if err := os.Remove(n.readinessFile); err != nil && !os.IsNotExist(err) {
+			n.logger.Error("Failed to remove readiness file", "path", n.readinessFile, "error", err)
+		}
+	}
+}
+
+// runWatchdog pings systemd at half the configured watchdog interval while the
+// application is healthy, so systemd restarts it once it stays down too long
+func (n *platformNotifier) runWatchdog(ctx context.Context) {
+	ticker := n.clock.NewTicker(n.watchdog / 2)
+	defer ticker.Stop()
+
+	healthy := true
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C():
+			report := container.AggregateHealth(ctx, n.app)
+			if report.Status == container.HealthDown {
+				if healthy {
+					n.logger.Warn("Application is unhealthy, withholding systemd watchdog pings")
+					healthy = false
+				}
+				continue
+			}
+			if !healthy {
+				n.logger.Info("Application is healthy again, resuming systemd watchdog pings")
+				healthy = true
+			}
+			n.notify("WATCHDOG=1")
+		}
+	}
+}
+
+func (n *platformNotifier) systemdActive() bool {
+	return n.systemd && n.socket != ""
+}
+
+// notify sends a single sd_notify state message over the NOTIFY_SOCKET datagram socket
+func (n *platformNotifier) notify(state string) {
+	if err := sdNotify(n.socket, state); err != nil {
+		n.logger.Warn("Failed to send systemd notification", "state", state, "error", err)
+		return
+	}
+	n.logger.Debug("Sent systemd notification", "state", state)
+}
+
+func sdNotify(socket, state string) error {
+	// Abstract namespace sockets are prefixed with '@'
+	addr := &net.UnixAddr{Name: socket, Net: "unixgram"}
+	if socket[0] == '@' {
+		addr.Name = "\x00" + socket[1:]
+	}
+
+	conn, err := net.DialUnix(addr.Net, nil, addr)
+	if err != nil {
+		return fmt.Errorf("dial notify socket: %w", err)
+	}
+	defer conn.Close()
+
+	_, err = conn.Write([]byte(state))
+	return err
+}
+
+// durationVariable reads a duration variable, logging an invalid value and using
+// defaultValue instead
+func durationVariable(ctx container.ApplicationContext, name string, defaultValue time.Duration, logger *slog.Logger) time.Duration {
+	if !ctx.HasVariable(name) {
+		return defaultValue
+	}
+	var d time.Duration
+	if err := ctx.GetVariableAs(name, &d); err != nil {
+		logger.Error("Invalid duration", "variable", name, "error", err)
+		return defaultValue
+	}
+	return d
+}
@@ -0,0 +1,133 @@
+package boot
+
+import (
+	"bytes"
+	"context"
+	"io"
+	"log/slog"
+	"net"
+	"path/filepath"
+	"strings"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/containertest"
+)
+
+// switchableHealth reports HealthDown while down is set, and the status of
+// each check on checked
+type switchableHealth struct {
+	container.ComponentBase
+	down    atomic.Bool
+	checked chan container.HealthStatus
+}
+
+func (h *switchableHealth) CheckHealth(context.Context) container.Health {
+	status := container.HealthUp
+	if h.down.Load() {
+		status = container.HealthDown
+	}
+	h.checked <- status
+	return container.Health{Status: status}
+}
+
+// listenNotifySocket returns a datagram socket standing in for systemd's NOTIFY_SOCKET
+func listenNotifySocket(t *testing.T) (*net.UnixConn, string) {
+	t.Helper()
+	path := filepath.Join(t.TempDir(), "notify.sock")
+	conn, err := net.ListenUnixgram("unixgram", &net.UnixAddr{Name: path, Net: "unixgram"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { conn.Close() })
+	return conn, path
+}
+
+// receiveNotification returns the next notification, or "" if none arrives within wait
+func receiveNotification(t *testing.T, conn *net.UnixConn, wait time.Duration) string {
+	t.Helper()
+	buf := make([]byte, 64)
+	conn.SetReadDeadline(time.Now().Add(wait))
+	n, err := conn.Read(buf)
+	if err != nil {
+		return ""
+	}
+	return string(buf[:n])
+}
+
+func TestWatchdogWithheldWhileUnhealthy(t *testing.T) {
+	health := &switchableHealth{ComponentBase: container.NewComponentBase("health"), checked: make(chan container.HealthStatus, 1)}
+	app, stop, err := container.New(context.Background(), containertest.NewConfig(), func(builder container.ContextBuilder) {
+		builder.RegisterComponent(health)
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer stop()
+
+	conn, socket := listenNotifySocket(t)
+	clock := containertest.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	n := newPlatformNotifier(app, clock, slog.New(slog.NewTextHandler(io.Discard, nil)))
+	n.systemd, n.socket, n.watchdog = true, socket, 10*time.Second
+
+	n.Ready(context.Background())
+	defer n.Stopping()
+	if got := receiveNotification(t, conn, time.Second); got != "READY=1" {
+		t.Fatalf("notification = %q, want READY=1", got)
+	}
+	clock.BlockUntil(1)
+
+	clock.Advance(5 * time.Second)
+	if got := receiveNotification(t, conn, time.Second); got != "WATCHDOG=1" {
+		t.Fatalf("notification while healthy = %q, want WATCHDOG=1", got)
+	}
+
+	<-health.checked
+
+	health.down.Store(true)
+	clock.Advance(5 * time.Second)
+	if status := <-health.checked; status != container.HealthDown {
+		t.Fatalf("health check = %s, want DOWN", status)
+	}
+	if got := receiveNotification(t, conn, 100*time.Millisecond); got != "" {
+		t.Fatalf("notification while unhealthy = %q, want none", got)
+	}
+
+	health.down.Store(false)
+	clock.Advance(5 * time.Second)
+	<-health.checked
+	if got := receiveNotification(t, conn, time.Second); got != "WATCHDOG=1" {
+		t.Errorf("notification once healthy again = %q, want WATCHDOG=1", got)
+	}
+}
+
+func TestTerminatingWithoutBudgetLeft(t *testing.T) {
+	var logs bytes.Buffer
+	clock := containertest.NewFakeClock(time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC))
+	n := &platformNotifier{
+		kubernetes:   true,
+		preStopDelay: 40 * time.Second,
+		gracePeriod:  30 * time.Second,
+		clock:        clock,
+		logger:       slog.New(slog.NewTextHandler(&logs, nil)),
+	}
+
+	stopped := make(chan context.Context)
+	go func() {
+		stopCtx, cancel := n.Terminating(context.Background())
+		defer cancel()
+		stopped <- stopCtx
+	}()
+	clock.BlockUntil(1)
+	clock.Advance(40 * time.Second)
+
+	stopCtx := <-stopped
+	if stopCtx.Err() == nil {
+		t.Error("stop context is live after the pre-stop delay used up the grace period")
+	}
+	if !strings.Contains(logs.String(), "level=WARN") || !strings.Contains(logs.String(), "budget=0s") {
+		t.Errorf("logs = %q, want a warning and a budget of 0s", logs.String())
+	}
+}
@@ -0,0 +1,60 @@
+package boot
+
+import (
+	"context"
+	"log/slog"
+	"sync/atomic"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// readinessIndicator is a health indicator reporting DOWN once the application
+// began shutting down, so that readiness probes fail while the pod is removed from
+// its endpoints
+type readinessIndicator struct {
+	down atomic.Bool
+}
+
+// Name returns the component name
+func (r *readinessIndicator) Name() string {
+	return "readiness"
+}
+
+// Init is a no-op
+func (r *readinessIndicator) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// CheckHealth reports UP until shutdown begins
+func (r *readinessIndicator) CheckHealth(context.Context) container.Health {
+	if r.down.Load() {
+		return container.Health{Status: container.HealthDown, Details: map[string]interface{}{"reason": "shutting down"}}
+	}
+	return container.Health{Status: container.HealthUp}
+}
+
+// markDown makes the indicator report DOWN
+func (r *readinessIndicator) markDown() {
+	if r != nil {
+		r.down.Store(true)
+	}
+}
+
+// kubernetesStarter registers the readiness indicator in Kubernetes mode
+func kubernetesStarter(readiness *readinessIndicator) container.Starter {
+	return container.NewConditionalStarter(
+		"KubernetesStarter",
+		container.PropertyCondition(PropertyKubernetesEnabled, "true"),
+		func(builder container.ContextBuilder) error {
+			if err := checkStopBudget(builder, slog.Default()); err != nil {
+				return err
+			}
+			// A restarted container serves again
+			readiness.down.Store(false)
+			return builder.RegisterComponent(readiness)
+		},
+	)
+}
+
+// Ensure that readinessIndicator implements container.HealthIndicator
+var _ container.HealthIndicator = (*readinessIndicator)(nil)
@@ -0,0 +1,47 @@
+package boot
+
+import (
+	"context"
+	"log/slog"
+)
+
+// PropertyPidFile is the path of the PID file written when running as a service
+const PropertyPidFile = "goboot.service.pid-file"
+
+// PausableComponent is a component that can be paused and resumed by the service manager
+type PausableComponent interface {
+	// Pause is called when the service manager pauses the application
+	Pause(context.Context)
+	// Resume is called when the service manager resumes the application
+	Resume(context.Context)
+}
+
+// pause pauses all components implementing PausableComponent
+func (a *Application) pause() {
+	slog.Info("Pausing application")
+	for _, comp := range a.pausableComponents() {
+		comp.Pause(a.ctx)
+	}
+}
+
+// resume resumes all components implementing PausableComponent
+func (a *Application) resume() {
+	slog.Info("Resuming application")
+	for _, comp := range a.pausableComponents() {
+		comp.Resume(a.ctx)
+	}
+}
+
+func (a *Application) pausableComponents() []PausableComponent {
+	var result []PausableComponent
+	for _, name := range a.container.GetComponentNames() {
+		comp, err := a.container.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if pausable, ok := comp.(PausableComponent); ok {
+			result = append(result, pausable)
+		}
+	}
+	return result
+}
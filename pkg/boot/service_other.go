@@ -0,0 +1,39 @@
+//go:build !windows
+
+package boot
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"os/signal"
+	"strconv"
+	"syscall"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// RunAsService runs the application as a background daemon and blocks until shutdown.
+// It writes a PID file if goboot.service.pid-file is set and ignores SIGHUP so the
+// process survives losing its controlling terminal.
+func (a *Application) RunAsService(name string) error {
+	slog.Info("Running as daemon", "service", name, "pid", os.Getpid())
+
+	// A daemon has no TTY, so a hangup must not terminate it
+	signal.Ignore(syscall.SIGHUP)
+
+	pidFile := container.NewVariableHelper(a.container).GetString(PropertyPidFile, "")
+	if pidFile != "" {
+		if err := os.WriteFile(pidFile, []byte(strconv.Itoa(os.Getpid())+"\n"), 0o644); err != nil {
+			return fmt.Errorf("write pid file %s: %w", pidFile, err)
+		}
+		defer func() {
+			if err := os.Remove(pidFile); err != nil && !os.IsNotExist(err) {
+				slog.Error("Failed to remove pid file", "path", pidFile, "error", err)
+			}
+		}()
+	}
+
+	a.Run()
+	return nil
+}
@@ -0,0 +1,69 @@
+//go:build windows
+
+package boot
+
+import (
+	"log/slog"
+
+	"golang.org/x/sys/windows/svc"
+)
+
+// RunAsService runs the application under the Windows Service Control Manager and
+// blocks until the service is stopped. Stop and shutdown requests stop the container,
+// pause and continue requests are forwarded to PausableComponent implementations.
+// When not launched by the SCM (e.g. from a console) it behaves like Run.
+func (a *Application) RunAsService(name string) error {
+	isService, err := svc.IsWindowsService()
+	if err != nil {
+		return err
+	}
+
+	if !isService {
+		a.Run()
+		return nil
+	}
+
+	slog.Info("Running as Windows service", "service", name)
+	return svc.Run(name, &windowsService{app: a})
+}
+
+// windowsService adapts an Application to the svc.Handler interface
+type windowsService struct {
+	app *Application
+}
+
+// Execute handles SCM change requests until the service is stopped
+func (s *windowsService) Execute(_ []string, requests <-chan svc.ChangeRequest, changes chan<- svc.Status) (bool, uint32) {
+	const accepted = svc.AcceptStop | svc.AcceptShutdown | svc.AcceptPauseAndContinue
+
+	done := s.app.ctx.Done()
+	changes <- svc.Status{State: svc.Running, Accepts: accepted}
+
+	for {
+		select {
+		case <-done:
+			changes <- svc.Status{State: svc.StopPending}
+			s.app.Shutdown()
+			return false, 0
+		case req := <-requests:
+			switch req.Cmd {
+			case svc.Interrogate:
+				changes <- req.CurrentStatus
+			case svc.Stop, svc.Shutdown:
+				changes <- svc.Status{State: svc.StopPending}
+				s.app.Shutdown()
+				return false, 0
+			case svc.Pause:
+				changes <- svc.Status{State: svc.PausePending}
+				s.app.pause()
+				changes <- svc.Status{State: svc.Paused, Accepts: accepted}
+			case svc.Continue:
+				changes <- svc.Status{State: svc.ContinuePending}
+				s.app.resume()
+				changes <- svc.Status{State: svc.Running, Accepts: accepted}
+			default:
+				slog.Warn("Unexpected service control request", "cmd", req.Cmd)
+			}
+		}
+	}
+}
@@ -0,0 +1,95 @@
+// Package boottest provides test assertions over container wiring
+package boottest
+
+import (
+	"testing"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// AssertDependsOn fails the test unless component directly depends on each of deps
+func AssertDependsOn(t testing.TB, app container.ApplicationContext, component string, deps ...string) {
+	t.Helper()
+
+	inspector := inspect(t, app)
+	if inspector == nil {
+		return
+	}
+
+	if !app.HasComponent(component) {
+		t.Errorf("component %q is not registered", component)
+		return
+	}
+
+	actual := inspector.GetDependencies(component)
+	known := make(map[string]bool, len(actual))
+	for _, dep := range actual {
+		known[dep] = true
+	}
+
+	for _, dep := range deps {
+		if !known[dep] {
+			t.Errorf("component %q does not depend on %q; dependencies: %v", component, dep, actual)
+		}
+	}
+}
+
+// AssertInitOrder fails the test unless the named components were initialized in the given relative order
+func AssertInitOrder(t testing.TB, app container.ApplicationContext, names ...string) {
+	t.Helper()
+
+	inspector := inspect(t, app)
+	if inspector == nil {
+		return
+	}
+
+	order := inspector.GetInitOrder()
+	position := make(map[string]int, len(order))
+	for i, name := range order {
+		position[name] = i
+	}
+
+	for i, name := range names {
+		if _, ok := position[name]; !ok {
+			t.Errorf("component %q was not initialized; init order: %v", name, order)
+			return
+		}
+		if i > 0 && position[names[i-1]] > position[name] {
+			t.Errorf("component %q was initialized before %q; init order: %v", name, names[i-1], order)
+			return
+		}
+	}
+}
+
+// AssertNoComponent fails the test if any of the named components is registered
+func AssertNoComponent(t testing.TB, app container.ApplicationContext, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		if app.HasComponent(name) {
+			t.Errorf("component %q is registered but should not be", name)
+		}
+	}
+}
+
+// AssertHasComponent fails the test unless all named components are registered
+func AssertHasComponent(t testing.TB, app container.ApplicationContext, names ...string) {
+	t.Helper()
+
+	for _, name := range names {
+		if !app.HasComponent(name) {
+			t.Errorf("component %q is not registered", name)
+		}
+	}
+}
+
+func inspect(t testing.TB, app container.ApplicationContext) container.ContainerInspector {
+	t.Helper()
+
+	inspector, ok := app.(container.ContainerInspector)
+	if !ok {
+		t.Errorf("application context %T does not expose its wiring", app)
+		return nil
+	}
+	return inspector
+}
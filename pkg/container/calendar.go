@@ -0,0 +1,209 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+	"time"
+)
+
+// PropertyCalendars holds the exclusion calendars of schedules:
+// scheduling.calendars.<name>.*
+const PropertyCalendars = "scheduling.calendars"
+
+// Calendar excludes the runs of scheduled components, e.g. on holidays or during
+// maintenance windows. Days and daily ranges are in the time zone of the schedule.
+//
+//	scheduling:
+//	  calendars:
+//	    holidays:
+//	      dates: [2026-12-25, 2027-01-01]
+//	      weekdays: [saturday, sunday]
+//	    maintenance:
+//	      daily: [02:00-04:00]
+//	      windows: [2026-11-01T00:00:00Z/2026-11-02T06:00:00Z]
+type Calendar struct {
+	// Dates are excluded days, e.g. 2026-12-25
+	Dates []string `yaml:"dates"`
+	// Weekdays are excluded days of the week, e.g. sunday
+	Weekdays []string `yaml:"weekdays"`
+	// Daily are ranges excluded every day, e.g. 02:00-04:00; 22:00-02:00 spans midnight
+	Daily []string `yaml:"daily"`
+	// Windows are periods excluded once, as RFC 3339 start/end
+	Windows []string `yaml:"windows"`
+}
+
+// ExcludedRunPolicy handles the runs of a schedule that fall into one of its
+// exclusion calendars
+type ExcludedRunPolicy string
+
+// Excluded run policies
+const (
+	// ExcludedRunSkip drops the excluded runs
+	ExcludedRunSkip ExcludedRunPolicy = "skip"
+	// ExcludedRunDefer runs once when the exclusion ends, unless a regular run
+	// comes first
+	ExcludedRunDefer ExcludedRunPolicy = "defer"
+)
+
+// Skip reasons recorded in ComponentMetrics.Skips; runs excluded by a calendar
+// are recorded as "calendar:<name>"
+const (
+	SkipReasonPaused = "paused"
+	SkipReasonMissed = "missed"
+)
+
+// calendar is a parsed Calendar
+type calendar struct {
+	name     string
+	dates    map[string]bool
+	weekdays map[time.Weekday]bool
+	daily    []dailyRange
+	windows  []window
+}
+
+// dailyRange is a range of the day in minutes, end excluded; end <= start spans midnight
+type dailyRange struct {
+	start, end int
+}
+
+type window struct {
+	start, end time.Time
+}
+
+// weekdayNames maps the lowercase weekday names to their time.Weekday
+var weekdayNames = func() map[string]time.Weekday {
+	names := make(map[string]time.Weekday, 7)
+	for d := time.Sunday; d <= time.Saturday; d++ {
+		names[strings.ToLower(d.String())] = d
+	}
+	return names
+}()
+
+// parseCalendar validates a calendar
+func parseCalendar(name string, c Calendar) (*calendar, error) {
+	parsed := &calendar{
+		name:     name,
+		dates:    make(map[string]bool, len(c.Dates)),
+		weekdays: make(map[time.Weekday]bool, len(c.Weekdays)),
+	}
+	for _, date := range c.Dates {
+		if _, err := time.Parse(time.DateOnly, date); err != nil {
+			return nil, fmt.Errorf("invalid date %q: %w", date, err)
+		}
+		parsed.dates[date] = true
+	}
+	for _, day := range c.Weekdays {
+		weekday, ok := weekdayNames[strings.ToLower(strings.TrimSpace(day))]
+		if !ok {
+			return nil, fmt.Errorf("invalid weekday %q", day)
+		}
+		parsed.weekdays[weekday] = true
+	}
+	for _, daily := range c.Daily {
+		from, to, ok := strings.Cut(daily, "-")
+		start, startErr := parseClock(from)
+		end, endErr := parseClock(to)
+		if !ok || startErr != nil || endErr != nil || start == end {
+			return nil, fmt.Errorf("invalid daily range %q, expected HH:MM-HH:MM", daily)
+		}
+		parsed.daily = append(parsed.daily, dailyRange{start: start, end: end})
+	}
+	for _, w := range c.Windows {
+		from, to, ok := strings.Cut(w, "/")
+		start, startErr := time.Parse(time.RFC3339, strings.TrimSpace(from))
+		end, endErr := time.Parse(time.RFC3339, strings.TrimSpace(to))
+		if !ok || startErr != nil || endErr != nil || !end.After(start) {
+			return nil, fmt.Errorf("invalid window %q, expected RFC 3339 start/end", w)
+		}
+		parsed.windows = append(parsed.windows, window{start: start, end: end})
+	}
+	return parsed, nil
+}
+
+// parseClock returns the minutes since midnight of HH:MM; 24:00 is the end of the day
+func parseClock(value string) (int, error) {
+	t, err := time.Parse("15:04", strings.TrimSpace(value))
+	if err != nil {
+		if strings.TrimSpace(value) == "24:00" {
+			return 24 * 60, nil
+		}
+		return 0, err
+	}
+	return t.Hour()*60 + t.Minute(), nil
+}
+
+// until returns when the exclusion containing t ends, false if t isn't excluded
+func (c *calendar) until(t time.Time, loc *time.Location) (time.Time, bool) {
+	local := t.In(loc)
+	var end time.Time
+	extend := func(candidate time.Time) {
+		if candidate.After(end) {
+			end = candidate
+		}
+	}
+
+	if c.dates[local.Format(time.DateOnly)] || c.weekdays[local.Weekday()] {
+		extend(startOfDay(local).AddDate(0, 0, 1))
+	}
+	minute := local.Hour()*60 + local.Minute()
+	for _, r := range c.daily {
+		day := startOfDay(local)
+		switch {
+		case r.start < r.end && minute >= r.start && minute < r.end:
+			extend(atMinute(day, r.end))
+		case r.end < r.start && minute >= r.start:
+			extend(atMinute(day.AddDate(0, 0, 1), r.end))
+		case r.end < r.start && minute < r.end:
+			extend(atMinute(day, r.end))
+		}
+	}
+	for _, w := range c.windows {
+		if !t.Before(w.start) && t.Before(w.end) {
+			extend(w.end)
+		}
+	}
+	return end, !end.IsZero()
+}
+
+func startOfDay(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+func atMinute(day time.Time, minute int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), minute/60, minute%60, 0, 0, day.Location())
+}
+
+// maxExclusionSteps bounds the walk through adjacent exclusions, e.g. a year of
+// excluded days
+const maxExclusionSteps = 400
+
+// excludedUntil returns the first calendar excluding t and when the adjacent
+// exclusions of all calendars end; nil if t isn't excluded
+func excludedUntil(calendars []*calendar, t time.Time, loc *time.Location) (*calendar, time.Time) {
+	var first *calendar
+	end := t
+	for step := 0; step < maxExclusionSteps; step++ {
+		var next time.Time
+		for _, c := range calendars {
+			if until, ok := c.until(end, loc); ok && until.After(next) {
+				if first == nil {
+					first = c
+				}
+				next = until
+			}
+		}
+		if next.IsZero() {
+			break
+		}
+		end = next
+	}
+	return first, end
+}
+
+// location returns the time zone of a schedule, UTC if it has none
+func (s Schedule) location() (*time.Location, error) {
+	if s.TimeZone == "" {
+		return time.UTC, nil
+	}
+	return time.LoadLocation(s.TimeZone)
+}
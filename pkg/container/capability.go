@@ -0,0 +1,68 @@
+package container
+
+import (
+	"reflect"
+	"sync"
+)
+
+// capabilities records the integrations linked into the binary
+var capabilities = struct {
+	mu    sync.RWMutex
+	names map[string]bool
+}{names: make(map[string]bool)}
+
+// RegisterCapability records that an integration is linked into the binary.
+// Integration packages call it from init, so that starters depending on them only
+// activate if the package is imported:
+//
+//	// in package mydriver
+//	func init() { container.RegisterCapability("mydriver") }
+//
+//	// in a starter
+//	container.NewConditionalStarter("db", container.ClassCondition("mydriver"), ...)
+func RegisterCapability(names ...string) {
+	capabilities.mu.Lock()
+	defer capabilities.mu.Unlock()
+	for _, name := range names {
+		capabilities.names[name] = true
+	}
+}
+
+// RegisterType records types as capabilities named by TypeName, e.g.
+// container.RegisterType(reflect.TypeOf(Driver{})) registers "example.com/mydriver.Driver"
+func RegisterType(types ...reflect.Type) {
+	for _, t := range types {
+		RegisterCapability(TypeName(t))
+	}
+}
+
+// TypeName returns the fully qualified name of a type, dereferencing pointers
+func TypeName(t reflect.Type) string {
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if t.PkgPath() == "" {
+		return t.String()
+	}
+	return t.PkgPath() + "." + t.Name()
+}
+
+// HasCapability reports whether a capability (or type name) was registered
+func HasCapability(name string) bool {
+	capabilities.mu.RLock()
+	defer capabilities.mu.RUnlock()
+	return capabilities.names[name]
+}
+
+// ClassCondition checks that all the capabilities or type names were registered
+// with RegisterCapability or RegisterType
+func ClassCondition(names ...string) func(ApplicationContext) bool {
+	return func(ApplicationContext) bool {
+		for _, name := range names {
+			if !HasCapability(name) {
+				return false
+			}
+		}
+		return true
+	}
+}
@@ -0,0 +1,94 @@
+package container
+
+import "time"
+
+// Clock abstracts time for the lifecycle manager so scheduling can be controlled in tests
+type Clock interface {
+	// Now returns the current time
+	Now() time.Time
+	// After waits for the duration to elapse and then sends the current time on the returned channel
+	After(d time.Duration) <-chan time.Time
+	// NewTicker returns a ticker that fires every d
+	NewTicker(d time.Duration) Ticker
+	// NewTimer returns a timer that fires once after d
+	NewTimer(d time.Duration) Timer
+}
+
+// Ticker delivers ticks at intervals
+type Ticker interface {
+	// C returns the channel on which ticks are delivered
+	C() <-chan time.Time
+	// Stop turns off the ticker
+	Stop()
+}
+
+// Timer delivers a single tick after a duration and can be rearmed
+type Timer interface {
+	// C returns the channel on which the tick is delivered
+	C() <-chan time.Time
+	// Stop prevents the timer from firing
+	Stop()
+	// Reset stops the timer and arms it to fire after d
+	Reset(d time.Duration)
+}
+
+// realClock implements Clock using the time package
+type realClock struct{}
+
+// RealClock returns a Clock backed by the system time
+func RealClock() Clock {
+	return realClock{}
+}
+
+func (realClock) Now() time.Time {
+	return time.Now()
+}
+
+func (realClock) After(d time.Duration) <-chan time.Time {
+	return time.After(d)
+}
+
+func (realClock) NewTicker(d time.Duration) Ticker {
+	return realTicker{ticker: time.NewTicker(d)}
+}
+
+func (realClock) NewTimer(d time.Duration) Timer {
+	return realTimer{timer: time.NewTimer(d)}
+}
+
+// realTicker wraps a time.Ticker
+type realTicker struct {
+	ticker *time.Ticker
+}
+
+func (t realTicker) C() <-chan time.Time {
+	return t.ticker.C
+}
+
+func (t realTicker) Stop() {
+	t.ticker.Stop()
+}
+
+// realTimer wraps a time.Timer
+type realTimer struct {
+	timer *time.Timer
+}
+
+func (t realTimer) C() <-chan time.Time {
+	return t.timer.C
+}
+
+func (t realTimer) Stop() {
+	t.timer.Stop()
+}
+
+func (t realTimer) Reset(d time.Duration) {
+	// Drain a tick that fired but wasn't received so it isn't mistaken for the new one
+	if !t.timer.Stop() {
+		select {
+		case <-t.timer.C:
+		default:
+		}
+	}
+	t.timer.Reset(d)
+}
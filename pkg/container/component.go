@@ -52,8 +52,49 @@ type Schedule struct {
 	InitialDelay time.Duration
 	// Whether to run immediately on startup
 	RunOnStartup bool
+	// MissedRuns decides what happens to runs that couldn't fire on time because
+	// the process was suspended or an execution overran (MissedRunFireOnceNow if empty)
+	MissedRuns MissedRunPolicy
+	// Jitter moves every run by a random offset within ±Jitter (at most half the
+	// interval), so that instances of a fleet don't all run at the same moment
+	Jitter time.Duration
+	// AlignTo aligns the first run to a multiple of AlignTo since the zero time
+	// (in TimeZone), e.g. time.Minute for the top of the minute; with an Interval
+	// that is a multiple of AlignTo every run is aligned. Applied after InitialDelay.
+	AlignTo time.Duration
+	// TimeZone is the IANA time zone of AlignTo and the exclusion calendars, e.g.
+	// Europe/Berlin (UTC if empty). Aligned runs keep to the local boundaries
+	// across daylight saving changes, e.g. a daily run at local midnight.
+	TimeZone string
+	// Exclusions name the calendars of scheduling.calendars during which the
+	// component doesn't run, e.g. holidays or maintenance windows
+	Exclusions []string
+	// ExcludedRuns decides what happens to runs falling into an exclusion
+	// (ExcludedRunSkip if empty)
+	ExcludedRuns ExcludedRunPolicy
+	// Property names a configuration section overriding the fields above, e.g.
+	// "schedule.cleanup" with the keys interval, initial-delay, run-on-startup,
+	// missed-runs, jitter, align-to, time-zone, exclusions and excluded-runs.
+	// Schedules are read again when the variables are reloaded and changes apply
+	// without restarting the component.
+	Property string
 }
 
+// MissedRunPolicy handles the runs of a schedule that were missed. A run is missed
+// when it falls due while the previous execution is still running, or fires more
+// than half an interval (at most a second) late.
+type MissedRunPolicy string
+
+// Missed run policies
+const (
+	// MissedRunFireOnceNow merges the missed runs into a single run as soon as possible
+	MissedRunFireOnceNow MissedRunPolicy = "fire-once-now"
+	// MissedRunCatchUpAll executes every missed run, one after another
+	MissedRunCatchUpAll MissedRunPolicy = "catch-up-all"
+	// MissedRunSkip drops the missed runs and waits for the next run on time
+	MissedRunSkip MissedRunPolicy = "skip"
+)
+
 // ConfigurableComponent can be configured after creation
 type ConfigurableComponent interface {
 	Component
@@ -75,6 +116,28 @@ type ConditionalComponent interface {
 	ShouldInitialize(ApplicationContext) bool
 }
 
+// CriticalComponent can declare that its initialization failures are not fatal
+type CriticalComponent interface {
+	Component
+	// IsCritical returns false if a failed Init should be logged and the component
+	// skipped instead of aborting container startup (components are critical by default)
+	IsCritical() bool
+}
+
+// PrimaryComponent can mark itself as the preferred candidate when several
+// components match a type requested via GetComponent
+type PrimaryComponent interface {
+	// IsPrimary returns true if this component wins type-based lookups
+	IsPrimary() bool
+}
+
+// Tagged components declare tags (stereotypes such as "repository" or "controller")
+// that can be queried with GetComponentsByTag
+type Tagged interface {
+	// Tags returns the tags of this component
+	Tags() []string
+}
+
 // ComponentBase provides a basic implementation of Component methods
 type ComponentBase struct {
 	name string
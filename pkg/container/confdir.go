@@ -0,0 +1,71 @@
+package container
+
+import (
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// ConfDirLoader loads every *.yml and *.yaml file below a directory in lexical
+// order of their paths, so operators can drop override snippets like
+// 10-base.yml, 50-db.yml and 90-local.yml instead of editing one file. Later
+// files override earlier ones the way profile files override application.yml.
+type ConfDirLoader struct {
+	// Path of the directory ("conf.d" if empty); a missing directory is skipped
+	Path string
+	// MergeStrategies defines how later files combine lists and maps with earlier
+	// files, by dot-separated key. They take precedence over goboot.config.merge.*
+	// from the files.
+	MergeStrategies map[string]MergeStrategy
+}
+
+// Load loads and merges the files of the directory
+func (l ConfDirLoader) Load(builder ContextBuilder) error {
+	logger := slog.Default()
+
+	dir := l.Path
+	if dir == "" {
+		dir = "conf.d"
+	}
+	if _, err := os.Stat(dir); os.IsNotExist(err) {
+		logger.Info("Configuration directory not found, skipping", "path", dir)
+		return nil
+	}
+
+	// WalkDir visits the entries of each directory in lexical order
+	var files []string
+	err := filepath.WalkDir(dir, func(path string, entry fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if ext := filepath.Ext(path); !entry.IsDir() && (ext == ".yml" || ext == ".yaml") && !strings.HasPrefix(entry.Name(), ".") {
+			files = append(files, path)
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("error reading config directory %s: %w", dir, err)
+	}
+
+	config := make(map[string]interface{})
+	for _, file := range files {
+		logger.Info("Loading configuration", "path", file)
+		fileConfig, err := readYamlConfig(file)
+		if err != nil {
+			return fmt.Errorf("error loading config %s: %w", file, err)
+		}
+
+		// Strategies configured by earlier files apply to the later ones
+		strategies := mergeStrategiesFrom(config)
+		for path, strategy := range l.MergeStrategies {
+			strategies[path] = strategy
+		}
+		config = mergeConfig(config, fileConfig, "", strategies)
+	}
+
+	// Register the merged configuration, so replaced lists and maps leave no stale keys
+	return MapVariableLoader{Variables: config}.Load(builder)
+}
@@ -1,6 +1,9 @@
 package container
 
-import "log/slog"
+import (
+	"log/slog"
+	"time"
+)
 
 // Config contains configuration options for the container
 type Config struct {
@@ -12,6 +15,35 @@ type Config struct {
 	DefaultVariableLoaders []VariableLoader
 	// DefaultStarters are loaded by default
 	DefaultStarters []Starter
+	// Clock used for scheduling components (uses the system clock if nil)
+	Clock Clock
+	// StrictRegistration makes New fail if any registration was invalid (nil
+	// components, starters, factories or loaders, duplicate component names, forbidden
+	// duplicate variables). Otherwise they are dropped and logged in one batch.
+	StrictRegistration bool
+	// ForbidDuplicateVariables reports registering a variable twice from the setup block,
+	// factories or starters as an invalid registration. Variable loaders and environment
+	// post-processors may still override variables.
+	ForbidDuplicateVariables bool
+	// InitializeOnDemand makes GetComponent, GetComponentByName and GetComponentsByTag
+	// called from a component's Init initialize the returned components first, so
+	// dependencies missed by dependency discovery are never seen half-built
+	InitializeOnDemand bool
+	// Profiles are activated in addition to GO_BOOT_ACTIVE_PROFILES and goboot.profiles.active
+	Profiles []string
+	// Converters used to bind variables to typed values (uses DefaultConverters if nil)
+	Converters *ConverterRegistry
+	// LifecycleEventListener receives the begin and end of every component's init,
+	// start and stop, e.g. a JSONLinesEventWriter for profiling startup
+	LifecycleEventListener LifecycleEventListener
+	// StopBudget is how long a component may take to stop before the shutdown report
+	// flags it (DefaultStopBudget if zero)
+	StopBudget time.Duration
+	// JobStore persists when scheduled components last fired, so that runs missed
+	// while the application was down follow their MissedRuns policy after a restart
+	JobStore JobStore
+	// StateStore keeps the state of StatefulComponents across restarts
+	StateStore StateStore
 }
 
 // DefaultConfig returns default configuration
@@ -24,5 +56,6 @@ func DefaultConfig() *Config {
 			&EnvVariableLoader{},
 		},
 		DefaultStarters: []Starter{},
+		Clock:           RealClock(),
 	}
 }
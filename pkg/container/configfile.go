@@ -0,0 +1,113 @@
+package container
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"strings"
+	"sync"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConfigFlag is the command-line flag naming a single configuration file,
+// e.g. --config=/etc/app/config.yml, or --config=- to read it from stdin
+const ConfigFlag = "--config"
+
+// ConfigFileLoader loads variables from exactly one YAML file, without looking
+// for application.yml or profile files. Unlike the directory loaders it fails
+// if the file doesn't exist.
+type ConfigFileLoader struct {
+	// Path of the YAML file, "-" to read it from Stdin
+	Path string
+	// Stdin is read when Path is "-" (os.Stdin if nil)
+	Stdin io.Reader
+
+	// stdin is read once, so that reloads see the same document
+	once  sync.Once
+	stdin []byte
+	err   error
+}
+
+// Load registers the flattened variables of the file
+func (l *ConfigFileLoader) Load(builder ContextBuilder) error {
+	data, err := l.read()
+	if err != nil {
+		return fmt.Errorf("error loading config %s: %w", l.Path, err)
+	}
+
+	config := make(map[string]interface{})
+	if err := yaml.Unmarshal(data, &config); err != nil {
+		return fmt.Errorf("error loading config %s: %w", l.Path, err)
+	}
+	return MapVariableLoader{Variables: config}.Load(builder)
+}
+
+// read returns the content of the file or stdin
+func (l *ConfigFileLoader) read() ([]byte, error) {
+	if l.Path != "-" {
+		slog.Info("Loading configuration", "path", l.Path)
+		return os.ReadFile(l.Path)
+	}
+
+	l.once.Do(func() {
+		stdin := l.Stdin
+		if stdin == nil {
+			stdin = os.Stdin
+		}
+		slog.Info("Loading configuration from stdin")
+		l.stdin, l.err = io.ReadAll(stdin)
+	})
+	return l.stdin, l.err
+}
+
+// CommandLineLoader registers --name=value arguments as variables, e.g.
+// --server.port=9090. A flag without a value is registered as "true". Other
+// arguments and ConfigFlag are ignored.
+type CommandLineLoader struct {
+	Args []string
+}
+
+// Load registers the variables of the arguments
+func (l CommandLineLoader) Load(builder ContextBuilder) error {
+	for i := 0; i < len(l.Args); i++ {
+		arg := l.Args[i]
+		if arg == "--" {
+			break
+		}
+		if !strings.HasPrefix(arg, "--") || len(arg) == 2 {
+			continue
+		}
+
+		name, value, hasValue := strings.Cut(arg[2:], "=")
+		if "--"+name == ConfigFlag {
+			if !hasValue {
+				i++ // skip the path of --config <path>
+			}
+			continue
+		}
+		if !hasValue {
+			value = "true"
+		}
+		builder.RegisterVariable(name, value)
+	}
+	return nil
+}
+
+// ConfigFileFromArgs returns the path given with ConfigFlag as --config=<path> or
+// --config <path>, "" if there is none
+func ConfigFileFromArgs(args []string) string {
+	for i, arg := range args {
+		if arg == "--" {
+			break
+		}
+		if path, ok := strings.CutPrefix(arg, ConfigFlag+"="); ok {
+			return path
+		}
+		if arg == ConfigFlag && i+1 < len(args) {
+			return args[i+1]
+		}
+	}
+	return ""
+}
@@ -5,6 +5,8 @@ import (
 	"fmt"
 	"log/slog"
 	"reflect"
+	"sort"
+	"sync"
 	"time"
 )
 
@@ -22,35 +24,211 @@ type container struct {
 	dependencyResolver DependencyResolver
 	componentInit      ComponentInitializer
 	lifecycleManager   ComponentLifecycleManager
+	states             *componentStates
 
 	// Factory and starter support
 	starters         []Starter
 	variablesLoaders []VariableLoader
+	postProcessors   []EnvironmentPostProcessor
+	profiles         []string
 	factories        []Factory
+
+	// Invalid registrations collected until the starters have run
+	registrationErrors []error
+	loadingVariables   bool
+	// registering while a factory or starter runs; missing is the last component
+	// it failed to look up by name
+	registering bool
+	missing     string
+	// registrar is the factory or starter running, registeredBy the registrar of
+	// each component it registered
+	registrar    string
+	registeredBy map[string]string
+	// docs are the component documentations set with DescribeComponent
+	docs map[string]ComponentDoc
+	// declarations are the variables declared with DeclareVariable
+	declarations map[string]VarSpec
+	// variableSource is the loader or post-processor running, origins the source of
+	// each variable
+	variableSource string
+	origins        *variableOrigins
+
+	// Modules and the module whose Register function is running
+	modules       []Module
+	currentModule string
+
+	// declaredDependencies are the required components of the components produced
+	// by planned factories
+	declaredDependencies map[string][]string
+
+	// Components shared with the parent container, see NewChild
+	inherited map[string]bool
+
+	// shutdownHooks run when the components stop
+	shutdownHooks *shutdownHooks
+
+	// reloadMu serializes variable reloads
+	reloadMu sync.Mutex
+}
+
+// recordRegistration remembers a failed registration so that it can be reported
+// (or rejected in strict mode) once all registrations are done
+func (c *container) recordRegistration(err error) error {
+	if err != nil {
+		c.registrationErrors = append(c.registrationErrors, err)
+	}
+	return err
+}
+
+// checkRegistrations reports the invalid registrations in a deterministic order
+func (c *container) checkRegistrations() error {
+	if len(c.registrationErrors) == 0 {
+		return nil
+	}
+
+	problems := make([]string, len(c.registrationErrors))
+	for i, err := range c.registrationErrors {
+		problems[i] = err.Error()
+	}
+	sort.Strings(problems)
+	c.registrationErrors = nil
+
+	if c.config.StrictRegistration {
+		return InvalidRegistrationError(problems)
+	}
+	c.logger.Warn("Ignored invalid registrations", "count", len(problems), "problems", problems)
+	return nil
+}
+
+// isNil reports whether v is nil or a typed nil pointer stored in an interface
+func isNil(v interface{}) bool {
+	if v == nil {
+		return true
+	}
+	rv := reflect.ValueOf(v)
+	switch rv.Kind() {
+	case reflect.Pointer, reflect.Map, reflect.Slice, reflect.Func, reflect.Interface, reflect.Chan:
+		return rv.IsNil()
+	}
+	return false
 }
 
 // RegisterComponent adds a component to the container
 func (c *container) RegisterComponent(component Component) error {
-	return c.componentRegistry.Register(component)
+	if err := c.recordRegistration(c.componentRegistry.Register(component)); err != nil {
+		return err
+	}
+	if c.currentModule != "" {
+		c.componentRegistry.SetModule(component.Name(), c.currentModule, false)
+	}
+	if c.registrar != "" {
+		if c.registeredBy == nil {
+			c.registeredBy = make(map[string]string)
+		}
+		c.registeredBy[component.Name()] = c.registrar
+	}
+	return nil
+}
+
+// RegisterComponentWithTags adds a component with additional tags
+func (c *container) RegisterComponentWithTags(component Component, tags ...string) error {
+	if err := c.RegisterComponent(component); err != nil {
+		return err
+	}
+	return c.componentRegistry.AddTags(component.Name(), tags...)
+}
+
+// RegisterComponentExposedAs adds a component that is only injectable by the given interface types
+func (c *container) RegisterComponentExposedAs(component Component, types ...reflect.Type) error {
+	if err := c.RegisterComponent(component); err != nil {
+		return err
+	}
+	return c.componentRegistry.SetExposedTypes(component.Name(), types...)
+}
+
+// RegisterInstance adds an arbitrary value to the container
+func (c *container) RegisterInstance(name string, value interface{}) error {
+	if value == nil {
+		return c.recordRegistration(fmt.Errorf("cannot register nil instance %s", name))
+	}
+	return c.RegisterComponent(NewInstance(name, value))
 }
 
 // RegisterVariable adds a variable to the container
 func (c *container) RegisterVariable(name string, value interface{}) {
+	if c.config.ForbidDuplicateVariables && !c.loadingVariables && c.variableRegistry.Has(name) {
+		c.recordRegistration(fmt.Errorf("variable %s registered twice", name))
+	}
 	c.variableRegistry.Register(name, value)
+	c.origins.set(c.variableOrigin(), false, name)
 }
 
-// AddVariableLoader adds a variable loader
+// RegisterVariables adds several variables to the container at once
+func (c *container) RegisterVariables(variables map[string]interface{}) {
+	if c.config.ForbidDuplicateVariables && !c.loadingVariables {
+		for name := range variables {
+			if c.variableRegistry.Has(name) {
+				c.recordRegistration(fmt.Errorf("variable %s registered twice", name))
+			}
+		}
+	}
+	c.variableRegistry.RegisterAll(variables)
+	c.origins.set(c.variableOrigin(), false, variableNames(variables)...)
+}
+
+// variableNames returns the names of variables
+func variableNames(variables map[string]interface{}) []string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	return names
+}
+
+// RegisterDefaultVariable adds a default value with lower precedence than all variables
+func (c *container) RegisterDefaultVariable(name string, value interface{}) {
+	c.variableRegistry.RegisterDefault(name, value)
+	c.origins.set(c.variableOrigin(), true, name)
+}
+
+// RegisterVariableString adds a string variable to the container
+func (c *container) RegisterVariableString(name string, value string) {
+	c.RegisterVariable(name, value)
+}
+
+// AddVariableLoader adds a variable loader; nil loaders are ignored
 func (c *container) AddVariableLoader(loader VariableLoader) {
+	if isNil(loader) {
+		c.recordRegistration(fmt.Errorf("cannot add nil variable loader"))
+		return
+	}
 	c.variablesLoaders = append(c.variablesLoaders, loader)
 }
 
-// RegisterFactory adds a component factory
+// AddEnvironmentPostProcessor adds an environment post-processor; nil post-processors are ignored
+func (c *container) AddEnvironmentPostProcessor(processor EnvironmentPostProcessor) {
+	if isNil(processor) {
+		c.recordRegistration(fmt.Errorf("cannot add nil environment post-processor"))
+		return
+	}
+	c.postProcessors = append(c.postProcessors, processor)
+}
+
+// RegisterFactory adds a component factory; nil factories are ignored
 func (c *container) RegisterFactory(factory Factory) {
+	if isNil(factory) {
+		c.recordRegistration(fmt.Errorf("cannot register nil factory"))
+		return
+	}
 	c.factories = append(c.factories, factory)
 }
 
-// RegisterStarter adds a starter to the container
+// RegisterStarter adds a starter to the container; nil starters are ignored
 func (c *container) RegisterStarter(starter Starter) {
+	if isNil(starter) {
+		c.recordRegistration(fmt.Errorf("cannot register nil starter (%T)", starter))
+		return
+	}
 	c.starters = append(c.starters, starter)
 }
 
@@ -59,6 +237,11 @@ func (c *container) HasComponent(name string) bool {
 	return c.componentRegistry.Has(name)
 }
 
+// GetComponentsByTag returns all components with the given tag, sorted by name
+func (c *container) GetComponentsByTag(tag string) []Component {
+	return c.componentRegistry.GetByTag(tag)
+}
+
 // GetComponentNames returns all registered component names
 func (c *container) GetComponentNames() []string {
 	return c.componentRegistry.GetNames()
@@ -66,14 +249,18 @@ func (c *container) GetComponentNames() []string {
 
 // GetComponentByName returns a component by name
 func (c *container) GetComponentByName(name string) (Component, error) {
-	return c.componentRegistry.Get(name)
+	comp, err := c.componentRegistry.Get(name)
+	if err != nil && c.registering {
+		c.missing = name
+	}
+	return comp, err
 }
 
 // GetComponent finds a component matching the type of the provided pointer and sets the pointer
 func (c *container) GetComponent(target interface{}) error {
 	// Get target type
 	targetType := reflect.TypeOf(target)
-	if targetType.Kind() != reflect.Ptr {
+	if targetType == nil || targetType.Kind() != reflect.Ptr {
 		return ErrorWithCode("TARGET_NOT_POINTER", "target must be a pointer")
 	}
 
@@ -81,37 +268,15 @@ func (c *container) GetComponent(target interface{}) error {
 	elemType := targetType.Elem()
 	targetValue := reflect.ValueOf(target).Elem()
 
-	// First try exact type match
-	components := c.componentRegistry.GetAll()
-	for _, comp := range components {
-		compType := reflect.TypeOf(comp)
-		if compType == elemType || compType == reflect.PtrTo(elemType) {
-			// Found a match, set the pointer
-			if targetValue.Kind() == reflect.Ptr {
-				// For pointer targets like **TestComponent
-				targetValue.Set(reflect.ValueOf(comp))
-			} else if targetValue.Kind() == reflect.Struct && compType.Kind() == reflect.Ptr {
-				// For struct targets and pointer components like TestComponent and *TestComponent
-				targetValue.Set(reflect.ValueOf(comp).Elem())
-			} else {
-				// For other cases, try direct assignment
-				targetValue.Set(reflect.ValueOf(comp))
-			}
-			return nil
-		}
-	}
-
-	// Then try assignable types for interface support
-	for _, comp := range components {
-		compType := reflect.TypeOf(comp)
-		if compType.AssignableTo(elemType) {
-			// Found a match, set the pointer
-			targetValue.Set(reflect.ValueOf(comp))
-			return nil
-		}
+	// Exact type matches win over assignable types for interface support
+	match, err := selectTypeMatch(findTypeMatches(c.componentRegistry, elemType), elemType)
+	if err != nil {
+		return err
 	}
 
-	return ErrorWithCode("COMPONENT_TYPE_NOT_FOUND", "no component found matching type %v", elemType)
+	// Found a match, set the pointer
+	match.assign(targetValue)
+	return nil
 }
 
 // GetVariable returns a variable by name
@@ -124,34 +289,182 @@ func (c *container) GetVariableRaw(name string) interface{} {
 	return c.variableRegistry.Get(name)
 }
 
+// GetVariableAs converts a variable or a section of variables into target
+func (c *container) GetVariableAs(name string, target interface{}) error {
+	return NewVariableHelper(c).Bind(name, target)
+}
+
+// HasVariable checks if a variable exists
+func (c *container) HasVariable(name string) bool {
+	return c.variableRegistry.Has(name)
+}
+
+// GetVariables returns a copy of all variables
+func (c *container) GetVariables() map[string]interface{} {
+	return c.variableRegistry.GetAll()
+}
+
+// variablesWithPrefix returns the variables below prefix with the prefix removed
+func (c *container) variablesWithPrefix(prefix string) map[string]interface{} {
+	return c.variableRegistry.GetWithPrefix(prefix)
+}
+
+// variableExpressionError returns the error of a failing expression in the
+// variable or in the variables below it
+func (c *container) variableExpressionError(name string) error {
+	if registry, ok := c.variableRegistry.(*defaultVariableRegistry); ok {
+		return registry.expressionError(name)
+	}
+	return nil
+}
+
+// GetConverters returns the converters used to bind variables
+func (c *container) GetConverters() *ConverterRegistry {
+	return c.config.Converters
+}
+
 // GetMetrics returns metrics for all components
 func (c *container) GetMetrics() map[string]*ComponentMetrics {
 	return c.metricsCollector.GetMetrics()
 }
 
-// runStarters runs all registered starters
-func (c *container) runStarters() error {
+// GetDependencies returns the sorted names of the components the named component depends on
+func (c *container) GetDependencies(name string) []string {
+	if c.dependencyResolver == nil {
+		return nil
+	}
+
+	deps := c.dependencyResolver.GetDependencies(name)
+	result := make([]string, 0, len(deps))
+	for dep := range deps {
+		if dep != name {
+			result = append(result, dep)
+		}
+	}
+	sort.Strings(result)
+	return result
+}
+
+// GetInitOrder returns component names in the order they were initialized
+func (c *container) GetInitOrder() []string {
+	if c.componentInit == nil {
+		return nil
+	}
+	return c.componentInit.GetInitOrder()
+}
+
+// Ensure that container implements ContainerInspector
+var _ ContainerInspector = (*container)(nil)
+
+// Ensure that container implements ConverterSource
+var _ ConverterSource = (*container)(nil)
+
+// runFactories runs the factories that aren't planned; those failing on a
+// component that isn't registered yet are deferred
+func (c *container) runFactories(deferred *[]*deferredRegistration) error {
+	c.logger.Info("Running component factories", "count", len(c.factories))
+	for _, factory := range c.factories {
+		if _, ok := factory.(PlannedFactory); ok {
+			continue
+		}
+		factory := factory
+		if err := c.runRegistration("factory", factoryName(factory), func() error { return factory.Create(c) }, deferred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// runPlannedFactories runs the planned factories in dependency order and records
+// the dependencies of the components they produce
+func (c *container) runPlannedFactories(deferred *[]*deferredRegistration) error {
+	planned, err := planFactories(c.factories)
+	if err != nil || len(planned) == 0 {
+		return err
+	}
+
+	c.logger.Info("Running planned component factories", "count", len(planned))
+	for _, factory := range planned {
+		factory := factory
+		run := func() error {
+			if err := factory.Create(c); err != nil {
+				return err
+			}
+			c.declareDependencies(factory)
+			return nil
+		}
+		if err := c.runRegistration("factory", factoryName(factory), run, deferred); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// declareDependencies makes the components a planned factory produced depend on
+// the components it requires
+func (c *container) declareDependencies(factory PlannedFactory) {
+	for _, name := range factory.Produces() {
+		if !c.componentRegistry.Has(name) {
+			c.logger.Warn("Planned factory didn't register a component it produces", "factory", factoryName(factory), "component", name)
+			continue
+		}
+		if c.declaredDependencies == nil {
+			c.declaredDependencies = make(map[string][]string)
+		}
+		c.declaredDependencies[name] = append(c.declaredDependencies[name], factory.Requires()...)
+	}
+}
+
+// runStarters runs the starters, then retries the deferred factories and starters
+func (c *container) runStarters(deferred []*deferredRegistration) error {
 	c.logger.Info("Running starters", "count", len(c.starters))
 
-	for _, starter := range c.starters {
+	next, err := c.runStartersFrom(0, &deferred)
+	if err != nil {
+		return err
+	}
+	return c.retryDeferred(deferred, next)
+}
+
+// runStartersFrom runs the starters from index next on and returns the index after
+// the last one; starters failing on a component that isn't registered yet are deferred
+func (c *container) runStartersFrom(next int, deferred *[]*deferredRegistration) (int, error) {
+	// Index loop: starters may register further starters (e.g. the PluginLoader)
+	for ; next < len(c.starters); next++ {
+		starter := c.starters[next]
 		c.logger.Debug("Running starter", "name", starter.Name())
-		if conditionalStarter, ok := starter.(ConditionalStarter); ok {
-			if !conditionalStarter.ShouldStart(c) {
+		run := func() error {
+			if conditionalStarter, ok := starter.(ConditionalStarter); ok && !conditionalStarter.ShouldStart(c) {
 				c.logger.Debug("Skipping conditional starter", "name", starter.Name())
-				continue
+				return nil
 			}
+			return starter.Start(c)
 		}
-
-		if err := starter.Start(c); err != nil {
-			return fmt.Errorf("starter %s failed: %w", starter.Name(), err)
+		if err := c.runRegistration("starter", starter.Name(), run, deferred); err != nil {
+			return next, err
 		}
 	}
-
-	return nil
+	return next, nil
 }
 
 // New creates a new container with the given configuration
 func New(ctx context.Context, cfg *Config, block func(ContextBuilder)) (ApplicationContext, func(), error) {
+	return newContainer(ctx, cfg, block, nil)
+}
+
+// StartSubset creates a container that only initializes and starts the named components
+// and their transitive dependencies. All other registered components are skipped,
+// which keeps focused integration tests from booting unrelated subsystems.
+func StartSubset(ctx context.Context, cfg *Config, block func(ContextBuilder), names ...string) (ApplicationContext, func(), error) {
+	if len(names) == 0 {
+		return nil, nil, ErrorWithCode("EMPTY_SUBSET", "at least one component name is required")
+	}
+	return newContainer(ctx, cfg, block, names)
+}
+
+// newContainer creates and starts a container; if subset is non-nil only those
+// components and their dependencies are initialized and started
+func newContainer(ctx context.Context, cfg *Config, block func(ContextBuilder), subset []string) (ApplicationContext, func(), error) {
 	if cfg == nil {
 		cfg = DefaultConfig()
 	}
@@ -160,6 +473,10 @@ func New(ctx context.Context, cfg *Config, block func(ContextBuilder)) (Applicat
 		cfg.Logger = slog.Default()
 	}
 
+	if cfg.Clock == nil {
+		cfg.Clock = RealClock()
+	}
+
 	logger := cfg.Logger
 	logger.Info("Creating container")
 	startTime := time.Now()
@@ -176,9 +493,18 @@ func New(ctx context.Context, cfg *Config, block func(ContextBuilder)) (Applicat
 		componentRegistry: compRegistry,
 		variableRegistry:  varRegistry,
 		metricsCollector:  metricsCollector,
-		variablesLoaders:  cfg.DefaultVariableLoaders,
-		starters:          cfg.DefaultStarters,
+		states:            newComponentStates(cfg.LifecycleEventListener),
 		factories:         []Factory{},
+		shutdownHooks:     newShutdownHooks(logger),
+		origins:           newVariableOrigins(),
+	}
+
+	// Copy the defaults so that registrations never modify the shared Config
+	for _, loader := range cfg.DefaultVariableLoaders {
+		res.AddVariableLoader(loader)
+	}
+	for _, starter := range cfg.DefaultStarters {
+		res.RegisterStarter(starter)
 	}
 
 	// Register components and variables
@@ -187,24 +513,51 @@ func New(ctx context.Context, cfg *Config, block func(ContextBuilder)) (Applicat
 	// Set up dependency resolver and initializer
 	res.dependencyResolver = newDependencyResolver(res, compRegistry, metricsCollector, logger)
 
-	// Run factories to register components
-	logger.Info("Running component factories", "count", len(res.factories))
-	for _, factory := range res.factories {
-		if err := factory.Create(res); err != nil {
-			return nil, nil, fmt.Errorf("factory failed: %w", err)
-		}
+	// Run factories to register components; those looking up components registered
+	// later are retried after the starters
+	var deferred []*deferredRegistration
+	if err := res.runFactories(&deferred); err != nil {
+		return nil, nil, err
 	}
 
 	// Load variables from loaders
 	logger.Info("Loading variables", "loaders", len(res.variablesLoaders))
+	res.loadingVariables = true
 	for _, loader := range res.variablesLoaders {
-		if err := loader.Load(res); err != nil {
+		res.variableSource = describeSource("loader", loader)
+		err := loader.Load(res)
+		res.variableSource = ""
+		if err != nil {
 			return nil, nil, fmt.Errorf("variable loader failed: %w", err)
 		}
 	}
 
+	// Compute derived variables from the loaded ones
+	if err := res.runPostProcessors(res); err != nil {
+		return nil, nil, err
+	}
+	res.loadingVariables = false
+
+	// Run the planned factories, which may read the variables
+	if err := res.runPlannedFactories(&deferred); err != nil {
+		return nil, nil, err
+	}
+
 	// Run starters - these can register more components
-	if err := res.runStarters(); err != nil {
+	if err := res.runStarters(deferred); err != nil {
+		return nil, nil, err
+	}
+
+	// Report invalid registrations at once; strict mode refuses to start
+	if err := res.checkRegistrations(); err != nil {
+		return nil, nil, err
+	}
+
+	if err := res.checkDeclaredVariables(res); err != nil {
+		return nil, nil, err
+	}
+
+	if err := res.validateModules(); err != nil {
 		return nil, nil, err
 	}
 
@@ -221,13 +574,17 @@ func New(ctx context.Context, cfg *Config, block func(ContextBuilder)) (Applicat
 	// Set up component initializer
 	res.componentInit = newComponentInitializer(res, compRegistry, res.dependencyResolver, metricsCollector, logger)
 
-	// Initialize all components
-	if err := res.componentInit.InitializeAll(); err != nil {
+	// Initialize all components, or only the requested subset
+	if subset != nil {
+		if err := res.componentInit.Initialize(subset); err != nil {
+			return nil, nil, err
+		}
+	} else if err := res.componentInit.InitializeAll(); err != nil {
 		return nil, nil, err
 	}
 
 	// Set up lifecycle manager with initialization order
-	res.lifecycleManager = newLifecycleManager(compRegistry, res.componentInit.GetInitOrder(), metricsCollector, logger)
+	res.lifecycleManager = newLifecycleManager(compRegistry, res.dependencyResolver, res.componentInit.GetInitOrder(), metricsCollector, res.states, cfg.Clock, cfg.StopBudget, cfg.JobStore, cfg.StateStore, res.shutdownHooks, res, logger)
 
 	// Start all components
 	if err := res.lifecycleManager.StartAll(ctx); err != nil {
@@ -236,8 +593,14 @@ func New(ctx context.Context, cfg *Config, block func(ContextBuilder)) (Applicat
 		return nil, nil, err
 	}
 
+	// Verify the external resources before the container is ready
+	if err := res.runEagerChecks(ctx); err != nil {
+		res.lifecycleManager.StopAll(ctx)
+		return nil, nil, err
+	}
+
 	logger.Info("Container started",
-		"components", len(compRegistry.GetAll()),
+		"components", len(res.componentInit.GetInitOrder()),
 		"startup_ms", time.Since(startTime).Milliseconds())
 
 	// Return context and shutdown function
@@ -246,6 +609,23 @@ func New(ctx context.Context, cfg *Config, block func(ContextBuilder)) (Applicat
 	}, nil
 }
 
+// RegisterShutdownHook adds a hook run when the container shuts down
+func (c *container) RegisterShutdownHook(name string, fn func(context.Context), opts ...ShutdownHookOption) {
+	c.shutdownHooks.register(name, fn, opts...)
+}
+
+// Shutdown stops the components of a container created by New, like its shutdown
+// function, passing ctx to their Stop methods instead of the container's context,
+// e.g. to bound the shutdown by a deadline
+func Shutdown(ctx context.Context, app ApplicationContext) error {
+	c, ok := app.(*container)
+	if !ok || c.lifecycleManager == nil {
+		return ErrorWithCode("UNSUPPORTED_CONTEXT", "%T is not a container created by New", app)
+	}
+	c.lifecycleManager.StopAll(ctx)
+	return nil
+}
+
 // Start initializes the container and starts all components
 func Start(ctx context.Context, block func(ContextBuilder)) (ApplicationContext, func()) {
 	app, shutdown, err := New(ctx, DefaultConfig(), block)
@@ -0,0 +1,259 @@
+package container
+
+import (
+	"encoding"
+	"fmt"
+	"net/url"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ConverterFunc converts a string variable into a value of the registered type
+type ConverterFunc func(value string) (interface{}, error)
+
+// ConverterRegistry holds custom string→type conversions used when binding
+// variables to structs (GetStruct, GetVariableAs, RegisterConfigProperties).
+// Types implementing encoding.TextUnmarshaler don't need a converter.
+type ConverterRegistry struct {
+	mu         sync.RWMutex
+	converters map[reflect.Type]ConverterFunc
+}
+
+// ConverterSource is implemented by contexts that use their own converter registry
+type ConverterSource interface {
+	// GetConverters returns the converters used to bind variables
+	GetConverters() *ConverterRegistry
+}
+
+// DefaultConverters is used by contexts without their own registry. It converts
+// time.Duration, url.URL, *url.URL and time.Time (RFC 3339) out of the box.
+var DefaultConverters = NewConverterRegistry()
+
+// NewConverterRegistry creates a registry with the built-in converters
+func NewConverterRegistry() *ConverterRegistry {
+	r := &ConverterRegistry{converters: make(map[reflect.Type]ConverterFunc)}
+	RegisterConverter(r, time.ParseDuration)
+	RegisterConverter(r, url.Parse)
+	RegisterConverter(r, func(s string) (url.URL, error) {
+		u, err := url.Parse(s)
+		if err != nil {
+			return url.URL{}, err
+		}
+		return *u, nil
+	})
+	RegisterConverter(r, func(s string) (time.Time, error) {
+		return time.Parse(time.RFC3339, s)
+	})
+	return r
+}
+
+// RegisterConverter registers a type-safe converter for T:
+//
+//	container.RegisterConverter(container.DefaultConverters, func(s string) (Level, error) {
+//		return ParseLevel(s)
+//	})
+func RegisterConverter[T any](r *ConverterRegistry, fn func(string) (T, error)) {
+	r.Register(reflect.TypeOf((*T)(nil)).Elem(), func(value string) (interface{}, error) {
+		return fn(value)
+	})
+}
+
+// Register adds or replaces the converter for typ
+func (r *ConverterRegistry) Register(typ reflect.Type, fn ConverterFunc) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.converters[typ] = fn
+}
+
+// Lookup returns the converter for typ, if any
+func (r *ConverterRegistry) Lookup(typ reflect.Type) (ConverterFunc, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	fn, ok := r.converters[typ]
+	return fn, ok
+}
+
+// Convert converts value into target (a pointer) using the registered converters,
+// encoding.TextUnmarshaler and YAML conversion of scalars. Maps bind to structs
+// and maps field by field, so nested fields use converters too.
+func (r *ConverterRegistry) Convert(value interface{}, target interface{}) error {
+	return r.convert(value, target, "")
+}
+
+// convert is Convert with name as the path prefix in error messages
+func (r *ConverterRegistry) convert(value interface{}, target interface{}, name string) error {
+	rv := reflect.ValueOf(target)
+	if target == nil || rv.Kind() != reflect.Pointer || rv.IsNil() {
+		return ErrorWithCode("TARGET_NOT_POINTER", "target must be a non-nil pointer")
+	}
+	return r.decode(value, rv.Elem(), name)
+}
+
+var (
+	textUnmarshalerType = reflect.TypeOf((*encoding.TextUnmarshaler)(nil)).Elem()
+	yamlUnmarshalerType = reflect.TypeOf((*yaml.Unmarshaler)(nil)).Elem()
+)
+
+// decode stores value into the addressable out; path is used in error messages
+func (r *ConverterRegistry) decode(value interface{}, out reflect.Value, path string) error {
+	if value == nil {
+		return nil
+	}
+
+	// Custom converters and text unmarshalers take precedence for strings
+	if str, ok := value.(string); ok {
+		if fn, ok := r.Lookup(out.Type()); ok {
+			converted, err := fn(str)
+			if err != nil {
+				return conversionError(path, out.Type(), err)
+			}
+			out.Set(reflect.ValueOf(converted))
+			return nil
+		}
+		if out.Kind() != reflect.Pointer && reflect.PointerTo(out.Type()).Implements(textUnmarshalerType) {
+			if err := out.Addr().Interface().(encoding.TextUnmarshaler).UnmarshalText([]byte(str)); err != nil {
+				return conversionError(path, out.Type(), err)
+			}
+			return nil
+		}
+	}
+
+	// Types with their own YAML decoding keep it
+	if reflect.PointerTo(out.Type()).Implements(yamlUnmarshalerType) {
+		return decodeYaml(value, out, path)
+	}
+
+	switch out.Kind() {
+	case reflect.Pointer:
+		elem := reflect.New(out.Type().Elem())
+		if !out.IsNil() {
+			elem.Elem().Set(out.Elem())
+		}
+		if err := r.decode(value, elem.Elem(), path); err != nil {
+			return err
+		}
+		out.Set(elem)
+		return nil
+	case reflect.Struct:
+		if fields, ok := stringKeyMap(value); ok {
+			return r.decodeStruct(fields, out, path)
+		}
+	case reflect.Map:
+		if entries, ok := stringKeyMap(value); ok && out.Type().Key().Kind() == reflect.String {
+			if out.IsNil() {
+				out.Set(reflect.MakeMapWithSize(out.Type(), len(entries)))
+			}
+			for key, entry := range entries {
+				elem := reflect.New(out.Type().Elem()).Elem()
+				if err := r.decode(entry, elem, joinPath(path, key)); err != nil {
+					return err
+				}
+				out.SetMapIndex(reflect.ValueOf(key).Convert(out.Type().Key()), elem)
+			}
+			return nil
+		}
+	case reflect.Slice:
+		if items, ok := value.([]interface{}); ok {
+			slice := reflect.MakeSlice(out.Type(), len(items), len(items))
+			for i, item := range items {
+				if err := r.decode(item, slice.Index(i), fmt.Sprintf("%s[%d]", path, i)); err != nil {
+					return err
+				}
+			}
+			out.Set(slice)
+			return nil
+		}
+	}
+
+	return decodeYaml(value, out, path)
+}
+
+// decodeStruct binds map entries to the struct fields, using yaml tags for the keys
+func (r *ConverterRegistry) decodeStruct(fields map[string]interface{}, out reflect.Value, path string) error {
+	t := out.Type()
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		key, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") {
+			if err := r.decode(fields, out.Field(i), path); err != nil {
+				return err
+			}
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+
+		if value, ok := fields[key]; ok {
+			if err := r.decode(value, out.Field(i), joinPath(path, key)); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// decodeYaml lets YAML convert value into out. Strings (e.g. from the environment)
+// are parsed as YAML scalars so "8080" converts to an int.
+func decodeYaml(value interface{}, out reflect.Value, path string) error {
+	if v := reflect.ValueOf(value); v.Type().AssignableTo(out.Type()) {
+		out.Set(v)
+		return nil
+	}
+
+	var data []byte
+	if str, ok := value.(string); ok && out.Kind() != reflect.String {
+		data = []byte(str)
+	} else {
+		var err error
+		if data, err = yaml.Marshal(value); err != nil {
+			return conversionError(path, out.Type(), err)
+		}
+	}
+	if err := yaml.Unmarshal(data, out.Addr().Interface()); err != nil {
+		return conversionError(path, out.Type(), err)
+	}
+	return nil
+}
+
+// stringKeyMap returns value as a map with string keys if it is a map
+func stringKeyMap(value interface{}) (map[string]interface{}, bool) {
+	switch v := value.(type) {
+	case map[string]interface{}:
+		return v, true
+	case map[interface{}]interface{}:
+		result := make(map[string]interface{}, len(v))
+		for mk, mv := range v {
+			if strKey, ok := mk.(string); ok {
+				result[strKey] = mv
+			}
+		}
+		return result, true
+	}
+	return nil, false
+}
+
+func joinPath(path, key string) string {
+	if path == "" {
+		return key
+	}
+	return path + "." + key
+}
+
+func conversionError(path string, typ reflect.Type, err error) error {
+	if path == "" {
+		return ConfigurationError(fmt.Sprintf("cannot convert value to %s", typ), err)
+	}
+	return ConfigurationError(fmt.Sprintf("cannot convert %s to %s", path, typ), err)
+}
@@ -0,0 +1,130 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+	"reflect"
+	"sort"
+	"time"
+)
+
+// VarType is the type of a declared variable
+type VarType string
+
+// Types of declared variables
+const (
+	String   VarType = "string"
+	Int      VarType = "int"
+	Float    VarType = "float"
+	Bool     VarType = "bool"
+	Duration VarType = "duration"
+	// List is a list of strings
+	List VarType = "list"
+)
+
+// varTypes are the Go types declared variables convert to
+var varTypes = map[VarType]reflect.Type{
+	String:   reflect.TypeOf(""),
+	Int:      reflect.TypeOf(0),
+	Float:    reflect.TypeOf(0.0),
+	Bool:     reflect.TypeOf(false),
+	Duration: reflect.TypeOf(time.Duration(0)),
+	List:     reflect.TypeOf([]string(nil)),
+}
+
+// VarSpec declares a variable the application expects; see
+// ContextBuilder.DeclareVariable
+type VarSpec struct {
+	// Type the value must convert to (String if empty)
+	Type VarType
+	// Default is registered as a default variable when not nil
+	Default interface{}
+	// Required variables must be set, by a default or any other source
+	Required    bool
+	Description string
+}
+
+// VariableDeclaration is a declared variable
+type VariableDeclaration struct {
+	Name string
+	VarSpec
+}
+
+// VariableDeclarations is implemented by contexts listing their declared variables,
+// e.g. for the dashboard or generated documentation
+type VariableDeclarations interface {
+	// GetVariableDeclarations returns the declared variables sorted by name
+	GetVariableDeclarations() []VariableDeclaration
+}
+
+// DeclareVariable declares a variable checked when the container starts and when
+// variables are reloaded. Its default is registered with RegisterDefaultVariable.
+func (c *container) DeclareVariable(name string, spec VarSpec) {
+	if spec.Type == "" {
+		spec.Type = String
+	}
+	t, ok := varTypes[spec.Type]
+	if !ok {
+		c.recordRegistration(fmt.Errorf("variable %s declared with unknown type %s", name, spec.Type))
+		return
+	}
+	if previous, ok := c.declarations[name]; ok && !reflect.DeepEqual(previous, spec) {
+		c.recordRegistration(fmt.Errorf("variable %s declared twice with different specs", name))
+		return
+	}
+	if spec.Default != nil {
+		if err := NewVariableHelper(c).converters().decode(spec.Default, reflect.New(t).Elem(), name); err != nil {
+			c.recordRegistration(fmt.Errorf("default of variable %s is not a valid %s", name, spec.Type))
+			return
+		}
+		c.RegisterDefaultVariable(name, spec.Default)
+	}
+
+	if c.declarations == nil {
+		c.declarations = make(map[string]VarSpec)
+	}
+	c.declarations[name] = spec
+}
+
+// GetVariableDeclarations returns the declared variables sorted by name
+func (c *container) GetVariableDeclarations() []VariableDeclaration {
+	declarations := make([]VariableDeclaration, 0, len(c.declarations))
+	for name, spec := range c.declarations {
+		declarations = append(declarations, VariableDeclaration{Name: name, VarSpec: spec})
+	}
+	sort.Slice(declarations, func(i, j int) bool {
+		return declarations[i].Name < declarations[j].Name
+	})
+	return declarations
+}
+
+// checkDeclaredVariables checks that the required declared variables are set and
+// that the declared variables resolve and convert to their type, reading them
+// from vars
+func (c *container) checkDeclaredVariables(vars ApplicationContext) error {
+	var problems []string
+	helper := NewVariableHelper(vars)
+	for _, declaration := range c.GetVariableDeclarations() {
+		var failed *ContainerError
+		if err := helper.expressionError(declaration.Name); errors.As(err, &failed) {
+			problems = append(problems, fmt.Sprintf("%s: %v", failed.Message, failed.Cause))
+			continue
+		}
+		value := vars.GetVariableRaw(declaration.Name)
+		if value == nil {
+			if declaration.Required {
+				problems = append(problems, fmt.Sprintf("%s is required", declaration.Name))
+			}
+			continue
+		}
+		target := reflect.New(varTypes[declaration.Type]).Elem()
+		if err := helper.converters().decode(value, target, declaration.Name); err != nil {
+			problems = append(problems, fmt.Sprintf("%s is not a valid %s", declaration.Name, declaration.Type))
+		}
+	}
+
+	if len(problems) > 0 {
+		return InvalidVariablesError(problems)
+	}
+	return nil
+}
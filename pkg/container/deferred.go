@@ -0,0 +1,106 @@
+package container
+
+import (
+	"errors"
+	"fmt"
+)
+
+// deferredRegistration is a factory or starter that failed looking up a component
+// that wasn't registered yet, e.g. one a later conditional starter registers
+type deferredRegistration struct {
+	kind string
+	name string
+	run  func() error
+	// missing is the component whose lookup failed, err the failure
+	missing string
+	err     error
+}
+
+// registrationMark counts what factories and starters register besides components,
+// so that the registrations of a deferred one can be rolled back before its retry
+type registrationMark struct {
+	starters, factories, loaders, postProcessors, modules, errors, hooks int
+}
+
+func (c *container) markRegistrations() registrationMark {
+	return registrationMark{
+		starters:       len(c.starters),
+		factories:      len(c.factories),
+		loaders:        len(c.variablesLoaders),
+		postProcessors: len(c.postProcessors),
+		modules:        len(c.modules),
+		errors:         len(c.registrationErrors),
+		hooks:          c.shutdownHooks.count(),
+	}
+}
+
+// rollbackRegistrations drops what was registered since mark
+func (c *container) rollbackRegistrations(mark registrationMark) {
+	c.starters = c.starters[:mark.starters]
+	c.factories = c.factories[:mark.factories]
+	c.variablesLoaders = c.variablesLoaders[:mark.loaders]
+	c.postProcessors = c.postProcessors[:mark.postProcessors]
+	c.modules = c.modules[:mark.modules]
+	c.registrationErrors = c.registrationErrors[:mark.errors]
+	c.shutdownHooks.truncate(mark.hooks)
+}
+
+// runRegistration runs a factory or starter. When it failed on a component looked
+// up by name before registering any component, what else it registered is rolled
+// back and it is deferred until the remaining factories and starters ran; any
+// other failure fails startup.
+func (c *container) runRegistration(kind, name string, run func() error, deferred *[]*deferredRegistration) error {
+	version := c.componentRegistry.Version()
+	mark := c.markRegistrations()
+	c.registering, c.missing = true, ""
+	previous := c.registrar
+	c.registrar = kind + " " + name
+	err := run()
+	c.registering, c.registrar = false, previous
+	if err == nil {
+		return nil
+	}
+
+	var containerErr *ContainerError
+	if c.missing != "" && c.componentRegistry.Version() == version &&
+		errors.As(err, &containerErr) && containerErr.Code == "COMPONENT_NOT_FOUND" {
+		c.logger.Info("Deferring until more components are registered", "kind", kind, "name", name, "missing", c.missing)
+		c.rollbackRegistrations(mark)
+		*deferred = append(*deferred, &deferredRegistration{kind: kind, name: name, run: run, missing: c.missing, err: err})
+		return nil
+	}
+	return fmt.Errorf("%s %s failed: %w", kind, name, err)
+}
+
+// retryDeferred runs the deferred factories and starters again, along with the
+// starters they register, as long as a round registers components. The ones still
+// missing a component then fail startup.
+func (c *container) retryDeferred(deferred []*deferredRegistration, next int) error {
+	for len(deferred) > 0 {
+		version := c.componentRegistry.Version()
+		var again []*deferredRegistration
+		for _, d := range deferred {
+			c.logger.Debug("Retrying deferred registration", "kind", d.kind, "name", d.name, "missing", d.missing)
+			if err := c.runRegistration(d.kind, d.name, d.run, &again); err != nil {
+				return err
+			}
+		}
+		var err error
+		if next, err = c.runStartersFrom(next, &again); err != nil {
+			return err
+		}
+
+		if len(again) == 0 {
+			return nil
+		}
+		if c.componentRegistry.Version() == version {
+			problems := make([]string, len(again))
+			for i, d := range again {
+				problems[i] = fmt.Sprintf("%s %s needs component '%s'", d.kind, d.name, d.missing)
+			}
+			return UnregisteredComponentError(problems, again[0].err)
+		}
+		deferred = again
+	}
+	return nil
+}
@@ -0,0 +1,47 @@
+package container
+
+import (
+	"context"
+	"testing"
+)
+
+type deferredTestDB struct{ ComponentBase }
+
+func TestDeferredStarterRollsBackRegistrations(t *testing.T) {
+	nestedRuns := 0
+	cfg := DefaultConfig()
+	cfg.DefaultVariableLoaders = nil
+	app, stop, err := New(context.Background(), cfg, func(builder ContextBuilder) {
+		builder.RegisterStarter(NewStarter("repo", func(builder ContextBuilder) error {
+			builder.RegisterStarter(NewStarter("nested", func(ContextBuilder) error {
+				nestedRuns++
+				return nil
+			}))
+			builder.RegisterShutdownHook("repo", func(context.Context) {})
+			builder.AddVariableLoader(MapVariableLoader{})
+			_, err := GetComponentAs[*deferredTestDB](builder, "db")
+			return err
+		}))
+		builder.RegisterStarter(NewStarter("db", func(builder ContextBuilder) error {
+			return builder.RegisterComponent(&deferredTestDB{ComponentBase: NewComponentBase("db")})
+		}))
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer stop()
+
+	c := app.(*container)
+	if nestedRuns != 1 {
+		t.Errorf("nested starter ran %d times, want 1", nestedRuns)
+	}
+	if n := len(c.starters); n != 3 {
+		t.Errorf("%d starters registered, want 3", n)
+	}
+	if n := len(c.variablesLoaders); n != 1 {
+		t.Errorf("%d variable loaders registered, want 1", n)
+	}
+	if n := c.shutdownHooks.count(); n != 1 {
+		t.Errorf("%d shutdown hooks registered, want 1", n)
+	}
+}
@@ -1,8 +1,12 @@
 package container
 
 import (
+	"context"
+	"errors"
+	"io"
 	"log/slog"
 	"reflect"
+	"sort"
 	"time"
 )
 
@@ -18,8 +22,11 @@ type accessTrackingContext struct {
 	container     ApplicationContext
 	componentName string
 	accessedDeps  map[string]bool
-	logger        *slog.Logger
-	compRegistry  ComponentRegistry
+	// accessedVars are the variables read, documented as the component's config keys
+	accessedVars map[string]bool
+	violation    error
+	logger       *slog.Logger
+	compRegistry ComponentRegistry
 }
 
 func newAccessTrackingContext(container ApplicationContext, componentName string, logger *slog.Logger, registry ComponentRegistry) *accessTrackingContext {
@@ -27,6 +34,7 @@ func newAccessTrackingContext(container ApplicationContext, componentName string
 		container:     container,
 		componentName: componentName,
 		accessedDeps:  make(map[string]bool),
+		accessedVars:  make(map[string]bool),
 		logger:        logger,
 		compRegistry:  registry,
 	}
@@ -35,7 +43,7 @@ func newAccessTrackingContext(container ApplicationContext, componentName string
 func (a *accessTrackingContext) GetComponent(target interface{}) error {
 	// Get target type
 	targetType := reflect.TypeOf(target)
-	if targetType.Kind() != reflect.Ptr {
+	if targetType == nil || targetType.Kind() != reflect.Ptr {
 		return ErrorWithCode("TARGET_NOT_POINTER", "target must be a pointer")
 	}
 
@@ -43,66 +51,44 @@ func (a *accessTrackingContext) GetComponent(target interface{}) error {
 	elemType := targetType.Elem()
 	targetValue := reflect.ValueOf(target).Elem()
 
-	// First try direct match with exact type name
-	components := a.compRegistry.GetAll()
-	for name, comp := range components {
-		compType := reflect.TypeOf(comp)
-
-		// Try exact type match first
-		if compType == elemType || compType == reflect.PtrTo(elemType) {
-			// Don't allow a component to access itself during dependency discovery
-			if name == a.componentName {
-				return CircularDependencyError([]string{name, name})
-			}
+	matches := findTypeMatches(a.compRegistry, elemType)
+	if len(matches) == 0 {
+		return ErrorWithCode("COMPONENT_TYPE_NOT_FOUND", "no component found matching type %v", elemType)
+	}
 
-			// Track dependency
-			a.accessedDeps[name] = true
-			a.logger.Debug("Component dependency detected via exact type match",
-				"component", a.componentName,
-				"depends_on", name,
-				"type", elemType.String())
-
-			// Always set the value for both discovery and initialization phases
-			if targetValue.Kind() == reflect.Ptr {
-				// For pointer targets like **TestComponent
-				targetValue.Set(reflect.ValueOf(comp))
-			} else if targetValue.Kind() == reflect.Struct && compType.Kind() == reflect.Ptr {
-				// For struct targets and pointer components like TestComponent and *TestComponent
-				targetValue.Set(reflect.ValueOf(comp).Elem())
-			} else {
-				// For other cases, try direct assignment
-				targetValue.Set(reflect.ValueOf(comp))
-			}
-			return nil
+	// Don't allow a component to access itself during dependency discovery
+	var others []typeMatch
+	for _, m := range matches {
+		if m.name != a.componentName {
+			others = append(others, m)
 		}
 	}
+	if len(others) == 0 {
+		return CircularDependencyError([]string{a.componentName, a.componentName})
+	}
 
-	// Then try assignable types for interface support
-	for name, comp := range components {
-		compType := reflect.TypeOf(comp)
-
-		// Check if the component type is assignable to the target type
-		if compType.AssignableTo(elemType) {
-			// Don't allow a component to access itself during dependency discovery
-			if name == a.componentName {
-				return CircularDependencyError([]string{name, name})
-			}
-
-			// Track dependency for this discovered match
-			a.accessedDeps[name] = true
-			a.logger.Debug("Component dependency detected via assignable type",
-				"component", a.componentName,
-				"depends_on", name,
-				"type", elemType.String(),
-				"comp_type", compType.String())
-
-			// Always set the value for both discovery and initialization phases
-			targetValue.Set(reflect.ValueOf(comp))
-			return nil
-		}
+	// Components private to another module are invisible to type lookups
+	visible := visibleMatches(a.compRegistry, a.componentName, others)
+	if len(visible) == 0 {
+		owner, _ := a.compRegistry.GetModule(others[0].name)
+		return a.violate(ModuleVisibilityError(a.componentName, others[0].name, owner))
+	}
+	match, err := selectTypeMatch(visible, elemType)
+	if err != nil {
+		return err
 	}
 
-	return ErrorWithCode("COMPONENT_TYPE_NOT_FOUND", "no component found matching type %v", elemType)
+	// Track dependency
+	a.accessedDeps[match.name] = true
+	a.logger.Debug("Component dependency detected by type",
+		"component", a.componentName,
+		"depends_on", match.name,
+		"type", elemType.String(),
+		"exact", match.exact)
+
+	// Always set the value for both discovery and initialization phases
+	match.assign(targetValue)
+	return nil
 }
 
 func (a *accessTrackingContext) GetComponentByName(name string) (Component, error) {
@@ -111,6 +97,11 @@ func (a *accessTrackingContext) GetComponentByName(name string) (Component, erro
 		return nil, CircularDependencyError([]string{name, name})
 	}
 
+	if a.compRegistry.Has(name) && !canAccess(a.compRegistry, a.componentName, name) {
+		owner, _ := a.compRegistry.GetModule(name)
+		return nil, a.violate(ModuleVisibilityError(a.componentName, name, owner))
+	}
+
 	// Track that this component was accessed
 	a.accessedDeps[name] = true
 
@@ -129,14 +120,74 @@ func (a *accessTrackingContext) GetComponentByName(name string) (Component, erro
 	return comp, nil
 }
 
+// violate remembers the first module visibility violation; unlike other lookup
+// failures during discovery, it fails startup
+func (a *accessTrackingContext) violate(err *ContainerError) error {
+	if a.violation == nil {
+		a.violation = err
+	}
+	return err
+}
+
 func (a *accessTrackingContext) GetVariable(name string) string {
+	a.accessedVars[name] = true
 	return a.container.GetVariable(name)
 }
 
 func (a *accessTrackingContext) GetVariableRaw(name string) interface{} {
+	a.accessedVars[name] = true
 	return a.container.GetVariableRaw(name)
 }
 
+func (a *accessTrackingContext) GetVariableAs(name string, target interface{}) error {
+	a.accessedVars[name] = true
+	return a.container.GetVariableAs(name, target)
+}
+
+func (a *accessTrackingContext) HasVariable(name string) bool {
+	a.accessedVars[name] = true
+	return a.container.HasVariable(name)
+}
+
+// variablesWithPrefix records the section read, e.g. "pool.*" for GetStruct("pool")
+func (a *accessTrackingContext) variablesWithPrefix(prefix string) map[string]interface{} {
+	a.accessedVars[prefix+"*"] = true
+	if source, ok := a.container.(prefixVariableSource); ok {
+		return source.variablesWithPrefix(prefix)
+	}
+	return NewVariableHelper(a.container).variablesWithPrefix(prefix)
+}
+
+func (a *accessTrackingContext) variableExpressionError(name string) error {
+	return NewVariableHelper(a.container).expressionError(name)
+}
+
+func (a *accessTrackingContext) GetVariables() map[string]interface{} {
+	if source, ok := a.container.(VariableSource); ok {
+		return source.GetVariables()
+	}
+	return nil
+}
+
+// RegisterShutdownHook ignores the hooks registered during dependency discovery;
+// the component registers them again in its real Init
+func (a *accessTrackingContext) RegisterShutdownHook(string, func(context.Context), ...ShutdownHookOption) {
+}
+
+// ReloadVariables reloads the variables of the container
+func (a *accessTrackingContext) ReloadVariables() ([]string, error) {
+	return a.container.ReloadVariables()
+}
+
+// DumpConfig dumps the configuration of the container
+func (a *accessTrackingContext) DumpConfig(w io.Writer, format DumpFormat) error {
+	return a.container.DumpConfig(w, format)
+}
+
+func (a *accessTrackingContext) GetActiveProfiles() []string {
+	return a.container.GetActiveProfiles()
+}
+
 func (a *accessTrackingContext) HasComponent(name string) bool {
 	// Track component checking as well
 	exists := a.container.HasComponent(name)
@@ -146,10 +197,32 @@ func (a *accessTrackingContext) HasComponent(name string) bool {
 	return exists
 }
 
+func (a *accessTrackingContext) GetComponentsByTag(tag string) []Component {
+	var result []Component
+	for _, comp := range a.compRegistry.GetByTag(tag) {
+		// A component doesn't depend on itself even if it carries the tag
+		if comp.Name() == a.componentName || !canAccess(a.compRegistry, a.componentName, comp.Name()) {
+			continue
+		}
+		a.accessedDeps[comp.Name()] = true
+		result = append(result, comp)
+	}
+	return result
+}
+
 func (a *accessTrackingContext) GetComponentNames() []string {
 	return a.container.GetComponentNames()
 }
 
+// FindComponents describes the components without recording dependencies; the
+// components looked up afterwards are recorded
+func (a *accessTrackingContext) FindComponents(pred func(ComponentInfo) bool) []ComponentInfo {
+	if inspector, ok := a.container.(ContainerInspector); ok {
+		return inspector.FindComponents(pred)
+	}
+	return nil
+}
+
 func (a *accessTrackingContext) GetMetrics() map[string]*ComponentMetrics {
 	return nil
 }
@@ -159,8 +232,10 @@ type defaultDependencyResolver struct {
 	container    *container
 	registry     ComponentRegistry
 	dependencies map[string]map[string]bool
-	metrics      MetricsCollector
-	logger       *slog.Logger
+	// variables are the variables each component read during discovery
+	variables map[string][]string
+	metrics   MetricsCollector
+	logger    *slog.Logger
 }
 
 func newDependencyResolver(container *container, registry ComponentRegistry, metrics MetricsCollector, logger *slog.Logger) *defaultDependencyResolver {
@@ -168,12 +243,18 @@ func newDependencyResolver(container *container, registry ComponentRegistry, met
 		container:    container,
 		registry:     registry,
 		dependencies: make(map[string]map[string]bool),
+		variables:    make(map[string][]string),
 		metrics:      metrics,
 		logger:       logger,
 	}
 }
 
 func (r *defaultDependencyResolver) discoverComponentDependencies(name string) (map[string]bool, error) {
+	// Components of the parent container were wired by the parent
+	if r.container.isInherited(name) {
+		return map[string]bool{}, nil
+	}
+
 	comp, err := r.registry.Get(name)
 	if err != nil {
 		return nil, err
@@ -186,10 +267,29 @@ func (r *defaultDependencyResolver) discoverComponentDependencies(name string) (
 	// This won't actually initialize the component fully, just track dependencies
 	start := time.Now()
 	r.logger.Debug("Discovering dependencies", "component", name)
-	_ = comp.Init(tracker) // Ignore errors during dependency discovery phase
+	// Ignore errors during dependency discovery phase; panics are common here because
+	// GetComponentByName returns nil for components that aren't resolved yet
+	if err := safeInit(comp, tracker); err != nil {
+		var panicErr *PanicError
+		if errors.As(err, &panicErr) {
+			r.logger.Debug("Recovered panic during dependency discovery",
+				"component", name,
+				"panic", panicErr.Value)
+		}
+	}
+
+	if tracker.violation != nil {
+		return nil, tracker.violation
+	}
+
+	// Planned factories declare dependencies passed to constructors
+	for _, dep := range r.container.declaredDependencies[name] {
+		tracker.accessedDeps[dep] = true
+	}
 
 	// Record metrics
 	r.metrics.RecordDependencyCount(name, len(tracker.accessedDeps))
+	r.variables[name] = sortedKeys(tracker.accessedVars)
 
 	r.logger.Debug("Dependencies discovered",
 		"component", name,
@@ -200,33 +300,69 @@ func (r *defaultDependencyResolver) discoverComponentDependencies(name string) (
 	return tracker.accessedDeps, nil
 }
 
-func (r *defaultDependencyResolver) detectCycle(source, target string, visited map[string]bool, path []string) (bool, []string) {
-	if source == target {
-		return true, append(path, target)
-	}
+// findCycle returns the first dependency cycle in name order, or nil. A single
+// depth-first search keeps it linear in the size of the graph.
+func (r *defaultDependencyResolver) findCycle(names []string) []string {
+	const (
+		unvisited = iota
+		visiting
+		done
+	)
+	state := make(map[string]int, len(r.dependencies))
+	var path []string
+
+	var visit func(name string) []string
+	visit = func(name string) []string {
+		state[name] = visiting
+		path = append(path, name)
+
+		deps := make([]string, 0, len(r.dependencies[name]))
+		for dep := range r.dependencies[name] {
+			// Skip self-dependencies
+			if dep != name {
+				deps = append(deps, dep)
+			}
+		}
+		sort.Strings(deps)
+
+		for _, dep := range deps {
+			switch state[dep] {
+			case visiting:
+				// dep is on the path: the cycle runs from it back to itself
+				for i, n := range path {
+					if n == dep {
+						return append(append([]string(nil), path[i:]...), dep)
+					}
+				}
+			case unvisited:
+				if cycle := visit(dep); cycle != nil {
+					return cycle
+				}
+			}
+		}
 
-	if visited[target] {
-		return false, nil
+		path = path[:len(path)-1]
+		state[name] = done
+		return nil
 	}
 
-	visited[target] = true
-	path = append(path, target)
-
-	for dep := range r.dependencies[target] {
-		if hasCycle, cyclePath := r.detectCycle(source, dep, visited, path); hasCycle {
-			return true, cyclePath
+	for _, name := range names {
+		if state[name] == unvisited {
+			if cycle := visit(name); cycle != nil {
+				return cycle
+			}
 		}
 	}
-
-	return false, nil
+	return nil
 }
 
 func (r *defaultDependencyResolver) DiscoverDependencies() error {
 	// Discover dependencies for all components
 	r.logger.Info("Discovering component dependencies")
-	components := r.registry.GetAll()
+	names := r.registry.GetNames()
+	sort.Strings(names)
 
-	for name := range components {
+	for _, name := range names {
 		deps, err := r.discoverComponentDependencies(name)
 		if err != nil {
 			return err
@@ -234,20 +370,11 @@ func (r *defaultDependencyResolver) DiscoverDependencies() error {
 
 		// Store discovered dependencies
 		r.dependencies[name] = deps
+	}
 
-		// Check for cycles after adding each component
-		for dep := range deps {
-			// Skip self-dependencies
-			if dep == name {
-				continue
-			}
-
-			// Check if adding this dependency would create a cycle
-			hasCycle, cycle := r.detectCycle(name, dep, make(map[string]bool), []string{name})
-			if hasCycle {
-				return CircularDependencyError(cycle)
-			}
-		}
+	// Check for cycles once the whole graph is known
+	if cycle := r.findCycle(names); cycle != nil {
+		return CircularDependencyError(cycle)
 	}
 
 	return nil
@@ -264,6 +391,17 @@ func (r *defaultDependencyResolver) ValidateDependencies() error {
 	return nil
 }
 
+// addDependencies adds dependencies found after discovery, e.g. the lookups of a
+// supplier while it built its component
+func (r *defaultDependencyResolver) addDependencies(name string, deps map[string]bool) {
+	if r.dependencies[name] == nil {
+		r.dependencies[name] = make(map[string]bool, len(deps))
+	}
+	for dep := range deps {
+		r.dependencies[name][dep] = true
+	}
+}
+
 func (r *defaultDependencyResolver) GetDependencies(componentName string) map[string]bool {
 	deps, exists := r.dependencies[componentName]
 	if !exists {
@@ -0,0 +1,250 @@
+package container
+
+import (
+	"encoding/gob"
+	"encoding/json"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// MaskedValue replaces the values of variables whose names look like secrets
+const MaskedValue = "******"
+
+// secretNameParts mark variables whose values are masked
+var secretNameParts = []string{"password", "secret", "token", "credential", "private-key", "apikey", "api-key"}
+
+// IsSecretVariable reports whether a variable name looks like it holds a secret,
+// e.g. datasource.password or sentry.api-key
+func IsSecretVariable(name string) bool {
+	name = strings.ToLower(name)
+	for _, part := range secretNameParts {
+		if strings.Contains(name, part) {
+			return true
+		}
+	}
+	return false
+}
+
+// DumpFormat is the encoding of a configuration dump
+type DumpFormat string
+
+// Configuration dump formats
+const (
+	DumpJSON DumpFormat = "json"
+	DumpGob  DumpFormat = "gob"
+)
+
+// ConfigDump is the effective configuration of a container, e.g. to attach to a
+// support ticket; see ApplicationContext.DumpConfig
+type ConfigDump struct {
+	CreatedAt time.Time `json:"createdAt"`
+	Profiles  []string  `json:"profiles"`
+	// Variables are sorted by name, secrets masked
+	Variables []DumpedVariable `json:"variables"`
+}
+
+// DumpedVariable is a variable of a configuration dump
+type DumpedVariable struct {
+	Name string `json:"name"`
+	// Value is resolved like GetVariableRaw. Encode keeps the structure of maps and
+	// lists and formats values other than strings, numbers and booleans as strings.
+	Value interface{} `json:"value"`
+	// Origin is the source that registered the value, e.g. "loader ProfileYamlLoader(config)",
+	// "starter web", "setup" or "default (starter web)"
+	Origin string `json:"origin"`
+}
+
+func init() {
+	// Types of the variable values held in interfaces
+	gob.Register([]interface{}{})
+	gob.Register(map[string]interface{}{})
+}
+
+// DumpConfig writes the resolved variables with their origins and the active profiles
+func (c *container) DumpConfig(w io.Writer, format DumpFormat) error {
+	dump := ConfigDump{CreatedAt: c.config.Clock.Now(), Profiles: c.GetActiveProfiles()}
+
+	variables := c.variableRegistry.GetAll()
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		value := variables[name]
+		if IsSecretVariable(name) && value != nil {
+			value = MaskedValue
+		}
+		dump.Variables = append(dump.Variables, DumpedVariable{Name: name, Value: value, Origin: c.origins.of(name)})
+	}
+	return dump.Encode(w, format)
+}
+
+// Encode writes the dump in a format
+func (dump ConfigDump) Encode(w io.Writer, format DumpFormat) error {
+	variables := make([]DumpedVariable, len(dump.Variables))
+	for i, variable := range dump.Variables {
+		variable.Value = dumpValue(variable.Value)
+		variables[i] = variable
+	}
+	dump.Variables = variables
+
+	switch format {
+	case DumpJSON, "":
+		encoder := json.NewEncoder(w)
+		encoder.SetIndent("", "  ")
+		return encoder.Encode(dump)
+	case DumpGob:
+		return gob.NewEncoder(w).Encode(dump)
+	}
+	return ErrorWithCode("UNSUPPORTED_FORMAT", "unsupported configuration dump format %q", format)
+}
+
+// dumpValue converts a variable value to strings, numbers, booleans, lists and maps
+// with string keys, which both formats encode. Values of map keys that look like
+// secrets are masked, e.g. in a list of users with passwords.
+func dumpValue(value interface{}) interface{} {
+	switch v := value.(type) {
+	case nil, string, bool, int, int64, float64:
+		return v
+	case []interface{}:
+		list := make([]interface{}, len(v))
+		for i, item := range v {
+			list[i] = dumpValue(item)
+		}
+		return list
+	case map[string]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[key] = dumpEntry(key, item)
+		}
+		return m
+	case map[interface{}]interface{}:
+		m := make(map[string]interface{}, len(v))
+		for key, item := range v {
+			m[fmt.Sprint(key)] = dumpEntry(fmt.Sprint(key), item)
+		}
+		return m
+	}
+
+	if rv := reflect.ValueOf(value); rv.Kind() == reflect.Slice {
+		list := make([]interface{}, rv.Len())
+		for i := range list {
+			list[i] = dumpValue(rv.Index(i).Interface())
+		}
+		return list
+	}
+	return fmt.Sprint(value)
+}
+
+// dumpEntry converts the value of a map entry, masking it if the key looks like a secret
+func dumpEntry(key string, value interface{}) interface{} {
+	if IsSecretVariable(key) && value != nil {
+		return MaskedValue
+	}
+	return dumpValue(value)
+}
+
+// variableOrigin returns the source registering variables: the loader or
+// post-processor running, otherwise the starter or factory, otherwise the setup block
+func (c *container) variableOrigin() string {
+	if c.variableSource != "" {
+		return c.variableSource
+	}
+	if c.registrar != "" {
+		return c.registrar
+	}
+	return "setup"
+}
+
+// variableOrigins holds the sources of the variables and of their defaults
+type variableOrigins struct {
+	mu       sync.RWMutex
+	values   map[string]string
+	defaults map[string]string
+}
+
+func newVariableOrigins() *variableOrigins {
+	return &variableOrigins{values: make(map[string]string), defaults: make(map[string]string)}
+}
+
+// set records the origin of variables, or of their defaults
+func (o *variableOrigins) set(origin string, defaults bool, names ...string) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	target := o.values
+	if defaults {
+		target, origin = o.defaults, "default ("+origin+")"
+	}
+	for _, name := range names {
+		target[name] = origin
+	}
+}
+
+// of returns the origin of a variable's value
+func (o *variableOrigins) of(name string) string {
+	o.mu.RLock()
+	defer o.mu.RUnlock()
+	if origin, ok := o.values[name]; ok {
+		return origin
+	}
+	return o.defaults[name]
+}
+
+// merge records the origins of a reload
+func (o *variableOrigins) merge(reloaded *variableOrigins) {
+	o.mu.Lock()
+	defer o.mu.Unlock()
+	for name, origin := range reloaded.values {
+		o.values[name] = origin
+	}
+	for name, origin := range reloaded.defaults {
+		o.defaults[name] = origin
+	}
+}
+
+// describeSource names a variable loader or post-processor in origins, with the
+// file or directory it reads
+func describeSource(kind string, source interface{}) string {
+	var where string
+	switch s := source.(type) {
+	case ProfileYamlLoader:
+		where = s.ConfigPath
+	case *ProfileYamlLoader:
+		where = s.ConfigPath
+	case HclLoader:
+		where = s.ConfigPath
+	case *HclLoader:
+		where = s.ConfigPath
+	case ConfDirLoader:
+		where = s.Path
+	case *ConfDirLoader:
+		where = s.Path
+	case *ConfigFileLoader:
+		where = s.Path
+	case PropertiesVariableLoader:
+		where = s.Path
+	case *PropertiesVariableLoader:
+		where = s.Path
+	case IniLoader:
+		where = s.Path
+	case *IniLoader:
+		where = s.Path
+	case EnvironmentPostProcessor:
+		where = s.Name()
+	}
+
+	t := reflect.TypeOf(source)
+	for t.Kind() == reflect.Pointer {
+		t = t.Elem()
+	}
+	if where != "" {
+		return fmt.Sprintf("%s %s(%s)", kind, t.Name(), where)
+	}
+	return fmt.Sprintf("%s %s", kind, t.Name())
+}
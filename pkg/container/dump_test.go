@@ -0,0 +1,62 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"reflect"
+	"testing"
+)
+
+func TestDumpConfigMasksSecrets(t *testing.T) {
+	cfg := DefaultConfig()
+	cfg.DefaultVariableLoaders = nil
+	app, stop, err := New(context.Background(), cfg, func(builder ContextBuilder) {
+		builder.RegisterVariable("db.password", "s3cret")
+		builder.RegisterVariable("server.port", 8080)
+		builder.RegisterVariable("users", []interface{}{
+			map[string]interface{}{"name": "a", "password": "x"},
+			map[interface{}]interface{}{"name": "b", "api-key": "y", "roles": []interface{}{"admin"}},
+		})
+		builder.RegisterVariable("clients", map[string]interface{}{
+			"billing": map[string]interface{}{"url": "http://billing", "credentials": map[string]interface{}{"user": "u"}},
+		})
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer stop()
+
+	var buf bytes.Buffer
+	if err := app.DumpConfig(&buf, DumpJSON); err != nil {
+		t.Fatalf("DumpConfig() error = %v", err)
+	}
+	var dump ConfigDump
+	if err := json.Unmarshal(buf.Bytes(), &dump); err != nil {
+		t.Fatalf("decoding the dump: %v", err)
+	}
+	values := make(map[string]interface{})
+	for _, variable := range dump.Variables {
+		values[variable.Name] = variable.Value
+	}
+
+	want := map[string]interface{}{
+		"db.password": MaskedValue,
+		"server.port": 8080.0,
+		"users": []interface{}{
+			map[string]interface{}{"name": "a", "password": MaskedValue},
+			map[string]interface{}{"name": "b", "api-key": MaskedValue, "roles": []interface{}{"admin"}},
+		},
+		"clients": map[string]interface{}{
+			"billing": map[string]interface{}{"url": "http://billing", "credentials": MaskedValue},
+		},
+	}
+	for name, value := range want {
+		if !reflect.DeepEqual(values[name], value) {
+			t.Errorf("dumped %s = %#v, want %#v", name, values[name], value)
+		}
+	}
+	if bytes.Contains(buf.Bytes(), []byte(`"x"`)) || bytes.Contains(buf.Bytes(), []byte(`"y"`)) {
+		t.Errorf("dump contains a nested secret: %s", buf.String())
+	}
+}
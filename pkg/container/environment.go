@@ -0,0 +1,87 @@
+package container
+
+import (
+	"fmt"
+	"sort"
+)
+
+// EnvironmentPostProcessor computes variables from the loaded ones. Post-processors
+// run after all variable loaders and before the starters, so starter conditions and
+// components see the derived variables:
+//
+//	builder.AddEnvironmentPostProcessor(container.NewEnvironmentPostProcessor("dsn", 0,
+//		func(builder container.ContextBuilder, profiles []string) error {
+//			builder.RegisterVariable("db.dsn", fmt.Sprintf("postgres://%s@%s:%s",
+//				builder.GetVariable("db.user"), builder.GetVariable("db.host"), builder.GetVariable("db.port")))
+//			return nil
+//		}))
+type EnvironmentPostProcessor interface {
+	// Name returns the name of this post-processor
+	Name() string
+	// PostProcessEnvironment reads and registers variables; profiles are the active profiles
+	PostProcessEnvironment(builder ContextBuilder, profiles []string) error
+}
+
+// OrderedEnvironmentPostProcessor controls the order post-processors run in
+// (lower values run first; post-processors without an order use 0)
+type OrderedEnvironmentPostProcessor interface {
+	EnvironmentPostProcessor
+	// GetOrder returns the run order
+	GetOrder() int
+}
+
+// EnvironmentPostProcessorFunc is a simple implementation of OrderedEnvironmentPostProcessor using a function
+type EnvironmentPostProcessorFunc struct {
+	name  string
+	order int
+	fn    func(ContextBuilder, []string) error
+}
+
+// Name returns the name of the post-processor
+func (p *EnvironmentPostProcessorFunc) Name() string {
+	return p.name
+}
+
+// GetOrder returns the run order
+func (p *EnvironmentPostProcessorFunc) GetOrder() int {
+	return p.order
+}
+
+// PostProcessEnvironment calls the function
+func (p *EnvironmentPostProcessorFunc) PostProcessEnvironment(builder ContextBuilder, profiles []string) error {
+	return p.fn(builder, profiles)
+}
+
+// NewEnvironmentPostProcessor creates a post-processor with the given name, order and function
+func NewEnvironmentPostProcessor(name string, order int, fn func(ContextBuilder, []string) error) OrderedEnvironmentPostProcessor {
+	return &EnvironmentPostProcessorFunc{name: name, order: order, fn: fn}
+}
+
+// postProcessorOrder returns the run order of a post-processor
+func postProcessorOrder(p EnvironmentPostProcessor) int {
+	if ordered, ok := p.(OrderedEnvironmentPostProcessor); ok {
+		return ordered.GetOrder()
+	}
+	return 0
+}
+
+// runPostProcessors runs the environment post-processors by order, keeping the
+// registration order for equal orders
+func (c *container) runPostProcessors(builder ContextBuilder) error {
+	processors := append([]EnvironmentPostProcessor(nil), c.postProcessors...)
+	sort.SliceStable(processors, func(i, j int) bool {
+		return postProcessorOrder(processors[i]) < postProcessorOrder(processors[j])
+	})
+
+	profiles := builder.GetActiveProfiles()
+	for _, processor := range processors {
+		c.logger.Debug("Running environment post-processor", "name", processor.Name())
+		c.variableSource = describeSource("post-processor", processor)
+		err := processor.PostProcessEnvironment(builder, profiles)
+		c.variableSource = ""
+		if err != nil {
+			return fmt.Errorf("environment post-processor %s failed: %w", processor.Name(), err)
+		}
+	}
+	return nil
+}
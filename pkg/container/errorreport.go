@@ -0,0 +1,113 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ErrorKind tells where a reported error happened
+type ErrorKind string
+
+// Kinds of reported errors
+const (
+	// ErrorKindStart is a panic in a component's Start, which fails the startup
+	ErrorKindStart ErrorKind = "start"
+	// ErrorKindPanic is a panic in a background component's Run or in a Stop
+	ErrorKindPanic ErrorKind = "panic"
+	// ErrorKindScheduled is a panic in a scheduled component's Execute
+	ErrorKindScheduled ErrorKind = "scheduled"
+	// ErrorKindListener is an event listener that failed after its retries
+	ErrorKindListener ErrorKind = "listener"
+)
+
+// Metadata keys set by ReportError
+const (
+	ErrorMetadataComponentType = "component.type"
+	ErrorMetadataComponentTags = "component.tags"
+)
+
+// ErrorReport is an error of a container-managed goroutine
+type ErrorReport struct {
+	Kind      ErrorKind
+	Component string
+	Err       error
+	// Panicked is true when Err was recovered from a panic, Stack then holds the
+	// stack of the panicking goroutine
+	Panicked bool
+	Stack    []byte
+	Time     time.Time
+	// Metadata describes the component (see ErrorMetadataComponentType) and the
+	// failure, e.g. the phase or the event type
+	Metadata map[string]string
+}
+
+// ErrorReporter is a component receiving the errors of components that would
+// otherwise only be logged, e.g. to send them to an error tracker such as Sentry.
+// ReportError is called synchronously from the failing goroutine, concurrently
+// for several components, and should return quickly.
+type ErrorReporter interface {
+	ReportError(ctx context.Context, report ErrorReport)
+}
+
+// ErrorReporters returns the ErrorReporter components sorted by name
+func ErrorReporters(app ApplicationContext) []ErrorReporter {
+	names := app.GetComponentNames()
+	sort.Strings(names)
+	var reporters []ErrorReporter
+	for _, name := range names {
+		comp, err := app.GetComponentByName(name)
+		if err != nil || comp == nil {
+			continue
+		}
+		if reporter, ok := componentValue(comp).(ErrorReporter); ok {
+			reporters = append(reporters, reporter)
+		}
+	}
+	return reporters
+}
+
+// ReportError passes the report to every ErrorReporter component, adding the
+// time and the type and tags of the component. Panics of reporters are logged.
+func ReportError(ctx context.Context, app ApplicationContext, report ErrorReport) {
+	reporters := ErrorReporters(app)
+	if len(reporters) == 0 {
+		return
+	}
+
+	if report.Time.IsZero() {
+		report.Time = time.Now()
+	}
+	metadata := make(map[string]string, len(report.Metadata)+2)
+	for key, value := range report.Metadata {
+		metadata[key] = value
+	}
+	if report.Component != "" {
+		if comp, err := app.GetComponentByName(report.Component); err == nil && comp != nil {
+			metadata[ErrorMetadataComponentType] = fmt.Sprintf("%T", componentValue(comp))
+		}
+		if inspector, ok := app.(ContainerInspector); ok {
+			infos := inspector.FindComponents(func(info ComponentInfo) bool { return info.Name == report.Component })
+			if len(infos) == 1 && len(infos[0].Tags) > 0 {
+				metadata[ErrorMetadataComponentTags] = strings.Join(infos[0].Tags, ",")
+			}
+		}
+	}
+	report.Metadata = metadata
+
+	for _, reporter := range reporters {
+		reportSafely(ctx, reporter, report)
+	}
+}
+
+func reportSafely(ctx context.Context, reporter ErrorReporter, report ErrorReport) {
+	defer func() {
+		if r := recover(); r != nil {
+			slog.Default().Error("Panic in error reporter", "reporter", fmt.Sprintf("%T", reporter), "error", r)
+		}
+	}()
+	reporter.ReportError(ctx, report)
+}
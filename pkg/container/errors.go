@@ -57,6 +57,15 @@ func CircularDependencyError(cycle []string) *ContainerError {
 	}
 }
 
+// AmbiguousComponentError returns an error for when several components match a requested type
+func AmbiguousComponentError(typeName string, candidates []string) *ContainerError {
+	return &ContainerError{
+		Code: "AMBIGUOUS_COMPONENT",
+		Message: fmt.Sprintf("multiple components match type %s: %s; mark one as primary or look it up by name",
+			typeName, strings.Join(candidates, ", ")),
+	}
+}
+
 // ComponentInitializationError returns an error for when a component fails to initialize
 func ComponentInitializationError(name string, err error) *ContainerError {
 	return &ContainerError{
@@ -74,6 +83,43 @@ func ComponentTypeError(name string, expected, actual string) *ContainerError {
 	}
 }
 
+// InvalidRegistrationError returns an error listing registrations rejected in strict mode
+func InvalidRegistrationError(problems []string) *ContainerError {
+	return &ContainerError{
+		Code:    "INVALID_REGISTRATION",
+		Message: fmt.Sprintf("%d invalid registrations: %s", len(problems), strings.Join(problems, "; ")),
+	}
+}
+
+// UnregisteredComponentError returns an error for factories and starters that
+// looked up components no factory or starter registered, even after the others ran
+func UnregisteredComponentError(problems []string, cause error) *ContainerError {
+	return &ContainerError{
+		Code:    "UNREGISTERED_COMPONENT",
+		Message: fmt.Sprintf("components never registered: %s", strings.Join(problems, "; ")),
+		Cause:   cause,
+	}
+}
+
+// InvalidVariablesError returns an error listing the declared variables that are
+// missing, fail to resolve or don't convert to their type
+func InvalidVariablesError(problems []string) *ContainerError {
+	return &ContainerError{
+		Code:    "INVALID_VARIABLES",
+		Message: fmt.Sprintf("%d invalid variables: %s", len(problems), strings.Join(problems, "; ")),
+	}
+}
+
+// VariableExpressionError returns an error for a variable whose expression, e.g.
+// file(/run/secrets/key), fails to resolve
+func VariableExpressionError(name string, cause error) *ContainerError {
+	return &ContainerError{
+		Code:    "VARIABLE_EXPRESSION_FAILED",
+		Message: fmt.Sprintf("variable %s can't be resolved", name),
+		Cause:   cause,
+	}
+}
+
 // ConfigurationError returns an error for when configuration is invalid
 func ConfigurationError(msg string, cause error) *ContainerError {
 	return &ContainerError{
@@ -82,3 +128,14 @@ func ConfigurationError(msg string, cause error) *ContainerError {
 		Cause:   cause,
 	}
 }
+
+// PanicError wraps a value recovered from a panic inside a component
+type PanicError struct {
+	Value interface{}
+	Stack []byte
+}
+
+// Error implements the error interface
+func (e *PanicError) Error() string {
+	return fmt.Sprintf("panic: %v\n%s", e.Value, e.Stack)
+}
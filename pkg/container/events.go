@@ -0,0 +1,223 @@
+package container
+
+import (
+	"bufio"
+	"encoding/json"
+	"fmt"
+	"io"
+	"sort"
+	"sync"
+	"time"
+)
+
+// LifecyclePhase is a phase of a component's lifecycle
+type LifecyclePhase string
+
+// Lifecycle phases
+const (
+	PhaseInit  LifecyclePhase = "init"
+	PhaseStart LifecyclePhase = "start"
+	// PhaseWarmUp is the eager check of a started component (see PropertyEagerChecks)
+	PhaseWarmUp LifecyclePhase = "warm-up"
+	PhaseStop   LifecyclePhase = "stop"
+)
+
+// LifecycleEventType tells whether a phase begins or ends
+type LifecycleEventType string
+
+// Lifecycle event types
+const (
+	EventBegin LifecycleEventType = "begin"
+	EventEnd   LifecycleEventType = "end"
+)
+
+// LifecycleEvent is a phase transition of a component
+type LifecycleEvent struct {
+	Time      time.Time          `json:"time"`
+	Component string             `json:"component"`
+	Phase     LifecyclePhase     `json:"phase"`
+	Type      LifecycleEventType `json:"type"`
+	// Duration of the phase, set on end events
+	Duration time.Duration `json:"durationNs,omitempty"`
+	// Error the phase failed with (Init errors, Start and Stop panics, Close errors)
+	Error string `json:"error,omitempty"`
+}
+
+// LifecycleEventListener receives the lifecycle events of the container's
+// components. Components start and stop concurrently, so OnLifecycleEvent must be
+// safe for concurrent use; it is called synchronously and should return quickly.
+type LifecycleEventListener interface {
+	OnLifecycleEvent(event LifecycleEvent)
+}
+
+// JSONLinesEventWriter writes lifecycle events as JSON lines, e.g. to a file that
+// is converted with WriteChromeTrace later
+type JSONLinesEventWriter struct {
+	mu      sync.Mutex
+	encoder *json.Encoder
+}
+
+// NewJSONLinesEventWriter creates a listener writing one JSON object per event to w
+func NewJSONLinesEventWriter(w io.Writer) *JSONLinesEventWriter {
+	return &JSONLinesEventWriter{encoder: json.NewEncoder(w)}
+}
+
+// OnLifecycleEvent writes the event; write errors are ignored
+func (w *JSONLinesEventWriter) OnLifecycleEvent(event LifecycleEvent) {
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	_ = w.encoder.Encode(event)
+}
+
+// LifecycleEventRecorder keeps lifecycle events in memory
+type LifecycleEventRecorder struct {
+	mu     sync.Mutex
+	events []LifecycleEvent
+}
+
+// OnLifecycleEvent records the event
+func (r *LifecycleEventRecorder) OnLifecycleEvent(event LifecycleEvent) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.events = append(r.events, event)
+}
+
+// Events returns the recorded events in the order they were received
+func (r *LifecycleEventRecorder) Events() []LifecycleEvent {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return append([]LifecycleEvent(nil), r.events...)
+}
+
+// ReadLifecycleEvents reads events written by a JSONLinesEventWriter
+func ReadLifecycleEvents(r io.Reader) ([]LifecycleEvent, error) {
+	var events []LifecycleEvent
+	scanner := bufio.NewScanner(r)
+	for line := 1; scanner.Scan(); line++ {
+		if len(scanner.Bytes()) == 0 {
+			continue
+		}
+		var event LifecycleEvent
+		if err := json.Unmarshal(scanner.Bytes(), &event); err != nil {
+			return nil, fmt.Errorf("line %d: %w", line, err)
+		}
+		events = append(events, event)
+	}
+	return events, scanner.Err()
+}
+
+// traceEvent is an event of the Chrome trace event format
+type traceEvent struct {
+	Name      string                 `json:"name"`
+	Category  string                 `json:"cat,omitempty"`
+	Phase     string                 `json:"ph"`
+	Timestamp float64                `json:"ts"`
+	Duration  float64                `json:"dur,omitempty"`
+	Pid       int                    `json:"pid"`
+	Tid       int                    `json:"tid"`
+	Args      map[string]interface{} `json:"args,omitempty"`
+}
+
+// WriteChromeTrace converts lifecycle events to the Chrome trace event format, which
+// can be opened in chrome://tracing or Perfetto. Every component gets its own track;
+// a phase that began but never ended (e.g. a Start that hangs) is shown as open.
+func WriteChromeTrace(w io.Writer, events []LifecycleEvent) error {
+	events = append([]LifecycleEvent(nil), events...)
+	sort.SliceStable(events, func(i, j int) bool {
+		return events[i].Time.Before(events[j].Time)
+	})
+
+	var origin time.Time
+	if len(events) > 0 {
+		origin = events[0].Time
+	}
+	micros := func(t time.Time) float64 {
+		return float64(t.Sub(origin)) / float64(time.Microsecond)
+	}
+
+	type phaseKey struct {
+		component string
+		phase     LifecyclePhase
+	}
+	tids := make(map[string]int)
+	begins := make(map[phaseKey]LifecycleEvent)
+	trace := []traceEvent{}
+
+	for _, event := range events {
+		tid, ok := tids[event.Component]
+		if !ok {
+			tid = len(tids) + 1
+			tids[event.Component] = tid
+			trace = append(trace, traceEvent{
+				Name: "thread_name", Phase: "M", Pid: 1, Tid: tid,
+				Args: map[string]interface{}{"name": event.Component},
+			})
+		}
+
+		key := phaseKey{event.Component, event.Phase}
+		if event.Type == EventBegin {
+			begins[key] = event
+			continue
+		}
+
+		// Place the phase at its recorded begin, measured by the duration of the end
+		// event which excludes the time spent in listeners
+		start := event.Time.Add(-event.Duration)
+		if begin, ok := begins[key]; ok {
+			start = begin.Time
+			delete(begins, key)
+		}
+		duration := event.Duration
+		if duration <= 0 {
+			duration = event.Time.Sub(start)
+		}
+		complete := traceEvent{
+			Name: string(event.Phase), Category: "lifecycle", Phase: "X", Pid: 1, Tid: tid,
+			Timestamp: micros(start),
+			Duration:  float64(duration) / float64(time.Microsecond),
+		}
+		if event.Error != "" {
+			complete.Args = map[string]interface{}{"error": event.Error}
+		}
+		trace = append(trace, complete)
+	}
+
+	open := make([]LifecycleEvent, 0, len(begins))
+	for _, begin := range begins {
+		open = append(open, begin)
+	}
+	sort.Slice(open, func(i, j int) bool {
+		return open[i].Time.Before(open[j].Time)
+	})
+	for _, begin := range open {
+		trace = append(trace, traceEvent{
+			Name: string(begin.Phase), Category: "lifecycle", Phase: "B", Pid: 1,
+			Tid: tids[begin.Component], Timestamp: micros(begin.Time),
+		})
+	}
+
+	return json.NewEncoder(w).Encode(map[string]interface{}{
+		"traceEvents":     trace,
+		"displayTimeUnit": "ms",
+	})
+}
+
+// begin reports that a component enters a lifecycle phase
+func (s *componentStates) begin(name string, phase LifecyclePhase) {
+	if s.listener == nil {
+		return
+	}
+	s.listener.OnLifecycleEvent(LifecycleEvent{Time: time.Now(), Component: name, Phase: phase, Type: EventBegin})
+}
+
+// end reports that a component left a lifecycle phase, failed if err is not nil
+func (s *componentStates) end(name string, phase LifecyclePhase, duration time.Duration, err error) {
+	if s.listener == nil {
+		return
+	}
+	event := LifecycleEvent{Time: time.Now(), Component: name, Phase: phase, Type: EventEnd, Duration: duration}
+	if err != nil {
+		event.Error = err.Error()
+	}
+	s.listener.OnLifecycleEvent(event)
+}
@@ -0,0 +1,89 @@
+package container
+
+import (
+	"encoding/base64"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+)
+
+// ExpressionFunction resolves a function-style variable value such as
+// file(/run/secrets/db_password) from its argument
+type ExpressionFunction func(arg string) (string, error)
+
+// expressionFunctions are the functions resolved in variable values
+var expressionFunctions = struct {
+	mu        sync.RWMutex
+	functions map[string]ExpressionFunction
+}{functions: map[string]ExpressionFunction{
+	"env":          envExpression,
+	"file":         fileExpression,
+	"base64decode": base64DecodeExpression,
+}}
+
+// RegisterExpressionFunction adds a function resolved in variable values, e.g.
+// vault(secret/db#password). A string variable whose whole value is name(arg) is
+// replaced with the function's result when it is read; arg may itself be an
+// expression. The built-in functions are:
+//
+//	env(HOME)                        the environment variable
+//	file(/run/secrets/db_password)   the file content, without the trailing newline
+//	base64decode(c2VjcmV0)           the decoded value, e.g. base64decode(env(TOKEN))
+func RegisterExpressionFunction(name string, fn ExpressionFunction) {
+	expressionFunctions.mu.Lock()
+	defer expressionFunctions.mu.Unlock()
+	expressionFunctions.functions[name] = fn
+}
+
+func envExpression(name string) (string, error) {
+	value, ok := os.LookupEnv(name)
+	if !ok {
+		return "", fmt.Errorf("environment variable %s not set", name)
+	}
+	return value, nil
+}
+
+func fileExpression(path string) (string, error) {
+	content, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	return strings.TrimRight(string(content), "\r\n"), nil
+}
+
+func base64DecodeExpression(encoded string) (string, error) {
+	decoded, err := base64.StdEncoding.DecodeString(encoded)
+	if err != nil {
+		return "", err
+	}
+	return string(decoded), nil
+}
+
+// parseExpression splits name(arg) into its registered function and argument
+func parseExpression(value string) (ExpressionFunction, string, bool) {
+	open := strings.IndexByte(value, '(')
+	if open <= 0 || !strings.HasSuffix(value, ")") {
+		return nil, "", false
+	}
+	expressionFunctions.mu.RLock()
+	fn, ok := expressionFunctions.functions[value[:open]]
+	expressionFunctions.mu.RUnlock()
+	if !ok {
+		return nil, "", false
+	}
+	return fn, value[open+1 : len(value)-1], true
+}
+
+// evaluateExpression resolves nested expressions from the innermost one
+func evaluateExpression(value string) (string, error) {
+	fn, arg, ok := parseExpression(value)
+	if !ok {
+		return value, nil
+	}
+	arg, err := evaluateExpression(arg)
+	if err != nil {
+		return "", err
+	}
+	return fn(arg)
+}
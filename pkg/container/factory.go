@@ -1,5 +1,10 @@
 package container
 
+import (
+	"fmt"
+	"strings"
+)
+
 // Factory is an interface for components that can create and register other components
 type Factory interface {
 	// Create creates components and registers them with the container
@@ -36,3 +41,108 @@ func (f FactoryFunc) Create(builder ContextBuilder) error {
 func NewFactory(fn func(ContextBuilder) error) Factory {
 	return &FactoryFunc{fn: fn}
 }
+
+// PlannedFactory is a factory declaring the components it produces and the ones
+// it requires. Planned factories run once the variables are loaded, so that they
+// can read configuration, and before the starters, each after the factories
+// producing the components it requires. The produced components depend on the
+// required ones, so they initialize after and stop before them even when the
+// dependency is passed to their constructor instead of looked up in Init.
+type PlannedFactory interface {
+	Factory
+	// Produces returns the names of the components Create registers
+	Produces() []string
+	// Requires returns the names of the components Create looks up
+	Requires() []string
+}
+
+// plannedFactoryFunc is a planned factory implemented as a function
+type plannedFactoryFunc struct {
+	produces []string
+	requires []string
+	fn       func(ContextBuilder) error
+}
+
+func (f *plannedFactoryFunc) Create(builder ContextBuilder) error {
+	return f.fn(builder)
+}
+
+func (f *plannedFactoryFunc) Produces() []string {
+	return f.produces
+}
+
+func (f *plannedFactoryFunc) Requires() []string {
+	return f.requires
+}
+
+// NewPlannedFactory creates a planned factory registering the produces components
+// from the requires ones
+func NewPlannedFactory(produces, requires []string, fn func(ContextBuilder) error) PlannedFactory {
+	return &plannedFactoryFunc{produces: produces, requires: requires, fn: fn}
+}
+
+// factoryName names a factory in logs and errors
+func factoryName(factory Factory) string {
+	if planned, ok := factory.(PlannedFactory); ok {
+		return "for " + strings.Join(planned.Produces(), ",")
+	}
+	return fmt.Sprintf("%T", factory)
+}
+
+// planFactories orders the planned factories so that each runs after the
+// factories producing the components it requires, otherwise keeping their
+// registration order
+func planFactories(factories []Factory) ([]PlannedFactory, error) {
+	var planned []PlannedFactory
+	producers := make(map[string]int)
+	for _, factory := range factories {
+		p, ok := factory.(PlannedFactory)
+		if !ok {
+			continue
+		}
+		for _, name := range p.Produces() {
+			if _, exists := producers[name]; exists {
+				return nil, ErrorWithCode("DUPLICATE_PRODUCER", "component '%s' is produced by several factories", name)
+			}
+			producers[name] = len(planned)
+		}
+		planned = append(planned, p)
+	}
+
+	ordered := make([]PlannedFactory, 0, len(planned))
+	done := make([]bool, len(planned))
+	for len(ordered) < len(planned) {
+		progress := false
+		for i, p := range planned {
+			if done[i] || !requirementsDone(p, producers, done, i) {
+				continue
+			}
+			done[i] = true
+			ordered = append(ordered, p)
+			progress = true
+			break
+		}
+		if !progress {
+			var waiting []string
+			for i, p := range planned {
+				if !done[i] {
+					waiting = append(waiting, factoryName(p))
+				}
+			}
+			return nil, ErrorWithCode("CIRCULAR_FACTORIES", "factories require each other's components: %s", strings.Join(waiting, "; "))
+		}
+	}
+	return ordered, nil
+}
+
+// requirementsDone reports whether the factories producing the requirements of
+// the factory at index self already ran; other requirements are left to the
+// components registered so far and to the starters
+func requirementsDone(factory PlannedFactory, producers map[string]int, done []bool, self int) bool {
+	for _, name := range factory.Requires() {
+		if producer, ok := producers[name]; ok && producer != self && !done[producer] {
+			return false
+		}
+	}
+	return true
+}
@@ -0,0 +1,487 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"strconv"
+	"strings"
+	"unicode"
+	"unicode/utf8"
+)
+
+// HclLoader loads variables from HashiCorp HCL files with profile support, like
+// ProfileYamlLoader: application.hcl, then application-<profile>.hcl for each
+// active profile. Attributes become variables and blocks become sections, their
+// labels nested keys:
+//
+//	server {
+//	  port = 8080
+//	}
+//	datasource "primary" {
+//	  url   = "postgres://db/app"
+//	  hosts = ["a", "b"]
+//	}
+//
+// registers server.port, datasource.primary.url and datasource.primary.hosts.
+// Repeated blocks of the same type and labels become a list. Expressions other
+// than literals, lists and objects (references, function calls, operators) are
+// not evaluated and fail loading; "${...}" inside strings is kept as is.
+type HclLoader struct {
+	// ConfigPath specifies directory where to look for config files
+	ConfigPath string
+	// Optional list of profile names to activate in addition to the active profiles
+	Profiles []string
+	// MergeStrategies defines how profile files combine lists and maps with earlier
+	// files, by dot-separated key
+	MergeStrategies map[string]MergeStrategy
+}
+
+// Load loads variables from HCL files with profile support
+func (l HclLoader) Load(builder ContextBuilder) error {
+	return profileFiles{
+		configPath: l.ConfigPath,
+		ext:        ".hcl",
+		read:       readHclConfig,
+		profiles:   l.Profiles,
+		strategies: l.MergeStrategies,
+	}.load(builder)
+}
+
+// readHclConfig reads an HCL file into a nested map
+func readHclConfig(filePath string) (map[string]interface{}, error) {
+	data, err := os.ReadFile(filePath)
+	if err != nil {
+		return nil, err
+	}
+	return parseHcl(string(data))
+}
+
+// parseHcl parses an HCL document into a nested map
+func parseHcl(src string) (map[string]interface{}, error) {
+	p := &hclParser{src: src, line: 1}
+	body, err := p.body(false)
+	if err != nil {
+		return nil, fmt.Errorf("line %d: %w", p.line, err)
+	}
+	return body, nil
+}
+
+// hclParser is a recursive descent parser of HCL native syntax without expressions
+type hclParser struct {
+	src  string
+	pos  int
+	line int
+}
+
+// body parses attributes and blocks until the end of the input, or the closing
+// brace of a block
+func (p *hclParser) body(block bool) (map[string]interface{}, error) {
+	result := make(map[string]interface{})
+	attributes := make(map[string]bool)
+	for {
+		p.skip(true)
+		if p.eof() {
+			if block {
+				return nil, fmt.Errorf("unclosed block")
+			}
+			return result, nil
+		}
+		if p.peek() == '}' {
+			if !block {
+				return nil, fmt.Errorf("unexpected '}'")
+			}
+			p.pos++
+			return result, nil
+		}
+
+		name, err := p.identifier()
+		if err != nil {
+			return nil, err
+		}
+		p.skip(false)
+
+		if p.peek() == '=' {
+			p.pos++
+			value, err := p.expression()
+			if err != nil {
+				return nil, fmt.Errorf("attribute %s: %w", name, err)
+			}
+			if attributes[name] || result[name] != nil {
+				return nil, fmt.Errorf("attribute %s redefined", name)
+			}
+			attributes[name] = true
+			result[name] = value
+		} else {
+			path := []string{name}
+			for p.peek() != '{' {
+				if p.eof() || p.peek() == '\n' {
+					return nil, fmt.Errorf("block %s: expected '{'", name)
+				}
+				label, err := p.label()
+				if err != nil {
+					return nil, fmt.Errorf("block %s: %w", name, err)
+				}
+				path = append(path, label)
+				p.skip(false)
+			}
+			if attributes[name] {
+				return nil, fmt.Errorf("block %s redefines an attribute", name)
+			}
+			p.pos++
+			content, err := p.body(true)
+			if err != nil {
+				return nil, err
+			}
+			addHclBlock(result, path, content)
+		}
+
+		// An attribute or block ends the line
+		p.skip(false)
+		if !p.eof() && p.peek() != '\n' && p.peek() != '}' {
+			return nil, fmt.Errorf("unexpected %q after %s", p.peek(), name)
+		}
+	}
+}
+
+// addHclBlock adds a block below its type and labels; repeating it makes a list
+func addHclBlock(body map[string]interface{}, path []string, content map[string]interface{}) {
+	for _, key := range path[:len(path)-1] {
+		next, ok := body[key].(map[string]interface{})
+		if !ok {
+			next = make(map[string]interface{})
+			body[key] = next
+		}
+		body = next
+	}
+
+	key := path[len(path)-1]
+	switch existing := body[key].(type) {
+	case nil:
+		body[key] = content
+	case []interface{}:
+		body[key] = append(existing, content)
+	default:
+		body[key] = []interface{}{existing, content}
+	}
+}
+
+// expression parses a literal, a list or an object
+func (p *hclParser) expression() (interface{}, error) {
+	p.skip(false)
+	if p.eof() {
+		return nil, fmt.Errorf("missing value")
+	}
+
+	switch c := p.peek(); {
+	case c == '"':
+		return p.quoted()
+	case c == '<' && strings.HasPrefix(p.src[p.pos:], "<<"):
+		return p.heredoc()
+	case c == '[':
+		return p.list()
+	case c == '{':
+		return p.object()
+	case c == '-' || c >= '0' && c <= '9':
+		return p.number()
+	case isHclIdentifierStart(c):
+		word, _ := p.identifier()
+		switch word {
+		case "true":
+			return true, nil
+		case "false":
+			return false, nil
+		case "null":
+			return nil, nil
+		}
+		return nil, fmt.Errorf("unsupported expression %s: only literals, lists and objects are supported", word)
+	default:
+		return nil, fmt.Errorf("unexpected %q", c)
+	}
+}
+
+// list parses [value, ...], allowing newlines and a trailing comma
+func (p *hclParser) list() (interface{}, error) {
+	p.pos++
+	list := []interface{}{}
+	for {
+		p.skip(true)
+		if p.eof() {
+			return nil, fmt.Errorf("unclosed list")
+		}
+		if p.peek() == ']' {
+			p.pos++
+			return list, nil
+		}
+		value, err := p.expression()
+		if err != nil {
+			return nil, err
+		}
+		list = append(list, value)
+
+		p.skip(true)
+		if p.peek() == ',' {
+			p.pos++
+		} else if p.peek() != ']' {
+			return nil, fmt.Errorf("expected ',' or ']' in list")
+		}
+	}
+}
+
+// object parses { key = value, ... }, with = or : and commas or newlines
+func (p *hclParser) object() (interface{}, error) {
+	p.pos++
+	object := make(map[string]interface{})
+	for {
+		p.skip(true)
+		if p.eof() {
+			return nil, fmt.Errorf("unclosed object")
+		}
+		if p.peek() == '}' {
+			p.pos++
+			return object, nil
+		}
+		key, err := p.label()
+		if err != nil {
+			return nil, err
+		}
+		p.skip(false)
+		if c := p.peek(); c != '=' && c != ':' {
+			return nil, fmt.Errorf("expected '=' after object key %s", key)
+		}
+		p.pos++
+		if object[key], err = p.expression(); err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+
+		p.skip(false)
+		if p.peek() == ',' {
+			p.pos++
+		} else if c := p.peek(); c != '\n' && c != '}' && c != 0 {
+			return nil, fmt.Errorf("expected ',' or newline after object key %s", key)
+		}
+	}
+}
+
+// number parses an integer as int and other numbers as float64
+func (p *hclParser) number() (interface{}, error) {
+	start := p.pos
+	if p.peek() == '-' {
+		p.pos++
+	}
+	for !p.eof() && strings.ContainsRune("0123456789.eE+-", rune(p.peek())) {
+		if c := p.peek(); (c == '+' || c == '-') && p.src[p.pos-1] != 'e' && p.src[p.pos-1] != 'E' {
+			break
+		}
+		p.pos++
+	}
+	text := p.src[start:p.pos]
+	if i, err := strconv.Atoi(text); err == nil {
+		return i, nil
+	}
+	f, err := strconv.ParseFloat(text, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid number %s", text)
+	}
+	return f, nil
+}
+
+// quoted parses a double-quoted string with escapes; "$${" and "%%{" are unescaped,
+// other template sequences are kept as written
+func (p *hclParser) quoted() (string, error) {
+	p.pos++
+	var b strings.Builder
+	for {
+		if p.eof() || p.peek() == '\n' {
+			return "", fmt.Errorf("unterminated string")
+		}
+		c := p.peek()
+		switch {
+		case c == '"':
+			p.pos++
+			return b.String(), nil
+		case c == '\\':
+			r, err := p.escape()
+			if err != nil {
+				return "", err
+			}
+			b.WriteRune(r)
+		case (c == '$' || c == '%') && strings.HasPrefix(p.src[p.pos+1:], string(c)+"{"):
+			b.WriteString(string(c) + "{")
+			p.pos += 3
+		default:
+			r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+			b.WriteRune(r)
+			p.pos += size
+		}
+	}
+}
+
+// escape parses a backslash escape sequence of a quoted string
+func (p *hclParser) escape() (rune, error) {
+	p.pos++
+	if p.eof() {
+		return 0, fmt.Errorf("unterminated string")
+	}
+	c := p.peek()
+	p.pos++
+	switch c {
+	case 'n':
+		return '\n', nil
+	case 'r':
+		return '\r', nil
+	case 't':
+		return '\t', nil
+	case '"', '\\':
+		return rune(c), nil
+	case 'u', 'U':
+		size := 4
+		if c == 'U' {
+			size = 8
+		}
+		if p.pos+size > len(p.src) {
+			return 0, fmt.Errorf("invalid unicode escape")
+		}
+		code, err := strconv.ParseUint(p.src[p.pos:p.pos+size], 16, 32)
+		if err != nil {
+			return 0, fmt.Errorf("invalid unicode escape \\%c%s", c, p.src[p.pos:p.pos+size])
+		}
+		p.pos += size
+		return rune(code), nil
+	}
+	return 0, fmt.Errorf("invalid escape \\%c", c)
+}
+
+// heredoc parses <<MARKER or the indented <<-MARKER form, whose lines lose the
+// indentation common to all of them
+func (p *hclParser) heredoc() (string, error) {
+	p.pos += 2
+	indented := p.peek() == '-'
+	if indented {
+		p.pos++
+	}
+	marker, err := p.identifier()
+	if err != nil {
+		return "", fmt.Errorf("heredoc: %w", err)
+	}
+	if p.peek() == '\r' {
+		p.pos++
+	}
+	if p.peek() != '\n' {
+		return "", fmt.Errorf("heredoc %s: expected newline", marker)
+	}
+	p.pos++
+	p.line++
+
+	var lines []string
+	for {
+		if p.eof() {
+			return "", fmt.Errorf("unterminated heredoc %s", marker)
+		}
+		end := strings.IndexByte(p.src[p.pos:], '\n')
+		if end < 0 {
+			end = len(p.src) - p.pos
+		}
+		line := p.src[p.pos : p.pos+end]
+		if strings.TrimSpace(line) == marker {
+			p.pos += len(line)
+			break
+		}
+		lines = append(lines, strings.TrimSuffix(line, "\r"))
+		p.pos += end
+		if !p.eof() {
+			p.pos++
+			p.line++
+		}
+	}
+
+	if indented {
+		indent := -1
+		for _, line := range lines {
+			if strings.TrimSpace(line) == "" {
+				continue
+			}
+			if n := len(line) - len(strings.TrimLeft(line, " \t")); indent < 0 || n < indent {
+				indent = n
+			}
+		}
+		for i, line := range lines {
+			if len(line) >= indent && indent > 0 {
+				lines[i] = line[indent:]
+			}
+		}
+	}
+	if len(lines) == 0 {
+		return "", nil
+	}
+	return strings.Join(lines, "\n") + "\n", nil
+}
+
+// label parses a block label or object key: an identifier or a quoted string
+func (p *hclParser) label() (string, error) {
+	if p.peek() == '"' {
+		return p.quoted()
+	}
+	return p.identifier()
+}
+
+// identifier parses a name of letters, digits, underscores and dashes
+func (p *hclParser) identifier() (string, error) {
+	start := p.pos
+	if p.eof() || !isHclIdentifierStart(p.peek()) {
+		if p.eof() {
+			return "", fmt.Errorf("expected a name")
+		}
+		return "", fmt.Errorf("expected a name, got %q", p.peek())
+	}
+	for !p.eof() {
+		r, size := utf8.DecodeRuneInString(p.src[p.pos:])
+		if !unicode.IsLetter(r) && !unicode.IsDigit(r) && r != '_' && r != '-' {
+			break
+		}
+		p.pos += size
+	}
+	return p.src[start:p.pos], nil
+}
+
+func isHclIdentifierStart(c byte) bool {
+	return c == '_' || c >= 'a' && c <= 'z' || c >= 'A' && c <= 'Z' || c >= utf8.RuneSelf
+}
+
+// skip skips spaces and comments, and newlines if newlines is set. A line comment
+// stops before its newline.
+func (p *hclParser) skip(newlines bool) {
+	for !p.eof() {
+		switch c := p.peek(); {
+		case c == ' ' || c == '\t' || c == '\r':
+			p.pos++
+		case c == '\n' && newlines:
+			p.pos++
+			p.line++
+		case c == '#' || c == '/' && strings.HasPrefix(p.src[p.pos:], "//"):
+			for !p.eof() && p.peek() != '\n' {
+				p.pos++
+			}
+		case c == '/' && strings.HasPrefix(p.src[p.pos:], "/*"):
+			end := strings.Index(p.src[p.pos+2:], "*/")
+			if end < 0 {
+				end = len(p.src) - p.pos - 2
+			}
+			p.line += strings.Count(p.src[p.pos:p.pos+2+end], "\n")
+			p.pos = min(p.pos+end+4, len(p.src))
+		default:
+			return
+		}
+	}
+}
+
+// peek returns the next byte, 0 at the end of the input
+func (p *hclParser) peek() byte {
+	if p.eof() {
+		return 0
+	}
+	return p.src[p.pos]
+}
+
+func (p *hclParser) eof() bool {
+	return p.pos >= len(p.src)
+}
@@ -0,0 +1,220 @@
+package container
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseHcl(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]interface{}
+	}{
+		{
+			name: "attributes",
+			src: `name    = "orders"
+port    = 8080
+ratio   = 0.5
+enabled = true
+missing = null
+hosts   = ["a", "b",]
+`,
+			want: map[string]interface{}{
+				"name":    "orders",
+				"port":    8080,
+				"ratio":   0.5,
+				"enabled": true,
+				"missing": nil,
+				"hosts":   []interface{}{"a", "b"},
+			},
+		},
+		{
+			name: "nested blocks",
+			src: `server {
+  port = 8080
+  tls {
+    enabled = false
+  }
+}
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{
+					"port": 8080,
+					"tls":  map[string]interface{}{"enabled": false},
+				},
+			},
+		},
+		{
+			name: "labelled blocks",
+			src: `datasource "primary" {
+  url = "postgres://db/app"
+}
+datasource "replica" "eu" {
+  url = "postgres://replica/app"
+}
+`,
+			want: map[string]interface{}{
+				"datasource": map[string]interface{}{
+					"primary": map[string]interface{}{"url": "postgres://db/app"},
+					"replica": map[string]interface{}{
+						"eu": map[string]interface{}{"url": "postgres://replica/app"},
+					},
+				},
+			},
+		},
+		{
+			name: "repeated block",
+			src: `route {
+  path = "/a"
+}
+route {
+  path = "/b"
+}
+route {
+  path = "/c"
+}
+`,
+			want: map[string]interface{}{
+				"route": []interface{}{
+					map[string]interface{}{"path": "/a"},
+					map[string]interface{}{"path": "/b"},
+					map[string]interface{}{"path": "/c"},
+				},
+			},
+		},
+		{
+			name: "object",
+			src: `limits = {
+  cpu: "500m", memory = "1Gi"
+  "max-conns" = 10
+}
+`,
+			want: map[string]interface{}{
+				"limits": map[string]interface{}{"cpu": "500m", "memory": "1Gi", "max-conns": 10},
+			},
+		},
+		{
+			name: "comments",
+			src: `# hash
+// slashes
+/* block
+   comment */
+port = 8080 # trailing
+`,
+			want: map[string]interface{}{"port": 8080},
+		},
+		{
+			name: "escapes",
+			src:  `text = "tab\there \"quoted\" \\ é $${kept} ${as.is}"`,
+			want: map[string]interface{}{"text": "tab\there \"quoted\" \\ é ${kept} ${as.is}"},
+		},
+		{
+			name: "heredoc",
+			src: `script = <<EOT
+  first
+second
+EOT
+`,
+			want: map[string]interface{}{"script": "  first\nsecond\n"},
+		},
+		{
+			name: "indented heredoc",
+			src: `server {
+  banner = <<-EOT
+    hello
+      world
+    EOT
+}
+`,
+			want: map[string]interface{}{
+				"server": map[string]interface{}{"banner": "hello\n  world\n"},
+			},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseHcl(tt.src)
+			if err != nil {
+				t.Fatalf("parseHcl() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseHcl() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParseHclErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want is contained in the error message
+		want string
+	}{
+		{
+			name: "duplicate attribute",
+			src:  "port = 1\nport = 2\n",
+			want: "line 2: attribute port redefined",
+		},
+		{
+			name: "attribute over block",
+			src:  "server {\n}\nserver = 1\n",
+			want: "line 3: attribute server redefined",
+		},
+		{
+			name: "block over attribute",
+			src:  "server = 1\nserver {\n  port = 2\n}\n",
+			want: "line 2: block server redefines an attribute",
+		},
+		{
+			name: "reference",
+			src:  "a = 1\nb = var.a\n",
+			want: "line 2: attribute b: unsupported expression var",
+		},
+		{
+			name: "function call",
+			src:  "\n\nname = upper(\"x\")\n",
+			want: "line 3: attribute name: unsupported expression upper",
+		},
+		{
+			name: "invalid escape",
+			src:  `name = "a\qb"`,
+			want: `line 1: attribute name: invalid escape \q`,
+		},
+		{
+			name: "unterminated string",
+			src:  "name = \"abc\nport = 1\n",
+			want: "line 1: attribute name: unterminated string",
+		},
+		{
+			name: "unterminated heredoc",
+			src:  "script = <<EOT\nline\n",
+			want: "attribute script: unterminated heredoc EOT",
+		},
+		{
+			name: "unclosed block",
+			src:  "server {\n  port = 1\n",
+			want: "line 3: unclosed block",
+		},
+		{
+			name: "two attributes on a line",
+			src:  "a = 1 b = 2\n",
+			want: "line 1: unexpected 'b' after a",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseHcl(tt.src)
+			if err == nil {
+				t.Fatalf("parseHcl() error = nil, want %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseHcl() error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
@@ -0,0 +1,90 @@
+package container
+
+import (
+	"context"
+	"time"
+)
+
+// HealthStatus is the health state of a component or of the whole application
+type HealthStatus string
+
+// Health statuses
+const (
+	HealthUp      HealthStatus = "UP"
+	HealthDown    HealthStatus = "DOWN"
+	HealthUnknown HealthStatus = "UNKNOWN"
+)
+
+// Health is the result of a single health check
+type Health struct {
+	Status  HealthStatus
+	Details map[string]interface{}
+}
+
+// HealthIndicator is implemented by components (or instance values) that can
+// report their own health, e.g. by pinging a database
+type HealthIndicator interface {
+	// CheckHealth reports the current health; it should honour ctx cancellation
+	CheckHealth(ctx context.Context) Health
+}
+
+// HealthReport aggregates the health of all HealthIndicator components
+type HealthReport struct {
+	// Status is DOWN if any indicator is DOWN, UP otherwise
+	Status HealthStatus
+	// Components holds each indicator's health by component name
+	Components map[string]Health
+}
+
+// AggregateHealth checks every HealthIndicator registered in the container
+func AggregateHealth(ctx context.Context, app ApplicationContext) HealthReport {
+	report := HealthReport{Status: HealthUp, Components: make(map[string]Health)}
+
+	for name, indicator := range HealthIndicators(app) {
+		health := CheckHealth(ctx, app, name, indicator)
+		report.Components[name] = health
+		if health.Status == HealthDown {
+			report.Status = HealthDown
+		}
+	}
+
+	return report
+}
+
+// healthCheckRecorder is implemented by contexts that keep health check metrics
+type healthCheckRecorder interface {
+	recordHealthCheck(name string, duration time.Duration, failed bool)
+}
+
+// CheckHealth runs the health check of the named indicator, reporting UNKNOWN for
+// an empty status and recording the check in the component's metrics
+func CheckHealth(ctx context.Context, app ApplicationContext, name string, indicator HealthIndicator) Health {
+	start := time.Now()
+	health := indicator.CheckHealth(ctx)
+	if health.Status == "" {
+		health.Status = HealthUnknown
+	}
+	if recorder, ok := app.(healthCheckRecorder); ok {
+		recorder.recordHealthCheck(name, time.Since(start), health.Status == HealthDown)
+	}
+	return health
+}
+
+func (c *container) recordHealthCheck(name string, duration time.Duration, failed bool) {
+	c.metricsCollector.RecordHealthCheck(name, duration, failed)
+}
+
+// HealthIndicators returns the HealthIndicator components by name
+func HealthIndicators(app ApplicationContext) map[string]HealthIndicator {
+	indicators := make(map[string]HealthIndicator)
+	for _, name := range app.GetComponentNames() {
+		comp, err := app.GetComponentByName(name)
+		if err != nil || comp == nil {
+			continue
+		}
+		if indicator, ok := componentValue(comp).(HealthIndicator); ok {
+			indicators[name] = indicator
+		}
+	}
+	return indicators
+}
@@ -0,0 +1,163 @@
+package container
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+)
+
+// hookPosition is when a shutdown hook runs
+type hookPosition int
+
+const (
+	hookBeforeComponents hookPosition = iota
+	hookAfterComponent
+	hookAfterComponents
+)
+
+// shutdownHook is a function run during shutdown
+type shutdownHook struct {
+	name     string
+	fn       func(context.Context)
+	position hookPosition
+	// component is the component the hook runs after, for hookAfterComponent
+	component string
+	ran       bool
+}
+
+// ShutdownHookOption places a shutdown hook relative to the components
+type ShutdownHookOption func(*shutdownHook)
+
+// BeforeComponentsStop runs the hook before any component stops, e.g. to
+// deregister from a load balancer while requests are still served (default)
+func BeforeComponentsStop() ShutdownHookOption {
+	return func(h *shutdownHook) {
+		h.position = hookBeforeComponents
+		h.component = ""
+	}
+}
+
+// AfterComponentsStop runs the hook once all components stopped, e.g. to flush
+// buffers the components wrote to
+func AfterComponentsStop() ShutdownHookOption {
+	return func(h *shutdownHook) {
+		h.position = hookAfterComponents
+		h.component = ""
+	}
+}
+
+// AfterComponentStop runs the hook right after the named component stopped,
+// before the components it depends on stop. Hooks after components that are not
+// stopped run after all components stopped.
+func AfterComponentStop(name string) ShutdownHookOption {
+	return func(h *shutdownHook) {
+		h.position = hookAfterComponent
+		h.component = name
+	}
+}
+
+// shutdownHooks are the hooks registered with RegisterShutdownHook
+type shutdownHooks struct {
+	logger *slog.Logger
+
+	mu    sync.Mutex
+	hooks []*shutdownHook
+}
+
+func newShutdownHooks(logger *slog.Logger) *shutdownHooks {
+	return &shutdownHooks{logger: logger}
+}
+
+// register adds a hook, replacing the hook of the same name
+func (s *shutdownHooks) register(name string, fn func(context.Context), options ...ShutdownHookOption) {
+	hook := &shutdownHook{name: name, fn: fn}
+	for _, option := range options {
+		option(hook)
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for i, existing := range s.hooks {
+		if existing.name == name {
+			s.hooks[i] = hook
+			return
+		}
+	}
+	s.hooks = append(s.hooks, hook)
+}
+
+// count returns the number of hooks registered
+func (s *shutdownHooks) count() int {
+	if s == nil {
+		return 0
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return len(s.hooks)
+}
+
+// truncate drops the hooks registered after the first n
+func (s *shutdownHooks) truncate(n int) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.hooks = s.hooks[:n]
+}
+
+// run runs the hooks matching pred that didn't run yet, in registration order
+func (s *shutdownHooks) run(ctx context.Context, pred func(*shutdownHook) bool) {
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	var due []*shutdownHook
+	for _, hook := range s.hooks {
+		if !hook.ran && pred(hook) {
+			hook.ran = true
+			due = append(due, hook)
+		}
+	}
+	s.mu.Unlock()
+
+	for _, hook := range due {
+		s.runHook(ctx, hook)
+	}
+}
+
+// runBefore runs the hooks before the components stop
+func (s *shutdownHooks) runBefore(ctx context.Context) {
+	s.run(ctx, func(h *shutdownHook) bool { return h.position == hookBeforeComponents })
+}
+
+// runAfterComponent runs the hooks after a stopped component
+func (s *shutdownHooks) runAfterComponent(ctx context.Context, name string) {
+	s.run(ctx, func(h *shutdownHook) bool { return h.position == hookAfterComponent && h.component == name })
+}
+
+// runAfter runs the hooks after all components stopped, including those after
+// components that were not stopped
+func (s *shutdownHooks) runAfter(ctx context.Context) {
+	s.run(ctx, func(h *shutdownHook) bool {
+		if h.position == hookAfterComponent {
+			s.logger.Warn("Shutdown hook after a component that was not stopped", "name", h.name, "component", h.component)
+		}
+		return h.position != hookBeforeComponents
+	})
+}
+
+// runHook runs a hook, logging its panic
+func (s *shutdownHooks) runHook(ctx context.Context, hook *shutdownHook) {
+	start := time.Now()
+	defer func() {
+		if r := recover(); r != nil {
+			s.logger.Error("Panic in shutdown hook", "name", hook.name, "error", r)
+		}
+	}()
+
+	s.logger.Debug("Running shutdown hook", "name", hook.name)
+	hook.fn(ctx)
+	s.logger.Info("Shutdown hook ran", "name", hook.name, "time_ms", time.Since(start).Milliseconds())
+}
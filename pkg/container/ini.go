@@ -0,0 +1,118 @@
+package container
+
+import (
+	"fmt"
+	"log/slog"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// IniLoader loads variables from an .ini file. Keys of a [database] section
+// become database.* variables, and a [database.pool] section database.pool.*;
+// keys before the first section have no prefix:
+//
+//	name = orders
+//
+//	[database]
+//	url = "postgres://db/app" ; inline comment
+//	hosts[] = db1
+//	hosts[] = db2
+//
+// registers name, database.url and the list database.hosts. Lines starting with
+// ; or # are comments, as is text after a ; or # preceded by a space. Values may
+// be double-quoted to keep comment characters or surrounding spaces.
+type IniLoader struct {
+	// Path to the .ini file; a missing file is skipped
+	Path string
+}
+
+// Load loads variables from the .ini file
+func (l IniLoader) Load(builder ContextBuilder) error {
+	if _, err := os.Stat(l.Path); os.IsNotExist(err) {
+		slog.Info("INI file not found, skipping", "path", l.Path)
+		return nil
+	}
+
+	data, err := os.ReadFile(l.Path)
+	if err != nil {
+		return err
+	}
+	variables, err := parseIni(string(data))
+	if err != nil {
+		return fmt.Errorf("error loading INI file %s: %w", l.Path, err)
+	}
+
+	builder.RegisterVariables(variables)
+	return nil
+}
+
+// parseIni parses an INI document into flattened variables
+func parseIni(src string) (map[string]interface{}, error) {
+	variables := make(map[string]interface{})
+	section := ""
+	for i, line := range strings.Split(src, "\n") {
+		line = strings.TrimSpace(line)
+		if line == "" || line[0] == ';' || line[0] == '#' {
+			continue
+		}
+
+		if line[0] == '[' {
+			end := strings.IndexByte(line, ']')
+			if end < 0 {
+				return nil, fmt.Errorf("line %d: unclosed section %s", i+1, line)
+			}
+			section = strings.TrimSpace(line[1:end])
+			continue
+		}
+
+		key, value, ok := strings.Cut(line, "=")
+		if !ok {
+			return nil, fmt.Errorf("line %d: expected key = value, got %s", i+1, line)
+		}
+		key = strings.TrimSpace(key)
+		value, err := iniValue(value)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %s: %w", i+1, key, err)
+		}
+
+		// key[] = value appends to the list at key
+		name, list := strings.CutSuffix(key, "[]")
+		name = joinKey(section, strings.TrimSpace(name))
+		if list {
+			items, _ := variables[name].([]interface{})
+			variables[name] = append(items, value)
+		} else {
+			variables[name] = value
+		}
+	}
+	return variables, nil
+}
+
+// iniValue unquotes a double-quoted value or strips an inline comment from the
+// text after the equals sign
+func iniValue(raw string) (string, error) {
+	value := strings.TrimSpace(raw)
+	if strings.HasPrefix(value, `"`) {
+		end := 1
+		for ; end < len(value) && value[end] != '"'; end++ {
+			if value[end] == '\\' {
+				end++
+			}
+		}
+		if end >= len(value) {
+			return "", fmt.Errorf("unterminated quoted value")
+		}
+		if rest := strings.TrimSpace(value[end+1:]); rest != "" && rest[0] != ';' && rest[0] != '#' {
+			return "", fmt.Errorf("unexpected %s after quoted value", rest)
+		}
+		return strconv.Unquote(value[:end+1])
+	}
+
+	for i := 1; i < len(raw); i++ {
+		if (raw[i] == ';' || raw[i] == '#') && (raw[i-1] == ' ' || raw[i-1] == '\t') {
+			return strings.TrimSpace(raw[:i]), nil
+		}
+	}
+	return value, nil
+}
@@ -2,12 +2,17 @@ package container
 
 import (
 	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"sort"
 	"time"
 )
 
 // ComponentInitializer handles component initialization in dependency order
 type ComponentInitializer interface {
 	InitializeAll() error
+	Initialize(names []string) error
+	Plan() ([]string, error)
 	GetInitOrder() []string
 }
 
@@ -17,7 +22,9 @@ type defaultComponentInitializer struct {
 	registry     ComponentRegistry
 	dependencies DependencyResolver
 	initialized  map[string]bool
+	failed       map[string]error
 	initOrder    []string
+	executed     bool
 	metrics      MetricsCollector
 	logger       *slog.Logger
 }
@@ -28,17 +35,46 @@ func newComponentInitializer(container *container, registry ComponentRegistry, d
 		registry:     registry,
 		dependencies: dependencies,
 		initialized:  make(map[string]bool),
+		failed:       make(map[string]error),
 		initOrder:    []string{},
 		metrics:      metrics,
 		logger:       logger,
 	}
 }
 
+// safeInit calls Init and converts a panic into a ComponentInitializationError
+func safeInit(comp Component, ctx ApplicationContext) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = ComponentInitializationError(comp.Name(), &PanicError{Value: r, Stack: debug.Stack()})
+		}
+	}()
+	return comp.Init(ctx)
+}
+
+// isCritical reports whether an initialization failure of comp must abort startup
+func isCritical(comp Component) bool {
+	if critical, ok := comp.(CriticalComponent); ok {
+		return critical.IsCritical()
+	}
+	return true
+}
+
 func (i *defaultComponentInitializer) initComponent(name string, visited map[string]bool, path []string) error {
 	if i.initialized[name] {
 		return nil
 	}
 
+	if _, failed := i.failed[name]; failed {
+		return nil
+	}
+
+	// Components of the parent container are already initialized and started
+	if i.container.isInherited(name) {
+		i.initialized[name] = true
+		return nil
+	}
+
 	if visited[name] {
 		cycle := append(path, name)
 		return CircularDependencyError(cycle)
@@ -57,24 +93,39 @@ func (i *defaultComponentInitializer) initComponent(name string, visited map[str
 
 	// Initialize dependencies first
 	if deps != nil {
-		for depName := range deps {
+		for _, depName := range i.sortedNames(deps) {
 			if depName != name { // Skip self-dependencies
 				if err := i.initComponent(depName, visited, path); err != nil {
 					return err
 				}
+				// A skipped non-critical dependency makes this component fail too
+				if depErr, failed := i.failed[depName]; failed {
+					delete(visited, name)
+					return i.handleFailure(comp, ComponentInitializationError(name, depErr))
+				}
 			}
 		}
 	}
 
 	// Initialize the component for real this time
 	i.logger.Debug("Initializing component", "name", name)
+	i.container.states.begin(name, PhaseInit)
 	start := time.Now()
-	err = comp.Init(i.container)
+	ctx := i.contextFor(name, visited, path)
+	err = safeInit(comp, ctx)
 	duration := time.Since(start)
+	i.container.states.end(name, PhaseInit, duration, err)
 
 	if err != nil {
-		i.logger.Error("Component initialization failed", "name", name, "error", err)
-		return err
+		delete(visited, name)
+		return i.handleFailure(comp, err)
+	}
+
+	// The lookups of a supplier weren't discovered, they are its dependencies
+	if _, supplied := comp.(*SuppliedComponent); supplied {
+		if x, ok := ctx.(*initContext); ok {
+			i.addDependencies(name, x.accessed)
+		}
 	}
 
 	// Record metrics
@@ -86,6 +137,7 @@ func (i *defaultComponentInitializer) initComponent(name string, visited map[str
 
 	i.initialized[name] = true
 	i.initOrder = append(i.initOrder, name)
+	i.container.states.set(name, StateInitialized)
 
 	// Remove from visited after initialization
 	delete(visited, name)
@@ -93,12 +145,236 @@ func (i *defaultComponentInitializer) initComponent(name string, visited map[str
 	return nil
 }
 
+// contextFor returns the context passed to a component's Init. With
+// Config.InitializeOnDemand, and always for suppliers, components it looks up are
+// initialized first; visited and path continue the caller's cycle detection.
+func (i *defaultComponentInitializer) contextFor(name string, visited map[string]bool, path []string) ApplicationContext {
+	if !i.container.config.InitializeOnDemand && !i.container.isSupplied(name) {
+		return i.container.contextFor(name)
+	}
+	return &initContext{
+		container: i.container,
+		base:      i.container.contextFor(name),
+		init:      i,
+		from:      name,
+		visited:   visited,
+		path:      path,
+		accessed:  make(map[string]bool),
+	}
+}
+
+// addDependencies records dependencies found while initializing a component
+func (i *defaultComponentInitializer) addDependencies(name string, deps map[string]bool) {
+	if resolver, ok := i.dependencies.(*defaultDependencyResolver); ok {
+		resolver.addDependencies(name, deps)
+	}
+}
+
+// initContext is the context of a component being initialized that initializes
+// the components it looks up before returning them
+type initContext struct {
+	*container
+	base    ApplicationContext
+	init    *defaultComponentInitializer
+	from    string
+	visited map[string]bool
+	path    []string
+	// accessed are the components looked up
+	accessed map[string]bool
+}
+
+// ensure initializes the named component unless it is the requesting one
+func (x *initContext) ensure(name string) error {
+	if name == x.from {
+		return nil
+	}
+	x.accessed[name] = true
+	if err := x.init.initComponent(name, x.visited, x.path); err != nil {
+		return err
+	}
+	if err, failed := x.init.failed[name]; failed {
+		return ComponentInitializationError(name, err)
+	}
+	return nil
+}
+
+// GetComponent resolves the component by type and initializes it before assigning it
+func (x *initContext) GetComponent(target interface{}) error {
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Ptr {
+		return ErrorWithCode("TARGET_NOT_POINTER", "target must be a pointer")
+	}
+
+	elemType := targetType.Elem()
+	match, err := selectTypeMatch(visibleMatches(x.componentRegistry, x.from, findTypeMatches(x.componentRegistry, elemType)), elemType)
+	if err != nil {
+		return err
+	}
+	if err := x.ensure(match.name); err != nil {
+		return err
+	}
+
+	match.assign(reflect.ValueOf(target).Elem())
+	return nil
+}
+
+// GetComponentByName returns the named component once it is initialized
+func (x *initContext) GetComponentByName(name string) (Component, error) {
+	comp, err := x.base.GetComponentByName(name)
+	if err != nil {
+		return nil, err
+	}
+	if err := x.ensure(name); err != nil {
+		return nil, err
+	}
+	return comp, nil
+}
+
+// GetComponentsByTag returns the tagged components once they are initialized;
+// components whose initialization failed are omitted
+func (x *initContext) GetComponentsByTag(tag string) []Component {
+	var result []Component
+	for _, comp := range x.base.GetComponentsByTag(tag) {
+		if err := x.ensure(comp.Name()); err != nil {
+			x.logger.Warn("Skipping tagged component that failed to initialize", "tag", tag, "name", comp.Name(), "error", err)
+			continue
+		}
+		result = append(result, comp)
+	}
+	return result
+}
+
+// handleFailure applies the component's failure policy: critical components abort
+// startup, non-critical ones are logged and skipped
+func (i *defaultComponentInitializer) handleFailure(comp Component, err error) error {
+	name := comp.Name()
+	if isCritical(comp) {
+		i.logger.Error("Component initialization failed", "name", name, "error", err)
+		return err
+	}
+
+	i.logger.Warn("Non-critical component initialization failed, skipping", "name", name, "error", err)
+	i.failed[name] = err
+	i.container.states.set(name, StateFailed)
+	return nil
+}
+
+// componentOrder returns the explicit order of a component (0 if unordered)
+func componentOrder(comp Component) int {
+	if ordered, ok := comp.(OrderedComponent); ok {
+		return ordered.GetOrder()
+	}
+	return 0
+}
+
+// sortedNames returns the given component names sorted by (order, name)
+// so that independent components are always processed in the same sequence
+func (i *defaultComponentInitializer) sortedNames(names map[string]bool) []string {
+	// Look the orders up once instead of in every comparison
+	type ordered struct {
+		name  string
+		order int
+	}
+	entries := make([]ordered, 0, len(names))
+	for name := range names {
+		entry := ordered{name: name}
+		if comp, err := i.registry.Get(name); err == nil {
+			entry.order = componentOrder(comp)
+		}
+		entries = append(entries, entry)
+	}
+
+	sort.Slice(entries, func(a, b int) bool {
+		if entries[a].order != entries[b].order {
+			return entries[a].order < entries[b].order
+		}
+		return entries[a].name < entries[b].name
+	})
+
+	result := make([]string, len(entries))
+	for n, entry := range entries {
+		result[n] = entry.name
+	}
+	return result
+}
+
+// allNames returns the set of all registered component names
+func (i *defaultComponentInitializer) allNames() map[string]bool {
+	names := make(map[string]bool)
+	for _, name := range i.registry.GetNames() {
+		names[name] = true
+	}
+	return names
+}
+
+// Plan returns the order in which InitializeAll will initialize components,
+// without calling Init
+func (i *defaultComponentInitializer) Plan() ([]string, error) {
+	planned := make(map[string]bool)
+	order := []string{}
+
+	var visit func(name string, visiting map[string]bool, path []string) error
+	visit = func(name string, visiting map[string]bool, path []string) error {
+		if planned[name] {
+			return nil
+		}
+		if visiting[name] {
+			return CircularDependencyError(append(path, name))
+		}
+		visiting[name] = true
+		path = append(path, name)
+
+		for _, depName := range i.sortedNames(i.dependencies.GetDependencies(name)) {
+			if depName != name {
+				if err := visit(depName, visiting, path); err != nil {
+					return err
+				}
+			}
+		}
+
+		delete(visiting, name)
+		planned[name] = true
+		if !i.container.isInherited(name) {
+			order = append(order, name)
+		}
+		return nil
+	}
+
+	for _, name := range i.sortedNames(i.allNames()) {
+		if err := visit(name, make(map[string]bool), []string{}); err != nil {
+			return nil, err
+		}
+	}
+	return order, nil
+}
+
 func (i *defaultComponentInitializer) InitializeAll() error {
-	// Initialize components in dependency order
+	// Initialize components in dependency order, roots sorted by (order, name)
 	i.logger.Info("Initializing components")
-	components := i.registry.GetAll()
+	i.executed = true
+
+	for _, name := range i.sortedNames(i.allNames()) {
+		if !i.initialized[name] {
+			if err := i.initComponent(name, make(map[string]bool), []string{}); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
 
-	for name := range components {
+// Initialize initializes only the named components and their transitive dependencies
+func (i *defaultComponentInitializer) Initialize(names []string) error {
+	i.logger.Info("Initializing component subset", "components", names)
+	i.executed = true
+
+	for _, name := range names {
+		if !i.registry.Has(name) {
+			return ComponentNotFoundError(name)
+		}
+	}
+
+	for _, name := range names {
 		if !i.initialized[name] {
 			if err := i.initComponent(name, make(map[string]bool), []string{}); err != nil {
 				return err
@@ -108,7 +384,17 @@ func (i *defaultComponentInitializer) InitializeAll() error {
 	return nil
 }
 
+// GetInitOrder returns the actual initialization order, or the planned order
+// if initialization hasn't run yet
 func (i *defaultComponentInitializer) GetInitOrder() []string {
+	if !i.executed {
+		plan, err := i.Plan()
+		if err != nil {
+			return nil
+		}
+		return plan
+	}
+
 	// Return a copy to avoid external modification
 	result := make([]string, len(i.initOrder))
 	copy(result, i.initOrder)
@@ -0,0 +1,147 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// typeMatch describes a component that can be injected into a target type
+type typeMatch struct {
+	name  string
+	value interface{}
+	exact bool
+}
+
+// findTypeMatches returns the components injectable into elemType, sorted by name.
+// Exact type matches take precedence over assignable (interface) matches. Components
+// registered with explicit exposure only match the interfaces they are exposed as.
+// The returned slice must not be modified.
+func findTypeMatches(registry ComponentRegistry, elemType reflect.Type) []typeMatch {
+	if r, ok := registry.(*defaultComponentRegistry); ok {
+		return r.typeMatches(elemType)
+	}
+	return scanTypeMatches(registry, elemType)
+}
+
+// scanTypeMatches implements findTypeMatches by checking every registered component
+func scanTypeMatches(registry ComponentRegistry, elemType reflect.Type) []typeMatch {
+	var candidates []typeMatch
+	registry.Range(func(name string, comp Component) bool {
+		candidates = append(candidates, typeMatch{name: name, value: componentValue(comp)})
+		return true
+	})
+	sort.Slice(candidates, func(i, j int) bool {
+		return candidates[i].name < candidates[j].name
+	})
+
+	var exact, assignable []typeMatch
+	for _, candidate := range candidates {
+		compType := reflect.TypeOf(candidate.value)
+
+		if exposed := registry.GetExposedTypes(candidate.name); len(exposed) > 0 {
+			for _, t := range exposed {
+				if t == elemType {
+					assignable = append(assignable, candidate)
+					break
+				}
+			}
+			continue
+		}
+
+		if compType == elemType || compType == reflect.PtrTo(elemType) {
+			candidate.exact = true
+			exact = append(exact, candidate)
+		} else if compType.AssignableTo(elemType) {
+			assignable = append(assignable, candidate)
+		}
+	}
+
+	if len(exact) > 0 {
+		return exact
+	}
+	return assignable
+}
+
+// selectTypeMatch picks the single component to inject into elemType.
+// If several components match, the one marked primary wins; otherwise the
+// lookup is ambiguous and an AMBIGUOUS_COMPONENT error lists the candidates.
+func selectTypeMatch(matches []typeMatch, elemType reflect.Type) (typeMatch, error) {
+	switch len(matches) {
+	case 0:
+		return typeMatch{}, ErrorWithCode("COMPONENT_TYPE_NOT_FOUND", "no component found matching type %v", elemType)
+	case 1:
+		return matches[0], nil
+	}
+
+	var primaries []typeMatch
+	for _, m := range matches {
+		if primary, ok := m.value.(PrimaryComponent); ok && primary.IsPrimary() {
+			primaries = append(primaries, m)
+		}
+	}
+	if len(primaries) == 1 {
+		return primaries[0], nil
+	}
+
+	candidates := matches
+	if len(primaries) > 1 {
+		candidates = primaries
+	}
+	names := make([]string, len(candidates))
+	for i, m := range candidates {
+		names[i] = m.name
+	}
+	return typeMatch{}, AmbiguousComponentError(elemType.String(), names)
+}
+
+// assign sets targetValue to the matched component
+func (m typeMatch) assign(targetValue reflect.Value) {
+	value := reflect.ValueOf(m.value)
+	if value.Type().AssignableTo(targetValue.Type()) {
+		// For targets of the component's own type like **TestComponent, or interfaces
+		targetValue.Set(value)
+	} else {
+		// For value targets and pointer components like TestComponent and *TestComponent
+		targetValue.Set(value.Elem())
+	}
+}
+
+// GetComponentAs looks up a component by name and converts it to T, unwrapping
+// registered instances. Use it to qualify a type-based lookup that is ambiguous.
+// Example: db, err := container.GetComponentAs[*sql.DB](ctx, "readOnlyDB")
+func GetComponentAs[T any](ctx ApplicationContext, name string) (T, error) {
+	var zero T
+
+	comp, err := ctx.GetComponentByName(name)
+	if err != nil {
+		return zero, err
+	}
+	if comp == nil {
+		// Not resolved yet during dependency discovery
+		return zero, ComponentNotFoundError(name)
+	}
+
+	result, ok := componentValue(comp).(T)
+	if !ok {
+		return zero, ComponentTypeError(name, reflect.TypeOf((*T)(nil)).Elem().String(), reflect.TypeOf(componentValue(comp)).String())
+	}
+	return result, nil
+}
+
+// RegisterComponentAs registers a component that is only discoverable by type via
+// the interface I; its concrete type is hidden from type-based injection.
+// Example: container.RegisterComponentAs[UserRepository](builder, &sqlUserRepository{})
+func RegisterComponentAs[I any](builder ContextBuilder, component Component) error {
+	ifaceType := reflect.TypeOf((*I)(nil)).Elem()
+	if ifaceType.Kind() != reflect.Interface {
+		return ErrorWithCode("NOT_AN_INTERFACE", "%v is not an interface type", ifaceType)
+	}
+	if component == nil {
+		return fmt.Errorf("cannot register nil component")
+	}
+	if !reflect.TypeOf(component).Implements(ifaceType) {
+		return ComponentTypeError(component.Name(), ifaceType.String(), reflect.TypeOf(component).String())
+	}
+	return builder.RegisterComponentExposedAs(component, ifaceType)
+}
@@ -0,0 +1,41 @@
+package container
+
+// InstanceComponent wraps an arbitrary value (an existing *sql.DB, a config struct,
+// a third-party client) so it can be registered and injected by type without
+// implementing Component
+type InstanceComponent struct {
+	name  string
+	value interface{}
+}
+
+// NewInstance creates a component wrapping the given value
+func NewInstance(name string, value interface{}) *InstanceComponent {
+	return &InstanceComponent{name: name, value: value}
+}
+
+// Name returns the component name
+func (i *InstanceComponent) Name() string {
+	return i.name
+}
+
+// Init is a no-op: instances are created outside the container
+func (i *InstanceComponent) Init(ApplicationContext) error {
+	return nil
+}
+
+// Value returns the wrapped value
+func (i *InstanceComponent) Value() interface{} {
+	return i.value
+}
+
+// componentValue returns the value injected for a component, unwrapping instances
+// and configuration properties
+func componentValue(comp Component) interface{} {
+	switch c := comp.(type) {
+	case *InstanceComponent:
+		return c.value
+	case *ConfigProperties:
+		return c.target
+	}
+	return comp
+}
@@ -0,0 +1,28 @@
+package container
+
+import (
+	"context"
+	"reflect"
+)
+
+// LifecycleInterceptor is a component called before the lifecycle of the other
+// components runs, e.g. to inject faults for resilience tests. Interceptors are
+// resolved once the components are initialized and called in name order.
+type LifecycleInterceptor interface {
+	// BeforeStart is called in the goroutine starting the named component, before
+	// its Start; a panic fails the startup like a panic of Start
+	BeforeStart(ctx context.Context, name string)
+	// BeforeExecute is called before every execution of the named scheduled
+	// component; a panic fails the execution like a panic of Execute
+	BeforeExecute(ctx context.Context, name string)
+}
+
+// findLifecycleInterceptors returns the LifecycleInterceptor components sorted by name
+func findLifecycleInterceptors(registry ComponentRegistry) []LifecycleInterceptor {
+	interceptorType := reflect.TypeOf((*LifecycleInterceptor)(nil)).Elem()
+	var interceptors []LifecycleInterceptor
+	for _, match := range findTypeMatches(registry, interceptorType) {
+		interceptors = append(interceptors, match.value.(LifecycleInterceptor))
+	}
+	return interceptors
+}
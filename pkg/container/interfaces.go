@@ -1,5 +1,11 @@
 package container
 
+import (
+	"context"
+	"io"
+	"reflect"
+)
+
 // ApplicationContext is the interface used by components to access container resources
 type ApplicationContext interface {
 	// GetComponent returns a component by type using a pointer to a variable of the desired type
@@ -11,12 +17,35 @@ type ApplicationContext interface {
 	GetVariable(name string) string
 	// GetVariableRaw returns the raw variable value without string conversion
 	GetVariableRaw(name string) interface{}
+	// GetVariableAs converts a variable (or a section of dot-separated variables) into target,
+	// which must be a pointer. Example: var port int; ctx.GetVariableAs("server.port", &port)
+	GetVariableAs(name string, target interface{}) error
+	// HasVariable checks if a variable exists
+	HasVariable(name string) bool
 	// HasComponent checks if a component exists
 	HasComponent(name string) bool
+	// GetActiveProfiles returns the active profiles, including the members of
+	// activated profile groups
+	GetActiveProfiles() []string
+	// GetComponentsByTag returns all components with the given tag, sorted by name
+	GetComponentsByTag(tag string) []Component
 	// GetComponentNames returns all registered component names
 	GetComponentNames() []string
 	// GetMetrics returns metrics for all components
 	GetMetrics() map[string]*ComponentMetrics
+	// RegisterShutdownHook runs fn when the container shuts down, before any
+	// component stops unless an option places it after all components or after a
+	// given one (see AfterComponentsStop and AfterComponentStop). Registering a hook
+	// of the same name again replaces it.
+	RegisterShutdownHook(name string, fn func(context.Context), opts ...ShutdownHookOption)
+	// ReloadVariables re-runs the variable loaders and applies the changes at once,
+	// e.g. from a SIGHUP handler or an admin endpoint (see VariableReloader)
+	ReloadVariables() ([]string, error)
+	// DumpConfig writes the resolved variables with secrets masked, the source of
+	// each one and the active profiles, e.g. for a support bundle:
+	//
+	//	ctx.DumpConfig(file, container.DumpJSON)
+	DumpConfig(w io.Writer, format DumpFormat) error
 }
 
 // ContextBuilder is used during container initialization
@@ -24,12 +53,73 @@ type ContextBuilder interface {
 	ApplicationContext
 	// RegisterComponent adds a component to the container
 	RegisterComponent(component Component) error
-	// RegisterVariable adds a variable to the container
+	// RegisterComponentWithTags adds a component with additional tags
+	RegisterComponentWithTags(component Component, tags ...string) error
+	// RegisterComponentExposedAs adds a component that is only injectable by type via
+	// the given interface types (see RegisterComponentAs for a type-safe variant)
+	RegisterComponentExposedAs(component Component, types ...reflect.Type) error
+	// RegisterInstance adds an arbitrary value to the container under the given name.
+	// The value is injected by its own type via GetComponent.
+	RegisterInstance(name string, value interface{}) error
+	// RegisterSupplier registers the name of a component that build constructs when
+	// the component is initialized, after the variables are bound and the components
+	// build looks up are initialized; they become its dependencies. Components not
+	// needed, e.g. outside a StartSubset, are never built. Until it is built the
+	// component is only found by name.
+	RegisterSupplier(name string, build func(ApplicationContext) (Component, error)) error
+	// RegisterVariable adds a variable to the container, preserving its type
+	// (ints, bools, maps and slices stay typed for GetVariableRaw and GetVariableAs)
 	RegisterVariable(name string, value interface{})
+	// RegisterVariables adds several variables at once, e.g. the flattened keys of a
+	// configuration file
+	RegisterVariables(variables map[string]interface{})
+	// RegisterDefaultVariable adds a default value that is used while no loader, starter
+	// or setup block registered the variable, e.g. for starter defaults users override
+	// from YAML or the environment
+	RegisterDefaultVariable(name string, value interface{})
+	// RegisterVariableString adds a string variable to the container
+	RegisterVariableString(name string, value string)
+	// DeclareVariable declares a variable with its type, default and whether it is
+	// required. The container fails to start, and reloads fail, when a declared
+	// variable is missing or doesn't convert to its type.
+	//
+	//	builder.DeclareVariable("server.port", container.VarSpec{Type: container.Int, Default: 8080})
+	DeclareVariable(name string, spec VarSpec)
 	// AddVariableLoader adds a variable loader
 	AddVariableLoader(loader VariableLoader)
+	// ActivateProfiles activates profiles (see GetActiveProfiles)
+	ActivateProfiles(profiles ...string)
+	// AddEnvironmentPostProcessor adds a post-processor that derives variables
+	// after all loaders ran and before the starters
+	AddEnvironmentPostProcessor(processor EnvironmentPostProcessor)
 	// RegisterFactory adds a component factory
 	RegisterFactory(factory Factory)
 	// RegisterStarter adds a starter to the container
 	RegisterStarter(starter Starter)
+	// RegisterModule registers a group of components whose non-provided members
+	// are only visible inside the module
+	RegisterModule(module Module) error
+}
+
+// ContainerInspector exposes the resolved wiring of a started container
+type ContainerInspector interface {
+	// GetDependencies returns the sorted names of the components the named component depends on
+	GetDependencies(name string) []string
+	// GetInitOrder returns component names in the order they were initialized
+	GetInitOrder() []string
+	// GetComponentReport returns every component with its live state, dependencies,
+	// dependents and timings, sorted by name
+	GetComponentReport() []ComponentReport
+	// GetShutdownReport returns the slow components and leaked goroutines of the
+	// last shutdown, nil before the container was shut down
+	GetShutdownReport() *ShutdownReport
+	ComponentFinder
+}
+
+// ComponentFinder describes components without getting them, so that a component
+// looking up others by interface or tag only depends on the ones it uses
+type ComponentFinder interface {
+	// FindComponents returns the components matching pred (all if pred is nil) with
+	// their type, well-known interfaces, tags and state, sorted by name
+	FindComponents(pred func(ComponentInfo) bool) []ComponentInfo
 }
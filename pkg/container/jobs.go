@@ -0,0 +1,271 @@
+package container
+
+import (
+	"context"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ScheduledJob controls the schedule of a started ScheduledComponent
+type ScheduledJob struct {
+	name      string
+	schedule  Schedule
+	component ScheduledComponent
+
+	mu     sync.Mutex
+	paused bool
+	// location and calendars resolve the TimeZone and Exclusions of the schedule
+	location  *time.Location
+	calendars []*calendar
+	lastRun   time.Time
+	lastFire  time.Time
+	// notify asks the scheduler for an immediate execution
+	notify func()
+}
+
+// JobScheduler is implemented by contexts whose scheduled components can be controlled
+type JobScheduler interface {
+	// GetScheduledJobs returns the jobs of all started scheduled components, sorted by name
+	GetScheduledJobs() []*ScheduledJob
+}
+
+func newScheduledJob(name string, component ScheduledComponent, schedule Schedule) *ScheduledJob {
+	return &ScheduledJob{
+		name:      name,
+		schedule:  schedule,
+		component: component,
+		location:  time.UTC,
+	}
+}
+
+// Name returns the component name
+func (j *ScheduledJob) Name() string {
+	return j.name
+}
+
+// Schedule returns the component's schedule, with the values of its Property section
+func (j *ScheduledJob) Schedule() Schedule {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.schedule
+}
+
+// setSchedule replaces the schedule after variables were reloaded
+func (j *ScheduledJob) setSchedule(schedule Schedule) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.schedule = schedule
+}
+
+// setExclusions replaces the time zone and calendars of the schedule
+func (j *ScheduledJob) setExclusions(location *time.Location, calendars []*calendar) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.location = location
+	j.calendars = calendars
+}
+
+// exclusions returns the time zone and calendars of the schedule
+func (j *ScheduledJob) exclusions() (*time.Location, []*calendar) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.location, j.calendars
+}
+
+// Pause skips the scheduled executions until Resume is called
+func (j *ScheduledJob) Pause() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.paused = true
+}
+
+// Resume continues the scheduled executions
+func (j *ScheduledJob) Resume() {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.paused = false
+}
+
+// Paused reports whether the job is paused
+func (j *ScheduledJob) Paused() bool {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.paused
+}
+
+// Trigger requests an immediate execution, even while paused. Triggers made
+// while an execution is already pending are merged.
+func (j *ScheduledJob) Trigger() {
+	j.mu.Lock()
+	notify := j.notify
+	j.mu.Unlock()
+
+	if notify != nil {
+		notify()
+	}
+}
+
+// setNotify sets the function Trigger calls
+func (j *ScheduledJob) setNotify(notify func()) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.notify = notify
+}
+
+// LastRun returns when the last execution started (zero if it never ran)
+func (j *ScheduledJob) LastRun() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.lastRun
+}
+
+// LastFire returns the time of the last run that fell due, whether it executed,
+// was paused or skipped (zero if no run fell due yet)
+func (j *ScheduledJob) LastFire() time.Time {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	return j.lastFire
+}
+
+// setLastFire records the time of the last run that fell due
+func (j *ScheduledJob) setLastFire(fired time.Time) {
+	j.mu.Lock()
+	defer j.mu.Unlock()
+	j.lastFire = fired
+}
+
+// execute runs the component once, recording the start on the scheduler's clock
+func (j *ScheduledJob) execute(ctx context.Context, clock Clock) {
+	j.mu.Lock()
+	j.lastRun = clock.Now()
+	j.mu.Unlock()
+
+	j.component.Execute(ctx)
+}
+
+// GetScheduledJobs returns the jobs of all started scheduled components, sorted by name
+func (c *container) GetScheduledJobs() []*ScheduledJob {
+	if c.lifecycleManager == nil {
+		return nil
+	}
+	return c.lifecycleManager.ScheduledJobs()
+}
+
+// ScheduledJobs returns the jobs started so far, sorted by name
+func (m *defaultLifecycleManager) ScheduledJobs() []*ScheduledJob {
+	m.jobsMu.Lock()
+	defer m.jobsMu.Unlock()
+
+	jobs := make([]*ScheduledJob, 0, len(m.jobs))
+	for _, job := range m.jobs {
+		jobs = append(jobs, job)
+	}
+	sort.Slice(jobs, func(i, j int) bool {
+		return jobs[i].name < jobs[j].name
+	})
+	return jobs
+}
+
+// Ensure that container implements JobScheduler
+var _ JobScheduler = (*container)(nil)
+
+// scheduleKeys maps the keys of a schedule's Property section to its fields
+var scheduleKeys = map[string]func(s *Schedule) interface{}{
+	"interval":       func(s *Schedule) interface{} { return &s.Interval },
+	"initial-delay":  func(s *Schedule) interface{} { return &s.InitialDelay },
+	"run-on-startup": func(s *Schedule) interface{} { return &s.RunOnStartup },
+	"missed-runs":    func(s *Schedule) interface{} { return &s.MissedRuns },
+	"jitter":         func(s *Schedule) interface{} { return &s.Jitter },
+	"align-to":       func(s *Schedule) interface{} { return &s.AlignTo },
+	"time-zone":      func(s *Schedule) interface{} { return &s.TimeZone },
+	"exclusions":     func(s *Schedule) interface{} { return &s.Exclusions },
+	"excluded-runs":  func(s *Schedule) interface{} { return &s.ExcludedRuns },
+}
+
+// resolveSchedule overrides the fields of a schedule with the values of its Property
+// section; a value that can't be converted is logged and ignored
+func (m *defaultLifecycleManager) resolveSchedule(name string, schedule Schedule) Schedule {
+	if schedule.Property == "" || m.app == nil {
+		return schedule
+	}
+
+	for key, field := range scheduleKeys {
+		variable := schedule.Property + "." + key
+		if !m.app.HasVariable(variable) {
+			continue
+		}
+		resolved := schedule
+		if err := m.app.GetVariableAs(variable, field(&resolved)); err != nil {
+			m.logger.Error("Invalid schedule variable", "name", name, "variable", variable, "error", err)
+			continue
+		}
+		schedule = resolved
+	}
+	return schedule
+}
+
+// ReloadSchedules reads the schedules of the scheduled components again and
+// reschedules the components whose schedule changed
+func (m *defaultLifecycleManager) ReloadSchedules() {
+	m.jobsMu.Lock()
+	scheduler := m.scheduler
+	m.jobsMu.Unlock()
+	if scheduler == nil {
+		return
+	}
+
+	for _, job := range m.ScheduledJobs() {
+		schedule := m.resolveSchedule(job.name, job.component.GetSchedule())
+		// Calendars may change without the schedule, they apply from the next run
+		job.setExclusions(m.resolveExclusions(job.name, schedule))
+		old := job.Schedule()
+		if reflect.DeepEqual(schedule, old) {
+			continue
+		}
+		if schedule.Interval <= 0 {
+			m.logger.Error("Ignoring schedule without positive interval", "name", job.name, "interval", schedule.Interval.String())
+			continue
+		}
+
+		job.setSchedule(schedule)
+		scheduler.reschedule(job.name)
+		m.logger.Info("Schedule updated",
+			"name", job.name,
+			"interval", schedule.Interval.String(),
+			"previous_interval", old.Interval.String())
+	}
+}
+
+// resolveExclusions returns the time zone and the exclusion calendars of a
+// schedule; an invalid time zone falls back to UTC and unknown or invalid
+// calendars are ignored, each logged
+func (m *defaultLifecycleManager) resolveExclusions(name string, schedule Schedule) (*time.Location, []*calendar) {
+	location, err := schedule.location()
+	if err != nil {
+		m.logger.Error("Invalid schedule time zone, using UTC", "name", name, "time_zone", schedule.TimeZone, "error", err)
+		location = time.UTC
+	}
+
+	var calendars []*calendar
+	for _, calendarName := range schedule.Exclusions {
+		variable := PropertyCalendars + "." + calendarName
+		if m.app == nil || !NewVariableHelper(m.app).HasSection(variable) {
+			m.logger.Error("Unknown schedule exclusion calendar", "name", name, "calendar", calendarName)
+			continue
+		}
+		var config Calendar
+		if err := m.app.GetVariableAs(variable, &config); err != nil {
+			m.logger.Error("Invalid schedule exclusion calendar", "name", name, "calendar", calendarName, "error", err)
+			continue
+		}
+		parsed, err := parseCalendar(calendarName, config)
+		if err != nil {
+			m.logger.Error("Invalid schedule exclusion calendar", "name", name, "calendar", calendarName, "error", err)
+			continue
+		}
+		calendars = append(calendars, parsed)
+	}
+	return location, calendars
+}
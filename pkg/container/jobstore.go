@@ -0,0 +1,93 @@
+package container
+
+import (
+	"encoding/json"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+)
+
+// JobStore persists the last due time the scheduler handled for each scheduled
+// component. A component with a stored time continues its schedule from it
+// instead of starting over, ignoring its InitialDelay.
+type JobStore interface {
+	// LastFire returns the stored time of a component, zero if there is none
+	LastFire(name string) (time.Time, error)
+	// SaveLastFire stores the time of a component
+	SaveLastFire(name string, fired time.Time) error
+}
+
+// FileJobStore is a JobStore keeping the times of all components in a JSON file
+type FileJobStore struct {
+	path string
+
+	mu     sync.Mutex
+	times  map[string]time.Time
+	loaded bool
+}
+
+// NewFileJobStore creates a store backed by the file at path, created on the first save
+func NewFileJobStore(path string) *FileJobStore {
+	return &FileJobStore{path: path}
+}
+
+// LastFire returns the stored time of a component
+func (s *FileJobStore) LastFire(name string) (time.Time, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return time.Time{}, err
+	}
+	return s.times[name], nil
+}
+
+// SaveLastFire stores the time of a component and rewrites the file
+func (s *FileJobStore) SaveLastFire(name string, fired time.Time) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.load(); err != nil {
+		return err
+	}
+	s.times[name] = fired
+
+	data, err := json.MarshalIndent(s.times, "", "  ")
+	if err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a crash never leaves a truncated file
+	tmp := s.path + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o644); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path)
+}
+
+// load reads the file once; a missing file is an empty store
+func (s *FileJobStore) load() error {
+	if s.loaded {
+		return nil
+	}
+
+	s.times = make(map[string]time.Time)
+	data, err := os.ReadFile(s.path)
+	if errors.Is(err, fs.ErrNotExist) {
+		s.loaded = true
+		return os.MkdirAll(filepath.Dir(s.path), 0o755)
+	}
+	if err != nil {
+		return err
+	}
+	if err := json.Unmarshal(data, &s.times); err != nil {
+		return err
+	}
+	s.loaded = true
+	return nil
+}
+
+// Ensure that FileJobStore implements JobStore
+var _ JobStore = (*FileJobStore)(nil)
@@ -0,0 +1,64 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"sync"
+)
+
+// GetComponentLazy sets target, a pointer to a func() T, to a provider that looks up
+// the component of type T on its first call. Unlike GetComponent the lookup isn't a
+// dependency, so components that genuinely reference each other (A notifies B,
+// B queries A) don't cause a CircularDependencyError:
+//
+//	type A struct {
+//		container.ComponentBase
+//		b func() *B
+//	}
+//
+//	func (a *A) Init(ctx container.ApplicationContext) error {
+//		return container.GetComponentLazy(ctx, &a.b)
+//	}
+//
+// Call the provider only once the container has started (e.g. in Start or in request
+// handlers), not from Init. It panics if the component can't be found.
+func GetComponentLazy(ctx ApplicationContext, target interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if target == nil || targetValue.Kind() != reflect.Pointer || targetValue.IsNil() {
+		return ErrorWithCode("TARGET_NOT_POINTER", "target must be a pointer")
+	}
+
+	funcType := targetValue.Type().Elem()
+	if funcType.Kind() != reflect.Func || funcType.NumIn() != 0 || funcType.NumOut() != 1 {
+		return ErrorWithCode("INVALID_LAZY_TARGET", "target must be a pointer to a func() T, got %T", target)
+	}
+
+	componentType := funcType.Out(0)
+	var mu sync.Mutex
+	var component reflect.Value
+	provider := reflect.MakeFunc(funcType, func([]reflect.Value) []reflect.Value {
+		mu.Lock()
+		defer mu.Unlock()
+
+		// Cache only successful lookups so that a too early call can be retried
+		if !component.IsValid() {
+			resolved := reflect.New(componentType)
+			if err := ctx.GetComponent(resolved.Interface()); err != nil {
+				panic(fmt.Sprintf("lazy component %v: %v", componentType, err))
+			}
+			component = resolved.Elem()
+		}
+		return []reflect.Value{component}
+	})
+
+	targetValue.Elem().Set(provider)
+	return nil
+}
+
+// Lazy returns a provider for the component of type T; see GetComponentLazy
+func Lazy[T any](ctx ApplicationContext) func() T {
+	var provider func() T
+	// The target is always a valid func pointer, so this can't fail
+	_ = GetComponentLazy(ctx, &provider)
+	return provider
+}
@@ -3,7 +3,9 @@ package container
 import (
 	"context"
 	"fmt"
+	"io"
 	"log/slog"
+	"runtime/debug"
 	"sync"
 	"time"
 )
@@ -12,40 +14,124 @@ import (
 type ComponentLifecycleManager interface {
 	StartAll(ctx context.Context) error
 	StopAll(ctx context.Context)
+	ScheduledJobs() []*ScheduledJob
+	ShutdownReport() *ShutdownReport
+	ReloadSchedules()
 }
 
 // defaultLifecycleManager implements ComponentLifecycleManager
 type defaultLifecycleManager struct {
-	registry  ComponentRegistry
-	initOrder []string
-	metrics   MetricsCollector
-	logger    *slog.Logger
+	registry     ComponentRegistry
+	dependencies DependencyResolver
+	initOrder    []string
+	metrics      MetricsCollector
+	states       *componentStates
+	clock        Clock
+	logger       *slog.Logger
+
+	jobsMu    sync.Mutex
+	jobs      map[string]*ScheduledJob
+	scheduler *jobScheduler
+	jobStore  JobStore
+	// stateStore keeps the state of stateful components, nil if disabled
+	stateStore StateStore
+	// app resolves the Property sections of schedules
+	app ApplicationContext
+	// hooks run before, between and after the components stop
+	hooks *shutdownHooks
+
+	// interceptors are resolved when the components start
+	interceptors []LifecycleInterceptor
+
+	// goroutines run the background and scheduled components
+	goroutines *goroutineTracker
+	stopBudget time.Duration
+	reportMu   sync.Mutex
+	slowStops  []SlowStop
+	report     *ShutdownReport
 }
 
-func newLifecycleManager(registry ComponentRegistry, initOrder []string, metrics MetricsCollector, logger *slog.Logger) *defaultLifecycleManager {
+func newLifecycleManager(registry ComponentRegistry, dependencies DependencyResolver, initOrder []string, metrics MetricsCollector, states *componentStates, clock Clock, stopBudget time.Duration, jobStore JobStore, stateStore StateStore, hooks *shutdownHooks, app ApplicationContext, logger *slog.Logger) *defaultLifecycleManager {
+	if stopBudget <= 0 {
+		stopBudget = DefaultStopBudget
+	}
 	return &defaultLifecycleManager{
-		registry:  registry,
-		initOrder: initOrder,
-		metrics:   metrics,
-		logger:    logger,
+		registry:     registry,
+		dependencies: dependencies,
+		initOrder:    initOrder,
+		metrics:      metrics,
+		states:       states,
+		clock:        clock,
+		logger:       logger,
+		jobs:         make(map[string]*ScheduledJob),
+		goroutines:   newGoroutineTracker(),
+		stopBudget:   stopBudget,
+		jobStore:     jobStore,
+		stateStore:   stateStore,
+		hooks:        hooks,
+		app:          app,
+	}
+}
+
+// startWaves groups the initialized components into topological waves: every
+// component is placed in the wave after the last wave containing one of its dependencies
+func (m *defaultLifecycleManager) startWaves() [][]string {
+	level := make(map[string]int, len(m.initOrder))
+	var waves [][]string
+
+	// initOrder is already topologically sorted, so dependencies have their level assigned
+	for _, name := range m.initOrder {
+		wave := 0
+		for dep := range m.dependencies.GetDependencies(name) {
+			if depLevel, ok := level[dep]; ok && dep != name && depLevel+1 > wave {
+				wave = depLevel + 1
+			}
+		}
+		level[name] = wave
+
+		for len(waves) <= wave {
+			waves = append(waves, nil)
+		}
+		waves[wave] = append(waves[wave], name)
 	}
+
+	return waves
 }
 
 func (m *defaultLifecycleManager) StartAll(ctx context.Context) error {
-	// Start components in dependency order
+	// Start components in dependency order: components within a wave start in
+	// parallel, and a wave only begins once every Start of the previous wave returned
 	m.logger.Info("Starting components")
+	m.interceptors = findLifecycleInterceptors(m.registry)
+
+	for i, wave := range m.startWaves() {
+		m.logger.Debug("Starting component wave", "wave", i, "components", wave)
+		if err := m.startWave(ctx, wave); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
 
+// startWave starts the lifecycle components of one wave concurrently and waits for them
+func (m *defaultLifecycleManager) startWave(ctx context.Context, names []string) error {
 	// Use a WaitGroup to track all component startups
 	var wg sync.WaitGroup
 	// Channel to collect any errors from goroutines
-	errChan := make(chan error, len(m.initOrder))
+	errChan := make(chan error, len(names))
 
-	for _, name := range m.initOrder {
+	for _, name := range names {
 		component, err := m.registry.Get(name)
 		if err != nil {
 			return err
 		}
 
+		// Components without lifecycle only get their state restored
+		if _, ok := component.(LifecycleComponent); !ok {
+			m.restoreState(ctx, component, name)
+		}
+
 		// Start lifecycle components
 		if lifecycle, ok := component.(LifecycleComponent); ok {
 			m.logger.Debug("Starting component", "name", name)
@@ -55,19 +141,31 @@ func (m *defaultLifecycleManager) StartAll(ctx context.Context) error {
 			go func(comp LifecycleComponent, compName string) {
 				defer wg.Done()
 
+				m.states.begin(compName, PhaseStart)
 				start := time.Now()
 
 				// Capture panics in component startup
 				defer func() {
 					if r := recover(); r != nil {
-						errChan <- fmt.Errorf("panic in component %s startup: %v", compName, r)
+						err := fmt.Errorf("panic in component %s startup: %v", compName, r)
+						m.reportPanic(ctx, ErrorKindStart, compName, err, string(PhaseStart))
+						m.metrics.RecordPanic(compName)
+						m.metrics.RecordError(compName)
+						m.states.end(compName, PhaseStart, time.Since(start), err)
+						errChan <- err
 					}
 				}()
 
+				m.restoreState(ctx, comp, compName)
+				for _, interceptor := range m.interceptors {
+					interceptor.BeforeStart(ctx, compName)
+				}
 				comp.Start(ctx)
 				duration := time.Since(start)
+				m.states.end(compName, PhaseStart, duration, nil)
 
 				m.metrics.RecordStartDuration(compName, duration)
+				m.states.set(compName, StateStarted)
 
 				m.logger.Info("Component started",
 					"name", compName,
@@ -112,137 +210,200 @@ func (m *defaultLifecycleManager) startBackgroundComponent(ctx context.Context,
 	m.logger.Debug("Starting background component", "name", name)
 
 	// Launch the component in a goroutine
-	go func(bgComponent BackgroundComponent, componentName string) {
-		m.logger.Info("Background component running", "name", componentName)
+	m.goroutines.launch(ctx, name, goroutineBackground, func(ctx context.Context) {
+		m.logger.Info("Background component running", "name", name)
 
 		// Run the component's main logic
-		bgComponent.Run(ctx)
+		m.runBackground(ctx, component, name)
 
-		m.logger.Info("Background component completed", "name", componentName)
-	}(component, name)
+		m.logger.Info("Background component completed", "name", name)
+	})
 }
 
-func (m *defaultLifecycleManager) startScheduledComponent(ctx context.Context, component ScheduledComponent, name string) {
-	m.logger.Debug("Starting scheduled component", "name", name)
+// runBackground runs a background component, recording and reporting a panic in
+// Run before passing it on
+func (m *defaultLifecycleManager) runBackground(ctx context.Context, component BackgroundComponent, name string) {
+	defer func() {
+		if r := recover(); r != nil {
+			m.reportPanic(ctx, ErrorKindPanic, name, fmt.Errorf("panic in background component %s: %v", name, r), "run")
+			m.metrics.RecordPanic(name)
+			m.logger.Error("Panic in background component", "name", name, "error", r)
+			panic(r)
+		}
+	}()
 
-	// Get schedule
-	schedule := component.GetSchedule()
+	component.Run(ctx)
+}
 
-	// Launch the component's scheduler in a goroutine
-	go func(schedComponent ScheduledComponent, componentName string, sched Schedule) {
-		// Run immediately if configured
-		if sched.RunOnStartup {
-			m.logger.Debug("Executing scheduled component on startup", "name", componentName)
-			schedComponent.Execute(ctx)
+// runJob executes a scheduled job once, recording its duration and recovering panics
+func (m *defaultLifecycleManager) runJob(ctx context.Context, job *ScheduledJob) {
+	start := time.Now()
+	defer func() {
+		r := recover()
+		if r != nil {
+			m.reportPanic(ctx, ErrorKindScheduled, job.name, fmt.Errorf("panic in scheduled component %s: %v", job.name, r), "execute")
+			m.metrics.RecordPanic(job.name)
+			m.logger.Error("Panic in scheduled component", "name", job.name, "error", r)
 		}
+		m.metrics.RecordExecution(job.name, time.Since(start), r != nil)
+	}()
 
-		// Wait for initial delay
-		if sched.InitialDelay > 0 {
-			select {
-			case <-ctx.Done():
-				return
-			case <-time.After(sched.InitialDelay):
-				// Continue after delay
-			}
-		}
+	for _, interceptor := range m.interceptors {
+		interceptor.BeforeExecute(ctx, job.name)
+	}
+	job.execute(ctx, m.clock)
+}
 
-		// Set up ticker for recurring execution
-		ticker := time.NewTicker(sched.Interval)
-		defer ticker.Stop()
+func (m *defaultLifecycleManager) startScheduledComponent(ctx context.Context, component ScheduledComponent, name string) {
+	m.logger.Debug("Starting scheduled component", "name", name)
 
-		m.logger.Info("Scheduled component running",
-			"name", componentName,
-			"interval", sched.Interval.String())
+	// Register the job so that it can be paused and triggered
+	job := newScheduledJob(name, component, m.resolveSchedule(name, component.GetSchedule()))
+	job.setExclusions(m.resolveExclusions(name, job.Schedule()))
+	m.jobsMu.Lock()
+	m.jobs[name] = job
+	if m.scheduler == nil {
+		m.scheduler = newJobScheduler(m.clock, findJobExecutor(m.registry), m.jobStore, m.goroutines, m.runJob, m.metrics, m.logger)
+	}
+	scheduler := m.scheduler
+	m.jobsMu.Unlock()
 
-		// Run the scheduled executions
-		for {
-			select {
-			case <-ctx.Done():
-				m.logger.Info("Scheduled component stopping due to context cancellation",
-					"name", componentName)
-				return
-			case <-ticker.C:
-				m.logger.Debug("Executing scheduled component", "name", componentName)
-				schedComponent.Execute(ctx)
-			}
-		}
-	}(component, name, schedule)
+	// All scheduled components share the scheduler's goroutine
+	scheduler.add(ctx, job)
 }
 
 func (m *defaultLifecycleManager) StopAll(ctx context.Context) {
 	m.logger.Info("Stopping components")
+	start := time.Now()
+	defer m.finishShutdown(start)
+	m.hooks.runBefore(ctx)
+
+	// Stop in reverse start waves so that dependent components always stop
+	// before their dependencies, while independent ones stop concurrently
+	waves := m.startWaves()
+	for i := len(waves) - 1; i >= 0; i-- {
+		m.stopWave(ctx, waves[i])
+	}
 
-	// Create a batch system to control concurrent shutdowns
-	// This allows us to shut down in reverse init order but in batches
-	// so that dependent components don't shut down before their dependencies
-	batchSize := 5 // Shutdown 5 components at a time
-
-	// Group components by initialization order (in reverse)
-	totalComponents := len(m.initOrder)
-	batches := (totalComponents + batchSize - 1) / batchSize // Ceiling division
-
-	for batch := 0; batch < batches; batch++ {
-		startIdx := totalComponents - (batch * batchSize) - 1
-		endIdx := max(totalComponents-((batch+1)*batchSize), 0)
+	m.jobsMu.Lock()
+	scheduler := m.scheduler
+	m.jobsMu.Unlock()
+	if scheduler != nil {
+		scheduler.stop()
+	}
+	m.hooks.runAfter(ctx)
+}
 
-		// Process each batch
-		batchWg := sync.WaitGroup{}
+// stopWave stops the components of one wave concurrently and waits for them.
+// Lifecycle components are stopped; other components (or instance values)
+// implementing io.Closer are closed.
+func (m *defaultLifecycleManager) stopWave(ctx context.Context, names []string) {
+	waveWg := sync.WaitGroup{}
 
-		// Start shutdown for components in this batch
-		for i := startIdx; i >= endIdx; i-- {
-			if i < 0 || i >= totalComponents {
-				continue
-			}
+	for _, name := range names {
+		component, err := m.registry.Get(name)
+		if err != nil {
+			m.logger.Error("Error getting component during shutdown",
+				"name", name,
+				"error", err)
+			continue
+		}
 
-			name := m.initOrder[i]
-			component, err := m.registry.Get(name)
-			if err != nil {
-				m.logger.Error("Error getting component during shutdown",
-					"name", name,
-					"error", err)
-				continue
+		var stop func() error
+		if lifecycle, ok := component.(LifecycleComponent); ok {
+			stop = func() error {
+				lifecycle.Stop(ctx)
+				return nil
 			}
+		} else if closer, ok := componentValue(component).(io.Closer); ok {
+			stop = closer.Close
+		} else if _, ok := component.(StatefulComponent); ok {
+			stop = func() error { return nil }
+		} else {
+			continue
+		}
 
-			if lifecycle, ok := component.(LifecycleComponent); ok {
-				batchWg.Add(1)
-
-				// Stop each component in its own goroutine
-				go func(comp LifecycleComponent, compName string) {
-					defer batchWg.Done()
+		waveWg.Add(1)
+
+		// Stop each component in its own goroutine
+		go func(compName string, component Component, stop func() error) {
+			defer waveWg.Done()
+			// Once stopped, its background goroutine or running execution is
+			// cancelled too, and it isn't scheduled anymore
+			defer m.goroutines.cancel(compName)
+			defer m.unschedule(compName)
+			// Hooks after the component run once it stopped, even if it panicked
+			defer m.hooks.runAfterComponent(ctx, compName)
+
+			m.logger.Debug("Stopping component", "name", compName)
+
+			m.states.begin(compName, PhaseStop)
+			start := time.Now()
+
+			// Capture panics in component shutdown
+			defer func() {
+				if r := recover(); r != nil {
+					err := fmt.Errorf("panic in component %s shutdown: %v", compName, r)
+					m.reportPanic(ctx, ErrorKindPanic, compName, err, string(PhaseStop))
+					m.states.end(compName, PhaseStop, time.Since(start), err)
+					m.metrics.RecordPanic(compName)
+					m.metrics.RecordError(compName)
+					m.logger.Error("Panic in component shutdown",
+						"name", compName,
+						"error", r)
+				}
+			}()
 
-					m.logger.Debug("Stopping component", "name", compName)
+			err := stop()
+			m.saveState(ctx, component, compName)
+			duration := time.Since(start)
+			m.states.end(compName, PhaseStop, duration, err)
+			m.recordStop(compName, duration)
 
-					// Capture panics in component shutdown
-					defer func() {
-						if r := recover(); r != nil {
-							m.logger.Error("Panic in component shutdown",
-								"name", compName,
-								"error", r)
-						}
-					}()
+			m.metrics.RecordStopDuration(compName, duration)
+			m.states.set(compName, StateStopped)
 
-					start := time.Now()
-					comp.Stop(ctx)
-					duration := time.Since(start)
+			if err != nil {
+				m.metrics.RecordError(compName)
+				m.logger.Error("Error closing component",
+					"name", compName,
+					"error", err)
+				return
+			}
 
-					m.metrics.RecordStopDuration(compName, duration)
+			m.logger.Info("Component stopped",
+				"name", compName,
+				"time_ms", duration.Milliseconds())
+		}(name, component, stop)
+	}
 
-					m.logger.Info("Component stopped",
-						"name", compName,
-						"time_ms", duration.Milliseconds())
-				}(lifecycle, name)
-			}
-		}
+	// Wait for all components in this wave to stop before moving to the next one
+	waveWg.Wait()
+}
 
-		// Wait for all components in this batch to stop before moving to the next batch
-		batchWg.Wait()
+// reportPanic passes a recovered panic with the stack of the current goroutine to
+// the ErrorReporter components
+func (m *defaultLifecycleManager) reportPanic(ctx context.Context, kind ErrorKind, name string, err error, phase string) {
+	if m.app == nil {
+		return
 	}
+	ReportError(ctx, m.app, ErrorReport{
+		Kind:      kind,
+		Component: name,
+		Err:       err,
+		Panicked:  true,
+		Stack:     debug.Stack(),
+		Time:      m.clock.Now(),
+		Metadata:  map[string]string{"phase": phase},
+	})
 }
 
-// Helper function for Go versions before 1.21 which don't have max in the std lib
-func max(a, b int) int {
-	if a > b {
-		return a
+// unschedule stops the scheduled executions of a stopped component
+func (m *defaultLifecycleManager) unschedule(name string) {
+	m.jobsMu.Lock()
+	scheduler := m.scheduler
+	m.jobsMu.Unlock()
+	if scheduler != nil {
+		scheduler.remove(name)
 	}
-	return b
 }
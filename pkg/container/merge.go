@@ -0,0 +1,112 @@
+package container
+
+import (
+	"fmt"
+	"strings"
+)
+
+// PropertyMergePrefix prefixes merge strategies configured in application.yml, e.g.
+// goboot.config.merge.servers: append
+const PropertyMergePrefix = "goboot.config.merge."
+
+// MergeStrategy defines how a profile file combines a list or map with the value
+// loaded before it. By default maps are merged key by key and lists are replaced.
+type MergeStrategy string
+
+const (
+	// MergeReplace replaces the whole list or map
+	MergeReplace MergeStrategy = "replace"
+	// MergeDeep merges maps key by key (the default for maps)
+	MergeDeep MergeStrategy = "merge"
+	// MergeAppend appends the profile's list items to the earlier ones
+	MergeAppend MergeStrategy = "append"
+)
+
+// mergeByKeyPrefix prefixes MergeByKey strategies
+const mergeByKeyPrefix = "merge-by-key:"
+
+// MergeByKey merges lists of maps item by item: items whose key field is equal are
+// merged, other items are appended. In YAML it is written "merge-by-key:<key>".
+func MergeByKey(key string) MergeStrategy {
+	return MergeStrategy(mergeByKeyPrefix + key)
+}
+
+// mergeStrategiesFrom returns the strategies configured under goboot.config.merge
+func mergeStrategiesFrom(config map[string]interface{}) map[string]MergeStrategy {
+	flattened := make(map[string]interface{})
+	flattenMap(config, "", flattened)
+
+	strategies := make(map[string]MergeStrategy)
+	for key, value := range flattened {
+		if path, ok := strings.CutPrefix(key, PropertyMergePrefix); ok {
+			strategies[path] = MergeStrategy(fmt.Sprint(value))
+		}
+	}
+	return strategies
+}
+
+// mergeConfig merges overlay into a copy of base; path is the dot-separated key of
+// base, used to look up strategies
+func mergeConfig(base, overlay map[string]interface{}, path string, strategies map[string]MergeStrategy) map[string]interface{} {
+	result := make(map[string]interface{}, len(base)+len(overlay))
+	for key, value := range base {
+		result[key] = value
+	}
+
+	for key, value := range overlay {
+		keyPath := joinPath(path, key)
+		result[key] = mergeValue(result[key], value, keyPath, strategies)
+	}
+	return result
+}
+
+// mergeValue combines an overlay value with the earlier value at path
+func mergeValue(base, overlay interface{}, path string, strategies map[string]MergeStrategy) interface{} {
+	strategy := strategies[path]
+
+	if overlayMap, ok := stringKeyMap(overlay); ok {
+		if baseMap, ok := stringKeyMap(base); ok && strategy != MergeReplace {
+			return mergeConfig(baseMap, overlayMap, path, strategies)
+		}
+		return overlayMap
+	}
+
+	overlayList, ok := overlay.([]interface{})
+	if !ok {
+		return overlay
+	}
+	baseList, ok := base.([]interface{})
+	if !ok {
+		return overlayList
+	}
+
+	if strategy == MergeAppend {
+		return append(append([]interface{}(nil), baseList...), overlayList...)
+	}
+	if key, ok := strings.CutPrefix(string(strategy), mergeByKeyPrefix); ok {
+		return mergeListByKey(baseList, overlayList, key)
+	}
+	return overlayList
+}
+
+// mergeListByKey merges list items that are maps with an equal value for key
+func mergeListByKey(base, overlay []interface{}, key string) []interface{} {
+	result := append([]interface{}(nil), base...)
+	for _, item := range overlay {
+		merged := false
+		if itemMap, ok := stringKeyMap(item); ok && itemMap[key] != nil {
+			for i, existing := range result {
+				existingMap, ok := stringKeyMap(existing)
+				if ok && fmt.Sprint(existingMap[key]) == fmt.Sprint(itemMap[key]) {
+					result[i] = mergeConfig(existingMap, itemMap, "", nil)
+					merged = true
+					break
+				}
+			}
+		}
+		if !merged {
+			result = append(result, item)
+		}
+	}
+	return result
+}
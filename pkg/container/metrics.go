@@ -11,6 +11,12 @@ type MetricsCollector interface {
 	RecordInitDuration(componentName string, duration time.Duration)
 	RecordStartDuration(componentName string, duration time.Duration)
 	RecordStopDuration(componentName string, duration time.Duration)
+	RecordExecution(componentName string, duration time.Duration, failed bool)
+	RecordHealthCheck(componentName string, duration time.Duration, failed bool)
+	RecordError(componentName string)
+	RecordPanic(componentName string)
+	// RecordSkip records scheduled runs that didn't execute, by reason
+	RecordSkip(componentName string, reason string, runs int)
 	GetMetrics() map[string]*ComponentMetrics
 }
 
@@ -21,6 +27,50 @@ type ComponentMetrics struct {
 	StartDuration   time.Duration
 	StopDuration    time.Duration
 	DependencyCount int
+
+	// Executions are the runs of a scheduled component; failed runs panicked
+	Executions OperationStats
+	// HealthChecks are the checks of a health indicator; failed checks reported DOWN
+	HealthChecks OperationStats
+	// ErrorCount counts failed executions and health checks as well as start
+	// and stop failures
+	ErrorCount int64
+	// PanicCount counts panics in Start, Stop, Run and Execute
+	PanicCount int64
+	// Skips counts the scheduled runs that didn't execute by reason: paused,
+	// missed or calendar:<name> for runs excluded by a calendar
+	Skips map[string]int64
+}
+
+// OperationStats summarizes the durations of a repeated operation
+type OperationStats struct {
+	Count  int64
+	Errors int64
+	Min    time.Duration
+	Max    time.Duration
+	Total  time.Duration
+}
+
+// Avg returns the average duration, zero if the operation never ran
+func (s OperationStats) Avg() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+func (s *OperationStats) record(duration time.Duration, failed bool) {
+	if s.Count == 0 || duration < s.Min {
+		s.Min = duration
+	}
+	if duration > s.Max {
+		s.Max = duration
+	}
+	s.Count++
+	s.Total += duration
+	if failed {
+		s.Errors++
+	}
 }
 
 // defaultMetricsCollector implements MetricsCollector
@@ -93,6 +143,71 @@ func (c *defaultMetricsCollector) RecordStopDuration(componentName string, durat
 	c.metrics[componentName].StopDuration = duration
 }
 
+func (c *defaultMetricsCollector) RecordExecution(componentName string, duration time.Duration, failed bool) {
+	if !c.enabled {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ensureMetricExists(componentName)
+	c.metrics[componentName].Executions.record(duration, failed)
+	if failed {
+		c.metrics[componentName].ErrorCount++
+	}
+}
+
+func (c *defaultMetricsCollector) RecordHealthCheck(componentName string, duration time.Duration, failed bool) {
+	if !c.enabled {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ensureMetricExists(componentName)
+	c.metrics[componentName].HealthChecks.record(duration, failed)
+	if failed {
+		c.metrics[componentName].ErrorCount++
+	}
+}
+
+func (c *defaultMetricsCollector) RecordError(componentName string) {
+	if !c.enabled {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ensureMetricExists(componentName)
+	c.metrics[componentName].ErrorCount++
+}
+
+func (c *defaultMetricsCollector) RecordPanic(componentName string) {
+	if !c.enabled {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ensureMetricExists(componentName)
+	c.metrics[componentName].PanicCount++
+}
+
+func (c *defaultMetricsCollector) RecordSkip(componentName string, reason string, runs int) {
+	if !c.enabled {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.ensureMetricExists(componentName)
+	metrics := c.metrics[componentName]
+	if metrics.Skips == nil {
+		metrics.Skips = make(map[string]int64)
+	}
+	metrics.Skips[reason] += int64(runs)
+}
+
 func (c *defaultMetricsCollector) GetMetrics() map[string]*ComponentMetrics {
 	if !c.enabled {
 		return nil
@@ -105,6 +220,12 @@ func (c *defaultMetricsCollector) GetMetrics() map[string]*ComponentMetrics {
 	result := make(map[string]*ComponentMetrics, len(c.metrics))
 	for k, v := range c.metrics {
 		copy := *v
+		if v.Skips != nil {
+			copy.Skips = make(map[string]int64, len(v.Skips))
+			for reason, count := range v.Skips {
+				copy.Skips[reason] = count
+			}
+		}
 		result[k] = &copy
 	}
 
@@ -0,0 +1,161 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// Module groups components behind a visibility boundary. Components registered by
+// a module are only injectable into components of the same module, unless their
+// names are listed in Provides. Components outside any module are visible everywhere.
+type Module struct {
+	// Name identifies the module in errors and logs
+	Name string
+	// Provides lists the components of this module injectable outside it
+	Provides []string
+	// Requires lists components the module needs from outside; a missing one
+	// fails startup with a module-level error
+	Requires []string
+	// Register registers the module's components, variables, factories and starters
+	Register func(ContextBuilder)
+}
+
+// ModuleVisibilityError returns an error for when a component accesses a component
+// that another module doesn't export
+func ModuleVisibilityError(from, to, module string) *ContainerError {
+	return &ContainerError{
+		Code:    "COMPONENT_NOT_EXPORTED",
+		Message: fmt.Sprintf("component '%s' cannot access '%s': it is private to module '%s' (add it to Provides)", from, to, module),
+	}
+}
+
+// ModuleRequirementError returns an error for when a module's requirement isn't satisfied
+func ModuleRequirementError(module, component, reason string) *ContainerError {
+	return &ContainerError{
+		Code:    "MODULE_REQUIREMENT_MISSING",
+		Message: fmt.Sprintf("module '%s' requires component '%s': %s", module, component, reason),
+	}
+}
+
+// RegisterModule runs the module's Register function; the components it
+// registers belong to the module
+func (c *container) RegisterModule(module Module) error {
+	if module.Name == "" {
+		return c.recordRegistration(fmt.Errorf("module name cannot be empty"))
+	}
+	for _, m := range c.modules {
+		if m.Name == module.Name {
+			return c.recordRegistration(fmt.Errorf("module %s registered twice", module.Name))
+		}
+	}
+	if c.currentModule != "" {
+		return c.recordRegistration(fmt.Errorf("module %s cannot be registered inside module %s", module.Name, c.currentModule))
+	}
+
+	c.logger.Info("Registering module", "name", module.Name)
+	c.modules = append(c.modules, module)
+
+	if module.Register != nil {
+		c.currentModule = module.Name
+		module.Register(c)
+		c.currentModule = ""
+	}
+
+	for _, name := range module.Provides {
+		owner, _ := c.componentRegistry.GetModule(name)
+		if owner != module.Name {
+			return c.recordRegistration(fmt.Errorf("module %s provides %s, which it didn't register", module.Name, name))
+		}
+		c.componentRegistry.SetModule(name, module.Name, true)
+	}
+	return nil
+}
+
+// validateModules checks that every module requirement is registered and
+// visible from inside the module
+func (c *container) validateModules() error {
+	for _, module := range c.modules {
+		for _, name := range module.Requires {
+			if !c.componentRegistry.Has(name) {
+				return ModuleRequirementError(module.Name, name, "not registered")
+			}
+			if owner, exported := c.componentRegistry.GetModule(name); owner != "" && owner != module.Name && !exported {
+				return ModuleRequirementError(module.Name, name, fmt.Sprintf("not exported by module '%s'", owner))
+			}
+		}
+	}
+	return nil
+}
+
+// canAccess reports whether component from may depend on component to
+func canAccess(registry ComponentRegistry, from, to string) bool {
+	owner, exported := registry.GetModule(to)
+	if owner == "" || exported {
+		return true
+	}
+	fromModule, _ := registry.GetModule(from)
+	return fromModule == owner
+}
+
+// visibleMatches drops the type matches that from isn't allowed to access
+func visibleMatches(registry ComponentRegistry, from string, matches []typeMatch) []typeMatch {
+	var visible []typeMatch
+	for _, m := range matches {
+		if canAccess(registry, from, m.name) {
+			visible = append(visible, m)
+		}
+	}
+	return visible
+}
+
+// contextFor returns the context passed to a component's Init. When modules are
+// used, lookups are restricted to the components visible from that component.
+func (c *container) contextFor(name string) ApplicationContext {
+	if len(c.modules) == 0 {
+		return c
+	}
+	return &moduleContext{container: c, from: name}
+}
+
+// moduleContext is the container as seen from one component
+type moduleContext struct {
+	*container
+	from string
+}
+
+// GetComponent only considers components visible from the requesting component
+func (m *moduleContext) GetComponent(target interface{}) error {
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Ptr {
+		return ErrorWithCode("TARGET_NOT_POINTER", "target must be a pointer")
+	}
+
+	elemType := targetType.Elem()
+	match, err := selectTypeMatch(visibleMatches(m.componentRegistry, m.from, findTypeMatches(m.componentRegistry, elemType)), elemType)
+	if err != nil {
+		return err
+	}
+
+	match.assign(reflect.ValueOf(target).Elem())
+	return nil
+}
+
+// GetComponentByName refuses components private to another module
+func (m *moduleContext) GetComponentByName(name string) (Component, error) {
+	if m.componentRegistry.Has(name) && !canAccess(m.componentRegistry, m.from, name) {
+		owner, _ := m.componentRegistry.GetModule(name)
+		return nil, ModuleVisibilityError(m.from, name, owner)
+	}
+	return m.container.GetComponentByName(name)
+}
+
+// GetComponentsByTag omits components private to another module
+func (m *moduleContext) GetComponentsByTag(tag string) []Component {
+	var result []Component
+	for _, comp := range m.container.GetComponentsByTag(tag) {
+		if canAccess(m.componentRegistry, m.from, comp.Name()) {
+			result = append(result, comp)
+		}
+	}
+	return result
+}
@@ -0,0 +1,90 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"plugin"
+	"sort"
+)
+
+// PropertyPluginsDir is the directory scanned by a PluginLoader without an explicit Dir
+const PropertyPluginsDir = "goboot.plugins.dir"
+
+// PluginRegisterSymbol is the function a plugin exports to register its components
+// and starters. It must have the signature func(container.ContextBuilder) or
+// func(container.ContextBuilder) error.
+const PluginRegisterSymbol = "GoBootRegister"
+
+// PluginLoader is a starter that opens every .so Go plugin in a directory (in
+// file name order) and calls its GoBootRegister function, so deployments can add
+// integrations without recompiling the main binary. Plugins must be built with the
+// same Go version and dependency versions as the application (go build -buildmode=plugin);
+// Go plugins are only supported on Linux, FreeBSD and macOS.
+type PluginLoader struct {
+	// Dir is the plugin directory; defaults to the goboot.plugins.dir property
+	Dir string
+	// Required fails startup if the directory doesn't exist
+	Required bool
+}
+
+// Name returns the name of the starter
+func (l *PluginLoader) Name() string {
+	return "PluginLoader"
+}
+
+// Start loads the plugins and lets them register with the builder
+func (l *PluginLoader) Start(builder ContextBuilder) error {
+	dir := l.Dir
+	if dir == "" {
+		dir = builder.GetVariable(PropertyPluginsDir)
+	}
+	if dir == "" {
+		return nil
+	}
+
+	paths, err := filepath.Glob(filepath.Join(dir, "*.so"))
+	if err != nil {
+		return fmt.Errorf("scan plugin directory %s: %w", dir, err)
+	}
+	if len(paths) == 0 {
+		if _, err := os.Stat(dir); err != nil && l.Required {
+			return fmt.Errorf("plugin directory %s: %w", dir, err)
+		}
+	}
+	sort.Strings(paths)
+
+	for _, path := range paths {
+		if err := loadPlugin(path, builder); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func loadPlugin(path string, builder ContextBuilder) error {
+	p, err := plugin.Open(path)
+	if err != nil {
+		return fmt.Errorf("open plugin %s: %w", path, err)
+	}
+
+	symbol, err := p.Lookup(PluginRegisterSymbol)
+	if err != nil {
+		return fmt.Errorf("plugin %s: %w", path, err)
+	}
+
+	switch register := symbol.(type) {
+	case func(ContextBuilder):
+		register(builder)
+	case func(ContextBuilder) error:
+		if err := register(builder); err != nil {
+			return fmt.Errorf("plugin %s registration failed: %w", path, err)
+		}
+	default:
+		return fmt.Errorf("plugin %s: %s has unsupported type %T", path, PluginRegisterSymbol, symbol)
+	}
+	return nil
+}
+
+// Ensure that PluginLoader implements Starter
+var _ Starter = (*PluginLoader)(nil)
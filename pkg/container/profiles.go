@@ -0,0 +1,111 @@
+package container
+
+import (
+	"fmt"
+	"os"
+	"strings"
+)
+
+const (
+	// PropertyProfilesActive lists profiles to activate from the configuration
+	PropertyProfilesActive = "goboot.profiles.active"
+	// PropertyProfilesDefault lists the profiles active when no profile was activated
+	PropertyProfilesDefault = "goboot.profiles.default"
+	// PropertyProfilesGroupPrefix prefixes profile groups: activating a group
+	// (goboot.profiles.group.prod: [prod-db, prod-metrics]) activates its members
+	PropertyProfilesGroupPrefix = "goboot.profiles.group."
+)
+
+// ActivateProfiles activates profiles in addition to the ones activated before
+func (c *container) ActivateProfiles(profiles ...string) {
+	for _, profile := range profiles {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			c.profiles = append(c.profiles, profile)
+		}
+	}
+}
+
+// GetActiveProfiles returns the active profiles with their groups expanded.
+// Profiles from all sources are combined, in this order:
+//
+//  1. goboot.profiles.active from the configuration
+//  2. Config.Profiles and ActivateProfiles (e.g. boot.WithProfiles, ProfileYamlLoader.Profiles)
+//  3. the GO_BOOT_ACTIVE_PROFILES environment variable
+//
+// Profile files are loaded in this order, so later profiles override earlier ones.
+// If no profile is active, goboot.profiles.default is used.
+func (c *container) GetActiveProfiles() []string {
+	return c.activeProfiles(c)
+}
+
+// activeProfiles returns the active profiles reading the variables from vars
+func (c *container) activeProfiles(vars ApplicationContext) []string {
+	var profiles []string
+	profiles = append(profiles, variableList(vars, PropertyProfilesActive)...)
+	profiles = append(profiles, c.config.Profiles...)
+	profiles = append(profiles, c.profiles...)
+	profiles = append(profiles, profilesFromEnv()...)
+	return resolveProfiles(vars, profiles)
+}
+
+// resolveProfiles falls back to the default profiles if none are requested and
+// expands profile groups; each group is followed by its members
+func resolveProfiles(ctx ApplicationContext, requested []string) []string {
+	if len(requested) == 0 {
+		requested = variableList(ctx, PropertyProfilesDefault)
+	}
+
+	var result []string
+	seen := make(map[string]bool)
+	var add func(profile string)
+	add = func(profile string) {
+		if seen[profile] {
+			return
+		}
+		seen[profile] = true
+		result = append(result, profile)
+		for _, member := range variableList(ctx, PropertyProfilesGroupPrefix+profile) {
+			add(member)
+		}
+	}
+	for _, profile := range requested {
+		add(profile)
+	}
+	return result
+}
+
+// variableList returns a list variable, accepting YAML lists and comma-separated strings
+func variableList(ctx ApplicationContext, name string) []string {
+	var items []string
+	switch value := ctx.GetVariableRaw(name).(type) {
+	case nil:
+		return nil
+	case []interface{}:
+		for _, item := range value {
+			items = append(items, fmt.Sprint(item))
+		}
+	case []string:
+		items = value
+	default:
+		items = strings.Split(fmt.Sprint(value), ",")
+	}
+
+	var result []string
+	for _, item := range items {
+		if item = strings.TrimSpace(item); item != "" {
+			result = append(result, item)
+		}
+	}
+	return result
+}
+
+// profilesFromEnv parses the comma-separated GO_BOOT_ACTIVE_PROFILES variable
+func profilesFromEnv() []string {
+	var profiles []string
+	for _, profile := range strings.Split(os.Getenv("GO_BOOT_ACTIVE_PROFILES"), ",") {
+		if profile = strings.TrimSpace(profile); profile != "" {
+			profiles = append(profiles, profile)
+		}
+	}
+	return profiles
+}
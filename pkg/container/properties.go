@@ -0,0 +1,124 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+)
+
+// StructValidatorName is the name of the component validating bound configuration
+// structs, e.g. the Validator of the validation starter
+const StructValidatorName = "validator"
+
+// StructValidator validates a struct, typically driven by its tags
+type StructValidator interface {
+	ValidateStruct(v interface{}) error
+}
+
+// ConfigProperties is a component binding a configuration section to a struct.
+// The struct itself is injected by type, like an instance.
+type ConfigProperties struct {
+	name     string
+	prefix   string
+	target   interface{}
+	defaults interface{}
+}
+
+// RegisterConfigProperties registers target (a pointer to a struct) as a component
+// named "config.<prefix>". The section is bound when the component is initialized,
+// after all variable loaders ran, and validated by the StructValidator component if
+// one is registered and by its Validate() error method if it has one. Other components inject the struct directly:
+//
+//	container.RegisterConfigProperties(builder, "database", &DBConfig{})
+//	...
+//	var cfg *DBConfig
+//	ctx.GetComponent(&cfg)
+func RegisterConfigProperties(builder ContextBuilder, prefix string, target interface{}) error {
+	if v := reflect.ValueOf(target); v.Kind() != reflect.Pointer || v.IsNil() || v.Elem().Kind() != reflect.Struct {
+		return ErrorWithCode("INVALID_CONFIG_PROPERTIES", "config properties %s must be a non-nil pointer to a struct, got %T", prefix, target)
+	}
+	// Keep a copy of the initial values, which are the defaults in the config schema
+	defaults := reflect.New(reflect.TypeOf(target).Elem())
+	defaults.Elem().Set(reflect.ValueOf(target).Elem())
+	return builder.RegisterComponent(&ConfigProperties{name: "config." + prefix, prefix: prefix, target: target, defaults: defaults.Interface()})
+}
+
+// Name returns the component name
+func (p *ConfigProperties) Name() string {
+	return p.name
+}
+
+// Prefix returns the bound configuration section
+func (p *ConfigProperties) Prefix() string {
+	return p.prefix
+}
+
+// Value returns the bound struct pointer
+func (p *ConfigProperties) Value() interface{} {
+	return p.target
+}
+
+// Defaults returns a copy of the struct as it was registered, before binding
+func (p *ConfigProperties) Defaults() interface{} {
+	return p.defaults
+}
+
+// Init binds and validates the section
+func (p *ConfigProperties) Init(ctx ApplicationContext) error {
+	return p.Rebind(ctx)
+}
+
+// Rebind binds the section again from the current variables. The struct keeps
+// its defaults for missing keys; it is only updated if binding and validation succeed.
+func (p *ConfigProperties) Rebind(ctx ApplicationContext) error {
+	target := reflect.ValueOf(p.target).Elem()
+
+	// Bind into a copy so that a failed refresh leaves the current values intact
+	fresh := reflect.New(target.Type())
+	fresh.Elem().Set(target)
+	if NewVariableHelper(ctx).HasSection(p.prefix) {
+		if err := ctx.GetVariableAs(p.prefix, fresh.Interface()); err != nil {
+			return ConfigurationError(fmt.Sprintf("cannot bind %s", p.prefix), err)
+		}
+	}
+
+	if ctx.HasComponent(StructValidatorName) {
+		comp, err := ctx.GetComponentByName(StructValidatorName)
+		if err != nil {
+			return err
+		}
+		if validator, ok := comp.(StructValidator); ok {
+			if err := validator.ValidateStruct(fresh.Interface()); err != nil {
+				return ConfigurationError(fmt.Sprintf("invalid %s configuration", p.prefix), err)
+			}
+		}
+	}
+	if validator, ok := fresh.Interface().(interface{ Validate() error }); ok {
+		if err := validator.Validate(); err != nil {
+			return ConfigurationError(fmt.Sprintf("invalid %s configuration", p.prefix), err)
+		}
+	}
+
+	target.Set(fresh.Elem())
+	return nil
+}
+
+// RefreshConfigProperties re-binds every registered ConfigProperties component,
+// e.g. after variables were reloaded. Components holding the struct pointer see
+// the new values; reads concurrent with a refresh are not synchronized.
+func RefreshConfigProperties(ctx ApplicationContext) error {
+	for _, name := range ctx.GetComponentNames() {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if props, ok := comp.(*ConfigProperties); ok {
+			if err := props.Rebind(ctx); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// Ensure that ConfigProperties implements Component
+var _ Component = (*ConfigProperties)(nil)
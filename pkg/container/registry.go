@@ -1,9 +1,14 @@
 package container
 
 import (
+	"context"
 	"fmt"
 	"log/slog"
+	"reflect"
+	"sort"
+	"strings"
 	"sync"
+	"sync/atomic"
 )
 
 // ComponentRegistry manages component registration and retrieval
@@ -12,20 +17,47 @@ type ComponentRegistry interface {
 	Get(name string) (Component, error)
 	Has(name string) bool
 	GetAll() map[string]Component
+	// Range calls fn for every component until it returns false, without copying the
+	// registry. fn must not register components.
+	Range(fn func(name string, comp Component) bool)
+	// Version changes whenever a component is registered or its exposed types change,
+	// so results derived from the registry can be cached until then
+	Version() uint64
 	GetNames() []string
+	AddTags(name string, tags ...string) error
+	GetByTag(tag string) []Component
+	// GetTags returns the sorted tags of a component
+	GetTags(name string) []string
+	SetExposedTypes(name string, types ...reflect.Type) error
+	GetExposedTypes(name string) []reflect.Type
+	SetModule(name, module string, exported bool)
+	GetModule(name string) (module string, exported bool)
 }
 
 // defaultComponentRegistry implements ComponentRegistry
 type defaultComponentRegistry struct {
 	components map[string]Component
+	tags       map[string]map[string]bool
+	exposed    map[string][]reflect.Type
+	modules    map[string]string
+	exported   map[string]bool
 	mu         sync.RWMutex
 	logger     *slog.Logger
+
+	// matches caches type lookups made at version
+	matches map[reflect.Type][]typeMatch
+	version uint64
 }
 
 func newComponentRegistry(logger *slog.Logger) *defaultComponentRegistry {
 	return &defaultComponentRegistry{
 		components: make(map[string]Component),
+		tags:       make(map[string]map[string]bool),
+		exposed:    make(map[string][]reflect.Type),
+		modules:    make(map[string]string),
+		exported:   make(map[string]bool),
 		logger:     logger,
+		matches:    make(map[reflect.Type][]typeMatch),
 	}
 }
 
@@ -48,9 +80,150 @@ func (r *defaultComponentRegistry) Register(component Component) error {
 
 	r.logger.Info("Registering component", "name", name)
 	r.components[name] = component
+	r.invalidateMatches()
+
+	if tagged, ok := component.(Tagged); ok {
+		r.addTags(name, tagged.Tags())
+	}
+	return nil
+}
+
+// replace swaps the component registered under the same name, keeping its tags,
+// exposed types and module
+func (r *defaultComponentRegistry) replace(component Component) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.components[component.Name()] = component
+	r.invalidateMatches()
+	if tagged, ok := component.(Tagged); ok {
+		r.addTags(component.Name(), tagged.Tags())
+	}
+}
+
+// AddTags adds tags to a registered component
+func (r *defaultComponentRegistry) AddTags(name string, tags ...string) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.components[name]; !exists {
+		return ComponentNotFoundError(name)
+	}
+	r.addTags(name, tags)
+	return nil
+}
+
+func (r *defaultComponentRegistry) addTags(name string, tags []string) {
+	for _, tag := range tags {
+		if r.tags[tag] == nil {
+			r.tags[tag] = make(map[string]bool)
+		}
+		r.tags[tag][name] = true
+	}
+}
+
+// GetByTag returns the components with the given tag, sorted by name
+func (r *defaultComponentRegistry) GetByTag(tag string) []Component {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.tags[tag]))
+	for name := range r.tags[tag] {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	result := make([]Component, 0, len(names))
+	for _, name := range names {
+		result = append(result, r.components[name])
+	}
+	return result
+}
+
+func (r *defaultComponentRegistry) GetTags(name string) []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	var tags []string
+	for tag, names := range r.tags {
+		if names[name] {
+			tags = append(tags, tag)
+		}
+	}
+	sort.Strings(tags)
+	return tags
+}
+
+// SetExposedTypes restricts type-based lookup of a component to the given types
+func (r *defaultComponentRegistry) SetExposedTypes(name string, types ...reflect.Type) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, exists := r.components[name]; !exists {
+		return ComponentNotFoundError(name)
+	}
+	r.exposed[name] = append(r.exposed[name], types...)
+	r.invalidateMatches()
 	return nil
 }
 
+// typeMatches returns the components injectable into elemType like findTypeMatches,
+// scanning the registry only once per type until a component is registered or exposed.
+// The returned slice is shared and must not be modified.
+func (r *defaultComponentRegistry) typeMatches(elemType reflect.Type) []typeMatch {
+	r.mu.RLock()
+	matches, cached := r.matches[elemType]
+	version := r.version
+	r.mu.RUnlock()
+	if cached {
+		return matches
+	}
+
+	matches = scanTypeMatches(r, elemType)
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	// Don't cache a scan that raced with a registration
+	if r.version == version {
+		r.matches[elemType] = matches
+	}
+	return matches
+}
+
+// invalidateMatches bumps the version and drops the cached type lookups; callers
+// hold the lock
+func (r *defaultComponentRegistry) invalidateMatches() {
+	r.version++
+	if len(r.matches) > 0 {
+		r.matches = make(map[reflect.Type][]typeMatch)
+	}
+}
+
+// GetExposedTypes returns the types a component is exposed as, or nil if unrestricted
+func (r *defaultComponentRegistry) GetExposedTypes(name string) []reflect.Type {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.exposed[name]
+}
+
+// SetModule records the module owning a component and whether the module exports it
+func (r *defaultComponentRegistry) SetModule(name, module string, exported bool) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.modules[name] = module
+	r.exported[name] = exported
+}
+
+// GetModule returns the module owning a component ("" if none) and whether it's exported
+func (r *defaultComponentRegistry) GetModule(name string) (string, bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.modules[name], r.exported[name]
+}
+
 func (r *defaultComponentRegistry) Get(name string) (Component, error) {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -82,6 +255,24 @@ func (r *defaultComponentRegistry) GetAll() map[string]Component {
 	return result
 }
 
+func (r *defaultComponentRegistry) Range(fn func(name string, comp Component) bool) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	for name, comp := range r.components {
+		if !fn(name, comp) {
+			return
+		}
+	}
+}
+
+func (r *defaultComponentRegistry) Version() uint64 {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return r.version
+}
+
 func (r *defaultComponentRegistry) GetNames() []string {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
@@ -96,20 +287,50 @@ func (r *defaultComponentRegistry) GetNames() []string {
 // VariableRegistry manages variable registration and retrieval
 type VariableRegistry interface {
 	Register(name string, value interface{})
+	// RegisterAll registers several variables at once
+	RegisterAll(variables map[string]interface{})
+	// RegisterDefault registers a value used while the variable isn't registered
+	RegisterDefault(name string, value interface{})
 	Get(name string) interface{}
 	GetString(name string) string
+	Has(name string) bool
+	GetAll() map[string]interface{}
+	// GetWithPrefix returns the variables whose names start with prefix, with the
+	// prefix removed
+	GetWithPrefix(prefix string) map[string]interface{}
+}
+
+// snapshotAfterReads is the number of reads since the last write after which readers
+// switch to a lock-free snapshot. Reads interleaved with writes (e.g. while loading)
+// keep using the lock so that the snapshot isn't rebuilt for every write.
+const snapshotAfterReads = 32
+
+// variableSnapshot is an immutable view of the variables, defaults overridden
+type variableSnapshot struct {
+	values map[string]interface{}
+	// strings caches the string form of values, filled on demand
+	strings sync.Map
 }
 
 // defaultVariableRegistry implements VariableRegistry
 type defaultVariableRegistry struct {
 	variables map[string]interface{}
+	defaults  map[string]interface{}
 	mu        sync.RWMutex
 	logger    *slog.Logger
+
+	// snapshot serves reads without locking; it is nil after a write until
+	// snapshotAfterReads reads happened
+	snapshot  atomic.Pointer[variableSnapshot]
+	lockReads atomic.Int64
+	// expressions caches the resolved expression values until the next write
+	expressions atomic.Pointer[sync.Map]
 }
 
 func newVariableRegistry(logger *slog.Logger) *defaultVariableRegistry {
 	return &defaultVariableRegistry{
 		variables: make(map[string]interface{}),
+		defaults:  make(map[string]interface{}),
 		logger:    logger,
 	}
 }
@@ -118,28 +339,217 @@ func (r *defaultVariableRegistry) Register(name string, value interface{}) {
 	r.mu.Lock()
 	defer r.mu.Unlock()
 
-	r.logger.Debug("Registering variable", "name", name, "type", fmt.Sprintf("%T", value))
+	r.logDebug("Registering variable", name, value)
 	r.variables[name] = value
+	r.invalidate()
 }
 
-func (r *defaultVariableRegistry) Get(name string) interface{} {
+func (r *defaultVariableRegistry) RegisterAll(variables map[string]interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logger.Debug("Registering variables", "count", len(variables))
+	for name, value := range variables {
+		r.variables[name] = value
+	}
+	r.invalidate()
+}
+
+func (r *defaultVariableRegistry) RegisterDefault(name string, value interface{}) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	r.logDebug("Registering default variable", name, value)
+	r.defaults[name] = value
+	r.invalidate()
+}
+
+// clone returns a registry with a copy of the variables and defaults
+func (r *defaultVariableRegistry) clone() *defaultVariableRegistry {
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	return r.variables[name]
+	clone := newVariableRegistry(r.logger)
+	for k, v := range r.variables {
+		clone.variables[k] = v
+	}
+	for k, v := range r.defaults {
+		clone.defaults[k] = v
+	}
+	return clone
 }
 
-func (r *defaultVariableRegistry) GetString(name string) string {
+// replaceWith replaces the variables and defaults with those of other in one
+// write, so that readers see either all the old or all the new values
+func (r *defaultVariableRegistry) replaceWith(other *defaultVariableRegistry) {
+	other.mu.RLock()
+	variables, defaults := other.variables, other.defaults
+	other.mu.RUnlock()
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.variables, r.defaults = variables, defaults
+	r.invalidate()
+}
+
+// logDebug logs a registration, formatting the value type only if debug logging is on
+func (r *defaultVariableRegistry) logDebug(msg, name string, value interface{}) {
+	if r.logger.Enabled(context.Background(), slog.LevelDebug) {
+		r.logger.Debug(msg, "name", name, "type", fmt.Sprintf("%T", value))
+	}
+}
+
+// invalidate drops the snapshot after a write; callers hold the write lock
+func (r *defaultVariableRegistry) invalidate() {
+	r.snapshot.Store(nil)
+	r.lockReads.Store(0)
+	r.expressions.Store(nil)
+}
+
+// expressionFailure marks a cached expression that failed, so that it is logged
+// once until the next write but evaluated again on every read
+type expressionFailure struct{}
+
+// resolve returns the value of an expression such as file(/run/secrets/key),
+// resolved on first access and cached until the next write, or the value itself.
+// A failing expression resolves to an empty string with its error; it isn't
+// cached, so that e.g. a secret file mounted later is picked up.
+func (r *defaultVariableRegistry) resolve(value interface{}) (interface{}, error) {
+	str, ok := value.(string)
+	if !ok {
+		return value, nil
+	}
+	if _, _, ok := parseExpression(str); !ok {
+		return value, nil
+	}
+
+	cache := r.expressions.Load()
+	if cache == nil {
+		r.expressions.CompareAndSwap(nil, &sync.Map{})
+		cache = r.expressions.Load()
+	}
+	cached, ok := cache.Load(str)
+	if _, failed := cached.(expressionFailure); ok && !failed {
+		return cached, nil
+	}
+	resolved, err := evaluateExpression(str)
+	if err != nil {
+		if _, logged := cache.LoadOrStore(str, expressionFailure{}); !logged {
+			r.logger.Error("Variable expression failed", "expression", str, "error", err)
+		}
+		return "", err
+	}
+	cache.Store(str, resolved)
+	return resolved, nil
+}
+
+// resolved returns the value of an expression, or an empty string if it fails;
+// reading a variable can't fail
+func (r *defaultVariableRegistry) resolved(value interface{}) interface{} {
+	resolved, _ := r.resolve(value)
+	return resolved
+}
+
+// expressionError returns the error of the first failing expression in the
+// variable or in the variables below it, e.g. before binding them
+func (r *defaultVariableRegistry) expressionError(name string) error {
+	values := make(map[string]interface{})
+	if value, exists := r.lookup(name); exists {
+		values[name] = value
+	}
+	for key, value := range r.withPrefix(name + ".") {
+		values[name+"."+key] = value
+	}
+
+	names := make([]string, 0, len(values))
+	for key := range values {
+		names = append(names, key)
+	}
+	sort.Strings(names)
+	for _, key := range names {
+		if _, err := r.resolve(values[key]); err != nil {
+			return VariableExpressionError(key, err)
+		}
+	}
+	return nil
+}
+
+// current returns the snapshot, building it once enough reads happened since the
+// last write; nil means the caller reads under the lock
+func (r *defaultVariableRegistry) current() *variableSnapshot {
+	if snapshot := r.snapshot.Load(); snapshot != nil {
+		return snapshot
+	}
+	if r.lockReads.Add(1) < snapshotAfterReads {
+		return nil
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if snapshot := r.snapshot.Load(); snapshot != nil {
+		return snapshot
+	}
+	snapshot := &variableSnapshot{values: r.merged()}
+	r.snapshot.Store(snapshot)
+	return snapshot
+}
+
+// merged returns the variables with defaults overridden; callers hold the lock
+func (r *defaultVariableRegistry) merged() map[string]interface{} {
+	result := make(map[string]interface{}, len(r.variables)+len(r.defaults))
+	for k, v := range r.defaults {
+		result[k] = v
+	}
+	for k, v := range r.variables {
+		result[k] = v
+	}
+	return result
+}
+
+// lookup returns the registered value, falling back to the default
+func (r *defaultVariableRegistry) lookup(name string) (interface{}, bool) {
+	if snapshot := r.current(); snapshot != nil {
+		value, exists := snapshot.values[name]
+		return value, exists
+	}
+
 	r.mu.RLock()
 	defer r.mu.RUnlock()
 
-	value := r.variables[name]
-	if value == nil {
-		return ""
+	if value, exists := r.variables[name]; exists {
+		return value, true
 	}
+	value, exists := r.defaults[name]
+	return value, exists
+}
 
-	// Convert value to string if possible
+func (r *defaultVariableRegistry) Get(name string) interface{} {
+	value, _ := r.lookup(name)
+	return r.resolved(value)
+}
+
+func (r *defaultVariableRegistry) GetString(name string) string {
+	snapshot := r.current()
+	if snapshot != nil {
+		if cached, ok := snapshot.strings.Load(name); ok {
+			return cached.(string)
+		}
+	}
+
+	value, _ := r.lookup(name)
+	resolved, err := r.resolve(value)
+	str := stringValue(resolved)
+	if snapshot != nil && err == nil {
+		snapshot.strings.Store(name, str)
+	}
+	return str
+}
+
+// stringValue converts a variable value to its string form
+func stringValue(value interface{}) string {
 	switch v := value.(type) {
+	case nil:
+		return ""
 	case string:
 		return v
 	case fmt.Stringer:
@@ -148,3 +558,61 @@ func (r *defaultVariableRegistry) GetString(name string) string {
 		return fmt.Sprintf("%v", v)
 	}
 }
+
+func (r *defaultVariableRegistry) Has(name string) bool {
+	_, exists := r.lookup(name)
+	return exists
+}
+
+func (r *defaultVariableRegistry) GetAll() map[string]interface{} {
+	// Return a copy so that callers can't modify the registry
+	if snapshot := r.current(); snapshot != nil {
+		result := make(map[string]interface{}, len(snapshot.values))
+		for k, v := range snapshot.values {
+			result[k] = r.resolved(v)
+		}
+		return result
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	result := r.merged()
+	for k, v := range result {
+		result[k] = r.resolved(v)
+	}
+	return result
+}
+
+func (r *defaultVariableRegistry) GetWithPrefix(prefix string) map[string]interface{} {
+	result := r.withPrefix(prefix)
+	for k, v := range result {
+		result[k] = r.resolved(v)
+	}
+	return result
+}
+
+// withPrefix returns the unresolved variables whose names start with prefix, with
+// the prefix removed
+func (r *defaultVariableRegistry) withPrefix(prefix string) map[string]interface{} {
+	// Only the matching variables are copied
+	result := make(map[string]interface{})
+	collect := func(vars map[string]interface{}) {
+		for k, v := range vars {
+			if strings.HasPrefix(k, prefix) {
+				result[k[len(prefix):]] = v
+			}
+		}
+	}
+
+	if snapshot := r.current(); snapshot != nil {
+		collect(snapshot.values)
+		return result
+	}
+
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+	// Defaults are overridden
+	collect(r.defaults)
+	collect(r.variables)
+	return result
+}
@@ -0,0 +1,161 @@
+package container
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+)
+
+// VariableReloader is implemented by contexts whose variables can be reloaded while
+// the application runs
+type VariableReloader interface {
+	// ReloadVariables runs the variable loaders and post-processors again, applies
+	// the changed variables at once, re-binds the config properties, reschedules
+	// scheduled components whose schedule changed and notifies
+	// VariableChangeListener components. It returns the sorted names of the
+	// variables that changed.
+	ReloadVariables() ([]string, error)
+}
+
+// VariableChangeListener is a component notified after variables were reloaded
+type VariableChangeListener interface {
+	Component
+	// OnVariablesChanged receives the sorted names of the changed variables
+	OnVariablesChanged(changed []string)
+}
+
+// ReloadVariables reloads the variables; variables that disappeared from their
+// source keep their last value. The loaders and post-processors write to a staged
+// copy of the variables, which replaces them at once when all of them succeeded,
+// so that readers never see a half-reloaded configuration and a failed reload
+// changes nothing. Defaults keep their lower precedence.
+func (c *container) ReloadVariables() ([]string, error) {
+	c.reloadMu.Lock()
+	defer c.reloadMu.Unlock()
+
+	registry, ok := c.variableRegistry.(*defaultVariableRegistry)
+	if !ok {
+		return nil, ErrorWithCode("UNSUPPORTED_REGISTRY", "variables of %T can't be reloaded", c.variableRegistry)
+	}
+	before := registry.GetAll()
+	staging := &stagingContext{container: c, staged: registry.clone(), origins: newVariableOrigins()}
+
+	c.logger.Info("Reloading variables", "loaders", len(c.variablesLoaders))
+	for _, loader := range c.variablesLoaders {
+		c.variableSource = describeSource("loader", loader)
+		err := loader.Load(staging)
+		c.variableSource = ""
+		if err != nil {
+			return nil, fmt.Errorf("variable loader failed: %w", err)
+		}
+	}
+	if err := c.runPostProcessors(staging); err != nil {
+		return nil, err
+	}
+	if err := c.checkDeclaredVariables(staging); err != nil {
+		return nil, err
+	}
+
+	after := staging.staged.GetAll()
+	var changed []string
+	for name, value := range after {
+		if old, ok := before[name]; !ok || !reflect.DeepEqual(old, value) {
+			changed = append(changed, name)
+		}
+	}
+	sort.Strings(changed)
+	c.logger.Info("Variables reloaded", "changed", len(changed))
+	if len(changed) == 0 {
+		return nil, nil
+	}
+	registry.replaceWith(staging.staged)
+	c.origins.merge(staging.origins)
+
+	if err := RefreshConfigProperties(c); err != nil {
+		return changed, err
+	}
+	if c.lifecycleManager != nil {
+		c.lifecycleManager.ReloadSchedules()
+	}
+
+	c.componentRegistry.Range(func(name string, comp Component) bool {
+		if listener, ok := comp.(VariableChangeListener); ok {
+			c.notifyVariablesChanged(listener, changed)
+		}
+		return true
+	})
+	return changed, nil
+}
+
+// stagingContext is the container as seen by the variable loaders and
+// post-processors during a reload: variables are read from and written to the
+// staged copy
+type stagingContext struct {
+	*container
+	staged  *defaultVariableRegistry
+	origins *variableOrigins
+}
+
+func (s *stagingContext) GetVariable(name string) string {
+	return s.staged.GetString(name)
+}
+
+func (s *stagingContext) GetVariableRaw(name string) interface{} {
+	return s.staged.Get(name)
+}
+
+func (s *stagingContext) GetVariableAs(name string, target interface{}) error {
+	return NewVariableHelper(s).Bind(name, target)
+}
+
+func (s *stagingContext) HasVariable(name string) bool {
+	return s.staged.Has(name)
+}
+
+func (s *stagingContext) GetVariables() map[string]interface{} {
+	return s.staged.GetAll()
+}
+
+func (s *stagingContext) variablesWithPrefix(prefix string) map[string]interface{} {
+	return s.staged.GetWithPrefix(prefix)
+}
+
+func (s *stagingContext) variableExpressionError(name string) error {
+	return s.staged.expressionError(name)
+}
+
+func (s *stagingContext) GetActiveProfiles() []string {
+	return s.container.activeProfiles(s)
+}
+
+func (s *stagingContext) RegisterVariable(name string, value interface{}) {
+	s.staged.Register(name, value)
+	s.origins.set(s.variableOrigin(), false, name)
+}
+
+func (s *stagingContext) RegisterVariables(variables map[string]interface{}) {
+	s.staged.RegisterAll(variables)
+	s.origins.set(s.variableOrigin(), false, variableNames(variables)...)
+}
+
+func (s *stagingContext) RegisterDefaultVariable(name string, value interface{}) {
+	s.staged.RegisterDefault(name, value)
+	s.origins.set(s.variableOrigin(), true, name)
+}
+
+func (s *stagingContext) RegisterVariableString(name string, value string) {
+	s.RegisterVariable(name, value)
+}
+
+// notifyVariablesChanged calls a listener, logging a panic instead of propagating it
+func (c *container) notifyVariablesChanged(listener VariableChangeListener, changed []string) {
+	defer func() {
+		if r := recover(); r != nil {
+			c.logger.Error("Panic in variable change listener", "name", listener.Name(), "error", r)
+		}
+	}()
+	listener.OnVariablesChanged(changed)
+}
+
+// Ensure that container implements VariableReloader
+var _ VariableReloader = (*container)(nil)
@@ -0,0 +1,218 @@
+package container
+
+import (
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+)
+
+// ComponentState is the lifecycle state of a component
+type ComponentState string
+
+// Component states
+const (
+	// StateRegistered components are not initialized (yet, or at all with StartSubset)
+	StateRegistered ComponentState = "REGISTERED"
+	// StateInitialized components passed Init; components without Start stay here
+	StateInitialized ComponentState = "INITIALIZED"
+	// StateFailed components are non-critical components whose Init failed
+	StateFailed ComponentState = "FAILED"
+	// StateStarted lifecycle components returned from Start
+	StateStarted ComponentState = "STARTED"
+	// StateStopped components were stopped or closed during shutdown
+	StateStopped ComponentState = "STOPPED"
+)
+
+// ComponentReport describes a component's wiring and live state
+type ComponentReport struct {
+	Name  string
+	Type  string
+	State ComponentState
+	// Dependencies are the components this one depends on, Dependents the
+	// components depending on it, both sorted by name
+	Dependencies  []string
+	Dependents    []string
+	InitDuration  time.Duration
+	StartDuration time.Duration
+	Background    bool
+	Scheduled     bool
+}
+
+// componentStates tracks component states across initialization and lifecycle
+// and reports phase transitions to the configured listener
+type componentStates struct {
+	mu       sync.RWMutex
+	states   map[string]ComponentState
+	listener LifecycleEventListener
+}
+
+func newComponentStates(listener LifecycleEventListener) *componentStates {
+	return &componentStates{states: make(map[string]ComponentState), listener: listener}
+}
+
+func (s *componentStates) set(name string, state ComponentState) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.states[name] = state
+}
+
+// get returns the state of a component, StateRegistered if it has none
+func (s *componentStates) get(name string) ComponentState {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if state, ok := s.states[name]; ok {
+		return state
+	}
+	return StateRegistered
+}
+
+// GetComponentReport returns every component with its state, dependencies,
+// dependents and timings, sorted by name
+func (c *container) GetComponentReport() []ComponentReport {
+	names := c.componentRegistry.GetNames()
+	sort.Strings(names)
+
+	dependents := make(map[string][]string)
+	dependencies := make(map[string][]string, len(names))
+	for _, name := range names {
+		dependencies[name] = c.GetDependencies(name)
+		for _, dep := range dependencies[name] {
+			dependents[dep] = append(dependents[dep], name)
+		}
+	}
+
+	metrics := c.metricsCollector.GetMetrics()
+	reports := make([]ComponentReport, 0, len(names))
+	for _, name := range names {
+		comp, err := c.componentRegistry.Get(name)
+		if err != nil {
+			continue
+		}
+
+		report := ComponentReport{
+			Name:         name,
+			Type:         fmt.Sprintf("%T", componentValue(comp)),
+			State:        c.states.get(name),
+			Dependencies: dependencies[name],
+			Dependents:   dependents[name],
+		}
+		sort.Strings(report.Dependents)
+		if m, ok := metrics[name]; ok {
+			report.InitDuration = m.InitDuration
+			report.StartDuration = m.StartDuration
+		}
+		_, report.Background = comp.(BackgroundComponent)
+		_, report.Scheduled = comp.(ScheduledComponent)
+		reports = append(reports, report)
+	}
+	return reports
+}
+
+// ComponentInterface is a well-known interface a component can implement
+type ComponentInterface string
+
+// Well-known component interfaces
+const (
+	InterfaceLifecycle       ComponentInterface = "Lifecycle"
+	InterfaceBackground      ComponentInterface = "Background"
+	InterfaceScheduled       ComponentInterface = "Scheduled"
+	InterfaceHealthIndicator ComponentInterface = "HealthIndicator"
+	InterfaceCloser          ComponentInterface = "Closer"
+	InterfaceErrorReporter   ComponentInterface = "ErrorReporter"
+)
+
+// ComponentInfo describes a registered component for FindComponents
+type ComponentInfo struct {
+	Name string
+	// Component is the registered component, Value the value injected for it
+	// (the wrapped value of instances and configuration properties)
+	Component Component
+	Value     interface{}
+	// Type is the concrete type of Value
+	Type reflect.Type
+	// Interfaces are the well-known interfaces the component implements
+	Interfaces []ComponentInterface
+	// Tags are the component's sorted tags
+	Tags  []string
+	State ComponentState
+}
+
+// Implements reports whether the component implements a well-known interface
+func (i ComponentInfo) Implements(iface ComponentInterface) bool {
+	for _, candidate := range i.Interfaces {
+		if candidate == iface {
+			return true
+		}
+	}
+	return false
+}
+
+// HasTag reports whether the component carries the tag
+func (i ComponentInfo) HasTag(tag string) bool {
+	for _, candidate := range i.Tags {
+		if candidate == tag {
+			return true
+		}
+	}
+	return false
+}
+
+// FindComponents returns the components matching pred (all if pred is nil), sorted by name:
+//
+//	closers := inspector.FindComponents(func(info container.ComponentInfo) bool {
+//		return info.Implements(container.InterfaceCloser) && !info.HasTag("shared")
+//	})
+func (c *container) FindComponents(pred func(ComponentInfo) bool) []ComponentInfo {
+	names := c.componentRegistry.GetNames()
+	sort.Strings(names)
+
+	var result []ComponentInfo
+	for _, name := range names {
+		comp, err := c.componentRegistry.Get(name)
+		if err != nil {
+			continue
+		}
+
+		value := componentValue(comp)
+		info := ComponentInfo{
+			Name:       name,
+			Component:  comp,
+			Value:      value,
+			Type:       reflect.TypeOf(value),
+			Interfaces: componentInterfaces(comp, value),
+			Tags:       c.componentRegistry.GetTags(name),
+			State:      c.states.get(name),
+		}
+		if pred == nil || pred(info) {
+			result = append(result, info)
+		}
+	}
+	return result
+}
+
+// componentInterfaces returns the well-known interfaces a component implements
+func componentInterfaces(comp Component, value interface{}) []ComponentInterface {
+	var interfaces []ComponentInterface
+	if _, ok := comp.(LifecycleComponent); ok {
+		interfaces = append(interfaces, InterfaceLifecycle)
+	}
+	if _, ok := comp.(BackgroundComponent); ok {
+		interfaces = append(interfaces, InterfaceBackground)
+	}
+	if _, ok := comp.(ScheduledComponent); ok {
+		interfaces = append(interfaces, InterfaceScheduled)
+	}
+	if _, ok := value.(HealthIndicator); ok {
+		interfaces = append(interfaces, InterfaceHealthIndicator)
+	}
+	if _, ok := value.(io.Closer); ok {
+		interfaces = append(interfaces, InterfaceCloser)
+	}
+	if _, ok := value.(ErrorReporter); ok {
+		interfaces = append(interfaces, InterfaceErrorReporter)
+	}
+	return interfaces
+}
@@ -0,0 +1,556 @@
+package container
+
+import (
+	"container/heap"
+	"context"
+	"log/slog"
+	"math/rand"
+	"reflect"
+	"sync"
+	"time"
+)
+
+// JobExecutor runs the executions of scheduled components. The events starter's
+// TaskExecutor implements it; when no JobExecutor component is registered every
+// execution runs in its own goroutine.
+type JobExecutor interface {
+	// Submit queues a task
+	Submit(task func()) error
+}
+
+// scheduledEntry is a job waiting in the scheduler's queue
+type scheduledEntry struct {
+	job *ScheduledJob
+	ctx context.Context
+	// next is the time of the next run on the schedule, offset its random jitter
+	next   time.Time
+	offset time.Duration
+	// index in the heap, -1 once removed
+	index int
+	// running while an execution is in flight; pending executions run after it
+	running bool
+	pending int
+	// deferred is the run of an excluded run deferred to the end of its exclusion,
+	// zero if there is none
+	deferred time.Time
+}
+
+// jobQueue is a min-heap of entries ordered by their next run
+type jobQueue []*scheduledEntry
+
+func (q jobQueue) Len() int           { return len(q) }
+func (q jobQueue) Less(i, j int) bool { return q[i].at().Before(q[j].at()) }
+func (q jobQueue) Swap(i, j int) {
+	q[i], q[j] = q[j], q[i]
+	q[i].index = i
+	q[j].index = j
+}
+
+func (q *jobQueue) Push(x interface{}) {
+	entry := x.(*scheduledEntry)
+	entry.index = len(*q)
+	*q = append(*q, entry)
+}
+
+func (q *jobQueue) Pop() interface{} {
+	old := *q
+	entry := old[len(old)-1]
+	old[len(old)-1] = nil
+	entry.index = -1
+	*q = old[:len(old)-1]
+	return entry
+}
+
+// jobScheduler runs all scheduled components from a single goroutine: it waits for
+// the earliest next run and dispatches the due executions to the executor. Runs
+// that were missed follow the schedule's MissedRunPolicy.
+type jobScheduler struct {
+	clock      Clock
+	executor   JobExecutor
+	store      JobStore
+	goroutines *goroutineTracker
+	run        func(ctx context.Context, job *ScheduledJob)
+	metrics    MetricsCollector
+	logger     *slog.Logger
+
+	mu      sync.Mutex
+	queue   jobQueue
+	entries map[string]*scheduledEntry
+	wake    chan struct{}
+	cancel  context.CancelFunc
+	done    chan struct{}
+}
+
+func newJobScheduler(clock Clock, executor JobExecutor, store JobStore, goroutines *goroutineTracker, run func(ctx context.Context, job *ScheduledJob), metrics MetricsCollector, logger *slog.Logger) *jobScheduler {
+	return &jobScheduler{
+		clock:      clock,
+		executor:   executor,
+		store:      store,
+		goroutines: goroutines,
+		run:        run,
+		metrics:    metrics,
+		logger:     logger,
+		entries:    make(map[string]*scheduledEntry),
+		wake:       make(chan struct{}, 1),
+	}
+}
+
+// findJobExecutor returns the JobExecutor component, nil if there is none or the
+// lookup is ambiguous
+func findJobExecutor(registry ComponentRegistry) JobExecutor {
+	executorType := reflect.TypeOf((*JobExecutor)(nil)).Elem()
+	match, err := selectTypeMatch(findTypeMatches(registry, executorType), executorType)
+	if err != nil {
+		return nil
+	}
+	return match.value.(JobExecutor)
+}
+
+// add schedules a job of a started component; the scheduler goroutine is started
+// with the first job and stops when ctx is cancelled
+func (s *jobScheduler) add(ctx context.Context, job *ScheduledJob) {
+	sched := job.Schedule()
+	if sched.Interval <= 0 {
+		s.logger.Error("Scheduled component has no positive interval, not scheduling it",
+			"name", job.name,
+			"interval", sched.Interval.String())
+		return
+	}
+	location, _ := job.exclusions()
+	entry := &scheduledEntry{job: job, ctx: ctx, next: firstRun(s.clock.Now(), sched, location), offset: jitter(sched)}
+
+	// Continue the schedule from the last stored run; runs missed while the
+	// application was down are handled by the first due check
+	if s.store != nil {
+		lastFire, err := s.store.LastFire(job.name)
+		if err != nil {
+			s.logger.Warn("Failed to load the last run of a scheduled component", "name", job.name, "error", err)
+		} else if !lastFire.IsZero() {
+			entry.next = nextRun(lastFire, sched, location)
+			job.setLastFire(lastFire)
+		}
+	}
+
+	var task func()
+	s.mu.Lock()
+	if s.done == nil {
+		loopCtx, cancel := context.WithCancel(ctx)
+		s.cancel = cancel
+		s.done = make(chan struct{})
+		go s.loop(loopCtx)
+	}
+	s.entries[job.name] = entry
+	heap.Push(&s.queue, entry)
+	if sched.RunOnStartup {
+		s.logger.Debug("Executing scheduled component on startup", "name", job.name)
+		task = s.dispatch(entry, false)
+	}
+	s.mu.Unlock()
+
+	s.submit(task)
+	job.setNotify(func() { s.trigger(job.name) })
+	s.notify()
+
+	s.logger.Info("Scheduled component running",
+		"name", job.name,
+		"interval", sched.Interval.String())
+}
+
+// remove unschedules the job of a stopped component
+func (s *jobScheduler) remove(name string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.entries[name]
+	if !ok {
+		return
+	}
+	delete(s.entries, name)
+	if entry.index >= 0 {
+		heap.Remove(&s.queue, entry.index)
+	}
+	entry.pending = 0
+}
+
+// reschedule moves a job to the next run of its changed schedule: one interval
+// after its last run, or as if it were just started if it never ran. A run that
+// would already be due fires right away.
+func (s *jobScheduler) reschedule(name string) {
+	s.mu.Lock()
+	entry, ok := s.entries[name]
+	if !ok || entry.index < 0 {
+		s.mu.Unlock()
+		return
+	}
+	sched := entry.job.Schedule()
+	location, _ := entry.job.exclusions()
+	now := s.clock.Now()
+	next := firstRun(now, sched, location)
+	if lastFire := entry.job.LastFire(); !lastFire.IsZero() {
+		next = nextRun(lastFire, sched, location)
+	}
+	if next.Before(now) {
+		next = now
+	}
+	entry.next = next
+	entry.offset = jitter(sched)
+	heap.Fix(&s.queue, entry.index)
+	s.mu.Unlock()
+
+	s.notify()
+}
+
+// firstRun returns the first run of a schedule started at now
+func firstRun(now time.Time, sched Schedule, location *time.Location) time.Time {
+	first := now.Add(sched.InitialDelay)
+	if sched.AlignTo > 0 {
+		// Round up to the next boundary, which replaces the first interval
+		if aligned := alignIn(first, sched.AlignTo, location, false); aligned.Before(first) {
+			first = aligned.Add(sched.AlignTo)
+		}
+		return first
+	}
+	return first.Add(sched.Interval)
+}
+
+// nextRun returns the run one interval after last. Runs of schedules aligned on
+// every run are rounded to the nearest boundary in the time zone, so that a
+// daylight saving change doesn't shift them by an hour.
+func nextRun(last time.Time, sched Schedule, location *time.Location) time.Time {
+	next := last.Add(sched.Interval)
+	if sched.AlignTo > 0 && sched.Interval%sched.AlignTo == 0 {
+		next = alignIn(next, sched.AlignTo, location, true)
+	}
+	return next
+}
+
+// alignIn truncates or rounds t to a multiple of d since the zero time in location
+func alignIn(t time.Time, d time.Duration, location *time.Location, round bool) time.Time {
+	_, offset := t.In(location).Zone()
+	shift := time.Duration(offset) * time.Second
+	local := t.Add(shift)
+	if round {
+		local = local.Round(d)
+	} else {
+		local = local.Truncate(d)
+	}
+	aligned := local.Add(-shift)
+	// The boundary may be on the other side of a daylight saving change
+	if _, alignedOffset := aligned.In(location).Zone(); alignedOffset != offset {
+		aligned = aligned.Add(time.Duration(offset-alignedOffset) * time.Second)
+	}
+	return aligned
+}
+
+// trigger runs a job immediately, even while paused, or right after its running execution
+func (s *jobScheduler) trigger(name string) {
+	var task func()
+	s.mu.Lock()
+	if entry, ok := s.entries[name]; ok {
+		s.logger.Debug("Executing triggered scheduled component", "name", name)
+		task = s.dispatch(entry, false)
+	}
+	s.mu.Unlock()
+
+	s.submit(task)
+}
+
+// stop ends the scheduler goroutine; running executions are not waited for
+func (s *jobScheduler) stop() {
+	s.mu.Lock()
+	cancel, done := s.cancel, s.done
+	s.mu.Unlock()
+
+	if cancel != nil {
+		cancel()
+		<-done
+	}
+}
+
+// notify wakes the scheduler goroutine to recompute its next deadline
+func (s *jobScheduler) notify() {
+	select {
+	case s.wake <- struct{}{}:
+	default:
+	}
+}
+
+func (s *jobScheduler) loop(ctx context.Context) {
+	defer close(s.done)
+
+	var timer Timer
+	var timerC <-chan time.Time
+	var armed time.Time
+	defer func() {
+		if timer != nil {
+			timer.Stop()
+		}
+	}()
+
+	for {
+		s.mu.Lock()
+		now := s.clock.Now()
+		tasks, fired := s.due(now)
+		var next time.Time
+		if len(s.queue) > 0 {
+			next = s.queue[0].at()
+		}
+		s.mu.Unlock()
+
+		s.submit(tasks...)
+		s.save(fired)
+
+		// Rearm only when the earliest run changed, a pending tick stays valid otherwise
+		switch {
+		case next.IsZero():
+			if timer != nil {
+				timer.Stop()
+			}
+			armed = time.Time{}
+		case timer == nil:
+			timer = s.clock.NewTimer(next.Sub(now))
+			timerC = timer.C()
+			armed = next
+		case !next.Equal(armed):
+			timer.Reset(next.Sub(now))
+			armed = next
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timerC:
+			armed = time.Time{}
+		case <-s.wake:
+		}
+	}
+}
+
+// at returns when the entry's next run fires
+func (e *scheduledEntry) at() time.Time {
+	if e.hasDeferred() {
+		return e.deferred
+	}
+	return e.next.Add(e.offset)
+}
+
+// hasDeferred reports whether the deferred run comes before the next run
+func (e *scheduledEntry) hasDeferred() bool {
+	return !e.deferred.IsZero() && e.deferred.Before(e.next.Add(e.offset))
+}
+
+// jitter draws the random offset of a run within ±Jitter, at most half the interval
+func jitter(sched Schedule) time.Duration {
+	limit := min(sched.Jitter, sched.Interval/2)
+	if limit <= 0 {
+		return 0
+	}
+	return time.Duration(rand.Int63n(int64(2*limit)+1)) - limit
+}
+
+// misfireThreshold is how late a run may fire before it counts as missed
+func misfireThreshold(interval time.Duration) time.Duration {
+	return min(interval/2, time.Second)
+}
+
+// due returns the executions of the jobs due at now, applying their missed run
+// policies, and moves the jobs to their next run; the lock must be held. The
+// returned entries had runs fall due and must be saved to the store.
+func (s *jobScheduler) due(now time.Time) ([]func(), []*scheduledEntry) {
+	var tasks []func()
+	var fired []*scheduledEntry
+	for len(s.queue) > 0 && !s.queue[0].at().After(now) {
+		entry := s.queue[0]
+		sched := entry.job.Schedule()
+
+		if entry.hasDeferred() {
+			entry.deferred = time.Time{}
+			heap.Fix(&s.queue, 0)
+			if entry.job.Paused() {
+				s.skip(entry, SkipReasonPaused, 1)
+				continue
+			}
+			if s.excluded(entry, sched, now) {
+				continue
+			}
+			s.logger.Debug("Executing deferred run of scheduled component", "name", entry.job.name)
+			if task := s.dispatch(entry, false); task != nil {
+				tasks = append(tasks, task)
+			}
+			continue
+		}
+
+		// All runs up to now are due; only the last one may still be on time
+		dueRuns := 1
+		if now.After(entry.next) {
+			dueRuns = int(now.Sub(entry.next)/sched.Interval) + 1
+		}
+		location, _ := entry.job.exclusions()
+		last := entry.next.Add(time.Duration(dueRuns-1) * sched.Interval)
+		lateness := now.Sub(last.Add(entry.offset))
+		entry.next = nextRun(last, sched, location)
+		entry.offset = jitter(sched)
+		heap.Fix(&s.queue, 0)
+		entry.job.setLastFire(last)
+		fired = append(fired, entry)
+
+		if entry.job.Paused() {
+			s.logger.Debug("Skipping paused scheduled component", "name", entry.job.name)
+			s.skip(entry, SkipReasonPaused, 1)
+			continue
+		}
+		if s.excluded(entry, sched, now) {
+			continue
+		}
+
+		onTime := !entry.running && lateness <= misfireThreshold(sched.Interval)
+		missed := dueRuns
+		if onTime {
+			missed--
+		}
+
+		runs := 1
+		switch sched.MissedRuns {
+		case MissedRunCatchUpAll:
+			runs = dueRuns
+		case MissedRunSkip:
+			runs = 0
+			if onTime {
+				runs = 1
+			}
+		}
+		if missed > 0 {
+			s.logger.Warn("Scheduled component missed runs",
+				"name", entry.job.name,
+				"missed", missed,
+				"executions", runs,
+				"policy", missedRunPolicy(sched))
+		} else {
+			s.logger.Debug("Executing scheduled component", "name", entry.job.name)
+		}
+
+		if runs < missed {
+			s.skip(entry, SkipReasonMissed, missed-runs)
+		}
+		if runs == 0 {
+			continue
+		}
+		// A regular run replaces the deferred one
+		entry.deferred = time.Time{}
+		catchUp := sched.MissedRuns == MissedRunCatchUpAll
+		if task := s.dispatch(entry, catchUp); task != nil {
+			tasks = append(tasks, task)
+		}
+		if catchUp {
+			// The other runs execute one after another once the first completed
+			entry.pending += runs - 1
+		}
+	}
+	return tasks, fired
+}
+
+// excluded reports whether a run at now falls into an exclusion calendar of the
+// entry's job, deferring it to the end of the exclusion if the schedule says so;
+// the lock must be held
+func (s *jobScheduler) excluded(entry *scheduledEntry, sched Schedule, now time.Time) bool {
+	location, calendars := entry.job.exclusions()
+	if len(calendars) == 0 {
+		return false
+	}
+	calendar, until := excludedUntil(calendars, now, location)
+	if calendar == nil {
+		return false
+	}
+
+	if sched.ExcludedRuns == ExcludedRunDefer {
+		s.logger.Info("Deferring excluded run of scheduled component",
+			"name", entry.job.name,
+			"calendar", calendar.name,
+			"until", until.Format(time.RFC3339))
+		if until.After(entry.deferred) {
+			entry.deferred = until
+			heap.Fix(&s.queue, entry.index)
+		}
+		return true
+	}
+	s.logger.Info("Skipping excluded run of scheduled component", "name", entry.job.name, "calendar", calendar.name)
+	s.skip(entry, "calendar:"+calendar.name, 1)
+	return true
+}
+
+// skip records runs of the entry's job that didn't execute
+func (s *jobScheduler) skip(entry *scheduledEntry, reason string, runs int) {
+	if s.metrics != nil {
+		s.metrics.RecordSkip(entry.job.name, reason, runs)
+	}
+}
+
+// missedRunPolicy returns the policy of a schedule, defaulting to MissedRunFireOnceNow
+func missedRunPolicy(sched Schedule) MissedRunPolicy {
+	if sched.MissedRuns == "" {
+		return MissedRunFireOnceNow
+	}
+	return sched.MissedRuns
+}
+
+// dispatch returns the next execution of the entry's job, or nil if an execution
+// is in flight: then another execution is queued after it, accumulating if queue
+// is set and merged into those already queued otherwise. The lock must be held.
+func (s *jobScheduler) dispatch(entry *scheduledEntry, queue bool) func() {
+	if entry.running {
+		if queue {
+			entry.pending++
+		} else if entry.pending == 0 {
+			entry.pending = 1
+		}
+		return nil
+	}
+	entry.running = true
+
+	g, ctx := s.goroutines.add(entry.ctx, entry.job.name, goroutineScheduled)
+	return func() {
+		s.goroutines.run(g, ctx, func(ctx context.Context) {
+			s.run(ctx, entry.job)
+		})
+		s.completed(entry)
+	}
+}
+
+// save stores the last due time of the entries, without holding the lock
+func (s *jobScheduler) save(entries []*scheduledEntry) {
+	if s.store == nil {
+		return
+	}
+	for _, entry := range entries {
+		if err := s.store.SaveLastFire(entry.job.name, entry.job.LastFire()); err != nil {
+			s.logger.Warn("Failed to save the last run of a scheduled component", "name", entry.job.name, "error", err)
+		}
+	}
+}
+
+// submit hands executions to the executor without holding the lock, since Submit
+// may block until a worker, possibly completing another execution, is free
+func (s *jobScheduler) submit(tasks ...func()) {
+	for _, task := range tasks {
+		if task == nil {
+			continue
+		}
+		if s.executor == nil || s.executor.Submit(task) != nil {
+			go task()
+		}
+	}
+}
+
+// completed runs the pending execution of an entry, if any
+func (s *jobScheduler) completed(entry *scheduledEntry) {
+	var task func()
+	s.mu.Lock()
+	entry.running = false
+	if entry.pending > 0 {
+		entry.pending--
+		task = s.dispatch(entry, false)
+	}
+	s.mu.Unlock()
+
+	s.submit(task)
+}
@@ -0,0 +1,29 @@
+package container
+
+import "testing"
+
+func TestDispatchWhileRunning(t *testing.T) {
+	tests := []struct {
+		name    string
+		pending int
+		queue   bool
+		want    int
+	}{
+		{name: "merged into none", pending: 0, want: 1},
+		{name: "merged into queued catch-up runs", pending: 3, want: 3},
+		{name: "queued", pending: 3, queue: true, want: 4},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			var s jobScheduler
+			entry := &scheduledEntry{running: true, pending: tt.pending}
+			if task := s.dispatch(entry, tt.queue); task != nil {
+				t.Fatal("dispatch() returned an execution while one is in flight")
+			}
+			if entry.pending != tt.want {
+				t.Errorf("pending = %d, want %d", entry.pending, tt.want)
+			}
+		})
+	}
+}
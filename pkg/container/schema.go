@@ -0,0 +1,216 @@
+package container
+
+import (
+	"encoding"
+	"encoding/json"
+	"net/url"
+	"reflect"
+	"sort"
+	"strings"
+	"time"
+)
+
+// ConfigSchemaVersion is the JSON Schema dialect of generated config schemas
+const ConfigSchemaVersion = "https://json-schema.org/draft/2020-12/schema"
+
+// GenerateConfigSchema returns a JSON Schema describing every configuration section
+// registered with RegisterConfigProperties and every variable declared with
+// DeclareVariable, so that IDEs can complete application.yml
+// and CI can validate it. Keys follow the yaml tags used for binding, defaults are
+// taken from the registered structs and fields can be documented with tags:
+//
+//	type DBConfig struct {
+//		URL  string `yaml:"url" description:"Database connection URL"`
+//		Mode string `yaml:"mode" enum:"read-only,read-write"`
+//	}
+func GenerateConfigSchema(ctx ApplicationContext) ([]byte, error) {
+	var sections []*ConfigProperties
+	for _, name := range ctx.GetComponentNames() {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if props, ok := comp.(*ConfigProperties); ok {
+			sections = append(sections, props)
+		}
+	}
+	sort.Slice(sections, func(i, j int) bool {
+		return sections[i].prefix < sections[j].prefix
+	})
+
+	generator := schemaGenerator{converters: NewVariableHelper(ctx).converters()}
+	root := objectSchema()
+	root["$schema"] = ConfigSchemaVersion
+	for _, props := range sections {
+		// Nest the section below its dot-separated prefix
+		parent, key := schemaParent(root, props.prefix)
+		defaults := reflect.ValueOf(props.defaults).Elem()
+		parent["properties"].(map[string]interface{})[key] = generator.schema(defaults.Type(), defaults)
+	}
+
+	// Declared variables not covered by a section
+	if source, ok := ctx.(VariableDeclarations); ok {
+		for _, declaration := range source.GetVariableDeclarations() {
+			parent, key := schemaParent(root, declaration.Name)
+			properties := parent["properties"].(map[string]interface{})
+			if _, ok := properties[key]; ok {
+				continue
+			}
+
+			value := reflect.New(varTypes[declaration.Type]).Elem()
+			generator.converters.decode(declaration.Default, value, declaration.Name)
+			variableSchema := generator.schema(value.Type(), value)
+			if declaration.Description != "" {
+				variableSchema["description"] = declaration.Description
+			}
+			properties[key] = variableSchema
+			if declaration.Required {
+				required, _ := parent["required"].([]string)
+				parent["required"] = append(required, key)
+			}
+		}
+	}
+
+	return json.MarshalIndent(root, "", "  ")
+}
+
+// schemaParent returns the object schema holding a dot-separated key, creating the
+// objects on its path, and the last part of the key
+func schemaParent(root map[string]interface{}, name string) (map[string]interface{}, string) {
+	parent := root
+	parts := strings.Split(name, ".")
+	for _, part := range parts[:len(parts)-1] {
+		properties := parent["properties"].(map[string]interface{})
+		next, ok := properties[part].(map[string]interface{})
+		if !ok || next["properties"] == nil {
+			next = objectSchema()
+			properties[part] = next
+		}
+		parent = next
+	}
+	return parent, parts[len(parts)-1]
+}
+
+// schemaGenerator builds JSON Schemas for Go types
+type schemaGenerator struct {
+	converters *ConverterRegistry
+}
+
+var (
+	durationType = reflect.TypeOf(time.Duration(0))
+	timeType     = reflect.TypeOf(time.Time{})
+	urlType      = reflect.TypeOf(url.URL{})
+)
+
+// schema returns the schema for t; value holds the defaults and may be invalid
+func (g schemaGenerator) schema(t reflect.Type, value reflect.Value) map[string]interface{} {
+	if t.Kind() == reflect.Pointer {
+		if value.IsValid() && !value.IsNil() {
+			return g.schema(t.Elem(), value.Elem())
+		}
+		return g.schema(t.Elem(), reflect.Value{})
+	}
+
+	// Types bound from strings by converters or text unmarshalers
+	switch t {
+	case durationType:
+		return withDefault(map[string]interface{}{"type": "string", "format": "duration"}, value, func(v reflect.Value) interface{} {
+			return v.Interface().(time.Duration).String()
+		})
+	case timeType:
+		return withDefault(map[string]interface{}{"type": "string", "format": "date-time"}, value, func(v reflect.Value) interface{} {
+			return v.Interface().(time.Time).Format(time.RFC3339)
+		})
+	case urlType:
+		return withDefault(map[string]interface{}{"type": "string", "format": "uri"}, value, func(v reflect.Value) interface{} {
+			u := v.Interface().(url.URL)
+			return u.String()
+		})
+	}
+	_, converted := g.converters.Lookup(t)
+	if converted || reflect.PointerTo(t).Implements(textUnmarshalerType) {
+		return withDefault(map[string]interface{}{"type": "string"}, value, func(v reflect.Value) interface{} {
+			if marshaler, ok := v.Interface().(encoding.TextMarshaler); ok {
+				if text, err := marshaler.MarshalText(); err == nil {
+					return string(text)
+				}
+			}
+			return nil
+		})
+	}
+
+	scalar := func(v reflect.Value) interface{} { return v.Interface() }
+	switch t.Kind() {
+	case reflect.Bool:
+		return withDefault(map[string]interface{}{"type": "boolean"}, value, scalar)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64,
+		reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		return withDefault(map[string]interface{}{"type": "integer"}, value, scalar)
+	case reflect.Float32, reflect.Float64:
+		return withDefault(map[string]interface{}{"type": "number"}, value, scalar)
+	case reflect.String:
+		return withDefault(map[string]interface{}{"type": "string"}, value, scalar)
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return map[string]interface{}{"type": "string"}
+		}
+		return map[string]interface{}{"type": "array", "items": g.schema(t.Elem(), reflect.Value{})}
+	case reflect.Map:
+		return map[string]interface{}{"type": "object", "additionalProperties": g.schema(t.Elem(), reflect.Value{})}
+	case reflect.Struct:
+		result := objectSchema()
+		g.addFields(result["properties"].(map[string]interface{}), t, value)
+		return result
+	}
+	return map[string]interface{}{}
+}
+
+// addFields adds the schemas of the struct fields, keyed like decodeStruct binds them
+func (g schemaGenerator) addFields(properties map[string]interface{}, t reflect.Type, value reflect.Value) {
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		var fieldValue reflect.Value
+		if value.IsValid() {
+			fieldValue = value.Field(i)
+		}
+
+		key, opts, _ := strings.Cut(field.Tag.Get("yaml"), ",")
+		if key == "-" {
+			continue
+		}
+		if strings.Contains(opts, "inline") && field.Type.Kind() == reflect.Struct {
+			g.addFields(properties, field.Type, fieldValue)
+			continue
+		}
+		if key == "" {
+			key = strings.ToLower(field.Name)
+		}
+
+		fieldSchema := g.schema(field.Type, fieldValue)
+		if description := field.Tag.Get("description"); description != "" {
+			fieldSchema["description"] = description
+		}
+		if enum := field.Tag.Get("enum"); enum != "" {
+			fieldSchema["enum"] = strings.Split(enum, ",")
+		}
+		properties[key] = fieldSchema
+	}
+}
+
+// withDefault adds value as the default unless it is missing or the zero value
+func withDefault(schema map[string]interface{}, value reflect.Value, format func(reflect.Value) interface{}) map[string]interface{} {
+	if value.IsValid() && !value.IsZero() {
+		if def := format(value); def != nil {
+			schema["default"] = def
+		}
+	}
+	return schema
+}
+
+func objectSchema() map[string]interface{} {
+	return map[string]interface{}{"type": "object", "properties": map[string]interface{}{}}
+}
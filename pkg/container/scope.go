@@ -0,0 +1,209 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"reflect"
+	"sync"
+)
+
+// ScopedComponent declares a component that is created lazily once per Scope, e.g. per
+// HTTP request, and closed with the scope if it implements io.Closer. The web and gRPC
+// starters open a scope for every request:
+//
+//	builder.RegisterComponent(container.NewScoped("unitOfWork", func(scope *container.Scope) (interface{}, error) {
+//		var db *sql.DB
+//		if err := scope.App().GetComponent(&db); err != nil {
+//			return nil, err
+//		}
+//		return newUnitOfWork(scope.Context(), db)
+//	}))
+//
+//	// In a handler
+//	uow, err := container.GetScoped[*UnitOfWork](r.Context(), "unitOfWork")
+type ScopedComponent struct {
+	name   string
+	create func(scope *Scope) (interface{}, error)
+}
+
+// NewScoped creates the declaration of a scoped component
+func NewScoped(name string, create func(scope *Scope) (interface{}, error)) *ScopedComponent {
+	return &ScopedComponent{name: name, create: create}
+}
+
+// Name returns the component name
+func (s *ScopedComponent) Name() string {
+	return s.name
+}
+
+// Init is a no-op: instances are created per scope
+func (s *ScopedComponent) Init(ApplicationContext) error {
+	return nil
+}
+
+// scopeKey is the context key of the current scope
+type scopeKey struct{}
+
+// scopeState is shared by a scope and the views passed to factories
+type scopeState struct {
+	app    ApplicationContext
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu        sync.Mutex
+	instances map[string]*scopedInstance
+	order     []string
+	closed    bool
+}
+
+// scopedInstance is a component instance created in a scope
+type scopedInstance struct {
+	ready chan struct{}
+	value interface{}
+	err   error
+}
+
+// Scope holds the instances of scoped components for one unit of work. Close it
+// when the work is done.
+type Scope struct {
+	state *scopeState
+	// resolving are the components being created by this view, to detect cycles
+	resolving []string
+}
+
+// NewScope opens a scope bound to parent; its Context carries the scope and is
+// cancelled when the scope is closed
+func NewScope(parent context.Context, app ApplicationContext) *Scope {
+	state := &scopeState{app: app}
+	scope := &Scope{state: state}
+	ctx, cancel := context.WithCancel(parent)
+	state.ctx = context.WithValue(ctx, scopeKey{}, scope)
+	state.cancel = cancel
+	return scope
+}
+
+// ScopeFromContext returns the scope carried by ctx
+func ScopeFromContext(ctx context.Context) (*Scope, bool) {
+	scope, ok := ctx.Value(scopeKey{}).(*Scope)
+	return scope, ok
+}
+
+// Context returns the scope's context
+func (s *Scope) Context() context.Context {
+	return s.state.ctx
+}
+
+// App returns the application context the scope belongs to
+func (s *Scope) App() ApplicationContext {
+	return s.state.app
+}
+
+// Get returns the scope's instance of the named scoped component, creating it on
+// first use. Concurrent calls for the same component wait for a single creation.
+func (s *Scope) Get(name string) (interface{}, error) {
+	for _, resolving := range s.resolving {
+		if resolving == name {
+			return nil, CircularDependencyError(append(append([]string(nil), s.resolving...), name))
+		}
+	}
+
+	state := s.state
+	state.mu.Lock()
+	if state.closed {
+		state.mu.Unlock()
+		return nil, ErrorWithCode("SCOPE_CLOSED", "scope is closed, can't get %s", name)
+	}
+	if instance, ok := state.instances[name]; ok {
+		state.mu.Unlock()
+		<-instance.ready
+		return instance.value, instance.err
+	}
+	instance := &scopedInstance{ready: make(chan struct{})}
+	if state.instances == nil {
+		state.instances = make(map[string]*scopedInstance)
+	}
+	state.instances[name] = instance
+	state.mu.Unlock()
+
+	instance.value, instance.err = s.create(name)
+	close(instance.ready)
+
+	if instance.err == nil {
+		state.mu.Lock()
+		state.order = append(state.order, name)
+		state.mu.Unlock()
+	}
+	return instance.value, instance.err
+}
+
+// create runs the factory of a scoped component with a view tracking the creation path
+func (s *Scope) create(name string) (value interface{}, err error) {
+	comp, err := s.state.app.GetComponentByName(name)
+	if err != nil {
+		return nil, err
+	}
+	scoped, ok := comp.(*ScopedComponent)
+	if !ok {
+		return nil, ComponentTypeError(name, "*container.ScopedComponent", fmt.Sprintf("%T", comp))
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = ComponentInitializationError(name, &PanicError{Value: r})
+		}
+	}()
+	view := &Scope{state: s.state, resolving: append(append([]string(nil), s.resolving...), name)}
+	value, err = scoped.create(view)
+	if err != nil {
+		return nil, ComponentInitializationError(name, err)
+	}
+	return value, nil
+}
+
+// Close cancels the scope's context and closes the instances implementing
+// io.Closer in reverse creation order. Closing twice is a no-op.
+func (s *Scope) Close() error {
+	state := s.state
+	state.mu.Lock()
+	if state.closed {
+		state.mu.Unlock()
+		return nil
+	}
+	state.closed = true
+	order := state.order
+	instances := state.instances
+	state.mu.Unlock()
+
+	var errs []error
+	for i := len(order) - 1; i >= 0; i-- {
+		if closer, ok := instances[order[i]].value.(io.Closer); ok {
+			if err := closer.Close(); err != nil {
+				errs = append(errs, fmt.Errorf("closing scoped component %s: %w", order[i], err))
+			}
+		}
+	}
+	state.cancel()
+	return errors.Join(errs...)
+}
+
+// GetScoped returns the instance of the named scoped component in the scope carried
+// by ctx, converted to T
+func GetScoped[T any](ctx context.Context, name string) (T, error) {
+	var zero T
+
+	scope, ok := ScopeFromContext(ctx)
+	if !ok {
+		return zero, ErrorWithCode("NO_SCOPE", "no scope in context, can't get %s", name)
+	}
+	value, err := scope.Get(name)
+	if err != nil {
+		return zero, err
+	}
+	result, ok := value.(T)
+	if !ok {
+		return zero, ComponentTypeError(name, reflect.TypeOf((*T)(nil)).Elem().String(), fmt.Sprintf("%T", value))
+	}
+	return result, nil
+}
@@ -0,0 +1,258 @@
+package container
+
+import (
+	"bytes"
+	"context"
+	"runtime"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// DefaultStopBudget is the stop budget used when Config.StopBudget is zero
+const DefaultStopBudget = 5 * time.Second
+
+// goroutineExitGrace is how long shutdown waits for background and scheduled
+// goroutines to return after their components were stopped
+const goroutineExitGrace = time.Second
+
+// ShutdownReport describes the last shutdown of a container
+type ShutdownReport struct {
+	// Duration is the total time StopAll took
+	Duration time.Duration
+	// SlowComponents are the components whose Stop or Close exceeded the stop budget
+	SlowComponents []SlowStop
+	// RunningGoroutines are background and scheduled components whose goroutine
+	// was still running after shutdown
+	RunningGoroutines []RunningGoroutine
+}
+
+// SlowStop is a component that took longer to stop than the stop budget
+type SlowStop struct {
+	Name     string
+	Duration time.Duration
+}
+
+// RunningGoroutine is a component goroutine that didn't return during shutdown
+type RunningGoroutine struct {
+	Name string
+	// Kind is "background" or "scheduled"
+	Kind string
+	// Stack is a sample of the goroutine's stack, empty if it couldn't be found
+	Stack string
+}
+
+// Kinds of component goroutines
+const (
+	goroutineBackground = "background"
+	goroutineScheduled  = "scheduled"
+)
+
+// managedGoroutine is the goroutine running a background or scheduled component
+type managedGoroutine struct {
+	name   string
+	kind   string
+	id     uint64
+	cancel context.CancelFunc
+}
+
+// goroutineTracker keeps the running component goroutines
+type goroutineTracker struct {
+	mu         sync.Mutex
+	goroutines map[*managedGoroutine]bool
+	wg         sync.WaitGroup
+}
+
+func newGoroutineTracker() *goroutineTracker {
+	return &goroutineTracker{goroutines: make(map[*managedGoroutine]bool)}
+}
+
+// launch runs fn in a goroutine with a context that is cancelled once the
+// component is stopped
+func (t *goroutineTracker) launch(ctx context.Context, name, kind string, fn func(ctx context.Context)) {
+	g, ctx := t.add(ctx, name, kind)
+	go t.run(g, ctx, fn)
+}
+
+// add registers a goroutine about to run fn for a component and returns its context
+func (t *goroutineTracker) add(ctx context.Context, name, kind string) (*managedGoroutine, context.Context) {
+	ctx, cancel := context.WithCancel(ctx)
+	g := &managedGoroutine{name: name, kind: kind, cancel: cancel}
+
+	t.mu.Lock()
+	t.goroutines[g] = true
+	t.mu.Unlock()
+	t.wg.Add(1)
+	return g, ctx
+}
+
+// run runs fn on the calling goroutine as the registered goroutine g
+func (t *goroutineTracker) run(g *managedGoroutine, ctx context.Context, fn func(ctx context.Context)) {
+	t.mu.Lock()
+	g.id = currentGoroutineID()
+	t.mu.Unlock()
+
+	defer func() {
+		t.mu.Lock()
+		delete(t.goroutines, g)
+		t.mu.Unlock()
+		g.cancel()
+		t.wg.Done()
+	}()
+	fn(ctx)
+}
+
+// cancel cancels the contexts of a component's goroutines once it was stopped
+func (t *goroutineTracker) cancel(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	for g := range t.goroutines {
+		if g.name == name {
+			g.cancel()
+		}
+	}
+}
+
+// wait waits up to timeout for all goroutines to return and reports the ones still running
+func (t *goroutineTracker) wait(timeout time.Duration) []RunningGoroutine {
+	done := make(chan struct{})
+	go func() {
+		t.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		return nil
+	case <-time.After(timeout):
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	stacks := goroutineStacks()
+	running := make([]RunningGoroutine, 0, len(t.goroutines))
+	for g := range t.goroutines {
+		running = append(running, RunningGoroutine{Name: g.name, Kind: g.kind, Stack: stacks[g.id]})
+	}
+	sort.Slice(running, func(i, j int) bool {
+		if running[i].Name != running[j].Name {
+			return running[i].Name < running[j].Name
+		}
+		return running[i].Kind < running[j].Kind
+	})
+	return running
+}
+
+// currentGoroutineID parses the ID of the calling goroutine from its stack header
+func currentGoroutineID() uint64 {
+	buf := make([]byte, 64)
+	buf = buf[:runtime.Stack(buf, false)]
+	id, _ := parseGoroutineHeader(buf)
+	return id
+}
+
+// goroutineStacks returns the stacks of all goroutines by ID
+func goroutineStacks() map[uint64]string {
+	buf := make([]byte, 1<<16)
+	for {
+		n := runtime.Stack(buf, true)
+		if n < len(buf) {
+			buf = buf[:n]
+			break
+		}
+		buf = make([]byte, 2*len(buf))
+	}
+
+	stacks := make(map[uint64]string)
+	for _, stack := range bytes.Split(buf, []byte("\n\n")) {
+		if id, ok := parseGoroutineHeader(stack); ok {
+			stacks[id] = string(stack)
+		}
+	}
+	return stacks
+}
+
+// parseGoroutineHeader parses the ID from a stack starting with "goroutine 42 [running]:"
+func parseGoroutineHeader(stack []byte) (uint64, bool) {
+	rest, found := bytes.CutPrefix(stack, []byte("goroutine "))
+	if !found {
+		return 0, false
+	}
+	end := bytes.IndexByte(rest, ' ')
+	if end < 0 {
+		return 0, false
+	}
+	id, err := strconv.ParseUint(string(rest[:end]), 10, 64)
+	return id, err == nil
+}
+
+// GetShutdownReport returns the report of the last shutdown, nil if the container
+// wasn't shut down yet
+func (c *container) GetShutdownReport() *ShutdownReport {
+	if c.lifecycleManager == nil {
+		return nil
+	}
+	return c.lifecycleManager.ShutdownReport()
+}
+
+// ShutdownReport returns the report of the last StopAll
+func (m *defaultLifecycleManager) ShutdownReport() *ShutdownReport {
+	m.reportMu.Lock()
+	defer m.reportMu.Unlock()
+	return m.report
+}
+
+// recordStop remembers a component whose stop exceeded the budget
+func (m *defaultLifecycleManager) recordStop(name string, duration time.Duration) {
+	if duration <= m.stopBudget {
+		return
+	}
+	m.reportMu.Lock()
+	defer m.reportMu.Unlock()
+	m.slowStops = append(m.slowStops, SlowStop{Name: name, Duration: duration})
+}
+
+// finishShutdown builds the shutdown report and logs it, at Warn level if a
+// component was slow or a goroutine is still running
+func (m *defaultLifecycleManager) finishShutdown(start time.Time) {
+	running := m.goroutines.wait(goroutineExitGrace)
+
+	m.reportMu.Lock()
+	slow := m.slowStops
+	m.slowStops = nil
+	sort.Slice(slow, func(i, j int) bool {
+		return slow[i].Duration > slow[j].Duration
+	})
+	report := &ShutdownReport{
+		Duration:          time.Since(start),
+		SlowComponents:    slow,
+		RunningGoroutines: running,
+	}
+	m.report = report
+	m.reportMu.Unlock()
+
+	if len(slow) == 0 && len(running) == 0 {
+		m.logger.Info("Components stopped", "time_ms", report.Duration.Milliseconds())
+		return
+	}
+
+	for _, s := range slow {
+		m.logger.Warn("Component exceeded its stop budget",
+			"name", s.Name,
+			"time_ms", s.Duration.Milliseconds(),
+			"budget_ms", m.stopBudget.Milliseconds())
+	}
+	for _, g := range running {
+		m.logger.Warn("Component goroutine still running after shutdown",
+			"name", g.Name,
+			"kind", g.Kind,
+			"stack", g.Stack)
+	}
+	m.logger.Warn("Slow shutdown",
+		"time_ms", report.Duration.Milliseconds(),
+		"slow_components", len(slow),
+		"running_goroutines", len(running))
+}
@@ -1,5 +1,7 @@
 package container
 
+import "strings"
+
 // Starter defines an interface for components that can create and configure other components
 // at container startup. This allows creation of modular "starters" like in Spring Boot.
 type Starter interface {
@@ -114,3 +116,80 @@ func ComponentExistsCondition(name string) func(ApplicationContext) bool {
 		return ctx.HasComponent(name)
 	}
 }
+
+// PropertyMissingCondition checks that a property is not set, e.g. to register a
+// default only if the user didn't configure one
+func PropertyMissingCondition(property string) func(ApplicationContext) bool {
+	return func(ctx ApplicationContext) bool {
+		return ctx.GetVariable(property) == ""
+	}
+}
+
+// PropertyInCondition checks if a property has one of the given values
+// (compared case-insensitively)
+func PropertyInCondition(property string, values ...string) func(ApplicationContext) bool {
+	return func(ctx ApplicationContext) bool {
+		value := ctx.GetVariable(property)
+		for _, expected := range values {
+			if strings.EqualFold(value, expected) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// ProfileCondition checks if any of the profiles is active. A profile prefixed
+// with "!" matches if that profile is not active:
+//
+//	container.ProfileCondition("prod", "staging")
+//	container.ProfileCondition("!test")
+func ProfileCondition(profiles ...string) func(ApplicationContext) bool {
+	return func(ctx ApplicationContext) bool {
+		active := make(map[string]bool)
+		for _, profile := range ctx.GetActiveProfiles() {
+			active[profile] = true
+		}
+		for _, profile := range profiles {
+			if name, negated := strings.CutPrefix(profile, "!"); negated {
+				if !active[name] {
+					return true
+				}
+			} else if active[profile] {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// AllConditions checks that all conditions hold
+func AllConditions(conditions ...func(ApplicationContext) bool) func(ApplicationContext) bool {
+	return func(ctx ApplicationContext) bool {
+		for _, condition := range conditions {
+			if !condition(ctx) {
+				return false
+			}
+		}
+		return true
+	}
+}
+
+// AnyCondition checks that at least one condition holds
+func AnyCondition(conditions ...func(ApplicationContext) bool) func(ApplicationContext) bool {
+	return func(ctx ApplicationContext) bool {
+		for _, condition := range conditions {
+			if condition(ctx) {
+				return true
+			}
+		}
+		return false
+	}
+}
+
+// NotCondition negates a condition
+func NotCondition(condition func(ApplicationContext) bool) func(ApplicationContext) bool {
+	return func(ctx ApplicationContext) bool {
+		return !condition(ctx)
+	}
+}
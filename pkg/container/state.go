@@ -0,0 +1,110 @@
+package container
+
+import (
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+)
+
+// StatefulComponent is a component whose in-memory state, e.g. consumer offsets or
+// a warm cache, survives restarts. With a Config.StateStore the state is saved
+// after the component stopped and restored before it starts again.
+type StatefulComponent interface {
+	Component
+	// SaveState returns the state to keep
+	SaveState(ctx context.Context) ([]byte, error)
+	// RestoreState receives the state saved by the previous run; it isn't called
+	// when there is none
+	RestoreState(ctx context.Context, data []byte) error
+}
+
+// StateStore keeps the state of stateful components, by component name
+type StateStore interface {
+	// LoadState returns the saved state of a component, nil if there is none
+	LoadState(ctx context.Context, name string) ([]byte, error)
+	// SaveState stores the state of a component
+	SaveState(ctx context.Context, name string, data []byte) error
+}
+
+// FileStateStore is a StateStore keeping the state of each component in a file
+// <name>.state of a directory
+type FileStateStore struct {
+	dir string
+}
+
+// NewFileStateStore creates a store writing to dir, created on the first save
+func NewFileStateStore(dir string) *FileStateStore {
+	return &FileStateStore{dir: dir}
+}
+
+// LoadState reads the state file of a component
+func (s *FileStateStore) LoadState(_ context.Context, name string) ([]byte, error) {
+	data, err := os.ReadFile(s.path(name))
+	if errors.Is(err, fs.ErrNotExist) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SaveState rewrites the state file of a component
+func (s *FileStateStore) SaveState(_ context.Context, name string, data []byte) error {
+	if err := os.MkdirAll(s.dir, 0o755); err != nil {
+		return err
+	}
+	// Write to a temporary file first so that a crash never leaves a truncated file
+	tmp := s.path(name) + ".tmp"
+	if err := os.WriteFile(tmp, data, 0o600); err != nil {
+		return err
+	}
+	return os.Rename(tmp, s.path(name))
+}
+
+func (s *FileStateStore) path(name string) string {
+	return filepath.Join(s.dir, filepath.Base(name)+".state")
+}
+
+// restoreState passes the saved state to a stateful component; a state that can't
+// be loaded or restored is logged and the component starts without it
+func (m *defaultLifecycleManager) restoreState(ctx context.Context, component Component, name string) {
+	stateful, ok := component.(StatefulComponent)
+	if !ok || m.stateStore == nil {
+		return
+	}
+
+	data, err := m.stateStore.LoadState(ctx, name)
+	if err == nil && data != nil {
+		err = stateful.RestoreState(ctx, data)
+	}
+	if err != nil {
+		m.metrics.RecordError(name)
+		m.logger.Error("Failed to restore component state, starting without it", "name", name, "error", err)
+		return
+	}
+	if data != nil {
+		m.logger.Debug("Component state restored", "name", name, "bytes", len(data))
+	}
+}
+
+// saveState stores the state of a stopped stateful component
+func (m *defaultLifecycleManager) saveState(ctx context.Context, component Component, name string) {
+	stateful, ok := component.(StatefulComponent)
+	if !ok || m.stateStore == nil {
+		return
+	}
+
+	data, err := stateful.SaveState(ctx)
+	if err == nil {
+		err = m.stateStore.SaveState(ctx, name, data)
+	}
+	if err != nil {
+		m.metrics.RecordError(name)
+		m.logger.Error("Failed to save component state", "name", name, "error", err)
+		return
+	}
+	m.logger.Debug("Component state saved", "name", name, "bytes", len(data))
+}
+
+// Ensure that FileStateStore implements StateStore
+var _ StateStore = (*FileStateStore)(nil)
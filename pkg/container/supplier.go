@@ -0,0 +1,80 @@
+package container
+
+// SupplierFunc builds a supplied component; see ContextBuilder.RegisterSupplier
+type SupplierFunc func(ctx ApplicationContext) (Component, error)
+
+// SuppliedComponent holds the name of a component until it is built. It is
+// replaced in the registry by the built component when it is initialized.
+type SuppliedComponent struct {
+	name  string
+	build SupplierFunc
+}
+
+// Name returns the name of the supplied component
+func (s *SuppliedComponent) Name() string {
+	return s.name
+}
+
+// Init builds the component, replaces the supplier with it and initializes it.
+// Nothing is built during dependency discovery: the components the supplier
+// looks up are initialized on demand instead and become its dependencies.
+func (s *SuppliedComponent) Init(ctx ApplicationContext) error {
+	if _, discovering := ctx.(*accessTrackingContext); discovering {
+		return nil
+	}
+
+	component, err := s.build(ctx)
+	if err != nil {
+		return err
+	}
+	if isNil(component) {
+		return ErrorWithCode("NIL_SUPPLIED_COMPONENT", "supplier of %s built no component", s.name)
+	}
+	if component.Name() != s.name {
+		return ErrorWithCode("SUPPLIED_NAME_MISMATCH", "supplier of %s built component %s", s.name, component.Name())
+	}
+
+	c := containerOf(ctx)
+	if c == nil {
+		return ErrorWithCode("UNSUPPORTED_CONTEXT", "supplied component %s needs a container context, got %T", s.name, ctx)
+	}
+	registry, ok := c.componentRegistry.(*defaultComponentRegistry)
+	if !ok {
+		return ErrorWithCode("UNSUPPORTED_REGISTRY", "supplied component %s can't replace its supplier in %T", s.name, c.componentRegistry)
+	}
+	registry.replace(component)
+	return component.Init(ctx)
+}
+
+// isSupplied reports whether the named component is a supplier not built yet
+func (c *container) isSupplied(name string) bool {
+	comp, err := c.componentRegistry.Get(name)
+	if err != nil {
+		return false
+	}
+	_, ok := comp.(*SuppliedComponent)
+	return ok
+}
+
+// RegisterSupplier registers the name of a component that build constructs when
+// the component is initialized, after the variables are bound and its
+// dependencies are initialized
+func (c *container) RegisterSupplier(name string, build func(ApplicationContext) (Component, error)) error {
+	if build == nil {
+		return c.recordRegistration(ErrorWithCode("NIL_SUPPLIER", "cannot register nil supplier %s", name))
+	}
+	return c.RegisterComponent(&SuppliedComponent{name: name, build: build})
+}
+
+// containerOf returns the container behind an initialization context
+func containerOf(ctx ApplicationContext) *container {
+	switch c := ctx.(type) {
+	case *container:
+		return c
+	case *initContext:
+		return c.container
+	case *moduleContext:
+		return c.container
+	}
+	return nil
+}
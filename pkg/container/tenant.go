@@ -0,0 +1,248 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// PropertyTenantsPrefix is the configuration section holding the variables of each
+// tenant, e.g. tenants.acme.db.url becomes db.url in the acme tenant
+const PropertyTenantsPrefix = "tenants"
+
+// NewChild creates a container that inherits the components and variables of
+// parent. Inherited components are shared, not initialized or started again, and
+// are overridden by components of the same name registered by block; the child's
+// variables take precedence over the parent's. Shut the child down before its parent.
+//
+// If cfg is nil the child uses the parent's configuration without its default
+// loaders and starters.
+func NewChild(ctx context.Context, parent ApplicationContext, cfg *Config, block func(ContextBuilder)) (ApplicationContext, func(), error) {
+	base, ok := parent.(*container)
+	if !ok {
+		return nil, nil, ErrorWithCode("UNSUPPORTED_PARENT", "parent %T is not a container created by New", parent)
+	}
+	if cfg == nil {
+		cfg = base.childConfig()
+	}
+
+	return newContainer(ctx, cfg, func(builder ContextBuilder) {
+		child := builder.(*container)
+		for name, value := range base.GetVariables() {
+			child.RegisterDefaultVariable(name, value)
+		}
+		block(builder)
+		child.inherit(base)
+	}, nil)
+}
+
+// childConfig returns a copy of the container's configuration for child containers,
+// without the default loaders and starters which already ran for the parent
+func (c *container) childConfig() *Config {
+	cfg := *c.config
+	cfg.DefaultVariableLoaders = nil
+	cfg.DefaultStarters = nil
+	return &cfg
+}
+
+// inherit registers the started components of parent that aren't private to a
+// module and aren't overridden by the child
+func (c *container) inherit(parent *container) {
+	if c.inherited == nil {
+		c.inherited = make(map[string]bool)
+	}
+	parent.componentRegistry.Range(func(name string, comp Component) bool {
+		if c.componentRegistry.Has(name) || parent.states.get(name) != StateStarted {
+			return true
+		}
+		if owner, exported := parent.componentRegistry.GetModule(name); owner != "" && !exported {
+			return true
+		}
+		if err := c.componentRegistry.Register(comp); err != nil {
+			return true
+		}
+		if tags := parent.componentRegistry.GetTags(name); len(tags) > 0 {
+			c.componentRegistry.AddTags(name, tags...)
+		}
+		if exposed := parent.componentRegistry.GetExposedTypes(name); len(exposed) > 0 {
+			c.componentRegistry.SetExposedTypes(name, exposed...)
+		}
+		c.inherited[name] = true
+		c.states.set(name, StateStarted)
+		return true
+	})
+}
+
+// isInherited reports whether a component belongs to the parent container
+func (c *container) isInherited(name string) bool {
+	return c.inherited[name]
+}
+
+// tenant is a tenant container, without app while it is starting
+type tenant struct {
+	app      ApplicationContext
+	shutdown func()
+}
+
+// Tenants manages per-tenant child containers of a base container, e.g. one
+// database pool per tenant next to shared services. Tenants are added and removed
+// while the application runs:
+//
+//	tenants := container.NewTenants(app, func(tenant string, builder container.ContextBuilder) {
+//		builder.RegisterComponent(&DataSource{})
+//	})
+//	defer tenants.Close()
+//	if err := tenants.AddConfigured(ctx); err != nil { ... }
+//
+//	ds, err := container.GetComponentAs[*DataSource](tenants.Get("acme"), "dataSource")
+type Tenants struct {
+	base  ApplicationContext
+	setup func(tenant string, builder ContextBuilder)
+
+	mu      sync.Mutex
+	tenants map[string]*tenant
+}
+
+// NewTenants creates a tenant manager; setup registers the components of each tenant
+func NewTenants(base ApplicationContext, setup func(tenant string, builder ContextBuilder)) *Tenants {
+	return &Tenants{base: base, setup: setup, tenants: make(map[string]*tenant)}
+}
+
+// Add creates and starts the container of a tenant. Its variables are the base
+// variables, overridden by the tenants.<name> section, overridden by variables.
+func (t *Tenants) Add(ctx context.Context, name string, variables map[string]interface{}) (ApplicationContext, error) {
+	if name == "" {
+		return nil, ErrorWithCode("INVALID_TENANT", "tenant name must not be empty")
+	}
+
+	// Reserve the name; the tenant is started without holding the lock
+	t.mu.Lock()
+	if _, exists := t.tenants[name]; exists {
+		t.mu.Unlock()
+		return nil, ErrorWithCode("DUPLICATE_TENANT", "tenant %s already exists", name)
+	}
+	t.tenants[name] = &tenant{}
+	t.mu.Unlock()
+
+	var cfg *Config
+	if base, ok := t.base.(*container); ok {
+		cfg = base.childConfig()
+		cfg.Logger = base.logger.With("tenant", name)
+	}
+
+	app, shutdown, err := NewChild(ctx, t.base, cfg, func(builder ContextBuilder) {
+		builder.RegisterVariables(NewVariableHelper(t.base).variablesWithPrefix(PropertyTenantsPrefix + "." + name + "."))
+		builder.RegisterVariables(variables)
+		if t.setup != nil {
+			t.setup(name, builder)
+		}
+	})
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if err != nil {
+		delete(t.tenants, name)
+		return nil, fmt.Errorf("tenant %s failed to start: %w", name, err)
+	}
+	t.tenants[name] = &tenant{app: app, shutdown: shutdown}
+	return app, nil
+}
+
+// AddConfigured adds every tenant with a section below tenants that isn't running yet
+func (t *Tenants) AddConfigured(ctx context.Context) error {
+	running := make(map[string]bool)
+	for _, name := range t.Names() {
+		running[name] = true
+	}
+	for _, name := range configuredTenants(t.base) {
+		if running[name] {
+			continue
+		}
+		if _, err := t.Add(ctx, name, nil); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Names returns the names of the running tenants, sorted
+func (t *Tenants) Names() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	names := make([]string, 0, len(t.tenants))
+	for name, tenant := range t.tenants {
+		if tenant.app != nil {
+			names = append(names, name)
+		}
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Get returns the container of a tenant, nil if the tenant isn't running
+func (t *Tenants) Get(name string) ApplicationContext {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+
+	if tenant, ok := t.tenants[name]; ok {
+		return tenant.app
+	}
+	return nil
+}
+
+// GetComponentByName returns a component of a tenant, including the components
+// inherited from the base container
+func (t *Tenants) GetComponentByName(tenant, name string) (Component, error) {
+	app := t.Get(tenant)
+	if app == nil {
+		return nil, ErrorWithCode("TENANT_NOT_FOUND", "tenant %s not found", tenant)
+	}
+	return app.GetComponentByName(name)
+}
+
+// Remove shuts a tenant down; the base container and other tenants keep running
+func (t *Tenants) Remove(name string) error {
+	t.mu.Lock()
+	tenant, ok := t.tenants[name]
+	if ok && tenant.app != nil {
+		delete(t.tenants, name)
+	}
+	t.mu.Unlock()
+
+	if !ok || tenant.app == nil {
+		return ErrorWithCode("TENANT_NOT_FOUND", "tenant %s not found", name)
+	}
+	tenant.shutdown()
+	return nil
+}
+
+// Close shuts all tenants down; call it before shutting the base container down
+func (t *Tenants) Close() {
+	for _, name := range t.Names() {
+		_ = t.Remove(name)
+	}
+}
+
+// configuredTenants returns the names of the sections below tenants, sorted
+func configuredTenants(app ApplicationContext) []string {
+	seen := make(map[string]bool)
+	for key := range NewVariableHelper(app).collectAllVariables() {
+		rest, found := strings.CutPrefix(key, PropertyTenantsPrefix+".")
+		if !found {
+			continue
+		}
+		if name, _, nested := strings.Cut(rest, "."); nested && name != "" {
+			seen[name] = true
+		}
+	}
+
+	names := make([]string, 0, len(seen))
+	for name := range seen {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
@@ -6,7 +6,11 @@ import (
 	"log/slog"
 	"os"
 	"path/filepath"
+	"reflect"
+	"strconv"
 	"strings"
+	"unicode"
+	"unicode/utf16"
 )
 
 // VariableLoader defines an interface for components that can load variables
@@ -19,57 +23,97 @@ type VariableLoader interface {
 type ProfileYamlLoader struct {
 	// ConfigPath specifies directory where to look for config files
 	ConfigPath string
-	// Optional explicit list of profile names to load (eg. "dev", "prod")
-	// If not specified, will read from GO_BOOT_ACTIVE_PROFILES environment variable
+	// Optional list of profile names to activate (eg. "dev", "prod") in addition to
+	// the GO_BOOT_ACTIVE_PROFILES environment variable and goboot.profiles.active
+	// (see ApplicationContext.GetActiveProfiles). Profile groups are expanded.
 	Profiles []string
+	// MergeStrategies defines how profile files combine lists and maps with earlier
+	// files, by dot-separated key. They take precedence over goboot.config.merge.*
+	// from application.yml.
+	MergeStrategies map[string]MergeStrategy
 }
 
 // Load loads variables from YAML files with profile support
 func (l ProfileYamlLoader) Load(builder ContextBuilder) error {
+	return profileFiles{
+		configPath: l.ConfigPath,
+		ext:        ".yml",
+		read:       readYamlConfig,
+		profiles:   l.Profiles,
+		strategies: l.MergeStrategies,
+	}.load(builder)
+}
+
+// profileFiles loads application<ext> and the application-<profile><ext> files of
+// the active profiles from a directory, merging each file over the earlier ones
+type profileFiles struct {
+	configPath string
+	ext        string
+	read       func(path string) (map[string]interface{}, error)
+	profiles   []string
+	strategies map[string]MergeStrategy
+}
+
+func (f profileFiles) load(builder ContextBuilder) error {
 	logger := slog.Default()
 
 	// Default to current directory if not specified
-	configPath := l.ConfigPath
+	configPath := f.configPath
 	if configPath == "" {
 		configPath = "."
 	}
 
-	// Get profiles from environment if not explicitly set
-	profiles := l.Profiles
-	if len(profiles) == 0 {
-		profilesEnv := os.Getenv("GO_BOOT_ACTIVE_PROFILES")
-		if profilesEnv != "" {
-			profiles = strings.Split(profilesEnv, ",")
-			for i, profile := range profiles {
-				profiles[i] = strings.TrimSpace(profile)
-			}
-			logger.Info("Using profiles from GO_BOOT_ACTIVE_PROFILES", "profiles", profiles)
-		}
-	}
-
-	// First load application.yml if it exists
-	defaultConfigPath := filepath.Join(configPath, "application.yml")
+	// First load application<ext> if it exists
+	config := make(map[string]interface{})
+	defaultConfigPath := filepath.Join(configPath, "application"+f.ext)
 	if _, err := os.Stat(defaultConfigPath); !os.IsNotExist(err) {
 		logger.Info("Loading default configuration", "path", defaultConfigPath)
-		if err := loadYamlConfig(defaultConfigPath, builder); err != nil {
+		var err error
+		if config, err = f.read(defaultConfigPath); err != nil {
 			return fmt.Errorf("error loading default config: %w", err)
 		}
 	}
 
+	// Its goboot section may define the default profiles, profile groups and merge strategies
+	if section, ok := config["goboot"]; ok {
+		if err := (MapVariableLoader{Variables: map[string]interface{}{"goboot": section}}).Load(builder); err != nil {
+			return err
+		}
+	}
+	strategies := mergeStrategiesFrom(config)
+	for path, strategy := range f.strategies {
+		strategies[path] = strategy
+	}
+
+	builder.ActivateProfiles(f.profiles...)
+	profiles := builder.GetActiveProfiles()
+	if len(profiles) > 0 {
+		logger.Info("Using active profiles", "profiles", profiles)
+	}
+
 	// Then load each profile-specific file
 	for _, profile := range profiles {
-		profileConfigPath := filepath.Join(configPath, fmt.Sprintf("application-%s.yml", profile))
+		profileConfigPath := filepath.Join(configPath, fmt.Sprintf("application-%s%s", profile, f.ext))
 		if _, err := os.Stat(profileConfigPath); !os.IsNotExist(err) {
 			logger.Info("Loading profile configuration", "profile", profile, "path", profileConfigPath)
-			if err := loadYamlConfig(profileConfigPath, builder); err != nil {
+			profileConfig, err := f.read(profileConfigPath)
+			if err != nil {
 				return fmt.Errorf("error loading profile config %s: %w", profile, err)
 			}
+			config = mergeConfig(config, profileConfig, "", strategies)
 		} else {
 			logger.Info("Profile configuration not found, skipping", "profile", profile, "path", profileConfigPath)
 		}
 	}
 
-	return nil
+	// Register the merged configuration, so replaced lists and maps leave no stale keys
+	return MapVariableLoader{Variables: config}.Load(builder)
+}
+
+// VariableSource is implemented by contexts that can list all their variables
+type VariableSource interface {
+	// GetVariables returns a copy of all variables
+	GetVariables() map[string]interface{}
 }
 
 // VariableHelper provides utility functions for working with variables
@@ -164,11 +208,15 @@ func (h *VariableHelper) GetString(name string, defaultValue string) string {
 	return value
 }
 
-// GetStruct unmarshals a variable or a section of the configuration into a struct
+// GetStruct unmarshals a variable or a section of the configuration into a struct.
+// Fields are converted with the context's converters (see ConverterRegistry).
 func (h *VariableHelper) GetStruct(name string, target interface{}) error {
+	if err := h.expressionError(name); err != nil {
+		return err
+	}
+
 	// Build a map of matching variables with the given prefix
 	prefix := name + "."
-	prefixLen := len(prefix)
 
 	// Collect all variables with the given prefix
 	matchingVars := make(map[string]interface{})
@@ -192,56 +240,69 @@ func (h *VariableHelper) GetStruct(name string, target interface{}) error {
 
 	// If we didn't find a root object, try to build one from nested properties
 	if len(matchingVars) == 0 {
-		// Get all available variables to check for matching prefix
-		allVars := h.collectAllVariables()
-
-		// Check each variable to see if it starts with our prefix
-		for k, v := range allVars {
-			if strings.HasPrefix(k, prefix) {
-				// Extract the part after the prefix
-				key := k[prefixLen:]
-				// Store the variable with the prefix removed
-				matchingVars[key] = v
-			}
-		}
+		// Rebuild nested sections like "pool.max" so they bind to nested structs and maps
+		matchingVars = unflattenMap(h.variablesWithPrefix(prefix))
 	}
 
 	if len(matchingVars) == 0 {
 		return fmt.Errorf("variable %s not found", name)
 	}
 
-	// Convert to YAML and unmarshal
-	data, err := yaml.Marshal(matchingVars)
-	if err != nil {
-		return err
+	return h.converters().convert(matchingVars, target, name)
+}
+
+// HasSection checks whether a variable exists at name or at any key below it
+// (e.g. "server" matches "server.port")
+func (h *VariableHelper) HasSection(name string) bool {
+	if h.ctx.HasVariable(name) {
+		return true
 	}
 
-	return yaml.Unmarshal(data, target)
+	return len(h.variablesWithPrefix(name+".")) > 0
 }
 
-// collectAllVariables gets all variables from the container
-// This is a helper method to make GetStruct more robust
+// expressionErrorSource is implemented by contexts reporting the variable
+// expressions that fail to resolve, which read as empty strings
+type expressionErrorSource interface {
+	variableExpressionError(name string) error
+}
+
+// expressionError returns the error of a failing expression in the variable or
+// in its section, nil if the context doesn't report them
+func (h *VariableHelper) expressionError(name string) error {
+	if source, ok := h.ctx.(expressionErrorSource); ok {
+		return source.variableExpressionError(name)
+	}
+	return nil
+}
+
+// prefixVariableSource is implemented by contexts that can look up the variables
+// below a prefix without copying all of them
+type prefixVariableSource interface {
+	variablesWithPrefix(prefix string) map[string]interface{}
+}
+
+// variablesWithPrefix returns the variables whose names start with prefix, with the
+// prefix removed
+func (h *VariableHelper) variablesWithPrefix(prefix string) map[string]interface{} {
+	if source, ok := h.ctx.(prefixVariableSource); ok {
+		return source.variablesWithPrefix(prefix)
+	}
+
+	matching := make(map[string]interface{})
+	for key, value := range h.collectAllVariables() {
+		if strings.HasPrefix(key, prefix) {
+			matching[key[len(prefix):]] = value
+		}
+	}
+	return matching
+}
+
+// collectAllVariables gets all variables from the context if it exposes them
 func (h *VariableHelper) collectAllVariables() map[string]interface{} {
-	// We access the container directly here, which is not ideal
-	// but we need a way to get all variables
-
-	// This is a best-effort implementation that might not always work
-	// because we don't have a built-in way to get all variables
-
-	// Try to use container-specific knowledge to extract vars
-	container, ok := h.ctx.(*container)
-	if ok && container != nil && container.variableRegistry != nil {
-		registry, ok := container.variableRegistry.(*defaultVariableRegistry)
-		if ok && registry != nil {
-			// Make a copy of the variables to avoid concurrent access issues
-			registry.mu.RLock()
-			defer registry.mu.RUnlock()
-
-			result := make(map[string]interface{}, len(registry.variables))
-			for k, v := range registry.variables {
-				result[k] = v
-			}
-			return result
+	if source, ok := h.ctx.(VariableSource); ok {
+		if vars := source.GetVariables(); vars != nil {
+			return vars
 		}
 	}
 
@@ -249,28 +310,66 @@ func (h *VariableHelper) collectAllVariables() map[string]interface{} {
 	return make(map[string]interface{})
 }
 
-// loadYamlConfig loads a YAML file and registers all variables in the container
-func loadYamlConfig(filePath string, builder ContextBuilder) error {
-	// Read file
+// Bind converts a variable into target, which must be a pointer.
+// Scalar values are converted (e.g. "8080" into an int, "5s" into a time.Duration);
+// maps and sections of dot-separated variables are bound like GetStruct.
+func (h *VariableHelper) Bind(name string, target interface{}) error {
+	if target == nil || reflect.TypeOf(target).Kind() != reflect.Ptr {
+		return ErrorWithCode("TARGET_NOT_POINTER", "target must be a pointer")
+	}
+
+	value := h.ctx.GetVariableRaw(name)
+	switch value.(type) {
+	case nil, map[string]interface{}, map[interface{}]interface{}:
+		return h.GetStruct(name, target)
+	}
+	if err := h.expressionError(name); err != nil {
+		return err
+	}
+
+	// Converters and text unmarshalers run first; otherwise YAML does the conversion
+	// and strings (e.g. from the environment) are parsed as YAML scalars so "8080"
+	// converts to an int
+	return h.converters().decode(value, reflect.ValueOf(target).Elem(), name)
+}
+
+// converters returns the context's converter registry, or DefaultConverters
+func (h *VariableHelper) converters() *ConverterRegistry {
+	if source, ok := h.ctx.(ConverterSource); ok {
+		if converters := source.GetConverters(); converters != nil {
+			return converters
+		}
+	}
+	return DefaultConverters
+}
+
+// readYamlConfig reads a YAML file into a nested map
+func readYamlConfig(filePath string) (map[string]interface{}, error) {
 	data, err := os.ReadFile(filePath)
 	if err != nil {
-		return err
+		return nil, err
 	}
 
-	// Parse YAML into a map
-	var config map[string]interface{}
+	config := make(map[string]interface{})
 	if err := yaml.Unmarshal(data, &config); err != nil {
-		return err
+		return nil, err
 	}
+	return config, nil
+}
 
-	// Register all variables with flattened keys
-	flattenedMap := make(map[string]interface{})
-	flattenMap(config, "", flattenedMap)
+// MapVariableLoader loads variables from an in-memory map.
+// Nested maps are flattened into dot-separated keys like YAML files.
+type MapVariableLoader struct {
+	// Variables to register
+	Variables map[string]interface{}
+}
 
-	for key, value := range flattenedMap {
-		builder.RegisterVariable(key, value)
-	}
+// Load registers all variables from the map
+func (l MapVariableLoader) Load(builder ContextBuilder) error {
+	flattenedMap := make(map[string]interface{}, len(l.Variables))
+	flattenMap(l.Variables, "", flattenedMap)
 
+	builder.RegisterVariables(flattenedMap)
 	return nil
 }
 
@@ -278,29 +377,55 @@ func loadYamlConfig(filePath string, builder ContextBuilder) error {
 // e.g. {"server": {"port": 8080}} becomes {"server.port": 8080}
 func flattenMap(input map[string]interface{}, prefix string, output map[string]interface{}) {
 	for k, v := range input {
-		key := k
-		if prefix != "" {
-			key = prefix + "." + k
+		flattenValue(joinKey(prefix, k), v, output)
+	}
+}
+
+// flattenValue adds a value to output, flattening nested maps below key
+func flattenValue(key string, v interface{}, output map[string]interface{}) {
+	switch value := v.(type) {
+	case map[string]interface{}:
+		// Recursively flatten nested maps
+		flattenMap(value, key, output)
+	case map[interface{}]interface{}:
+		// Flatten the string keys in place rather than copying the map first
+		for mk, mv := range value {
+			if strKey, ok := mk.(string); ok {
+				flattenValue(joinKey(key, strKey), mv, output)
+			}
 		}
+	default:
+		// For non-map values, add them directly
+		output[key] = v
+	}
+}
 
-		switch value := v.(type) {
-		case map[string]interface{}:
-			// Recursively flatten nested maps
-			flattenMap(value, key, output)
-		case map[interface{}]interface{}:
-			// Convert to string keys and recursively flatten
-			stringMap := make(map[string]interface{})
-			for mk, mv := range value {
-				if strKey, ok := mk.(string); ok {
-					stringMap[strKey] = mv
-				}
+// joinKey appends a key to a dot-separated prefix
+func joinKey(prefix, key string) string {
+	if prefix == "" {
+		return key
+	}
+	return prefix + "." + key
+}
+
+// unflattenMap is the inverse of flattenMap
+// e.g. {"server.port": 8080} becomes {"server": {"port": 8080}}
+func unflattenMap(input map[string]interface{}) map[string]interface{} {
+	result := make(map[string]interface{})
+	for key, value := range input {
+		parts := strings.Split(key, ".")
+		current := result
+		for _, part := range parts[:len(parts)-1] {
+			next, ok := current[part].(map[string]interface{})
+			if !ok {
+				next = make(map[string]interface{})
+				current[part] = next
 			}
-			flattenMap(stringMap, key, output)
-		default:
-			// For non-map values, add them directly
-			output[key] = v
+			current = next
 		}
+		current[parts[len(parts)-1]] = value
 	}
+	return result
 }
 
 // SimpleYamlLoader implements a basic YAML file variable loader
@@ -375,45 +500,176 @@ func (l EnvVariableLoader) Load(builder ContextBuilder) error {
 	return nil
 }
 
-// PropertiesVariableLoader loads variables from .properties files
+// PropertiesVariableLoader loads variables from a UTF-8 .properties file, then
+// from its <name>-<profile>.properties variant for each active profile, e.g.
+// application-dev.properties next to application.properties. Later files override
+// earlier ones. The file follows java.util.Properties: keys end at the first
+// unescaped '=', ':' or whitespace, lines ending with a backslash continue on the
+// next line, \uXXXX and the usual backslash escapes are decoded, and lines
+// starting with '#' or '!' are comments. A '#' preceded by whitespace also starts
+// a comment after a value; escape it as \# to keep it.
 type PropertiesVariableLoader struct {
 	// Path to the properties file
 	Path string
+	// Optional list of profile names to activate in addition to the active profiles
+	Profiles []string
 }
 
-// Load loads variables from .properties file
+// Load loads variables from the .properties file and its profile variants
 func (l PropertiesVariableLoader) Load(builder ContextBuilder) error {
+	if err := loadPropertiesFile(builder, l.Path); err != nil {
+		return err
+	}
+
+	builder.ActivateProfiles(l.Profiles...)
+	ext := filepath.Ext(l.Path)
+	base := strings.TrimSuffix(l.Path, ext)
+	for _, profile := range builder.GetActiveProfiles() {
+		if err := loadPropertiesFile(builder, fmt.Sprintf("%s-%s%s", base, profile, ext)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// loadPropertiesFile registers the variables of a .properties file if it exists
+func loadPropertiesFile(builder ContextBuilder, path string) error {
 	// Skip if file doesn't exist
-	if _, err := os.Stat(l.Path); os.IsNotExist(err) {
-		slog.Info("Properties file not found, skipping", "path", l.Path)
+	if _, err := os.Stat(path); os.IsNotExist(err) {
+		slog.Info("Properties file not found, skipping", "path", path)
 		return nil
 	}
 
-	// Read file
-	data, err := os.ReadFile(l.Path)
+	data, err := os.ReadFile(path)
 	if err != nil {
 		return err
 	}
+	variables, err := parseProperties(string(data))
+	if err != nil {
+		return fmt.Errorf("error loading properties file %s: %w", path, err)
+	}
+
+	slog.Info("Loading properties", "path", path)
+	builder.RegisterVariables(variables)
+	return nil
+}
+
+// parseProperties parses a .properties document
+func parseProperties(src string) (map[string]interface{}, error) {
+	src = strings.TrimPrefix(src, "\uFEFF")
+	lines := strings.Split(strings.ReplaceAll(src, "\r\n", "\n"), "\n")
 
-	// Parse properties
-	lines := strings.Split(string(data), "\n")
-	for _, line := range lines {
-		// Skip comments and empty lines
-		line = strings.TrimSpace(line)
-		if line == "" || strings.HasPrefix(line, "#") {
+	variables := make(map[string]interface{})
+	for i := 0; i < len(lines); i++ {
+		number := i + 1
+		line := strings.TrimLeft(lines[i], " \t\f")
+		if line == "" || line[0] == '#' || line[0] == '!' {
 			continue
 		}
 
-		// Split on first equals sign
-		parts := strings.SplitN(line, "=", 2)
-		if len(parts) == 2 {
-			key := strings.TrimSpace(parts[0])
-			value := strings.TrimSpace(parts[1])
+		// Join continuation lines, dropping their leading whitespace
+		for endsWithContinuation(line) && i+1 < len(lines) {
+			i++
+			line = line[:len(line)-1] + strings.TrimLeft(lines[i], " \t\f")
+		}
+		if endsWithContinuation(line) {
+			// A continuation on the last line continues with nothing
+			line = line[:len(line)-1]
+		}
 
-			// Register variable
-			builder.RegisterVariable(key, value)
+		key, value, err := splitProperty(line)
+		if err != nil {
+			return nil, fmt.Errorf("line %d: %w", number, err)
 		}
+		variables[key] = value
 	}
+	return variables, nil
+}
 
-	return nil
+// endsWithContinuation reports whether a line ends with an odd number of backslashes
+func endsWithContinuation(line string) bool {
+	n := 0
+	for i := len(line) - 1; i >= 0 && line[i] == '\\'; i-- {
+		n++
+	}
+	return n%2 == 1
+}
+
+// splitProperty splits a logical line into its unescaped key and value
+func splitProperty(line string) (string, string, error) {
+	// The key ends at the first unescaped separator or whitespace
+	end := 0
+	for end < len(line) && !strings.ContainsRune("=: \t\f", rune(line[end])) {
+		if line[end] == '\\' {
+			end++
+		}
+		end++
+	}
+	end = min(end, len(line))
+	key, err := unescapeProperty(line[:end], false)
+	if err != nil {
+		return "", "", err
+	}
+
+	// Then whitespace, at most one '=' or ':' and whitespace again
+	rest := strings.TrimLeft(line[end:], " \t\f")
+	if rest != "" && (rest[0] == '=' || rest[0] == ':') {
+		rest = strings.TrimLeft(rest[1:], " \t\f")
+	}
+	value, err := unescapeProperty(rest, true)
+	if err != nil {
+		return "", "", err
+	}
+	return key, value, nil
+}
+
+// unescapeProperty decodes the escapes of a key or value. In a value, an unescaped
+// '#' preceded by whitespace starts a comment.
+func unescapeProperty(s string, value bool) (string, error) {
+	var b strings.Builder
+	for i := 0; i < len(s); i++ {
+		c := s[i]
+		if value && c == '#' && i > 0 && (s[i-1] == ' ' || s[i-1] == '\t') {
+			return strings.TrimRight(b.String(), " \t\f"), nil
+		}
+		if c != '\\' || i+1 == len(s) {
+			b.WriteByte(c)
+			continue
+		}
+
+		i++
+		switch s[i] {
+		case 't':
+			b.WriteByte('\t')
+		case 'n':
+			b.WriteByte('\n')
+		case 'r':
+			b.WriteByte('\r')
+		case 'f':
+			b.WriteByte('\f')
+		case 'u':
+			if i+5 > len(s) {
+				return "", fmt.Errorf("invalid unicode escape \\u%s", s[i+1:])
+			}
+			code, err := strconv.ParseUint(s[i+1:i+5], 16, 16)
+			if err != nil {
+				return "", fmt.Errorf("invalid unicode escape \\u%s", s[i+1:i+5])
+			}
+			i += 4
+			// Surrogate pairs encode characters outside the Basic Multilingual Plane
+			r := rune(code)
+			if utf16.IsSurrogate(r) && i+6 < len(s) && s[i+1] == '\\' && s[i+2] == 'u' {
+				if low, err := strconv.ParseUint(s[i+3:i+7], 16, 16); err == nil {
+					if decoded := utf16.DecodeRune(r, rune(low)); decoded != unicode.ReplacementChar {
+						r = decoded
+						i += 6
+					}
+				}
+			}
+			b.WriteRune(r)
+		default:
+			b.WriteByte(s[i])
+		}
+	}
+	return b.String(), nil
 }
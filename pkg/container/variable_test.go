@@ -0,0 +1,191 @@
+package container
+
+import (
+	"context"
+	"os"
+	"path/filepath"
+	"reflect"
+	"strings"
+	"testing"
+)
+
+func TestParseProperties(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		want map[string]interface{}
+	}{
+		{
+			name: "separators",
+			src:  "a=1\nb = 2\nc: 3\nd 4\ne\t=\t5\nf\n",
+			want: map[string]interface{}{"a": "1", "b": "2", "c": "3", "d": "4", "e": "5", "f": ""},
+		},
+		{
+			name: "separator in value",
+			src:  "url = jdbc:postgres://db?a=b\n",
+			want: map[string]interface{}{"url": "jdbc:postgres://db?a=b"},
+		},
+		{
+			name: "key ends at whitespace",
+			src:  "my key=v\n",
+			want: map[string]interface{}{"my": "key=v"},
+		},
+		{
+			name: "escaped key characters",
+			src:  "my\\ key=v\na\\=b\\:c = d\n",
+			want: map[string]interface{}{"my key": "v", "a=b:c": "d"},
+		},
+		{
+			name: "comments",
+			src:  "# hash\n! bang\n   # indented\nname = orders\n",
+			want: map[string]interface{}{"name": "orders"},
+		},
+		{
+			name: "byte order mark and CRLF",
+			src:  "\uFEFFname = orders\r\nport = 8080\r\n",
+			want: map[string]interface{}{"name": "orders", "port": "8080"},
+		},
+		{
+			name: "continuation",
+			src:  "hosts = db1, \\\n        db2, \\\n  db3\nnext = x\n",
+			want: map[string]interface{}{"hosts": "db1, db2, db3", "next": "x"},
+		},
+		{
+			name: "odd trailing backslashes continue",
+			src:  "path = x\\\\\\\n  y\n",
+			want: map[string]interface{}{"path": "x\\y"},
+		},
+		{
+			name: "even trailing backslashes end the line",
+			src:  "path = x\\\\\nnext = y\n",
+			want: map[string]interface{}{"path": "x\\", "next": "y"},
+		},
+		{
+			name: "continuation on the last line",
+			src:  "path = x\\",
+			want: map[string]interface{}{"path": "x"},
+		},
+		{
+			name: "escapes",
+			src:  "text = a\\tb\\nc\\\\d\\qe\npath = C:\\\\dir\n",
+			want: map[string]interface{}{"text": "a\tb\nc\\dqe", "path": "C:\\dir"},
+		},
+		{
+			name: "inline comment",
+			src:  "password=abc #1\ncolor = red\t# primary\n",
+			want: map[string]interface{}{"password": "abc", "color": "red"},
+		},
+		{
+			name: "hash without whitespace",
+			src:  "password=abc#1\n",
+			want: map[string]interface{}{"password": "abc#1"},
+		},
+		{
+			name: "escaped hash",
+			src:  "color = \\#fff\npassword = abc \\#1\n",
+			want: map[string]interface{}{"color": "#fff", "password": "abc #1"},
+		},
+		{
+			name: "unicode escapes",
+			src:  "greeting = caf\\u00e9\nnative = naïve\n",
+			want: map[string]interface{}{"greeting": "café", "native": "naïve"},
+		},
+		{
+			name: "surrogate pair",
+			src:  "emoji = \\uD83D\\uDE00!\n",
+			want: map[string]interface{}{"emoji": "\U0001F600!"},
+		},
+		{
+			name: "unpaired surrogate",
+			src:  "emoji = \\uD83Dx\n",
+			want: map[string]interface{}{"emoji": "\uFFFDx"},
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := parseProperties(tt.src)
+			if err != nil {
+				t.Fatalf("parseProperties() error = %v", err)
+			}
+			if !reflect.DeepEqual(got, tt.want) {
+				t.Errorf("parseProperties() = %#v, want %#v", got, tt.want)
+			}
+		})
+	}
+}
+
+func TestParsePropertiesErrors(t *testing.T) {
+	tests := []struct {
+		name string
+		src  string
+		// want is contained in the error message
+		want string
+	}{
+		{
+			name: "short unicode escape",
+			src:  "a = 1\nb = \\u12\n",
+			want: `line 2: invalid unicode escape \u12`,
+		},
+		{
+			name: "invalid unicode escape",
+			src:  "a = \\uZZZZ\n",
+			want: `line 1: invalid unicode escape \uZZZZ`,
+		},
+		{
+			name: "line of a continued entry",
+			src:  "a = 1 \\\n  2\nb = \\u00\n",
+			want: "line 3: invalid unicode escape",
+		},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.name, func(t *testing.T) {
+			_, err := parseProperties(tt.src)
+			if err == nil {
+				t.Fatalf("parseProperties() error = nil, want %q", tt.want)
+			}
+			if !strings.Contains(err.Error(), tt.want) {
+				t.Errorf("parseProperties() error = %q, want %q", err, tt.want)
+			}
+		})
+	}
+}
+
+func TestPropertiesVariableLoaderProfiles(t *testing.T) {
+	dir := t.TempDir()
+	files := map[string]string{
+		"application.properties":      "name = orders\nserver.port = 8080\ndb.url = postgres://db/app\n",
+		"application-dev.properties":  "server.port = 9090\n",
+		"application-test.properties": "server.port = 7070\ndb.url = postgres://test/app\n",
+	}
+	for name, content := range files {
+		if err := os.WriteFile(filepath.Join(dir, name), []byte(content), 0o644); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	cfg := DefaultConfig()
+	cfg.DefaultVariableLoaders = nil
+	app, stop, err := New(context.Background(), cfg, func(builder ContextBuilder) {
+		builder.AddVariableLoader(PropertiesVariableLoader{
+			Path:     filepath.Join(dir, "application.properties"),
+			Profiles: []string{"dev"},
+		})
+	})
+	if err != nil {
+		t.Fatalf("New() error = %v", err)
+	}
+	defer stop()
+
+	want := map[string]string{
+		"name":        "orders",
+		"server.port": "9090",
+		"db.url":      "postgres://db/app",
+	}
+	for name, value := range want {
+		if got := app.GetVariable(name); got != value {
+			t.Errorf("GetVariable(%q) = %q, want %q", name, got, value)
+		}
+	}
+}
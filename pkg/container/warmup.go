@@ -0,0 +1,206 @@
+package container
+
+import (
+	"context"
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// PropertyEagerChecks configures the eager resource checks run once the components
+// have started, before the container is returned as ready:
+//
+//	goboot:
+//	  startup:
+//	    eager-checks:
+//	      enabled: true
+//	      timeout: 10s
+//	      components:
+//	        dataSource:
+//	          timeout: 30s
+//	        kafkaSender:
+//	          enabled: false
+const PropertyEagerChecks = "goboot.startup.eager-checks"
+
+// DefaultEagerCheckTimeout bounds a check when no timeout is configured
+const DefaultEagerCheckTimeout = 10 * time.Second
+
+// WarmUpComponent is a component using an external resource (database, Redis,
+// broker) that verifies it is reachable and warms its connection pool when the
+// eager checks run. WarmUp returns an error if the resource is unavailable.
+type WarmUpComponent interface {
+	Component
+	WarmUp(ctx context.Context) error
+}
+
+// EagerChecksConfig configures the eager checks: goboot.startup.eager-checks.*
+type EagerChecksConfig struct {
+	Enabled bool `yaml:"enabled"`
+	// Timeout bounds each check (DefaultEagerCheckTimeout if zero)
+	Timeout time.Duration `yaml:"timeout"`
+	// FailFast makes a failed check fail the startup (default true); otherwise it is
+	// logged and the application starts anyway
+	FailFast *bool `yaml:"fail-fast"`
+	// Components configure the checks of single components by name. A listed
+	// HealthIndicator that isn't a WarmUpComponent is checked by its health.
+	Components map[string]EagerCheckConfig `yaml:"components"`
+}
+
+// EagerCheckConfig configures the check of a component
+type EagerCheckConfig struct {
+	// Enabled skips the component when false
+	Enabled *bool `yaml:"enabled"`
+	// Timeout overrides the default timeout
+	Timeout time.Duration `yaml:"timeout"`
+}
+
+// failFast reports whether a failed check fails the startup
+func (c EagerChecksConfig) failFast() bool {
+	return c.FailFast == nil || *c.FailFast
+}
+
+// timeout returns the timeout of the check of a component
+func (c EagerChecksConfig) timeout(name string) time.Duration {
+	if timeout := c.Components[name].Timeout; timeout > 0 {
+		return timeout
+	}
+	if c.Timeout > 0 {
+		return c.Timeout
+	}
+	return DefaultEagerCheckTimeout
+}
+
+// eagerCheck is the check of one component
+type eagerCheck struct {
+	name    string
+	timeout time.Duration
+	run     func(ctx context.Context) error
+}
+
+// eagerChecks returns the checks of the started components, sorted by name
+func (c *container) eagerChecks(config EagerChecksConfig) ([]eagerCheck, error) {
+	var checks []eagerCheck
+	for _, name := range c.componentInit.GetInitOrder() {
+		componentConfig, listed := config.Components[name]
+		if componentConfig.Enabled != nil && !*componentConfig.Enabled {
+			continue
+		}
+		component, err := c.componentRegistry.Get(name)
+		if err != nil {
+			return nil, err
+		}
+
+		check := eagerCheck{name: name, timeout: config.timeout(name)}
+		if warmUp, ok := component.(WarmUpComponent); ok {
+			check.run = warmUp.WarmUp
+		} else if indicator, ok := component.(HealthIndicator); ok && listed {
+			check.run = func(ctx context.Context) error {
+				health := CheckHealth(ctx, c, name, indicator)
+				if health.Status == HealthDown {
+					return fmt.Errorf("health is %s: %v", health.Status, health.Details)
+				}
+				return nil
+			}
+		} else {
+			continue
+		}
+		checks = append(checks, check)
+	}
+
+	sort.Slice(checks, func(i, j int) bool {
+		return checks[i].name < checks[j].name
+	})
+	return checks, nil
+}
+
+// runEagerChecks runs the eager checks concurrently, each within its timeout, when
+// goboot.startup.eager-checks.enabled is true. With fail-fast, it returns an error
+// listing every failed check.
+func (c *container) runEagerChecks(ctx context.Context) error {
+	if !NewVariableHelper(c).GetBool(PropertyEagerChecks+".enabled", false) {
+		return nil
+	}
+	var config EagerChecksConfig
+	if err := c.GetVariableAs(PropertyEagerChecks, &config); err != nil {
+		return ConfigurationError("invalid "+PropertyEagerChecks+" configuration", err)
+	}
+	for name := range config.Components {
+		if !c.componentRegistry.Has(name) {
+			c.logger.Warn("Eager check configured for an unknown component", "component", name)
+		}
+	}
+
+	checks, err := c.eagerChecks(config)
+	if err != nil {
+		return err
+	}
+	c.logger.Info("Running eager checks", "components", len(checks))
+
+	errs := make([]error, len(checks))
+	var wg sync.WaitGroup
+	for i, check := range checks {
+		wg.Add(1)
+		go func(i int, check eagerCheck) {
+			defer wg.Done()
+			errs[i] = c.runEagerCheck(ctx, check)
+		}(i, check)
+	}
+	wg.Wait()
+
+	var failed, reasons []string
+	for i, err := range errs {
+		if err == nil {
+			continue
+		}
+		failed = append(failed, checks[i].name)
+		reasons = append(reasons, checks[i].name+": "+err.Error())
+		c.logger.Error("Eager check failed", "component", checks[i].name, "error", err)
+	}
+	if len(failed) == 0 {
+		return nil
+	}
+	if !config.failFast() {
+		c.logger.Warn("Starting despite failed eager checks", "components", failed)
+		return nil
+	}
+	return ErrorWithCode("EAGER_CHECK_FAILED", "eager checks failed: %s", strings.Join(reasons, "; "))
+}
+
+// runEagerCheck runs a check within its timeout, reporting it as the warm-up phase
+// of the component
+func (c *container) runEagerCheck(ctx context.Context, check eagerCheck) (err error) {
+	ctx, cancel := context.WithTimeout(ctx, check.timeout)
+	defer cancel()
+
+	c.states.begin(check.name, PhaseWarmUp)
+	start := time.Now()
+	defer func() {
+		if err != nil && ctx.Err() == context.DeadlineExceeded {
+			err = fmt.Errorf("timed out after %s: %w", check.timeout, err)
+		}
+		c.states.end(check.name, PhaseWarmUp, time.Since(start), err)
+	}()
+
+	// A check ignoring its context doesn't hold up the startup past the timeout
+	result := make(chan error, 1)
+	go func() {
+		defer func() {
+			if r := recover(); r != nil {
+				result <- fmt.Errorf("panic in eager check: %v", r)
+			}
+		}()
+		result <- check.run(ctx)
+	}()
+	select {
+	case err = <-result:
+	case <-ctx.Done():
+		err = ctx.Err()
+	}
+	if err != nil {
+		return err
+	}
+	c.logger.Debug("Eager check passed", "component", check.name, "duration_ms", time.Since(start).Milliseconds())
+	return nil
+}
@@ -0,0 +1,368 @@
+package container
+
+import (
+	"bytes"
+	"fmt"
+	htmltemplate "html/template"
+	"sort"
+	"strings"
+	"text/template"
+)
+
+// ComponentDoc documents a component or starter in the generated wiring document
+type ComponentDoc struct {
+	// Description says what the component does
+	Description string
+	// Owner is the team owning the component
+	Owner string
+	// ConfigPrefix is the configuration section the component reads, e.g. "sentry"
+	ConfigPrefix string
+}
+
+// Documented is implemented by components and starters documenting themselves
+type Documented interface {
+	Documentation() ComponentDoc
+}
+
+// documenter is implemented by contexts storing the documentation of components
+type documenter interface {
+	describeComponent(name string, doc ComponentDoc)
+}
+
+// DescribeComponent documents a registered component that doesn't implement
+// Documented, e.g. an instance or a component of a third-party starter. Contexts
+// not generating wiring documents, like containermock.Context, ignore it.
+func DescribeComponent(builder ContextBuilder, name string, doc ComponentDoc) error {
+	if !builder.HasComponent(name) {
+		return ComponentNotFoundError(name)
+	}
+	if d, ok := builder.(documenter); ok {
+		d.describeComponent(name, doc)
+	}
+	return nil
+}
+
+func (c *container) describeComponent(name string, doc ComponentDoc) {
+	if c.docs == nil {
+		c.docs = make(map[string]ComponentDoc)
+	}
+	c.docs[name] = doc
+}
+
+// WiringFormat is the format of a generated wiring document
+type WiringFormat string
+
+// Wiring document formats
+const (
+	WiringMarkdown WiringFormat = "markdown"
+	WiringHTML     WiringFormat = "html"
+)
+
+// Wiring describes the components of a started container, their dependencies and
+// the configuration keys they read
+type Wiring struct {
+	Components []WiredComponent
+	Starters   []WiredStarter
+	// ConfigKeys are the configuration keys read by the components, sorted
+	ConfigKeys []WiredConfigKey
+	// Variables are the variables declared with DeclareVariable
+	Variables []VariableDeclaration
+}
+
+// WiredComponent is a component of the wiring document
+type WiredComponent struct {
+	Name string
+	ComponentDoc
+	Type       string
+	Module     string
+	Tags       []string
+	Interfaces []ComponentInterface
+	// RegisteredBy is the starter or factory that registered the component, empty
+	// for the setup block
+	RegisteredBy string
+	Dependencies []string
+	Dependents   []string
+	// ConfigKeys are the variables the component read in Init, and its ConfigPrefix
+	// and configuration properties section as prefix.*
+	ConfigKeys []string
+}
+
+// WiredStarter is a starter of the wiring document
+type WiredStarter struct {
+	Name string
+	ComponentDoc
+	// Components are the components the starter registered, none if it didn't apply
+	Components []string
+}
+
+// WiredConfigKey is a configuration key and the components reading it
+type WiredConfigKey struct {
+	Key        string
+	Components []string
+}
+
+// DescribeWiring describes the wiring of a container created by New
+func DescribeWiring(app ApplicationContext) (*Wiring, error) {
+	c, ok := app.(*container)
+	if !ok || c.dependencyResolver == nil {
+		return nil, ErrorWithCode("UNSUPPORTED_CONTEXT", "%T is not a container created by New", app)
+	}
+	resolver, _ := c.dependencyResolver.(*defaultDependencyResolver)
+
+	wiring := &Wiring{}
+	registered := make(map[string][]string)
+	readers := make(map[string][]string)
+	reports := make(map[string]ComponentReport)
+	for _, report := range c.GetComponentReport() {
+		reports[report.Name] = report
+	}
+
+	for _, info := range c.FindComponents(nil) {
+		component := WiredComponent{
+			Name:         info.Name,
+			ComponentDoc: c.documentation(info),
+			Type:         reports[info.Name].Type,
+			Tags:         info.Tags,
+			Interfaces:   info.Interfaces,
+			RegisteredBy: c.registeredBy[info.Name],
+			Dependencies: reports[info.Name].Dependencies,
+			Dependents:   reports[info.Name].Dependents,
+		}
+		component.Module, _ = c.componentRegistry.GetModule(info.Name)
+
+		keys := make(map[string]bool)
+		if resolver != nil {
+			for _, key := range resolver.variables[info.Name] {
+				keys[key] = true
+			}
+		}
+		if component.ConfigPrefix != "" {
+			keys[component.ConfigPrefix+".*"] = true
+		}
+		if props, ok := info.Component.(*ConfigProperties); ok {
+			keys[props.prefix+".*"] = true
+		}
+		component.ConfigKeys = sortedKeys(keys)
+		for _, key := range component.ConfigKeys {
+			readers[key] = append(readers[key], info.Name)
+		}
+
+		if component.RegisteredBy != "" {
+			registered[component.RegisteredBy] = append(registered[component.RegisteredBy], info.Name)
+		}
+		wiring.Components = append(wiring.Components, component)
+	}
+
+	for _, starter := range c.starters {
+		wired := WiredStarter{Name: starter.Name(), Components: registered["starter "+starter.Name()]}
+		if documented, ok := starter.(Documented); ok {
+			wired.ComponentDoc = documented.Documentation()
+		}
+		wiring.Starters = append(wiring.Starters, wired)
+	}
+
+	keys := make([]string, 0, len(readers))
+	for key := range readers {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		wiring.ConfigKeys = append(wiring.ConfigKeys, WiredConfigKey{Key: key, Components: readers[key]})
+	}
+	wiring.Variables = c.GetVariableDeclarations()
+	return wiring, nil
+}
+
+// documentation returns the documentation of a component: DescribeComponent
+// overrides the component's own
+func (c *container) documentation(info ComponentInfo) ComponentDoc {
+	if doc, ok := c.docs[info.Name]; ok {
+		return doc
+	}
+	if documented, ok := info.Component.(Documented); ok {
+		return documented.Documentation()
+	}
+	if documented, ok := info.Value.(Documented); ok {
+		return documented.Documentation()
+	}
+	return ComponentDoc{}
+}
+
+// GenerateWiringDoc renders the wiring of a container created by New as a
+// Markdown or HTML document, e.g. to keep architecture docs current from CI:
+//
+//	doc, err := container.GenerateWiringDoc(app, container.WiringMarkdown)
+//	os.WriteFile("docs/wiring.md", doc, 0o644)
+func GenerateWiringDoc(app ApplicationContext, format WiringFormat) ([]byte, error) {
+	wiring, err := DescribeWiring(app)
+	if err != nil {
+		return nil, err
+	}
+
+	var out bytes.Buffer
+	switch format {
+	case WiringMarkdown, "":
+		err = markdownWiring.Execute(&out, wiring)
+	case WiringHTML:
+		err = htmlWiring.Execute(&out, wiring)
+	default:
+		return nil, ErrorWithCode("UNSUPPORTED_FORMAT", "unsupported wiring document format %q", format)
+	}
+	if err != nil {
+		return nil, err
+	}
+	return out.Bytes(), nil
+}
+
+// sortedKeys returns the keys of a set in order
+func sortedKeys(set map[string]bool) []string {
+	keys := make([]string, 0, len(set))
+	for key := range set {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+var wiringFuncs = map[string]interface{}{
+	"codes": func(values []string) string {
+		if len(values) == 0 {
+			return "-"
+		}
+		return "`" + strings.Join(values, "`, `") + "`"
+	},
+	"join": func(values interface{}) string {
+		var parts []string
+		switch v := values.(type) {
+		case []string:
+			parts = v
+		case []ComponentInterface:
+			for _, i := range v {
+				parts = append(parts, string(i))
+			}
+		}
+		return strings.Join(parts, ", ")
+	},
+	"value": func(value interface{}) string {
+		if value == nil {
+			return ""
+		}
+		return fmt.Sprint(value)
+	},
+	"cell": func(value string) string {
+		if value == "" {
+			return "-"
+		}
+		return strings.NewReplacer("|", `\|`, "\n", " ").Replace(value)
+	},
+}
+
+var markdownWiring = template.Must(template.New("wiring").Funcs(wiringFuncs).Parse(`# Application Wiring
+
+## Components
+{{range .Components}}
+### {{.Name}}
+{{if .Description}}
+{{.Description}}
+{{end}}
+| | |
+|---|---|
+| Type | ` + "`{{.Type}}`" + ` |
+| Owner | {{cell .Owner}} |
+| Registered by | {{cell .RegisteredBy}} |
+{{- if .Module}}
+| Module | {{.Module}} |
+{{- end}}
+{{- if .Tags}}
+| Tags | {{join .Tags}} |
+{{- end}}
+{{- if .Interfaces}}
+| Interfaces | {{join .Interfaces}} |
+{{- end}}
+| Depends on | {{codes .Dependencies}} |
+| Used by | {{codes .Dependents}} |
+| Config keys | {{codes .ConfigKeys}} |
+{{end}}
+{{- if .Starters}}
+## Starters
+
+| Starter | Description | Owner | Components |
+|---|---|---|---|
+{{- range .Starters}}
+| {{.Name}} | {{cell .Description}} | {{cell .Owner}} | {{codes .Components}} |
+{{- end}}
+{{end}}
+{{- if .ConfigKeys}}
+## Configuration Keys
+
+| Key | Read by |
+|---|---|
+{{- range .ConfigKeys}}
+| ` + "`{{.Key}}`" + ` | {{join .Components}} |
+{{- end}}
+{{end}}
+{{- if .Variables}}
+## Declared Variables
+
+| Variable | Type | Default | Required | Description |
+|---|---|---|---|---|
+{{- range .Variables}}
+| ` + "`{{.Name}}`" + ` | {{.Type}} | {{cell (value .Default)}} | {{if .Required}}yes{{else}}no{{end}} | {{cell .Description}} |
+{{- end}}
+{{end -}}
+`))
+
+var htmlWiring = htmltemplate.Must(htmltemplate.New("wiring").Funcs(htmltemplate.FuncMap{"join": wiringFuncs["join"], "value": wiringFuncs["value"]}).Parse(`<!DOCTYPE html>
+<html>
+<head>
+<meta charset="utf-8">
+<title>Application Wiring</title>
+<style>
+body { font-family: sans-serif; margin: 2em; }
+table { border-collapse: collapse; margin-bottom: 1.5em; }
+th, td { border: 1px solid #ccc; padding: 0.3em 0.6em; text-align: left; vertical-align: top; }
+code { background: #f4f4f4; }
+</style>
+</head>
+<body>
+<h1>Application Wiring</h1>
+<h2>Components</h2>
+{{range .Components}}
+<h3 id="{{.Name}}">{{.Name}}</h3>
+{{if .Description}}<p>{{.Description}}</p>{{end}}
+<table>
+<tr><th>Type</th><td><code>{{.Type}}</code></td></tr>
+<tr><th>Owner</th><td>{{.Owner}}</td></tr>
+<tr><th>Registered by</th><td>{{.RegisteredBy}}</td></tr>
+{{if .Module}}<tr><th>Module</th><td>{{.Module}}</td></tr>{{end}}
+{{if .Tags}}<tr><th>Tags</th><td>{{join .Tags}}</td></tr>{{end}}
+{{if .Interfaces}}<tr><th>Interfaces</th><td>{{join .Interfaces}}</td></tr>{{end}}
+<tr><th>Depends on</th><td>{{range $i, $d := .Dependencies}}{{if $i}}, {{end}}<a href="#{{$d}}">{{$d}}</a>{{end}}</td></tr>
+<tr><th>Used by</th><td>{{range $i, $d := .Dependents}}{{if $i}}, {{end}}<a href="#{{$d}}">{{$d}}</a>{{end}}</td></tr>
+<tr><th>Config keys</th><td>{{range $i, $k := .ConfigKeys}}{{if $i}}, {{end}}<code>{{$k}}</code>{{end}}</td></tr>
+</table>
+{{end}}
+{{if .Starters}}
+<h2>Starters</h2>
+<table>
+<tr><th>Starter</th><th>Description</th><th>Owner</th><th>Components</th></tr>
+{{range .Starters}}<tr><td>{{.Name}}</td><td>{{.Description}}</td><td>{{.Owner}}</td><td>{{join .Components}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .ConfigKeys}}
+<h2>Configuration Keys</h2>
+<table>
+<tr><th>Key</th><th>Read by</th></tr>
+{{range .ConfigKeys}}<tr><td><code>{{.Key}}</code></td><td>{{join .Components}}</td></tr>
+{{end}}</table>
+{{end}}
+{{if .Variables}}
+<h2>Declared Variables</h2>
+<table>
+<tr><th>Variable</th><th>Type</th><th>Default</th><th>Required</th><th>Description</th></tr>
+{{range .Variables}}<tr><td><code>{{.Name}}</code></td><td>{{.Type}}</td><td>{{value .Default}}</td><td>{{if .Required}}yes{{else}}no{{end}}</td><td>{{.Description}}</td></tr>
+{{end}}</table>
+{{end}}
+</body>
+</html>
+`))
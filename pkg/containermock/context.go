@@ -0,0 +1,549 @@
+// Package containermock provides a programmable fake ApplicationContext and
+// ContextBuilder for unit testing components without a real container
+package containermock
+
+import (
+	"context"
+	"fmt"
+	"io"
+	"reflect"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Call records a single method invocation on the fake
+type Call struct {
+	Method string
+	Args   []interface{}
+}
+
+// Context is a fake container.ContextBuilder.
+// By default it serves components and variables from its maps; any behavior can be
+// overridden by setting the corresponding *Func field. All calls are recorded.
+type Context struct {
+	mu    sync.Mutex
+	calls []Call
+
+	// Components served by name and type lookups
+	Components map[string]container.Component
+	// Variables served by GetVariable and GetVariableRaw
+	Variables map[string]interface{}
+	// Tags maps tag names to component names for GetComponentsByTag
+	Tags map[string][]string
+	// Metrics returned by GetMetrics
+	Metrics map[string]*container.ComponentMetrics
+	// Profiles returned by GetActiveProfiles and extended by ActivateProfiles
+	Profiles []string
+
+	// Loaders, PostProcessors, Factories, Starters and Modules collect what was added through the builder
+	Loaders        []container.VariableLoader
+	PostProcessors []container.EnvironmentPostProcessor
+	Factories      []container.Factory
+	Starters       []container.Starter
+	Modules        []container.Module
+	// ShutdownHooks collects the hooks of RegisterShutdownHook by name; their
+	// options are ignored
+	ShutdownHooks map[string]func(context.Context)
+
+	// Optional overrides
+	GetComponentFunc       func(target interface{}) error
+	GetComponentByNameFunc func(name string) (container.Component, error)
+	GetVariableFunc        func(name string) string
+	GetVariableRawFunc     func(name string) interface{}
+	GetVariableAsFunc      func(name string, target interface{}) error
+	HasComponentFunc       func(name string) bool
+	RegisterComponentFunc  func(component container.Component) error
+	ReloadVariablesFunc    func() ([]string, error)
+}
+
+// New creates an empty fake context
+func New() *Context {
+	return &Context{
+		Components: make(map[string]container.Component),
+		Variables:  make(map[string]interface{}),
+		Tags:       make(map[string][]string),
+
+		ShutdownHooks: make(map[string]func(context.Context)),
+	}
+}
+
+// WithComponent adds a component to the fake and returns it for chaining
+func (c *Context) WithComponent(component container.Component) *Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Components[component.Name()] = component
+	return c
+}
+
+// WithVariable adds a variable to the fake and returns it for chaining
+func (c *Context) WithVariable(name string, value interface{}) *Context {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Variables[name] = value
+	return c
+}
+
+// Calls returns all recorded calls in order
+func (c *Context) Calls() []Call {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make([]Call, len(c.calls))
+	copy(result, c.calls)
+	return result
+}
+
+// CallsTo returns the recorded calls to the given method
+func (c *Context) CallsTo(method string) []Call {
+	var result []Call
+	for _, call := range c.Calls() {
+		if call.Method == method {
+			result = append(result, call)
+		}
+	}
+	return result
+}
+
+// Reset clears the recorded calls
+func (c *Context) Reset() {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = nil
+}
+
+func (c *Context) record(method string, args ...interface{}) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.calls = append(c.calls, Call{Method: method, Args: args})
+}
+
+// GetComponent assigns the first component (by name order) whose type matches the target
+func (c *Context) GetComponent(target interface{}) error {
+	c.record("GetComponent", target)
+	if c.GetComponentFunc != nil {
+		return c.GetComponentFunc(target)
+	}
+
+	targetType := reflect.TypeOf(target)
+	if targetType == nil || targetType.Kind() != reflect.Ptr {
+		return container.ErrorWithCode("TARGET_NOT_POINTER", "target must be a pointer")
+	}
+	elemType := targetType.Elem()
+	targetValue := reflect.ValueOf(target).Elem()
+
+	c.mu.Lock()
+	names := sortedNames(c.Components)
+	components := make([]container.Component, len(names))
+	for i, name := range names {
+		components[i] = c.Components[name]
+	}
+	c.mu.Unlock()
+
+	for _, comp := range components {
+		var value interface{} = comp
+		switch c := comp.(type) {
+		case *container.InstanceComponent:
+			value = c.Value()
+		case *container.ConfigProperties:
+			value = c.Value()
+		}
+		if reflect.TypeOf(value).AssignableTo(elemType) {
+			targetValue.Set(reflect.ValueOf(value))
+			return nil
+		}
+	}
+
+	return container.ErrorWithCode("COMPONENT_TYPE_NOT_FOUND", "no component found matching type %v", elemType)
+}
+
+// GetComponentByName returns the named component
+func (c *Context) GetComponentByName(name string) (container.Component, error) {
+	c.record("GetComponentByName", name)
+	if c.GetComponentByNameFunc != nil {
+		return c.GetComponentByNameFunc(name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	comp, ok := c.Components[name]
+	if !ok {
+		return nil, container.ComponentNotFoundError(name)
+	}
+	return comp, nil
+}
+
+// GetVariable returns the named variable formatted as a string
+func (c *Context) GetVariable(name string) string {
+	c.record("GetVariable", name)
+	if c.GetVariableFunc != nil {
+		return c.GetVariableFunc(name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	value, ok := c.Variables[name]
+	if !ok || value == nil {
+		return ""
+	}
+	if s, ok := value.(string); ok {
+		return s
+	}
+	return fmt.Sprintf("%v", value)
+}
+
+// GetVariableRaw returns the named variable
+func (c *Context) GetVariableRaw(name string) interface{} {
+	c.record("GetVariableRaw", name)
+	if c.GetVariableRawFunc != nil {
+		return c.GetVariableRawFunc(name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Variables[name]
+}
+
+// GetVariableAs converts the named variable into target
+func (c *Context) GetVariableAs(name string, target interface{}) error {
+	c.record("GetVariableAs", name, target)
+	if c.GetVariableAsFunc != nil {
+		return c.GetVariableAsFunc(name, target)
+	}
+	return container.NewVariableHelper(c).Bind(name, target)
+}
+
+// HasVariable checks if the named variable exists
+func (c *Context) HasVariable(name string) bool {
+	c.record("HasVariable", name)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.Variables[name]
+	return ok
+}
+
+// GetVariables returns a copy of all variables
+func (c *Context) GetVariables() map[string]interface{} {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	result := make(map[string]interface{}, len(c.Variables))
+	for k, v := range c.Variables {
+		result[k] = v
+	}
+	return result
+}
+
+// DumpConfig writes the fake's variables, secrets masked, with the origin "containermock"
+func (c *Context) DumpConfig(w io.Writer, format container.DumpFormat) error {
+	c.record("DumpConfig", w, format)
+
+	variables := c.GetVariables()
+	dump := container.ConfigDump{CreatedAt: time.Now(), Profiles: c.GetActiveProfiles()}
+	for _, name := range sortedVariableNames(variables) {
+		value := variables[name]
+		if container.IsSecretVariable(name) && value != nil {
+			value = container.MaskedValue
+		}
+		dump.Variables = append(dump.Variables, container.DumpedVariable{Name: name, Value: value, Origin: "containermock"})
+	}
+	return dump.Encode(w, format)
+}
+
+// GetActiveProfiles returns the fake's profiles; groups aren't expanded
+func (c *Context) GetActiveProfiles() []string {
+	c.record("GetActiveProfiles")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return append([]string(nil), c.Profiles...)
+}
+
+// HasComponent checks if the named component exists
+func (c *Context) HasComponent(name string) bool {
+	c.record("HasComponent", name)
+	if c.HasComponentFunc != nil {
+		return c.HasComponentFunc(name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	_, ok := c.Components[name]
+	return ok
+}
+
+// GetComponentsByTag returns the components with the given tag, sorted by name
+func (c *Context) GetComponentsByTag(tag string) []container.Component {
+	c.record("GetComponentsByTag", tag)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	tagged := make(map[string]container.Component)
+	for _, name := range c.Tags[tag] {
+		if comp, ok := c.Components[name]; ok {
+			tagged[name] = comp
+		}
+	}
+	for name, comp := range c.Components {
+		if t, ok := comp.(container.Tagged); ok {
+			for _, candidate := range t.Tags() {
+				if candidate == tag {
+					tagged[name] = comp
+				}
+			}
+		}
+	}
+
+	result := make([]container.Component, 0, len(tagged))
+	for _, name := range sortedNames(tagged) {
+		result = append(result, tagged[name])
+	}
+	return result
+}
+
+// GetComponentNames returns the sorted component names
+func (c *Context) GetComponentNames() []string {
+	c.record("GetComponentNames")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return sortedNames(c.Components)
+}
+
+// GetMetrics returns the configured metrics
+func (c *Context) GetMetrics() map[string]*container.ComponentMetrics {
+	c.record("GetMetrics")
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.Metrics
+}
+
+// RegisterComponent adds a component
+func (c *Context) RegisterComponent(component container.Component) error {
+	c.record("RegisterComponent", component)
+	if c.RegisterComponentFunc != nil {
+		return c.RegisterComponentFunc(component)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.register(component)
+}
+
+// RegisterComponentExposedAs adds a component; exposure is recorded but not enforced by the fake
+func (c *Context) RegisterComponentExposedAs(component container.Component, types ...reflect.Type) error {
+	c.record("RegisterComponentExposedAs", component, types)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.register(component)
+}
+
+// RegisterInstance adds an arbitrary value wrapped in a container.InstanceComponent
+func (c *Context) RegisterInstance(name string, value interface{}) error {
+	c.record("RegisterInstance", name, value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.register(container.NewInstance(name, value))
+}
+
+// RegisterSupplier builds the component right away with the fake as context and
+// registers it
+func (c *Context) RegisterSupplier(name string, build func(container.ApplicationContext) (container.Component, error)) error {
+	c.record("RegisterSupplier", name)
+
+	component, err := build(c)
+	if err != nil {
+		return err
+	}
+	if component == nil {
+		return container.ErrorWithCode("NIL_SUPPLIED_COMPONENT", "supplier of %s built no component", name)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.register(component)
+}
+
+func (c *Context) register(component container.Component) error {
+	if _, exists := c.Components[component.Name()]; exists {
+		return container.ComponentAlreadyRegisteredError(component.Name())
+	}
+	c.Components[component.Name()] = component
+	return nil
+}
+
+// RegisterComponentWithTags adds a component with additional tags
+func (c *Context) RegisterComponentWithTags(component container.Component, tags ...string) error {
+	c.record("RegisterComponentWithTags", component, tags)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if err := c.register(component); err != nil {
+		return err
+	}
+	for _, tag := range tags {
+		c.Tags[tag] = append(c.Tags[tag], component.Name())
+	}
+	return nil
+}
+
+// RegisterVariable adds a variable
+func (c *Context) RegisterVariable(name string, value interface{}) {
+	c.record("RegisterVariable", name, value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Variables[name] = value
+}
+
+// RegisterVariables adds several variables
+func (c *Context) RegisterVariables(variables map[string]interface{}) {
+	c.record("RegisterVariables", variables)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	for name, value := range variables {
+		c.Variables[name] = value
+	}
+}
+
+// RegisterDefaultVariable records a default value and sets it unless the variable exists
+func (c *Context) RegisterDefaultVariable(name string, value interface{}) {
+	c.record("RegisterDefaultVariable", name, value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if _, ok := c.Variables[name]; !ok {
+		c.Variables[name] = value
+	}
+}
+
+// DeclareVariable records the declaration and sets its default unless the variable exists
+func (c *Context) DeclareVariable(name string, spec container.VarSpec) {
+	c.record("DeclareVariable", name, spec)
+	if spec.Default != nil {
+		c.mu.Lock()
+		defer c.mu.Unlock()
+		if _, ok := c.Variables[name]; !ok {
+			c.Variables[name] = spec.Default
+		}
+	}
+}
+
+// RegisterVariableString adds a string variable
+func (c *Context) RegisterVariableString(name string, value string) {
+	c.record("RegisterVariableString", name, value)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Variables[name] = value
+}
+
+// ReloadVariables calls ReloadVariablesFunc; by default nothing changes
+func (c *Context) ReloadVariables() ([]string, error) {
+	c.record("ReloadVariables")
+	if c.ReloadVariablesFunc != nil {
+		return c.ReloadVariablesFunc()
+	}
+	return nil, nil
+}
+
+// RegisterShutdownHook records a shutdown hook
+func (c *Context) RegisterShutdownHook(name string, fn func(context.Context), opts ...container.ShutdownHookOption) {
+	c.record("RegisterShutdownHook", name, fn, opts)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if c.ShutdownHooks == nil {
+		c.ShutdownHooks = make(map[string]func(context.Context))
+	}
+	c.ShutdownHooks[name] = fn
+}
+
+// ActivateProfiles records the profiles and adds them to Profiles
+func (c *Context) ActivateProfiles(profiles ...string) {
+	c.record("ActivateProfiles", profiles)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Profiles = append(c.Profiles, profiles...)
+}
+
+// AddVariableLoader records a variable loader
+func (c *Context) AddVariableLoader(loader container.VariableLoader) {
+	c.record("AddVariableLoader", loader)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Loaders = append(c.Loaders, loader)
+}
+
+// AddEnvironmentPostProcessor records an environment post-processor
+func (c *Context) AddEnvironmentPostProcessor(processor container.EnvironmentPostProcessor) {
+	c.record("AddEnvironmentPostProcessor", processor)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.PostProcessors = append(c.PostProcessors, processor)
+}
+
+// RegisterFactory records a component factory
+func (c *Context) RegisterFactory(factory container.Factory) {
+	c.record("RegisterFactory", factory)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Factories = append(c.Factories, factory)
+}
+
+// RegisterStarter records a starter
+func (c *Context) RegisterStarter(starter container.Starter) {
+	c.record("RegisterStarter", starter)
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.Starters = append(c.Starters, starter)
+}
+
+// RegisterModule records the module and runs its Register function against the fake;
+// module visibility isn't enforced
+func (c *Context) RegisterModule(module container.Module) error {
+	c.record("RegisterModule", module)
+
+	c.mu.Lock()
+	c.Modules = append(c.Modules, module)
+	c.mu.Unlock()
+
+	if module.Register != nil {
+		module.Register(c)
+	}
+	return nil
+}
+
+func sortedVariableNames(variables map[string]interface{}) []string {
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func sortedNames(components map[string]container.Component) []string {
+	names := make([]string, 0, len(components))
+	for name := range components {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Ensure that Context implements container.ContextBuilder
+var _ container.ContextBuilder = (*Context)(nil)
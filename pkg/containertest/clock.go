@@ -0,0 +1,224 @@
+package containertest
+
+import (
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// FakeClock is a container.Clock whose time only moves when Advance is called.
+// It lets tests assert how many times a scheduled component executed without sleeping.
+type FakeClock struct {
+	mu      sync.Mutex
+	changed *sync.Cond
+	now     time.Time
+	waiters []*fakeWaiter
+}
+
+// fakeWaiter is a pending After call, an active ticker or an armed timer
+type fakeWaiter struct {
+	deadline time.Time
+	period   time.Duration
+	ch       chan time.Time
+	stopped  chan struct{}
+	stopOnce sync.Once
+	// timer waiters are rearmed by their receiver; generation counts Reset and Stop calls
+	timer      bool
+	generation int
+}
+
+// NewFakeClock creates a fake clock starting at the given time
+func NewFakeClock(start time.Time) *FakeClock {
+	c := &FakeClock{now: start}
+	c.changed = sync.NewCond(&c.mu)
+	return c
+}
+
+// WithClock sets the clock used by the container
+func WithClock(clock container.Clock) Option {
+	return func(cfg *container.Config) {
+		cfg.Clock = clock
+	}
+}
+
+// Now returns the current fake time
+func (c *FakeClock) Now() time.Time {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.now
+}
+
+// After returns a channel that receives the fake time once it has advanced by d
+func (c *FakeClock) After(d time.Duration) <-chan time.Time {
+	w := &fakeWaiter{ch: make(chan time.Time, 1), stopped: make(chan struct{})}
+	c.addWaiter(w, d)
+	return w.ch
+}
+
+// NewTicker returns a ticker that fires each time the fake time advances by d
+func (c *FakeClock) NewTicker(d time.Duration) container.Ticker {
+	if d <= 0 {
+		panic("containertest: non-positive interval for NewTicker")
+	}
+	w := &fakeWaiter{period: d, ch: make(chan time.Time), stopped: make(chan struct{})}
+	c.addWaiter(w, d)
+	return &fakeTicker{clock: c, waiter: w}
+}
+
+// NewTimer returns a timer that fires once the fake time advances by d. The
+// receiver of its tick must reset or stop it, Advance waits for that.
+func (c *FakeClock) NewTimer(d time.Duration) container.Timer {
+	w := &fakeWaiter{ch: make(chan time.Time), stopped: make(chan struct{}), timer: true}
+	c.addWaiter(w, d)
+	return &fakeTimer{clock: c, waiter: w}
+}
+
+// Advance moves the fake time forward by d, firing every timer and ticker that
+// becomes due in chronological order. Each tick is delivered synchronously, and
+// after a timer's tick Advance waits until the receiver resets or stops the timer,
+// so the container's scheduler sees every due run before Advance returns. Scheduled
+// executions run asynchronously and runs falling due while one is in flight are
+// merged, so wait for an execution to complete before advancing to the next run.
+func (c *FakeClock) Advance(d time.Duration) {
+	c.mu.Lock()
+	target := c.now.Add(d)
+
+	for {
+		next := c.nextDue(target)
+		if next == nil {
+			break
+		}
+
+		c.now = next.deadline
+		if next.period > 0 {
+			next.deadline = next.deadline.Add(next.period)
+		} else {
+			c.removeWaiter(next)
+		}
+		now, ch, stopped, generation := c.now, next.ch, next.stopped, next.generation
+
+		// Deliver without holding the lock so the receiver may use the clock
+		c.mu.Unlock()
+		delivered := false
+		select {
+		case ch <- now:
+			delivered = true
+		case <-stopped:
+		}
+		c.mu.Lock()
+
+		for delivered && next.timer && next.generation == generation {
+			c.changed.Wait()
+		}
+	}
+
+	c.now = target
+	c.mu.Unlock()
+}
+
+// BlockUntil blocks until at least n timers or tickers are waiting on the clock.
+// Use it to make sure scheduled components have set up their timers before advancing.
+func (c *FakeClock) BlockUntil(n int) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	for len(c.waiters) < n {
+		c.changed.Wait()
+	}
+}
+
+func (c *FakeClock) addWaiter(w *fakeWaiter, d time.Duration) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	w.deadline = c.now.Add(d)
+	c.waiters = append(c.waiters, w)
+	c.changed.Broadcast()
+}
+
+func (c *FakeClock) removeWaiter(w *fakeWaiter) {
+	for i, existing := range c.waiters {
+		if existing == w {
+			c.waiters = append(c.waiters[:i], c.waiters[i+1:]...)
+			c.changed.Broadcast()
+			return
+		}
+	}
+}
+
+// nextDue returns the earliest waiter due at or before target
+func (c *FakeClock) nextDue(target time.Time) *fakeWaiter {
+	var next *fakeWaiter
+	for _, w := range c.waiters {
+		if w.deadline.After(target) {
+			continue
+		}
+		if next == nil || w.deadline.Before(next.deadline) {
+			next = w
+		}
+	}
+	return next
+}
+
+// fakeTicker is a container.Ticker driven by a FakeClock
+type fakeTicker struct {
+	clock  *FakeClock
+	waiter *fakeWaiter
+}
+
+// C returns the tick channel
+func (t *fakeTicker) C() <-chan time.Time {
+	return t.waiter.ch
+}
+
+// Stop removes the ticker from the clock
+func (t *fakeTicker) Stop() {
+	t.waiter.stopOnce.Do(func() {
+		close(t.waiter.stopped)
+	})
+
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.clock.removeWaiter(t.waiter)
+}
+
+// fakeTimer is a container.Timer driven by a FakeClock
+type fakeTimer struct {
+	clock  *FakeClock
+	waiter *fakeWaiter
+}
+
+// C returns the tick channel
+func (t *fakeTimer) C() <-chan time.Time {
+	return t.waiter.ch
+}
+
+// Stop removes the timer from the clock, abandoning a tick being delivered
+func (t *fakeTimer) Stop() {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.disarm()
+}
+
+// Reset rearms the timer to fire once the fake time advances by d
+func (t *fakeTimer) Reset(d time.Duration) {
+	t.clock.mu.Lock()
+	defer t.clock.mu.Unlock()
+	t.disarm()
+	t.waiter.deadline = t.clock.now.Add(d)
+	t.clock.waiters = append(t.clock.waiters, t.waiter)
+	t.clock.changed.Broadcast()
+}
+
+// disarm removes the waiter and releases a pending delivery; the clock lock must be held
+func (t *fakeTimer) disarm() {
+	t.clock.removeWaiter(t.waiter)
+	close(t.waiter.stopped)
+	t.waiter.stopped = make(chan struct{})
+	t.waiter.generation++
+	t.clock.changed.Broadcast()
+}
+
+// Ensure that FakeClock implements container.Clock
+var _ container.Clock = (*FakeClock)(nil)
@@ -0,0 +1,30 @@
+package containertest
+
+import "github.com/01fortes/goboot/pkg/container"
+
+// Option customizes a test container configuration
+type Option func(*container.Config)
+
+// WithoutDefaultLoaders removes the default loaders so no configuration is read
+// from files or the process environment
+func WithoutDefaultLoaders() Option {
+	return func(cfg *container.Config) {
+		cfg.DefaultVariableLoaders = nil
+	}
+}
+
+// WithLoaders appends variable loaders to the configuration
+func WithLoaders(loaders ...container.VariableLoader) Option {
+	return func(cfg *container.Config) {
+		cfg.DefaultVariableLoaders = append(cfg.DefaultVariableLoaders, loaders...)
+	}
+}
+
+// NewConfig returns the default container configuration with the given options applied
+func NewConfig(opts ...Option) *container.Config {
+	cfg := container.DefaultConfig()
+	for _, opt := range opts {
+		opt(cfg)
+	}
+	return cfg
+}
@@ -0,0 +1,80 @@
+package containertest
+
+import (
+	"fmt"
+	"math/rand"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// SyntheticGraph describes a generated container for benchmarks: Components
+// components named "component-<i>", each depending on about AvgDeps components
+// registered before it, plus Variables configuration values read in Init
+type SyntheticGraph struct {
+	Components int
+	AvgDeps    int
+	Variables  int
+	// Seed makes the generated dependencies reproducible
+	Seed int64
+}
+
+// syntheticComponent looks up its dependencies by name and reads its variable
+type syntheticComponent struct {
+	container.ComponentBase
+	deps     []string
+	variable string
+}
+
+// syntheticService is a component looked up by type from every synthetic component
+type syntheticService struct {
+	container.ComponentBase
+}
+
+func (c *syntheticComponent) Init(ctx container.ApplicationContext) error {
+	var service *syntheticService
+	if err := ctx.GetComponent(&service); err != nil {
+		return err
+	}
+	for _, dep := range c.deps {
+		if _, err := ctx.GetComponentByName(dep); err != nil {
+			return err
+		}
+	}
+	_ = ctx.GetVariable(c.variable)
+	return nil
+}
+
+// Setup returns the setup block registering the generated components
+func (g SyntheticGraph) Setup() func(container.ContextBuilder) {
+	random := rand.New(rand.NewSource(g.Seed))
+	components := make([]*syntheticComponent, g.Components)
+	for i := range components {
+		comp := &syntheticComponent{
+			ComponentBase: container.NewComponentBase(fmt.Sprintf("component-%d", i)),
+			variable:      fmt.Sprintf("synthetic.component-%d.value", i%max(g.Variables, 1)),
+		}
+		// Draw between 0 and 2*AvgDeps dependencies among the previous components
+		if i > 0 && g.AvgDeps > 0 {
+			for n := random.Intn(2*g.AvgDeps + 1); n > 0; n-- {
+				comp.deps = append(comp.deps, fmt.Sprintf("component-%d", random.Intn(i)))
+			}
+		}
+		components[i] = comp
+	}
+
+	return func(builder container.ContextBuilder) {
+		builder.RegisterComponent(&syntheticService{ComponentBase: container.NewComponentBase("syntheticService")})
+		for _, comp := range components {
+			builder.RegisterComponent(comp)
+		}
+	}
+}
+
+// Loader returns a loader registering the generated variables as one nested map
+func (g SyntheticGraph) Loader() container.VariableLoader {
+	components := make(map[string]interface{}, g.Variables)
+	for i := 0; i < g.Variables; i++ {
+		components[fmt.Sprintf("component-%d", i)] = map[string]interface{}{"value": i}
+	}
+	return Variables(map[string]any{"synthetic": components})
+}
@@ -0,0 +1,34 @@
+// Package containertest provides utilities for testing code built on the container
+package containertest
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"gopkg.in/yaml.v3"
+)
+
+// Variables returns a loader that registers exactly the given variables.
+// Nested maps are flattened into dot-separated keys.
+func Variables(vars map[string]any) container.VariableLoader {
+	return container.MapVariableLoader{Variables: vars}
+}
+
+// YAMLString returns a loader that registers variables parsed from a YAML document
+func YAMLString(doc string) container.VariableLoader {
+	return yamlStringLoader{doc: doc}
+}
+
+// yamlStringLoader loads variables from an inline YAML document
+type yamlStringLoader struct {
+	doc string
+}
+
+// Load parses the document and registers its flattened variables
+func (l yamlStringLoader) Load(builder container.ContextBuilder) error {
+	var vars map[string]interface{}
+	if err := yaml.Unmarshal([]byte(l.doc), &vars); err != nil {
+		return fmt.Errorf("invalid YAML document: %w", err)
+	}
+	return container.MapVariableLoader{Variables: vars}.Load(builder)
+}
@@ -0,0 +1,169 @@
+package awsconfig
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/starters/internal/awsv4"
+)
+
+// APIError is an error response of the service
+type APIError struct {
+	StatusCode int
+	// Type is the exception name, e.g. AccessDeniedException
+	Type    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("aws: %s (status %d): %s", e.Type, e.StatusCode, e.Message)
+}
+
+// apiClient calls the JSON APIs of Parameter Store and Secrets Manager
+type apiClient struct {
+	client   *http.Client
+	endpoint string
+	signer   awsv4.Signer
+	now      func() time.Time
+}
+
+// parameters returns the values of the parameters under path by name
+func (a *apiClient) parameters(ctx context.Context, path string, decrypt bool) (map[string]string, error) {
+	if path != "/" {
+		path = strings.TrimSuffix(path, "/")
+	}
+	request := struct {
+		Path           string
+		Recursive      bool
+		WithDecryption bool
+		NextToken      string `json:",omitempty"`
+	}{Path: path, Recursive: true, WithDecryption: decrypt}
+
+	values := make(map[string]string)
+	for {
+		var response struct {
+			Parameters []struct {
+				Name  string
+				Value string
+			}
+			NextToken string
+		}
+		if err := a.call(ctx, "AmazonSSM.GetParametersByPath", request, &response); err != nil {
+			return nil, err
+		}
+		for _, parameter := range response.Parameters {
+			values[parameter.Name] = parameter.Value
+		}
+		if response.NextToken == "" {
+			return values, nil
+		}
+		request.NextToken = response.NextToken
+	}
+}
+
+// secrets returns the string values of the secrets whose name starts with prefix
+// by name
+func (a *apiClient) secrets(ctx context.Context, prefix string) (map[string]string, error) {
+	type filter struct {
+		Key    string
+		Values []string
+	}
+	request := struct {
+		Filters    []filter
+		MaxResults int
+		NextToken  string `json:",omitempty"`
+	}{Filters: []filter{{Key: "name", Values: []string{prefix}}}, MaxResults: 20}
+
+	values := make(map[string]string)
+	for {
+		var response struct {
+			SecretValues []struct {
+				Name         string
+				SecretString *string
+			}
+			Errors []struct {
+				SecretId     string
+				ErrorCode    string
+				ErrorMessage string
+			}
+			NextToken string
+		}
+		if err := a.call(ctx, "secretsmanager.BatchGetSecretValue", request, &response); err != nil {
+			return nil, err
+		}
+		if len(response.Errors) > 0 {
+			failed := response.Errors[0]
+			return nil, fmt.Errorf("secret %s: %s: %s", failed.SecretId, failed.ErrorCode, failed.ErrorMessage)
+		}
+		for _, secret := range response.SecretValues {
+			// Binary secrets have no string value
+			if secret.SecretString != nil {
+				values[secret.Name] = *secret.SecretString
+			}
+		}
+		if response.NextToken == "" {
+			return values, nil
+		}
+		request.NextToken = response.NextToken
+	}
+}
+
+// call sends a signed request to an action of the service and decodes the
+// response into response
+func (a *apiClient) call(ctx context.Context, target string, request, response interface{}) error {
+	body, err := json.Marshal(request)
+	if err != nil {
+		return err
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, a.endpoint, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-amz-json-1.1")
+	req.Header.Set("X-Amz-Target", target)
+	a.signer.Sign(req, awsv4.PayloadHash(body), a.now())
+
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return apiError(resp.StatusCode, data)
+	}
+	return json.Unmarshal(data, response)
+}
+
+// apiError decodes an error response; the type is prefixed with a namespace and
+// the message field is spelled message or Message depending on the service
+func apiError(status int, data []byte) error {
+	var body struct {
+		Type         string `json:"__type"`
+		Message      string `json:"message"`
+		MessageUpper string `json:"Message"`
+	}
+	_ = json.Unmarshal(data, &body)
+
+	apiErr := &APIError{StatusCode: status, Type: body.Type, Message: body.Message}
+	if index := strings.LastIndex(apiErr.Type, "#"); index >= 0 {
+		apiErr.Type = apiErr.Type[index+1:]
+	}
+	if apiErr.Message == "" {
+		apiErr.Message = body.MessageUpper
+	}
+	if apiErr.Type == "" {
+		apiErr.Type = http.StatusText(status)
+	}
+	return apiErr
+}
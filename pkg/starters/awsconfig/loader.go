@@ -0,0 +1,204 @@
+// Package awsconfig loads variables from AWS Systems Manager Parameter Store or
+// Secrets Manager, and refreshes them with the refresh scope
+package awsconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/cloudconfig"
+	"github.com/01fortes/goboot/pkg/starters/internal/awsv4"
+)
+
+// PropertyAWSConfig holds the loader configuration: aws.config.*
+const PropertyAWSConfig = "aws.config"
+
+// Sources selected by aws.config.source
+const (
+	SourceParameterStore = "parameter-store"
+	SourceSecretsManager = "secrets-manager"
+)
+
+// Config configures the loader: aws.config.*
+type Config struct {
+	// Enabled overrides the provider selection; see cloudconfig.Selected
+	Enabled *bool `yaml:"enabled"`
+	// Source is parameter-store (default) or secrets-manager
+	Source string `yaml:"source"`
+	// Path is the prefix of the loaded parameters or secrets, e.g. /shop/prod/
+	Path   string `yaml:"path"`
+	Region string `yaml:"region"`
+	// Endpoint overrides the service endpoint, e.g. LocalStack's
+	Endpoint string `yaml:"endpoint"`
+	// Decrypt decrypts SecureString parameters (default true)
+	Decrypt *bool `yaml:"decrypt"`
+	// Optional starts the application when the service can't be reached
+	Optional bool `yaml:"optional"`
+	// RefreshInterval reloads the variables periodically when set; see Starter
+	RefreshInterval time.Duration `yaml:"refresh-interval"`
+	// Timeout bounds loading all the parameters (default 10s)
+	Timeout      time.Duration `yaml:"timeout"`
+	AccessKey    string        `yaml:"access-key"`
+	SecretKey    string        `yaml:"secret-key"`
+	SessionToken string        `yaml:"session-token"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Source == "" {
+		c.Source = SourceParameterStore
+	}
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_REGION")
+	}
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	if c.Endpoint == "" {
+		c.Endpoint = "https://" + c.service() + "." + c.Region + ".amazonaws.com"
+	}
+	if c.Decrypt == nil {
+		decrypt := true
+		c.Decrypt = &decrypt
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.AccessKey == "" && c.SecretKey == "" {
+		env := awsv4.CredentialsFromEnv()
+		c.AccessKey, c.SecretKey = env.AccessKey, env.SecretKey
+		if c.SessionToken == "" {
+			c.SessionToken = env.SessionToken
+		}
+	}
+	return c
+}
+
+// service is the signing name of the source
+func (c Config) service() string {
+	if c.Source == SourceSecretsManager {
+		return "secretsmanager"
+	}
+	return "ssm"
+}
+
+func (c Config) validate() error {
+	switch c.Source {
+	case SourceParameterStore, SourceSecretsManager:
+	default:
+		return fmt.Errorf("unsupported %s.source %q", PropertyAWSConfig, c.Source)
+	}
+	if c.Path == "" {
+		return fmt.Errorf("%s.path is required", PropertyAWSConfig)
+	}
+	if c.AccessKey == "" || c.SecretKey == "" {
+		return fmt.Errorf("%s credentials are required: set access-key and secret-key or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY", PropertyAWSConfig)
+	}
+	return nil
+}
+
+// Loader loads the parameters under aws.config.path as variables when AWS is the
+// selected provider (see cloudconfig.Selected). It runs after the loaders added
+// before it, which provide its configuration, so add it after the YAML loader:
+//
+//	aws:
+//	  config:
+//	    enabled: true
+//	    path: /shop/prod/
+//
+// The parameter /shop/prod/db/password becomes the variable db.password. With
+// the secrets-manager source, a secret whose value is a JSON object becomes one
+// variable per field: the secret shop/prod/db holding {"password": "..."} becomes
+// db.password.
+//
+// When the service fails while the variables are reloaded, the loader logs the
+// error and keeps the last loaded values.
+type Loader struct {
+	// Client sends the requests (http.DefaultClient by default)
+	Client    *http.Client
+	now       func() time.Time
+	registrar cloudconfig.Registrar
+}
+
+// NewLoader creates a loader
+func NewLoader() *Loader {
+	return &Loader{now: time.Now}
+}
+
+// Load fetches the parameters and registers them as variables
+func (l *Loader) Load(builder container.ContextBuilder) error {
+	if !cloudconfig.Selected(builder, cloudconfig.ProviderAWS, PropertyAWSConfig) {
+		return nil
+	}
+	var config Config
+	if container.NewVariableHelper(builder).HasSection(PropertyAWSConfig) {
+		if err := builder.GetVariableAs(PropertyAWSConfig, &config); err != nil {
+			return container.ConfigurationError("invalid "+PropertyAWSConfig+" configuration", err)
+		}
+	}
+	config = config.withDefaults()
+	if err := config.validate(); err != nil {
+		return container.ConfigurationError("invalid "+PropertyAWSConfig+" configuration", err)
+	}
+
+	variables, err := l.fetch(config)
+	l.registrar.Source = config.Source + " " + config.Path
+	return l.registrar.Register(builder, variables, err, config.Optional)
+}
+
+// fetch returns the variables of the parameters or secrets under the path
+func (l *Loader) fetch(config Config) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	api := &apiClient{
+		client:   client,
+		endpoint: strings.TrimSuffix(config.Endpoint, "/") + "/",
+		now:      l.now,
+		signer: awsv4.Signer{
+			Credentials: awsv4.Credentials{AccessKey: config.AccessKey, SecretKey: config.SecretKey, SessionToken: config.SessionToken},
+			Region:      config.Region,
+			Service:     config.service(),
+		},
+	}
+
+	variables := make(map[string]string)
+	if config.Source == SourceSecretsManager {
+		secrets, err := api.secrets(ctx, config.Path)
+		if err != nil {
+			return nil, err
+		}
+		for name, value := range secrets {
+			cloudconfig.AddSecret(variables, variableName(name, config.Path), value)
+		}
+		return variables, nil
+	}
+
+	parameters, err := api.parameters(ctx, config.Path, *config.Decrypt)
+	if err != nil {
+		return nil, err
+	}
+	for name, value := range parameters {
+		variables[variableName(name, config.Path)] = value
+	}
+	return variables, nil
+}
+
+// variableName maps a parameter name under path to a dot-separated variable name:
+// /shop/prod/db/password under /shop/prod becomes db.password
+func variableName(name, path string) string {
+	name = strings.TrimPrefix(name, path)
+	name = strings.Trim(name, "/")
+	return strings.ReplaceAll(name, "/", ".")
+}
+
+// Ensure that Loader implements container.VariableLoader
+var _ container.VariableLoader = (*Loader)(nil)
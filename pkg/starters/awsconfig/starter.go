@@ -0,0 +1,31 @@
+package awsconfig
+
+import (
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/cloudconfig"
+)
+
+// RefresherName is the cloudconfig.Refresher component of the loader
+const RefresherName = "awsConfigRefresher"
+
+// Setup adds the loader and the starter of its refresher to a context builder, in
+// the setup block after the YAML loader:
+//
+//	awsconfig.Setup(builder)
+func Setup(builder container.ContextBuilder) {
+	builder.AddVariableLoader(NewLoader())
+	builder.RegisterStarter(Starter())
+}
+
+// Starter registers a cloudconfig.Refresher when the loader is active and
+// aws.config.refresh-interval is set. The refresh reloads all the variable
+// loaders, Loader among them:
+//
+//	aws:
+//	  config:
+//	    enabled: true
+//	    path: /shop/prod/
+//	    refresh-interval: 5m
+func Starter() container.Starter {
+	return cloudconfig.RefreshStarter("AWSConfigStarter", RefresherName, cloudconfig.ProviderAWS, PropertyAWSConfig)
+}
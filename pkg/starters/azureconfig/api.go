@@ -0,0 +1,125 @@
+package azureconfig
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// apiVersion is the version of the Key Vault REST API
+const apiVersion = "7.4"
+
+// APIError is an error response of Key Vault or of the token endpoints
+type APIError struct {
+	StatusCode int
+	// Code is the error code, e.g. Forbidden
+	Code    string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("azure: %s (status %d): %s", e.Code, e.StatusCode, e.Message)
+}
+
+// apiClient calls the REST API of a vault
+type apiClient struct {
+	client   *http.Client
+	vaultURL string
+	token    string
+}
+
+// secrets returns the IDs of the enabled secrets whose name starts with prefix
+func (a *apiClient) secrets(ctx context.Context, prefix string) ([]string, error) {
+	var ids []string
+	next := a.vaultURL + "/secrets?api-version=" + apiVersion
+	for next != "" {
+		var response struct {
+			Value []struct {
+				// ID is <vault>/secrets/<name>
+				ID         string `json:"id"`
+				Attributes struct {
+					Enabled bool `json:"enabled"`
+				} `json:"attributes"`
+			} `json:"value"`
+			NextLink string `json:"nextLink"`
+		}
+		if err := a.get(ctx, next, &response); err != nil {
+			return nil, err
+		}
+		for _, secret := range response.Value {
+			name := secret.ID[strings.LastIndex(secret.ID, "/")+1:]
+			if secret.Attributes.Enabled && strings.HasPrefix(name, prefix) {
+				ids = append(ids, secret.ID)
+			}
+		}
+		next = response.NextLink
+	}
+	return ids, nil
+}
+
+// secret returns the value of the current version of a secret
+func (a *apiClient) secret(ctx context.Context, id string) (string, error) {
+	var response struct {
+		Value string `json:"value"`
+	}
+	if err := a.get(ctx, id+"?api-version="+apiVersion, &response); err != nil {
+		return "", err
+	}
+	return response.Value, nil
+}
+
+func (a *apiClient) get(ctx context.Context, url string, response interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, url, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return doJSON(a.client, req, response)
+}
+
+// doJSON sends a request and decodes its JSON response into response
+func doJSON(client *http.Client, req *http.Request, response interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		return apiError(resp.StatusCode, data)
+	}
+	return json.Unmarshal(data, response)
+}
+
+// apiError decodes an error response: Key Vault nests the code and message in
+// error, while the token endpoints return OAuth2 errors
+func apiError(status int, data []byte) error {
+	var body struct {
+		Error json.RawMessage `json:"error"`
+		// OAuth2 errors of the token endpoints
+		ErrorDescription string `json:"error_description"`
+	}
+	_ = json.Unmarshal(data, &body)
+
+	apiErr := &APIError{StatusCode: status, Message: body.ErrorDescription}
+	var nested struct {
+		Code    string `json:"code"`
+		Message string `json:"message"`
+	}
+	if json.Unmarshal(body.Error, &nested) == nil {
+		apiErr.Code, apiErr.Message = nested.Code, nested.Message
+	} else {
+		_ = json.Unmarshal(body.Error, &apiErr.Code)
+	}
+	if apiErr.Code == "" {
+		apiErr.Code = http.StatusText(status)
+	}
+	return apiErr
+}
@@ -0,0 +1,157 @@
+// Package azureconfig loads variables from Azure Key Vault, and refreshes them
+// with the refresh scope
+package azureconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/cloudconfig"
+)
+
+// PropertyAzureConfig holds the loader configuration: azure.config.*
+const PropertyAzureConfig = "azure.config"
+
+// Config configures the loader: azure.config.*
+type Config struct {
+	// Enabled overrides the provider selection; see cloudconfig.Selected
+	Enabled *bool `yaml:"enabled"`
+	// VaultURL is the URL of the vault, e.g. https://shop.vault.azure.net
+	VaultURL string `yaml:"vault-url"`
+	// Prefix selects the secrets whose name starts with it, e.g. shop--; it is
+	// removed from the variable names
+	Prefix string `yaml:"prefix"`
+	// Optional starts the application when the vault can't be reached
+	Optional bool `yaml:"optional"`
+	// RefreshInterval reloads the variables periodically when set; see Starter
+	RefreshInterval time.Duration `yaml:"refresh-interval"`
+	// Timeout bounds loading all the secrets (default 10s)
+	Timeout time.Duration `yaml:"timeout"`
+	// AccessToken is a Key Vault access token, e.g. of az account get-access-token
+	AccessToken string `yaml:"access-token"`
+	// TenantID, ClientID and ClientSecret authenticate a service principal
+	// (default AZURE_TENANT_ID, AZURE_CLIENT_ID and AZURE_CLIENT_SECRET). Without a
+	// secret, the token of the managed identity is requested, of the user-assigned
+	// identity ClientID when set.
+	TenantID     string `yaml:"tenant-id"`
+	ClientID     string `yaml:"client-id"`
+	ClientSecret string `yaml:"client-secret"`
+	// AuthorityHost is the Microsoft Entra ID endpoint (default
+	// https://login.microsoftonline.com)
+	AuthorityHost string `yaml:"authority-host"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.TenantID == "" {
+		c.TenantID = os.Getenv("AZURE_TENANT_ID")
+	}
+	if c.ClientID == "" {
+		c.ClientID = os.Getenv("AZURE_CLIENT_ID")
+	}
+	if c.ClientSecret == "" {
+		c.ClientSecret = os.Getenv("AZURE_CLIENT_SECRET")
+	}
+	if c.AuthorityHost == "" {
+		c.AuthorityHost = "https://login.microsoftonline.com"
+	}
+	return c
+}
+
+// Loader loads the secrets of azure.config.vault-url as variables when Azure is
+// the selected provider (see cloudconfig.Selected). It runs after the loaders
+// added before it, which provide its configuration, so add it after the YAML
+// loader:
+//
+//	azure:
+//	  config:
+//	    vault-url: https://shop.vault.azure.net
+//
+// Secret names can only contain letters, digits and dashes, so double dashes
+// separate the levels: the secret db--password becomes the variable db.password.
+// A secret whose value is a JSON object becomes one variable per field: the secret
+// db holding {"password": "..."} becomes db.password as well. Disabled secrets
+// are skipped.
+//
+// When the vault fails while the variables are reloaded, the loader logs the
+// error and keeps the last loaded values.
+type Loader struct {
+	// Client sends the requests (http.DefaultClient by default)
+	Client    *http.Client
+	registrar cloudconfig.Registrar
+}
+
+// NewLoader creates a loader
+func NewLoader() *Loader {
+	return &Loader{}
+}
+
+// Load fetches the secrets and registers them as variables
+func (l *Loader) Load(builder container.ContextBuilder) error {
+	if !cloudconfig.Selected(builder, cloudconfig.ProviderAzure, PropertyAzureConfig) {
+		return nil
+	}
+	var config Config
+	if container.NewVariableHelper(builder).HasSection(PropertyAzureConfig) {
+		if err := builder.GetVariableAs(PropertyAzureConfig, &config); err != nil {
+			return container.ConfigurationError("invalid "+PropertyAzureConfig+" configuration", err)
+		}
+	}
+	config = config.withDefaults()
+	if config.VaultURL == "" {
+		return container.ConfigurationError("invalid "+PropertyAzureConfig+" configuration",
+			fmt.Errorf("%s.vault-url is required", PropertyAzureConfig))
+	}
+
+	variables, err := l.fetch(config)
+	l.registrar.Source = "key vault " + config.VaultURL
+	return l.registrar.Register(builder, variables, err, config.Optional)
+}
+
+// fetch returns the variables of the enabled secrets
+func (l *Loader) fetch(config Config) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	token, err := accessToken(ctx, client, config)
+	if err != nil {
+		return nil, fmt.Errorf("authenticate: %w", err)
+	}
+	api := &apiClient{client: client, vaultURL: strings.TrimSuffix(config.VaultURL, "/"), token: token}
+
+	ids, err := api.secrets(ctx, config.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	variables := make(map[string]string)
+	for _, id := range ids {
+		value, err := api.secret(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: %w", id, err)
+		}
+		cloudconfig.AddSecret(variables, variableName(id, config.Prefix), value)
+	}
+	return variables, nil
+}
+
+// variableName maps the ID of a secret to a dot-separated variable name:
+// https://shop.vault.azure.net/secrets/shop--db--password with the prefix shop--
+// becomes db.password
+func variableName(id, prefix string) string {
+	name := strings.TrimPrefix(id[strings.LastIndex(id, "/")+1:], prefix)
+	return strings.ReplaceAll(name, "--", ".")
+}
+
+// Ensure that Loader implements container.VariableLoader
+var _ container.VariableLoader = (*Loader)(nil)
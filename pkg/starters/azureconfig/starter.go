@@ -0,0 +1,25 @@
+package azureconfig
+
+import (
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/cloudconfig"
+)
+
+// RefresherName is the cloudconfig.Refresher component of the loader
+const RefresherName = "azureConfigRefresher"
+
+// Setup adds the loader and the starter of its refresher to a context builder, in
+// the setup block after the YAML loader:
+//
+//	azureconfig.Setup(builder)
+func Setup(builder container.ContextBuilder) {
+	builder.AddVariableLoader(NewLoader())
+	builder.RegisterStarter(Starter())
+}
+
+// Starter registers a cloudconfig.Refresher when the loader is active and
+// azure.config.refresh-interval is set. The refresh reloads all the variable
+// loaders, Loader among them.
+func Starter() container.Starter {
+	return cloudconfig.RefreshStarter("AzureConfigStarter", RefresherName, cloudconfig.ProviderAzure, PropertyAzureConfig)
+}
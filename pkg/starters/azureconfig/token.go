@@ -0,0 +1,94 @@
+package azureconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+)
+
+// resource is the audience of Key Vault tokens
+const resource = "https://vault.azure.net"
+
+// imdsTokenURL returns the token of the managed identity of a VM or AKS pod; a
+// variable so that it can point at a fake endpoint
+var imdsTokenURL = "http://169.254.169.254/metadata/identity/oauth2/token"
+
+// tokenResponse is the response of the token endpoints
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+// accessToken returns the configured access token, or requests one for the
+// service principal or the managed identity
+func accessToken(ctx context.Context, client *http.Client, config Config) (string, error) {
+	if config.AccessToken != "" {
+		return config.AccessToken, nil
+	}
+	if config.ClientSecret != "" {
+		return clientSecretToken(ctx, client, config)
+	}
+	return managedIdentityToken(ctx, client, config.ClientID)
+}
+
+// clientSecretToken requests a token with the client credentials of a service
+// principal
+func clientSecretToken(ctx context.Context, client *http.Client, config Config) (string, error) {
+	if config.TenantID == "" || config.ClientID == "" {
+		return "", fmt.Errorf("%s.tenant-id and client-id are required with a client secret", PropertyAzureConfig)
+	}
+	form := url.Values{
+		"grant_type":    {"client_credentials"},
+		"client_id":     {config.ClientID},
+		"client_secret": {config.ClientSecret},
+		"scope":         {resource + "/.default"},
+	}
+	tokenURL := strings.TrimSuffix(config.AuthorityHost, "/") + "/" + url.PathEscape(config.TenantID) + "/oauth2/v2.0/token"
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, tokenURL, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var token tokenResponse
+	if err := doJSON(client, req, &token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// managedIdentityToken requests the token of the managed identity, from the
+// identity endpoint of App Service and Container Apps when present, otherwise
+// from the instance metadata service
+func managedIdentityToken(ctx context.Context, client *http.Client, clientID string) (string, error) {
+	query := url.Values{"resource": {resource}}
+	if clientID != "" {
+		query.Set("client_id", clientID)
+	}
+
+	endpoint, header := os.Getenv("IDENTITY_ENDPOINT"), os.Getenv("IDENTITY_HEADER")
+	var req *http.Request
+	var err error
+	if endpoint != "" && header != "" {
+		query.Set("api-version", "2019-08-01")
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, endpoint+"?"+query.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("X-IDENTITY-HEADER", header)
+	} else {
+		query.Set("api-version", "2018-02-01")
+		req, err = http.NewRequestWithContext(ctx, http.MethodGet, imdsTokenURL+"?"+query.Encode(), nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata", "true")
+	}
+
+	var token tokenResponse
+	if err := doJSON(client, req, &token); err != nil {
+		return "", fmt.Errorf("managed identity: %w", err)
+	}
+	return token.AccessToken, nil
+}
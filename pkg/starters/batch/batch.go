@@ -0,0 +1,159 @@
+// Package batch runs ETL-style jobs: a Job is a sequence of steps, typically chunked
+// reader-processor-writer steps, whose progress is stored in a JobRepository so that
+// a failed job restarts where it stopped instead of from the beginning
+package batch
+
+import (
+	"context"
+	"net/url"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Status is the status of a job or step execution
+type Status string
+
+const (
+	StatusStarted   Status = "STARTED"
+	StatusCompleted Status = "COMPLETED"
+	StatusFailed    Status = "FAILED"
+	StatusStopped   Status = "STOPPED"
+)
+
+// Parameters identify a job instance: a job completes once per set of parameters
+// and a failed execution is restarted by launching the job with the same parameters
+type Parameters map[string]string
+
+// key returns the canonical form of the parameters, sorted by name
+func (p Parameters) key() string {
+	values := make(url.Values, len(p))
+	for name, value := range p {
+		values.Set(name, value)
+	}
+	return values.Encode()
+}
+
+// JobExecution is one run of a job
+type JobExecution struct {
+	// ID is assigned by the repository
+	ID          int64
+	JobName     string
+	Parameters  Parameters
+	Status      Status
+	StartTime   time.Time
+	EndTime     time.Time
+	ExitMessage string
+	Steps       []*StepExecution
+}
+
+// Step returns the execution of a step, nil if the step didn't run yet
+func (e *JobExecution) Step(name string) *StepExecution {
+	for _, step := range e.Steps {
+		if step.Name == name {
+			return step
+		}
+	}
+	return nil
+}
+
+// StepExecution is the progress of a step; counts cover the committed chunks only
+type StepExecution struct {
+	Name        string
+	Status      Status
+	ReadCount   int
+	WriteCount  int
+	FilterCount int
+	SkipCount   int
+	RetryCount  int
+	CommitCount int
+	// Checkpoint is the state a step saved with its last commit, restored on restart
+	Checkpoint  map[string]string
+	StartTime   time.Time
+	EndTime     time.Time
+	ExitMessage string
+}
+
+// StepContext is passed to a running step
+type StepContext struct {
+	// Execution is the step's progress; on restart it holds the progress of the
+	// failed execution
+	Execution  *StepExecution
+	Parameters Parameters
+	save       func(ctx context.Context) error
+}
+
+// Commit stores the step's progress; a restart continues from the last commit
+func (c *StepContext) Commit(ctx context.Context) error {
+	c.Execution.CommitCount++
+	return c.save(ctx)
+}
+
+// Step is a unit of work of a job
+type Step interface {
+	Name() string
+	// Execute runs the step, committing its progress as it goes
+	Execute(ctx context.Context, step *StepContext) error
+}
+
+// Job is a component running its steps in order; a step that fails stops the job
+type Job struct {
+	name  string
+	steps []Step
+}
+
+// NewJob creates a job; register it as a component to launch it by name
+func NewJob(name string, steps ...Step) *Job {
+	return &Job{name: name, steps: steps}
+}
+
+// Name returns the component name
+func (j *Job) Name() string {
+	return j.name
+}
+
+// Init checks that the step names are unique, since progress is stored per step name
+func (j *Job) Init(container.ApplicationContext) error {
+	if len(j.steps) == 0 {
+		return container.ErrorWithCode("INVALID_JOB", "job %s has no steps", j.name)
+	}
+	seen := make(map[string]bool, len(j.steps))
+	for _, step := range j.steps {
+		if seen[step.Name()] {
+			return container.ErrorWithCode("INVALID_JOB", "job %s has several steps named %s", j.name, step.Name())
+		}
+		seen[step.Name()] = true
+	}
+	return nil
+}
+
+// Steps returns the steps of the job
+func (j *Job) Steps() []Step {
+	return j.steps
+}
+
+// taskletStep runs a function once
+type taskletStep struct {
+	name string
+	run  func(ctx context.Context, step *StepContext) error
+}
+
+// NewTaskletStep creates a step calling run once, e.g. to create a table or move a
+// file; a restart calls it again unless it completed
+func NewTaskletStep(name string, run func(ctx context.Context, step *StepContext) error) Step {
+	return &taskletStep{name: name, run: run}
+}
+
+func (s *taskletStep) Name() string {
+	return s.name
+}
+
+func (s *taskletStep) Execute(ctx context.Context, step *StepContext) error {
+	if err := s.run(ctx, step); err != nil {
+		return err
+	}
+	return step.Commit(ctx)
+}
+
+// Ensure that Job implements container.Component
+var _ container.Component = (*Job)(nil)
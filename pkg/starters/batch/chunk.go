@@ -0,0 +1,284 @@
+package batch
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"log/slog"
+	"time"
+)
+
+// ErrFiltered is returned by a processor to drop an item without counting it as skipped
+var ErrFiltered = errors.New("batch: item filtered")
+
+// ItemReader reads the input of a chunk step one item at a time, returning io.EOF
+// when the input is exhausted
+type ItemReader[T any] interface {
+	Read(ctx context.Context) (T, error)
+}
+
+// ItemProcessor transforms an item read into the item written
+type ItemProcessor[I, O any] interface {
+	Process(ctx context.Context, item I) (O, error)
+}
+
+// ItemWriter writes the items of a chunk. A chunk that failed is written again when
+// the step restarts, so writes should be idempotent.
+type ItemWriter[T any] interface {
+	Write(ctx context.Context, items []T) error
+}
+
+// CheckpointReader is an ItemReader that can resume from a checkpoint, e.g. the last
+// key or file offset read. A restarted step passes the checkpoint saved with the
+// last commit to Open; readers without checkpoints are read again from the
+// beginning and the items already committed are discarded.
+type CheckpointReader interface {
+	// Open positions the reader after the checkpoint, which is empty on the first run
+	Open(ctx context.Context, checkpoint map[string]string) error
+	// Checkpoint returns the position after the last item read
+	Checkpoint() map[string]string
+}
+
+// ReaderFunc adapts a function to an ItemReader
+type ReaderFunc[T any] func(ctx context.Context) (T, error)
+
+func (f ReaderFunc[T]) Read(ctx context.Context) (T, error) { return f(ctx) }
+
+// ProcessorFunc adapts a function to an ItemProcessor
+type ProcessorFunc[I, O any] func(ctx context.Context, item I) (O, error)
+
+func (f ProcessorFunc[I, O]) Process(ctx context.Context, item I) (O, error) { return f(ctx, item) }
+
+// WriterFunc adapts a function to an ItemWriter
+type WriterFunc[T any] func(ctx context.Context, items []T) error
+
+func (f WriterFunc[T]) Write(ctx context.Context, items []T) error { return f(ctx, items) }
+
+// ChunkConfig configures a chunk step
+type ChunkConfig struct {
+	// Size is the number of items read per chunk and commit (100 if zero)
+	Size int
+	// RetryLimit is how many times processing an item or writing a chunk is retried
+	RetryLimit int
+	// RetryBackoff is the delay between retries
+	RetryBackoff time.Duration
+	// Retryable decides whether an error is retried (all errors except context
+	// cancellation by default)
+	Retryable func(error) bool
+	// SkipLimit is how many items may be skipped before the step fails; an item is
+	// skipped when reading, processing or writing it fails after its retries
+	SkipLimit int
+	// Skippable decides whether a failed item may be skipped (all errors except
+	// context cancellation by default)
+	Skippable func(error) bool
+}
+
+func (c ChunkConfig) withDefaults() ChunkConfig {
+	if c.Size <= 0 {
+		c.Size = 100
+	}
+	if c.Retryable == nil {
+		c.Retryable = notCancelled
+	}
+	if c.Skippable == nil {
+		c.Skippable = notCancelled
+	}
+	return c
+}
+
+func notCancelled(err error) bool {
+	return !errors.Is(err, context.Canceled) && !errors.Is(err, context.DeadlineExceeded)
+}
+
+// chunkCounts are the counts of the chunk being processed, added to the step
+// execution when the chunk commits
+type chunkCounts struct {
+	read, written, filtered, skipped, retried int
+}
+
+// chunkStep reads, processes and writes items in chunks
+type chunkStep[I, O any] struct {
+	name      string
+	reader    ItemReader[I]
+	processor ItemProcessor[I, O]
+	writer    ItemWriter[O]
+	config    ChunkConfig
+	logger    *slog.Logger
+}
+
+// NewChunkStep creates a step reading items until io.EOF, processing each and
+// writing them in chunks of config.Size; progress is committed after each chunk. A
+// nil processor passes items through when I and O are the same type.
+func NewChunkStep[I, O any](name string, reader ItemReader[I], processor ItemProcessor[I, O], writer ItemWriter[O], config ChunkConfig) Step {
+	if processor == nil {
+		processor = ProcessorFunc[I, O](func(_ context.Context, item I) (O, error) {
+			out, ok := any(item).(O)
+			if !ok {
+				return out, fmt.Errorf("step %s has no processor converting %T", name, item)
+			}
+			return out, nil
+		})
+	}
+	return &chunkStep[I, O]{
+		name:      name,
+		reader:    reader,
+		processor: processor,
+		writer:    writer,
+		config:    config.withDefaults(),
+		logger:    slog.Default(),
+	}
+}
+
+func (s *chunkStep[I, O]) Name() string {
+	return s.name
+}
+
+func (s *chunkStep[I, O]) Execute(ctx context.Context, step *StepContext) error {
+	exec := step.Execution
+	if err := s.open(ctx, exec); err != nil {
+		return err
+	}
+
+	for {
+		if err := ctx.Err(); err != nil {
+			return err
+		}
+
+		var counts chunkCounts
+		var items []O
+		eof := false
+		for n := 0; n < s.config.Size; n++ {
+			item, err := s.reader.Read(ctx)
+			if errors.Is(err, io.EOF) {
+				eof = true
+				break
+			}
+			if err != nil {
+				if s.skip(exec, &counts, err) {
+					continue
+				}
+				return fmt.Errorf("read: %w", err)
+			}
+			counts.read++
+
+			var out O
+			err = s.retry(ctx, &counts, func() error {
+				var err error
+				out, err = s.processor.Process(ctx, item)
+				return err
+			})
+			if errors.Is(err, ErrFiltered) {
+				counts.filtered++
+				continue
+			}
+			if err != nil {
+				if s.skip(exec, &counts, err) {
+					continue
+				}
+				return fmt.Errorf("process: %w", err)
+			}
+			items = append(items, out)
+		}
+
+		if len(items) > 0 {
+			if err := s.write(ctx, exec, &counts, items); err != nil {
+				return fmt.Errorf("write: %w", err)
+			}
+		}
+
+		if counts != (chunkCounts{}) {
+			exec.ReadCount += counts.read
+			exec.WriteCount += counts.written
+			exec.FilterCount += counts.filtered
+			exec.SkipCount += counts.skipped
+			exec.RetryCount += counts.retried
+			if reader, ok := s.reader.(CheckpointReader); ok {
+				exec.Checkpoint = reader.Checkpoint()
+			}
+			if err := step.Commit(ctx); err != nil {
+				return fmt.Errorf("commit: %w", err)
+			}
+		}
+		if eof {
+			return nil
+		}
+	}
+}
+
+// open positions the reader after the items committed by a previous execution
+func (s *chunkStep[I, O]) open(ctx context.Context, exec *StepExecution) error {
+	if reader, ok := s.reader.(CheckpointReader); ok {
+		if err := reader.Open(ctx, exec.Checkpoint); err != nil {
+			return fmt.Errorf("open reader: %w", err)
+		}
+		return nil
+	}
+
+	for i := 0; i < exec.ReadCount; i++ {
+		if _, err := s.reader.Read(ctx); err != nil {
+			if errors.Is(err, io.EOF) {
+				return nil
+			}
+			return fmt.Errorf("skip to item %d: %w", exec.ReadCount, err)
+		}
+	}
+	return nil
+}
+
+// write writes a chunk; if it fails and items may be skipped, the items are written
+// one at a time to skip only the failing ones
+func (s *chunkStep[I, O]) write(ctx context.Context, exec *StepExecution, counts *chunkCounts, items []O) error {
+	err := s.retry(ctx, counts, func() error { return s.writer.Write(ctx, items) })
+	if err == nil {
+		counts.written += len(items)
+		return nil
+	}
+	if len(items) == 1 || s.config.SkipLimit <= 0 || !s.config.Skippable(err) {
+		if len(items) == 1 && s.skip(exec, counts, err) {
+			return nil
+		}
+		return err
+	}
+
+	s.logger.Warn("Chunk write failed, writing items one at a time", "step", s.name, "items", len(items), "error", err)
+	for i := range items {
+		item := items[i : i+1]
+		if err := s.retry(ctx, counts, func() error { return s.writer.Write(ctx, item) }); err != nil {
+			if s.skip(exec, counts, err) {
+				continue
+			}
+			return err
+		}
+		counts.written++
+	}
+	return nil
+}
+
+// retry calls fn until it succeeds, fails with an error that isn't retryable or
+// exhausts the retry limit
+func (s *chunkStep[I, O]) retry(ctx context.Context, counts *chunkCounts, fn func() error) error {
+	err := fn()
+	for attempt := 0; err != nil && attempt < s.config.RetryLimit && !errors.Is(err, ErrFiltered) && s.config.Retryable(err); attempt++ {
+		counts.retried++
+		if s.config.RetryBackoff > 0 {
+			select {
+			case <-ctx.Done():
+				return ctx.Err()
+			case <-time.After(s.config.RetryBackoff):
+			}
+		}
+		err = fn()
+	}
+	return err
+}
+
+// skip reports whether a failed item may be skipped, counting it if so
+func (s *chunkStep[I, O]) skip(exec *StepExecution, counts *chunkCounts, err error) bool {
+	if exec.SkipCount+counts.skipped >= s.config.SkipLimit || !s.config.Skippable(err) {
+		return false
+	}
+	counts.skipped++
+	s.logger.Warn("Skipping failed item", "step", s.name, "skipped", exec.SkipCount+counts.skipped, "error", err)
+	return true
+}
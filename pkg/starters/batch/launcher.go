@@ -0,0 +1,315 @@
+package batch
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Launcher runs the Job components. Launching a job whose last execution with the
+// same parameters failed or was stopped restarts it: completed steps are skipped
+// and the failed step continues from its last commit.
+type Launcher struct {
+	repository JobRepository
+	app        container.ApplicationContext
+	logger     *slog.Logger
+	now        func() time.Time
+
+	mu sync.Mutex
+	// running maps the instance keys of the running jobs to their execution
+	running map[string]*runningJob
+	wg      sync.WaitGroup
+}
+
+// runningJob is an execution in flight
+type runningJob struct {
+	id     int64
+	cancel context.CancelFunc
+}
+
+// NewLauncher creates a launcher storing executions in repository
+func NewLauncher(repository JobRepository) *Launcher {
+	return &Launcher{
+		repository: repository,
+		logger:     slog.Default(),
+		now:        time.Now,
+		running:    make(map[string]*runningJob),
+	}
+}
+
+// Name returns the component name
+func (l *Launcher) Name() string {
+	return "batchLauncher"
+}
+
+// Init keeps the application context to look jobs up
+func (l *Launcher) Init(app container.ApplicationContext) error {
+	l.app = app
+	return nil
+}
+
+// Start is a no-op; jobs are launched by Run and Launch
+func (l *Launcher) Start(context.Context) {}
+
+// Stop stops the running jobs and waits for them until ctx is done; stopped jobs
+// restart from their last commit when launched again
+func (l *Launcher) Stop(ctx context.Context) {
+	l.mu.Lock()
+	for _, job := range l.running {
+		job.cancel()
+	}
+	l.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		l.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+	case <-ctx.Done():
+		l.logger.Warn("Batch jobs still running after stop")
+	}
+}
+
+// Run runs a job and returns its execution once it completed, failed or was stopped
+func (l *Launcher) Run(ctx context.Context, jobName string, params Parameters) (*JobExecution, error) {
+	job, exec, ctx, err := l.prepare(ctx, jobName, params)
+	if err != nil {
+		return nil, err
+	}
+	return exec, l.execute(ctx, job, exec)
+}
+
+// Launch starts a job in the background and returns the ID of its execution
+func (l *Launcher) Launch(ctx context.Context, jobName string, params Parameters) (int64, error) {
+	job, exec, ctx, err := l.prepare(context.WithoutCancel(ctx), jobName, params)
+	if err != nil {
+		return 0, err
+	}
+	go func() {
+		_ = l.execute(ctx, job, exec)
+	}()
+	return exec.ID, nil
+}
+
+// StopExecution stops a running execution; it ends with StatusStopped
+func (l *Launcher) StopExecution(id int64) error {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	for _, job := range l.running {
+		if job.id == id {
+			job.cancel()
+			return nil
+		}
+	}
+	return container.ErrorWithCode("EXECUTION_NOT_RUNNING", "job execution %d is not running", id)
+}
+
+// Execution returns an execution by ID
+func (l *Launcher) Execution(ctx context.Context, id int64) (*JobExecution, error) {
+	return l.repository.Execution(ctx, id)
+}
+
+// prepare creates the execution of a job and registers it as running
+func (l *Launcher) prepare(ctx context.Context, jobName string, params Parameters) (*Job, *JobExecution, context.Context, error) {
+	comp, err := l.app.GetComponentByName(jobName)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	job, ok := comp.(*Job)
+	if !ok {
+		return nil, nil, nil, container.ComponentTypeError(jobName, "*batch.Job", fmt.Sprintf("%T", comp))
+	}
+	if params == nil {
+		params = Parameters{}
+	}
+
+	key := jobName + "?" + params.key()
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if _, running := l.running[key]; running {
+		return nil, nil, nil, container.ErrorWithCode("JOB_RUNNING", "job %s is already running with parameters %s", jobName, params.key())
+	}
+
+	last, err := l.repository.LastExecution(ctx, jobName, params)
+	if err != nil {
+		return nil, nil, nil, err
+	}
+	exec := &JobExecution{JobName: jobName, Parameters: params, Status: StatusStarted, StartTime: l.now()}
+	if last != nil {
+		switch last.Status {
+		case StatusCompleted:
+			return nil, nil, nil, container.ErrorWithCode("JOB_COMPLETE", "job %s already completed with parameters %s", jobName, params.key())
+		case StatusStarted:
+			// Not running in this process, so the process running it died
+			l.logger.Warn("Restarting job execution that didn't finish", "job", jobName, "execution", last.ID)
+		}
+		exec.Steps = last.Steps
+	}
+	if err := l.repository.CreateExecution(ctx, exec); err != nil {
+		return nil, nil, nil, err
+	}
+	for _, step := range exec.Steps {
+		if err := l.repository.SaveStep(ctx, exec, step); err != nil {
+			return nil, nil, nil, err
+		}
+	}
+	if last != nil {
+		l.logger.Info("Restarting job", "job", jobName, "execution", exec.ID, "previous", last.ID)
+	}
+
+	ctx, cancel := context.WithCancel(ctx)
+	l.running[key] = &runningJob{id: exec.ID, cancel: cancel}
+	l.wg.Add(1)
+	return job, exec, ctx, nil
+}
+
+// execute runs the steps of an execution that didn't complete yet
+func (l *Launcher) execute(ctx context.Context, job *Job, exec *JobExecution) error {
+	defer func() {
+		l.mu.Lock()
+		key := exec.JobName + "?" + exec.Parameters.key()
+		l.running[key].cancel()
+		delete(l.running, key)
+		l.mu.Unlock()
+		l.wg.Done()
+	}()
+
+	l.logger.Info("Job started", "job", exec.JobName, "execution", exec.ID)
+	var failure error
+	for _, step := range job.Steps() {
+		se := exec.Step(step.Name())
+		if se != nil && se.Status == StatusCompleted {
+			l.logger.Debug("Skipping completed step", "job", exec.JobName, "step", se.Name)
+			continue
+		}
+		if se == nil {
+			se = &StepExecution{Name: step.Name()}
+			exec.Steps = append(exec.Steps, se)
+		}
+
+		if failure = l.executeStep(ctx, step, exec, se); failure != nil {
+			exec.Status = se.Status
+			exec.ExitMessage = se.ExitMessage
+			failure = fmt.Errorf("job %s failed in step %s: %w", exec.JobName, se.Name, failure)
+			break
+		}
+	}
+	if failure == nil {
+		exec.Status = StatusCompleted
+	}
+	exec.EndTime = l.now()
+
+	// Record the outcome even when the job was stopped
+	if err := l.repository.UpdateExecution(context.WithoutCancel(ctx), exec); err != nil {
+		l.logger.Error("Failed to store job execution", "job", exec.JobName, "execution", exec.ID, "error", err)
+	}
+	l.logger.Info("Job finished",
+		"job", exec.JobName,
+		"execution", exec.ID,
+		"status", exec.Status,
+		"time_ms", exec.EndTime.Sub(exec.StartTime).Milliseconds())
+	return failure
+}
+
+// executeStep runs a step and stores its outcome
+func (l *Launcher) executeStep(ctx context.Context, step Step, exec *JobExecution, se *StepExecution) (err error) {
+	se.Status = StatusStarted
+	se.StartTime = l.now()
+	se.EndTime = time.Time{}
+	se.ExitMessage = ""
+	save := func(ctx context.Context) error { return l.repository.SaveStep(ctx, exec, se) }
+	if err := save(ctx); err != nil {
+		return err
+	}
+
+	defer func() {
+		if r := recover(); r != nil {
+			err = &container.PanicError{Value: r}
+		}
+		se.EndTime = l.now()
+		switch {
+		case err == nil:
+			se.Status = StatusCompleted
+		case ctx.Err() != nil:
+			se.Status = StatusStopped
+			se.ExitMessage = err.Error()
+		default:
+			se.Status = StatusFailed
+			se.ExitMessage = err.Error()
+		}
+		if saveErr := save(context.WithoutCancel(ctx)); saveErr != nil && err == nil {
+			err = saveErr
+		}
+	}()
+
+	return step.Execute(ctx, &StepContext{Execution: se, Parameters: exec.Parameters, save: save})
+}
+
+// Trigger is a scheduled component launching a job on every run. Its schedule can
+// be overridden in the batch.triggers.<job> section, e.g. batch.triggers.import.interval.
+type Trigger struct {
+	job      string
+	schedule container.Schedule
+	// Parameters returns the parameters of the run at t; by default run.time holds
+	// t, so that every run is a new job instance
+	Parameters func(t time.Time) Parameters
+
+	launcher *Launcher
+	logger   *slog.Logger
+}
+
+// NewTrigger creates a trigger for a job, named <job>Trigger
+func NewTrigger(jobName string, schedule container.Schedule) *Trigger {
+	if schedule.Property == "" {
+		schedule.Property = PropertyBatch + ".triggers." + jobName
+	}
+	return &Trigger{
+		job:      jobName,
+		schedule: schedule,
+		Parameters: func(t time.Time) Parameters {
+			return Parameters{"run.time": t.UTC().Format(time.RFC3339)}
+		},
+		logger: slog.Default(),
+	}
+}
+
+// Name returns the component name
+func (t *Trigger) Name() string {
+	return t.job + "Trigger"
+}
+
+// Init looks the launcher up
+func (t *Trigger) Init(app container.ApplicationContext) error {
+	return app.GetComponent(&t.launcher)
+}
+
+// Start is a no-op
+func (t *Trigger) Start(context.Context) {}
+
+// Stop is a no-op
+func (t *Trigger) Stop(context.Context) {}
+
+// GetSchedule returns the schedule of the job
+func (t *Trigger) GetSchedule() container.Schedule {
+	return t.schedule
+}
+
+// Execute runs the job, waiting for it to finish
+func (t *Trigger) Execute(ctx context.Context) {
+	if _, err := t.launcher.Run(ctx, t.job, t.Parameters(time.Now())); err != nil {
+		t.logger.Error("Scheduled job failed", "job", t.job, "error", err)
+	}
+}
+
+// Ensure that Launcher and Trigger implement the lifecycle interfaces
+var (
+	_ container.LifecycleComponent = (*Launcher)(nil)
+	_ container.ScheduledComponent = (*Trigger)(nil)
+)
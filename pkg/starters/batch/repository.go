@@ -0,0 +1,151 @@
+package batch
+
+import (
+	"context"
+	"sync"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// JobRepository stores job executions and the progress of their steps
+type JobRepository interface {
+	// LastExecution returns the latest execution of a job with the parameters, nil
+	// if there is none
+	LastExecution(ctx context.Context, jobName string, params Parameters) (*JobExecution, error)
+	// Execution returns an execution by ID
+	Execution(ctx context.Context, id int64) (*JobExecution, error)
+	// CreateExecution stores a new execution and assigns its ID
+	CreateExecution(ctx context.Context, exec *JobExecution) error
+	// UpdateExecution stores the status of an execution
+	UpdateExecution(ctx context.Context, exec *JobExecution) error
+	// SaveStep stores the progress of a step of an execution
+	SaveStep(ctx context.Context, exec *JobExecution, step *StepExecution) error
+}
+
+// MemoryRepository keeps executions in memory; jobs restart within the process only
+type MemoryRepository struct {
+	mu         sync.Mutex
+	executions []*JobExecution
+}
+
+// NewMemoryRepository creates an empty in-memory repository
+func NewMemoryRepository() *MemoryRepository {
+	return &MemoryRepository{}
+}
+
+// Name returns the component name
+func (r *MemoryRepository) Name() string {
+	return "batchRepository"
+}
+
+// Init is a no-op
+func (r *MemoryRepository) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// LastExecution returns a copy of the latest execution of a job with the parameters
+func (r *MemoryRepository) LastExecution(_ context.Context, jobName string, params Parameters) (*JobExecution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	key := params.key()
+	for i := len(r.executions) - 1; i >= 0; i-- {
+		if exec := r.executions[i]; exec.JobName == jobName && exec.Parameters.key() == key {
+			return copyExecution(exec), nil
+		}
+	}
+	return nil, nil
+}
+
+// Execution returns a copy of an execution
+func (r *MemoryRepository) Execution(_ context.Context, id int64) (*JobExecution, error) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if id <= 0 || int(id) > len(r.executions) {
+		return nil, container.ErrorWithCode("EXECUTION_NOT_FOUND", "job execution %d not found", id)
+	}
+	return copyExecution(r.executions[id-1]), nil
+}
+
+// CreateExecution stores a copy of a new execution
+func (r *MemoryRepository) CreateExecution(_ context.Context, exec *JobExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	exec.ID = int64(len(r.executions) + 1)
+	r.executions = append(r.executions, copyExecution(exec))
+	return nil
+}
+
+// UpdateExecution stores the status of an execution
+func (r *MemoryRepository) UpdateExecution(_ context.Context, exec *JobExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.stored(exec.ID)
+	if err != nil {
+		return err
+	}
+	stored.Status = exec.Status
+	stored.EndTime = exec.EndTime
+	stored.ExitMessage = exec.ExitMessage
+	return nil
+}
+
+// SaveStep stores a copy of the progress of a step
+func (r *MemoryRepository) SaveStep(_ context.Context, exec *JobExecution, step *StepExecution) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	stored, err := r.stored(exec.ID)
+	if err != nil {
+		return err
+	}
+	saved := copyStep(step)
+	for i, existing := range stored.Steps {
+		if existing.Name == step.Name {
+			stored.Steps[i] = saved
+			return nil
+		}
+	}
+	stored.Steps = append(stored.Steps, saved)
+	return nil
+}
+
+// stored returns the stored execution; the lock must be held
+func (r *MemoryRepository) stored(id int64) (*JobExecution, error) {
+	if id <= 0 || int(id) > len(r.executions) {
+		return nil, container.ErrorWithCode("EXECUTION_NOT_FOUND", "job execution %d not found", id)
+	}
+	return r.executions[id-1], nil
+}
+
+// copyExecution returns a deep copy of an execution
+func copyExecution(exec *JobExecution) *JobExecution {
+	copied := *exec
+	copied.Parameters = make(Parameters, len(exec.Parameters))
+	for name, value := range exec.Parameters {
+		copied.Parameters[name] = value
+	}
+	copied.Steps = make([]*StepExecution, len(exec.Steps))
+	for i, step := range exec.Steps {
+		copied.Steps[i] = copyStep(step)
+	}
+	return &copied
+}
+
+// copyStep returns a deep copy of a step execution
+func copyStep(step *StepExecution) *StepExecution {
+	copied := *step
+	if step.Checkpoint != nil {
+		copied.Checkpoint = make(map[string]string, len(step.Checkpoint))
+		for name, value := range step.Checkpoint {
+			copied.Checkpoint[name] = value
+		}
+	}
+	return &copied
+}
+
+// Ensure that MemoryRepository implements JobRepository
+var _ JobRepository = (*MemoryRepository)(nil)
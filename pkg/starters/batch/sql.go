@@ -0,0 +1,267 @@
+package batch
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/internal/sqldialect"
+)
+
+// Supported SQL dialects
+const (
+	DialectPostgres = sqldialect.Postgres
+	DialectMySQL    = sqldialect.MySQL
+	DialectSQLite   = sqldialect.SQLite
+)
+
+// SQLRepository stores executions in the tables <prefix>job_execution and
+// <prefix>step_execution, so that failed jobs restart after the process restarts
+type SQLRepository struct {
+	db           *sql.DB
+	prefix       string
+	dialect      string
+	createTables bool
+}
+
+// NewSQLRepository creates a repository using the dialect's placeholders; with
+// createTables the tables are created on Init if they don't exist
+func NewSQLRepository(db *sql.DB, prefix, dialect string, createTables bool) *SQLRepository {
+	return &SQLRepository{db: db, prefix: prefix, dialect: dialect, createTables: createTables}
+}
+
+// Name returns the component name
+func (r *SQLRepository) Name() string {
+	return "batchRepository"
+}
+
+// Init creates the tables if configured
+func (r *SQLRepository) Init(container.ApplicationContext) error {
+	if !r.createTables {
+		return nil
+	}
+	statements, err := Schema(r.prefix, r.dialect)
+	if err != nil {
+		return err
+	}
+	for _, ddl := range statements {
+		if _, err := r.db.Exec(ddl); err != nil {
+			return fmt.Errorf("create batch tables: %w", err)
+		}
+	}
+	return nil
+}
+
+const jobColumns = "id, job_name, parameters, status, start_time, end_time, exit_message"
+
+// LastExecution returns the latest execution of a job with the parameters
+func (r *SQLRepository) LastExecution(ctx context.Context, jobName string, params Parameters) (*JobExecution, error) {
+	query := fmt.Sprintf("SELECT %s FROM %sjob_execution WHERE job_name = %s AND job_key = %s ORDER BY id DESC LIMIT 1",
+		jobColumns, r.prefix, sqldialect.Placeholder(r.dialect, 1), sqldialect.Placeholder(r.dialect, 2))
+	exec, err := r.queryExecution(ctx, query, jobName, params.key())
+	if err == sql.ErrNoRows {
+		return nil, nil
+	}
+	return exec, err
+}
+
+// Execution returns an execution by ID
+func (r *SQLRepository) Execution(ctx context.Context, id int64) (*JobExecution, error) {
+	query := fmt.Sprintf("SELECT %s FROM %sjob_execution WHERE id = %s", jobColumns, r.prefix, sqldialect.Placeholder(r.dialect, 1))
+	exec, err := r.queryExecution(ctx, query, id)
+	if err == sql.ErrNoRows {
+		return nil, container.ErrorWithCode("EXECUTION_NOT_FOUND", "job execution %d not found", id)
+	}
+	return exec, err
+}
+
+// CreateExecution inserts an execution and assigns its ID
+func (r *SQLRepository) CreateExecution(ctx context.Context, exec *JobExecution) error {
+	params, err := json.Marshal(exec.Parameters)
+	if err != nil {
+		return fmt.Errorf("encode job parameters: %w", err)
+	}
+
+	query := fmt.Sprintf("INSERT INTO %sjob_execution (job_name, job_key, parameters, status, start_time, end_time, exit_message) VALUES (%s)",
+		r.prefix, sqldialect.Placeholders(r.dialect, 1, 7))
+	args := []any{exec.JobName, exec.Parameters.key(), string(params), string(exec.Status), exec.StartTime.UTC(), nullTime(exec.EndTime), exec.ExitMessage}
+
+	// Postgres drivers don't support LastInsertId
+	if r.dialect == DialectPostgres {
+		if err := r.db.QueryRowContext(ctx, query+" RETURNING id", args...).Scan(&exec.ID); err != nil {
+			return fmt.Errorf("insert job execution: %w", err)
+		}
+		return nil
+	}
+	result, err := r.db.ExecContext(ctx, query, args...)
+	if err != nil {
+		return fmt.Errorf("insert job execution: %w", err)
+	}
+	if exec.ID, err = result.LastInsertId(); err != nil {
+		return fmt.Errorf("insert job execution: %w", err)
+	}
+	return nil
+}
+
+// UpdateExecution stores the status of an execution
+func (r *SQLRepository) UpdateExecution(ctx context.Context, exec *JobExecution) error {
+	d := r.dialect
+	query := fmt.Sprintf("UPDATE %sjob_execution SET status = %s, end_time = %s, exit_message = %s WHERE id = %s",
+		r.prefix, sqldialect.Placeholder(d, 1), sqldialect.Placeholder(d, 2), sqldialect.Placeholder(d, 3), sqldialect.Placeholder(d, 4))
+	if _, err := r.db.ExecContext(ctx, query, string(exec.Status), nullTime(exec.EndTime), exec.ExitMessage, exec.ID); err != nil {
+		return fmt.Errorf("update job execution %d: %w", exec.ID, err)
+	}
+	return nil
+}
+
+// SaveStep updates the progress of a step, inserting it on the first save
+func (r *SQLRepository) SaveStep(ctx context.Context, exec *JobExecution, step *StepExecution) error {
+	checkpoint, err := json.Marshal(step.Checkpoint)
+	if err != nil {
+		return fmt.Errorf("encode checkpoint of step %s: %w", step.Name, err)
+	}
+	values := []any{
+		string(step.Status), step.ReadCount, step.WriteCount, step.FilterCount, step.SkipCount, step.RetryCount,
+		step.CommitCount, string(checkpoint), step.StartTime.UTC(), nullTime(step.EndTime), step.ExitMessage,
+	}
+
+	d := r.dialect
+	query := fmt.Sprintf(`UPDATE %sstep_execution SET status = %s, read_count = %s, write_count = %s, filter_count = %s,
+	skip_count = %s, retry_count = %s, commit_count = %s, checkpoint = %s, start_time = %s, end_time = %s, exit_message = %s
+	WHERE job_execution_id = %s AND step_name = %s`,
+		r.prefix, sqldialect.Placeholder(d, 1), sqldialect.Placeholder(d, 2), sqldialect.Placeholder(d, 3),
+		sqldialect.Placeholder(d, 4), sqldialect.Placeholder(d, 5), sqldialect.Placeholder(d, 6), sqldialect.Placeholder(d, 7),
+		sqldialect.Placeholder(d, 8), sqldialect.Placeholder(d, 9), sqldialect.Placeholder(d, 10), sqldialect.Placeholder(d, 11),
+		sqldialect.Placeholder(d, 12), sqldialect.Placeholder(d, 13))
+	result, err := r.db.ExecContext(ctx, query, append(values, exec.ID, step.Name)...)
+	if err != nil {
+		return fmt.Errorf("update step %s of job execution %d: %w", step.Name, exec.ID, err)
+	}
+	if updated, err := result.RowsAffected(); err == nil && updated > 0 {
+		return nil
+	}
+
+	query = fmt.Sprintf(`INSERT INTO %sstep_execution (job_execution_id, step_name, status, read_count, write_count, filter_count,
+	skip_count, retry_count, commit_count, checkpoint, start_time, end_time, exit_message) VALUES (%s)`,
+		r.prefix, sqldialect.Placeholders(d, 1, 13))
+	if _, err := r.db.ExecContext(ctx, query, append([]any{exec.ID, step.Name}, values...)...); err != nil {
+		return fmt.Errorf("insert step %s of job execution %d: %w", step.Name, exec.ID, err)
+	}
+	return nil
+}
+
+// queryExecution reads one execution and its steps
+func (r *SQLRepository) queryExecution(ctx context.Context, query string, args ...any) (*JobExecution, error) {
+	var exec JobExecution
+	var params, status string
+	var end sql.NullTime
+	var message sql.NullString
+	err := r.db.QueryRowContext(ctx, query, args...).
+		Scan(&exec.ID, &exec.JobName, &params, &status, &exec.StartTime, &end, &message)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, err
+		}
+		return nil, fmt.Errorf("query job execution: %w", err)
+	}
+	if err := json.Unmarshal([]byte(params), &exec.Parameters); err != nil {
+		return nil, fmt.Errorf("decode parameters of job execution %d: %w", exec.ID, err)
+	}
+	exec.Status = Status(status)
+	exec.EndTime = end.Time
+	exec.ExitMessage = message.String
+
+	if exec.Steps, err = r.querySteps(ctx, exec.ID); err != nil {
+		return nil, err
+	}
+	return &exec, nil
+}
+
+func (r *SQLRepository) querySteps(ctx context.Context, id int64) ([]*StepExecution, error) {
+	query := fmt.Sprintf(`SELECT step_name, status, read_count, write_count, filter_count, skip_count, retry_count, commit_count,
+	checkpoint, start_time, end_time, exit_message FROM %sstep_execution WHERE job_execution_id = %s`,
+		r.prefix, sqldialect.Placeholder(r.dialect, 1))
+	rows, err := r.db.QueryContext(ctx, query, id)
+	if err != nil {
+		return nil, fmt.Errorf("query steps of job execution %d: %w", id, err)
+	}
+	defer rows.Close()
+
+	var steps []*StepExecution
+	for rows.Next() {
+		var step StepExecution
+		var status, checkpoint string
+		var end sql.NullTime
+		var message sql.NullString
+		if err := rows.Scan(&step.Name, &status, &step.ReadCount, &step.WriteCount, &step.FilterCount, &step.SkipCount,
+			&step.RetryCount, &step.CommitCount, &checkpoint, &step.StartTime, &end, &message); err != nil {
+			return nil, fmt.Errorf("scan step of job execution %d: %w", id, err)
+		}
+		if err := json.Unmarshal([]byte(checkpoint), &step.Checkpoint); err != nil {
+			return nil, fmt.Errorf("decode checkpoint of step %s: %w", step.Name, err)
+		}
+		step.Status = Status(status)
+		step.EndTime = end.Time
+		step.ExitMessage = message.String
+		steps = append(steps, &step)
+	}
+	return steps, rows.Err()
+}
+
+// Schema returns the DDL creating the batch tables for a dialect
+func Schema(prefix, dialect string) ([]string, error) {
+	var id, timestamp string
+	switch dialect {
+	case DialectPostgres:
+		id, timestamp = "BIGSERIAL PRIMARY KEY", "TIMESTAMPTZ"
+	case DialectMySQL:
+		id, timestamp = "BIGINT AUTO_INCREMENT PRIMARY KEY", "DATETIME(6)"
+	case DialectSQLite:
+		id, timestamp = "INTEGER PRIMARY KEY AUTOINCREMENT", "TIMESTAMP"
+	default:
+		return nil, fmt.Errorf("unsupported batch dialect %q", dialect)
+	}
+
+	return []string{
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %sjob_execution (
+	id %s,
+	job_name VARCHAR(255) NOT NULL,
+	job_key TEXT NOT NULL,
+	parameters TEXT NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	start_time %s NOT NULL,
+	end_time %s,
+	exit_message TEXT
+)`, prefix, id, timestamp, timestamp),
+		fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %sstep_execution (
+	job_execution_id BIGINT NOT NULL,
+	step_name VARCHAR(255) NOT NULL,
+	status VARCHAR(20) NOT NULL,
+	read_count INTEGER NOT NULL,
+	write_count INTEGER NOT NULL,
+	filter_count INTEGER NOT NULL,
+	skip_count INTEGER NOT NULL,
+	retry_count INTEGER NOT NULL,
+	commit_count INTEGER NOT NULL,
+	checkpoint TEXT NOT NULL,
+	start_time %s NOT NULL,
+	end_time %s,
+	exit_message TEXT,
+	PRIMARY KEY (job_execution_id, step_name)
+)`, prefix, timestamp, timestamp),
+	}, nil
+}
+
+// nullTime returns nil for the zero time, stored as NULL
+func nullTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+	return t.UTC()
+}
+
+// Ensure that SQLRepository implements JobRepository
+var _ JobRepository = (*SQLRepository)(nil)
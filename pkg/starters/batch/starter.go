@@ -0,0 +1,84 @@
+package batch
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyBatch holds the batch configuration: batch.*
+const PropertyBatch = "batch"
+
+// Repository types
+const (
+	RepositoryMemory = "memory"
+	RepositorySQL    = "sql"
+)
+
+// Config configures the batch starter: batch.*
+type Config struct {
+	// Repository is memory (default) or sql, which needs a *sql.DB instance
+	Repository string `yaml:"repository"`
+	// TablePrefix prefixes the names of the SQL tables
+	TablePrefix string `yaml:"table-prefix"`
+	Dialect     string `yaml:"dialect"`
+	// CreateTables creates the SQL tables on startup if they don't exist
+	CreateTables bool `yaml:"create-tables"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Repository == "" {
+		c.Repository = RepositoryMemory
+	}
+	if c.TablePrefix == "" {
+		c.TablePrefix = "batch_"
+	}
+	if c.Dialect == "" {
+		c.Dialect = DialectPostgres
+	}
+	return c
+}
+
+// Starter registers a JobRepository and a Launcher when batch.enabled is true. Jobs
+// and their triggers are registered in the setup block:
+//
+//	builder.RegisterComponent(batch.NewJob("import",
+//		batch.NewChunkStep[Row, Customer]("load", reader, processor, writer, batch.ChunkConfig{Size: 500, SkipLimit: 10}),
+//	))
+//	builder.RegisterComponent(batch.NewTrigger("import", container.Schedule{Interval: time.Hour}))
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"BatchStarter",
+		container.PropertyCondition(PropertyBatch+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertyBatch, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyBatch, err)
+			}
+			config = config.withDefaults()
+
+			var repository interface {
+				container.Component
+				JobRepository
+			}
+			switch config.Repository {
+			case RepositoryMemory:
+				repository = NewMemoryRepository()
+			case RepositorySQL:
+				var db *sql.DB
+				if err := builder.GetComponent(&db); err != nil {
+					return fmt.Errorf("batch sql repository requires a *sql.DB: %w", err)
+				}
+				repository = NewSQLRepository(db, config.TablePrefix, config.Dialect, config.CreateTables)
+			default:
+				return fmt.Errorf("unsupported batch repository %q", config.Repository)
+			}
+
+			if err := builder.RegisterComponent(repository); err != nil {
+				return err
+			}
+			return builder.RegisterComponent(NewLauncher(repository))
+		},
+	)
+}
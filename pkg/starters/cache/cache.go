@@ -0,0 +1,111 @@
+package cache
+
+import (
+	"context"
+	"fmt"
+	"reflect"
+	"sync/atomic"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Cache stores values by key with an optional time to live
+type Cache interface {
+	container.Component
+	// Get copies the cached value into target (a pointer) and reports whether it was found
+	Get(ctx context.Context, key string, target interface{}) (bool, error)
+	// Set stores a value; a zero ttl uses the cache's default TTL
+	Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error
+	// Delete removes a value
+	Delete(ctx context.Context, key string) error
+	// GetOrLoad copies the cached value into target, or calls load, caches its result and copies it into target
+	GetOrLoad(ctx context.Context, key string, target interface{}, ttl time.Duration, load func(context.Context) (interface{}, error)) error
+	// Metrics returns a snapshot of the cache counters
+	Metrics() Metrics
+}
+
+// Metrics is a snapshot of cache counters
+type Metrics struct {
+	Name      string
+	Hits      int64
+	Misses    int64
+	Sets      int64
+	Deletes   int64
+	Evictions int64
+}
+
+// HitRatio returns hits / (hits + misses), or 0 if there were no lookups
+func (m Metrics) HitRatio() float64 {
+	if m.Hits+m.Misses == 0 {
+		return 0
+	}
+	return float64(m.Hits) / float64(m.Hits+m.Misses)
+}
+
+// counters tracks cache metrics
+type counters struct {
+	hits      atomic.Int64
+	misses    atomic.Int64
+	sets      atomic.Int64
+	deletes   atomic.Int64
+	evictions atomic.Int64
+}
+
+func (c *counters) snapshot(name string) Metrics {
+	return Metrics{
+		Name:      name,
+		Hits:      c.hits.Load(),
+		Misses:    c.misses.Load(),
+		Sets:      c.sets.Load(),
+		Deletes:   c.deletes.Load(),
+		Evictions: c.evictions.Load(),
+	}
+}
+
+// getOrLoad implements GetOrLoad on top of Get and Set
+func getOrLoad(ctx context.Context, c Cache, key string, target interface{}, ttl time.Duration, load func(context.Context) (interface{}, error)) error {
+	found, err := c.Get(ctx, key, target)
+	if err != nil || found {
+		return err
+	}
+
+	value, err := load(ctx)
+	if err != nil {
+		return err
+	}
+	if err := c.Set(ctx, key, value, ttl); err != nil {
+		return err
+	}
+	return assign(target, value)
+}
+
+// assign copies value into the target pointer
+func assign(target interface{}, value interface{}) error {
+	targetValue := reflect.ValueOf(target)
+	if targetValue.Kind() != reflect.Ptr || targetValue.IsNil() {
+		return fmt.Errorf("cache target must be a non-nil pointer")
+	}
+
+	elem := targetValue.Elem()
+	if value == nil {
+		elem.Set(reflect.Zero(elem.Type()))
+		return nil
+	}
+
+	v := reflect.ValueOf(value)
+	switch {
+	case v.Type().AssignableTo(elem.Type()):
+		elem.Set(v)
+	case v.Kind() == reflect.Ptr && v.Elem().Type().AssignableTo(elem.Type()):
+		elem.Set(v.Elem())
+	default:
+		return fmt.Errorf("cached value of type %T can't be assigned to %s", value, elem.Type())
+	}
+	return nil
+}
+
+// componentName returns the container name of a cache
+func componentName(name string) string {
+	return "cache." + name
+}
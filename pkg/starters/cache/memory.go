@@ -0,0 +1,147 @@
+package cache
+
+import (
+	"container/list"
+	"context"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// MemoryCache is an in-process LRU cache with per-entry expiry
+type MemoryCache struct {
+	name       string
+	maxEntries int
+	defaultTTL time.Duration
+	now        func() time.Time
+
+	mu      sync.Mutex
+	entries map[string]*list.Element
+	lru     *list.List
+	counters
+}
+
+// memoryEntry is an LRU list element
+type memoryEntry struct {
+	key     string
+	value   interface{}
+	expires time.Time
+}
+
+// NewMemoryCache creates an LRU cache; maxEntries <= 0 means unbounded
+func NewMemoryCache(name string, maxEntries int, defaultTTL time.Duration) *MemoryCache {
+	return &MemoryCache{
+		name:       name,
+		maxEntries: maxEntries,
+		defaultTTL: defaultTTL,
+		now:        time.Now,
+		entries:    make(map[string]*list.Element),
+		lru:        list.New(),
+	}
+}
+
+// Name returns the component name
+func (c *MemoryCache) Name() string {
+	return componentName(c.name)
+}
+
+// Init is a no-op: caches are configured by the starter
+func (c *MemoryCache) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Get copies the cached value into target
+func (c *MemoryCache) Get(_ context.Context, key string, target interface{}) (bool, error) {
+	c.mu.Lock()
+	elem, ok := c.entries[key]
+	if ok {
+		entry := elem.Value.(*memoryEntry)
+		if !entry.expires.IsZero() && !c.now().Before(entry.expires) {
+			c.removeElement(elem)
+			ok = false
+		} else {
+			c.lru.MoveToFront(elem)
+		}
+	}
+	var value interface{}
+	if ok {
+		value = elem.Value.(*memoryEntry).value
+	}
+	c.mu.Unlock()
+
+	if !ok {
+		c.misses.Add(1)
+		return false, nil
+	}
+	c.hits.Add(1)
+	return true, assign(target, value)
+}
+
+// Set stores a value, evicting the least recently used entry if full
+func (c *MemoryCache) Set(_ context.Context, key string, value interface{}, ttl time.Duration) error {
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+	var expires time.Time
+	if ttl > 0 {
+		expires = c.now().Add(ttl)
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	c.sets.Add(1)
+	if elem, ok := c.entries[key]; ok {
+		entry := elem.Value.(*memoryEntry)
+		entry.value = value
+		entry.expires = expires
+		c.lru.MoveToFront(elem)
+		return nil
+	}
+
+	c.entries[key] = c.lru.PushFront(&memoryEntry{key: key, value: value, expires: expires})
+	if c.maxEntries > 0 && c.lru.Len() > c.maxEntries {
+		c.removeElement(c.lru.Back())
+		c.evictions.Add(1)
+	}
+	return nil
+}
+
+// Delete removes a value
+func (c *MemoryCache) Delete(_ context.Context, key string) error {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+
+	if elem, ok := c.entries[key]; ok {
+		c.removeElement(elem)
+		c.deletes.Add(1)
+	}
+	return nil
+}
+
+// GetOrLoad returns the cached value or loads and caches it
+func (c *MemoryCache) GetOrLoad(ctx context.Context, key string, target interface{}, ttl time.Duration, load func(context.Context) (interface{}, error)) error {
+	return getOrLoad(ctx, c, key, target, ttl, load)
+}
+
+// Len returns the number of entries, including expired ones not yet evicted
+func (c *MemoryCache) Len() int {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	return c.lru.Len()
+}
+
+// Metrics returns a snapshot of the cache counters
+func (c *MemoryCache) Metrics() Metrics {
+	return c.snapshot(c.name)
+}
+
+// removeElement must be called with the lock held
+func (c *MemoryCache) removeElement(elem *list.Element) {
+	c.lru.Remove(elem)
+	delete(c.entries, elem.Value.(*memoryEntry).key)
+}
+
+// Ensure that MemoryCache implements Cache
+var _ Cache = (*MemoryCache)(nil)
@@ -0,0 +1,132 @@
+package cache
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// ErrNotFound is returned by a RedisClient when a key doesn't exist
+var ErrNotFound = errors.New("cache key not found")
+
+// RedisClient is the subset of a Redis client used by RedisCache. Register an
+// adapter around your client (e.g. go-redis) with builder.RegisterInstance and
+// the starter will find it by type.
+type RedisClient interface {
+	// Get returns the value of key or ErrNotFound
+	Get(ctx context.Context, key string) ([]byte, error)
+	// Set stores value with the given expiration (0 means no expiration)
+	Set(ctx context.Context, key string, value []byte, ttl time.Duration) error
+	// Del removes key
+	Del(ctx context.Context, key string) error
+}
+
+// Pinger is implemented by RedisClient adapters that can check the connection,
+// e.g. with the PING command
+type Pinger interface {
+	Ping(ctx context.Context) error
+}
+
+// Ping checks that Redis is reachable: with Ping if the client is a Pinger, else
+// by reading a key that doesn't exist
+func Ping(ctx context.Context, client RedisClient) error {
+	if pinger, ok := client.(Pinger); ok {
+		return pinger.Ping(ctx)
+	}
+	if _, err := client.Get(ctx, "goboot:ping"); err != nil && !errors.Is(err, ErrNotFound) {
+		return err
+	}
+	return nil
+}
+
+// RedisCache stores JSON-encoded values in Redis
+type RedisCache struct {
+	name       string
+	client     RedisClient
+	prefix     string
+	defaultTTL time.Duration
+	counters
+}
+
+// NewRedisCache creates a Redis-backed cache; keys are prefixed with prefix
+func NewRedisCache(name string, client RedisClient, prefix string, defaultTTL time.Duration) *RedisCache {
+	return &RedisCache{
+		name:       name,
+		client:     client,
+		prefix:     prefix,
+		defaultTTL: defaultTTL,
+	}
+}
+
+// Name returns the component name
+func (c *RedisCache) Name() string {
+	return componentName(c.name)
+}
+
+// Init is a no-op: caches are configured by the starter
+func (c *RedisCache) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Get decodes the cached value into target
+func (c *RedisCache) Get(ctx context.Context, key string, target interface{}) (bool, error) {
+	data, err := c.client.Get(ctx, c.prefix+key)
+	if errors.Is(err, ErrNotFound) {
+		c.misses.Add(1)
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	c.hits.Add(1)
+	return true, json.Unmarshal(data, target)
+}
+
+// Set encodes and stores a value
+func (c *RedisCache) Set(ctx context.Context, key string, value interface{}, ttl time.Duration) error {
+	if ttl == 0 {
+		ttl = c.defaultTTL
+	}
+	if ttl < 0 {
+		ttl = 0
+	}
+
+	data, err := json.Marshal(value)
+	if err != nil {
+		return err
+	}
+
+	c.sets.Add(1)
+	return c.client.Set(ctx, c.prefix+key, data, ttl)
+}
+
+// Delete removes a value
+func (c *RedisCache) Delete(ctx context.Context, key string) error {
+	c.deletes.Add(1)
+	return c.client.Del(ctx, c.prefix+key)
+}
+
+// GetOrLoad returns the cached value or loads and caches it
+func (c *RedisCache) GetOrLoad(ctx context.Context, key string, target interface{}, ttl time.Duration, load func(context.Context) (interface{}, error)) error {
+	return getOrLoad(ctx, c, key, target, ttl, load)
+}
+
+// WarmUp checks that Redis is reachable
+func (c *RedisCache) WarmUp(ctx context.Context) error {
+	return Ping(ctx, c.client)
+}
+
+// Metrics returns a snapshot of the cache counters
+func (c *RedisCache) Metrics() Metrics {
+	return c.snapshot(c.name)
+}
+
+// Ensure that RedisCache implements Cache and container.WarmUpComponent
+var (
+	_ Cache                     = (*RedisCache)(nil)
+	_ container.WarmUpComponent = (*RedisCache)(nil)
+)
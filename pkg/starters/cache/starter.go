@@ -0,0 +1,83 @@
+// Package cache provides a Cache abstraction with in-memory LRU and Redis backends
+package cache
+
+import (
+	"fmt"
+	"sort"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyCaches holds per-cache configuration:
+// cache.caches.<name>.backend / max-entries / ttl / prefix
+const PropertyCaches = "cache.caches"
+
+// Supported backends
+const (
+	BackendMemory = "memory"
+	BackendRedis  = "redis"
+)
+
+// Config configures a cache
+type Config struct {
+	// Backend is memory (default) or redis
+	Backend string `yaml:"backend"`
+	// MaxEntries bounds an in-memory cache (0 means unbounded)
+	MaxEntries int `yaml:"max-entries"`
+	// TTL is the default time to live (0 means no expiry)
+	TTL time.Duration `yaml:"ttl"`
+	// Prefix is prepended to Redis keys (defaults to "<name>:")
+	Prefix string `yaml:"prefix"`
+}
+
+// Starter registers a Cache component named "cache.<name>" for each configured
+// cache. Redis caches require a RedisClient component or instance in the container.
+func Starter() container.Starter {
+	return container.NewStarter("CacheStarter", func(builder container.ContextBuilder) error {
+		if !container.NewVariableHelper(builder).HasSection(PropertyCaches) {
+			return nil
+		}
+
+		configs := map[string]Config{}
+		if err := builder.GetVariableAs(PropertyCaches, &configs); err != nil {
+			return fmt.Errorf("invalid %s configuration: %w", PropertyCaches, err)
+		}
+
+		names := make([]string, 0, len(configs))
+		for name := range configs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			c, err := newCache(builder, name, configs[name])
+			if err != nil {
+				return err
+			}
+			if err := builder.RegisterComponent(c); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
+
+func newCache(ctx container.ApplicationContext, name string, config Config) (Cache, error) {
+	switch config.Backend {
+	case "", BackendMemory:
+		return NewMemoryCache(name, config.MaxEntries, config.TTL), nil
+	case BackendRedis:
+		var client RedisClient
+		if err := ctx.GetComponent(&client); err != nil {
+			return nil, fmt.Errorf("cache %s: redis backend requires a RedisClient: %w", name, err)
+		}
+		prefix := config.Prefix
+		if prefix == "" {
+			prefix = name + ":"
+		}
+		return NewRedisCache(name, client, prefix, config.TTL), nil
+	default:
+		return nil, fmt.Errorf("cache %s: unknown backend %q", name, config.Backend)
+	}
+}
@@ -0,0 +1,181 @@
+// Package chaos injects faults into the lifecycle of components for resilience
+// tests: delayed starts, panicking scheduled executions and dropped event
+// deliveries
+package chaos
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/events"
+)
+
+// PropertyChaos holds the chaos configuration: chaos.*
+const PropertyChaos = "chaos"
+
+// MonkeyName is the Monkey component
+const MonkeyName = "chaosMonkey"
+
+// DefaultProfile is the profile enabling the faults when chaos.profiles is not set
+const DefaultProfile = "chaos"
+
+// Config configures the faults: chaos.*
+type Config struct {
+	// Enabled turns the faults on (default true when the chaos section exists)
+	Enabled *bool `yaml:"enabled"`
+	// Profiles injects the faults only when one of them is active (default chaos)
+	Profiles []string `yaml:"profiles"`
+	// Components are the faults of components by name
+	Components map[string]ComponentConfig `yaml:"components"`
+	Events     EventsConfig               `yaml:"events"`
+}
+
+// ComponentConfig configures the faults of a component: chaos.components.<name>.*
+type ComponentConfig struct {
+	// StartDelay delays the Start of the component
+	StartDelay time.Duration `yaml:"start-delay"`
+	// PanicEvery panics every Nth execution of the scheduled component
+	PanicEvery int `yaml:"panic-every"`
+}
+
+// EventsConfig configures the dropped event deliveries: chaos.events.*
+type EventsConfig struct {
+	// DropEvery drops every Nth delivery to a listener
+	DropEvery int `yaml:"drop-every"`
+	// DropRate drops this fraction of the deliveries at random, from 0 to 1
+	DropRate float64 `yaml:"drop-rate"`
+	// Types limits the dropped deliveries to events of these types, as printed by
+	// %T, e.g. orders.OrderPlaced (default all events)
+	Types []string `yaml:"types"`
+}
+
+func (c Config) withDefaults() Config {
+	if len(c.Profiles) == 0 {
+		c.Profiles = []string{DefaultProfile}
+	}
+	return c
+}
+
+func (c Config) validate() error {
+	for name, component := range c.Components {
+		if component.StartDelay < 0 || component.PanicEvery < 0 {
+			return fmt.Errorf("invalid %s.components.%s: negative start-delay or panic-every", PropertyChaos, name)
+		}
+	}
+	if c.Events.DropEvery < 0 || c.Events.DropRate < 0 || c.Events.DropRate > 1 {
+		return fmt.Errorf("invalid %s.events: drop-every must be positive and drop-rate between 0 and 1", PropertyChaos)
+	}
+	return nil
+}
+
+// Monkey injects the configured faults. It is a container.LifecycleInterceptor
+// delaying starts and panicking scheduled executions, and it adds a delivery
+// filter to the events.EventBus, if any, dropping deliveries.
+type Monkey struct {
+	config Config
+	logger *slog.Logger
+
+	mu         sync.Mutex
+	executions map[string]int
+	deliveries int
+	random     *rand.Rand
+}
+
+// NewMonkey creates a monkey injecting the faults of the configuration
+func NewMonkey(config Config) *Monkey {
+	return &Monkey{
+		config:     config.withDefaults(),
+		logger:     slog.Default(),
+		executions: make(map[string]int),
+		random:     rand.New(rand.NewSource(time.Now().UnixNano())),
+	}
+}
+
+// Name returns the component name
+func (m *Monkey) Name() string {
+	return MonkeyName
+}
+
+// Init adds the delivery filter to the event bus when deliveries are dropped
+func (m *Monkey) Init(ctx container.ApplicationContext) error {
+	if m.config.Events.DropEvery == 0 && m.config.Events.DropRate == 0 {
+		return nil
+	}
+	if !ctx.HasComponent("eventBus") {
+		m.logger.Warn("No event bus, chaos event drops are ignored")
+		return nil
+	}
+	bus, err := container.GetComponentAs[*events.EventBus](ctx, "eventBus")
+	if err != nil {
+		return err
+	}
+	bus.AddDeliveryFilter(m.deliver)
+	return nil
+}
+
+// BeforeStart delays the Start of the component
+func (m *Monkey) BeforeStart(ctx context.Context, name string) {
+	delay := m.config.Components[name].StartDelay
+	if delay <= 0 {
+		return
+	}
+	m.logger.Warn("Chaos: delaying component start", "name", name, "delay", delay.String())
+	select {
+	case <-time.After(delay):
+	case <-ctx.Done():
+	}
+}
+
+// BeforeExecute panics every Nth execution of the scheduled component
+func (m *Monkey) BeforeExecute(_ context.Context, name string) {
+	every := m.config.Components[name].PanicEvery
+	if every <= 0 {
+		return
+	}
+	m.mu.Lock()
+	m.executions[name]++
+	n := m.executions[name]
+	m.mu.Unlock()
+
+	if n%every == 0 {
+		m.logger.Warn("Chaos: panicking scheduled execution", "name", name, "execution", n)
+		panic(fmt.Sprintf("chaos: execution %d of %s", n, name))
+	}
+}
+
+// deliver is the delivery filter of the event bus; false drops the delivery
+func (m *Monkey) deliver(_ context.Context, event any) bool {
+	config := m.config.Events
+	if len(config.Types) > 0 {
+		eventType := fmt.Sprintf("%T", event)
+		matched := false
+		for _, t := range config.Types {
+			if t == eventType {
+				matched = true
+				break
+			}
+		}
+		if !matched {
+			return true
+		}
+	}
+
+	m.mu.Lock()
+	m.deliveries++
+	drop := (config.DropEvery > 0 && m.deliveries%config.DropEvery == 0) ||
+		(config.DropRate > 0 && m.random.Float64() < config.DropRate)
+	m.mu.Unlock()
+
+	if drop {
+		m.logger.Warn("Chaos: dropping event delivery", "event", fmt.Sprintf("%T", event))
+	}
+	return !drop
+}
+
+// Ensure that Monkey intercepts the lifecycle of the components
+var _ container.LifecycleInterceptor = (*Monkey)(nil)
@@ -0,0 +1,48 @@
+package chaos
+
+import (
+	"log/slog"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Starter registers the Monkey when the chaos section exists and one of
+// chaos.profiles is active, so that the faults never reach an environment that
+// didn't ask for them:
+//
+//	chaos:
+//	  profiles: [chaos, staging]
+//	  components:
+//	    orderService:
+//	      start-delay: 5s
+//	    reportJob:
+//	      panic-every: 3
+//	  events:
+//	    drop-rate: 0.1
+//	    types: [orders.OrderPlaced]
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"ChaosStarter",
+		func(ctx container.ApplicationContext) bool {
+			return container.NewVariableHelper(ctx).HasSection(PropertyChaos) &&
+				container.NewVariableHelper(ctx).GetBool(PropertyChaos+".enabled", true)
+		},
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertyChaos, &config); err != nil {
+				return container.ConfigurationError("invalid "+PropertyChaos+" configuration", err)
+			}
+			config = config.withDefaults()
+			if err := config.validate(); err != nil {
+				return err
+			}
+			if !container.ProfileCondition(config.Profiles...)(builder) {
+				slog.Default().Info("Chaos faults configured but not enabled, no profile active", "profiles", config.Profiles)
+				return nil
+			}
+
+			slog.Default().Warn("Chaos faults enabled", "profiles", config.Profiles)
+			return builder.RegisterComponent(NewMonkey(config))
+		},
+	)
+}
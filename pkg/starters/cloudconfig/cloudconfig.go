@@ -0,0 +1,124 @@
+// Package cloudconfig layers the variables of cloud secret stores over
+// application.yml. The loaders of awsconfig, gcpconfig and azureconfig can all be
+// added to a builder; the one of the provider selected by cloud.provider or by the
+// active profile loads its variables:
+//
+//	awsconfig.Setup(builder)
+//	gcpconfig.Setup(builder)
+//	azureconfig.Setup(builder)
+package cloudconfig
+
+import (
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sort"
+	"strconv"
+	"sync"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyProvider selects the provider whose loader is active: cloud.provider
+const PropertyProvider = "cloud.provider"
+
+// Providers selected by cloud.provider or by the profile of the same name
+const (
+	ProviderAWS   = "aws"
+	ProviderGCP   = "gcp"
+	ProviderAzure = "azure"
+)
+
+// Selected reports whether the loader of a provider configured under prefix is
+// active. prefix.enabled decides when set; otherwise cloud.provider when set;
+// otherwise whether the profile named after the provider is active, so that
+// application-gcp.yml configures the loader for GCP deployments.
+func Selected(ctx container.ApplicationContext, provider, prefix string) bool {
+	if ctx.HasVariable(prefix + ".enabled") {
+		enabled, _ := strconv.ParseBool(ctx.GetVariable(prefix + ".enabled"))
+		return enabled
+	}
+	if ctx.HasVariable(PropertyProvider) {
+		return ctx.GetVariable(PropertyProvider) == provider
+	}
+	return container.ProfileCondition(provider)(ctx)
+}
+
+// Condition returns the starter condition of Selected
+func Condition(provider, prefix string) func(container.ApplicationContext) bool {
+	return func(ctx container.ApplicationContext) bool {
+		return Selected(ctx, provider, prefix)
+	}
+}
+
+// Registrar registers the variables fetched by a loader. The first load fails
+// with the fetch error unless the source is optional; a failed reload is logged
+// and the last loaded values are kept.
+type Registrar struct {
+	// Source names the secret store in logs and errors
+	Source string
+	Logger *slog.Logger
+
+	mu     sync.Mutex
+	loaded bool
+}
+
+// Register registers variables, or handles err
+func (r *Registrar) Register(builder container.ContextBuilder, variables map[string]string, err error, optional bool) error {
+	logger := r.Logger
+	if logger == nil {
+		logger = slog.Default()
+	}
+
+	r.mu.Lock()
+	reload := r.loaded
+	r.loaded = r.loaded || err == nil
+	r.mu.Unlock()
+
+	if err != nil {
+		if reload {
+			logger.Warn("Cloud variables not refreshed, keeping the last values", "source", r.Source, "error", err)
+			return nil
+		}
+		if optional {
+			logger.Warn("Cloud variables not loaded", "source", r.Source, "error", err)
+			return nil
+		}
+		return fmt.Errorf("load variables from %s: %w", r.Source, err)
+	}
+
+	names := make([]string, 0, len(variables))
+	for name := range variables {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	for _, name := range names {
+		builder.RegisterVariable(name, variables[name])
+	}
+	logger.Info("Cloud variables loaded", "source", r.Source, "count", len(variables))
+	return nil
+}
+
+// AddSecret adds the value of a secret to variables under name, expanding a JSON
+// object into a variable per field: {"password": "..."} under db becomes
+// db.password
+func AddSecret(variables map[string]string, name, value string) {
+	var fields map[string]interface{}
+	if err := json.Unmarshal([]byte(value), &fields); err != nil {
+		variables[name] = value
+		return
+	}
+	for field, fieldValue := range fields {
+		key := field
+		if name != "" {
+			key = name + "." + field
+		}
+		switch v := fieldValue.(type) {
+		case string:
+			variables[key] = v
+		default:
+			data, _ := json.Marshal(v)
+			variables[key] = string(data)
+		}
+	}
+}
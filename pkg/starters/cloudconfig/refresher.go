@@ -0,0 +1,83 @@
+package cloudconfig
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Refresher reloads the variables of the context periodically, so that changed
+// secrets reach the config properties, schedules and VariableChangeListener
+// components; see container.VariableReloader
+type Refresher struct {
+	name     string
+	reloader container.VariableReloader
+	interval time.Duration
+	logger   *slog.Logger
+}
+
+// NewRefresher creates a refresher reloading the variables every interval
+func NewRefresher(name string, reloader container.VariableReloader, interval time.Duration) *Refresher {
+	return &Refresher{name: name, reloader: reloader, interval: interval, logger: slog.Default()}
+}
+
+// Name returns the component name
+func (r *Refresher) Name() string {
+	return r.name
+}
+
+// Init is a no-op
+func (r *Refresher) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Start is a no-op
+func (r *Refresher) Start(context.Context) {}
+
+// Stop is a no-op
+func (r *Refresher) Stop(context.Context) {}
+
+// GetSchedule returns the refresh schedule
+func (r *Refresher) GetSchedule() container.Schedule {
+	return container.Schedule{Interval: r.interval, InitialDelay: r.interval}
+}
+
+// Execute reloads the variables
+func (r *Refresher) Execute(context.Context) {
+	changed, err := r.reloader.ReloadVariables()
+	if err != nil {
+		r.logger.Error("Variables refresh failed", "error", err)
+		return
+	}
+	if len(changed) > 0 {
+		r.logger.Info("Variables refreshed", "changed", changed)
+	}
+}
+
+// RefreshStarter registers a Refresher named name when the provider is Selected
+// and prefix.refresh-interval is set. The refresh reloads all the variable
+// loaders, the provider's among them.
+func RefreshStarter(starterName, name, provider, prefix string) container.Starter {
+	return container.NewConditionalStarter(
+		starterName,
+		Condition(provider, prefix),
+		func(builder container.ContextBuilder) error {
+			var interval time.Duration
+			if builder.HasVariable(prefix + ".refresh-interval") {
+				if err := builder.GetVariableAs(prefix+".refresh-interval", &interval); err != nil {
+					return fmt.Errorf("invalid %s.refresh-interval: %w", prefix, err)
+				}
+			}
+			if interval <= 0 {
+				return nil
+			}
+			return builder.RegisterComponent(NewRefresher(name, builder, interval))
+		},
+	)
+}
+
+// Ensure that Refresher implements container.ScheduledComponent
+var _ container.ScheduledComponent = (*Refresher)(nil)
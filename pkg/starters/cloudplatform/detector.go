@@ -0,0 +1,152 @@
+package cloudplatform
+
+import (
+	"context"
+	"log/slog"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyDetection holds the detection configuration: cloud.detection.*
+const PropertyDetection = "cloud.detection"
+
+// Variables registered for the detected platform, unless the configuration sets
+// them. cloud.detected-provider names the provider without selecting its secret
+// loader, unlike cloud.provider.
+const (
+	PropertyPlatform         = "cloud.platform"
+	PropertyDetectedProvider = "cloud.detected-provider"
+	PropertyRegion           = "cloud.region"
+	PropertyZone             = "cloud.zone"
+	PropertyInstanceID       = "cloud.instance-id"
+	PropertyNamespace        = "cloud.namespace"
+)
+
+// DetectorOrder runs the detector before the post-processors of the default order
+const DetectorOrder = -100
+
+// DetectionConfig configures the detection: cloud.detection.*
+type DetectionConfig struct {
+	// Enabled turns the detection on (default true)
+	Enabled *bool `yaml:"enabled"`
+	// ProbeMetadata asks the metadata endpoints of the cloud providers for the
+	// region and instance, and detects VMs (default true)
+	ProbeMetadata *bool `yaml:"probe-metadata"`
+	// Timeout bounds probing the metadata endpoints (default 500ms)
+	Timeout time.Duration `yaml:"timeout"`
+	// ActivateProfile activates the profile named after the platform, e.g.
+	// kubernetes (default true)
+	ActivateProfile *bool `yaml:"activate-profile"`
+}
+
+func (c DetectionConfig) withDefaults() DetectionConfig {
+	enabled := true
+	if c.Enabled == nil {
+		c.Enabled = &enabled
+	}
+	if c.ProbeMetadata == nil {
+		c.ProbeMetadata = &enabled
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 500 * time.Millisecond
+	}
+	if c.ActivateProfile == nil {
+		c.ActivateProfile = &enabled
+	}
+	return c
+}
+
+// Detector detects the platform once and registers its variables and profile. It
+// is an environment post-processor, so that starters and components see them:
+//
+//	builder.AddEnvironmentPostProcessor(cloudplatform.NewDetector())
+//
+// Post-processors run after the variable loaders, which have loaded the profile
+// files by then. To also load application-kubernetes.yml and the like, add the
+// detector as the first variable loader instead; it then only sees the
+// configuration of the loaders before it:
+//
+//	cfg.DefaultVariableLoaders = append([]container.VariableLoader{cloudplatform.NewDetector()},
+//		cfg.DefaultVariableLoaders...)
+type Detector struct {
+	logger *slog.Logger
+
+	once     sync.Once
+	platform Platform
+}
+
+// NewDetector creates a detector
+func NewDetector() *Detector {
+	return &Detector{logger: slog.Default()}
+}
+
+// Name returns the post-processor name
+func (d *Detector) Name() string {
+	return "cloudPlatformDetector"
+}
+
+// GetOrder returns DetectorOrder
+func (d *Detector) GetOrder() int {
+	return DetectorOrder
+}
+
+// PostProcessEnvironment detects the platform
+func (d *Detector) PostProcessEnvironment(builder container.ContextBuilder, _ []string) error {
+	return d.Load(builder)
+}
+
+// Load detects the platform
+func (d *Detector) Load(builder container.ContextBuilder) error {
+	var config DetectionConfig
+	if container.NewVariableHelper(builder).HasSection(PropertyDetection) {
+		if err := builder.GetVariableAs(PropertyDetection, &config); err != nil {
+			return container.ConfigurationError("invalid "+PropertyDetection+" configuration", err)
+		}
+	}
+	config = config.withDefaults()
+	if !*config.Enabled {
+		return nil
+	}
+
+	platform := d.detect(*config.ProbeMetadata, config.Timeout)
+	if !platform.Detected() {
+		return nil
+	}
+
+	for name, value := range map[string]string{
+		PropertyPlatform:         platform.Name,
+		PropertyDetectedProvider: platform.Provider,
+		PropertyRegion:           platform.Region,
+		PropertyZone:             platform.Zone,
+		PropertyInstanceID:       platform.InstanceID,
+		PropertyNamespace:        platform.Namespace,
+	} {
+		if value != "" && !builder.HasVariable(name) {
+			builder.RegisterVariable(name, value)
+		}
+	}
+	if *config.ActivateProfile && !container.ProfileCondition(platform.Name)(builder) {
+		builder.ActivateProfiles(platform.Name)
+	}
+	return nil
+}
+
+// detect detects the platform on the first call; reloads reuse it
+func (d *Detector) detect(probe bool, timeout time.Duration) Platform {
+	d.once.Do(func() {
+		d.platform = Detect(context.Background(), probe, timeout)
+		if d.platform.Detected() {
+			d.logger.Info("Cloud platform detected", "platform", d.platform.Name,
+				"provider", d.platform.Provider, "region", d.platform.Region, "instance", d.platform.InstanceID)
+		}
+	})
+	return d.platform
+}
+
+// Ensure that Detector is a post-processor and a variable loader
+var (
+	_ container.OrderedEnvironmentPostProcessor = (*Detector)(nil)
+	_ container.VariableLoader                  = (*Detector)(nil)
+)
@@ -0,0 +1,168 @@
+package cloudplatform
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"strings"
+)
+
+// Metadata endpoints of the cloud providers; variables so that they can point at
+// fake servers
+var (
+	awsMetadataURL   = "http://169.254.169.254/latest"
+	gcpMetadataURL   = "http://metadata.google.internal/computeMetadata/v1"
+	azureMetadataURL = "http://169.254.169.254/metadata/instance/compute?api-version=2021-02-01"
+)
+
+// probeMetadata asks the metadata endpoints of all providers concurrently and
+// returns the instance of the one that answers
+func probeMetadata(ctx context.Context) (Platform, bool) {
+	ctx, cancel := context.WithCancel(ctx)
+	defer cancel()
+
+	probes := []func(context.Context) (Platform, error){probeAWS, probeGCP, probeAzure}
+	results := make(chan Platform, len(probes))
+	for _, probe := range probes {
+		go func(probe func(context.Context) (Platform, error)) {
+			instance, err := probe(ctx)
+			if err != nil {
+				instance = Platform{}
+			}
+			results <- instance
+		}(probe)
+	}
+	for range probes {
+		if instance := <-results; instance.Provider != "" {
+			return instance, true
+		}
+	}
+	return Platform{}, false
+}
+
+// probeAWS reads the identity document of an EC2 instance with an IMDSv2 token
+func probeAWS(ctx context.Context) (Platform, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, awsMetadataURL+"/api/token", nil)
+	if err != nil {
+		return Platform{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token-ttl-seconds", "60")
+	token, err := fetch(req)
+	if err != nil {
+		return Platform{}, err
+	}
+
+	req, err = http.NewRequestWithContext(ctx, http.MethodGet, awsMetadataURL+"/dynamic/instance-identity/document", nil)
+	if err != nil {
+		return Platform{}, err
+	}
+	req.Header.Set("X-aws-ec2-metadata-token", string(token))
+	data, err := fetch(req)
+	if err != nil {
+		return Platform{}, err
+	}
+	var document struct {
+		Region           string `json:"region"`
+		AvailabilityZone string `json:"availabilityZone"`
+		InstanceID       string `json:"instanceId"`
+	}
+	if err := json.Unmarshal(data, &document); err != nil {
+		return Platform{}, err
+	}
+	return Platform{Provider: "aws", Region: document.Region, Zone: document.AvailabilityZone, InstanceID: document.InstanceID}, nil
+}
+
+// probeGCP reads the instance ID and zone from the metadata server of Compute
+// Engine, GKE and Cloud Run
+func probeGCP(ctx context.Context) (Platform, error) {
+	get := func(path string) (string, error) {
+		req, err := http.NewRequestWithContext(ctx, http.MethodGet, gcpMetadataURL+path, nil)
+		if err != nil {
+			return "", err
+		}
+		req.Header.Set("Metadata-Flavor", "Google")
+		data, err := fetch(req)
+		return string(data), err
+	}
+
+	id, err := get("/instance/id")
+	if err != nil {
+		return Platform{}, err
+	}
+	// projects/<number>/zones/<zone>
+	zone, err := get("/instance/zone")
+	if err != nil {
+		return Platform{}, err
+	}
+	zone = zone[strings.LastIndex(zone, "/")+1:]
+	region := zone
+	if index := strings.LastIndex(zone, "-"); index > 0 {
+		region = zone[:index]
+	}
+	return Platform{Provider: "gcp", Region: region, Zone: zone, InstanceID: id}, nil
+}
+
+// probeAzure reads the compute metadata of an Azure VM
+func probeAzure(ctx context.Context) (Platform, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, azureMetadataURL, nil)
+	if err != nil {
+		return Platform{}, err
+	}
+	req.Header.Set("Metadata", "true")
+	data, err := fetch(req)
+	if err != nil {
+		return Platform{}, err
+	}
+	var compute struct {
+		Location string `json:"location"`
+		Zone     string `json:"zone"`
+		VMID     string `json:"vmId"`
+	}
+	if err := json.Unmarshal(data, &compute); err != nil {
+		return Platform{}, err
+	}
+	if compute.VMID == "" {
+		return Platform{}, fmt.Errorf("no vmId in azure metadata")
+	}
+	return Platform{Provider: "azure", Region: compute.Location, Zone: compute.Zone, InstanceID: compute.VMID}, nil
+}
+
+// probeECS reads the task metadata of an ECS task
+func probeECS(ctx context.Context, uri string) (Platform, bool) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, strings.TrimSuffix(uri, "/")+"/task", nil)
+	if err != nil {
+		return Platform{}, false
+	}
+	data, err := fetch(req)
+	if err != nil {
+		return Platform{}, false
+	}
+	var task struct {
+		// TaskARN is arn:aws:ecs:<region>:<account>:task/<cluster>/<id>
+		TaskARN          string `json:"TaskARN"`
+		AvailabilityZone string `json:"AvailabilityZone"`
+	}
+	if err := json.Unmarshal(data, &task); err != nil {
+		return Platform{}, false
+	}
+	instance := Platform{Provider: "aws", Zone: task.AvailabilityZone, InstanceID: task.TaskARN[strings.LastIndex(task.TaskARN, "/")+1:]}
+	if parts := strings.Split(task.TaskARN, ":"); len(parts) > 3 {
+		instance.Region = parts[3]
+	}
+	return instance, true
+}
+
+// fetch sends a request and returns the body of a successful response
+func fetch(req *http.Request) ([]byte, error) {
+	resp, err := http.DefaultClient.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("%s: status %d", req.URL, resp.StatusCode)
+	}
+	return io.ReadAll(io.LimitReader(resp.Body, 1<<20))
+}
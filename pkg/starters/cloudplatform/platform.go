@@ -0,0 +1,101 @@
+// Package cloudplatform detects the platform the application runs on
+// (Kubernetes, ECS, Cloud Run or a cloud VM), registers its cloud.* variables and
+// activates the profile of the platform
+package cloudplatform
+
+import (
+	"context"
+	"os"
+	"strings"
+	"time"
+)
+
+// Platforms detected, which are also the names of the activated profiles
+const (
+	PlatformKubernetes = "kubernetes"
+	PlatformECS        = "ecs"
+	PlatformCloudRun   = "cloud-run"
+	PlatformVM         = "vm"
+)
+
+// Platform describes where the application runs
+type Platform struct {
+	// Name is the platform, e.g. kubernetes; empty when none was detected
+	Name string
+	// Provider is the cloud provider (aws, gcp or azure) when known
+	Provider string
+	Region   string
+	Zone     string
+	// InstanceID identifies the VM, task, pod or instance of the service
+	InstanceID string
+	// Namespace is the Kubernetes namespace
+	Namespace string
+}
+
+// Detected reports whether a platform was detected
+func (p Platform) Detected() bool {
+	return p.Name != ""
+}
+
+// namespaceFile holds the namespace of a Kubernetes pod; a variable so that it can
+// point at a test file
+var namespaceFile = "/var/run/secrets/kubernetes.io/serviceaccount/namespace"
+
+// Detect detects the platform from the environment variables of Kubernetes, ECS
+// and Cloud Run, then completes it from the metadata endpoints of the cloud
+// providers when probe is true. Without a known environment, a successful probe
+// means a cloud VM. Each probe is bounded by timeout.
+func Detect(ctx context.Context, probe bool, timeout time.Duration) Platform {
+	var platform Platform
+	switch {
+	case os.Getenv("KUBERNETES_SERVICE_HOST") != "":
+		platform.Name = PlatformKubernetes
+		platform.InstanceID = os.Getenv("HOSTNAME")
+		if data, err := os.ReadFile(namespaceFile); err == nil {
+			platform.Namespace = strings.TrimSpace(string(data))
+		}
+	case os.Getenv("ECS_CONTAINER_METADATA_URI_V4") != "":
+		platform.Name = PlatformECS
+		platform.Provider = "aws"
+		platform.Region = os.Getenv("AWS_REGION")
+	case os.Getenv("K_SERVICE") != "":
+		platform.Name = PlatformCloudRun
+		platform.Provider = "gcp"
+	}
+	if !probe {
+		return platform
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, timeout)
+	defer cancel()
+	if platform.Name == PlatformECS {
+		if task, ok := probeECS(ctx, os.Getenv("ECS_CONTAINER_METADATA_URI_V4")); ok {
+			platform.complete(task)
+		}
+		return platform
+	}
+	if instance, ok := probeMetadata(ctx); ok {
+		if platform.Name == "" {
+			platform.Name = PlatformVM
+		}
+		platform.complete(instance)
+	}
+	return platform
+}
+
+// complete fills the fields that weren't detected from probed ones; the instance
+// of a pod or a Cloud Run instance stays theirs rather than the node's
+func (p *Platform) complete(probed Platform) {
+	if p.Provider == "" {
+		p.Provider = probed.Provider
+	}
+	if p.Region == "" {
+		p.Region = probed.Region
+	}
+	if p.Zone == "" {
+		p.Zone = probed.Zone
+	}
+	if p.InstanceID == "" {
+		p.InstanceID = probed.InstanceID
+	}
+}
@@ -0,0 +1,275 @@
+// Package dashboard serves a small admin UI for development that shows the
+// container's dependency graph, startup timeline, health, scheduled jobs and
+// configuration
+package dashboard
+
+import (
+	_ "embed"
+	"encoding/json"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/web"
+)
+
+// Dashboard properties: goboot.dashboard.*
+const (
+	// PropertyEnabled registers the dashboard (default false)
+	PropertyEnabled = "goboot.dashboard.enabled"
+	// PropertyPath is the dashboard base path (default /goboot/dashboard)
+	PropertyPath = "goboot.dashboard.path"
+)
+
+//go:embed static/index.html
+var indexPage []byte
+
+// Dashboard is an HTTPHandler component serving the dashboard page and its JSON APIs
+type Dashboard struct {
+	ctx      container.ApplicationContext
+	basePath string
+}
+
+// NewDashboard creates the dashboard component
+func NewDashboard() *Dashboard {
+	return &Dashboard{}
+}
+
+// Name returns the component name
+func (d *Dashboard) Name() string {
+	return "dashboard"
+}
+
+// Init keeps the context to report on
+func (d *Dashboard) Init(ctx container.ApplicationContext) error {
+	d.ctx = ctx
+	d.basePath = strings.TrimSuffix(container.NewVariableHelper(ctx).GetString(PropertyPath, "/goboot/dashboard"), "/")
+	return nil
+}
+
+// Routes returns the dashboard page and its APIs
+func (d *Dashboard) Routes() []web.Route {
+	tags := []string{"dashboard"}
+	return []web.Route{
+		{Method: http.MethodGet, Path: d.basePath + "/", Handler: http.HandlerFunc(d.serveIndex), Summary: "Dashboard page", Tags: tags},
+		{Method: http.MethodGet, Path: d.basePath + "/api/components", Handler: http.HandlerFunc(d.serveComponents), Summary: "Components with states and dependencies", Tags: tags},
+		{Method: http.MethodGet, Path: d.basePath + "/api/timeline", Handler: http.HandlerFunc(d.serveTimeline), Summary: "Startup timeline", Tags: tags},
+		{Method: http.MethodGet, Path: d.basePath + "/api/health", Handler: http.HandlerFunc(d.serveHealth), Summary: "Aggregated health", Tags: tags},
+		{Method: http.MethodGet, Path: d.basePath + "/api/config", Handler: http.HandlerFunc(d.serveConfig), Summary: "Configuration with secrets masked", Tags: tags},
+		{Method: http.MethodGet, Path: d.basePath + "/api/config/declared", Handler: http.HandlerFunc(d.serveDeclaredVariables), Summary: "Declared variables with their current values", Tags: tags},
+		{Method: http.MethodGet, Path: d.basePath + "/api/jobs", Handler: http.HandlerFunc(d.serveJobs), Summary: "Scheduled jobs", Tags: tags},
+		{Method: http.MethodPost, Path: d.basePath + "/api/jobs/{name}/{action}", Handler: http.HandlerFunc(d.controlJob), Summary: "Pause, resume or trigger a scheduled job", Tags: tags},
+		{Method: http.MethodPost, Path: d.basePath + "/api/refresh", Handler: http.HandlerFunc(d.refresh), Summary: "Reload the variables", Tags: tags},
+	}
+}
+
+func (d *Dashboard) serveIndex(w http.ResponseWriter, r *http.Request) {
+	if strings.TrimSuffix(r.URL.Path, "/") != d.basePath {
+		http.NotFound(w, r)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	_, _ = w.Write(indexPage)
+}
+
+// componentInfo is a component as shown in the graph
+type componentInfo struct {
+	Name         string   `json:"name"`
+	Type         string   `json:"type"`
+	State        string   `json:"state"`
+	Dependencies []string `json:"dependencies"`
+	Dependents   []string `json:"dependents"`
+	Background   bool     `json:"background"`
+	Scheduled    bool     `json:"scheduled"`
+}
+
+func (d *Dashboard) serveComponents(w http.ResponseWriter, r *http.Request) {
+	components := []componentInfo{}
+	for _, report := range d.reports() {
+		components = append(components, componentInfo{
+			Name:         report.Name,
+			Type:         report.Type,
+			State:        string(report.State),
+			Dependencies: nonNil(report.Dependencies),
+			Dependents:   nonNil(report.Dependents),
+			Background:   report.Background,
+			Scheduled:    report.Scheduled,
+		})
+	}
+	writeJSON(w, http.StatusOK, components)
+}
+
+// timelineEntry is a component's initialization and start in startup order
+type timelineEntry struct {
+	Name            string  `json:"name"`
+	InitDurationMs  float64 `json:"initDurationMs"`
+	StartDurationMs float64 `json:"startDurationMs"`
+}
+
+func (d *Dashboard) serveTimeline(w http.ResponseWriter, r *http.Request) {
+	timeline := []timelineEntry{}
+	inspector, ok := d.ctx.(container.ContainerInspector)
+	if ok {
+		reports := make(map[string]container.ComponentReport)
+		for _, report := range inspector.GetComponentReport() {
+			reports[report.Name] = report
+		}
+		for _, name := range inspector.GetInitOrder() {
+			report := reports[name]
+			timeline = append(timeline, timelineEntry{
+				Name:            name,
+				InitDurationMs:  milliseconds(report.InitDuration),
+				StartDurationMs: milliseconds(report.StartDuration),
+			})
+		}
+	}
+	writeJSON(w, http.StatusOK, timeline)
+}
+
+func (d *Dashboard) serveHealth(w http.ResponseWriter, r *http.Request) {
+	writeJSON(w, http.StatusOK, container.AggregateHealth(r.Context(), d.ctx))
+}
+
+func (d *Dashboard) serveConfig(w http.ResponseWriter, r *http.Request) {
+	config := make(map[string]interface{})
+	if source, ok := d.ctx.(container.VariableSource); ok {
+		for key, value := range source.GetVariables() {
+			if container.IsSecretVariable(key) {
+				value = container.MaskedValue
+			}
+			config[key] = value
+		}
+	}
+	writeJSON(w, http.StatusOK, config)
+}
+
+// declaredVariable is a declared variable with its current value
+type declaredVariable struct {
+	Name        string      `json:"name"`
+	Type        string      `json:"type"`
+	Default     interface{} `json:"default,omitempty"`
+	Required    bool        `json:"required"`
+	Description string      `json:"description,omitempty"`
+	Value       interface{} `json:"value"`
+}
+
+func (d *Dashboard) serveDeclaredVariables(w http.ResponseWriter, r *http.Request) {
+	variables := []declaredVariable{}
+	if source, ok := d.ctx.(container.VariableDeclarations); ok {
+		for _, declaration := range source.GetVariableDeclarations() {
+			variable := declaredVariable{
+				Name:        declaration.Name,
+				Type:        string(declaration.Type),
+				Default:     declaration.Default,
+				Required:    declaration.Required,
+				Description: declaration.Description,
+				Value:       d.ctx.GetVariableRaw(declaration.Name),
+			}
+			if container.IsSecretVariable(declaration.Name) {
+				if variable.Default != nil {
+					variable.Default = container.MaskedValue
+				}
+				if variable.Value != nil {
+					variable.Value = container.MaskedValue
+				}
+			}
+			variables = append(variables, variable)
+		}
+	}
+	writeJSON(w, http.StatusOK, variables)
+}
+
+// jobInfo is a scheduled job's state
+type jobInfo struct {
+	Name       string     `json:"name"`
+	IntervalMs float64    `json:"intervalMs"`
+	Paused     bool       `json:"paused"`
+	LastRun    *time.Time `json:"lastRun"`
+}
+
+func (d *Dashboard) serveJobs(w http.ResponseWriter, r *http.Request) {
+	jobs := []jobInfo{}
+	for _, job := range d.jobs() {
+		info := jobInfo{Name: job.Name(), IntervalMs: milliseconds(job.Schedule().Interval), Paused: job.Paused()}
+		if lastRun := job.LastRun(); !lastRun.IsZero() {
+			info.LastRun = &lastRun
+		}
+		jobs = append(jobs, info)
+	}
+	writeJSON(w, http.StatusOK, jobs)
+}
+
+// controlJob handles POST <base>/api/jobs/<name>/<pause|resume|trigger>
+func (d *Dashboard) controlJob(w http.ResponseWriter, r *http.Request) {
+	rest := strings.TrimPrefix(r.URL.Path, d.basePath+"/api/jobs/")
+	name, action, ok := strings.Cut(rest, "/")
+	if !ok {
+		http.NotFound(w, r)
+		return
+	}
+
+	for _, job := range d.jobs() {
+		if job.Name() != name {
+			continue
+		}
+		switch action {
+		case "pause":
+			job.Pause()
+		case "resume":
+			job.Resume()
+		case "trigger":
+			job.Trigger()
+		default:
+			http.Error(w, "unknown action "+action, http.StatusBadRequest)
+			return
+		}
+		writeJSON(w, http.StatusOK, map[string]string{"name": name, "action": action})
+		return
+	}
+	http.NotFound(w, r)
+}
+
+// refresh handles POST <base>/api/refresh, returning the names of the changed variables
+func (d *Dashboard) refresh(w http.ResponseWriter, _ *http.Request) {
+	changed, err := d.ctx.ReloadVariables()
+	if err != nil {
+		writeJSON(w, http.StatusInternalServerError, map[string]any{"error": err.Error(), "changed": nonNil(changed)})
+		return
+	}
+	writeJSON(w, http.StatusOK, map[string]any{"changed": nonNil(changed)})
+}
+
+func (d *Dashboard) reports() []container.ComponentReport {
+	if inspector, ok := d.ctx.(container.ContainerInspector); ok {
+		return inspector.GetComponentReport()
+	}
+	return nil
+}
+
+func (d *Dashboard) jobs() []*container.ScheduledJob {
+	if scheduler, ok := d.ctx.(container.JobScheduler); ok {
+		return scheduler.GetScheduledJobs()
+	}
+	return nil
+}
+
+func writeJSON(w http.ResponseWriter, status int, value any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(value)
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func nonNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
+
+// Ensure that Dashboard implements web.HTTPHandler
+var _ web.HTTPHandler = (*Dashboard)(nil)
@@ -0,0 +1,21 @@
+package dashboard
+
+import (
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Starter registers the dashboard when goboot.dashboard.enabled is true. It is
+// served by the web server (register web.Starter() too) under goboot.dashboard.path.
+// The dashboard shows all configuration with secret-looking values masked and can
+// pause and trigger scheduled jobs, so only enable it for development.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"DashboardStarter",
+		func(ctx container.ApplicationContext) bool {
+			return container.NewVariableHelper(ctx).GetBool(PropertyEnabled, false)
+		},
+		func(builder container.ContextBuilder) error {
+			return builder.RegisterComponent(NewDashboard())
+		},
+	)
+}
@@ -0,0 +1,173 @@
+// Package datasource opens the application's *sql.DB from the datasource.*
+// properties and reports its health and pool metrics. The gormdb and sqlxdb
+// starters expose it as a *gorm.DB or a *sqlx.DB.
+package datasource
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Config configures a datasource: datasource.*
+type Config struct {
+	// Driver is the database/sql driver name, e.g. postgres or mysql; the
+	// application imports the driver package
+	Driver string `yaml:"driver"`
+	// URL is the data source name passed to the driver
+	URL string `yaml:"url"`
+	// MaxOpen and MaxIdle bound the connection pool (0 keeps the database/sql defaults)
+	MaxOpen int `yaml:"max-open"`
+	MaxIdle int `yaml:"max-idle"`
+	// ConnMaxLifetime and ConnMaxIdleTime recycle connections (0 means never)
+	ConnMaxLifetime time.Duration `yaml:"conn-max-lifetime"`
+	ConnMaxIdleTime time.Duration `yaml:"conn-max-idle-time"`
+	// PingTimeout bounds health checks (default 2s)
+	PingTimeout time.Duration `yaml:"ping-timeout"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.PingTimeout <= 0 {
+		c.PingTimeout = 2 * time.Second
+	}
+	return c
+}
+
+// Open opens a *sql.DB with the pool settings of the configuration. Like sql.Open,
+// it doesn't connect yet.
+func Open(config Config) (*sql.DB, error) {
+	if config.Driver == "" || config.URL == "" {
+		return nil, container.ErrorWithCode("INVALID_DATASOURCE", "a datasource needs a driver and a url")
+	}
+	db, err := sql.Open(config.Driver, config.URL)
+	if err != nil {
+		return nil, fmt.Errorf("open %s datasource: %w", config.Driver, err)
+	}
+	db.SetMaxOpenConns(config.MaxOpen)
+	if config.MaxIdle > 0 {
+		db.SetMaxIdleConns(config.MaxIdle)
+	}
+	db.SetConnMaxLifetime(config.ConnMaxLifetime)
+	db.SetConnMaxIdleTime(config.ConnMaxIdleTime)
+	return db, nil
+}
+
+// PoolMetrics is a snapshot of the connection pool of a datasource
+type PoolMetrics struct {
+	Name         string
+	MaxOpen      int
+	Open         int
+	InUse        int
+	Idle         int
+	WaitCount    int64
+	WaitDuration time.Duration
+	// MaxIdleClosed and MaxLifetimeClosed count connections closed by the pool settings
+	MaxIdleClosed     int64
+	MaxLifetimeClosed int64
+}
+
+// DataSource reports the health and pool metrics of a *sql.DB and closes it when
+// the container stops
+type DataSource struct {
+	name   string
+	db     *sql.DB
+	driver string
+	config Config
+}
+
+// NewDataSource creates the component of a *sql.DB opened with the configuration
+func NewDataSource(name string, db *sql.DB, config Config) *DataSource {
+	return &DataSource{name: name, db: db, driver: config.Driver, config: config.withDefaults()}
+}
+
+// Name returns the component name
+func (d *DataSource) Name() string {
+	return d.name
+}
+
+// Init is a no-op
+func (d *DataSource) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Start is a no-op; connections are opened on demand
+func (d *DataSource) Start(context.Context) {}
+
+// Stop closes the database
+func (d *DataSource) Stop(context.Context) {
+	_ = d.db.Close()
+}
+
+// DB returns the database
+func (d *DataSource) DB() *sql.DB {
+	return d.db
+}
+
+// Driver returns the database/sql driver name
+func (d *DataSource) Driver() string {
+	return d.driver
+}
+
+// WarmUp pings the database and opens up to max-idle connections, so that the
+// first requests don't wait for them
+func (d *DataSource) WarmUp(ctx context.Context) error {
+	if err := d.db.PingContext(ctx); err != nil {
+		return fmt.Errorf("ping %s datasource: %w", d.driver, err)
+	}
+	conns := make([]*sql.Conn, 0, d.config.MaxIdle)
+	defer func() {
+		// Released connections stay idle in the pool
+		for _, conn := range conns {
+			_ = conn.Close()
+		}
+	}()
+	for len(conns) < d.config.MaxIdle {
+		conn, err := d.db.Conn(ctx)
+		if err != nil {
+			return fmt.Errorf("open %s connection: %w", d.driver, err)
+		}
+		conns = append(conns, conn)
+	}
+	return nil
+}
+
+// CheckHealth pings the database
+func (d *DataSource) CheckHealth(ctx context.Context) container.Health {
+	ctx, cancel := context.WithTimeout(ctx, d.config.PingTimeout)
+	defer cancel()
+
+	stats := d.db.Stats()
+	details := map[string]interface{}{"driver": d.driver, "open": stats.OpenConnections, "inUse": stats.InUse}
+	if err := d.db.PingContext(ctx); err != nil {
+		details["error"] = err.Error()
+		return container.Health{Status: container.HealthDown, Details: details}
+	}
+	return container.Health{Status: container.HealthUp, Details: details}
+}
+
+// Metrics returns a snapshot of the connection pool
+func (d *DataSource) Metrics() PoolMetrics {
+	stats := d.db.Stats()
+	return PoolMetrics{
+		Name:              d.name,
+		MaxOpen:           stats.MaxOpenConnections,
+		Open:              stats.OpenConnections,
+		InUse:             stats.InUse,
+		Idle:              stats.Idle,
+		WaitCount:         stats.WaitCount,
+		WaitDuration:      stats.WaitDuration,
+		MaxIdleClosed:     stats.MaxIdleClosed,
+		MaxLifetimeClosed: stats.MaxLifetimeClosed,
+	}
+}
+
+// Ensure that DataSource implements LifecycleComponent, HealthIndicator and
+// WarmUpComponent
+var (
+	_ container.LifecycleComponent = (*DataSource)(nil)
+	_ container.HealthIndicator    = (*DataSource)(nil)
+	_ container.WarmUpComponent    = (*DataSource)(nil)
+)
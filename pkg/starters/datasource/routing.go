@@ -0,0 +1,224 @@
+package datasource
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"sync/atomic"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// RoutingConfig configures a primary and its read replicas:
+//
+//	datasource:
+//	  enabled: true
+//	  primary:
+//	    driver: postgres
+//	    url: postgres://primary/app
+//	  replicas:
+//	    - url: postgres://replica-1/app
+//	    - url: postgres://replica-2/app
+//
+// A replica takes the driver and the pool settings it leaves out from the primary.
+type RoutingConfig struct {
+	Primary  Config   `yaml:"primary"`
+	Replicas []Config `yaml:"replicas"`
+}
+
+// configured reports whether the datasource.primary section is set
+func (c RoutingConfig) configured() bool {
+	return c.Primary.Driver != "" || c.Primary.URL != ""
+}
+
+// replica completes the configuration of a replica with the primary's
+func (c RoutingConfig) replica(replica Config) Config {
+	if replica.Driver == "" {
+		replica.Driver = c.Primary.Driver
+	}
+	if replica.MaxOpen == 0 {
+		replica.MaxOpen = c.Primary.MaxOpen
+	}
+	if replica.MaxIdle == 0 {
+		replica.MaxIdle = c.Primary.MaxIdle
+	}
+	if replica.ConnMaxLifetime == 0 {
+		replica.ConnMaxLifetime = c.Primary.ConnMaxLifetime
+	}
+	if replica.ConnMaxIdleTime == 0 {
+		replica.ConnMaxIdleTime = c.Primary.ConnMaxIdleTime
+	}
+	if replica.PingTimeout == 0 {
+		replica.PingTimeout = c.Primary.PingTimeout
+	}
+	return replica
+}
+
+type readOnlyKey struct{}
+
+// WithReadOnly marks the operations of the context as read-only, so that a
+// RoutingDataSource sends them to a replica
+//
+//	orders, err := sqlrepo.Select[Order](datasource.WithReadOnly(ctx), txManager, query, arg)
+func WithReadOnly(ctx context.Context) context.Context {
+	return context.WithValue(ctx, readOnlyKey{}, true)
+}
+
+// IsReadOnly reports whether the operations of the context are read-only
+func IsReadOnly(ctx context.Context) bool {
+	readOnly, _ := ctx.Value(readOnlyKey{}).(bool)
+	return readOnly
+}
+
+// replica is a read replica, skipped while its last health check failed
+type replica struct {
+	*DataSource
+	down atomic.Bool
+}
+
+// RoutingDataSource routes the operations of read-only contexts to the replicas in
+// turn and everything else to the primary. A replica failing its health check is
+// skipped until it passes again; without a healthy replica, reads go to the
+// primary.
+type RoutingDataSource struct {
+	name     string
+	primary  *DataSource
+	replicas []*replica
+	next     atomic.Uint64
+}
+
+// NewRoutingDataSource creates the component routing between a primary and its
+// replicas, all opened with their configuration
+func NewRoutingDataSource(name string, primary *DataSource, replicas ...*DataSource) *RoutingDataSource {
+	routing := &RoutingDataSource{name: name, primary: primary}
+	for _, dataSource := range replicas {
+		routing.replicas = append(routing.replicas, &replica{DataSource: dataSource})
+	}
+	return routing
+}
+
+// OpenRouting opens the primary and the replicas of the configuration
+func OpenRouting(name string, config RoutingConfig) (*RoutingDataSource, error) {
+	db, err := Open(config.Primary)
+	if err != nil {
+		return nil, fmt.Errorf("primary: %w", err)
+	}
+	primary := NewDataSource("primary", db, config.Primary)
+
+	replicas := make([]*DataSource, 0, len(config.Replicas))
+	for i, replicaConfig := range config.Replicas {
+		replicaConfig = config.replica(replicaConfig)
+		db, err := Open(replicaConfig)
+		if err != nil {
+			_ = primary.db.Close()
+			for _, opened := range replicas {
+				_ = opened.db.Close()
+			}
+			return nil, fmt.Errorf("replica %d: %w", i, err)
+		}
+		replicas = append(replicas, NewDataSource(fmt.Sprintf("replica-%d", i), db, replicaConfig))
+	}
+	return NewRoutingDataSource(name, primary, replicas...), nil
+}
+
+// Name returns the component name
+func (r *RoutingDataSource) Name() string {
+	return r.name
+}
+
+// Init is a no-op
+func (r *RoutingDataSource) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Start is a no-op; connections are opened on demand
+func (r *RoutingDataSource) Start(context.Context) {}
+
+// Stop closes the primary and the replicas
+func (r *RoutingDataSource) Stop(ctx context.Context) {
+	r.primary.Stop(ctx)
+	for _, replica := range r.replicas {
+		replica.Stop(ctx)
+	}
+}
+
+// Primary returns the primary database
+func (r *RoutingDataSource) Primary() *sql.DB {
+	return r.primary.db
+}
+
+// Replicas returns the replica databases
+func (r *RoutingDataSource) Replicas() []*sql.DB {
+	dbs := make([]*sql.DB, len(r.replicas))
+	for i, replica := range r.replicas {
+		dbs[i] = replica.db
+	}
+	return dbs
+}
+
+// Driver returns the database/sql driver name of the primary
+func (r *RoutingDataSource) Driver() string {
+	return r.primary.driver
+}
+
+// DB returns the database for the operations of the context: the next healthy
+// replica if the context is read-only, else the primary
+func (r *RoutingDataSource) DB(ctx context.Context) *sql.DB {
+	if !IsReadOnly(ctx) || len(r.replicas) == 0 {
+		return r.primary.db
+	}
+	start := r.next.Add(1)
+	for i := range r.replicas {
+		replica := r.replicas[(start+uint64(i))%uint64(len(r.replicas))]
+		if !replica.down.Load() {
+			return replica.db
+		}
+	}
+	return r.primary.db
+}
+
+// WarmUp warms the pools of the primary and the replicas
+func (r *RoutingDataSource) WarmUp(ctx context.Context) error {
+	var errs []error
+	if err := r.primary.WarmUp(ctx); err != nil {
+		errs = append(errs, fmt.Errorf("primary: %w", err))
+	}
+	for _, replica := range r.replicas {
+		if err := replica.WarmUp(ctx); err != nil {
+			errs = append(errs, fmt.Errorf("%s: %w", replica.name, err))
+		}
+	}
+	return errors.Join(errs...)
+}
+
+// CheckHealth pings the primary and the replicas. It is down only if the primary
+// is; a replica that is down is reported in the details and skipped by DB.
+func (r *RoutingDataSource) CheckHealth(ctx context.Context) container.Health {
+	primary := r.primary.CheckHealth(ctx)
+	details := map[string]interface{}{"primary": map[string]interface{}{"status": primary.Status, "details": primary.Details}}
+	for _, replica := range r.replicas {
+		health := replica.CheckHealth(ctx)
+		replica.down.Store(health.Status != container.HealthUp)
+		details[replica.name] = map[string]interface{}{"status": health.Status, "details": health.Details}
+	}
+	return container.Health{Status: primary.Status, Details: details}
+}
+
+// Metrics returns a snapshot of the connection pool of the primary, then of each
+// replica
+func (r *RoutingDataSource) Metrics() []PoolMetrics {
+	metrics := []PoolMetrics{r.primary.Metrics()}
+	for _, replica := range r.replicas {
+		metrics = append(metrics, replica.Metrics())
+	}
+	return metrics
+}
+
+// Ensure that RoutingDataSource implements LifecycleComponent, HealthIndicator and
+// WarmUpComponent
+var (
+	_ container.LifecycleComponent = (*RoutingDataSource)(nil)
+	_ container.HealthIndicator    = (*RoutingDataSource)(nil)
+	_ container.WarmUpComponent    = (*RoutingDataSource)(nil)
+)
@@ -0,0 +1,65 @@
+package datasource
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyDataSource holds the datasource configuration: datasource.*
+const PropertyDataSource = "datasource"
+
+// Component names
+const (
+	// DBName is the *sql.DB instance, injected by type into the SQL components
+	DBName = "db"
+	// DataSourceName is the DataSource component reporting health and pool metrics
+	DataSourceName = "dataSource"
+)
+
+// Starter opens the *sql.DB of datasource.* when datasource.enabled is true and
+// registers it as the db instance, with the DataSource component checking its
+// health. The application imports the driver:
+//
+//	import _ "github.com/lib/pq"
+//
+// With a datasource.primary section (see RoutingConfig), the db instance is the
+// primary and the dataSource component is a RoutingDataSource sending the
+// operations of WithReadOnly contexts to the replicas.
+//
+// Register the starter before the starters needing a *sql.DB (sql, outbox, batch).
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"DataSourceStarter",
+		container.PropertyCondition(PropertyDataSource+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var routing RoutingConfig
+			if err := builder.GetVariableAs(PropertyDataSource, &routing); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyDataSource, err)
+			}
+			if routing.configured() {
+				dataSource, err := OpenRouting(DataSourceName, routing)
+				if err != nil {
+					return err
+				}
+				if err := builder.RegisterInstance(DBName, dataSource.Primary()); err != nil {
+					return err
+				}
+				return builder.RegisterComponent(dataSource)
+			}
+
+			var config Config
+			if err := builder.GetVariableAs(PropertyDataSource, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyDataSource, err)
+			}
+			db, err := Open(config)
+			if err != nil {
+				return err
+			}
+			if err := builder.RegisterInstance(DBName, db); err != nil {
+				return err
+			}
+			return builder.RegisterComponent(NewDataSource(DataSourceName, db, config))
+		},
+	)
+}
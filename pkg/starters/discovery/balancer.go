@@ -0,0 +1,218 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"net/url"
+	"strings"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// LoadBalancerConfig configures the load balancer: discovery.load-balancer.*
+type LoadBalancerConfig struct {
+	// Refresh is how long the instances of a service are cached (default 5s)
+	Refresh time.Duration `yaml:"refresh"`
+	// Cooldown is how long an instance is skipped after a failed request (default 30s)
+	Cooldown time.Duration `yaml:"cooldown"`
+	// Clients restricts the decorated http-client clients, empty for all of them
+	Clients []string `yaml:"clients"`
+}
+
+func (c LoadBalancerConfig) withDefaults() LoadBalancerConfig {
+	if c.Refresh <= 0 {
+		c.Refresh = 5 * time.Second
+	}
+	if c.Cooldown <= 0 {
+		c.Cooldown = 30 * time.Second
+	}
+	return c
+}
+
+// serviceInstances is the cached instance list of one service
+type serviceInstances struct {
+	instances []Instance
+	fetched   time.Time
+	next      atomic.Uint64
+}
+
+// LoadBalancer picks an instance of a service in round-robin order, skipping the
+// instances the registry reports unhealthy and, for a cooldown, the instances a
+// request failed on. Its transport resolves logical service names: a request to
+// http://orders/items is sent to one of the instances of the orders service.
+type LoadBalancer struct {
+	client DiscoveryClient
+	config LoadBalancerConfig
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	services map[string]*serviceInstances
+	// failed holds the end of the cooldown of failed instances by ID
+	failed map[string]time.Time
+}
+
+// NewLoadBalancer creates a load balancer over the instances listed by client
+func NewLoadBalancer(client DiscoveryClient, config LoadBalancerConfig) *LoadBalancer {
+	return &LoadBalancer{
+		client:   client,
+		config:   config.withDefaults(),
+		logger:   slog.Default(),
+		services: make(map[string]*serviceInstances),
+		failed:   make(map[string]time.Time),
+	}
+}
+
+// Name returns the component name
+func (b *LoadBalancer) Name() string {
+	return "loadBalancer"
+}
+
+// Init is a no-op
+func (b *LoadBalancer) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Choose returns the next available instance of a service. If every healthy instance
+// is cooling down after a failure, they are tried anyway.
+func (b *LoadBalancer) Choose(ctx context.Context, service string) (Instance, error) {
+	cached, err := b.instances(ctx, service)
+	if err != nil {
+		return Instance{}, err
+	}
+
+	now := time.Now()
+	var healthy, available []Instance
+	b.mu.Lock()
+	for _, instance := range cached.instances {
+		if !instance.Healthy {
+			continue
+		}
+		healthy = append(healthy, instance)
+		if until, ok := b.failed[instance.ID]; !ok || now.After(until) {
+			available = append(available, instance)
+		}
+	}
+	b.mu.Unlock()
+
+	if len(available) == 0 {
+		available = healthy
+	}
+	if len(available) == 0 {
+		return Instance{}, fmt.Errorf("discovery: no healthy instance of service %s", service)
+	}
+	return available[(cached.next.Add(1)-1)%uint64(len(available))], nil
+}
+
+// MarkFailed skips an instance for the cooldown
+func (b *LoadBalancer) MarkFailed(instance Instance) {
+	b.mu.Lock()
+	b.failed[instance.ID] = time.Now().Add(b.config.Cooldown)
+	b.mu.Unlock()
+	b.logger.Warn("Service instance failed", "service", instance.Service, "id", instance.ID, "cooldown", b.config.Cooldown)
+}
+
+// Transport returns a transport resolving logical service names through next
+func (b *LoadBalancer) Transport(next http.RoundTripper) http.RoundTripper {
+	if next == nil {
+		next = http.DefaultTransport
+	}
+	return &balancedTransport{balancer: b, next: next}
+}
+
+// Client returns an HTTP client resolving logical service names
+func (b *LoadBalancer) Client() *http.Client {
+	return &http.Client{Transport: b.Transport(nil)}
+}
+
+// DecorateTransport makes the http-client starter's clients resolve logical service
+// names; it implements httpclient.TransportDecorator
+func (b *LoadBalancer) DecorateTransport(client string, next http.RoundTripper) http.RoundTripper {
+	if len(b.config.Clients) == 0 {
+		return b.Transport(next)
+	}
+	for _, name := range b.config.Clients {
+		if name == client {
+			return b.Transport(next)
+		}
+	}
+	return next
+}
+
+// instances returns the cached instances of a service, fetching them again once
+// the refresh interval elapsed. If fetching fails, stale instances are kept.
+func (b *LoadBalancer) instances(ctx context.Context, service string) (*serviceInstances, error) {
+	b.mu.Lock()
+	cached, ok := b.services[service]
+	b.mu.Unlock()
+	if ok && time.Since(cached.fetched) < b.config.Refresh {
+		return cached, nil
+	}
+
+	instances, err := b.client.Instances(ctx, service)
+	if err != nil {
+		if ok {
+			b.logger.Warn("Service instances refresh failed, using cached instances", "service", service, "error", err)
+			return cached, nil
+		}
+		return nil, fmt.Errorf("discovery: instances of service %s: %w", service, err)
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	cached = &serviceInstances{instances: instances, fetched: time.Now()}
+	// Keep the round-robin position across refreshes
+	if current, ok := b.services[service]; ok {
+		cached.next.Store(current.next.Load())
+	}
+	b.services[service] = cached
+	return cached, nil
+}
+
+// balancedTransport sends requests to logical service names to an instance of the service
+type balancedTransport struct {
+	balancer *LoadBalancer
+	next     http.RoundTripper
+}
+
+func (t *balancedTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isServiceName(req.URL) {
+		return t.next.RoundTrip(req)
+	}
+
+	instance, err := t.balancer.Choose(req.Context(), req.URL.Hostname())
+	if err != nil {
+		return nil, err
+	}
+
+	out := req.Clone(req.Context())
+	out.URL.Host = instance.Address()
+	out.Host = ""
+	if instance.Scheme != "" {
+		out.URL.Scheme = instance.Scheme
+	}
+
+	resp, err := t.next.RoundTrip(out)
+	if err != nil || isUnavailable(resp.StatusCode) {
+		t.balancer.MarkFailed(instance)
+	}
+	return resp, err
+}
+
+// isServiceName reports whether the host of a URL is a logical service name: a
+// single label without port that is neither localhost nor an IP address
+func isServiceName(u *url.URL) bool {
+	host := u.Hostname()
+	return host != "" && u.Port() == "" && !strings.Contains(host, ".") &&
+		host != "localhost" && net.ParseIP(host) == nil
+}
+
+// isUnavailable reports whether a status means the instance can't serve requests
+func isUnavailable(status int) bool {
+	return status == http.StatusBadGateway || status == http.StatusServiceUnavailable || status == http.StatusGatewayTimeout
+}
@@ -0,0 +1,241 @@
+package discovery
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// ConsulConfig configures the Consul registry: discovery.consul.*
+type ConsulConfig struct {
+	// Address of the Consul agent (default http://127.0.0.1:8500)
+	Address string `yaml:"address"`
+	// Token is the ACL token sent with every request
+	Token string `yaml:"token"`
+	// TTL is how long an instance stays healthy without heartbeat (default 3 heartbeats)
+	TTL time.Duration `yaml:"ttl"`
+	// DeregisterAfter removes an instance that stayed critical that long (default 1m)
+	DeregisterAfter time.Duration `yaml:"deregister-after"`
+}
+
+func (c ConsulConfig) withDefaults(heartbeat time.Duration) ConsulConfig {
+	if c.Address == "" {
+		c.Address = "http://127.0.0.1:8500"
+	}
+	c.Address = strings.TrimSuffix(c.Address, "/")
+	if c.TTL <= 0 {
+		c.TTL = 3 * heartbeat
+	}
+	if c.DeregisterAfter <= 0 {
+		c.DeregisterAfter = time.Minute
+	}
+	return c
+}
+
+// ConsulRegistry registers instances with the local Consul agent using a TTL check
+// passed by every heartbeat
+type ConsulRegistry struct {
+	config ConsulConfig
+	client *http.Client
+}
+
+// NewConsulRegistry creates a registry talking to the agent at config.Address
+func NewConsulRegistry(config ConsulConfig, heartbeat time.Duration) *ConsulRegistry {
+	return &ConsulRegistry{
+		config: config.withDefaults(heartbeat),
+		client: &http.Client{Timeout: 10 * time.Second},
+	}
+}
+
+// Name returns the component name
+func (r *ConsulRegistry) Name() string {
+	return "consulRegistry"
+}
+
+// Init is a no-op
+func (r *ConsulRegistry) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// consulService is the body of the agent's service registration
+type consulService struct {
+	ID      string            `json:"ID"`
+	Name    string            `json:"Name"`
+	Address string            `json:"Address"`
+	Port    int               `json:"Port"`
+	Meta    map[string]string `json:"Meta,omitempty"`
+	Checks  []consulCheck     `json:"Checks"`
+}
+
+type consulCheck struct {
+	CheckID                        string `json:"CheckID"`
+	Name                           string `json:"Name"`
+	TTL                            string `json:"TTL,omitempty"`
+	HTTP                           string `json:"HTTP,omitempty"`
+	Interval                       string `json:"Interval,omitempty"`
+	DeregisterCriticalServiceAfter string `json:"DeregisterCriticalServiceAfter"`
+}
+
+// Register registers the instance with a TTL check and, with a HealthURL, an HTTP check
+func (r *ConsulRegistry) Register(ctx context.Context, instance Instance) error {
+	meta := make(map[string]string, len(instance.Metadata)+1)
+	for key, value := range instance.Metadata {
+		meta[key] = value
+	}
+	if instance.Scheme != "" {
+		meta["scheme"] = instance.Scheme
+	}
+
+	deregister := r.config.DeregisterAfter.String()
+	service := consulService{
+		ID:      instance.ID,
+		Name:    instance.Service,
+		Address: instance.Host,
+		Port:    instance.Port,
+		Meta:    meta,
+		Checks: []consulCheck{{
+			CheckID:                        checkID(instance.ID),
+			Name:                           "heartbeat",
+			TTL:                            r.config.TTL.String(),
+			DeregisterCriticalServiceAfter: deregister,
+		}},
+	}
+	if instance.HealthURL != "" {
+		service.Checks = append(service.Checks, consulCheck{
+			CheckID:                        checkID(instance.ID) + ":http",
+			Name:                           "health",
+			HTTP:                           instance.HealthURL,
+			Interval:                       r.config.TTL.String(),
+			DeregisterCriticalServiceAfter: deregister,
+		})
+	}
+
+	body, err := json.Marshal(service)
+	if err != nil {
+		return err
+	}
+	return r.put(ctx, "/v1/agent/service/register", body)
+}
+
+// Deregister removes the instance from the agent
+func (r *ConsulRegistry) Deregister(ctx context.Context, id string) error {
+	return r.put(ctx, "/v1/agent/service/deregister/"+url.PathEscape(id), nil)
+}
+
+// Heartbeat passes the TTL check, or fails it if the instance is DOWN
+func (r *ConsulRegistry) Heartbeat(ctx context.Context, id string, status container.HealthStatus) error {
+	state := "pass"
+	if status == container.HealthDown {
+		state = "fail"
+	}
+	return r.put(ctx, fmt.Sprintf("/v1/agent/check/%s/%s?note=%s", state, url.PathEscape(checkID(id)), url.QueryEscape(string(status))), nil)
+}
+
+// consulEntry is an entry of the agent's service health endpoint
+type consulEntry struct {
+	Node struct {
+		Address string `json:"Address"`
+	} `json:"Node"`
+	Service struct {
+		ID      string            `json:"ID"`
+		Service string            `json:"Service"`
+		Address string            `json:"Address"`
+		Port    int               `json:"Port"`
+		Meta    map[string]string `json:"Meta"`
+	} `json:"Service"`
+	Checks []struct {
+		Status string `json:"Status"`
+	} `json:"Checks"`
+}
+
+// Instances returns the instances of a service; an instance is healthy if all its
+// checks pass
+func (r *ConsulRegistry) Instances(ctx context.Context, service string) ([]Instance, error) {
+	path := "/v1/health/service/" + url.PathEscape(service)
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, r.config.Address+path, nil)
+	if err != nil {
+		return nil, err
+	}
+	if r.config.Token != "" {
+		req.Header.Set("X-Consul-Token", r.config.Token)
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("consul %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		return nil, fmt.Errorf("consul %s: %s", path, resp.Status)
+	}
+
+	var entries []consulEntry
+	if err := json.NewDecoder(resp.Body).Decode(&entries); err != nil {
+		return nil, fmt.Errorf("consul %s: %w", path, err)
+	}
+	instances := make([]Instance, 0, len(entries))
+	for _, entry := range entries {
+		instance := Instance{
+			ID:       entry.Service.ID,
+			Service:  entry.Service.Service,
+			Host:     entry.Service.Address,
+			Port:     entry.Service.Port,
+			Scheme:   entry.Service.Meta["scheme"],
+			Metadata: entry.Service.Meta,
+			Healthy:  true,
+		}
+		if instance.Host == "" {
+			instance.Host = entry.Node.Address
+		}
+		for _, check := range entry.Checks {
+			if check.Status != "passing" {
+				instance.Healthy = false
+			}
+		}
+		instances = append(instances, instance)
+	}
+	return instances, nil
+}
+
+func (r *ConsulRegistry) put(ctx context.Context, path string, body []byte) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodPut, r.config.Address+path, bytes.NewReader(body))
+	if err != nil {
+		return err
+	}
+	if r.config.Token != "" {
+		req.Header.Set("X-Consul-Token", r.config.Token)
+	}
+	if body != nil {
+		req.Header.Set("Content-Type", "application/json")
+	}
+
+	resp, err := r.client.Do(req)
+	if err != nil {
+		return fmt.Errorf("consul %s: %w", path, err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode >= 300 {
+		message, _ := io.ReadAll(io.LimitReader(resp.Body, 1024))
+		return fmt.Errorf("consul %s: %s: %s", path, resp.Status, strings.TrimSpace(string(message)))
+	}
+	return nil
+}
+
+// checkID returns the ID of the TTL check of an instance
+func checkID(id string) string {
+	return "service:" + id
+}
+
+// Ensure that ConsulRegistry implements ServiceRegistry and DiscoveryClient
+var (
+	_ ServiceRegistry = (*ConsulRegistry)(nil)
+	_ DiscoveryClient = (*ConsulRegistry)(nil)
+)
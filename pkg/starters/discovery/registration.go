@@ -0,0 +1,207 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"os"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/web"
+)
+
+// PropertyInfo is the section whose variables become the instance metadata, e.g.
+// info.version becomes the metadata entry version
+const PropertyInfo = "info"
+
+// deregisterTimeout bounds deregistration when the stop context is already done
+const deregisterTimeout = 5 * time.Second
+
+// Registration registers the instance on start, reports the aggregated health of
+// the application on every heartbeat and deregisters the instance on stop. It runs
+// after the web server started, so that a random server port is known.
+type Registration struct {
+	registry ServiceRegistry
+	config   Config
+	app      container.ApplicationContext
+	server   *web.Server
+	logger   *slog.Logger
+
+	mu         sync.Mutex
+	instance   Instance
+	registered bool
+}
+
+// NewRegistration creates the registration of this instance
+func NewRegistration(registry ServiceRegistry, config Config) *Registration {
+	return &Registration{registry: registry, config: config.withDefaults(), logger: slog.Default()}
+}
+
+// Name returns the component name
+func (r *Registration) Name() string {
+	return "serviceRegistration"
+}
+
+// Init looks up the web server, if any
+func (r *Registration) Init(app container.ApplicationContext) error {
+	r.app = app
+	if app.HasComponent("webServer") {
+		if err := app.GetComponent(&r.server); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Start registers the instance; a failed registration is retried on every heartbeat
+func (r *Registration) Start(ctx context.Context) {
+	instance, err := r.buildInstance()
+	if err != nil {
+		panic(err)
+	}
+
+	r.mu.Lock()
+	r.instance = instance
+	r.mu.Unlock()
+	r.register(ctx)
+}
+
+// Stop deregisters the instance
+func (r *Registration) Stop(ctx context.Context) {
+	r.mu.Lock()
+	registered, id := r.registered, r.instance.ID
+	r.registered = false
+	r.mu.Unlock()
+	if !registered {
+		return
+	}
+
+	if ctx.Err() != nil {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(context.WithoutCancel(ctx), deregisterTimeout)
+		defer cancel()
+	}
+	if err := r.registry.Deregister(ctx, id); err != nil {
+		r.logger.Error("Service deregistration failed", "id", id, "error", err)
+		return
+	}
+	r.logger.Info("Service deregistered", "id", id)
+}
+
+// GetSchedule returns the heartbeat schedule
+func (r *Registration) GetSchedule() container.Schedule {
+	return container.Schedule{Interval: r.config.HeartbeatInterval}
+}
+
+// Execute sends a heartbeat with the aggregated health, registering the instance
+// again if the registry lost it
+func (r *Registration) Execute(ctx context.Context) {
+	r.mu.Lock()
+	registered, id := r.registered, r.instance.ID
+	r.mu.Unlock()
+	if !registered {
+		r.register(ctx)
+		return
+	}
+
+	status := container.AggregateHealth(ctx, r.app).Status
+	if err := r.registry.Heartbeat(ctx, id, status); err != nil {
+		r.logger.Warn("Service heartbeat failed, registering again", "id", id, "error", err)
+		r.mu.Lock()
+		r.registered = false
+		r.mu.Unlock()
+	}
+}
+
+// Instance returns the registered instance
+func (r *Registration) Instance() Instance {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	return r.instance
+}
+
+func (r *Registration) register(ctx context.Context) {
+	r.mu.Lock()
+	instance := r.instance
+	r.mu.Unlock()
+
+	if err := r.registry.Register(ctx, instance); err != nil {
+		r.logger.Error("Service registration failed", "service", instance.Service, "id", instance.ID, "error", err)
+		return
+	}
+	r.mu.Lock()
+	r.registered = true
+	r.mu.Unlock()
+	r.logger.Info("Service registered",
+		"service", instance.Service,
+		"id", instance.ID,
+		"address", instance.Address())
+}
+
+// buildInstance describes this instance from the configuration and the web server
+func (r *Registration) buildInstance() (Instance, error) {
+	instance := Instance{
+		ID:       r.config.InstanceID,
+		Service:  r.config.ServiceName,
+		Host:     r.config.Host,
+		Port:     r.config.Port,
+		Scheme:   r.config.Scheme,
+		Metadata: metadata(r.app),
+	}
+
+	if instance.Host == "" {
+		host, err := os.Hostname()
+		if err != nil {
+			return Instance{}, fmt.Errorf("discovery: can't determine host: %w", err)
+		}
+		instance.Host = host
+	}
+	if instance.Port == 0 && r.server != nil {
+		if addr, ok := r.server.Addr().(*net.TCPAddr); ok {
+			instance.Port = addr.Port
+		}
+	}
+	if instance.Port == 0 {
+		return Instance{}, fmt.Errorf("discovery: %s.port is required without a running web server", PropertyDiscovery)
+	}
+	if instance.ID == "" {
+		instance.ID = instance.Service + "-" + instance.Host + "-" + strconv.Itoa(instance.Port)
+	}
+	if r.config.HealthPath != "" {
+		instance.HealthURL = instance.Scheme + "://" + instance.Address() + r.config.HealthPath
+	}
+	return instance, nil
+}
+
+// metadata flattens the info section into dotted keys
+func metadata(app container.ApplicationContext) map[string]string {
+	if !container.NewVariableHelper(app).HasSection(PropertyInfo) {
+		return nil
+	}
+	info := map[string]interface{}{}
+	if err := app.GetVariableAs(PropertyInfo, &info); err != nil {
+		return nil
+	}
+
+	result := make(map[string]string)
+	var flatten func(prefix string, values map[string]interface{})
+	flatten = func(prefix string, values map[string]interface{}) {
+		for key, value := range values {
+			switch value := value.(type) {
+			case map[string]interface{}:
+				flatten(prefix+key+".", value)
+			default:
+				result[prefix+key] = fmt.Sprint(value)
+			}
+		}
+	}
+	flatten("", info)
+	return result
+}
+
+// Ensure that Registration implements container.ScheduledComponent
+var _ container.ScheduledComponent = (*Registration)(nil)
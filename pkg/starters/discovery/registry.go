@@ -0,0 +1,53 @@
+// Package discovery registers the running instance with a service registry such as
+// Consul, keeps its health up to date and deregisters it on shutdown. Clients find
+// the instances of other services through a DiscoveryClient and call them by
+// logical name (http://orders/) through the LoadBalancer.
+package discovery
+
+import (
+	"context"
+	"net"
+	"strconv"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Instance is a running instance of a service
+type Instance struct {
+	// ID identifies the instance in the registry
+	ID      string
+	Service string
+	Host    string
+	Port    int
+	// Scheme is http or https
+	Scheme string
+	// HealthURL is where the registry or peers can check the instance's health
+	HealthURL string
+	Metadata  map[string]string
+	// Healthy reports whether the registry considers the instance healthy; it is
+	// set by DiscoveryClients
+	Healthy bool
+}
+
+// Address returns host:port
+func (i Instance) Address() string {
+	return net.JoinHostPort(i.Host, strconv.Itoa(i.Port))
+}
+
+// ServiceRegistry registers instances with a registry. Register a component
+// implementing it to use a registry other than the built-in ones.
+type ServiceRegistry interface {
+	// Register adds or replaces an instance
+	Register(ctx context.Context, instance Instance) error
+	// Deregister removes an instance
+	Deregister(ctx context.Context, id string) error
+	// Heartbeat reports that an instance is alive with the given health
+	Heartbeat(ctx context.Context, id string, status container.HealthStatus) error
+}
+
+// DiscoveryClient lists the instances of services. Register a component
+// implementing it to use a registry other than the built-in ones.
+type DiscoveryClient interface {
+	// Instances returns the instances of a service, healthy or not
+	Instances(ctx context.Context, service string) ([]Instance, error)
+}
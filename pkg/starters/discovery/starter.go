@@ -0,0 +1,116 @@
+package discovery
+
+import (
+	"fmt"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyDiscovery holds the discovery configuration: discovery.*
+const PropertyDiscovery = "discovery"
+
+// Registry types
+const (
+	// RegistryConsul registers with the local Consul agent
+	RegistryConsul = "consul"
+)
+
+// Config configures the registration and discovery: discovery.*
+type Config struct {
+	// Registry is consul, or empty to use ServiceRegistry and DiscoveryClient components
+	Registry string `yaml:"registry"`
+	// ServiceName is the logical name of the service; without it the instance
+	// isn't registered and only discovers other services
+	ServiceName string `yaml:"service-name"`
+	// InstanceID defaults to <service>-<host>-<port>
+	InstanceID string `yaml:"instance-id"`
+	// Host is the advertised host (default the hostname)
+	Host string `yaml:"host"`
+	// Port is the advertised port (default the web server's port)
+	Port   int    `yaml:"port"`
+	Scheme string `yaml:"scheme"`
+	// HealthPath is appended to the instance address to build its health URL
+	HealthPath string `yaml:"health-path"`
+	// HeartbeatInterval is how often the health is reported (default 10s)
+	HeartbeatInterval time.Duration `yaml:"heartbeat-interval"`
+	Consul            ConsulConfig  `yaml:"consul"`
+	// Services lists static instances by service, as host:port or scheme://host:port
+	Services     map[string][]string `yaml:"services"`
+	LoadBalancer LoadBalancerConfig  `yaml:"load-balancer"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Scheme == "" {
+		c.Scheme = "http"
+	}
+	if c.HeartbeatInterval <= 0 {
+		c.HeartbeatInterval = 10 * time.Second
+	}
+	return c
+}
+
+// Starter registers this instance with a service registry when discovery.enabled is
+// true. With discovery.registry set to consul it talks to the Consul agent;
+// otherwise it uses the ServiceRegistry component registered in the setup block.
+//
+// If a DiscoveryClient is available (Consul, discovery.services or a component),
+// it also registers the LoadBalancer, which decorates the clients of the
+// http-client starter when registered before it.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"DiscoveryStarter",
+		container.PropertyCondition(PropertyDiscovery+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertyDiscovery, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyDiscovery, err)
+			}
+			config = config.withDefaults()
+
+			var registry ServiceRegistry
+			var client DiscoveryClient
+			switch config.Registry {
+			case RegistryConsul:
+				consul := NewConsulRegistry(config.Consul, config.HeartbeatInterval)
+				if err := builder.RegisterComponent(consul); err != nil {
+					return err
+				}
+				registry, client = consul, consul
+			case "":
+				if config.ServiceName != "" {
+					if err := builder.GetComponent(&registry); err != nil {
+						return fmt.Errorf("discovery requires a ServiceRegistry: %w", err)
+					}
+				}
+				// A DiscoveryClient component is optional
+				if err := builder.GetComponent(&client); err != nil {
+					client = nil
+				}
+			default:
+				return fmt.Errorf("unsupported %s.registry %q", PropertyDiscovery, config.Registry)
+			}
+
+			if len(config.Services) > 0 {
+				static, err := NewStaticDiscovery(config.Services, client)
+				if err != nil {
+					return err
+				}
+				if err := builder.RegisterComponent(static); err != nil {
+					return err
+				}
+				client = static
+			}
+			if client != nil {
+				if err := builder.RegisterComponent(NewLoadBalancer(client, config.LoadBalancer)); err != nil {
+					return err
+				}
+			}
+
+			if config.ServiceName == "" {
+				return nil
+			}
+			return builder.RegisterComponent(NewRegistration(registry, config))
+		},
+	)
+}
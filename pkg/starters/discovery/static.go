@@ -0,0 +1,92 @@
+package discovery
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"net/url"
+	"strconv"
+	"strings"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// StaticDiscovery lists the instances configured in discovery.services, e.g.
+//
+//	discovery:
+//	  services:
+//	    orders: [ "10.0.0.1:8080", "https://10.0.0.2:8443" ]
+//
+// Services that aren't configured are looked up in the fallback client, if any.
+type StaticDiscovery struct {
+	services map[string][]Instance
+	fallback DiscoveryClient
+}
+
+// NewStaticDiscovery creates a client over the given addresses by service, as
+// host:port or scheme://host:port. fallback may be nil.
+func NewStaticDiscovery(services map[string][]string, fallback DiscoveryClient) (*StaticDiscovery, error) {
+	d := &StaticDiscovery{services: make(map[string][]Instance, len(services)), fallback: fallback}
+	for service, addresses := range services {
+		for _, address := range addresses {
+			instance, err := parseInstance(service, address)
+			if err != nil {
+				return nil, fmt.Errorf("%s.services.%s: %w", PropertyDiscovery, service, err)
+			}
+			d.services[service] = append(d.services[service], instance)
+		}
+	}
+	return d, nil
+}
+
+// Name returns the component name
+func (d *StaticDiscovery) Name() string {
+	return "staticDiscovery"
+}
+
+// Init is a no-op
+func (d *StaticDiscovery) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Instances returns the configured instances of a service, which are always healthy
+func (d *StaticDiscovery) Instances(ctx context.Context, service string) ([]Instance, error) {
+	if instances, ok := d.services[service]; ok {
+		return append([]Instance(nil), instances...), nil
+	}
+	if d.fallback != nil {
+		return d.fallback.Instances(ctx, service)
+	}
+	return nil, fmt.Errorf("unknown service %s", service)
+}
+
+// parseInstance parses host:port or scheme://host:port
+func parseInstance(service, address string) (Instance, error) {
+	scheme := ""
+	if strings.Contains(address, "://") {
+		u, err := url.Parse(address)
+		if err != nil {
+			return Instance{}, err
+		}
+		scheme, address = u.Scheme, u.Host
+	}
+	host, portText, err := net.SplitHostPort(address)
+	if err != nil {
+		return Instance{}, err
+	}
+	port, err := strconv.Atoi(portText)
+	if err != nil {
+		return Instance{}, fmt.Errorf("invalid port in %q", address)
+	}
+	return Instance{
+		ID:      service + "-" + address,
+		Service: service,
+		Host:    host,
+		Port:    port,
+		Scheme:  scheme,
+		Healthy: true,
+	}, nil
+}
+
+// Ensure that StaticDiscovery implements DiscoveryClient
+var _ DiscoveryClient = (*StaticDiscovery)(nil)
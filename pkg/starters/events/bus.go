@@ -0,0 +1,368 @@
+// Package events provides an in-process event bus with synchronous and
+// asynchronous listeners
+package events
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"log/slog"
+	"reflect"
+	"runtime/debug"
+	"sort"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/propagation"
+)
+
+// Listener handles a published event
+type Listener func(ctx context.Context, event any) error
+
+// DeliveryFilter decides whether an event is delivered to a listener; false
+// drops the delivery as if the listener had handled the event
+type DeliveryFilter func(ctx context.Context, event any) bool
+
+// DeadLetterHandler receives events a listener failed to handle after all retries
+type DeadLetterHandler func(ctx context.Context, event any, err error)
+
+// listenerConfig holds the options of a subscription
+type listenerConfig struct {
+	async      bool
+	order      int
+	eventType  reflect.Type
+	filter     func(any) bool
+	attempts   int
+	backoff    time.Duration
+	deadLetter DeadLetterHandler
+}
+
+// ListenerOption configures a subscription
+type ListenerOption func(*listenerConfig)
+
+// Async runs the listener on the bus's TaskExecutor instead of the publisher's goroutine
+func Async() ListenerOption {
+	return func(c *listenerConfig) { c.async = true }
+}
+
+// Order sets the listener order; lower values are notified first (default 0,
+// ties are notified in subscription order)
+func Order(order int) ListenerOption {
+	return func(c *listenerConfig) { c.order = order }
+}
+
+// OfType only delivers events whose dynamic type is assignable to T
+func OfType[T any]() ListenerOption {
+	return func(c *listenerConfig) { c.eventType = reflect.TypeOf((*T)(nil)).Elem() }
+}
+
+// Filter only delivers events for which predicate returns true
+func Filter(predicate func(event any) bool) ListenerOption {
+	return func(c *listenerConfig) { c.filter = predicate }
+}
+
+// Retry calls a failing listener up to attempts times, waiting backoff between calls
+func Retry(attempts int, backoff time.Duration) ListenerOption {
+	return func(c *listenerConfig) {
+		c.attempts = attempts
+		c.backoff = backoff
+	}
+}
+
+// DeadLetter receives events the listener still failed to handle after retries.
+// Without it, failures of synchronous listeners are returned from Publish and
+// failures of asynchronous ones go to the bus's dead-letter handler or are logged.
+func DeadLetter(handler DeadLetterHandler) ListenerOption {
+	return func(c *listenerConfig) { c.deadLetter = handler }
+}
+
+// VariablesChanged is published by the bus when reloaded variables changed (see
+// container.VariableReloader); listeners read the new values from the context
+type VariablesChanged struct {
+	// Names are the sorted names of the changed variables
+	Names []string
+}
+
+// subscription is a registered listener
+type subscription struct {
+	id       uint64
+	listener Listener
+	config   listenerConfig
+}
+
+// Subscription allows removing a listener
+type Subscription struct {
+	bus *EventBus
+	id  uint64
+}
+
+// Unsubscribe removes the listener from the bus
+func (s Subscription) Unsubscribe() {
+	s.bus.mu.Lock()
+	defer s.bus.mu.Unlock()
+
+	for i, sub := range s.bus.subscriptions {
+		if sub.id == s.id {
+			s.bus.subscriptions = append(s.bus.subscriptions[:i:i], s.bus.subscriptions[i+1:]...)
+			return
+		}
+	}
+}
+
+// EventBus dispatches events to listeners
+type EventBus struct {
+	executor   TaskExecutor
+	propagator *propagation.Propagator
+	deadLetter DeadLetterHandler
+	filters    []DeliveryFilter
+	// app finds the ErrorReporter components
+	app    container.ApplicationContext
+	logger *slog.Logger
+
+	mu            sync.RWMutex
+	subscriptions []*subscription
+	nextID        uint64
+}
+
+// NewEventBus creates a bus; asynchronous listeners run on the TaskExecutor
+// component resolved during Init
+func NewEventBus() *EventBus {
+	return &EventBus{logger: slog.Default()}
+}
+
+// Name returns the component name
+func (b *EventBus) Name() string {
+	return "eventBus"
+}
+
+// Init resolves the TaskExecutor and the propagation.Propagator, if any; listeners
+// subscribe themselves in their own Init. Listeners failing after their retries
+// are reported to the container.ErrorReporter components.
+func (b *EventBus) Init(ctx container.ApplicationContext) error {
+	var executor TaskExecutor
+	if err := ctx.GetComponent(&executor); err != nil {
+		return fmt.Errorf("event bus requires a TaskExecutor: %w", err)
+	}
+	var propagator *propagation.Propagator
+	if ctx.HasComponent("propagator") {
+		comp, err := ctx.GetComponentByName("propagator")
+		if err != nil {
+			return err
+		}
+		propagator, _ = comp.(*propagation.Propagator)
+	}
+
+	// Init also runs during dependency discovery, where listeners may already have
+	// subscribed and filters been added; they are added again in the real Init of
+	// their components, which runs after this one
+	b.mu.Lock()
+	b.executor = executor
+	b.propagator = propagator
+	b.app = ctx
+	b.subscriptions = nil
+	b.filters = nil
+	b.mu.Unlock()
+	return nil
+}
+
+// SetDeadLetterHandler sets the handler for failed events of listeners without their own
+func (b *EventBus) SetDeadLetterHandler(handler DeadLetterHandler) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.deadLetter = handler
+}
+
+// AddDeliveryFilter adds a filter consulted before every delivery to a listener,
+// e.g. to drop deliveries in resilience tests
+func (b *EventBus) AddDeliveryFilter(filter DeliveryFilter) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.filters = append(b.filters, filter)
+}
+
+// Subscribe registers a listener
+func (b *EventBus) Subscribe(listener Listener, opts ...ListenerOption) Subscription {
+	config := listenerConfig{attempts: 1}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	if config.attempts < 1 {
+		config.attempts = 1
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.nextID++
+	b.subscriptions = append(b.subscriptions, &subscription{id: b.nextID, listener: listener, config: config})
+	sort.SliceStable(b.subscriptions, func(i, j int) bool {
+		return b.subscriptions[i].config.order < b.subscriptions[j].config.order
+	})
+	return Subscription{bus: b, id: b.nextID}
+}
+
+// Subscribe registers a typed listener that only receives events of type T
+func Subscribe[T any](bus *EventBus, listener func(ctx context.Context, event T) error, opts ...ListenerOption) Subscription {
+	opts = append([]ListenerOption{OfType[T]()}, opts...)
+	return bus.Subscribe(func(ctx context.Context, event any) error {
+		return listener(ctx, event.(T))
+	}, opts...)
+}
+
+// Publish notifies matching listeners in order. Synchronous listeners run before
+// Publish returns; the errors of those without a dead-letter handler are returned.
+// With a propagation.Propagator, listeners get the correlation ID of the publisher
+// (a new one if it has none) and events implementing propagation.Carrier carry it.
+func (b *EventBus) Publish(ctx context.Context, event any) error {
+	b.mu.RLock()
+	subscriptions := make([]*subscription, len(b.subscriptions))
+	copy(subscriptions, b.subscriptions)
+	propagator := b.propagator
+	b.mu.RUnlock()
+
+	if propagator != nil {
+		ctx = propagator.EventContext(ctx, event)
+	}
+
+	var errs []error
+	for _, sub := range subscriptions {
+		if !sub.matches(event) {
+			continue
+		}
+
+		if sub.config.async {
+			sub := sub
+			if err := b.executor.Submit(func() {
+				if err := b.deliver(context.WithoutCancel(ctx), sub, event); err != nil {
+					b.report(ctx, sub, event, err)
+					b.fail(ctx, sub, event, err)
+				}
+			}); err != nil {
+				errs = append(errs, fmt.Errorf("submit async listener: %w", err))
+			}
+			continue
+		}
+
+		if err := b.deliver(ctx, sub, event); err != nil {
+			b.report(ctx, sub, event, err)
+			if sub.config.deadLetter != nil {
+				sub.config.deadLetter(ctx, event, err)
+			} else {
+				errs = append(errs, err)
+			}
+		}
+	}
+
+	return errors.Join(errs...)
+}
+
+func (s *subscription) matches(event any) bool {
+	if s.config.eventType != nil {
+		if event == nil || !reflect.TypeOf(event).AssignableTo(s.config.eventType) {
+			return false
+		}
+	}
+	return s.config.filter == nil || s.config.filter(event)
+}
+
+// deliver calls the listener, retrying according to its policy
+func (b *EventBus) deliver(ctx context.Context, sub *subscription, event any) error {
+	b.mu.RLock()
+	filters := b.filters
+	b.mu.RUnlock()
+	for _, filter := range filters {
+		if !filter(ctx, event) {
+			return nil
+		}
+	}
+
+	var err error
+	for attempt := 1; attempt <= sub.config.attempts; attempt++ {
+		if err = safeCall(ctx, sub.listener, event); err == nil {
+			return nil
+		}
+		if attempt == sub.config.attempts {
+			break
+		}
+
+		b.logger.Debug("Event listener failed, retrying", "attempt", attempt, "error", err)
+		select {
+		case <-ctx.Done():
+			return errors.Join(err, ctx.Err())
+		case <-time.After(sub.config.backoff):
+		}
+	}
+	return err
+}
+
+// OnVariablesChanged publishes VariablesChanged to the listeners
+func (b *EventBus) OnVariablesChanged(changed []string) {
+	if err := b.Publish(context.Background(), VariablesChanged{Names: changed}); err != nil {
+		b.logger.Error("Variables change listener failed", "error", err)
+	}
+}
+
+// report passes a failed delivery to the container.ErrorReporter components
+func (b *EventBus) report(ctx context.Context, sub *subscription, event any, err error) {
+	b.mu.RLock()
+	app := b.app
+	b.mu.RUnlock()
+	if app == nil {
+		return
+	}
+
+	var panicErr *listenerPanic
+	report := container.ErrorReport{
+		Kind:      container.ErrorKindListener,
+		Component: b.Name(),
+		Err:       err,
+		Metadata: map[string]string{
+			"event.type":     fmt.Sprintf("%T", event),
+			"listener.async": strconv.FormatBool(sub.config.async),
+		},
+	}
+	if errors.As(err, &panicErr) {
+		report.Panicked = true
+		report.Stack = panicErr.stack
+	}
+	container.ReportError(ctx, app, report)
+}
+
+// fail routes a failed asynchronous delivery to a dead-letter handler
+func (b *EventBus) fail(ctx context.Context, sub *subscription, event any, err error) {
+	handler := sub.config.deadLetter
+	if handler == nil {
+		b.mu.RLock()
+		handler = b.deadLetter
+		b.mu.RUnlock()
+	}
+	if handler == nil {
+		b.logger.Error("Asynchronous event listener failed", "event", fmt.Sprintf("%T", event), "error", err)
+		return
+	}
+	handler(ctx, event, err)
+}
+
+// listenerPanic is the error of a panicking listener
+type listenerPanic struct {
+	value any
+	stack []byte
+}
+
+func (e *listenerPanic) Error() string {
+	return fmt.Sprintf("panic in event listener: %v", e.value)
+}
+
+func safeCall(ctx context.Context, listener Listener, event any) (err error) {
+	defer func() {
+		if r := recover(); r != nil {
+			err = &listenerPanic{value: r, stack: debug.Stack()}
+		}
+	}()
+	return listener(ctx, event)
+}
+
+// Ensure that EventBus is notified of reloaded variables
+var _ container.VariableChangeListener = (*EventBus)(nil)
@@ -0,0 +1,119 @@
+package events
+
+import (
+	"context"
+	"errors"
+	"log/slog"
+	"sync"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// ErrExecutorStopped is returned when submitting to a stopped executor
+var ErrExecutorStopped = errors.New("task executor stopped")
+
+// TaskExecutor runs tasks asynchronously
+type TaskExecutor interface {
+	// Submit queues a task, blocking while the queue is full
+	Submit(task func()) error
+}
+
+// PoolExecutor is a TaskExecutor component backed by a fixed pool of workers.
+// Stop waits for queued tasks to finish.
+type PoolExecutor struct {
+	workers int
+	queue   chan func()
+	logger  *slog.Logger
+
+	mu      sync.RWMutex
+	stopped bool
+	wg      sync.WaitGroup
+}
+
+// NewPoolExecutor creates an executor with the given number of workers and queue capacity
+func NewPoolExecutor(workers, queueSize int) *PoolExecutor {
+	if workers <= 0 {
+		workers = 1
+	}
+	if queueSize < 0 {
+		queueSize = 0
+	}
+	return &PoolExecutor{
+		workers: workers,
+		queue:   make(chan func(), queueSize),
+		logger:  slog.Default(),
+	}
+}
+
+// Name returns the component name
+func (e *PoolExecutor) Name() string {
+	return "taskExecutor"
+}
+
+// Init is a no-op
+func (e *PoolExecutor) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Start launches the workers
+func (e *PoolExecutor) Start(context.Context) {
+	for i := 0; i < e.workers; i++ {
+		e.wg.Add(1)
+		go e.work()
+	}
+}
+
+// Stop rejects new tasks and waits for queued ones to complete
+func (e *PoolExecutor) Stop(ctx context.Context) {
+	e.mu.Lock()
+	if e.stopped {
+		e.mu.Unlock()
+		return
+	}
+	e.stopped = true
+	close(e.queue)
+	e.mu.Unlock()
+
+	done := make(chan struct{})
+	go func() {
+		e.wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		e.logger.Warn("Task executor stopped before draining its queue")
+	}
+}
+
+// Submit queues a task
+func (e *PoolExecutor) Submit(task func()) error {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+
+	if e.stopped {
+		return ErrExecutorStopped
+	}
+	e.queue <- task
+	return nil
+}
+
+func (e *PoolExecutor) work() {
+	defer e.wg.Done()
+	for task := range e.queue {
+		e.run(task)
+	}
+}
+
+func (e *PoolExecutor) run(task func()) {
+	defer func() {
+		if r := recover(); r != nil {
+			e.logger.Error("Panic in asynchronous task", "error", r)
+		}
+	}()
+	task()
+}
+
+// Ensure that PoolExecutor implements container.LifecycleComponent
+var _ container.LifecycleComponent = (*PoolExecutor)(nil)
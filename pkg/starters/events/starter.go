@@ -0,0 +1,34 @@
+package events
+
+import (
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Executor properties: events.executor.*
+const (
+	// PropertyExecutorWorkers is the number of workers of the shared TaskExecutor (default 4)
+	PropertyExecutorWorkers = "events.executor.workers"
+	// PropertyExecutorQueueSize is the task queue capacity (default 1000)
+	PropertyExecutorQueueSize = "events.executor.queue-size"
+)
+
+// Starter registers the EventBus and, unless a TaskExecutor is already
+// registered, a shared PoolExecutor running asynchronous listeners. Listeners
+// subscribe in their Init:
+//
+//	bus, err := container.GetComponentAs[*events.EventBus](ctx, "eventBus")
+//	events.Subscribe(bus, c.onOrderPlaced, events.Async(), events.Retry(3, time.Second))
+func Starter() container.Starter {
+	return container.NewStarter("EventsStarter", func(builder container.ContextBuilder) error {
+		var executor TaskExecutor
+		if err := builder.GetComponent(&executor); err != nil {
+			vars := container.NewVariableHelper(builder)
+			pool := NewPoolExecutor(vars.GetInt(PropertyExecutorWorkers, 4), vars.GetInt(PropertyExecutorQueueSize, 1000))
+			if err := builder.RegisterComponent(pool); err != nil {
+				return err
+			}
+		}
+
+		return builder.RegisterComponent(NewEventBus())
+	})
+}
@@ -0,0 +1,131 @@
+package gcpconfig
+
+import (
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// APIError is an error response of Secret Manager
+type APIError struct {
+	StatusCode int
+	// Status is the canonical error code, e.g. PERMISSION_DENIED
+	Status  string
+	Message string
+}
+
+func (e *APIError) Error() string {
+	return fmt.Sprintf("gcp: %s (status %d): %s", e.Status, e.StatusCode, e.Message)
+}
+
+// apiClient calls the REST API of Secret Manager for a project
+type apiClient struct {
+	client   *http.Client
+	endpoint string
+	token    string
+}
+
+// secrets returns the IDs of the secrets starting with prefix
+func (a *apiClient) secrets(ctx context.Context, prefix string) ([]string, error) {
+	query := url.Values{"pageSize": {"250"}}
+	if prefix != "" {
+		// The name filter matches substrings; the prefix is checked below
+		query.Set("filter", "name:"+prefix)
+	}
+
+	var ids []string
+	for {
+		var response struct {
+			Secrets []struct {
+				// Name is projects/<project>/secrets/<id>
+				Name string `json:"name"`
+			} `json:"secrets"`
+			NextPageToken string `json:"nextPageToken"`
+		}
+		if err := a.get(ctx, "/secrets?"+query.Encode(), &response); err != nil {
+			return nil, err
+		}
+		for _, secret := range response.Secrets {
+			id := secret.Name[strings.LastIndex(secret.Name, "/")+1:]
+			if strings.HasPrefix(id, prefix) {
+				ids = append(ids, id)
+			}
+		}
+		if response.NextPageToken == "" {
+			return ids, nil
+		}
+		query.Set("pageToken", response.NextPageToken)
+	}
+}
+
+// access returns the value of the latest enabled version of a secret; ok is false
+// when the secret has none
+func (a *apiClient) access(ctx context.Context, id string) (string, bool, error) {
+	var response struct {
+		Payload struct {
+			Data string `json:"data"`
+		} `json:"payload"`
+	}
+	err := a.get(ctx, "/secrets/"+url.PathEscape(id)+"/versions/latest:access", &response)
+	var apiErr *APIError
+	if errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound {
+		return "", false, nil
+	}
+	if err != nil {
+		return "", false, err
+	}
+	value, err := base64.StdEncoding.DecodeString(response.Payload.Data)
+	if err != nil {
+		return "", false, fmt.Errorf("decode payload: %w", err)
+	}
+	return string(value), true, nil
+}
+
+func (a *apiClient) get(ctx context.Context, path string, response interface{}) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.endpoint+path, nil)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Authorization", "Bearer "+a.token)
+	return doJSON(a.client, req, response)
+}
+
+// doJSON sends a request and decodes its JSON response into response
+func doJSON(client *http.Client, req *http.Request, response interface{}) error {
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+
+	data, err := io.ReadAll(io.LimitReader(resp.Body, 16<<20))
+	if err != nil {
+		return err
+	}
+	if resp.StatusCode >= 300 {
+		var body struct {
+			Error struct {
+				Message string `json:"message"`
+				Status  string `json:"status"`
+			} `json:"error"`
+			// OAuth2 errors of the token endpoints
+			ErrorDescription string `json:"error_description"`
+		}
+		_ = json.Unmarshal(data, &body)
+		apiErr := &APIError{StatusCode: resp.StatusCode, Status: body.Error.Status, Message: body.Error.Message}
+		if apiErr.Message == "" {
+			apiErr.Message = body.ErrorDescription
+		}
+		if apiErr.Status == "" {
+			apiErr.Status = http.StatusText(resp.StatusCode)
+		}
+		return apiErr
+	}
+	return json.Unmarshal(data, response)
+}
@@ -0,0 +1,154 @@
+// Package gcpconfig loads variables from GCP Secret Manager, and refreshes them
+// with the refresh scope
+package gcpconfig
+
+import (
+	"context"
+	"fmt"
+	"net/http"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/cloudconfig"
+)
+
+// PropertyGCPConfig holds the loader configuration: gcp.config.*
+const PropertyGCPConfig = "gcp.config"
+
+// Config configures the loader: gcp.config.*
+type Config struct {
+	// Enabled overrides the provider selection; see cloudconfig.Selected
+	Enabled *bool `yaml:"enabled"`
+	// Project is the project ID (default GOOGLE_CLOUD_PROJECT)
+	Project string `yaml:"project"`
+	// Prefix selects the secrets whose ID starts with it, e.g. shop-prod_; it is
+	// removed from the variable names
+	Prefix string `yaml:"prefix"`
+	// Endpoint overrides the Secret Manager endpoint, e.g. an emulator's
+	Endpoint string `yaml:"endpoint"`
+	// Optional starts the application when Secret Manager can't be reached
+	Optional bool `yaml:"optional"`
+	// RefreshInterval reloads the variables periodically when set; see Starter
+	RefreshInterval time.Duration `yaml:"refresh-interval"`
+	// Timeout bounds loading all the secrets (default 10s)
+	Timeout time.Duration `yaml:"timeout"`
+	// AccessToken is an OAuth2 access token, e.g. of gcloud auth print-access-token
+	AccessToken string `yaml:"access-token"`
+	// CredentialsFile is a service account key file (default
+	// GOOGLE_APPLICATION_CREDENTIALS); without it nor an access token, the token of
+	// the attached service account is requested from the metadata server
+	CredentialsFile string `yaml:"credentials-file"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Project == "" {
+		c.Project = os.Getenv("GOOGLE_CLOUD_PROJECT")
+	}
+	if c.Endpoint == "" {
+		c.Endpoint = "https://secretmanager.googleapis.com"
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 10 * time.Second
+	}
+	if c.AccessToken == "" && c.CredentialsFile == "" {
+		c.CredentialsFile = os.Getenv("GOOGLE_APPLICATION_CREDENTIALS")
+	}
+	return c
+}
+
+// Loader loads the secrets of gcp.config.project as variables when GCP is the
+// selected provider (see cloudconfig.Selected). It runs after the loaders added
+// before it, which provide its configuration, so add it after the YAML loader:
+//
+//	gcp:
+//	  config:
+//	    project: shop-prod
+//	    prefix: shop_
+//
+// Secret IDs can't contain dots, so double underscores separate the levels: the
+// secret shop_db__password becomes the variable db.password. A secret whose value
+// is a JSON object becomes one variable per field: the secret shop_db holding
+// {"password": "..."} becomes db.password as well.
+//
+// When Secret Manager fails while the variables are reloaded, the loader logs the
+// error and keeps the last loaded values.
+type Loader struct {
+	// Client sends the requests (http.DefaultClient by default)
+	Client    *http.Client
+	now       func() time.Time
+	registrar cloudconfig.Registrar
+}
+
+// NewLoader creates a loader
+func NewLoader() *Loader {
+	return &Loader{now: time.Now}
+}
+
+// Load fetches the secrets and registers them as variables
+func (l *Loader) Load(builder container.ContextBuilder) error {
+	if !cloudconfig.Selected(builder, cloudconfig.ProviderGCP, PropertyGCPConfig) {
+		return nil
+	}
+	var config Config
+	if container.NewVariableHelper(builder).HasSection(PropertyGCPConfig) {
+		if err := builder.GetVariableAs(PropertyGCPConfig, &config); err != nil {
+			return container.ConfigurationError("invalid "+PropertyGCPConfig+" configuration", err)
+		}
+	}
+	config = config.withDefaults()
+	if config.Project == "" {
+		return container.ConfigurationError("invalid "+PropertyGCPConfig+" configuration",
+			fmt.Errorf("%s.project is required", PropertyGCPConfig))
+	}
+
+	variables, err := l.fetch(config)
+	l.registrar.Source = "secret manager " + config.Project
+	return l.registrar.Register(builder, variables, err, config.Optional)
+}
+
+// fetch returns the variables of the latest versions of the secrets
+func (l *Loader) fetch(config Config) (map[string]string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), config.Timeout)
+	defer cancel()
+
+	client := l.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	token, err := accessToken(ctx, client, config, l.now())
+	if err != nil {
+		return nil, fmt.Errorf("authenticate: %w", err)
+	}
+	api := &apiClient{
+		client:   client,
+		endpoint: strings.TrimSuffix(config.Endpoint, "/") + "/v1/projects/" + config.Project,
+		token:    token,
+	}
+
+	ids, err := api.secrets(ctx, config.Prefix)
+	if err != nil {
+		return nil, err
+	}
+	variables := make(map[string]string)
+	for _, id := range ids {
+		value, ok, err := api.access(ctx, id)
+		if err != nil {
+			return nil, fmt.Errorf("secret %s: %w", id, err)
+		}
+		if ok {
+			cloudconfig.AddSecret(variables, variableName(id, config.Prefix), value)
+		}
+	}
+	return variables, nil
+}
+
+// variableName maps a secret ID to a dot-separated variable name: shop_db__password
+// with the prefix shop_ becomes db.password
+func variableName(id, prefix string) string {
+	return strings.ReplaceAll(strings.TrimPrefix(id, prefix), "__", ".")
+}
+
+// Ensure that Loader implements container.VariableLoader
+var _ container.VariableLoader = (*Loader)(nil)
@@ -0,0 +1,133 @@
+package gcpconfig
+
+import (
+	"context"
+	"crypto"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/sha256"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+)
+
+// scope grants access to the Google Cloud APIs
+const scope = "https://www.googleapis.com/auth/cloud-platform"
+
+// metadataTokenURL returns the token of the service account attached to the
+// instance; a variable so that it can point at a fake metadata server
+var metadataTokenURL = "http://metadata.google.internal/computeMetadata/v1/instance/service-accounts/default/token"
+
+// serviceAccountKey is the content of a service account key file
+type serviceAccountKey struct {
+	Type        string `json:"type"`
+	ClientEmail string `json:"client_email"`
+	PrivateKey  string `json:"private_key"`
+	TokenURI    string `json:"token_uri"`
+}
+
+// tokenResponse is the response of the token endpoints
+type tokenResponse struct {
+	AccessToken string `json:"access_token"`
+}
+
+// accessToken returns the configured access token, or requests one for the
+// service account key file or from the metadata server
+func accessToken(ctx context.Context, client *http.Client, config Config, now time.Time) (string, error) {
+	if config.AccessToken != "" {
+		return config.AccessToken, nil
+	}
+	if config.CredentialsFile != "" {
+		return serviceAccountToken(ctx, client, config.CredentialsFile, now)
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, metadataTokenURL, nil)
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Metadata-Flavor", "Google")
+	var token tokenResponse
+	if err := doJSON(client, req, &token); err != nil {
+		return "", fmt.Errorf("metadata server: %w", err)
+	}
+	return token.AccessToken, nil
+}
+
+// serviceAccountToken exchanges a JWT signed with the key of a service account
+// for an access token
+func serviceAccountToken(ctx context.Context, client *http.Client, path string, now time.Time) (string, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+	var key serviceAccountKey
+	if err := json.Unmarshal(data, &key); err != nil {
+		return "", fmt.Errorf("invalid credentials file %s: %w", path, err)
+	}
+	if key.Type != "service_account" {
+		return "", fmt.Errorf("credentials file %s: unsupported type %q", path, key.Type)
+	}
+	if key.TokenURI == "" {
+		key.TokenURI = "https://oauth2.googleapis.com/token"
+	}
+
+	assertion, err := signedAssertion(key, now)
+	if err != nil {
+		return "", fmt.Errorf("credentials file %s: %w", path, err)
+	}
+	form := url.Values{
+		"grant_type": {"urn:ietf:params:oauth:grant-type:jwt-bearer"},
+		"assertion":  {assertion},
+	}
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, key.TokenURI, strings.NewReader(form.Encode()))
+	if err != nil {
+		return "", err
+	}
+	req.Header.Set("Content-Type", "application/x-www-form-urlencoded")
+	var token tokenResponse
+	if err := doJSON(client, req, &token); err != nil {
+		return "", err
+	}
+	return token.AccessToken, nil
+}
+
+// signedAssertion returns the RS256 JWT asserting the identity of the service
+// account for an hour
+func signedAssertion(key serviceAccountKey, now time.Time) (string, error) {
+	block, _ := pem.Decode([]byte(key.PrivateKey))
+	if block == nil {
+		return "", errors.New("invalid private key")
+	}
+	parsed, err := x509.ParsePKCS8PrivateKey(block.Bytes)
+	if err != nil {
+		return "", fmt.Errorf("invalid private key: %w", err)
+	}
+	privateKey, ok := parsed.(*rsa.PrivateKey)
+	if !ok {
+		return "", errors.New("private key is not an RSA key")
+	}
+
+	header, _ := json.Marshal(map[string]string{"alg": "RS256", "typ": "JWT"})
+	claims, _ := json.Marshal(map[string]interface{}{
+		"iss":   key.ClientEmail,
+		"scope": scope,
+		"aud":   key.TokenURI,
+		"iat":   now.Unix(),
+		"exp":   now.Add(time.Hour).Unix(),
+	})
+	unsigned := base64.RawURLEncoding.EncodeToString(header) + "." + base64.RawURLEncoding.EncodeToString(claims)
+	digest := sha256.Sum256([]byte(unsigned))
+	signature, err := rsa.SignPKCS1v15(rand.Reader, privateKey, crypto.SHA256, digest[:])
+	if err != nil {
+		return "", err
+	}
+	return unsigned + "." + base64.RawURLEncoding.EncodeToString(signature), nil
+}
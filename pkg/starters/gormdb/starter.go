@@ -0,0 +1,50 @@
+// Package gormdb registers a *gorm.DB over the *sql.DB of the datasource starter,
+// for applications standardized on GORM
+package gormdb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/datasource"
+	"gorm.io/gorm"
+)
+
+// GormName is the *gorm.DB instance
+const GormName = "gormDB"
+
+// Dialector creates the GORM dialector of the datasource's *sql.DB. Register one
+// as an instance, as GORM's dialectors live in driver-specific modules:
+//
+//	builder.RegisterInstance("gormDialector", gormdb.Dialector(func(db *sql.DB) gorm.Dialector {
+//		return postgres.New(postgres.Config{Conn: db})
+//	}))
+type Dialector func(db *sql.DB) gorm.Dialector
+
+// Starter registers a *gorm.DB over the datasource's *sql.DB when
+// datasource.gorm.enabled is true, so that it shares the pool, the health check
+// and the pool metrics of the DataSource component. Register it after
+// datasource.Starter.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"GormStarter",
+		container.PropertyCondition(datasource.PropertyDataSource+".gorm.enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			db, err := container.GetComponentAs[*sql.DB](builder, datasource.DBName)
+			if err != nil {
+				return fmt.Errorf("gorm requires the datasource: %w", err)
+			}
+			var dialector Dialector
+			if err := builder.GetComponent(&dialector); err != nil {
+				return fmt.Errorf("gorm requires a gormdb.Dialector: %w", err)
+			}
+
+			gormDB, err := gorm.Open(dialector(db), &gorm.Config{})
+			if err != nil {
+				return fmt.Errorf("open gorm: %w", err)
+			}
+			return builder.RegisterInstance(GormName, gormDB)
+		},
+	)
+}
@@ -0,0 +1,86 @@
+package grpcserver
+
+import (
+	"context"
+	"sync/atomic"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/status"
+)
+
+// healthService implements grpc.health.v1.Health on top of the container's
+// HealthIndicator aggregate. The empty service name reports the whole application,
+// a component name reports that indicator and a registered gRPC service name
+// reports the application status.
+type healthService struct {
+	healthpb.UnimplementedHealthServer
+	app           container.ApplicationContext
+	server        *grpc.Server
+	watchInterval time.Duration
+	serving       atomic.Bool
+}
+
+// Check returns the current status of a service
+func (h *healthService) Check(ctx context.Context, req *healthpb.HealthCheckRequest) (*healthpb.HealthCheckResponse, error) {
+	st, ok := h.status(ctx, req.GetService())
+	if !ok {
+		return nil, status.Errorf(codes.NotFound, "unknown service %q", req.GetService())
+	}
+	return &healthpb.HealthCheckResponse{Status: st}, nil
+}
+
+// Watch streams the status of a service whenever it changes
+func (h *healthService) Watch(req *healthpb.HealthCheckRequest, stream healthpb.Health_WatchServer) error {
+	ticker := time.NewTicker(h.watchInterval)
+	defer ticker.Stop()
+
+	last := healthpb.HealthCheckResponse_ServingStatus(-1)
+	for {
+		st, ok := h.status(stream.Context(), req.GetService())
+		if !ok {
+			st = healthpb.HealthCheckResponse_SERVICE_UNKNOWN
+		}
+		if st != last {
+			if err := stream.Send(&healthpb.HealthCheckResponse{Status: st}); err != nil {
+				return err
+			}
+			last = st
+		}
+
+		select {
+		case <-stream.Context().Done():
+			return status.FromContextError(stream.Context().Err()).Err()
+		case <-ticker.C:
+		}
+	}
+}
+
+func (h *healthService) status(ctx context.Context, service string) (healthpb.HealthCheckResponse_ServingStatus, bool) {
+	if service != "" {
+		if indicator, ok := container.HealthIndicators(h.app)[service]; ok {
+			if !h.serving.Load() {
+				return healthpb.HealthCheckResponse_NOT_SERVING, true
+			}
+			return servingStatus(container.CheckHealth(ctx, h.app, service, indicator).Status), true
+		}
+		if _, ok := h.server.GetServiceInfo()[service]; !ok {
+			return healthpb.HealthCheckResponse_SERVICE_UNKNOWN, false
+		}
+	}
+
+	if !h.serving.Load() {
+		return healthpb.HealthCheckResponse_NOT_SERVING, true
+	}
+	return servingStatus(container.AggregateHealth(ctx, h.app).Status), true
+}
+
+func servingStatus(status container.HealthStatus) healthpb.HealthCheckResponse_ServingStatus {
+	if status == container.HealthDown {
+		return healthpb.HealthCheckResponse_NOT_SERVING
+	}
+	return healthpb.HealthCheckResponse_SERVING
+}
@@ -0,0 +1,53 @@
+package grpcserver
+
+import (
+	"context"
+
+	"github.com/01fortes/goboot/pkg/starters/propagation"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataCarrier adapts gRPC metadata to a propagation.Carrier; keys are lower
+// case in metadata
+type MetadataCarrier metadata.MD
+
+// Get returns the first value of a key
+func (c MetadataCarrier) Get(key string) string {
+	if values := metadata.MD(c).Get(key); len(values) > 0 {
+		return values[0]
+	}
+	return ""
+}
+
+// Set replaces the values of a key
+func (c MetadataCarrier) Set(key, value string) {
+	metadata.MD(c).Set(key, value)
+}
+
+// propagationUnaryInterceptor extracts the propagated values of the incoming
+// metadata and returns the correlation ID in the response headers
+func propagationUnaryInterceptor(propagator *propagation.Propagator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx = extract(ctx, propagator)
+		return handler(ctx, req)
+	}
+}
+
+// propagationStreamInterceptor extracts the propagated values of the incoming
+// metadata of a stream
+func propagationStreamInterceptor(propagator *propagation.Propagator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx := extract(stream.Context(), propagator)
+		return handler(srv, &scopedStream{ServerStream: stream, ctx: ctx})
+	}
+}
+
+// extract returns the context of an incoming call
+func extract(ctx context.Context, propagator *propagation.Propagator) context.Context {
+	incoming, _ := metadata.FromIncomingContext(ctx)
+	ctx = propagator.Extract(ctx, MetadataCarrier(incoming.Copy()))
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(propagator.CorrelationHeader(), propagation.CorrelationID(ctx)))
+	return ctx
+}
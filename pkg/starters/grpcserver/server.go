@@ -0,0 +1,256 @@
+// Package grpcserver runs a gRPC server that serves the services registered by
+// GRPCService components, together with the standard health and reflection services
+package grpcserver
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"net"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/propagation"
+	"github.com/01fortes/goboot/pkg/starters/tlsprovider"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
+	healthpb "google.golang.org/grpc/health/grpc_health_v1"
+	"google.golang.org/grpc/reflection"
+)
+
+// Server properties: grpc.server.*
+const (
+	// PropertyEnabled disables the server when false (default true)
+	PropertyEnabled = "grpc.server.enabled"
+	// PropertyAddress is the listen host (default all interfaces)
+	PropertyAddress = "grpc.server.address"
+	// PropertyPort is the listen port (default 9090, 0 picks a free port)
+	PropertyPort = "grpc.server.port"
+	// PropertyHealthEnabled registers grpc.health.v1.Health (default true)
+	PropertyHealthEnabled = "grpc.server.health.enabled"
+	// PropertyHealthWatchInterval is how often Watch streams re-check health (default 5s)
+	PropertyHealthWatchInterval = "grpc.server.health.watch-interval"
+	// PropertyReflectionEnabled registers the server reflection service (default false)
+	PropertyReflectionEnabled = "grpc.server.reflection.enabled"
+)
+
+// GRPCService is a component that registers gRPC services on the server
+type GRPCService interface {
+	container.Component
+	// RegisterService registers the component's services, e.g. pb.RegisterGreeterServer(server, c)
+	RegisterService(server *grpc.Server)
+}
+
+// ServerOptionsProvider is a component contributing grpc.ServerOption values
+// such as interceptors
+type ServerOptionsProvider interface {
+	ServerOptions() []grpc.ServerOption
+}
+
+// Server is the gRPC server component. It serves TLS when a TLSProvider is registered.
+// When a propagation.Propagator is registered, call contexts carry the values
+// propagated in the incoming metadata.
+type Server struct {
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	server   *grpc.Server
+	health   *healthService
+	address  string
+	listener net.Listener
+}
+
+// NewServer creates a gRPC server component
+func NewServer() *Server {
+	return &Server{logger: slog.Default()}
+}
+
+// Name returns the component name
+func (s *Server) Name() string {
+	return "grpcServer"
+}
+
+// GRPCServer returns the underlying server
+func (s *Server) GRPCServer() *grpc.Server {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	return s.server
+}
+
+// Init creates the server and registers all services
+func (s *Server) Init(ctx container.ApplicationContext) error {
+	vars := container.NewVariableHelper(ctx)
+
+	// Propagation runs first so that the scoped components see the correlation ID
+	var unary []grpc.UnaryServerInterceptor
+	var stream []grpc.StreamServerInterceptor
+	if ctx.HasComponent("propagator") {
+		comp, err := ctx.GetComponentByName("propagator")
+		if err != nil {
+			return err
+		}
+		if propagator, ok := comp.(*propagation.Propagator); ok {
+			unary = append(unary, propagationUnaryInterceptor(propagator))
+			stream = append(stream, propagationStreamInterceptor(propagator))
+		}
+	}
+	options := []grpc.ServerOption{
+		grpc.ChainUnaryInterceptor(append(unary, s.scopeUnaryInterceptor(ctx))...),
+		grpc.ChainStreamInterceptor(append(stream, s.scopeStreamInterceptor(ctx))...),
+	}
+	services := []GRPCService{}
+	for _, name := range sortedNames(ctx) {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil || comp == nil {
+			continue
+		}
+		if service, ok := comp.(GRPCService); ok {
+			services = append(services, service)
+		}
+		if provider, ok := comp.(ServerOptionsProvider); ok {
+			options = append(options, provider.ServerOptions()...)
+		}
+		if provider, ok := comp.(*tlsprovider.TLSProvider); ok {
+			options = append(options, grpc.Creds(credentials.NewTLS(provider.TLSConfig())))
+		}
+	}
+
+	server := grpc.NewServer(options...)
+	for _, service := range services {
+		service.RegisterService(server)
+	}
+
+	var health *healthService
+	if vars.GetBool(PropertyHealthEnabled, true) {
+		watchInterval := 5 * time.Second
+		if ctx.HasVariable(PropertyHealthWatchInterval) {
+			if err := ctx.GetVariableAs(PropertyHealthWatchInterval, &watchInterval); err != nil {
+				return fmt.Errorf("invalid %s: %w", PropertyHealthWatchInterval, err)
+			}
+		}
+		health = &healthService{app: ctx, server: server, watchInterval: watchInterval}
+		healthpb.RegisterHealthServer(server, health)
+	}
+
+	if vars.GetBool(PropertyReflectionEnabled, false) {
+		reflection.Register(server)
+	}
+
+	s.mu.Lock()
+	s.server = server
+	s.health = health
+	s.address = net.JoinHostPort(vars.GetString(PropertyAddress, ""), vars.GetString(PropertyPort, "9090"))
+	s.mu.Unlock()
+	return nil
+}
+
+// Start binds the listener and serves requests in the background
+func (s *Server) Start(context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listener, err := net.Listen("tcp", s.address)
+	if err != nil {
+		// Start can't return an error; a panic is reported by the lifecycle manager
+		panic(fmt.Errorf("listen on %s: %w", s.address, err))
+	}
+	s.listener = listener
+
+	if s.health != nil {
+		s.health.serving.Store(true)
+	}
+	s.logger.Info("gRPC server listening", "address", listener.Addr().String())
+
+	server := s.server
+	go func() {
+		if err := server.Serve(listener); err != nil {
+			s.logger.Error("gRPC server failed", "error", err)
+		}
+	}()
+}
+
+// Stop reports NOT_SERVING to health checks and gracefully stops the server,
+// forcing it down if ctx expires first
+func (s *Server) Stop(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return
+	}
+	if s.health != nil {
+		s.health.serving.Store(false)
+	}
+
+	done := make(chan struct{})
+	go func() {
+		s.server.GracefulStop()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+	case <-ctx.Done():
+		s.server.Stop()
+	}
+	s.listener = nil
+}
+
+// Addr returns the bound address, or nil if the server isn't running
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+func sortedNames(ctx container.ApplicationContext) []string {
+	names := ctx.GetComponentNames()
+	sort.Strings(names)
+	return names
+}
+
+// Ensure that Server implements container.LifecycleComponent
+var _ container.LifecycleComponent = (*Server)(nil)
+
+// scopeUnaryInterceptor opens a container scope for every call, so services can get
+// request-scoped components with container.GetScoped(ctx, name)
+func (s *Server) scopeUnaryInterceptor(app container.ApplicationContext) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		scope := container.NewScope(ctx, app)
+		defer s.closeScope(scope, info.FullMethod)
+		return handler(scope.Context(), req)
+	}
+}
+
+// scopeStreamInterceptor opens a container scope for every stream
+func (s *Server) scopeStreamInterceptor(app container.ApplicationContext) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		scope := container.NewScope(stream.Context(), app)
+		defer s.closeScope(scope, info.FullMethod)
+		return handler(srv, &scopedStream{ServerStream: stream, ctx: scope.Context()})
+	}
+}
+
+// scopedStream is a server stream whose context carries the call's scope
+type scopedStream struct {
+	grpc.ServerStream
+	ctx context.Context
+}
+
+// Context returns the scope's context
+func (st *scopedStream) Context() context.Context {
+	return st.ctx
+}
+
+// closeScope closes the scope of a call, logging close errors
+func (s *Server) closeScope(scope *container.Scope, method string) {
+	if err := scope.Close(); err != nil {
+		s.logger.Error("Failed to close request scope", "method", method, "error", err)
+	}
+}
@@ -0,0 +1,22 @@
+package grpcserver
+
+import (
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Starter registers the gRPC server unless grpc.server.enabled is false. The
+// server always exposes grpc.health.v1.Health backed by the container's
+// HealthIndicator components (disable with grpc.server.health.enabled=false), so
+// Kubernetes gRPC probes work without extra code; set grpc.server.reflection.enabled
+// to register server reflection.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"GRPCServerStarter",
+		func(ctx container.ApplicationContext) bool {
+			return container.NewVariableHelper(ctx).GetBool(PropertyEnabled, true)
+		},
+		func(builder container.ContextBuilder) error {
+			return builder.RegisterComponent(NewServer())
+		},
+	)
+}
@@ -0,0 +1,175 @@
+package httpclient
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"net"
+	"net/http"
+	"net/url"
+	"os"
+	"time"
+)
+
+// Config configures a named HTTP client
+type Config struct {
+	// Timeout is the overall request timeout (0 means none)
+	Timeout time.Duration `yaml:"timeout"`
+	// DialTimeout limits establishing TCP connections
+	DialTimeout time.Duration `yaml:"dial-timeout"`
+	// TLSHandshakeTimeout limits the TLS handshake
+	TLSHandshakeTimeout time.Duration `yaml:"tls-handshake-timeout"`
+	// ResponseHeaderTimeout limits waiting for response headers
+	ResponseHeaderTimeout time.Duration `yaml:"response-header-timeout"`
+	// Proxy is a proxy URL; empty uses the environment (HTTP_PROXY etc.)
+	Proxy string `yaml:"proxy"`
+	// TLS configures client certificates and trust
+	TLS TLSConfig `yaml:"tls"`
+	// Pool configures connection pooling
+	Pool PoolConfig `yaml:"pool"`
+	// Retry configures retries of idempotent requests
+	Retry RetryConfig `yaml:"retry"`
+}
+
+// TLSConfig configures TLS for a client
+type TLSConfig struct {
+	// CAFile is a PEM bundle of additional trusted CAs
+	CAFile string `yaml:"ca-file"`
+	// CertFile and KeyFile are a client certificate for mutual TLS
+	CertFile string `yaml:"cert-file"`
+	KeyFile  string `yaml:"key-file"`
+	// ServerName overrides the server name used for verification
+	ServerName string `yaml:"server-name"`
+	// InsecureSkipVerify disables certificate verification (never use in production)
+	InsecureSkipVerify bool `yaml:"insecure-skip-verify"`
+}
+
+// PoolConfig configures the connection pool
+type PoolConfig struct {
+	MaxIdleConns        int           `yaml:"max-idle-conns"`
+	MaxIdleConnsPerHost int           `yaml:"max-idle-conns-per-host"`
+	MaxConnsPerHost     int           `yaml:"max-conns-per-host"`
+	IdleConnTimeout     time.Duration `yaml:"idle-conn-timeout"`
+}
+
+// RetryConfig configures retries
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts (0 or 1 disables retries)
+	MaxAttempts int `yaml:"max-attempts"`
+	// Backoff is the delay between attempts, doubled after each retry
+	Backoff time.Duration `yaml:"backoff"`
+}
+
+// DefaultConfig returns the configuration used for unset values
+func DefaultConfig() Config {
+	return Config{
+		Timeout:               30 * time.Second,
+		DialTimeout:           10 * time.Second,
+		TLSHandshakeTimeout:   10 * time.Second,
+		ResponseHeaderTimeout: 0,
+		Pool: PoolConfig{
+			MaxIdleConns:        100,
+			MaxIdleConnsPerHost: 10,
+			IdleConnTimeout:     90 * time.Second,
+		},
+		Retry: RetryConfig{
+			Backoff: 100 * time.Millisecond,
+		},
+	}
+}
+
+// withDefaults fills unset values from DefaultConfig
+func (c Config) withDefaults() Config {
+	d := DefaultConfig()
+	if c.Timeout == 0 {
+		c.Timeout = d.Timeout
+	}
+	if c.DialTimeout == 0 {
+		c.DialTimeout = d.DialTimeout
+	}
+	if c.TLSHandshakeTimeout == 0 {
+		c.TLSHandshakeTimeout = d.TLSHandshakeTimeout
+	}
+	if c.Pool.MaxIdleConns == 0 {
+		c.Pool.MaxIdleConns = d.Pool.MaxIdleConns
+	}
+	if c.Pool.MaxIdleConnsPerHost == 0 {
+		c.Pool.MaxIdleConnsPerHost = d.Pool.MaxIdleConnsPerHost
+	}
+	if c.Pool.IdleConnTimeout == 0 {
+		c.Pool.IdleConnTimeout = d.Pool.IdleConnTimeout
+	}
+	if c.Retry.Backoff == 0 {
+		c.Retry.Backoff = d.Retry.Backoff
+	}
+	return c
+}
+
+// newTransport builds an *http.Transport from configuration
+func newTransport(config Config) (*http.Transport, error) {
+	proxy := http.ProxyFromEnvironment
+	if config.Proxy != "" {
+		proxyURL, err := url.Parse(config.Proxy)
+		if err != nil {
+			return nil, fmt.Errorf("invalid proxy url: %w", err)
+		}
+		proxy = http.ProxyURL(proxyURL)
+	}
+
+	tlsConfig, err := newTLSConfig(config.TLS)
+	if err != nil {
+		return nil, err
+	}
+
+	dialer := &net.Dialer{Timeout: config.DialTimeout, KeepAlive: 30 * time.Second}
+
+	return &http.Transport{
+		Proxy:                 proxy,
+		DialContext:           dialer.DialContext,
+		ForceAttemptHTTP2:     true,
+		TLSClientConfig:       tlsConfig,
+		TLSHandshakeTimeout:   config.TLSHandshakeTimeout,
+		ResponseHeaderTimeout: config.ResponseHeaderTimeout,
+		MaxIdleConns:          config.Pool.MaxIdleConns,
+		MaxIdleConnsPerHost:   config.Pool.MaxIdleConnsPerHost,
+		MaxConnsPerHost:       config.Pool.MaxConnsPerHost,
+		IdleConnTimeout:       config.Pool.IdleConnTimeout,
+	}, nil
+}
+
+// newTLSConfig builds a *tls.Config, or nil to use Go's defaults
+func newTLSConfig(config TLSConfig) (*tls.Config, error) {
+	if config == (TLSConfig{}) {
+		return nil, nil
+	}
+
+	result := &tls.Config{
+		ServerName:         config.ServerName,
+		InsecureSkipVerify: config.InsecureSkipVerify,
+	}
+
+	if config.CAFile != "" {
+		pem, err := os.ReadFile(config.CAFile)
+		if err != nil {
+			return nil, fmt.Errorf("read CA file: %w", err)
+		}
+		pool, err := x509.SystemCertPool()
+		if err != nil {
+			pool = x509.NewCertPool()
+		}
+		if !pool.AppendCertsFromPEM(pem) {
+			return nil, fmt.Errorf("no certificates found in %s", config.CAFile)
+		}
+		result.RootCAs = pool
+	}
+
+	if config.CertFile != "" || config.KeyFile != "" {
+		cert, err := tls.LoadX509KeyPair(config.CertFile, config.KeyFile)
+		if err != nil {
+			return nil, fmt.Errorf("load client certificate: %w", err)
+		}
+		result.Certificates = []tls.Certificate{cert}
+	}
+
+	return result, nil
+}
@@ -0,0 +1,170 @@
+// Package httpclient builds named *http.Client components from configuration
+package httpclient
+
+import (
+	"fmt"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyClients holds per-client configuration: http.clients.<name>.*
+const PropertyClients = "http.clients"
+
+// Clients is a component giving access to all configured clients and their metrics
+type Clients struct {
+	mu      sync.RWMutex
+	clients map[string]*http.Client
+	metrics map[string]*metricsTransport
+}
+
+// Name returns the component name
+func (c *Clients) Name() string {
+	return "httpClients"
+}
+
+// Init is a no-op: clients are built by the starter
+func (c *Clients) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Get returns the named client, or nil if it isn't configured
+func (c *Clients) Get(name string) *http.Client {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	return c.clients[name]
+}
+
+// Names returns the sorted client names
+func (c *Clients) Names() []string {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+
+	names := make([]string, 0, len(c.clients))
+	for name := range c.clients {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Metrics returns a snapshot of the named client's counters
+func (c *Clients) Metrics(name string) Metrics {
+	c.mu.RLock()
+	m := c.metrics[name]
+	c.mu.RUnlock()
+
+	if m == nil {
+		return Metrics{Name: name}
+	}
+	return Metrics{
+		Name:          name,
+		Requests:      m.requests.Load(),
+		Errors:        m.errors.Load(),
+		Retries:       m.retries.Load(),
+		TotalDuration: time.Duration(m.duration.Load()),
+	}
+}
+
+// Close closes idle connections of all clients
+func (c *Clients) Close() error {
+	c.mu.RLock()
+	defer c.mu.RUnlock()
+	for _, client := range c.clients {
+		client.CloseIdleConnections()
+	}
+	return nil
+}
+
+// ClientName returns the container name of the named client instance
+func ClientName(name string) string {
+	return "http-client." + name
+}
+
+// Starter registers an *http.Client instance named "http-client.<name>" for each
+// configured client plus a Clients component. Look clients up by name:
+//
+//	client, err := container.GetComponentAs[*http.Client](ctx, httpclient.ClientName("payments"))
+//
+// Components implementing TransportDecorator that are registered before starters
+// run wrap every client's transport (e.g. for OpenTelemetry instrumentation).
+func Starter() container.Starter {
+	return container.NewStarter("HTTPClientStarter", func(builder container.ContextBuilder) error {
+		configs := map[string]Config{}
+		if container.NewVariableHelper(builder).HasSection(PropertyClients) {
+			if err := builder.GetVariableAs(PropertyClients, &configs); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyClients, err)
+			}
+		}
+
+		decorators := findDecorators(builder)
+		clients := &Clients{
+			clients: make(map[string]*http.Client),
+			metrics: make(map[string]*metricsTransport),
+		}
+
+		names := make([]string, 0, len(configs))
+		for name := range configs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			client, metrics, err := newClient(name, configs[name].withDefaults(), decorators)
+			if err != nil {
+				return fmt.Errorf("http client %s: %w", name, err)
+			}
+			clients.clients[name] = client
+			clients.metrics[name] = metrics
+
+			if err := builder.RegisterInstance(ClientName(name), client); err != nil {
+				return err
+			}
+		}
+
+		return builder.RegisterComponent(clients)
+	})
+}
+
+// newClient builds a client: decorators wrap metrics, which wraps retries, which wraps the transport
+func newClient(name string, config Config, decorators []TransportDecorator) (*http.Client, *metricsTransport, error) {
+	transport, err := newTransport(config)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	metrics := &metricsTransport{}
+	var rt http.RoundTripper = transport
+	if config.Retry.MaxAttempts > 1 {
+		rt = &retryTransport{next: rt, maxAttempts: config.Retry.MaxAttempts, backoff: config.Retry.Backoff, metrics: metrics}
+	}
+	metrics.next = rt
+	rt = metrics
+
+	for _, decorator := range decorators {
+		rt = decorator.DecorateTransport(name, rt)
+	}
+
+	return &http.Client{Transport: rt, Timeout: config.Timeout}, metrics, nil
+}
+
+// findDecorators returns registered TransportDecorator components sorted by name
+func findDecorators(ctx container.ApplicationContext) []TransportDecorator {
+	names := ctx.GetComponentNames()
+	sort.Strings(names)
+
+	var result []TransportDecorator
+	for _, name := range names {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if decorator, ok := comp.(TransportDecorator); ok {
+			result = append(result, decorator)
+		}
+	}
+	return result
+}
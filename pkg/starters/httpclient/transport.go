@@ -0,0 +1,110 @@
+package httpclient
+
+import (
+	"net/http"
+	"sync/atomic"
+	"time"
+)
+
+// TransportDecorator is implemented by components that wrap the transport of every
+// client built by the starter, e.g. an OpenTelemetry adapter around otelhttp.NewTransport
+type TransportDecorator interface {
+	// DecorateTransport returns a transport wrapping next for the named client
+	DecorateTransport(client string, next http.RoundTripper) http.RoundTripper
+}
+
+// Metrics is a snapshot of client counters
+type Metrics struct {
+	Name          string
+	Requests      int64
+	Errors        int64
+	Retries       int64
+	TotalDuration time.Duration
+}
+
+// metricsTransport counts requests, errors and latency
+type metricsTransport struct {
+	next     http.RoundTripper
+	requests atomic.Int64
+	errors   atomic.Int64
+	retries  atomic.Int64
+	duration atomic.Int64
+}
+
+func (t *metricsTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	start := time.Now()
+	resp, err := t.next.RoundTrip(req)
+
+	t.requests.Add(1)
+	t.duration.Add(int64(time.Since(start)))
+	if err != nil || resp.StatusCode >= 500 {
+		t.errors.Add(1)
+	}
+	return resp, err
+}
+
+// retryTransport retries idempotent requests that fail with a transport error or a 502/503/504
+type retryTransport struct {
+	next        http.RoundTripper
+	maxAttempts int
+	backoff     time.Duration
+	metrics     *metricsTransport
+}
+
+func (t *retryTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if !isIdempotent(req) {
+		return t.next.RoundTrip(req)
+	}
+
+	backoff := t.backoff
+	for attempt := 1; ; attempt++ {
+		resp, err := t.next.RoundTrip(req)
+		if attempt >= t.maxAttempts || !shouldRetry(resp, err) {
+			return resp, err
+		}
+
+		// Requests with a body can only be replayed if it can be recreated
+		if req.Body != nil && req.Body != http.NoBody {
+			if req.GetBody == nil {
+				return resp, err
+			}
+			body, bodyErr := req.GetBody()
+			if bodyErr != nil {
+				return resp, err
+			}
+			req = req.Clone(req.Context())
+			req.Body = body
+		}
+
+		if resp != nil {
+			resp.Body.Close()
+		}
+
+		select {
+		case <-req.Context().Done():
+			return nil, req.Context().Err()
+		case <-time.After(backoff):
+		}
+		backoff *= 2
+		t.metrics.retries.Add(1)
+	}
+}
+
+func isIdempotent(req *http.Request) bool {
+	switch req.Method {
+	case http.MethodGet, http.MethodHead, http.MethodOptions, http.MethodPut, http.MethodDelete:
+		return true
+	}
+	return false
+}
+
+func shouldRetry(resp *http.Response, err error) bool {
+	if err != nil {
+		return true
+	}
+	switch resp.StatusCode {
+	case http.StatusBadGateway, http.StatusServiceUnavailable, http.StatusGatewayTimeout:
+		return true
+	}
+	return false
+}
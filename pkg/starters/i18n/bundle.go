@@ -0,0 +1,189 @@
+package i18n
+
+import (
+	"bufio"
+	"bytes"
+	"fmt"
+	"io/fs"
+	"path"
+	"strconv"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// bundleExtensions are the supported bundle formats, in lookup order
+var bundleExtensions = []string{".properties", ".yaml", ".yml"}
+
+// NormalizeLocale formats a locale as language-REGION, e.g. de_ch becomes de-CH
+func NormalizeLocale(locale string) string {
+	parts := strings.Split(strings.ReplaceAll(strings.TrimSpace(locale), "_", "-"), "-")
+	for i, part := range parts {
+		switch {
+		case i == 0:
+			parts[i] = strings.ToLower(part)
+		case len(part) == 2:
+			parts[i] = strings.ToUpper(part)
+		case len(part) == 4:
+			parts[i] = strings.ToUpper(part[:1]) + strings.ToLower(part[1:])
+		}
+	}
+	return strings.Join(parts, "-")
+}
+
+// parentLocale returns the locale without its last subtag, empty for a language
+func parentLocale(locale string) string {
+	if i := strings.LastIndex(locale, "-"); i > 0 {
+		return locale[:i]
+	}
+	return ""
+}
+
+// loadBundles reads the bundles named basename[_locale].{properties,yaml,yml} in
+// dir of fsys, keyed by normalized locale; the base bundle has the empty locale
+func loadBundles(fsys fs.FS, dir, basename string) (map[string]map[string]string, error) {
+	entries, err := fs.ReadDir(fsys, dir)
+	if err != nil {
+		return nil, err
+	}
+
+	bundles := make(map[string]map[string]string)
+	for _, entry := range entries {
+		if entry.IsDir() {
+			continue
+		}
+		locale, ext, ok := bundleName(entry.Name(), basename)
+		if !ok {
+			continue
+		}
+
+		data, err := fs.ReadFile(fsys, path.Join(dir, entry.Name()))
+		if err != nil {
+			return nil, err
+		}
+		var messages map[string]string
+		if ext == ".properties" {
+			messages, err = parseProperties(data)
+		} else {
+			messages, err = parseYAML(data)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("bundle %s: %w", entry.Name(), err)
+		}
+
+		bundle, exists := bundles[locale]
+		if !exists {
+			bundle = make(map[string]string, len(messages))
+			bundles[locale] = bundle
+		}
+		for code, message := range messages {
+			bundle[code] = message
+		}
+	}
+	return bundles, nil
+}
+
+// bundleName splits a file name into locale and extension if it names a bundle
+func bundleName(name, basename string) (locale, ext string, ok bool) {
+	for _, candidate := range bundleExtensions {
+		if stem, found := strings.CutSuffix(name, candidate); found {
+			if stem == basename {
+				return "", candidate, true
+			}
+			if rest, found := strings.CutPrefix(stem, basename+"_"); found && rest != "" {
+				return NormalizeLocale(rest), candidate, true
+			}
+		}
+	}
+	return "", "", false
+}
+
+// parseProperties parses key=value or key: value lines; lines starting with # or !
+// are comments and a trailing backslash continues the value on the next line
+func parseProperties(data []byte) (map[string]string, error) {
+	messages := make(map[string]string)
+	scanner := bufio.NewScanner(bytes.NewReader(data))
+	var logical strings.Builder
+	for scanner.Scan() {
+		line := strings.TrimSpace(scanner.Text())
+		if logical.Len() == 0 && (line == "" || strings.HasPrefix(line, "#") || strings.HasPrefix(line, "!")) {
+			continue
+		}
+		if strings.HasSuffix(line, `\`) && !strings.HasSuffix(line, `\\`) {
+			logical.WriteString(strings.TrimSuffix(line, `\`))
+			continue
+		}
+		logical.WriteString(line)
+		line = logical.String()
+		logical.Reset()
+
+		i := strings.IndexAny(line, "=:")
+		if i < 0 {
+			continue
+		}
+		value, err := unescape(strings.TrimSpace(line[i+1:]))
+		if err != nil {
+			return nil, err
+		}
+		messages[strings.TrimSpace(line[:i])] = value
+	}
+	return messages, scanner.Err()
+}
+
+// unescape resolves the \n, \t, \\ and \uXXXX escapes of a properties value
+func unescape(value string) (string, error) {
+	if !strings.Contains(value, `\`) {
+		return value, nil
+	}
+	var b strings.Builder
+	for i := 0; i < len(value); i++ {
+		if value[i] != '\\' || i == len(value)-1 {
+			b.WriteByte(value[i])
+			continue
+		}
+		i++
+		switch value[i] {
+		case 'n':
+			b.WriteByte('\n')
+		case 't':
+			b.WriteByte('\t')
+		case 'u':
+			if i+5 > len(value) {
+				return "", fmt.Errorf("invalid escape in %q", value)
+			}
+			r, err := strconv.ParseUint(value[i+1:i+5], 16, 32)
+			if err != nil {
+				return "", fmt.Errorf("invalid escape in %q", value)
+			}
+			b.WriteRune(rune(r))
+			i += 4
+		default:
+			b.WriteByte(value[i])
+		}
+	}
+	return b.String(), nil
+}
+
+// parseYAML parses a YAML document, flattening nested keys with dots
+func parseYAML(data []byte) (map[string]string, error) {
+	var document map[string]interface{}
+	if err := yaml.Unmarshal(data, &document); err != nil {
+		return nil, err
+	}
+
+	messages := make(map[string]string)
+	var flatten func(prefix string, values map[string]interface{})
+	flatten = func(prefix string, values map[string]interface{}) {
+		for key, value := range values {
+			switch value := value.(type) {
+			case map[string]interface{}:
+				flatten(prefix+key+".", value)
+			case nil:
+			default:
+				messages[prefix+key] = fmt.Sprint(value)
+			}
+		}
+	}
+	flatten("", document)
+	return messages, nil
+}
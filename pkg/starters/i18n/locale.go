@@ -0,0 +1,70 @@
+package i18n
+
+import (
+	"context"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// localeKey is the context key of the locale
+type localeKey struct{}
+
+// WithLocale returns a context whose messages are resolved in locale
+func WithLocale(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, NormalizeLocale(locale))
+}
+
+// LocaleFromContext returns the locale of ctx, empty for the default locale
+func LocaleFromContext(ctx context.Context) string {
+	locale, _ := ctx.Value(localeKey{}).(string)
+	return locale
+}
+
+// AcceptLanguage returns the locales of an Accept-Language header by decreasing
+// quality, e.g. "de-CH, de;q=0.9, en;q=0.5" gives de-CH, de, en
+func AcceptLanguage(header string) []string {
+	type weighted struct {
+		locale  string
+		quality float64
+	}
+	var accepted []weighted
+	for _, part := range strings.Split(header, ",") {
+		locale, params, _ := strings.Cut(strings.TrimSpace(part), ";")
+		if locale == "" || locale == "*" {
+			continue
+		}
+		quality := 1.0
+		if q, found := strings.CutPrefix(strings.TrimSpace(params), "q="); found {
+			if value, err := strconv.ParseFloat(q, 64); err == nil {
+				quality = value
+			}
+		}
+		if quality > 0 {
+			accepted = append(accepted, weighted{NormalizeLocale(locale), quality})
+		}
+	}
+	sort.SliceStable(accepted, func(i, j int) bool { return accepted[i].quality > accepted[j].quality })
+
+	locales := make([]string, len(accepted))
+	for i, a := range accepted {
+		locales[i] = a.locale
+	}
+	return locales
+}
+
+// MatchLocale returns the first locale of an Accept-Language header with a bundle,
+// trying the parents of each locale, or the default locale
+func (s *MessageSource) MatchLocale(acceptLanguage string) string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, locale := range AcceptLanguage(acceptLanguage) {
+		for ; locale != ""; locale = parentLocale(locale) {
+			if _, ok := s.bundles[locale]; ok {
+				return locale
+			}
+		}
+	}
+	return NormalizeLocale(s.config.DefaultLocale)
+}
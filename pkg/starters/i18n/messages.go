@@ -0,0 +1,243 @@
+// Package i18n provides a MessageSource resolving localized messages, e.g. API
+// error messages and email texts, from bundles per locale
+package i18n
+
+import (
+	"context"
+	"fmt"
+	"io/fs"
+	"log/slog"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"sync"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyI18n holds the message source configuration: i18n.*
+const PropertyI18n = "i18n"
+
+// Config configures the message source: i18n.*
+type Config struct {
+	// Directory holds the bundles (default i18n)
+	Directory string `yaml:"directory"`
+	// Basename names the bundles: messages.yaml, messages_de.properties, ... (default messages)
+	Basename string `yaml:"basename"`
+	// DefaultLocale is tried after the requested locale (default en)
+	DefaultLocale string `yaml:"default-locale"`
+	// Fallbacks maps a locale to the locales tried before the default, e.g. pt-BR: [pt-PT]
+	Fallbacks map[string][]string `yaml:"fallbacks"`
+	// UseCodeAsDefault returns the code of messages that aren't found instead of an error
+	UseCodeAsDefault bool `yaml:"use-code-as-default"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Directory == "" {
+		c.Directory = "i18n"
+	}
+	if c.Basename == "" {
+		c.Basename = "messages"
+	}
+	if c.DefaultLocale == "" {
+		c.DefaultLocale = "en"
+	}
+	return c
+}
+
+// MessageSource resolves messages by code and locale. A message is looked up in
+// the bundle of the locale, of its parents (de-CH, then de), of its configured
+// fallbacks, of the default locale and finally in the base bundle. Messages may
+// reference arguments by position ({0}) or, with a single map argument, by name ({user}).
+type MessageSource struct {
+	fsys   fs.FS
+	config Config
+	app    container.ApplicationContext
+	logger *slog.Logger
+
+	mu      sync.RWMutex
+	bundles map[string]map[string]string
+}
+
+// NewMessageSource creates a message source reading the bundles from fsys, e.g.
+// an embed.FS; with a nil fsys they are read from the working directory
+func NewMessageSource(fsys fs.FS, config Config) *MessageSource {
+	return &MessageSource{fsys: fsys, config: config.withDefaults(), logger: slog.Default()}
+}
+
+// Name returns the component name
+func (s *MessageSource) Name() string {
+	return "messageSource"
+}
+
+// Init loads the bundles
+func (s *MessageSource) Init(app container.ApplicationContext) error {
+	s.app = app
+	return s.Reload()
+}
+
+// Reload reads the bundles again
+func (s *MessageSource) Reload() error {
+	s.mu.RLock()
+	config := s.config
+	s.mu.RUnlock()
+
+	fsys := s.fsys
+	if fsys == nil {
+		fsys = os.DirFS(".")
+	}
+	bundles, err := loadBundles(fsys, config.Directory, config.Basename)
+	if err != nil {
+		return container.ErrorWithCode("MESSAGE_BUNDLES", "load message bundles %s/%s: %v", config.Directory, config.Basename, err)
+	}
+
+	s.mu.Lock()
+	s.bundles = bundles
+	s.mu.Unlock()
+	return nil
+}
+
+// OnVariablesChanged applies the changed i18n configuration and reloads the bundles
+func (s *MessageSource) OnVariablesChanged(changed []string) {
+	configChanged := false
+	for _, name := range changed {
+		if strings.HasPrefix(name, PropertyI18n+".") {
+			configChanged = true
+		}
+	}
+	if configChanged && s.app != nil && container.NewVariableHelper(s.app).HasSection(PropertyI18n) {
+		var config Config
+		if err := s.app.GetVariableAs(PropertyI18n, &config); err != nil {
+			s.logger.Error("Invalid message source configuration", "error", err)
+		} else {
+			s.mu.Lock()
+			s.config = config.withDefaults()
+			s.mu.Unlock()
+		}
+	}
+	if err := s.Reload(); err != nil {
+		s.logger.Error("Message bundles reload failed, keeping previous messages", "error", err)
+		return
+	}
+	s.logger.Info("Message bundles reloaded")
+}
+
+// Locales returns the locales with a bundle, sorted; the base bundle isn't included
+func (s *MessageSource) Locales() []string {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	locales := make([]string, 0, len(s.bundles))
+	for locale := range s.bundles {
+		if locale != "" {
+			locales = append(locales, locale)
+		}
+	}
+	sort.Strings(locales)
+	return locales
+}
+
+// Find returns the message of code in locale, formatted with args
+func (s *MessageSource) Find(locale, code string, args ...any) (string, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+
+	for _, candidate := range s.chain(locale) {
+		if message, ok := s.bundles[candidate][code]; ok {
+			return format(message, args), nil
+		}
+	}
+	if s.config.UseCodeAsDefault {
+		return code, nil
+	}
+	return "", container.ErrorWithCode("MESSAGE_NOT_FOUND", "no message %s for locale %s", code, locale)
+}
+
+// Message returns the message of code in locale, or the code if there is none
+func (s *MessageSource) Message(locale, code string, args ...any) string {
+	message, err := s.Find(locale, code, args...)
+	if err != nil {
+		return code
+	}
+	return message
+}
+
+// MessageContext returns the message of code in the locale of ctx
+func (s *MessageSource) MessageContext(ctx context.Context, code string, args ...any) string {
+	return s.Message(LocaleFromContext(ctx), code, args...)
+}
+
+// chain returns the locales tried for locale, in order and without duplicates
+func (s *MessageSource) chain(locale string) []string {
+	var chain []string
+	seen := make(map[string]bool)
+	var add func(locale string)
+	add = func(locale string) {
+		for locale != "" {
+			if !seen[locale] {
+				seen[locale] = true
+				chain = append(chain, locale)
+				for _, fallback := range s.config.Fallbacks[locale] {
+					add(NormalizeLocale(fallback))
+				}
+			}
+			locale = parentLocale(locale)
+		}
+	}
+	add(NormalizeLocale(locale))
+	add(NormalizeLocale(s.config.DefaultLocale))
+	return append(chain, "")
+}
+
+// format replaces {0}, {1}, ... with the arguments, or {name} with the entries of
+// a single map argument; unknown placeholders are kept
+func format(message string, args []any) string {
+	if len(args) == 0 || !strings.Contains(message, "{") {
+		return message
+	}
+	var named map[string]any
+	if len(args) == 1 {
+		named, _ = args[0].(map[string]any)
+	}
+
+	var b strings.Builder
+	for {
+		start := strings.IndexByte(message, '{')
+		if start < 0 {
+			break
+		}
+		end := strings.IndexByte(message[start:], '}')
+		if end < 0 {
+			break
+		}
+		end += start
+		name := message[start+1 : end]
+
+		b.WriteString(message[:start])
+		if value, ok := argument(name, args, named); ok {
+			fmt.Fprint(&b, value)
+		} else {
+			b.WriteString(message[start : end+1])
+		}
+		message = message[end+1:]
+	}
+	b.WriteString(message)
+	return b.String()
+}
+
+// argument returns the value of a placeholder
+func argument(name string, args []any, named map[string]any) (any, bool) {
+	if named != nil {
+		value, ok := named[name]
+		return value, ok
+	}
+	index, err := strconv.Atoi(name)
+	if err != nil || index < 0 || index >= len(args) {
+		return nil, false
+	}
+	return args[index], true
+}
+
+// Ensure that MessageSource implements container.VariableChangeListener
+var _ container.VariableChangeListener = (*MessageSource)(nil)
@@ -0,0 +1,30 @@
+package i18n
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Starter registers a MessageSource reading the bundles from i18n.directory when
+// i18n.enabled is true. Components get it like any other component:
+//
+//	var messages *i18n.MessageSource
+//	if err := ctx.GetComponent(&messages); err != nil { ... }
+//	text := messages.Message(messages.MatchLocale(r.Header.Get("Accept-Language")), "order.not-found", id)
+//
+// The bundles are read again when the variables are reloaded. To embed the bundles
+// in the binary, register NewMessageSource with an embed.FS instead.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"I18nStarter",
+		container.PropertyCondition(PropertyI18n+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertyI18n, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyI18n, err)
+			}
+			return builder.RegisterComponent(NewMessageSource(nil, config))
+		},
+	)
+}
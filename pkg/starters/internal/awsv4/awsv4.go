@@ -0,0 +1,212 @@
+// Package awsv4 signs requests to AWS services with Signature Version 4, for the
+// starters talking to S3-compatible storage and AWS configuration services
+// without the AWS SDK
+package awsv4
+
+import (
+	"crypto/hmac"
+	"crypto/sha256"
+	"encoding/hex"
+	"net/http"
+	"net/url"
+	"os"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+const (
+	algorithm = "AWS4-HMAC-SHA256"
+	// UnsignedPayload is the payload hash of requests whose body isn't signed,
+	// which S3 accepts
+	UnsignedPayload = "UNSIGNED-PAYLOAD"
+	amzDateFormat   = "20060102T150405Z"
+)
+
+// Credentials sign requests
+type Credentials struct {
+	AccessKey    string
+	SecretKey    string
+	SessionToken string
+}
+
+// CredentialsFromEnv returns the credentials of AWS_ACCESS_KEY_ID,
+// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+func CredentialsFromEnv() Credentials {
+	return Credentials{
+		AccessKey:    os.Getenv("AWS_ACCESS_KEY_ID"),
+		SecretKey:    os.Getenv("AWS_SECRET_ACCESS_KEY"),
+		SessionToken: os.Getenv("AWS_SESSION_TOKEN"),
+	}
+}
+
+// Valid reports whether the access and secret keys are set
+func (c Credentials) Valid() bool {
+	return c.AccessKey != "" && c.SecretKey != ""
+}
+
+// Signer signs the requests of a service in a region
+type Signer struct {
+	Credentials Credentials
+	Region      string
+	// Service is the signing name of the service, e.g. s3 or ssm
+	Service string
+}
+
+// PayloadHash returns the hex SHA-256 of a request body
+func PayloadHash(body []byte) string {
+	hash := sha256.Sum256(body)
+	return hex.EncodeToString(hash[:])
+}
+
+// Sign adds the authorization headers to a request. payloadHash is the
+// PayloadHash of the body, or UnsignedPayload.
+func (s Signer) Sign(req *http.Request, payloadHash string, now time.Time) {
+	req.Header.Set("X-Amz-Date", now.UTC().Format(amzDateFormat))
+	if s.Service == "s3" {
+		req.Header.Set("X-Amz-Content-Sha256", payloadHash)
+	}
+	if s.Credentials.SessionToken != "" {
+		req.Header.Set("X-Amz-Security-Token", s.Credentials.SessionToken)
+	}
+
+	headers := map[string]string{"host": req.URL.Host}
+	for key, values := range req.Header {
+		lower := strings.ToLower(key)
+		if lower == "content-type" || strings.HasPrefix(lower, "x-amz-") {
+			headers[lower] = strings.TrimSpace(strings.Join(values, ","))
+		}
+	}
+	signedHeaders, canonicalHeaders := canonicalHeaders(headers)
+
+	canonical := strings.Join([]string{
+		req.Method,
+		canonicalPath(req.URL),
+		CanonicalQuery(req.URL.Query()),
+		canonicalHeaders,
+		signedHeaders,
+		payloadHash,
+	}, "\n")
+	req.Header.Set("Authorization", algorithm+
+		" Credential="+s.Credentials.AccessKey+"/"+s.scope(now)+
+		", SignedHeaders="+signedHeaders+
+		", Signature="+s.signature(now, canonical))
+}
+
+// Presign returns the URL with the query parameters authorizing the request
+func (s Signer) Presign(method string, u *url.URL, expiry time.Duration, now time.Time) string {
+	query := u.Query()
+	query.Set("X-Amz-Algorithm", algorithm)
+	query.Set("X-Amz-Credential", s.Credentials.AccessKey+"/"+s.scope(now))
+	query.Set("X-Amz-Date", now.UTC().Format(amzDateFormat))
+	query.Set("X-Amz-Expires", strconv.Itoa(int(expiry/time.Second)))
+	query.Set("X-Amz-SignedHeaders", "host")
+	if s.Credentials.SessionToken != "" {
+		query.Set("X-Amz-Security-Token", s.Credentials.SessionToken)
+	}
+
+	canonical := strings.Join([]string{
+		method,
+		canonicalPath(u),
+		CanonicalQuery(query),
+		"host:" + u.Host + "\n",
+		"host",
+		UnsignedPayload,
+	}, "\n")
+	query.Set("X-Amz-Signature", s.signature(now, canonical))
+
+	signed := *u
+	signed.RawQuery = CanonicalQuery(query)
+	return signed.String()
+}
+
+// scope is the credential scope of the day
+func (s Signer) scope(now time.Time) string {
+	return now.UTC().Format("20060102") + "/" + s.Region + "/" + s.Service + "/aws4_request"
+}
+
+// signature signs the canonical request with the key derived for the day
+func (s Signer) signature(now time.Time, canonical string) string {
+	stringToSign := algorithm + "\n" +
+		now.UTC().Format(amzDateFormat) + "\n" +
+		s.scope(now) + "\n" +
+		PayloadHash([]byte(canonical))
+
+	key := hmacSHA256([]byte("AWS4"+s.Credentials.SecretKey), now.UTC().Format("20060102"))
+	key = hmacSHA256(key, s.Region)
+	key = hmacSHA256(key, s.Service)
+	key = hmacSHA256(key, "aws4_request")
+	return hex.EncodeToString(hmacSHA256(key, stringToSign))
+}
+
+func hmacSHA256(key []byte, data string) []byte {
+	mac := hmac.New(sha256.New, key)
+	mac.Write([]byte(data))
+	return mac.Sum(nil)
+}
+
+// canonicalHeaders returns the signed header names and the canonical headers
+func canonicalHeaders(headers map[string]string) (string, string) {
+	names := make([]string, 0, len(headers))
+	for name := range headers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var canonical strings.Builder
+	for _, name := range names {
+		canonical.WriteString(name + ":" + headers[name] + "\n")
+	}
+	return strings.Join(names, ";"), canonical.String()
+}
+
+// canonicalPath is the URI-encoded path; S3 paths are encoded once
+func canonicalPath(u *url.URL) string {
+	path := u.EscapedPath()
+	if path == "" {
+		return "/"
+	}
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		unescaped, err := url.PathUnescape(segment)
+		if err != nil {
+			unescaped = segment
+		}
+		segments[i] = URIEncode(unescaped)
+	}
+	return strings.Join(segments, "/")
+}
+
+// CanonicalQuery encodes the query parameters sorted by name
+func CanonicalQuery(query url.Values) string {
+	keys := make([]string, 0, len(query))
+	for key := range query {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	var parts []string
+	for _, key := range keys {
+		values := append([]string(nil), query[key]...)
+		sort.Strings(values)
+		for _, value := range values {
+			parts = append(parts, URIEncode(key)+"="+URIEncode(value))
+		}
+	}
+	return strings.Join(parts, "&")
+}
+
+// URIEncode percent-encodes everything but the unreserved characters of RFC 3986
+func URIEncode(value string) string {
+	var encoded strings.Builder
+	for _, b := range []byte(value) {
+		if 'A' <= b && b <= 'Z' || 'a' <= b && b <= 'z' || '0' <= b && b <= '9' ||
+			b == '-' || b == '_' || b == '.' || b == '~' {
+			encoded.WriteByte(b)
+			continue
+		}
+		encoded.WriteString("%" + strings.ToUpper(hex.EncodeToString([]byte{b})))
+	}
+	return encoded.String()
+}
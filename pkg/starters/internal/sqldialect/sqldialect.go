@@ -0,0 +1,33 @@
+// Package sqldialect holds the SQL dialects supported by the starters storing
+// data through database/sql, and the bind parameters each of them uses
+package sqldialect
+
+import (
+	"strconv"
+	"strings"
+)
+
+// Supported SQL dialects
+const (
+	Postgres = "postgres"
+	MySQL    = "mysql"
+	SQLite   = "sqlite"
+)
+
+// Placeholder returns the bind parameter at position, counted from 1:
+// $1, $2... for Postgres and ? otherwise
+func Placeholder(dialect string, position int) string {
+	if dialect == Postgres {
+		return "$" + strconv.Itoa(position)
+	}
+	return "?"
+}
+
+// Placeholders returns count comma separated bind parameters starting at position from
+func Placeholders(dialect string, from, count int) string {
+	params := make([]string, count)
+	for i := range params {
+		params[i] = Placeholder(dialect, from+i)
+	}
+	return strings.Join(params, ", ")
+}
@@ -0,0 +1,22 @@
+package sqldialect
+
+import "testing"
+
+func TestPlaceholders(t *testing.T) {
+	tests := []struct {
+		dialect string
+		want    string
+	}{
+		{dialect: Postgres, want: "$3, $4, $5"},
+		{dialect: MySQL, want: "?, ?, ?"},
+		{dialect: SQLite, want: "?, ?, ?"},
+	}
+
+	for _, tt := range tests {
+		t.Run(tt.dialect, func(t *testing.T) {
+			if got := Placeholders(tt.dialect, 3, 3); got != tt.want {
+				t.Errorf("Placeholders() = %q, want %q", got, tt.want)
+			}
+		})
+	}
+}
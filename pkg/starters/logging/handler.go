@@ -0,0 +1,180 @@
+// Package logging configures the default slog logger from the logging.*
+// properties: level, format, output file with rotation and attributes added to
+// every record
+package logging
+
+import (
+	"fmt"
+	"io"
+	"log/slog"
+	"os"
+	"sort"
+	"strings"
+
+	"github.com/01fortes/goboot/pkg/starters/propagation"
+)
+
+// PropertyLogging holds the logging configuration: logging.*
+const PropertyLogging = "logging"
+
+// Formats selected by logging.format
+const (
+	FormatText = "text"
+	FormatJSON = "json"
+)
+
+// Outputs selected by logging.output
+const (
+	OutputStdout = "stdout"
+	OutputStderr = "stderr"
+	OutputFile   = "file"
+)
+
+// Config configures logging: logging.*
+type Config struct {
+	// Enabled configures the default logger (default true when the logging section
+	// exists)
+	Enabled *bool `yaml:"enabled"`
+	// Level is debug, info (default), warn or error; it can be changed at runtime by
+	// reloading the variables
+	Level string `yaml:"level"`
+	// Format is text (default) or json
+	Format string `yaml:"format"`
+	// Output is stdout (default), stderr or file; ignored when sinks are configured
+	Output string     `yaml:"output"`
+	File   FileConfig `yaml:"file"`
+	// Sinks write the records to several outputs by name, each with its own level
+	// and format, e.g. a text console and a JSON file
+	Sinks map[string]SinkConfig `yaml:"sinks"`
+	// AddSource adds the source file and line of the log call
+	AddSource bool `yaml:"add-source"`
+	// Attributes are added to every record, e.g. service: shop; ${name} in a value
+	// is replaced with the variable name, or else the environment variable, e.g.
+	// instance: ${cloud.instance-id}
+	Attributes map[string]string `yaml:"attributes"`
+	// Propagation adds the correlation ID, tenant ID and baggage of the context of
+	// the record (default true); see propagation.NewLogHandler
+	Propagation *bool `yaml:"propagation"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Level == "" {
+		c.Level = "info"
+	}
+	if c.Format == "" {
+		c.Format = FormatText
+	}
+	if c.Output == "" {
+		c.Output = OutputStdout
+	}
+	if c.Propagation == nil {
+		propagate := true
+		c.Propagation = &propagate
+	}
+	c.File = c.File.withDefaults()
+	return c
+}
+
+// SinkConfig configures a sink: logging.sinks.<name>.*
+type SinkConfig struct {
+	// Enabled turns the sink on (default true)
+	Enabled *bool `yaml:"enabled"`
+	// Output is stdout (default), stderr or file
+	Output string `yaml:"output"`
+	// Level and Format default to logging.level and logging.format
+	Level  string     `yaml:"level"`
+	Format string     `yaml:"format"`
+	File   FileConfig `yaml:"file"`
+}
+
+// sinks returns the enabled sinks by name; without sinks, logging.output is the
+// sink named default
+func (c Config) sinks() map[string]SinkConfig {
+	if len(c.Sinks) == 0 {
+		return map[string]SinkConfig{"default": {Output: c.Output, File: c.File}}
+	}
+	sinks := make(map[string]SinkConfig, len(c.Sinks))
+	for name, sink := range c.Sinks {
+		if sink.Enabled != nil && !*sink.Enabled {
+			continue
+		}
+		if sink.Output == "" {
+			sink.Output = OutputStdout
+		}
+		if sink.Format == "" {
+			sink.Format = c.Format
+		}
+		sinks[name] = sink
+	}
+	return sinks
+}
+
+// parseLevel parses a level name such as debug or warn+2
+func parseLevel(name string) (slog.Level, error) {
+	var level slog.Level
+	if err := level.UnmarshalText([]byte(name)); err != nil {
+		return 0, fmt.Errorf("invalid %s.level %q", PropertyLogging, name)
+	}
+	return level, nil
+}
+
+// NewHandler creates the handler of the configuration writing to w, with its
+// level controlled by level
+func NewHandler(config Config, w io.Writer, level slog.Leveler) (slog.Handler, error) {
+	config = config.withDefaults()
+	handler, err := formatHandler(config.Format, w, &slog.HandlerOptions{Level: level, AddSource: config.AddSource})
+	if err != nil {
+		return nil, err
+	}
+	return decorate(handler, config), nil
+}
+
+// formatHandler creates the handler of a format
+func formatHandler(format string, w io.Writer, options *slog.HandlerOptions) (slog.Handler, error) {
+	switch strings.ToLower(format) {
+	case FormatText:
+		return slog.NewTextHandler(w, options), nil
+	case FormatJSON:
+		return slog.NewJSONHandler(w, options), nil
+	default:
+		return nil, fmt.Errorf("unsupported %s.format %q", PropertyLogging, format)
+	}
+}
+
+// decorate adds the attributes and the propagated values of the configuration
+func decorate(handler slog.Handler, config Config) slog.Handler {
+	if len(config.Attributes) > 0 {
+		keys := make([]string, 0, len(config.Attributes))
+		for key := range config.Attributes {
+			keys = append(keys, key)
+		}
+		sort.Strings(keys)
+		attrs := make([]slog.Attr, 0, len(keys))
+		for _, key := range keys {
+			attrs = append(attrs, slog.String(key, config.Attributes[key]))
+		}
+		handler = handler.WithAttrs(attrs)
+	}
+	if *config.Propagation {
+		handler = propagation.NewLogHandler(handler)
+	}
+	return handler
+}
+
+// openOutput opens the writer of an output; closing it closes the log file
+func openOutput(output string, file FileConfig) (io.Writer, io.Closer, error) {
+	switch output {
+	case OutputStdout:
+		return os.Stdout, nil, nil
+	case OutputStderr:
+		return os.Stderr, nil, nil
+	case OutputFile:
+		rotating, err := OpenRotatingFile(file)
+		if err != nil {
+			return nil, nil, err
+		}
+		return rotating, rotating, nil
+	default:
+		return nil, nil, fmt.Errorf("unsupported %s output %q", PropertyLogging, output)
+	}
+}
@@ -0,0 +1,187 @@
+package logging
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+)
+
+// FileConfig configures a log file: logging.file.* or logging.sinks.<name>.file.*
+type FileConfig struct {
+	// Path of the log file (default logs/application.log)
+	Path string `yaml:"path"`
+	// MaxSize is the size in megabytes that rotates the file (default 100)
+	MaxSize int `yaml:"max-size"`
+	// MaxBackups is the number of rotated files kept, path.1 being the most recent
+	// (default 5)
+	MaxBackups int `yaml:"max-backups"`
+	// Rotate also rotates the file when the hour or the day changes: hourly or
+	// daily (by default only the size rotates it)
+	Rotate string `yaml:"rotate"`
+}
+
+// Periods of FileConfig.Rotate
+const (
+	RotateHourly = "hourly"
+	RotateDaily  = "daily"
+)
+
+// rotateRetryDelay is how long a file whose rotation failed is appended to before
+// rotating it is tried again
+const rotateRetryDelay = time.Minute
+
+func (c FileConfig) withDefaults() FileConfig {
+	if c.Path == "" {
+		c.Path = filepath.Join("logs", "application.log")
+	}
+	if c.MaxSize <= 0 {
+		c.MaxSize = 100
+	}
+	if c.MaxBackups <= 0 {
+		c.MaxBackups = 5
+	}
+	return c
+}
+
+// RotatingFile is a log file that is renamed to path.1 when a write would make it
+// larger than its maximum size, or on the first write of a new period; older
+// files are shifted to path.2 and so on
+type RotatingFile struct {
+	config  FileConfig
+	maxSize int64
+	now     func() time.Time
+
+	mu     sync.Mutex
+	file   *os.File
+	closed bool
+	size   int64
+	// period is the start of the period of the current file
+	period time.Time
+	// retryAt is when rotating is tried again after a failed rotation
+	retryAt time.Time
+}
+
+// OpenRotatingFile opens the log file for appending, creating its directory
+func OpenRotatingFile(config FileConfig) (*RotatingFile, error) {
+	config = config.withDefaults()
+	switch config.Rotate {
+	case "", RotateHourly, RotateDaily:
+	default:
+		return nil, fmt.Errorf("unsupported %s file rotation %q", PropertyLogging, config.Rotate)
+	}
+	f := &RotatingFile{config: config, maxSize: int64(config.MaxSize) << 20, now: time.Now}
+	if err := os.MkdirAll(filepath.Dir(config.Path), 0o755); err != nil {
+		return nil, fmt.Errorf("create log directory: %w", err)
+	}
+	if err := f.open(); err != nil {
+		return nil, err
+	}
+	return f, nil
+}
+
+func (f *RotatingFile) open() error {
+	file, err := os.OpenFile(f.config.Path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return fmt.Errorf("open log file: %w", err)
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return fmt.Errorf("open log file: %w", err)
+	}
+	f.file, f.size = file, info.Size()
+	// A file written in an earlier period is rotated on the first write
+	f.period = f.periodOf(f.now())
+	if f.size > 0 {
+		f.period = f.periodOf(info.ModTime())
+	}
+	return nil
+}
+
+// periodOf returns the start of the rotation period of t
+func (f *RotatingFile) periodOf(t time.Time) time.Time {
+	switch f.config.Rotate {
+	case RotateHourly:
+		return time.Date(t.Year(), t.Month(), t.Day(), t.Hour(), 0, 0, 0, t.Location())
+	case RotateDaily:
+		return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	default:
+		return time.Time{}
+	}
+}
+
+// Write appends p, rotating the file first if it would grow past its maximum size
+// or its period is over. If the file can't be rotated, p is appended to it, the
+// error is returned and rotating is tried again after rotateRetryDelay.
+func (f *RotatingFile) Write(p []byte) (int, error) {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	if f.closed {
+		return 0, os.ErrClosed
+	}
+	if f.file == nil {
+		// Reopen the file a failed rotation couldn't open
+		if err := f.open(); err != nil {
+			return 0, err
+		}
+	}
+
+	now := f.now()
+	period := f.periodOf(now)
+	expired := !period.Equal(f.period)
+	var rotateErr error
+	if f.size > 0 && (expired || f.size+int64(len(p)) > f.maxSize) && !now.Before(f.retryAt) {
+		if rotateErr = f.rotate(); rotateErr != nil {
+			if f.file == nil {
+				return 0, rotateErr
+			}
+			f.retryAt = now.Add(rotateRetryDelay)
+		}
+	}
+	f.period = period
+	n, err := f.file.Write(p)
+	f.size += int64(n)
+	if err == nil {
+		err = rotateErr
+	}
+	return n, err
+}
+
+// rotate shifts the backups, dropping the oldest, and starts a new file. A failed
+// rename keeps the current file open; a file that can't be opened leaves f.file nil.
+func (f *RotatingFile) rotate() error {
+	_ = f.file.Close()
+	f.file = nil
+
+	path := f.config.Path
+	_ = os.Remove(path + "." + strconv.Itoa(f.config.MaxBackups))
+	for i := f.config.MaxBackups - 1; i >= 1; i-- {
+		_ = os.Rename(path+"."+strconv.Itoa(i), path+"."+strconv.Itoa(i+1))
+	}
+	renameErr := os.Rename(path, path+".1")
+	if err := f.open(); err != nil {
+		return err
+	}
+	if renameErr != nil {
+		return fmt.Errorf("rotate log file: %w", renameErr)
+	}
+	return nil
+}
+
+// Close closes the file; later writes fail
+func (f *RotatingFile) Close() error {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+
+	f.closed = true
+	if f.file == nil {
+		return nil
+	}
+	err := f.file.Close()
+	f.file = nil
+	return err
+}
@@ -0,0 +1,89 @@
+package logging
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+)
+
+// openTestFile opens a rotating file of one megabyte whose clock is advanced by the test
+func openTestFile(t *testing.T) (*RotatingFile, *time.Time) {
+	t.Helper()
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	f, err := OpenRotatingFile(FileConfig{Path: filepath.Join(t.TempDir(), "app.log"), MaxSize: 1, MaxBackups: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+	f.now = func() time.Time { return now }
+	t.Cleanup(func() { f.Close() })
+	return f, &now
+}
+
+func readFile(t *testing.T, path string) string {
+	t.Helper()
+	data, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return string(data)
+}
+
+func TestRotatingFileFailedRename(t *testing.T) {
+	f, now := openTestFile(t)
+	path := f.config.Path
+	big := make([]byte, 1<<20)
+	if _, err := f.Write(big); err != nil {
+		t.Fatal(err)
+	}
+
+	// A non-empty directory at path.1 makes the rename fail
+	if err := os.MkdirAll(filepath.Join(path+".1", "keep"), 0o755); err != nil {
+		t.Fatal(err)
+	}
+	n, err := f.Write([]byte("first\n"))
+	if err == nil || n != len("first\n") {
+		t.Fatalf("Write() = %d, %v, want the line written and the rotation error", n, err)
+	}
+	if n, err := f.Write([]byte("second\n")); err != nil || n != len("second\n") {
+		t.Fatalf("Write() during the retry delay = %d, %v", n, err)
+	}
+	if got, want := readFile(t, path), string(big)+"first\nsecond\n"; got != want {
+		t.Errorf("file has %d bytes, want %d", len(got), len(want))
+	}
+
+	// Rotating is tried again after the delay
+	if err := os.RemoveAll(path + ".1"); err != nil {
+		t.Fatal(err)
+	}
+	*now = now.Add(rotateRetryDelay)
+	if _, err := f.Write([]byte("third\n")); err != nil {
+		t.Fatalf("Write() after the retry delay error = %v", err)
+	}
+	if got := readFile(t, path); got != "third\n" {
+		t.Errorf("file after rotating = %q, want %q", got, "third\n")
+	}
+	if got := readFile(t, path+".1"); len(got) != len(big)+len("first\nsecond\n") {
+		t.Errorf("backup has %d bytes, want %d", len(got), len(big)+len("first\nsecond\n"))
+	}
+}
+
+func TestRotatingFileReopensAfterFailedOpen(t *testing.T) {
+	f, _ := openTestFile(t)
+	path := f.config.Path
+
+	// A failed rotation leaves no file open
+	f.file.Close()
+	f.file = nil
+	if _, err := f.Write([]byte("reopened\n")); err != nil {
+		t.Fatalf("Write() error = %v", err)
+	}
+	if got := readFile(t, path); got != "reopened\n" {
+		t.Errorf("file = %q, want %q", got, "reopened\n")
+	}
+
+	f.Close()
+	if _, err := f.Write([]byte("closed\n")); err != os.ErrClosed {
+		t.Errorf("Write() after Close error = %v, want os.ErrClosed", err)
+	}
+}
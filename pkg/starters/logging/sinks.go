@@ -0,0 +1,133 @@
+package logging
+
+import (
+	"context"
+	"errors"
+	"io"
+	"log/slog"
+	"sort"
+)
+
+// sink is an output of the records with its level
+type sink struct {
+	name    string
+	level   *slog.LevelVar
+	handler slog.Handler
+	closer  io.Closer
+	// inherited is true when the level follows logging.level
+	inherited bool
+}
+
+// openSinks opens the sinks of the configuration sorted by name, closing the
+// opened ones on error
+func openSinks(config Config, level slog.Level) ([]*sink, error) {
+	configs := config.sinks()
+	names := make([]string, 0, len(configs))
+	for name := range configs {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	var sinks []*sink
+	for _, name := range names {
+		s, err := openSink(name, configs[name], config, level)
+		if err != nil {
+			closeSinks(sinks)
+			return nil, err
+		}
+		sinks = append(sinks, s)
+	}
+	return sinks, nil
+}
+
+func openSink(name string, sinkConfig SinkConfig, config Config, level slog.Level) (*sink, error) {
+	s := &sink{name: name, level: new(slog.LevelVar), inherited: sinkConfig.Level == ""}
+	s.level.Set(level)
+	if !s.inherited {
+		own, err := parseLevel(sinkConfig.Level)
+		if err != nil {
+			return nil, err
+		}
+		s.level.Set(own)
+	}
+
+	format := sinkConfig.Format
+	if format == "" {
+		format = config.Format
+	}
+	// Check the format before creating a log file
+	if _, err := formatHandler(format, io.Discard, nil); err != nil {
+		return nil, err
+	}
+	w, closer, err := openOutput(sinkConfig.Output, sinkConfig.File)
+	if err != nil {
+		return nil, err
+	}
+	s.handler, _ = formatHandler(format, w, &slog.HandlerOptions{Level: s.level, AddSource: config.AddSource})
+	s.closer = closer
+	return s, nil
+}
+
+// closeSinks closes the log files of the sinks
+func closeSinks(sinks []*sink) {
+	for _, s := range sinks {
+		if s.closer != nil {
+			_ = s.closer.Close()
+		}
+	}
+}
+
+// fanoutHandler passes the records to several handlers
+type fanoutHandler struct {
+	handlers []slog.Handler
+}
+
+// newFanoutHandler returns the handler of the sinks, or the handler of the only
+// sink
+func newFanoutHandler(sinks []*sink) slog.Handler {
+	if len(sinks) == 1 {
+		return sinks[0].handler
+	}
+	handlers := make([]slog.Handler, len(sinks))
+	for i, s := range sinks {
+		handlers[i] = s.handler
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (h *fanoutHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, level) {
+			return true
+		}
+	}
+	return false
+}
+
+func (h *fanoutHandler) Handle(ctx context.Context, record slog.Record) error {
+	var errs []error
+	for _, handler := range h.handlers {
+		if handler.Enabled(ctx, record.Level) {
+			if err := handler.Handle(ctx, record.Clone()); err != nil {
+				errs = append(errs, err)
+			}
+		}
+	}
+	return errors.Join(errs...)
+}
+
+func (h *fanoutHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithAttrs(attrs)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
+
+func (h *fanoutHandler) WithGroup(name string) slog.Handler {
+	handlers := make([]slog.Handler, len(h.handlers))
+	for i, handler := range h.handlers {
+		handlers[i] = handler.WithGroup(name)
+	}
+	return &fanoutHandler{handlers: handlers}
+}
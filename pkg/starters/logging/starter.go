@@ -0,0 +1,191 @@
+package logging
+
+import (
+	"context"
+	"fmt"
+	"log/slog"
+	"os"
+	"strings"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// LoggingSystemName is the LoggingSystem component
+const LoggingSystemName = "loggingSystem"
+
+// LoggingSystem owns the default logger configured by the starter. It changes the
+// levels when logging.level or logging.sinks.<name>.level is reloaded, and on stop
+// restores the previous default logger and closes the log files.
+type LoggingSystem struct {
+	level    slog.LevelVar
+	sinks    []*sink
+	previous *slog.Logger
+	app      container.ApplicationContext
+	logger   *slog.Logger
+}
+
+// Name returns the component name
+func (s *LoggingSystem) Name() string {
+	return LoggingSystemName
+}
+
+// Init keeps the context to read the reloaded levels from
+func (s *LoggingSystem) Init(app container.ApplicationContext) error {
+	s.app = app
+	return nil
+}
+
+// Start is a no-op; the logger is configured by the starter, before the
+// components are initialized
+func (s *LoggingSystem) Start(context.Context) {}
+
+// Stop restores the previous default logger and closes the log files
+func (s *LoggingSystem) Stop(context.Context) {
+	slog.SetDefault(s.previous)
+	closeSinks(s.sinks)
+}
+
+// Level returns the level of logging.level
+func (s *LoggingSystem) Level() slog.Level {
+	return s.level.Level()
+}
+
+// SetLevel changes the level of the sinks without a level of their own
+func (s *LoggingSystem) SetLevel(level slog.Level) {
+	s.level.Set(level)
+	for _, sink := range s.sinks {
+		if sink.inherited {
+			sink.level.Set(level)
+		}
+	}
+}
+
+// SetSinkLevel changes the level of a sink
+func (s *LoggingSystem) SetSinkLevel(name string, level slog.Level) error {
+	for _, sink := range s.sinks {
+		if sink.name == name {
+			sink.level.Set(level)
+			sink.inherited = false
+			return nil
+		}
+	}
+	return fmt.Errorf("unknown log sink %q", name)
+}
+
+// OnVariablesChanged applies changed levels; the other settings apply on restart
+func (s *LoggingSystem) OnVariablesChanged(changed []string) {
+	for _, name := range changed {
+		if !strings.HasPrefix(name, PropertyLogging+".") {
+			continue
+		}
+		sinkName, isSink := strings.CutPrefix(name, PropertyLogging+".sinks.")
+		sinkName, isLevel := strings.CutSuffix(sinkName, ".level")
+		isSinkLevel := isSink && isLevel
+		if name != PropertyLogging+".level" && !isSinkLevel {
+			s.logger.Warn("Logging configuration changed, restart to apply it", "property", name)
+			continue
+		}
+
+		level, err := parseLevel(s.app.GetVariable(name))
+		if err != nil {
+			s.logger.Error("Logging level not changed", "error", err)
+			continue
+		}
+		if isSinkLevel {
+			err = s.SetSinkLevel(sinkName, level)
+		} else {
+			s.SetLevel(level)
+		}
+		if err != nil {
+			s.logger.Warn("Logging level not changed, restart to apply it", "property", name, "error", err)
+			continue
+		}
+		s.logger.Info("Logging level changed", "property", name, "level", level)
+	}
+}
+
+// Starter replaces the default slog logger with the one of the logging.*
+// properties when the logging section exists, so that main() doesn't set up a
+// handler:
+//
+//	logging:
+//	  level: info
+//	  format: json
+//	  output: file
+//	  file:
+//	    path: logs/shop.log
+//	    max-size: 50
+//	  attributes:
+//	    service: shop
+//	    instance: ${cloud.instance-id}
+//
+// Sinks write to several outputs instead, e.g. a console for humans and a file
+// rotated every day for the support team:
+//
+//	logging:
+//	  level: info
+//	  sinks:
+//	    console:
+//	      format: text
+//	    file:
+//	      output: file
+//	      format: json
+//	      level: debug
+//	      file:
+//	        path: logs/shop.log
+//	        rotate: daily
+//	        max-backups: 14
+//
+// Components created after the starters log with it; the container keeps the
+// logger it was created with.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"LoggingStarter",
+		func(ctx container.ApplicationContext) bool {
+			return container.NewVariableHelper(ctx).HasSection(PropertyLogging) &&
+				container.NewVariableHelper(ctx).GetBool(PropertyLogging+".enabled", true)
+		},
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertyLogging, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyLogging, err)
+			}
+			config = config.withDefaults()
+			for key, value := range config.Attributes {
+				config.Attributes[key] = expand(builder, value)
+			}
+
+			level, err := parseLevel(config.Level)
+			if err != nil {
+				return err
+			}
+			sinks, err := openSinks(config, level)
+			if err != nil {
+				return err
+			}
+			system := &LoggingSystem{sinks: sinks, previous: slog.Default()}
+			system.level.Set(level)
+			system.logger = slog.New(decorate(newFanoutHandler(sinks), config))
+			if err := builder.RegisterComponent(system); err != nil {
+				closeSinks(sinks)
+				return err
+			}
+			slog.SetDefault(system.logger)
+			return nil
+		},
+	)
+}
+
+// expand replaces ${name} in an attribute value with the variable name, or else
+// the environment variable
+func expand(ctx container.ApplicationContext, value string) string {
+	return os.Expand(value, func(name string) string {
+		if ctx.HasVariable(name) {
+			return ctx.GetVariable(name)
+		}
+		return os.Getenv(name)
+	})
+}
+
+// Ensure that LoggingSystem is notified of reloaded variables
+var _ container.VariableChangeListener = (*LoggingSystem)(nil)
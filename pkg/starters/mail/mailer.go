@@ -0,0 +1,146 @@
+package mail
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+	"sync/atomic"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/resilience"
+	"github.com/01fortes/goboot/pkg/starters/templates"
+)
+
+// Metrics is a snapshot of the mailer counters
+type Metrics struct {
+	Sent    int64
+	Failed  int64
+	Retries int64
+}
+
+// Mailer sends messages through its transport, retrying transient failures with
+// exponential backoff
+type Mailer struct {
+	from      string
+	transport Transport
+	retryer   *resilience.Retryer
+	renderer  *templates.TemplateRenderer
+
+	sent   atomic.Int64
+	failed atomic.Int64
+}
+
+// NewMailer creates a mailer; from is the default sender
+func NewMailer(from string, transport Transport, retry resilience.RetryConfig) *Mailer {
+	retryer := resilience.NewRetryer("mail", retry)
+	retryer.Retryable = func(err error) bool {
+		return !errors.Is(err, ErrPermanent) &&
+			!errors.Is(err, context.Canceled) &&
+			!errors.Is(err, context.DeadlineExceeded)
+	}
+	return &Mailer{from: from, transport: transport, retryer: retryer}
+}
+
+// Name returns the component name
+func (m *Mailer) Name() string {
+	return MailerName
+}
+
+// Init finds the template renderer, if the templates starter registered one
+func (m *Mailer) Init(ctx container.ApplicationContext) error {
+	if !ctx.HasComponent("templateRenderer") {
+		return nil
+	}
+	renderer, err := container.GetComponentAs[*templates.TemplateRenderer](ctx, "templateRenderer")
+	if err != nil {
+		return err
+	}
+	m.renderer = renderer
+	return nil
+}
+
+// Transport returns the transport of the mailer
+func (m *Mailer) Transport() Transport {
+	return m.transport
+}
+
+// Send validates and sends a message, retrying transient failures
+func (m *Mailer) Send(ctx context.Context, message Message) error {
+	if message.From == "" {
+		message.From = m.from
+	}
+	if err := message.validate(); err != nil {
+		m.failed.Add(1)
+		return err
+	}
+
+	if err := m.retryer.Do(ctx, func(ctx context.Context) error {
+		return m.transport.Send(ctx, &message)
+	}); err != nil {
+		m.failed.Add(1)
+		return fmt.Errorf("send mail %q: %w", message.Subject, err)
+	}
+	m.sent.Add(1)
+	return nil
+}
+
+// SendTemplate renders the bodies of a message from the templates of the templates
+// starter, then sends it. The text body comes from <name>.txt and the HTML body
+// from <name>.html, whichever exist; <name>.subject.txt, if it exists, renders the
+// subject.
+//
+//	err := mailer.SendTemplate(ctx, mail.Message{To: []string{user.Email}}, "mail/welcome", user)
+func (m *Mailer) SendTemplate(ctx context.Context, message Message, name string, data any) error {
+	if m.renderer == nil {
+		return container.ErrorWithCode("TEMPLATES_DISABLED", "mail template %s needs the templates starter (templates.enabled)", name)
+	}
+
+	available := make(map[string]bool)
+	for _, template := range m.renderer.Names() {
+		available[template] = true
+	}
+	for _, part := range []struct {
+		template string
+		target   *string
+	}{
+		{name + ".subject.txt", &message.Subject},
+		{name + ".txt", &message.Text},
+		{name + ".html", &message.HTML},
+	} {
+		if !available[part.template] {
+			continue
+		}
+		rendered, err := m.renderer.RenderString(part.template, data)
+		if err != nil {
+			return fmt.Errorf("render mail template %s: %w", part.template, err)
+		}
+		*part.target = rendered
+	}
+	message.Subject = strings.TrimSpace(message.Subject)
+
+	if message.Text == "" && message.HTML == "" {
+		return container.ErrorWithCode("TEMPLATE_NOT_FOUND", "mail template %s has neither %s.txt nor %s.html", name, name, name)
+	}
+	return m.Send(ctx, message)
+}
+
+// WarmUp checks the connection of a transport that supports it, e.g. SMTP
+func (m *Mailer) WarmUp(ctx context.Context) error {
+	if warmUp, ok := m.transport.(interface{ WarmUp(context.Context) error }); ok {
+		return warmUp.WarmUp(ctx)
+	}
+	return nil
+}
+
+// Metrics returns a snapshot of the mailer counters
+func (m *Mailer) Metrics() Metrics {
+	return Metrics{
+		Sent:    m.sent.Load(),
+		Failed:  m.failed.Load(),
+		Retries: m.retryer.Metrics().Retries,
+	}
+}
+
+// Ensure that Mailer implements container.WarmUpComponent
+var _ container.WarmUpComponent = (*Mailer)(nil)
@@ -0,0 +1,248 @@
+// Package mail sends email through SMTP or an email API, with bodies rendered by
+// the templates starter and transient failures retried with backoff
+package mail
+
+import (
+	"bytes"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/hex"
+	"fmt"
+	"io"
+	"mime"
+	"mime/multipart"
+	"mime/quotedprintable"
+	"net/mail"
+	"net/textproto"
+	"path"
+	"sort"
+	"strings"
+	"time"
+)
+
+// Message is an email. At least one recipient and a text or HTML body are required.
+type Message struct {
+	// From defaults to mail.from
+	From    string
+	To      []string
+	Cc      []string
+	Bcc     []string
+	ReplyTo string
+	Subject string
+	// Text and HTML are the bodies; with both, clients pick the HTML one
+	Text string
+	HTML string
+	// Headers are additional headers, e.g. List-Unsubscribe
+	Headers     map[string]string
+	Attachments []Attachment
+}
+
+// Attachment is a file attached to a message
+type Attachment struct {
+	Filename string
+	// ContentType defaults to the type of the file extension
+	ContentType string
+	Data        []byte
+}
+
+// Recipients returns the addresses of all recipients, including Bcc
+func (m *Message) Recipients() []string {
+	recipients := make([]string, 0, len(m.To)+len(m.Cc)+len(m.Bcc))
+	recipients = append(recipients, m.To...)
+	recipients = append(recipients, m.Cc...)
+	return append(recipients, m.Bcc...)
+}
+
+// validate checks the addresses and that the message has a body
+func (m *Message) validate() error {
+	if m.From == "" {
+		return fmt.Errorf("mail has no sender: set From or mail.from")
+	}
+	if len(m.Recipients()) == 0 {
+		return fmt.Errorf("mail has no recipient")
+	}
+	if m.Text == "" && m.HTML == "" {
+		return fmt.Errorf("mail has no body")
+	}
+	for _, address := range append(m.Recipients(), m.From) {
+		if _, err := mail.ParseAddress(address); err != nil {
+			return fmt.Errorf("invalid mail address %q: %w", address, err)
+		}
+	}
+	return nil
+}
+
+// Bytes formats the message as MIME, without the Bcc recipients
+func (m *Message) Bytes() ([]byte, error) {
+	header := textproto.MIMEHeader{}
+	header.Set("From", formatAddresses(m.From))
+	header.Set("To", formatAddresses(m.To...))
+	if len(m.Cc) > 0 {
+		header.Set("Cc", formatAddresses(m.Cc...))
+	}
+	if m.ReplyTo != "" {
+		header.Set("Reply-To", formatAddresses(m.ReplyTo))
+	}
+	header.Set("Subject", mime.QEncoding.Encode("utf-8", m.Subject))
+	header.Set("Date", time.Now().Format(time.RFC1123Z))
+	header.Set("Message-ID", messageID(m.From))
+	header.Set("MIME-Version", "1.0")
+	for key, value := range m.Headers {
+		header.Set(key, mime.QEncoding.Encode("utf-8", value))
+	}
+
+	bodyHeader, body, err := m.body()
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if len(m.Attachments) == 0 {
+		for key, values := range bodyHeader {
+			header[key] = values
+		}
+		writeHeader(&buf, header)
+		buf.Write(body)
+		return buf.Bytes(), nil
+	}
+
+	var parts bytes.Buffer
+	mixed := multipart.NewWriter(&parts)
+	part, err := mixed.CreatePart(bodyHeader)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := part.Write(body); err != nil {
+		return nil, err
+	}
+	for _, attachment := range m.Attachments {
+		if err := writeAttachment(mixed, attachment); err != nil {
+			return nil, err
+		}
+	}
+	if err := mixed.Close(); err != nil {
+		return nil, err
+	}
+	header.Set("Content-Type", "multipart/mixed; boundary="+mixed.Boundary())
+	writeHeader(&buf, header)
+	buf.Write(parts.Bytes())
+	return buf.Bytes(), nil
+}
+
+// body returns the content headers and the encoded text and/or HTML body
+func (m *Message) body() (textproto.MIMEHeader, []byte, error) {
+	var buf bytes.Buffer
+	if m.Text == "" || m.HTML == "" {
+		contentType, body := "text/plain; charset=utf-8", m.Text
+		if m.HTML != "" {
+			contentType, body = "text/html; charset=utf-8", m.HTML
+		}
+		if err := writeQuotedPrintable(&buf, body); err != nil {
+			return nil, nil, err
+		}
+		return textproto.MIMEHeader{
+			"Content-Type":              {contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		}, buf.Bytes(), nil
+	}
+
+	alternative := multipart.NewWriter(&buf)
+	for _, part := range []struct{ contentType, body string }{
+		{"text/plain; charset=utf-8", m.Text},
+		{"text/html; charset=utf-8", m.HTML},
+	} {
+		pw, err := alternative.CreatePart(textproto.MIMEHeader{
+			"Content-Type":              {part.contentType},
+			"Content-Transfer-Encoding": {"quoted-printable"},
+		})
+		if err != nil {
+			return nil, nil, err
+		}
+		if err := writeQuotedPrintable(pw, part.body); err != nil {
+			return nil, nil, err
+		}
+	}
+	if err := alternative.Close(); err != nil {
+		return nil, nil, err
+	}
+	return textproto.MIMEHeader{
+		"Content-Type": {"multipart/alternative; boundary=" + alternative.Boundary()},
+	}, buf.Bytes(), nil
+}
+
+// formatAddresses formats addresses for a header, encoding non-ASCII names
+func formatAddresses(addresses ...string) string {
+	formatted := make([]string, len(addresses))
+	for i, value := range addresses {
+		formatted[i] = value
+		if parsed, err := mail.ParseAddress(value); err == nil {
+			formatted[i] = parsed.String()
+		}
+	}
+	return strings.Join(formatted, ", ")
+}
+
+// writeHeader writes the header fields sorted by key, then the blank line
+func writeHeader(w io.Writer, header textproto.MIMEHeader) {
+	keys := make([]string, 0, len(header))
+	for key := range header {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		for _, value := range header[key] {
+			fmt.Fprintf(w, "%s: %s\r\n", key, value)
+		}
+	}
+	io.WriteString(w, "\r\n")
+}
+
+func writeQuotedPrintable(w io.Writer, body string) error {
+	qp := quotedprintable.NewWriter(w)
+	if _, err := io.WriteString(qp, body); err != nil {
+		return err
+	}
+	return qp.Close()
+}
+
+// writeAttachment writes a base64-encoded attachment part
+func writeAttachment(w *multipart.Writer, attachment Attachment) error {
+	contentType := attachment.ContentType
+	if contentType == "" {
+		contentType = mime.TypeByExtension(path.Ext(attachment.Filename))
+		if contentType == "" {
+			contentType = "application/octet-stream"
+		}
+	}
+	part, err := w.CreatePart(textproto.MIMEHeader{
+		"Content-Type":              {contentType},
+		"Content-Transfer-Encoding": {"base64"},
+		"Content-Disposition":       {mime.FormatMediaType("attachment", map[string]string{"filename": attachment.Filename})},
+	})
+	if err != nil {
+		return err
+	}
+
+	// Lines of base64 are limited to 76 characters
+	encoded := base64.StdEncoding.EncodeToString(attachment.Data)
+	for len(encoded) > 76 {
+		if _, err := io.WriteString(part, encoded[:76]+"\r\n"); err != nil {
+			return err
+		}
+		encoded = encoded[76:]
+	}
+	_, err = io.WriteString(part, encoded+"\r\n")
+	return err
+}
+
+// messageID returns a unique Message-ID in the domain of the sender
+func messageID(from string) string {
+	domain := "localhost"
+	if address, err := mail.ParseAddress(from); err == nil {
+		if _, host, ok := strings.Cut(address.Address, "@"); ok {
+			domain = host
+		}
+	}
+	id := make([]byte, 16)
+	_, _ = rand.Read(id)
+	return "<" + hex.EncodeToString(id) + "@" + domain + ">"
+}
@@ -0,0 +1,93 @@
+package mail
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/resilience"
+)
+
+// PropertyMail holds the mail configuration: mail.*
+const PropertyMail = "mail"
+
+// Transports selected by mail.transport
+const (
+	TransportSMTP    = "smtp"
+	TransportCapture = "capture"
+)
+
+// Component names
+const (
+	// MailerName is the Mailer component
+	MailerName = "mailer"
+	// CaptureName is the CaptureTransport component of the capture transport
+	CaptureName = "mailCapture"
+)
+
+// Config configures the mailer: mail.*
+type Config struct {
+	// From is the default sender, e.g. "Shop <noreply@shop.example>"
+	From string `yaml:"from"`
+	// Transport is smtp (default) or capture (default in the test profile)
+	Transport string     `yaml:"transport"`
+	SMTP      SMTPConfig `yaml:"smtp"`
+	// Retry configures the retries of transient failures (3 attempts by default)
+	Retry resilience.RetryConfig `yaml:"retry"`
+}
+
+// Starter registers the mailer component when mail.enabled is true. It sends
+// through the Transport registered in the setup block, if any, e.g. an email API
+// adapter; otherwise through the transport of mail.transport:
+//
+//	mail:
+//	  enabled: true
+//	  from: Shop <noreply@shop.example>
+//	  smtp:
+//	    host: smtp.example.com
+//	    username: shop
+//
+// The password can come from the environment variable MAIL_SMTP_PASSWORD. In the
+// test profile the default transport captures the messages in the
+// mailCapture component instead of sending them.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"MailStarter",
+		container.PropertyCondition(PropertyMail+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertyMail, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyMail, err)
+			}
+
+			var transport Transport
+			if err := builder.GetComponent(&transport); err != nil {
+				transport, err = newTransport(builder, config)
+				if err != nil {
+					return err
+				}
+			}
+			return builder.RegisterComponent(NewMailer(config.From, transport, config.Retry))
+		},
+	)
+}
+
+// newTransport creates the transport of mail.transport
+func newTransport(builder container.ContextBuilder, config Config) (Transport, error) {
+	name := config.Transport
+	if name == "" {
+		name = TransportSMTP
+		if container.ProfileCondition("test")(builder) {
+			name = TransportCapture
+		}
+	}
+
+	switch name {
+	case TransportSMTP:
+		return NewSMTPTransport(config.SMTP)
+	case TransportCapture:
+		capture := NewCaptureTransport()
+		return capture, builder.RegisterComponent(capture)
+	default:
+		return nil, fmt.Errorf("unsupported %s.transport %q", PropertyMail, name)
+	}
+}
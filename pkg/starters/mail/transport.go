@@ -0,0 +1,291 @@
+package mail
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"net"
+	"net/mail"
+	"net/smtp"
+	"net/textproto"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Transport delivers messages. Register an adapter for an email API (SES,
+// SendGrid, ...) in the setup block to use it instead of SMTP:
+//
+//	builder.RegisterInstance("mailTransport", &SendGridTransport{...})
+//
+// Errors that can't succeed on retry should wrap ErrPermanent.
+type Transport interface {
+	Send(ctx context.Context, message *Message) error
+}
+
+// ErrPermanent marks failures that are not retried, e.g. a rejected recipient
+var ErrPermanent = errors.New("permanent mail failure")
+
+// TLS modes of SMTP connections
+const (
+	// TLSStartTLS upgrades the connection with STARTTLS (port 587)
+	TLSStartTLS = "starttls"
+	// TLSImplicit connects with TLS (port 465)
+	TLSImplicit = "tls"
+	// TLSNone sends in plain text, e.g. to a local relay or a test server
+	TLSNone = "none"
+)
+
+// SMTPConfig configures the SMTP transport: mail.smtp.*
+type SMTPConfig struct {
+	Host string `yaml:"host"`
+	// Port defaults to 587, or 465 with implicit TLS
+	Port int `yaml:"port"`
+	// Username and Password authenticate with PLAIN when the username is set
+	Username string `yaml:"username"`
+	Password string `yaml:"password"`
+	// TLS is starttls (default), tls or none
+	TLS string `yaml:"tls"`
+	// Timeout bounds connecting and sending a message (default 30s)
+	Timeout time.Duration `yaml:"timeout"`
+	// LocalName is the host name sent with EHLO (default localhost)
+	LocalName string `yaml:"local-name"`
+}
+
+func (c SMTPConfig) withDefaults() SMTPConfig {
+	if c.TLS == "" {
+		c.TLS = TLSStartTLS
+	}
+	if c.Port == 0 {
+		c.Port = 587
+		if c.TLS == TLSImplicit {
+			c.Port = 465
+		}
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 30 * time.Second
+	}
+	if c.LocalName == "" {
+		c.LocalName = "localhost"
+	}
+	return c
+}
+
+// SMTPTransport sends each message over a new SMTP connection
+type SMTPTransport struct {
+	config SMTPConfig
+}
+
+// NewSMTPTransport creates an SMTP transport
+func NewSMTPTransport(config SMTPConfig) (*SMTPTransport, error) {
+	config = config.withDefaults()
+	if config.Host == "" {
+		return nil, fmt.Errorf("mail.smtp.host is required")
+	}
+	switch config.TLS {
+	case TLSStartTLS, TLSImplicit, TLSNone:
+	default:
+		return nil, fmt.Errorf("unsupported mail.smtp.tls %q", config.TLS)
+	}
+	return &SMTPTransport{config: config}, nil
+}
+
+// Send delivers a message to all its recipients
+func (t *SMTPTransport) Send(ctx context.Context, message *Message) error {
+	data, err := message.Bytes()
+	if err != nil {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+
+	client, err := t.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+
+	if err := client.Mail(address(message.From)); err != nil {
+		return smtpError(err)
+	}
+	for _, recipient := range message.Recipients() {
+		if err := client.Rcpt(address(recipient)); err != nil {
+			return smtpError(err)
+		}
+	}
+	w, err := client.Data()
+	if err != nil {
+		return smtpError(err)
+	}
+	if _, err := w.Write(data); err != nil {
+		return err
+	}
+	if err := w.Close(); err != nil {
+		return smtpError(err)
+	}
+	return client.Quit()
+}
+
+// WarmUp connects and authenticates, so that a wrong host or credentials fail the
+// startup
+func (t *SMTPTransport) WarmUp(ctx context.Context) error {
+	client, err := t.dial(ctx)
+	if err != nil {
+		return err
+	}
+	defer client.Close()
+	return client.Quit()
+}
+
+// dial connects, says EHLO, upgrades to TLS and authenticates. The connection
+// expires with the context or the timeout.
+func (t *SMTPTransport) dial(ctx context.Context) (*smtp.Client, error) {
+	deadline := time.Now().Add(t.config.Timeout)
+	if ctxDeadline, ok := ctx.Deadline(); ok && ctxDeadline.Before(deadline) {
+		deadline = ctxDeadline
+	}
+	addr := net.JoinHostPort(t.config.Host, strconv.Itoa(t.config.Port))
+	tlsConfig := &tls.Config{ServerName: t.config.Host}
+
+	dialer := &net.Dialer{Deadline: deadline}
+	var conn net.Conn
+	var err error
+	if t.config.TLS == TLSImplicit {
+		conn, err = (&tls.Dialer{NetDialer: dialer, Config: tlsConfig}).DialContext(ctx, "tcp", addr)
+	} else {
+		conn, err = dialer.DialContext(ctx, "tcp", addr)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	_ = conn.SetDeadline(deadline)
+
+	client, err := smtp.NewClient(conn, t.config.Host)
+	if err != nil {
+		conn.Close()
+		return nil, fmt.Errorf("connect to %s: %w", addr, err)
+	}
+	if err := t.handshake(client, tlsConfig); err != nil {
+		client.Close()
+		return nil, err
+	}
+	return client, nil
+}
+
+func (t *SMTPTransport) handshake(client *smtp.Client, tlsConfig *tls.Config) error {
+	if err := client.Hello(t.config.LocalName); err != nil {
+		return smtpError(err)
+	}
+	if t.config.TLS == TLSStartTLS {
+		if ok, _ := client.Extension("STARTTLS"); !ok {
+			return fmt.Errorf("%w: %s doesn't support STARTTLS", ErrPermanent, t.config.Host)
+		}
+		if err := client.StartTLS(tlsConfig); err != nil {
+			return err
+		}
+	}
+	if t.config.Username != "" {
+		// PlainAuth refuses to send the password without TLS, except to localhost
+		auth := smtp.PlainAuth("", t.config.Username, t.config.Password, t.config.Host)
+		if err := client.Auth(auth); err != nil {
+			return smtpError(err)
+		}
+	}
+	return nil
+}
+
+// smtpError marks 5xx replies as permanent; 4xx replies and network errors are
+// transient
+func smtpError(err error) error {
+	var reply *textproto.Error
+	if errors.As(err, &reply) && reply.Code >= 500 {
+		return fmt.Errorf("%w: %v", ErrPermanent, err)
+	}
+	return err
+}
+
+// address returns the address of "Name <address>"
+func address(value string) string {
+	if parsed, err := mail.ParseAddress(value); err == nil {
+		return parsed.Address
+	}
+	return value
+}
+
+// CaptureTransport keeps the messages instead of sending them. It is the default
+// transport of the test profile, so that tests assert on the sent emails:
+//
+//	capture, _ := container.GetComponentAs[*mail.CaptureTransport](app, mail.CaptureName)
+//	sent := capture.SentTo("alice@example.com")
+type CaptureTransport struct {
+	mu       sync.Mutex
+	messages []Message
+}
+
+// NewCaptureTransport creates a capturing transport
+func NewCaptureTransport() *CaptureTransport {
+	return &CaptureTransport{}
+}
+
+// Name returns the component name
+func (t *CaptureTransport) Name() string {
+	return CaptureName
+}
+
+// Init is a no-op
+func (t *CaptureTransport) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Send records a copy of the message
+func (t *CaptureTransport) Send(_ context.Context, message *Message) error {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = append(t.messages, *message)
+	return nil
+}
+
+// Messages returns the captured messages in the order they were sent
+func (t *CaptureTransport) Messages() []Message {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return append([]Message(nil), t.messages...)
+}
+
+// Last returns the last captured message
+func (t *CaptureTransport) Last() (Message, bool) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if len(t.messages) == 0 {
+		return Message{}, false
+	}
+	return t.messages[len(t.messages)-1], true
+}
+
+// SentTo returns the captured messages with the address among their recipients
+func (t *CaptureTransport) SentTo(recipient string) []Message {
+	var sent []Message
+	for _, message := range t.Messages() {
+		for _, candidate := range message.Recipients() {
+			if address(candidate) == address(recipient) {
+				sent = append(sent, message)
+				break
+			}
+		}
+	}
+	return sent
+}
+
+// Reset forgets the captured messages
+func (t *CaptureTransport) Reset() {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.messages = nil
+}
+
+// Ensure that the transports implement Transport
+var (
+	_ Transport = (*SMTPTransport)(nil)
+	_ Transport = (*CaptureTransport)(nil)
+)
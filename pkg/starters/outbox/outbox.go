@@ -0,0 +1,126 @@
+// Package outbox implements the transactional outbox pattern: events are written
+// to an outbox table in the same database transaction as the business change and
+// relayed to a message broker by a background component, giving at-least-once delivery
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/internal/sqldialect"
+)
+
+// Supported SQL dialects
+const (
+	DialectPostgres = sqldialect.Postgres
+	DialectMySQL    = sqldialect.MySQL
+	DialectSQLite   = sqldialect.SQLite
+)
+
+// Event is a message stored in the outbox
+type Event struct {
+	// ID is assigned by the database when the event is stored
+	ID        int64
+	Topic     string
+	Key       string
+	Payload   []byte
+	Headers   map[string]string
+	CreatedAt time.Time
+	// Attempts is the number of failed deliveries so far
+	Attempts int
+}
+
+// Sender delivers relayed events to a broker (Kafka, NATS, ...). Send must be
+// idempotent-friendly: an event may be delivered more than once if the relay
+// crashes between sending and marking it sent. A Sender component implementing
+// container.WarmUpComponent is checked by the eager startup checks, e.g. by
+// fetching the metadata of its topics.
+type Sender interface {
+	Send(ctx context.Context, event Event) error
+}
+
+// Execer is satisfied by *sql.Tx, *sql.DB and *sql.Conn
+type Execer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+}
+
+// Publisher writes events to the outbox table
+type Publisher struct {
+	table   string
+	dialect string
+	now     func() time.Time
+}
+
+// NewPublisher creates a publisher writing to table using the dialect's placeholders
+func NewPublisher(table, dialect string) *Publisher {
+	return &Publisher{table: table, dialect: dialect, now: time.Now}
+}
+
+// Name returns the component name
+func (p *Publisher) Name() string {
+	return "outboxPublisher"
+}
+
+// Init is a no-op
+func (p *Publisher) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Publish stores an event using tx. Pass the transaction of the business change
+// so that the event is only relayed if the transaction commits.
+func (p *Publisher) Publish(ctx context.Context, tx Execer, topic, key string, payload []byte, headers map[string]string) error {
+	headerJSON, err := json.Marshal(headers)
+	if err != nil {
+		return fmt.Errorf("encode outbox headers: %w", err)
+	}
+
+	now := p.now().UTC()
+	query := fmt.Sprintf(
+		"INSERT INTO %s (topic, message_key, payload, headers, created_at, attempts, next_attempt_at) VALUES (%s)",
+		p.table, sqldialect.Placeholders(p.dialect, 1, 7))
+	if _, err := tx.ExecContext(ctx, query, topic, key, payload, string(headerJSON), now, 0, now); err != nil {
+		return fmt.Errorf("insert outbox event: %w", err)
+	}
+	return nil
+}
+
+// PublishJSON stores an event whose payload is value encoded as JSON
+func (p *Publisher) PublishJSON(ctx context.Context, tx Execer, topic, key string, value any) error {
+	payload, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Errorf("encode outbox payload: %w", err)
+	}
+	return p.Publish(ctx, tx, topic, key, payload, nil)
+}
+
+// Schema returns the DDL creating the outbox table for a dialect
+func Schema(table, dialect string) (string, error) {
+	var id, payload, timestamp string
+	switch dialect {
+	case DialectPostgres:
+		id, payload, timestamp = "BIGSERIAL PRIMARY KEY", "BYTEA", "TIMESTAMPTZ"
+	case DialectMySQL:
+		id, payload, timestamp = "BIGINT AUTO_INCREMENT PRIMARY KEY", "LONGBLOB", "DATETIME(6)"
+	case DialectSQLite:
+		id, payload, timestamp = "INTEGER PRIMARY KEY AUTOINCREMENT", "BLOB", "TIMESTAMP"
+	default:
+		return "", fmt.Errorf("unsupported outbox dialect %q", dialect)
+	}
+
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	id %s,
+	topic VARCHAR(255) NOT NULL,
+	message_key VARCHAR(255) NOT NULL,
+	payload %s NOT NULL,
+	headers TEXT NOT NULL,
+	created_at %s NOT NULL,
+	attempts INTEGER NOT NULL,
+	next_attempt_at %s NOT NULL,
+	last_error TEXT,
+	sent_at %s
+)`, table, id, payload, timestamp, timestamp, timestamp), nil
+}
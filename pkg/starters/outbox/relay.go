@@ -0,0 +1,245 @@
+package outbox
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/internal/sqldialect"
+)
+
+// RelayConfig configures the relay: outbox.*
+type RelayConfig struct {
+	Table   string `yaml:"table"`
+	Dialect string `yaml:"dialect"`
+	// CreateTable creates the outbox table on startup if it doesn't exist
+	CreateTable bool `yaml:"create-table"`
+	// PollInterval is the delay between polls when the outbox is drained
+	PollInterval time.Duration `yaml:"poll-interval"`
+	// BatchSize is the number of events relayed per poll
+	BatchSize int `yaml:"batch-size"`
+	// MaxAttempts stops retrying an event after that many failures (0 retries forever)
+	MaxAttempts int `yaml:"max-attempts"`
+	// InitialBackoff and MaxBackoff bound the exponential delay between retries
+	InitialBackoff time.Duration `yaml:"initial-backoff"`
+	MaxBackoff     time.Duration `yaml:"max-backoff"`
+	// Retention deletes sent events older than this (0 keeps them)
+	Retention time.Duration `yaml:"retention"`
+}
+
+func (c RelayConfig) withDefaults() RelayConfig {
+	if c.Table == "" {
+		c.Table = "outbox"
+	}
+	if c.Dialect == "" {
+		c.Dialect = DialectPostgres
+	}
+	if c.PollInterval <= 0 {
+		c.PollInterval = time.Second
+	}
+	if c.BatchSize <= 0 {
+		c.BatchSize = 100
+	}
+	if c.InitialBackoff <= 0 {
+		c.InitialBackoff = time.Second
+	}
+	if c.MaxBackoff <= 0 {
+		c.MaxBackoff = 5 * time.Minute
+	}
+	return c
+}
+
+// RelayMetrics is a snapshot of relay counters
+type RelayMetrics struct {
+	Sent   int64
+	Failed int64
+}
+
+// Relay is a background component moving events from the outbox table to the Sender
+type Relay struct {
+	db     *sql.DB
+	sender Sender
+	config RelayConfig
+	logger *slog.Logger
+	now    func() time.Time
+
+	sent   atomic.Int64
+	failed atomic.Int64
+}
+
+// NewRelay creates a relay reading from db and delivering to sender
+func NewRelay(db *sql.DB, sender Sender, config RelayConfig) *Relay {
+	return &Relay{
+		db:     db,
+		sender: sender,
+		config: config.withDefaults(),
+		logger: slog.Default(),
+		now:    time.Now,
+	}
+}
+
+// Name returns the component name
+func (r *Relay) Name() string {
+	return "outboxRelay"
+}
+
+// Init creates the outbox table if configured
+func (r *Relay) Init(container.ApplicationContext) error {
+	if !r.config.CreateTable {
+		return nil
+	}
+	ddl, err := Schema(r.config.Table, r.config.Dialect)
+	if err != nil {
+		return err
+	}
+	if _, err := r.db.Exec(ddl); err != nil {
+		return fmt.Errorf("create outbox table: %w", err)
+	}
+	return nil
+}
+
+// Start is a no-op; relaying happens in Run
+func (r *Relay) Start(context.Context) {}
+
+// Stop is a no-op; Run returns when the container context is cancelled
+func (r *Relay) Stop(context.Context) {}
+
+// Run relays events until ctx is cancelled
+func (r *Relay) Run(ctx context.Context) {
+	for {
+		relayed, err := r.RelayBatch(ctx)
+		if err != nil && ctx.Err() == nil {
+			r.logger.Error("Outbox relay failed", "error", err)
+		}
+
+		// Keep draining while full batches are found
+		if err == nil && relayed == r.config.BatchSize {
+			continue
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(r.config.PollInterval):
+		}
+	}
+}
+
+// RelayBatch sends one batch of due events and returns how many were processed
+func (r *Relay) RelayBatch(ctx context.Context) (int, error) {
+	events, err := r.dueEvents(ctx)
+	if err != nil {
+		return 0, err
+	}
+
+	for _, event := range events {
+		if err := r.sender.Send(ctx, event); err != nil {
+			r.failed.Add(1)
+			r.logger.Warn("Outbox event delivery failed",
+				"id", event.ID, "topic", event.Topic, "attempt", event.Attempts+1, "error", err)
+			if err := r.markFailed(ctx, event, err); err != nil {
+				return 0, err
+			}
+			continue
+		}
+
+		r.sent.Add(1)
+		if err := r.markSent(ctx, event); err != nil {
+			return 0, err
+		}
+	}
+
+	if r.config.Retention > 0 {
+		if err := r.purge(ctx); err != nil {
+			return 0, err
+		}
+	}
+
+	return len(events), nil
+}
+
+// Metrics returns a snapshot of the relay counters
+func (r *Relay) Metrics() RelayMetrics {
+	return RelayMetrics{Sent: r.sent.Load(), Failed: r.failed.Load()}
+}
+
+func (r *Relay) dueEvents(ctx context.Context) ([]Event, error) {
+	d := r.config.Dialect
+	query := fmt.Sprintf(
+		"SELECT id, topic, message_key, payload, headers, created_at, attempts FROM %s WHERE sent_at IS NULL AND next_attempt_at <= %s",
+		r.config.Table, sqldialect.Placeholder(d, 1))
+	args := []any{r.now().UTC()}
+	if r.config.MaxAttempts > 0 {
+		query += " AND attempts < " + sqldialect.Placeholder(d, 2)
+		args = append(args, r.config.MaxAttempts)
+	}
+	query += fmt.Sprintf(" ORDER BY id LIMIT %d", r.config.BatchSize)
+
+	rows, err := r.db.QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, fmt.Errorf("query outbox: %w", err)
+	}
+	defer rows.Close()
+
+	var events []Event
+	for rows.Next() {
+		var event Event
+		var headers string
+		if err := rows.Scan(&event.ID, &event.Topic, &event.Key, &event.Payload, &headers, &event.CreatedAt, &event.Attempts); err != nil {
+			return nil, fmt.Errorf("scan outbox event: %w", err)
+		}
+		if err := json.Unmarshal([]byte(headers), &event.Headers); err != nil {
+			return nil, fmt.Errorf("decode headers of outbox event %d: %w", event.ID, err)
+		}
+		events = append(events, event)
+	}
+	return events, rows.Err()
+}
+
+func (r *Relay) markSent(ctx context.Context, event Event) error {
+	d := r.config.Dialect
+	query := fmt.Sprintf("UPDATE %s SET sent_at = %s WHERE id = %s", r.config.Table, sqldialect.Placeholder(d, 1), sqldialect.Placeholder(d, 2))
+	if _, err := r.db.ExecContext(ctx, query, r.now().UTC(), event.ID); err != nil {
+		return fmt.Errorf("mark outbox event %d sent: %w", event.ID, err)
+	}
+	return nil
+}
+
+func (r *Relay) markFailed(ctx context.Context, event Event, sendErr error) error {
+	d := r.config.Dialect
+	next := r.now().UTC().Add(r.backoff(event.Attempts))
+	query := fmt.Sprintf("UPDATE %s SET attempts = attempts + 1, next_attempt_at = %s, last_error = %s WHERE id = %s",
+		r.config.Table, sqldialect.Placeholder(d, 1), sqldialect.Placeholder(d, 2), sqldialect.Placeholder(d, 3))
+	if _, err := r.db.ExecContext(ctx, query, next, sendErr.Error(), event.ID); err != nil {
+		return fmt.Errorf("mark outbox event %d failed: %w", event.ID, err)
+	}
+	return nil
+}
+
+func (r *Relay) purge(ctx context.Context) error {
+	query := fmt.Sprintf("DELETE FROM %s WHERE sent_at IS NOT NULL AND sent_at < %s", r.config.Table, sqldialect.Placeholder(r.config.Dialect, 1))
+	if _, err := r.db.ExecContext(ctx, query, r.now().UTC().Add(-r.config.Retention)); err != nil {
+		return fmt.Errorf("purge outbox: %w", err)
+	}
+	return nil
+}
+
+// backoff doubles the initial backoff per previous failure, capped at MaxBackoff
+func (r *Relay) backoff(attempts int) time.Duration {
+	delay := r.config.InitialBackoff
+	for i := 0; i < attempts && delay < r.config.MaxBackoff; i++ {
+		delay *= 2
+	}
+	if delay > r.config.MaxBackoff {
+		delay = r.config.MaxBackoff
+	}
+	return delay
+}
+
+// Ensure that Relay implements container.BackgroundComponent
+var _ container.BackgroundComponent = (*Relay)(nil)
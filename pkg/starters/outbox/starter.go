@@ -0,0 +1,45 @@
+package outbox
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyOutbox holds the relay configuration: outbox.*
+const PropertyOutbox = "outbox"
+
+// Starter registers a Publisher and a Relay when outbox.enabled is true. It
+// needs a *sql.DB instance and a Sender component (e.g. a Kafka or NATS producer
+// adapter) registered in the setup block:
+//
+//	builder.RegisterInstance("db", db)
+//	builder.RegisterComponent(&KafkaSender{...})
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"OutboxStarter",
+		container.PropertyCondition(PropertyOutbox+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config RelayConfig
+			if err := builder.GetVariableAs(PropertyOutbox, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyOutbox, err)
+			}
+			config = config.withDefaults()
+
+			var db *sql.DB
+			if err := builder.GetComponent(&db); err != nil {
+				return fmt.Errorf("outbox requires a *sql.DB: %w", err)
+			}
+			var sender Sender
+			if err := builder.GetComponent(&sender); err != nil {
+				return fmt.Errorf("outbox requires a Sender: %w", err)
+			}
+
+			if err := builder.RegisterComponent(NewPublisher(config.Table, config.Dialect)); err != nil {
+				return err
+			}
+			return builder.RegisterComponent(NewRelay(db, sender, config))
+		},
+	)
+}
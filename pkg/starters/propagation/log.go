@@ -0,0 +1,96 @@
+package propagation
+
+import (
+	"context"
+	"log/slog"
+	"sort"
+)
+
+// Log attribute keys
+const (
+	LogKeyCorrelationID = "correlation_id"
+	LogKeyTenantID      = "tenant_id"
+	LogKeyBaggage       = "baggage"
+	LogKeyTraceID       = "trace_id"
+	LogKeySpanID        = "span_id"
+)
+
+// LogAttrsFunc returns attributes of a context to add to log records, e.g. the IDs
+// of a tracing library's span
+type LogAttrsFunc func(ctx context.Context) []slog.Attr
+
+// LogOption configures the log handler
+type LogOption func(*logHandler)
+
+// WithLogAttrs adds the attributes returned by fn to the records logged with a
+// context
+func WithLogAttrs(fn LogAttrsFunc) LogOption {
+	return func(h *logHandler) {
+		h.extractors = append(h.extractors, fn)
+	}
+}
+
+// logHandler adds the propagated values of the record's context to log records
+type logHandler struct {
+	next       slog.Handler
+	extractors []LogAttrsFunc
+}
+
+// NewLogHandler wraps next so that records logged with a context (InfoContext,
+// ErrorContext, ...) carry its correlation ID, tenant ID, trace and span IDs and
+// baggage, so that the logs of all the components serving one request line up.
+// The web and gRPC servers and the event bus put these values into the contexts
+// they pass on.
+func NewLogHandler(next slog.Handler, options ...LogOption) slog.Handler {
+	h := &logHandler{next: next}
+	for _, option := range options {
+		option(h)
+	}
+	return h
+}
+
+func (h *logHandler) Enabled(ctx context.Context, level slog.Level) bool {
+	return h.next.Enabled(ctx, level)
+}
+
+func (h *logHandler) Handle(ctx context.Context, record slog.Record) error {
+	if ctx != nil {
+		values := FromContext(ctx)
+		if values.CorrelationID != "" {
+			record.AddAttrs(slog.String(LogKeyCorrelationID, values.CorrelationID))
+		}
+		if values.TenantID != "" {
+			record.AddAttrs(slog.String(LogKeyTenantID, values.TenantID))
+		}
+		if values.TraceID != "" {
+			record.AddAttrs(slog.String(LogKeyTraceID, values.TraceID))
+			if values.SpanID != "" {
+				record.AddAttrs(slog.String(LogKeySpanID, values.SpanID))
+			}
+		}
+		if len(values.Baggage) > 0 {
+			keys := make([]string, 0, len(values.Baggage))
+			for key := range values.Baggage {
+				keys = append(keys, key)
+			}
+			sort.Strings(keys)
+			attrs := make([]any, 0, len(keys))
+			for _, key := range keys {
+				attrs = append(attrs, slog.String(key, values.Baggage[key]))
+			}
+			record.AddAttrs(slog.Group(LogKeyBaggage, attrs...))
+		}
+		for _, extract := range h.extractors {
+			record.AddAttrs(extract(ctx)...)
+		}
+	}
+	return h.next.Handle(ctx, record)
+}
+
+func (h *logHandler) WithAttrs(attrs []slog.Attr) slog.Handler {
+	return &logHandler{next: h.next.WithAttrs(attrs), extractors: h.extractors}
+}
+
+func (h *logHandler) WithGroup(name string) slog.Handler {
+	return &logHandler{next: h.next.WithGroup(name), extractors: h.extractors}
+}
@@ -0,0 +1,219 @@
+// Package propagation carries a correlation ID, a tenant ID, the W3C trace
+// context and baggage through context.Context and across process boundaries. The
+// Propagator extracts them from incoming HTTP requests (web starter), gRPC calls
+// (grpc-server starter) and published events (events starter) and injects them
+// into outgoing requests (http-client starter). To add them to every log record
+// written with a context, wrap the log handler (the logging starter does it):
+//
+//	slog.SetDefault(slog.New(propagation.NewLogHandler(slog.NewJSONHandler(os.Stdout, nil))))
+//	logger.InfoContext(ctx, "Order placed") // ... correlation_id=3f2a... tenant_id=acme trace_id=4bf9...
+package propagation
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"net/url"
+	"sort"
+	"strings"
+)
+
+// Values are the values propagated with a context
+type Values struct {
+	CorrelationID string
+	TenantID      string
+	// Baggage holds application-defined entries, e.g. the user or the client version
+	Baggage map[string]string
+	// TraceID and SpanID identify the trace and the calling span of a W3C
+	// traceparent header, as hex strings
+	TraceID string
+	SpanID  string
+}
+
+// empty reports whether no value is set
+func (v Values) empty() bool {
+	return v.CorrelationID == "" && v.TenantID == "" && len(v.Baggage) == 0 && v.TraceID == ""
+}
+
+// valuesKey is the context key of the propagated values
+type valuesKey struct{}
+
+// FromContext returns the values propagated with ctx
+func FromContext(ctx context.Context) Values {
+	values, _ := ctx.Value(valuesKey{}).(Values)
+	return values
+}
+
+// NewContext returns a context propagating values
+func NewContext(ctx context.Context, values Values) context.Context {
+	return context.WithValue(ctx, valuesKey{}, values)
+}
+
+// CorrelationID returns the correlation ID of ctx, empty if none
+func CorrelationID(ctx context.Context) string {
+	return FromContext(ctx).CorrelationID
+}
+
+// TenantID returns the tenant ID of ctx, empty if none
+func TenantID(ctx context.Context) string {
+	return FromContext(ctx).TenantID
+}
+
+// BaggageValue returns a baggage entry of ctx
+func BaggageValue(ctx context.Context, key string) string {
+	return FromContext(ctx).Baggage[key]
+}
+
+// WithCorrelationID returns a context with the correlation ID
+func WithCorrelationID(ctx context.Context, id string) context.Context {
+	values := FromContext(ctx)
+	values.CorrelationID = id
+	return NewContext(ctx, values)
+}
+
+// WithTenantID returns a context with the tenant ID
+func WithTenantID(ctx context.Context, id string) context.Context {
+	values := FromContext(ctx)
+	values.TenantID = id
+	return NewContext(ctx, values)
+}
+
+// TraceID returns the trace ID of ctx, empty if none
+func TraceID(ctx context.Context) string {
+	return FromContext(ctx).TraceID
+}
+
+// SpanID returns the span ID of ctx, empty if none
+func SpanID(ctx context.Context) string {
+	return FromContext(ctx).SpanID
+}
+
+// WithTrace returns a context with the trace and span IDs, e.g. of the span a
+// tracing middleware started, so that logs and outgoing requests carry them
+func WithTrace(ctx context.Context, traceID, spanID string) context.Context {
+	values := FromContext(ctx)
+	values.TraceID, values.SpanID = traceID, spanID
+	return NewContext(ctx, values)
+}
+
+// WithBaggage returns a context with a baggage entry added
+func WithBaggage(ctx context.Context, key, value string) context.Context {
+	values := FromContext(ctx)
+	baggage := make(map[string]string, len(values.Baggage)+1)
+	for k, v := range values.Baggage {
+		baggage[k] = v
+	}
+	baggage[key] = value
+	values.Baggage = baggage
+	return NewContext(ctx, values)
+}
+
+// NewCorrelationID returns a random correlation ID of 32 hex characters
+func NewCorrelationID() string {
+	var id [16]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id[:])
+}
+
+// Carrier reads and writes propagation headers; http.Header implements it
+type Carrier interface {
+	Get(key string) string
+	Set(key, value string)
+}
+
+// MapCarrier adapts string headers, such as the headers of a message, to a Carrier
+type MapCarrier map[string]string
+
+// Get returns a header
+func (c MapCarrier) Get(key string) string {
+	return c[key]
+}
+
+// Set sets a header
+func (c MapCarrier) Set(key, value string) {
+	c[key] = value
+}
+
+// encodeBaggage formats baggage as a W3C baggage header: key1=value1,key2=value2
+func encodeBaggage(baggage map[string]string) string {
+	keys := make([]string, 0, len(baggage))
+	for key := range baggage {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	entries := make([]string, 0, len(keys))
+	for _, key := range keys {
+		entries = append(entries, url.PathEscape(key)+"="+url.PathEscape(baggage[key]))
+	}
+	return strings.Join(entries, ",")
+}
+
+// decodeBaggage parses a W3C baggage header, ignoring entry properties and
+// malformed entries
+func decodeBaggage(header string) map[string]string {
+	if header == "" {
+		return nil
+	}
+	baggage := make(map[string]string)
+	for _, entry := range strings.Split(header, ",") {
+		entry, _, _ = strings.Cut(entry, ";")
+		key, value, ok := strings.Cut(strings.TrimSpace(entry), "=")
+		if !ok {
+			continue
+		}
+		key, err := url.PathUnescape(strings.TrimSpace(key))
+		if err != nil || key == "" {
+			continue
+		}
+		value, err = url.PathUnescape(strings.TrimSpace(value))
+		if err != nil {
+			continue
+		}
+		baggage[key] = value
+	}
+	return baggage
+}
+
+// parseTraceParent returns the trace and parent span IDs of a W3C traceparent
+// header: 00-<32 hex trace ID>-<16 hex span ID>-<2 hex flags>
+func parseTraceParent(header string) (traceID, spanID string, ok bool) {
+	parts := strings.Split(strings.TrimSpace(header), "-")
+	if len(parts) < 4 || len(parts[0]) != 2 || parts[0] == "ff" {
+		return "", "", false
+	}
+	traceID, spanID = parts[1], parts[2]
+	if !isHexID(traceID, 32) || !isHexID(spanID, 16) {
+		return "", "", false
+	}
+	return traceID, spanID, true
+}
+
+// formatTraceParent returns a sampled W3C traceparent header
+func formatTraceParent(traceID, spanID string) string {
+	return "00-" + traceID + "-" + spanID + "-01"
+}
+
+// isHexID reports whether id has n lowercase hex digits and isn't all zeros
+func isHexID(id string, n int) bool {
+	if len(id) != n || strings.Trim(id, "0") == "" {
+		return false
+	}
+	for _, c := range id {
+		if !('0' <= c && c <= '9' || 'a' <= c && c <= 'f') {
+			return false
+		}
+	}
+	return true
+}
+
+// newSpanID returns a random span ID of 16 hex characters
+func newSpanID() string {
+	var id [8]byte
+	if _, err := rand.Read(id[:]); err != nil {
+		panic(err)
+	}
+	return hex.EncodeToString(id[:])
+}
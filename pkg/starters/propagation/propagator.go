@@ -0,0 +1,200 @@
+package propagation
+
+import (
+	"context"
+	"net/http"
+	"sort"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// ContextEnricher is implemented by components adding values to the context of
+// incoming requests and events after the propagated values were extracted, e.g.
+// the tenant or the user of an authenticated request as baggage
+type ContextEnricher interface {
+	EnrichContext(ctx context.Context) context.Context
+}
+
+// Config configures the propagation headers: propagation.*
+type Config struct {
+	// CorrelationHeader carries the correlation ID (default X-Correlation-ID)
+	CorrelationHeader string `yaml:"correlation-header"`
+	// TenantHeader carries the tenant ID (default X-Tenant-ID)
+	TenantHeader string `yaml:"tenant-header"`
+	// BaggageHeader carries the baggage in the W3C format (default baggage)
+	BaggageHeader string `yaml:"baggage-header"`
+	// TraceHeader carries the trace context in the W3C format (default traceparent)
+	TraceHeader string `yaml:"trace-header"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.CorrelationHeader == "" {
+		c.CorrelationHeader = "X-Correlation-ID"
+	}
+	if c.TenantHeader == "" {
+		c.TenantHeader = "X-Tenant-ID"
+	}
+	if c.BaggageHeader == "" {
+		c.BaggageHeader = "baggage"
+	}
+	if c.TraceHeader == "" {
+		c.TraceHeader = "traceparent"
+	}
+	return c
+}
+
+// Propagator extracts the propagated values from carriers into contexts and
+// injects them back. Incoming requests and events without a correlation ID get a
+// new one, so that everything they cause shares it.
+type Propagator struct {
+	config    Config
+	enrichers []ContextEnricher
+}
+
+// NewPropagator creates a propagator using the configured headers
+func NewPropagator(config Config) *Propagator {
+	return &Propagator{config: config.withDefaults()}
+}
+
+// Name returns the component name
+func (p *Propagator) Name() string {
+	return "propagator"
+}
+
+// Init collects the ContextEnricher components sorted by name
+func (p *Propagator) Init(ctx container.ApplicationContext) error {
+	names := ctx.GetComponentNames()
+	if finder, ok := ctx.(container.ComponentFinder); ok {
+		names = names[:0]
+		for _, info := range finder.FindComponents(func(info container.ComponentInfo) bool {
+			_, ok := info.Component.(ContextEnricher)
+			return ok
+		}) {
+			names = append(names, info.Name)
+		}
+	}
+	sort.Strings(names)
+
+	p.enrichers = nil
+	for _, name := range names {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if enricher, ok := comp.(ContextEnricher); ok {
+			p.enrichers = append(p.enrichers, enricher)
+		}
+	}
+	return nil
+}
+
+// Extract returns ctx with the values of the carrier, which take precedence over
+// those already in ctx, a generated correlation ID if there is none, and the
+// values added by the enrichers
+func (p *Propagator) Extract(ctx context.Context, carrier Carrier) context.Context {
+	values := FromContext(ctx)
+	if id := carrier.Get(p.config.CorrelationHeader); id != "" {
+		values.CorrelationID = id
+	}
+	if values.CorrelationID == "" {
+		values.CorrelationID = NewCorrelationID()
+	}
+	if id := carrier.Get(p.config.TenantHeader); id != "" {
+		values.TenantID = id
+	}
+	if baggage := decodeBaggage(carrier.Get(p.config.BaggageHeader)); len(baggage) > 0 {
+		merged := make(map[string]string, len(values.Baggage)+len(baggage))
+		for key, value := range values.Baggage {
+			merged[key] = value
+		}
+		for key, value := range baggage {
+			merged[key] = value
+		}
+		values.Baggage = merged
+	}
+	if traceID, spanID, ok := parseTraceParent(carrier.Get(p.config.TraceHeader)); ok {
+		values.TraceID, values.SpanID = traceID, spanID
+	}
+
+	ctx = NewContext(ctx, values)
+	for _, enricher := range p.enrichers {
+		ctx = enricher.EnrichContext(ctx)
+	}
+	return ctx
+}
+
+// Inject writes the values of ctx into the carrier
+func (p *Propagator) Inject(ctx context.Context, carrier Carrier) {
+	values := FromContext(ctx)
+	if values.CorrelationID != "" {
+		carrier.Set(p.config.CorrelationHeader, values.CorrelationID)
+	}
+	if values.TenantID != "" {
+		carrier.Set(p.config.TenantHeader, values.TenantID)
+	}
+	if len(values.Baggage) > 0 {
+		carrier.Set(p.config.BaggageHeader, encodeBaggage(values.Baggage))
+	}
+	if values.TraceID != "" {
+		spanID := values.SpanID
+		if spanID == "" {
+			spanID = newSpanID()
+		}
+		carrier.Set(p.config.TraceHeader, formatTraceParent(values.TraceID, spanID))
+	}
+}
+
+// Middleware extracts the values of incoming requests and returns the correlation
+// ID in the response headers
+func (p *Propagator) Middleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		ctx := p.Extract(r.Context(), r.Header)
+		w.Header().Set(p.CorrelationHeader(), CorrelationID(ctx))
+		next.ServeHTTP(w, r.WithContext(ctx))
+	})
+}
+
+// CorrelationHeader returns the header carrying the correlation ID, which servers
+// send back in their responses
+func (p *Propagator) CorrelationHeader() string {
+	return p.config.CorrelationHeader
+}
+
+// DecorateTransport injects the values of the request context into the headers
+// of outgoing requests; it implements httpclient.TransportDecorator
+func (p *Propagator) DecorateTransport(client string, next http.RoundTripper) http.RoundTripper {
+	return &propagatingTransport{propagator: p, next: next}
+}
+
+// EventContext prepares the context of a published event. A context without
+// correlation ID is treated as incoming: the values are extracted from events
+// implementing Carrier, or generated. The values are then injected into such events.
+func (p *Propagator) EventContext(ctx context.Context, event any) context.Context {
+	carrier, _ := event.(Carrier)
+	if CorrelationID(ctx) == "" {
+		if carrier != nil {
+			ctx = p.Extract(ctx, carrier)
+		} else {
+			ctx = p.Extract(ctx, MapCarrier(nil))
+		}
+	}
+	if carrier != nil {
+		p.Inject(ctx, carrier)
+	}
+	return ctx
+}
+
+// propagatingTransport injects the propagated values into outgoing requests
+type propagatingTransport struct {
+	propagator *Propagator
+	next       http.RoundTripper
+}
+
+func (t *propagatingTransport) RoundTrip(req *http.Request) (*http.Response, error) {
+	if FromContext(req.Context()).empty() {
+		return t.next.RoundTrip(req)
+	}
+	out := req.Clone(req.Context())
+	t.propagator.Inject(req.Context(), out.Header)
+	return t.next.RoundTrip(out)
+}
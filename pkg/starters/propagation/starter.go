@@ -0,0 +1,32 @@
+package propagation
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyPropagation holds the propagation configuration: propagation.*
+const PropertyPropagation = "propagation"
+
+// Starter registers the Propagator unless propagation.enabled is false. The web
+// starter then extracts the values of every request; the http-client starter
+// injects them into outgoing requests when this starter is registered before it;
+// the event bus passes them to listeners and into events implementing Carrier.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"PropagationStarter",
+		func(ctx container.ApplicationContext) bool {
+			return container.NewVariableHelper(ctx).GetBool(PropertyPropagation+".enabled", true)
+		},
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if container.NewVariableHelper(builder).HasSection(PropertyPropagation) {
+				if err := builder.GetVariableAs(PropertyPropagation, &config); err != nil {
+					return fmt.Errorf("invalid %s configuration: %w", PropertyPropagation, err)
+				}
+			}
+			return builder.RegisterComponent(NewPropagator(config))
+		},
+	)
+}
@@ -0,0 +1,276 @@
+package ratelimit
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// ErrLimitExceeded is returned by Wait when a call can't be admitted
+var ErrLimitExceeded = errors.New("rate limit exceeded")
+
+// Limiter limits the rate of calls
+type Limiter interface {
+	container.Component
+	// Allow reports whether a call may happen now, consuming capacity if so
+	Allow() bool
+	// Wait blocks until a call may happen, ctx is done, or the limit can't be met
+	Wait(ctx context.Context) error
+	// Metrics returns a snapshot of the limiter counters
+	Metrics() Metrics
+}
+
+// Metrics is a snapshot of limiter counters
+type Metrics struct {
+	Name string
+	// Allowed is the number of admitted calls
+	Allowed int64
+	// Throttled is the number of rejected calls
+	Throttled int64
+	// Delayed is the number of calls admitted after waiting
+	Delayed int64
+}
+
+// counters tracks limiter metrics
+type counters struct {
+	allowed   atomic.Int64
+	throttled atomic.Int64
+	delayed   atomic.Int64
+}
+
+func (c *counters) snapshot(name string) Metrics {
+	return Metrics{
+		Name:      name,
+		Allowed:   c.allowed.Load(),
+		Throttled: c.throttled.Load(),
+		Delayed:   c.delayed.Load(),
+	}
+}
+
+// sleep waits for d or until ctx is done
+func sleep(ctx context.Context, d time.Duration) error {
+	timer := time.NewTimer(d)
+	defer timer.Stop()
+
+	select {
+	case <-ctx.Done():
+		return ctx.Err()
+	case <-timer.C:
+		return nil
+	}
+}
+
+// TokenBucket admits bursts of up to Burst calls and refills at Rate tokens per second
+type TokenBucket struct {
+	name  string
+	rate  float64
+	burst float64
+	now   func() time.Time
+
+	mu     sync.Mutex
+	tokens float64
+	last   time.Time
+	counters
+}
+
+// NewTokenBucket creates a full token bucket
+func NewTokenBucket(name string, rate float64, burst int) *TokenBucket {
+	if burst < 1 {
+		burst = 1
+	}
+	return &TokenBucket{
+		name:   name,
+		rate:   rate,
+		burst:  float64(burst),
+		now:    time.Now,
+		tokens: float64(burst),
+		last:   time.Now(),
+	}
+}
+
+// Name returns the component name
+func (b *TokenBucket) Name() string {
+	return componentName(b.name)
+}
+
+// Init is a no-op: limiters are configured by the starter
+func (b *TokenBucket) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// refill adds the tokens earned since the last call; must be called with the lock held
+func (b *TokenBucket) refill() time.Time {
+	now := b.now()
+	b.tokens += now.Sub(b.last).Seconds() * b.rate
+	if b.tokens > b.burst {
+		b.tokens = b.burst
+	}
+	b.last = now
+	return now
+}
+
+// Allow consumes a token if one is available
+func (b *TokenBucket) Allow() bool {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	b.refill()
+	if b.tokens >= 1 {
+		b.tokens--
+		b.allowed.Add(1)
+		return true
+	}
+	b.throttled.Add(1)
+	return false
+}
+
+// Wait reserves a token and sleeps until it is available
+func (b *TokenBucket) Wait(ctx context.Context) error {
+	b.mu.Lock()
+	b.refill()
+	b.tokens--
+	deficit := -b.tokens
+	b.mu.Unlock()
+
+	if deficit <= 0 {
+		b.allowed.Add(1)
+		return nil
+	}
+	if b.rate <= 0 {
+		b.cancelReservation()
+		return ErrLimitExceeded
+	}
+
+	if err := sleep(ctx, time.Duration(deficit/b.rate*float64(time.Second))); err != nil {
+		b.cancelReservation()
+		return err
+	}
+	b.allowed.Add(1)
+	b.delayed.Add(1)
+	return nil
+}
+
+func (b *TokenBucket) cancelReservation() {
+	b.mu.Lock()
+	b.tokens++
+	b.mu.Unlock()
+	b.throttled.Add(1)
+}
+
+// Metrics returns a snapshot of the limiter counters
+func (b *TokenBucket) Metrics() Metrics {
+	return b.snapshot(b.name)
+}
+
+// LeakyBucket smooths calls to a constant Rate per second, queueing up to Capacity
+// calls in Wait and rejecting calls beyond that
+type LeakyBucket struct {
+	name     string
+	interval time.Duration
+	capacity int
+	now      func() time.Time
+
+	mu   sync.Mutex
+	next time.Time
+	counters
+}
+
+// NewLeakyBucket creates an empty leaky bucket
+func NewLeakyBucket(name string, rate float64, capacity int) *LeakyBucket {
+	if capacity < 0 {
+		capacity = 0
+	}
+	interval := time.Duration(0)
+	if rate > 0 {
+		interval = time.Duration(float64(time.Second) / rate)
+	}
+	return &LeakyBucket{
+		name:     name,
+		interval: interval,
+		capacity: capacity,
+		now:      time.Now,
+	}
+}
+
+// Name returns the component name
+func (b *LeakyBucket) Name() string {
+	return componentName(b.name)
+}
+
+// Init is a no-op: limiters are configured by the starter
+func (b *LeakyBucket) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// reserve returns the delay before the call's slot, or false if the queue is full
+func (b *LeakyBucket) reserve(allowWait bool) (time.Duration, bool) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	now := b.now()
+	slot := b.next
+	if slot.Before(now) {
+		slot = now
+	}
+
+	delay := slot.Sub(now)
+	if !allowWait && delay > 0 {
+		return 0, false
+	}
+	if b.interval > 0 && delay > time.Duration(b.capacity)*b.interval {
+		return 0, false
+	}
+
+	b.next = slot.Add(b.interval)
+	return delay, true
+}
+
+// Allow admits a call only if it doesn't need to queue
+func (b *LeakyBucket) Allow() bool {
+	if _, ok := b.reserve(false); !ok {
+		b.throttled.Add(1)
+		return false
+	}
+	b.allowed.Add(1)
+	return true
+}
+
+// Wait queues the call until its slot or rejects it if the queue is full.
+// A cancelled wait still consumes its slot.
+func (b *LeakyBucket) Wait(ctx context.Context) error {
+	delay, ok := b.reserve(true)
+	if !ok {
+		b.throttled.Add(1)
+		return ErrLimitExceeded
+	}
+
+	if delay > 0 {
+		if err := sleep(ctx, delay); err != nil {
+			b.throttled.Add(1)
+			return err
+		}
+		b.delayed.Add(1)
+	}
+	b.allowed.Add(1)
+	return nil
+}
+
+// Metrics returns a snapshot of the limiter counters
+func (b *LeakyBucket) Metrics() Metrics {
+	return b.snapshot(b.name)
+}
+
+// componentName returns the container name of a limiter
+func componentName(name string) string {
+	return "ratelimiter." + name
+}
+
+// Ensure that the limiters implement Limiter
+var (
+	_ Limiter = (*TokenBucket)(nil)
+	_ Limiter = (*LeakyBucket)(nil)
+)
@@ -0,0 +1,80 @@
+// Package ratelimit provides named token bucket and leaky bucket rate limiters
+package ratelimit
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyLimiters holds per-limiter configuration:
+// ratelimit.limiters.<name>.algorithm / rate / burst
+const PropertyLimiters = "ratelimit.limiters"
+
+// Supported algorithms
+const (
+	AlgorithmTokenBucket = "token-bucket"
+	AlgorithmLeakyBucket = "leaky-bucket"
+)
+
+// Config configures a rate limiter
+type Config struct {
+	// Algorithm is token-bucket (default) or leaky-bucket
+	Algorithm string `yaml:"algorithm"`
+	// Rate is the number of calls per second
+	Rate float64 `yaml:"rate"`
+	// Burst is the bucket size: the burst size for token buckets and the
+	// queue capacity for leaky buckets
+	Burst int `yaml:"burst"`
+}
+
+// New creates a limiter from configuration
+func New(name string, config Config) (Limiter, error) {
+	if config.Rate <= 0 {
+		return nil, fmt.Errorf("rate limiter %s: rate must be positive", name)
+	}
+
+	switch config.Algorithm {
+	case "", AlgorithmTokenBucket:
+		return NewTokenBucket(name, config.Rate, config.Burst), nil
+	case AlgorithmLeakyBucket:
+		return NewLeakyBucket(name, config.Rate, config.Burst), nil
+	default:
+		return nil, fmt.Errorf("rate limiter %s: unknown algorithm %q", name, config.Algorithm)
+	}
+}
+
+// Starter registers a Limiter component named "ratelimiter.<name>" for each
+// configured limiter. Inject one by type when only one is configured, or by name:
+//
+//	limiter, err := container.GetComponentAs[ratelimit.Limiter](ctx, "ratelimiter.api")
+func Starter() container.Starter {
+	return container.NewStarter("RateLimitStarter", func(builder container.ContextBuilder) error {
+		if !container.NewVariableHelper(builder).HasSection(PropertyLimiters) {
+			return nil
+		}
+
+		configs := map[string]Config{}
+		if err := builder.GetVariableAs(PropertyLimiters, &configs); err != nil {
+			return fmt.Errorf("invalid %s configuration: %w", PropertyLimiters, err)
+		}
+
+		names := make([]string, 0, len(configs))
+		for name := range configs {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+
+		for _, name := range names {
+			limiter, err := New(name, configs[name])
+			if err != nil {
+				return err
+			}
+			if err := builder.RegisterComponent(limiter); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+}
@@ -0,0 +1,284 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// ErrCircuitOpen is returned when a call is rejected by an open circuit breaker
+var ErrCircuitOpen = errors.New("circuit breaker is open")
+
+// errCallPanicked records a call that panicked as a failure
+var errCallPanicked = errors.New("call panicked")
+
+// State is the state of a circuit breaker
+type State int
+
+const (
+	// StateClosed lets all calls through
+	StateClosed State = iota
+	// StateOpen rejects all calls until the open timeout elapses
+	StateOpen
+	// StateHalfOpen lets a limited number of probe calls through
+	StateHalfOpen
+)
+
+// String returns the state name
+func (s State) String() string {
+	switch s {
+	case StateClosed:
+		return "closed"
+	case StateOpen:
+		return "open"
+	case StateHalfOpen:
+		return "half-open"
+	default:
+		return "unknown"
+	}
+}
+
+// BreakerConfig configures a circuit breaker
+type BreakerConfig struct {
+	// FailureThreshold is the number of consecutive failures that opens the circuit
+	FailureThreshold int `yaml:"failure-threshold"`
+	// OpenTimeout is how long the circuit stays open before allowing probes
+	OpenTimeout time.Duration `yaml:"open-timeout"`
+	// HalfOpenProbes is the number of successful probes needed to close the circuit
+	HalfOpenProbes int `yaml:"half-open-probes"`
+}
+
+// DefaultBreakerConfig returns the default circuit breaker configuration
+func DefaultBreakerConfig() BreakerConfig {
+	return BreakerConfig{
+		FailureThreshold: 5,
+		OpenTimeout:      30 * time.Second,
+		HalfOpenProbes:   1,
+	}
+}
+
+// StateChange describes a circuit breaker state transition
+type StateChange struct {
+	Breaker string
+	From    State
+	To      State
+	At      time.Time
+}
+
+// BreakerMetrics is a snapshot of circuit breaker counters
+type BreakerMetrics struct {
+	Name         string
+	State        State
+	Calls        int64
+	Successes    int64
+	Failures     int64
+	Rejections   int64
+	StateChanges int64
+}
+
+// CircuitBreaker stops calling a failing dependency for a while after repeated failures
+type CircuitBreaker struct {
+	name   string
+	config BreakerConfig
+	now    func() time.Time
+
+	mu                sync.Mutex
+	state             State
+	consecutiveFails  int
+	halfOpenSuccesses int
+	halfOpenInFlight  int
+	// generation counts the state transitions, so that calls finishing after one
+	// don't count towards the new state
+	generation uint64
+	openedAt   time.Time
+	metrics    BreakerMetrics
+	listeners  []func(StateChange)
+}
+
+// NewCircuitBreaker creates a circuit breaker; zero config values fall back to defaults
+func NewCircuitBreaker(name string, config BreakerConfig) *CircuitBreaker {
+	defaults := DefaultBreakerConfig()
+	if config.FailureThreshold <= 0 {
+		config.FailureThreshold = defaults.FailureThreshold
+	}
+	if config.OpenTimeout <= 0 {
+		config.OpenTimeout = defaults.OpenTimeout
+	}
+	if config.HalfOpenProbes <= 0 {
+		config.HalfOpenProbes = defaults.HalfOpenProbes
+	}
+
+	return &CircuitBreaker{
+		name:    name,
+		config:  config,
+		now:     time.Now,
+		metrics: BreakerMetrics{Name: name},
+	}
+}
+
+// Name returns the component name
+func (b *CircuitBreaker) Name() string {
+	return "circuit-breaker." + b.name
+}
+
+// Init is a no-op: breakers are configured by the starter
+func (b *CircuitBreaker) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// OnStateChange registers a listener called on every state transition
+func (b *CircuitBreaker) OnStateChange(listener func(StateChange)) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.listeners = append(b.listeners, listener)
+}
+
+// State returns the current state
+func (b *CircuitBreaker) State() State {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshState()
+	return b.state
+}
+
+// Metrics returns a snapshot of the breaker counters
+func (b *CircuitBreaker) Metrics() BreakerMetrics {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.refreshState()
+	result := b.metrics
+	result.State = b.state
+	return result
+}
+
+// Execute calls fn unless the circuit is open, recording its outcome. A call that
+// panics is recorded as a failure before the panic is passed on.
+func (b *CircuitBreaker) Execute(ctx context.Context, fn func(context.Context) error) error {
+	call, changes, err := b.acquire()
+	b.notify(changes)
+	if err != nil {
+		return err
+	}
+
+	callErr := errCallPanicked
+	defer func() {
+		b.notify(b.release(call, callErr))
+	}()
+
+	callErr = fn(ctx)
+	return callErr
+}
+
+// breakerCall is a call let through, with the state it was let through in
+type breakerCall struct {
+	generation uint64
+	probe      bool
+}
+
+// acquire checks whether a call may proceed
+func (b *CircuitBreaker) acquire() (breakerCall, []StateChange, error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	changes := b.refreshState()
+	b.metrics.Calls++
+
+	call := breakerCall{generation: b.generation}
+	switch b.state {
+	case StateOpen:
+		b.metrics.Rejections++
+		return call, changes, ErrCircuitOpen
+	case StateHalfOpen:
+		if b.halfOpenInFlight >= b.config.HalfOpenProbes {
+			b.metrics.Rejections++
+			return call, changes, ErrCircuitOpen
+		}
+		b.halfOpenInFlight++
+		call.probe = true
+	}
+	return call, changes, nil
+}
+
+// release records the outcome of a call. Calls let through before the last state
+// transition only count in the metrics.
+func (b *CircuitBreaker) release(call breakerCall, err error) []StateChange {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	if err != nil {
+		b.metrics.Failures++
+	} else {
+		b.metrics.Successes++
+	}
+	if call.generation != b.generation {
+		return nil
+	}
+	if call.probe {
+		b.halfOpenInFlight--
+	}
+
+	if err != nil {
+		b.consecutiveFails++
+		if call.probe || b.consecutiveFails >= b.config.FailureThreshold {
+			return b.transition(StateOpen)
+		}
+		return nil
+	}
+
+	b.consecutiveFails = 0
+	if call.probe {
+		b.halfOpenSuccesses++
+		if b.halfOpenSuccesses >= b.config.HalfOpenProbes {
+			return b.transition(StateClosed)
+		}
+	}
+	return nil
+}
+
+// refreshState moves an open circuit to half-open once the open timeout elapsed
+func (b *CircuitBreaker) refreshState() []StateChange {
+	if b.state == StateOpen && b.now().Sub(b.openedAt) >= b.config.OpenTimeout {
+		return b.transition(StateHalfOpen)
+	}
+	return nil
+}
+
+// transition changes state; must be called with the lock held
+func (b *CircuitBreaker) transition(to State) []StateChange {
+	if b.state == to {
+		return nil
+	}
+
+	change := StateChange{Breaker: b.name, From: b.state, To: to, At: b.now()}
+	b.state = to
+	b.generation++
+	b.metrics.StateChanges++
+	b.consecutiveFails = 0
+	b.halfOpenSuccesses = 0
+	b.halfOpenInFlight = 0
+	if to == StateOpen {
+		b.openedAt = change.At
+	}
+	return []StateChange{change}
+}
+
+// notify calls the listeners outside the lock
+func (b *CircuitBreaker) notify(changes []StateChange) {
+	if len(changes) == 0 {
+		return
+	}
+
+	b.mu.Lock()
+	listeners := make([]func(StateChange), len(b.listeners))
+	copy(listeners, b.listeners)
+	b.mu.Unlock()
+
+	for _, change := range changes {
+		for _, listener := range listeners {
+			listener(change)
+		}
+	}
+}
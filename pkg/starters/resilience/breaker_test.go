@@ -0,0 +1,126 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+)
+
+var errCall = errors.New("call failed")
+
+// newTestBreaker returns a breaker opening after one failure, whose clock is advanced by the test
+func newTestBreaker() (*CircuitBreaker, *time.Time) {
+	now := time.Date(2026, 1, 1, 0, 0, 0, 0, time.UTC)
+	breaker := NewCircuitBreaker("test", BreakerConfig{FailureThreshold: 1, OpenTimeout: time.Second, HalfOpenProbes: 1})
+	breaker.now = func() time.Time { return now }
+	return breaker, &now
+}
+
+func succeed(context.Context) error { return nil }
+
+func fail(context.Context) error { return errCall }
+
+func TestCircuitBreakerPanickingProbe(t *testing.T) {
+	breaker, now := newTestBreaker()
+	_ = breaker.Execute(context.Background(), fail)
+	*now = now.Add(time.Second)
+	if state := breaker.State(); state != StateHalfOpen {
+		t.Fatalf("State() = %s, want half-open", state)
+	}
+
+	func() {
+		defer func() {
+			if r := recover(); r != "boom" {
+				t.Errorf("recover() = %v, want the probe's panic", r)
+			}
+		}()
+		_ = breaker.Execute(context.Background(), func(context.Context) error { panic("boom") })
+	}()
+	if state := breaker.State(); state != StateOpen {
+		t.Fatalf("State() after a panicking probe = %s, want open", state)
+	}
+	if failures := breaker.Metrics().Failures; failures != 2 {
+		t.Errorf("Failures = %d, want 2", failures)
+	}
+
+	// The panicking probe doesn't hold the only probe slot
+	*now = now.Add(time.Second)
+	if err := breaker.Execute(context.Background(), succeed); err != nil {
+		t.Fatalf("Execute() of the next probe error = %v", err)
+	}
+	if state := breaker.State(); state != StateClosed {
+		t.Errorf("State() after a successful probe = %s, want closed", state)
+	}
+}
+
+func TestCircuitBreakerCallFromEarlierState(t *testing.T) {
+	breaker, now := newTestBreaker()
+
+	// A slow call let through while closed
+	started, finish := make(chan struct{}), make(chan error)
+	done := make(chan error)
+	go func() {
+		done <- breaker.Execute(context.Background(), func(context.Context) error {
+			close(started)
+			return <-finish
+		})
+	}()
+	<-started
+
+	_ = breaker.Execute(context.Background(), fail)
+	*now = now.Add(time.Second)
+	if state := breaker.State(); state != StateHalfOpen {
+		t.Fatalf("State() = %s, want half-open", state)
+	}
+
+	// Its success doesn't count as a probe closing the circuit
+	finish <- nil
+	if err := <-done; err != nil {
+		t.Fatalf("Execute() of the slow call error = %v", err)
+	}
+	if state := breaker.State(); state != StateHalfOpen {
+		t.Fatalf("State() after the slow call = %s, want half-open", state)
+	}
+
+	// Nor does it free a probe slot: one probe is let through, a second is rejected
+	probing, release := make(chan struct{}), make(chan error)
+	go func() {
+		done <- breaker.Execute(context.Background(), func(context.Context) error {
+			close(probing)
+			return <-release
+		})
+	}()
+	<-probing
+	if err := breaker.Execute(context.Background(), succeed); !errors.Is(err, ErrCircuitOpen) {
+		t.Errorf("Execute() during the probe error = %v, want ErrCircuitOpen", err)
+	}
+	release <- nil
+	if err := <-done; err != nil {
+		t.Fatalf("Execute() of the probe error = %v", err)
+	}
+	if state := breaker.State(); state != StateClosed {
+		t.Errorf("State() after the probe = %s, want closed", state)
+	}
+}
+
+func TestCircuitBreakerFailureFromEarlierState(t *testing.T) {
+	breaker, now := newTestBreaker()
+
+	call, _, err := breaker.acquire()
+	if err != nil {
+		t.Fatal(err)
+	}
+	_ = breaker.Execute(context.Background(), fail)
+	*now = now.Add(time.Second)
+	breaker.State()
+
+	// A failure let through while closed doesn't reopen the half-open circuit
+	breaker.release(call, errCall)
+	if state := breaker.State(); state != StateHalfOpen {
+		t.Errorf("State() = %s, want half-open", state)
+	}
+	if inFlight := breaker.halfOpenInFlight; inFlight != 0 {
+		t.Errorf("halfOpenInFlight = %d, want 0", inFlight)
+	}
+}
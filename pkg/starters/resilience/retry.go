@@ -0,0 +1,138 @@
+package resilience
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// RetryConfig configures a retryer
+type RetryConfig struct {
+	// MaxAttempts is the total number of attempts including the first call
+	MaxAttempts int `yaml:"max-attempts"`
+	// InitialBackoff is the delay before the first retry
+	InitialBackoff time.Duration `yaml:"initial-backoff"`
+	// MaxBackoff caps the delay between retries
+	MaxBackoff time.Duration `yaml:"max-backoff"`
+	// Multiplier grows the delay after each retry
+	Multiplier float64 `yaml:"multiplier"`
+}
+
+// DefaultRetryConfig returns the default retry configuration
+func DefaultRetryConfig() RetryConfig {
+	return RetryConfig{
+		MaxAttempts:    3,
+		InitialBackoff: 100 * time.Millisecond,
+		MaxBackoff:     5 * time.Second,
+		Multiplier:     2,
+	}
+}
+
+// RetryMetrics is a snapshot of retryer counters
+type RetryMetrics struct {
+	Name      string
+	Calls     int64
+	Retries   int64
+	Successes int64
+	Failures  int64
+}
+
+// Retryer retries failed calls with exponential backoff
+type Retryer struct {
+	name   string
+	config RetryConfig
+	// Retryable decides whether an error is worth retrying (all errors by default
+	// except context cancellation and ErrCircuitOpen)
+	Retryable func(error) bool
+
+	calls     atomic.Int64
+	retries   atomic.Int64
+	successes atomic.Int64
+	failures  atomic.Int64
+}
+
+// NewRetryer creates a retryer; zero config values fall back to defaults
+func NewRetryer(name string, config RetryConfig) *Retryer {
+	defaults := DefaultRetryConfig()
+	if config.MaxAttempts <= 0 {
+		config.MaxAttempts = defaults.MaxAttempts
+	}
+	if config.InitialBackoff <= 0 {
+		config.InitialBackoff = defaults.InitialBackoff
+	}
+	if config.MaxBackoff <= 0 {
+		config.MaxBackoff = defaults.MaxBackoff
+	}
+	if config.Multiplier < 1 {
+		config.Multiplier = defaults.Multiplier
+	}
+
+	return &Retryer{
+		name:      name,
+		config:    config,
+		Retryable: defaultRetryable,
+	}
+}
+
+// Name returns the component name
+func (r *Retryer) Name() string {
+	return "retryer." + r.name
+}
+
+// Init is a no-op: retryers are configured by the starter
+func (r *Retryer) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Metrics returns a snapshot of the retryer counters
+func (r *Retryer) Metrics() RetryMetrics {
+	return RetryMetrics{
+		Name:      r.name,
+		Calls:     r.calls.Load(),
+		Retries:   r.retries.Load(),
+		Successes: r.successes.Load(),
+		Failures:  r.failures.Load(),
+	}
+}
+
+// Do calls fn until it succeeds, returns a non-retryable error, the attempts are
+// exhausted or ctx is done. The last error is returned.
+func (r *Retryer) Do(ctx context.Context, fn func(context.Context) error) error {
+	r.calls.Add(1)
+	backoff := r.config.InitialBackoff
+
+	var err error
+	for attempt := 1; ; attempt++ {
+		if err = fn(ctx); err == nil {
+			r.successes.Add(1)
+			return nil
+		}
+
+		if attempt >= r.config.MaxAttempts || !r.Retryable(err) {
+			r.failures.Add(1)
+			return err
+		}
+
+		select {
+		case <-ctx.Done():
+			r.failures.Add(1)
+			return err
+		case <-time.After(backoff):
+		}
+
+		r.retries.Add(1)
+		backoff = time.Duration(float64(backoff) * r.config.Multiplier)
+		if backoff > r.config.MaxBackoff {
+			backoff = r.config.MaxBackoff
+		}
+	}
+}
+
+func defaultRetryable(err error) bool {
+	return !errors.Is(err, context.Canceled) &&
+		!errors.Is(err, context.DeadlineExceeded) &&
+		!errors.Is(err, ErrCircuitOpen)
+}
@@ -0,0 +1,70 @@
+// Package resilience provides circuit breaker and retry components for outbound calls
+package resilience
+
+import (
+	"fmt"
+	"sort"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Configuration properties
+const (
+	// PropertyCircuitBreakers holds per-breaker configuration:
+	// resilience.circuit-breakers.<name>.failure-threshold / open-timeout / half-open-probes
+	PropertyCircuitBreakers = "resilience.circuit-breakers"
+	// PropertyRetries holds per-retryer configuration:
+	// resilience.retries.<name>.max-attempts / initial-backoff / max-backoff / multiplier
+	PropertyRetries = "resilience.retries"
+)
+
+// Starter registers a CircuitBreaker component named "circuit-breaker.<name>" for each
+// configured breaker and a Retryer component named "retryer.<name>" for each
+// configured retryer. Components look them up by name:
+//
+//	breaker, err := container.GetComponentAs[*resilience.CircuitBreaker](ctx, "circuit-breaker.payments")
+func Starter() container.Starter {
+	return container.NewStarter("ResilienceStarter", func(builder container.ContextBuilder) error {
+		breakers := map[string]BreakerConfig{}
+		if err := bindSection(builder, PropertyCircuitBreakers, &breakers); err != nil {
+			return err
+		}
+		for _, name := range sortedKeys(breakers) {
+			if err := builder.RegisterComponent(NewCircuitBreaker(name, breakers[name])); err != nil {
+				return err
+			}
+		}
+
+		retries := map[string]RetryConfig{}
+		if err := bindSection(builder, PropertyRetries, &retries); err != nil {
+			return err
+		}
+		for _, name := range sortedKeys(retries) {
+			if err := builder.RegisterComponent(NewRetryer(name, retries[name])); err != nil {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
+
+// bindSection binds an optional configuration section
+func bindSection(ctx container.ApplicationContext, name string, target interface{}) error {
+	if !container.NewVariableHelper(ctx).HasSection(name) {
+		return nil
+	}
+	if err := ctx.GetVariableAs(name, target); err != nil {
+		return fmt.Errorf("invalid %s configuration: %w", name, err)
+	}
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
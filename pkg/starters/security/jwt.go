@@ -0,0 +1,372 @@
+package security
+
+import (
+	"context"
+	"crypto"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/hmac"
+	"crypto/rsa"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"log/slog"
+	"math/big"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// JWTConfig configures JWT authentication: security.jwt.*
+type JWTConfig struct {
+	// Secret verifies HS256/HS384/HS512 tokens
+	Secret string `yaml:"secret"`
+	// JWKSURL is fetched for the keys verifying RS* and ES* tokens
+	JWKSURL string `yaml:"jwks-url"`
+	// Issuer and Audience are required claim values when set
+	Issuer   string `yaml:"issuer"`
+	Audience string `yaml:"audience"`
+	// RolesClaim holds the roles, as array or space separated string (default roles)
+	RolesClaim string `yaml:"roles-claim"`
+	// Leeway tolerates clock skew when checking exp and nbf (default 30s)
+	Leeway time.Duration `yaml:"leeway"`
+	// JWKSRefresh is how long the fetched keys are used (default 1h); an unknown
+	// key ID fetches them again at most once a minute
+	JWKSRefresh time.Duration `yaml:"jwks-refresh"`
+}
+
+func (c JWTConfig) withDefaults() JWTConfig {
+	if c.RolesClaim == "" {
+		c.RolesClaim = "roles"
+	}
+	if c.Leeway <= 0 {
+		c.Leeway = 30 * time.Second
+	}
+	if c.JWKSRefresh <= 0 {
+		c.JWKSRefresh = time.Hour
+	}
+	return c
+}
+
+// algorithmHashes maps the suffix of an algorithm to its hash
+var algorithmHashes = map[string]crypto.Hash{"256": crypto.SHA256, "384": crypto.SHA384, "512": crypto.SHA512}
+
+// jwksMinRefresh limits fetching the keys for unknown key IDs
+const jwksMinRefresh = time.Minute
+
+// JWTAuthenticator authenticates requests carrying a valid bearer JWT
+type JWTAuthenticator struct {
+	config JWTConfig
+	client *http.Client
+	now    func() time.Time
+	logger *slog.Logger
+
+	mu      sync.Mutex
+	keys    map[string]crypto.PublicKey
+	fetched time.Time
+}
+
+// NewJWTAuthenticator creates an authenticator verifying tokens with the secret or
+// the keys of the JWKS URL
+func NewJWTAuthenticator(config JWTConfig) *JWTAuthenticator {
+	return &JWTAuthenticator{
+		config: config.withDefaults(),
+		client: &http.Client{Timeout: 10 * time.Second},
+		now:    time.Now,
+		logger: slog.Default(),
+	}
+}
+
+// Name returns the component name
+func (a *JWTAuthenticator) Name() string {
+	return "jwtAuthenticator"
+}
+
+// Init is a no-op; the keys are fetched on first use
+func (a *JWTAuthenticator) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Challenge returns the WWW-Authenticate header of unauthenticated responses
+func (a *JWTAuthenticator) Challenge() string {
+	return "Bearer"
+}
+
+// Authenticate verifies the bearer token of the Authorization header
+func (a *JWTAuthenticator) Authenticate(r *http.Request) (*Principal, error) {
+	scheme, token, found := strings.Cut(r.Header.Get("Authorization"), " ")
+	if !found || !strings.EqualFold(scheme, "Bearer") {
+		return nil, nil
+	}
+	claims, err := a.Verify(r.Context(), strings.TrimSpace(token))
+	if err != nil {
+		return nil, err
+	}
+
+	subject, _ := claims["sub"].(string)
+	return &Principal{Subject: subject, Roles: roles(claims[a.config.RolesClaim]), Method: MethodJWT, Claims: claims}, nil
+}
+
+// jwtHeader is the JOSE header of a token
+type jwtHeader struct {
+	Alg string `json:"alg"`
+	Kid string `json:"kid"`
+}
+
+// Verify checks the signature and the registered claims of a token and returns its claims
+func (a *JWTAuthenticator) Verify(ctx context.Context, token string) (map[string]any, error) {
+	parts := strings.Split(token, ".")
+	if len(parts) != 3 {
+		return nil, errors.New("malformed token")
+	}
+	var header jwtHeader
+	if err := decodeSegment(parts[0], &header); err != nil {
+		return nil, fmt.Errorf("malformed token header: %w", err)
+	}
+	signature, err := base64.RawURLEncoding.DecodeString(parts[2])
+	if err != nil {
+		return nil, fmt.Errorf("malformed token signature: %w", err)
+	}
+	if err := a.verifySignature(ctx, header, []byte(parts[0]+"."+parts[1]), signature); err != nil {
+		return nil, err
+	}
+
+	var claims map[string]any
+	if err := decodeSegment(parts[1], &claims); err != nil {
+		return nil, fmt.Errorf("malformed token claims: %w", err)
+	}
+	if err := a.checkClaims(claims); err != nil {
+		return nil, err
+	}
+	return claims, nil
+}
+
+func (a *JWTAuthenticator) verifySignature(ctx context.Context, header jwtHeader, signed, signature []byte) error {
+	// Only HS*, RS* and ES* with SHA-256, SHA-384 or SHA-512 are accepted, never none
+	if len(header.Alg) != 5 {
+		return fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+	family := header.Alg[:2]
+	hash, ok := algorithmHashes[header.Alg[2:]]
+	if !ok || (family != "HS" && family != "RS" && family != "ES") {
+		return fmt.Errorf("unsupported token algorithm %q", header.Alg)
+	}
+
+	if family == "HS" {
+		if a.config.Secret == "" {
+			return fmt.Errorf("unsupported token algorithm %q", header.Alg)
+		}
+		mac := hmac.New(hash.New, []byte(a.config.Secret))
+		mac.Write(signed)
+		if !hmac.Equal(mac.Sum(nil), signature) {
+			return errors.New("invalid token signature")
+		}
+		return nil
+	}
+
+	key, err := a.key(ctx, header.Kid)
+	if err != nil {
+		return err
+	}
+	digest := hash.New()
+	digest.Write(signed)
+	sum := digest.Sum(nil)
+
+	switch key := key.(type) {
+	case *rsa.PublicKey:
+		if family != "RS" || rsa.VerifyPKCS1v15(key, hash, sum, signature) != nil {
+			return errors.New("invalid token signature")
+		}
+	case *ecdsa.PublicKey:
+		size := (key.Curve.Params().BitSize + 7) / 8
+		if family != "ES" || len(signature) != 2*size {
+			return errors.New("invalid token signature")
+		}
+		r := new(big.Int).SetBytes(signature[:size])
+		s := new(big.Int).SetBytes(signature[size:])
+		if !ecdsa.Verify(key, sum, r, s) {
+			return errors.New("invalid token signature")
+		}
+	default:
+		return errors.New("invalid token signature")
+	}
+	return nil
+}
+
+// checkClaims checks exp, nbf, iss and aud
+func (a *JWTAuthenticator) checkClaims(claims map[string]any) error {
+	now := a.now()
+	if exp, ok := claims["exp"].(float64); ok && now.After(time.Unix(int64(exp), 0).Add(a.config.Leeway)) {
+		return errors.New("token expired")
+	}
+	if nbf, ok := claims["nbf"].(float64); ok && now.Add(a.config.Leeway).Before(time.Unix(int64(nbf), 0)) {
+		return errors.New("token not valid yet")
+	}
+	if a.config.Issuer != "" && claims["iss"] != a.config.Issuer {
+		return errors.New("token issuer mismatch")
+	}
+	if a.config.Audience != "" {
+		matched := false
+		switch aud := claims["aud"].(type) {
+		case string:
+			matched = aud == a.config.Audience
+		case []any:
+			for _, value := range aud {
+				matched = matched || value == a.config.Audience
+			}
+		}
+		if !matched {
+			return errors.New("token audience mismatch")
+		}
+	}
+	return nil
+}
+
+// key returns the JWKS key with the ID, fetching the keys when they are stale or
+// the ID is unknown
+func (a *JWTAuthenticator) key(ctx context.Context, kid string) (crypto.PublicKey, error) {
+	if a.config.JWKSURL == "" {
+		return nil, errors.New("no JWKS configured")
+	}
+
+	a.mu.Lock()
+	defer a.mu.Unlock()
+
+	age := a.now().Sub(a.fetched)
+	key, known := a.keys[kid]
+	if a.keys == nil || age > a.config.JWKSRefresh || (!known && age > jwksMinRefresh) {
+		keys, err := a.fetchKeys(ctx)
+		if err != nil {
+			if a.keys == nil {
+				return nil, err
+			}
+			a.logger.Warn("JWKS refresh failed, using cached keys", "url", a.config.JWKSURL, "error", err)
+		} else {
+			a.keys, a.fetched = keys, a.now()
+		}
+		key, known = a.keys[kid]
+	}
+	if !known {
+		return nil, fmt.Errorf("unknown token key %q", kid)
+	}
+	return key, nil
+}
+
+// jwk is a key of a JWKS document
+type jwk struct {
+	Kty string `json:"kty"`
+	Kid string `json:"kid"`
+	Use string `json:"use"`
+	N   string `json:"n"`
+	E   string `json:"e"`
+	Crv string `json:"crv"`
+	X   string `json:"x"`
+	Y   string `json:"y"`
+}
+
+// fetchKeys fetches the RSA and EC signing keys of the JWKS URL by key ID
+func (a *JWTAuthenticator) fetchKeys(ctx context.Context) (map[string]crypto.PublicKey, error) {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, a.config.JWKSURL, nil)
+	if err != nil {
+		return nil, err
+	}
+	resp, err := a.client.Do(req)
+	if err != nil {
+		return nil, fmt.Errorf("fetch JWKS: %w", err)
+	}
+	defer resp.Body.Close()
+	if resp.StatusCode != http.StatusOK {
+		return nil, fmt.Errorf("fetch JWKS: %s", resp.Status)
+	}
+
+	var document struct {
+		Keys []jwk `json:"keys"`
+	}
+	if err := json.NewDecoder(resp.Body).Decode(&document); err != nil {
+		return nil, fmt.Errorf("decode JWKS: %w", err)
+	}
+
+	keys := make(map[string]crypto.PublicKey, len(document.Keys))
+	for _, key := range document.Keys {
+		if key.Use != "" && key.Use != "sig" {
+			continue
+		}
+		publicKey, err := key.publicKey()
+		if err != nil {
+			a.logger.Warn("Ignoring invalid JWKS key", "kid", key.Kid, "error", err)
+			continue
+		}
+		keys[key.Kid] = publicKey
+	}
+	return keys, nil
+}
+
+func (k jwk) publicKey() (crypto.PublicKey, error) {
+	switch k.Kty {
+	case "RSA":
+		n, err := base64.RawURLEncoding.DecodeString(k.N)
+		if err != nil {
+			return nil, err
+		}
+		e, err := base64.RawURLEncoding.DecodeString(k.E)
+		if err != nil {
+			return nil, err
+		}
+		return &rsa.PublicKey{N: new(big.Int).SetBytes(n), E: int(new(big.Int).SetBytes(e).Int64())}, nil
+	case "EC":
+		var curve elliptic.Curve
+		switch k.Crv {
+		case "P-256":
+			curve = elliptic.P256()
+		case "P-384":
+			curve = elliptic.P384()
+		case "P-521":
+			curve = elliptic.P521()
+		default:
+			return nil, fmt.Errorf("unsupported curve %q", k.Crv)
+		}
+		x, err := base64.RawURLEncoding.DecodeString(k.X)
+		if err != nil {
+			return nil, err
+		}
+		y, err := base64.RawURLEncoding.DecodeString(k.Y)
+		if err != nil {
+			return nil, err
+		}
+		return &ecdsa.PublicKey{Curve: curve, X: new(big.Int).SetBytes(x), Y: new(big.Int).SetBytes(y)}, nil
+	}
+	return nil, fmt.Errorf("unsupported key type %q", k.Kty)
+}
+
+// decodeSegment decodes a base64url JSON segment of a token
+func decodeSegment(segment string, out any) error {
+	data, err := base64.RawURLEncoding.DecodeString(segment)
+	if err != nil {
+		return err
+	}
+	return json.Unmarshal(data, out)
+}
+
+// roles reads a roles claim given as array or space separated string
+func roles(claim any) []string {
+	switch value := claim.(type) {
+	case string:
+		return strings.Fields(value)
+	case []any:
+		result := make([]string, 0, len(value))
+		for _, role := range value {
+			if role, ok := role.(string); ok {
+				result = append(result, role)
+			}
+		}
+		return result
+	}
+	return nil
+}
+
+// Ensure that JWTAuthenticator implements Authenticator
+var _ Authenticator = (*JWTAuthenticator)(nil)
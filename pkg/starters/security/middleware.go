@@ -0,0 +1,176 @@
+package security
+
+import (
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"sort"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/web"
+)
+
+// Default policies of requests no rule matches
+const (
+	PolicyAuthenticated = "authenticated"
+	PolicyPublic        = "public"
+)
+
+// challenger is implemented by authenticators announcing a WWW-Authenticate scheme
+type challenger interface {
+	Challenge() string
+}
+
+// Middleware authenticates every request with the registered Authenticator
+// components and authorizes it with the rules of the SecuredHandler components.
+// The principal is added to the request context before the request scope opens,
+// so handlers can get it with PrincipalFromContext or the principal component.
+type Middleware struct {
+	defaultPolicy string
+	publicPaths   []string
+
+	authenticators []Authenticator
+	challenges     []string
+	rules          ruleSet
+	logger         *slog.Logger
+}
+
+// NewMiddleware creates the security middleware; requests no rule matches are
+// public if defaultPolicy is PolicyPublic, and publicPaths are public path patterns
+func NewMiddleware(defaultPolicy string, publicPaths []string) *Middleware {
+	if defaultPolicy == "" {
+		defaultPolicy = PolicyAuthenticated
+	}
+	return &Middleware{defaultPolicy: defaultPolicy, publicPaths: publicPaths, logger: slog.Default()}
+}
+
+// Name returns the component name
+func (m *Middleware) Name() string {
+	return "securityMiddleware"
+}
+
+// Init collects the authenticators and the rules of the secured handlers
+func (m *Middleware) Init(ctx container.ApplicationContext) error {
+	if m.defaultPolicy != PolicyAuthenticated && m.defaultPolicy != PolicyPublic {
+		return container.ErrorWithCode("INVALID_SECURITY_POLICY", "unknown default policy %q", m.defaultPolicy)
+	}
+
+	m.authenticators, m.challenges = nil, nil
+	var rules []Rule
+	for _, path := range m.publicPaths {
+		rules = append(rules, Rule{Path: path, Public: true})
+	}
+	for _, comp := range findComponents(ctx, func(comp any) bool {
+		_, isAuthenticator := comp.(Authenticator)
+		_, isSecured := comp.(SecuredHandler)
+		return isAuthenticator || isSecured
+	}) {
+		if authenticator, ok := comp.(Authenticator); ok {
+			m.authenticators = append(m.authenticators, authenticator)
+			if challenger, ok := comp.(challenger); ok {
+				m.challenges = append(m.challenges, challenger.Challenge())
+			}
+		}
+		if secured, ok := comp.(SecuredHandler); ok {
+			rules = append(rules, secured.SecurityRules()...)
+		}
+	}
+	m.rules = newRuleSet(rules)
+
+	if len(m.authenticators) == 0 && m.defaultPolicy == PolicyAuthenticated {
+		m.logger.Warn("No authenticator registered, only public routes can be served")
+	}
+	return nil
+}
+
+// Order returns web.OrderSecurity
+func (m *Middleware) Order() int {
+	return web.OrderSecurity
+}
+
+// Wrap authenticates and authorizes the requests of next. Unauthenticated requests
+// get a 401 announcing the schemes of the authenticators and requests lacking a
+// required role get a 403.
+func (m *Middleware) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		rule, matched := m.rules.match(r.Method, r.URL.Path)
+		public := (matched && rule.Public) || (!matched && m.defaultPolicy == PolicyPublic)
+
+		principal, err := m.authenticate(r)
+		if err != nil && !public {
+			m.logger.WarnContext(r.Context(), "Authentication failed", "path", r.URL.Path, "error", err)
+			m.unauthorized(w)
+			return
+		}
+
+		if !public {
+			if principal == nil {
+				m.unauthorized(w)
+				return
+			}
+			if len(rule.Roles) > 0 && !principal.HasAnyRole(rule.Roles...) {
+				writeError(w, http.StatusForbidden)
+				return
+			}
+		}
+
+		if principal != nil {
+			r = r.WithContext(WithPrincipal(r.Context(), principal))
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// authenticate returns the principal of the first authenticator recognizing the
+// credentials of the request, nil if it carries none
+func (m *Middleware) authenticate(r *http.Request) (*Principal, error) {
+	for _, authenticator := range m.authenticators {
+		principal, err := authenticator.Authenticate(r)
+		if err != nil || principal != nil {
+			return principal, err
+		}
+	}
+	return nil, nil
+}
+
+func (m *Middleware) unauthorized(w http.ResponseWriter) {
+	for _, challenge := range m.challenges {
+		w.Header().Add("WWW-Authenticate", challenge)
+	}
+	writeError(w, http.StatusUnauthorized)
+}
+
+func writeError(w http.ResponseWriter, status int) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(map[string]string{"error": http.StatusText(status)})
+}
+
+// findComponents returns the components matching pred sorted by name
+func findComponents(ctx container.ApplicationContext, pred func(any) bool) []any {
+	names := ctx.GetComponentNames()
+	if finder, ok := ctx.(container.ComponentFinder); ok {
+		names = names[:0]
+		for _, info := range finder.FindComponents(func(info container.ComponentInfo) bool {
+			return pred(info.Component)
+		}) {
+			names = append(names, info.Name)
+		}
+	}
+	sort.Strings(names)
+
+	var result []any
+	for _, name := range names {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if pred(comp) {
+			result = append(result, comp)
+		}
+	}
+	return result
+}
+
+// Ensure that Middleware implements web.Middleware
+var _ web.Middleware = (*Middleware)(nil)
@@ -0,0 +1,86 @@
+// Package security authenticates the requests of the web starter with API keys,
+// basic auth or JWTs and authorizes them with route-level rules
+package security
+
+import (
+	"context"
+	"net/http"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Authentication methods
+const (
+	MethodAPIKey = "api-key"
+	MethodBasic  = "basic"
+	MethodJWT    = "jwt"
+)
+
+// Principal is the authenticated caller of a request
+type Principal struct {
+	// Subject identifies the caller: the key's subject, the user name or the sub claim
+	Subject string
+	Roles   []string
+	// Method is the authentication method, e.g. MethodJWT
+	Method string
+	// Claims holds the claims of a JWT
+	Claims map[string]any
+}
+
+// HasRole reports whether the principal has the role
+func (p *Principal) HasRole(role string) bool {
+	for _, candidate := range p.Roles {
+		if candidate == role {
+			return true
+		}
+	}
+	return false
+}
+
+// HasAnyRole reports whether the principal has one of the roles
+func (p *Principal) HasAnyRole(roles ...string) bool {
+	for _, role := range roles {
+		if p.HasRole(role) {
+			return true
+		}
+	}
+	return false
+}
+
+// principalKey is the context key of the principal
+type principalKey struct{}
+
+// WithPrincipal returns a context carrying the principal
+func WithPrincipal(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// PrincipalFromContext returns the principal of an authenticated request
+func PrincipalFromContext(ctx context.Context) (*Principal, bool) {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	return principal, ok && principal != nil
+}
+
+// Authenticator authenticates requests with one kind of credentials. Register a
+// component implementing it to support other credentials.
+type Authenticator interface {
+	// Authenticate returns nil without error if the request doesn't carry its kind
+	// of credentials, and an error if they are invalid
+	Authenticate(r *http.Request) (*Principal, error)
+}
+
+// PrincipalName is the name of the request-scoped principal component:
+//
+//	principal, err := container.GetScoped[*security.Principal](r.Context(), security.PrincipalName)
+const PrincipalName = "principal"
+
+// newPrincipalComponent declares the request-scoped principal
+func newPrincipalComponent() *container.ScopedComponent {
+	return container.NewScoped(PrincipalName, func(scope *container.Scope) (interface{}, error) {
+		principal, ok := PrincipalFromContext(scope.Context())
+		if !ok {
+			return nil, container.ErrorWithCode("UNAUTHENTICATED", "the request isn't authenticated")
+		}
+		return principal, nil
+	})
+}
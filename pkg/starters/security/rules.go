@@ -0,0 +1,108 @@
+package security
+
+import (
+	"sort"
+	"strings"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Rule secures the requests matching a method and path
+type Rule struct {
+	// Method restricts the rule to one HTTP method (all methods if empty)
+	Method string
+	// Path is matched like a web.Route path: a trailing slash matches the whole
+	// subtree and {param} segments match any segment
+	Path string
+	// Public lets unauthenticated requests through
+	Public bool
+	// Roles requires the principal to have at least one of the roles
+	Roles []string
+}
+
+// SecuredHandler is implemented by components, usually web.HTTPHandler ones, that
+// declare the security rules of their routes
+type SecuredHandler interface {
+	container.Component
+	SecurityRules() []Rule
+}
+
+// ruleSet matches requests to the most specific rule
+type ruleSet []Rule
+
+// newRuleSet sorts the rules from the most to the least specific: more literal
+// characters first, then exact paths before subtrees, then method-specific rules
+func newRuleSet(rules []Rule) ruleSet {
+	sorted := append(ruleSet(nil), rules...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		a, b := sorted[i], sorted[j]
+		if la, lb := literalLength(a.Path), literalLength(b.Path); la != lb {
+			return la > lb
+		}
+		if sa, sb := isSubtree(a.Path), isSubtree(b.Path); sa != sb {
+			return !sa
+		}
+		return a.Method != "" && b.Method == ""
+	})
+	return sorted
+}
+
+// match returns the most specific rule for the request
+func (rs ruleSet) match(method, path string) (Rule, bool) {
+	for _, rule := range rs {
+		if rule.Method != "" && !strings.EqualFold(rule.Method, method) {
+			continue
+		}
+		if matchPath(rule.Path, path) {
+			return rule, true
+		}
+	}
+	return Rule{}, false
+}
+
+// matchPath matches a path against an exact, subtree or parameterized pattern
+func matchPath(pattern, path string) bool {
+	subtree := isSubtree(pattern)
+	patternSegments := strings.Split(strings.Trim(pattern, "/"), "/")
+	pathSegments := strings.Split(strings.Trim(path, "/"), "/")
+	if pattern == "/" {
+		return true
+	}
+	if len(pathSegments) < len(patternSegments) || (!subtree && len(pathSegments) != len(patternSegments)) {
+		return false
+	}
+	if !subtree && strings.HasSuffix(path, "/") && path != "/" {
+		return false
+	}
+	for i, segment := range patternSegments {
+		if isParam(segment) {
+			if pathSegments[i] == "" {
+				return false
+			}
+			continue
+		}
+		if segment != pathSegments[i] {
+			return false
+		}
+	}
+	return true
+}
+
+// literalLength counts the characters of a pattern outside its parameters
+func literalLength(pattern string) int {
+	length := 0
+	for _, segment := range strings.Split(pattern, "/") {
+		if !isParam(segment) {
+			length += len(segment) + 1
+		}
+	}
+	return length
+}
+
+func isSubtree(pattern string) bool {
+	return strings.HasSuffix(pattern, "/")
+}
+
+func isParam(segment string) bool {
+	return strings.HasPrefix(segment, "{") && strings.HasSuffix(segment, "}")
+}
@@ -0,0 +1,68 @@
+package security
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertySecurity holds the security configuration: security.*
+const PropertySecurity = "security"
+
+// Config configures the security starter
+type Config struct {
+	// DefaultPolicy applies to requests no rule matches: authenticated (default) or public
+	DefaultPolicy string `yaml:"default-policy"`
+	// PublicPaths are path patterns served without authentication, e.g. /health
+	PublicPaths []string     `yaml:"public-paths"`
+	APIKey      APIKeyConfig `yaml:"api-key"`
+	Basic       BasicConfig  `yaml:"basic"`
+	JWT         JWTConfig    `yaml:"jwt"`
+}
+
+// Starter secures the web server when security.enabled is true. It registers the
+// authenticators that are configured (API keys, basic auth users, a JWT secret or
+// JWKS URL), the request-scoped principal component and the security middleware.
+// Handlers declare the rules of their routes by implementing SecuredHandler:
+//
+//	func (h *OrderHandler) SecurityRules() []security.Rule {
+//		return []security.Rule{
+//			{Method: "GET", Path: "/orders/", Public: true},
+//			{Method: "DELETE", Path: "/orders/{id}", Roles: []string{"admin"}},
+//		}
+//	}
+//
+// Register the starter before the web starter.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"SecurityStarter",
+		container.PropertyCondition(PropertySecurity+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertySecurity, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertySecurity, err)
+			}
+
+			var components []container.Component
+			if len(config.APIKey.Keys) > 0 {
+				components = append(components, NewAPIKeyAuthenticator(config.APIKey))
+			}
+			if len(config.Basic.Users) > 0 {
+				components = append(components, NewBasicAuthenticator(config.Basic))
+			}
+			if config.JWT.Secret != "" || config.JWT.JWKSURL != "" {
+				components = append(components, NewJWTAuthenticator(config.JWT))
+			}
+			components = append(components,
+				newPrincipalComponent(),
+				NewMiddleware(config.DefaultPolicy, config.PublicPaths))
+
+			for _, comp := range components {
+				if err := builder.RegisterComponent(comp); err != nil {
+					return err
+				}
+			}
+			return nil
+		},
+	)
+}
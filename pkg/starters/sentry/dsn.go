@@ -0,0 +1,55 @@
+// Package sentry sends the errors reported by the container (panics of
+// components, failed starts and executions, failing event listeners) to Sentry
+package sentry
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+)
+
+// DSN is a parsed Sentry DSN, e.g. https://public@o1.ingest.sentry.io/42
+type DSN struct {
+	Scheme    string
+	PublicKey string
+	Host      string
+	// Path is the path before the project ID, empty for sentry.io
+	Path      string
+	ProjectID string
+}
+
+// ParseDSN parses a Sentry DSN
+func ParseDSN(dsn string) (*DSN, error) {
+	u, err := url.Parse(dsn)
+	if err != nil {
+		return nil, fmt.Errorf("invalid %s.dsn: %w", PropertySentry, err)
+	}
+	if u.Scheme != "http" && u.Scheme != "https" {
+		return nil, fmt.Errorf("invalid %s.dsn: unsupported scheme %q", PropertySentry, u.Scheme)
+	}
+	if u.User == nil || u.User.Username() == "" {
+		return nil, fmt.Errorf("invalid %s.dsn: missing public key", PropertySentry)
+	}
+	path := strings.TrimSuffix(u.Path, "/")
+	i := strings.LastIndex(path, "/")
+	if i < 0 || path[i+1:] == "" {
+		return nil, fmt.Errorf("invalid %s.dsn: missing project ID", PropertySentry)
+	}
+	return &DSN{
+		Scheme:    u.Scheme,
+		PublicKey: u.User.Username(),
+		Host:      u.Host,
+		Path:      path[:i],
+		ProjectID: path[i+1:],
+	}, nil
+}
+
+// EnvelopeURL returns the URL events are sent to
+func (d *DSN) EnvelopeURL() string {
+	return fmt.Sprintf("%s://%s%s/api/%s/envelope/", d.Scheme, d.Host, d.Path, d.ProjectID)
+}
+
+// AuthHeader returns the X-Sentry-Auth header of the requests
+func (d *DSN) AuthHeader() string {
+	return fmt.Sprintf("Sentry sentry_version=7, sentry_client=%s, sentry_key=%s", clientName, d.PublicKey)
+}
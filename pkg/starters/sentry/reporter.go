@@ -0,0 +1,308 @@
+package sentry
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"io"
+	"log/slog"
+	"net/http"
+	"os"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertySentry holds the Sentry configuration: sentry.*
+const PropertySentry = "sentry"
+
+// ReporterName is the Reporter component
+const ReporterName = "sentryReporter"
+
+// clientName identifies the reporter to Sentry
+const clientName = "goboot-sentry/1.0"
+
+// Config configures the reporter: sentry.*
+type Config struct {
+	// DSN of the Sentry project
+	DSN string `yaml:"dsn"`
+	// Environment of the events (default the active profiles)
+	Environment string `yaml:"environment"`
+	// Release of the events, e.g. the version of the application
+	Release string `yaml:"release"`
+	// ServerName of the events (default the host name)
+	ServerName string `yaml:"server-name"`
+	// Tags are added to every event
+	Tags map[string]string `yaml:"tags"`
+	// Timeout bounds sending one event (default 5s)
+	Timeout time.Duration `yaml:"timeout"`
+	// MaxPending is the number of events being sent at once; further events are
+	// dropped until one is sent (default 100)
+	MaxPending int `yaml:"max-pending"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.ServerName == "" {
+		c.ServerName, _ = os.Hostname()
+	}
+	if c.Timeout <= 0 {
+		c.Timeout = 5 * time.Second
+	}
+	if c.MaxPending <= 0 {
+		c.MaxPending = 100
+	}
+	return c
+}
+
+// Reporter is a container.ErrorReporter sending the reports to Sentry as events.
+// Events are sent in the background; Stop waits for the pending ones.
+type Reporter struct {
+	config Config
+	dsn    *DSN
+	// Client sends the events (http.DefaultClient by default)
+	Client *http.Client
+	logger *slog.Logger
+
+	pending chan struct{}
+	wg      sync.WaitGroup
+}
+
+// NewReporter creates a reporter sending to the DSN of the configuration
+func NewReporter(config Config) (*Reporter, error) {
+	config = config.withDefaults()
+	dsn, err := ParseDSN(config.DSN)
+	if err != nil {
+		return nil, err
+	}
+	return &Reporter{
+		config:  config,
+		dsn:     dsn,
+		logger:  slog.Default(),
+		pending: make(chan struct{}, config.MaxPending),
+	}, nil
+}
+
+// Name returns the component name
+func (r *Reporter) Name() string {
+	return ReporterName
+}
+
+// Init is a no-op
+func (r *Reporter) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Start is a no-op; reports are accepted as soon as the reporter is registered
+func (r *Reporter) Start(context.Context) {}
+
+// Stop waits for the events being sent, until ctx is done
+func (r *Reporter) Stop(ctx context.Context) {
+	if err := r.Flush(ctx); err != nil {
+		r.logger.Warn("Sentry events not sent before shutdown", "error", err)
+	}
+}
+
+// Flush waits for the events being sent, until ctx is done
+func (r *Reporter) Flush(ctx context.Context) error {
+	done := make(chan struct{})
+	go func() {
+		r.wg.Wait()
+		close(done)
+	}()
+	select {
+	case <-done:
+		return nil
+	case <-ctx.Done():
+		return ctx.Err()
+	}
+}
+
+// ReportError sends the report in the background, or drops it when MaxPending
+// events are being sent
+func (r *Reporter) ReportError(_ context.Context, report container.ErrorReport) {
+	select {
+	case r.pending <- struct{}{}:
+	default:
+		r.logger.Warn("Too many pending Sentry events, dropping one", "component", report.Component, "error", report.Err)
+		return
+	}
+
+	event := r.event(report)
+	r.wg.Add(1)
+	go func() {
+		defer r.wg.Done()
+		defer func() { <-r.pending }()
+
+		ctx, cancel := context.WithTimeout(context.Background(), r.config.Timeout)
+		defer cancel()
+		if err := r.send(ctx, event); err != nil {
+			r.logger.Warn("Failed to send Sentry event", "component", report.Component, "error", err)
+		}
+	}()
+}
+
+// event is a Sentry event
+type event struct {
+	EventID     string            `json:"event_id"`
+	Timestamp   string            `json:"timestamp"`
+	Platform    string            `json:"platform"`
+	Level       string            `json:"level"`
+	Logger      string            `json:"logger"`
+	ServerName  string            `json:"server_name,omitempty"`
+	Release     string            `json:"release,omitempty"`
+	Environment string            `json:"environment,omitempty"`
+	Transaction string            `json:"transaction,omitempty"`
+	Tags        map[string]string `json:"tags,omitempty"`
+	Exception   struct {
+		Values []exception `json:"values"`
+	} `json:"exception"`
+}
+
+type exception struct {
+	Type      string `json:"type"`
+	Value     string `json:"value"`
+	Mechanism struct {
+		Type    string `json:"type"`
+		Handled bool   `json:"handled"`
+	} `json:"mechanism"`
+	Stacktrace *stacktrace `json:"stacktrace,omitempty"`
+}
+
+type stacktrace struct {
+	Frames []frame `json:"frames"`
+}
+
+type frame struct {
+	Function string `json:"function"`
+	Filename string `json:"filename"`
+	Lineno   int    `json:"lineno"`
+}
+
+// event converts a report to a Sentry event; failed starts are fatal
+func (r *Reporter) event(report container.ErrorReport) *event {
+	e := &event{
+		EventID:     newEventID(),
+		Timestamp:   report.Time.UTC().Format(time.RFC3339Nano),
+		Platform:    "go",
+		Level:       "error",
+		Logger:      "goboot",
+		ServerName:  r.config.ServerName,
+		Release:     r.config.Release,
+		Environment: r.config.Environment,
+		Transaction: report.Component,
+		Tags:        map[string]string{"error.kind": string(report.Kind)},
+	}
+	if report.Kind == container.ErrorKindStart {
+		e.Level = "fatal"
+	}
+	for key, value := range r.config.Tags {
+		e.Tags[key] = value
+	}
+	if report.Component != "" {
+		e.Tags["component"] = report.Component
+	}
+	for key, value := range report.Metadata {
+		e.Tags[key] = value
+	}
+
+	ex := exception{Type: fmt.Sprintf("%T", report.Err), Value: fmt.Sprint(report.Err)}
+	if report.Panicked {
+		ex.Type = "panic"
+	}
+	ex.Mechanism.Type = "goboot." + string(report.Kind)
+	ex.Mechanism.Handled = !report.Panicked
+	if frames := parseStack(report.Stack); len(frames) > 0 {
+		ex.Stacktrace = &stacktrace{Frames: frames}
+	}
+	e.Exception.Values = []exception{ex}
+	return e
+}
+
+// send posts the event in an envelope
+func (r *Reporter) send(ctx context.Context, e *event) error {
+	var body bytes.Buffer
+	encoder := json.NewEncoder(&body)
+	header := map[string]string{"event_id": e.EventID, "sent_at": time.Now().UTC().Format(time.RFC3339Nano)}
+	if err := encoder.Encode(header); err != nil {
+		return err
+	}
+	if err := encoder.Encode(map[string]string{"type": "event"}); err != nil {
+		return err
+	}
+	if err := encoder.Encode(e); err != nil {
+		return err
+	}
+
+	req, err := http.NewRequestWithContext(ctx, http.MethodPost, r.dsn.EnvelopeURL(), &body)
+	if err != nil {
+		return err
+	}
+	req.Header.Set("Content-Type", "application/x-sentry-envelope")
+	req.Header.Set("X-Sentry-Auth", r.dsn.AuthHeader())
+
+	client := r.Client
+	if client == nil {
+		client = http.DefaultClient
+	}
+	resp, err := client.Do(req)
+	if err != nil {
+		return err
+	}
+	defer resp.Body.Close()
+	_, _ = io.Copy(io.Discard, resp.Body)
+	if resp.StatusCode/100 != 2 {
+		return fmt.Errorf("sentry responded %s", resp.Status)
+	}
+	return nil
+}
+
+// parseStack converts the output of debug.Stack to frames, the outermost call
+// first as Sentry expects
+func parseStack(stack []byte) []frame {
+	lines := strings.Split(strings.TrimSpace(string(stack)), "\n")
+	var frames []frame
+	// The first line is the goroutine header, then a function and a file line per call
+	for i := 1; i+1 < len(lines); i += 2 {
+		// main.run(0x1, ...) or created by main.start in goroutine 7
+		function := strings.TrimPrefix(lines[i], "created by ")
+		if in := strings.Index(function, " in goroutine "); in > 0 {
+			function = function[:in]
+		}
+		if strings.HasSuffix(function, ")") {
+			function = function[:strings.LastIndex(function, "(")]
+		}
+		location := strings.TrimSpace(lines[i+1])
+		if space := strings.LastIndex(location, " +0x"); space > 0 {
+			location = location[:space]
+		}
+		colon := strings.LastIndex(location, ":")
+		if colon < 0 {
+			continue
+		}
+		line, _ := strconv.Atoi(location[colon+1:])
+		frames = append(frames, frame{Function: function, Filename: location[:colon], Lineno: line})
+	}
+	for i, j := 0, len(frames)-1; i < j; i, j = i+1, j-1 {
+		frames[i], frames[j] = frames[j], frames[i]
+	}
+	return frames
+}
+
+// newEventID returns a random event ID of 32 hex digits
+func newEventID() string {
+	var id [16]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// Ensure that Reporter is a lifecycle component reporting errors
+var (
+	_ container.LifecycleComponent = (*Reporter)(nil)
+	_ container.ErrorReporter      = (*Reporter)(nil)
+)
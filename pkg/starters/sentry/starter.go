@@ -0,0 +1,44 @@
+package sentry
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Starter registers the Reporter when sentry.dsn is set, unless sentry.enabled is
+// false, so that the panics of components, scheduled executions and event
+// listeners reach Sentry instead of only the logs:
+//
+//	sentry:
+//	  dsn: https://public@o1.ingest.sentry.io/42
+//	  release: shop@1.4.2
+//	  tags:
+//	    team: checkout
+//
+// The DSN can come from the environment variable SENTRY_DSN.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"SentryStarter",
+		container.AllConditions(
+			container.PropertyExistsCondition(PropertySentry+".dsn"),
+			container.NotCondition(container.PropertyCondition(PropertySentry+".enabled", "false")),
+		),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertySentry, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertySentry, err)
+			}
+			if config.Environment == "" {
+				config.Environment = strings.Join(builder.GetActiveProfiles(), ",")
+			}
+
+			reporter, err := NewReporter(config)
+			if err != nil {
+				return err
+			}
+			return builder.RegisterComponent(reporter)
+		},
+	)
+}
@@ -0,0 +1,334 @@
+package session
+
+import (
+	"context"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/web"
+)
+
+// errNoSession is returned outside of requests served by the SessionManager
+var errNoSession = container.ErrorWithCode("NO_SESSION", "the request isn't served through the session manager")
+
+// CookieConfig configures the session cookie: session.cookie.*
+type CookieConfig struct {
+	// Enabled identifies sessions by the cookie (default true)
+	Enabled *bool `yaml:"enabled"`
+	// Name of the cookie (default SESSION)
+	Name   string `yaml:"name"`
+	Path   string `yaml:"path"`
+	Domain string `yaml:"domain"`
+	// Secure sends the cookie over HTTPS only; always set with SameSite none
+	Secure bool `yaml:"secure"`
+	// HTTPOnly hides the cookie from scripts (default true)
+	HTTPOnly *bool `yaml:"http-only"`
+	// SameSite is lax (default), strict or none
+	SameSite string `yaml:"same-site"`
+}
+
+// Config configures the session manager: session.*
+type Config struct {
+	// Store is memory (default) or redis; ignored if a sessionStore component is registered
+	Store string `yaml:"store"`
+	// Prefix is prepended to the Redis keys (default session:)
+	Prefix string `yaml:"prefix"`
+	// TTL expires sessions that weren't used for that long (default 30m)
+	TTL time.Duration `yaml:"ttl"`
+	// Header identifies sessions by a request header, e.g. X-Session-ID, for clients
+	// without cookies; the ID of new sessions is returned in the same header
+	Header string       `yaml:"header"`
+	Cookie CookieConfig `yaml:"cookie"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Prefix == "" {
+		c.Prefix = "session:"
+	}
+	if c.TTL <= 0 {
+		c.TTL = 30 * time.Minute
+	}
+	if c.Cookie.Enabled == nil {
+		enabled := true
+		c.Cookie.Enabled = &enabled
+	}
+	if c.Cookie.Name == "" {
+		c.Cookie.Name = "SESSION"
+	}
+	if c.Cookie.Path == "" {
+		c.Cookie.Path = "/"
+	}
+	if c.Cookie.HTTPOnly == nil {
+		httpOnly := true
+		c.Cookie.HTTPOnly = &httpOnly
+	}
+	if c.Cookie.SameSite == "" {
+		c.Cookie.SameSite = "lax"
+	}
+	return c
+}
+
+// SessionManager loads the session of each request from the Store and saves it
+// when the response starts. Sessions are created on first use and only stored once
+// they hold a value, so requests that don't need one don't create one.
+type SessionManager struct {
+	config   Config
+	sameSite http.SameSite
+	store    Store
+	logger   *slog.Logger
+}
+
+// NewSessionManager creates a session manager using the sessionStore component
+func NewSessionManager(config Config) *SessionManager {
+	return &SessionManager{config: config.withDefaults(), logger: slog.Default()}
+}
+
+// Name returns the component name
+func (m *SessionManager) Name() string {
+	return "sessionManager"
+}
+
+// Init resolves the store and checks the cookie configuration
+func (m *SessionManager) Init(ctx container.ApplicationContext) error {
+	switch strings.ToLower(m.config.Cookie.SameSite) {
+	case "lax":
+		m.sameSite = http.SameSiteLaxMode
+	case "strict":
+		m.sameSite = http.SameSiteStrictMode
+	case "none":
+		m.sameSite = http.SameSiteNoneMode
+		m.config.Cookie.Secure = true
+	default:
+		return container.ErrorWithCode("INVALID_SESSION_CONFIG", "unknown same-site policy %q", m.config.Cookie.SameSite)
+	}
+	if !*m.config.Cookie.Enabled && m.config.Header == "" {
+		return container.ErrorWithCode("INVALID_SESSION_CONFIG", "sessions need a cookie or a header")
+	}
+
+	store, err := container.GetComponentAs[Store](ctx, StoreName)
+	if err != nil {
+		return err
+	}
+	m.store = store
+	return nil
+}
+
+// Order returns web.OrderSession
+func (m *SessionManager) Order() int {
+	return web.OrderSession
+}
+
+// Get returns the session of a request, loading or creating it on first use
+func (m *SessionManager) Get(r *http.Request) (*Session, error) {
+	return FromContext(r.Context())
+}
+
+// Delete removes a session by ID, e.g. to log a client out from elsewhere
+func (m *SessionManager) Delete(ctx context.Context, id string) error {
+	return m.store.Delete(ctx, id)
+}
+
+// Wrap makes the session of each request available to next through FromContext
+// and saves it with the cookie or header before the response starts. Changes made
+// after the response started are still saved, but a new session's ID can't be sent
+// anymore.
+func (m *SessionManager) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		state := &requestSession{manager: m, id: m.requestID(r)}
+		writer := &sessionWriter{ResponseWriter: w}
+		writer.commit = func() { m.commit(r.Context(), writer, state) }
+
+		next.ServeHTTP(writer, r.WithContext(context.WithValue(r.Context(), sessionKey{}, state)))
+		m.commit(r.Context(), writer, state)
+	})
+}
+
+// requestID returns the session ID sent with the request, preferring the header
+func (m *SessionManager) requestID(r *http.Request) string {
+	if m.config.Header != "" {
+		if id := r.Header.Get(m.config.Header); id != "" {
+			return id
+		}
+	}
+	if *m.config.Cookie.Enabled {
+		if cookie, err := r.Cookie(m.config.Cookie.Name); err == nil {
+			return cookie.Value
+		}
+	}
+	return ""
+}
+
+// commit saves the session if the request used it and, while the headers can
+// still be written, sends its ID or expires its cookie
+func (m *SessionManager) commit(ctx context.Context, w *sessionWriter, state *requestSession) {
+	s := state.loaded()
+	if s == nil {
+		return
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	// A loaded session is saved again on first commit to extend its TTL
+	touch := !s.isNew && !state.touched
+	if !s.dirty && !touch {
+		return
+	}
+
+	// A new session is only stored once it holds a value
+	if s.isNew && len(s.values) == 0 {
+		if s.previousID != "" {
+			m.deleteSession(ctx, s.previousID)
+			if !w.headersWritten {
+				m.writeID(w, "", -1)
+			}
+		}
+		s.previousID, s.dirty = "", false
+		return
+	}
+
+	data, err := json.Marshal(s.values)
+	if err != nil {
+		m.logger.ErrorContext(ctx, "Failed to encode session", "error", err)
+		return
+	}
+	if err := m.store.Save(ctx, s.id, data, m.config.TTL); err != nil {
+		m.logger.ErrorContext(ctx, "Failed to save session", "error", err)
+		return
+	}
+	m.deleteSession(ctx, s.previousID)
+	if !w.headersWritten {
+		m.writeID(w, s.id, int(m.config.TTL.Seconds()))
+	}
+	s.isNew, s.previousID, s.dirty = false, "", false
+	state.touched = true
+}
+
+func (m *SessionManager) deleteSession(ctx context.Context, id string) {
+	if id == "" {
+		return
+	}
+	if err := m.store.Delete(ctx, id); err != nil {
+		m.logger.ErrorContext(ctx, "Failed to delete session", "error", err)
+	}
+}
+
+// writeID sets the cookie and the header carrying the session ID; a negative
+// maxAge expires the cookie
+func (m *SessionManager) writeID(w http.ResponseWriter, id string, maxAge int) {
+	if m.config.Header != "" {
+		w.Header().Set(m.config.Header, id)
+	}
+	if *m.config.Cookie.Enabled {
+		http.SetCookie(w, &http.Cookie{
+			Name:     m.config.Cookie.Name,
+			Value:    id,
+			Path:     m.config.Cookie.Path,
+			Domain:   m.config.Cookie.Domain,
+			MaxAge:   maxAge,
+			Secure:   m.config.Cookie.Secure,
+			HttpOnly: *m.config.Cookie.HTTPOnly,
+			SameSite: m.sameSite,
+		})
+	}
+}
+
+// requestSession loads the session of a request on first use
+type requestSession struct {
+	manager *SessionManager
+	// id is the session ID sent with the request
+	id string
+	// touched is set once the session was saved during the request
+	touched bool
+
+	mu      sync.Mutex
+	session *Session
+}
+
+func (rs *requestSession) get(ctx context.Context) (*Session, error) {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	if rs.session != nil {
+		return rs.session, nil
+	}
+	session, err := rs.load(ctx)
+	if err != nil {
+		return nil, err
+	}
+	rs.session = session
+	return session, nil
+}
+
+func (rs *requestSession) load(ctx context.Context) (*Session, error) {
+	if rs.id == "" {
+		return newSession(), nil
+	}
+	data, err := rs.manager.store.Load(ctx, rs.id)
+	if err != nil {
+		return nil, err
+	}
+	if data == nil {
+		return newSession(), nil
+	}
+	values := make(map[string]any)
+	if err := json.Unmarshal(data, &values); err != nil {
+		rs.manager.logger.WarnContext(ctx, "Discarding undecodable session", "error", err)
+		return newSession(), nil
+	}
+	return &Session{id: rs.id, values: values}, nil
+}
+
+// loaded returns the session if the request used it
+func (rs *requestSession) loaded() *Session {
+	rs.mu.Lock()
+	defer rs.mu.Unlock()
+
+	return rs.session
+}
+
+// sessionWriter commits the session before the response headers are written
+type sessionWriter struct {
+	http.ResponseWriter
+	commit         func()
+	committed      bool
+	headersWritten bool
+}
+
+func (w *sessionWriter) WriteHeader(status int) {
+	w.beforeHeaders()
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *sessionWriter) Write(data []byte) (int, error) {
+	w.beforeHeaders()
+	return w.ResponseWriter.Write(data)
+}
+
+func (w *sessionWriter) beforeHeaders() {
+	if !w.committed {
+		w.committed = true
+		w.commit()
+		w.headersWritten = true
+	}
+}
+
+// Flush supports streaming responses
+func (w *sessionWriter) Flush() {
+	w.beforeHeaders()
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *sessionWriter) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Ensure that SessionManager implements web.Middleware
+var _ web.Middleware = (*SessionManager)(nil)
@@ -0,0 +1,156 @@
+// Package session keeps per-client sessions for the web starter, identified by a
+// cookie or a header and kept in a pluggable Store
+package session
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"sync"
+)
+
+// Session holds the values of one client. Values are stored as JSON, so after a
+// reload numbers are float64 and structs are maps; use Decode to read them back
+// into a type. A Session is safe for concurrent use.
+type Session struct {
+	mu     sync.Mutex
+	id     string
+	values map[string]any
+	isNew  bool
+	// dirty is set by changes not saved yet
+	dirty bool
+	// previousID is the stored ID replaced by Regenerate or Invalidate, deleted on commit
+	previousID string
+}
+
+// newSession creates an empty session with a random ID
+func newSession() *Session {
+	return &Session{id: newID(), values: make(map[string]any), isNew: true}
+}
+
+// ID returns the session ID
+func (s *Session) ID() string {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.id
+}
+
+// IsNew reports whether the session was created by the current request
+func (s *Session) IsNew() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return s.isNew
+}
+
+// Get returns a value of the session
+func (s *Session) Get(key string) (any, bool) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	value, ok := s.values[key]
+	return value, ok
+}
+
+// GetString returns a string value of the session, "" if it is missing or not a string
+func (s *Session) GetString(key string) string {
+	value, _ := s.Get(key)
+	str, _ := value.(string)
+	return str
+}
+
+// Decode reads a value of the session into target through JSON; it reports
+// whether the value exists
+func (s *Session) Decode(key string, target any) (bool, error) {
+	value, ok := s.Get(key)
+	if !ok {
+		return false, nil
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return true, err
+	}
+	return true, json.Unmarshal(data, target)
+}
+
+// Set stores a JSON-encodable value in the session
+func (s *Session) Set(key string, value any) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	s.values[key] = value
+	s.dirty = true
+}
+
+// Delete removes a value of the session
+func (s *Session) Delete(key string) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if _, ok := s.values[key]; ok {
+		delete(s.values, key)
+		s.dirty = true
+	}
+}
+
+// Values returns a copy of the values of the session
+func (s *Session) Values() map[string]any {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	values := make(map[string]any, len(s.values))
+	for key, value := range s.values {
+		values[key] = value
+	}
+	return values
+}
+
+// Regenerate gives the session a new ID keeping its values. Call it when the
+// privileges of the client change, e.g. on login, to prevent session fixation.
+func (s *Session) Regenerate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.previousID == "" && !s.isNew {
+		s.previousID = s.id
+	}
+	s.id = newID()
+	s.dirty = true
+}
+
+// Invalidate removes the session from the store and expires its cookie, e.g. on
+// logout. Values set afterwards go to a new session.
+func (s *Session) Invalidate() {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.previousID == "" && !s.isNew {
+		s.previousID = s.id
+	}
+	s.id = newID()
+	s.values = make(map[string]any)
+	s.isNew = true
+	s.dirty = true
+}
+
+// newID returns 32 random bytes encoded as base64url
+func newID() string {
+	var id [32]byte
+	_, _ = rand.Read(id[:])
+	return base64.RawURLEncoding.EncodeToString(id[:])
+}
+
+// sessionKey is the context key of the request's session state
+type sessionKey struct{}
+
+// FromContext returns the session of a request served through the SessionManager
+// middleware, loading or creating it on first use
+func FromContext(ctx context.Context) (*Session, error) {
+	state, ok := ctx.Value(sessionKey{}).(*requestSession)
+	if !ok {
+		return nil, errNoSession
+	}
+	return state.get(ctx)
+}
@@ -0,0 +1,72 @@
+package session
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/cache"
+)
+
+// PropertySession holds the session configuration: session.*
+const PropertySession = "session"
+
+// Supported stores
+const (
+	StoreMemory = "memory"
+	StoreRedis  = "redis"
+)
+
+// SessionName is the name of the request-scoped session component:
+//
+//	s, err := container.GetScoped[*session.Session](r.Context(), session.SessionName)
+const SessionName = "session"
+
+// Starter registers the SessionManager middleware when session.enabled is true,
+// with a memory or Redis store (session.store) unless a sessionStore component is
+// registered. The Redis store requires a cache.RedisClient component or instance.
+// Handlers get the session with FromContext, the sessionManager component or the
+// request-scoped session component.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"SessionStarter",
+		container.PropertyCondition(PropertySession+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertySession, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertySession, err)
+			}
+			config = config.withDefaults()
+
+			if !builder.HasComponent(StoreName) {
+				store, err := newStore(builder, config)
+				if err != nil {
+					return err
+				}
+				if err := builder.RegisterComponent(store); err != nil {
+					return err
+				}
+			}
+			if err := builder.RegisterComponent(container.NewScoped(SessionName, func(scope *container.Scope) (interface{}, error) {
+				return FromContext(scope.Context())
+			})); err != nil {
+				return err
+			}
+			return builder.RegisterComponent(NewSessionManager(config))
+		},
+	)
+}
+
+func newStore(ctx container.ApplicationContext, config Config) (container.Component, error) {
+	switch config.Store {
+	case "", StoreMemory:
+		return NewMemoryStore(), nil
+	case StoreRedis:
+		var client cache.RedisClient
+		if err := ctx.GetComponent(&client); err != nil {
+			return nil, fmt.Errorf("session: redis store requires a RedisClient: %w", err)
+		}
+		return NewRedisStore(client, config.Prefix), nil
+	default:
+		return nil, fmt.Errorf("session: unknown store %q", config.Store)
+	}
+}
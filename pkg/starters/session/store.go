@@ -0,0 +1,158 @@
+package session
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/cache"
+)
+
+// StoreName is the name of the Store component used by the SessionManager; register
+// a component of that name to plug in another store
+const StoreName = "sessionStore"
+
+// Store keeps the encoded values of sessions by ID
+type Store interface {
+	// Load returns the data of a session, nil if it doesn't exist or expired
+	Load(ctx context.Context, id string) ([]byte, error)
+	// Save stores the data of a session, expiring after ttl
+	Save(ctx context.Context, id string, data []byte, ttl time.Duration) error
+	// Delete removes a session
+	Delete(ctx context.Context, id string) error
+}
+
+// MemoryStore keeps sessions in memory; expired sessions are removed on access and
+// swept at most once a minute
+type MemoryStore struct {
+	mu       sync.Mutex
+	sessions map[string]memoryEntry
+	swept    time.Time
+	now      func() time.Time
+}
+
+type memoryEntry struct {
+	data    []byte
+	expires time.Time
+}
+
+// NewMemoryStore creates an empty in-memory store
+func NewMemoryStore() *MemoryStore {
+	return &MemoryStore{sessions: make(map[string]memoryEntry), now: time.Now}
+}
+
+// Name returns the component name
+func (s *MemoryStore) Name() string {
+	return StoreName
+}
+
+// Init is a no-op
+func (s *MemoryStore) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Load returns the data of an unexpired session
+func (s *MemoryStore) Load(_ context.Context, id string) ([]byte, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	entry, ok := s.sessions[id]
+	if !ok {
+		return nil, nil
+	}
+	if s.now().After(entry.expires) {
+		delete(s.sessions, id)
+		return nil, nil
+	}
+	return entry.data, nil
+}
+
+// Save stores the data of a session
+func (s *MemoryStore) Save(_ context.Context, id string, data []byte, ttl time.Duration) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	now := s.now()
+	if now.Sub(s.swept) > time.Minute {
+		for key, entry := range s.sessions {
+			if now.After(entry.expires) {
+				delete(s.sessions, key)
+			}
+		}
+		s.swept = now
+	}
+	s.sessions[id] = memoryEntry{data: data, expires: now.Add(ttl)}
+	return nil
+}
+
+// Delete removes a session
+func (s *MemoryStore) Delete(_ context.Context, id string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	delete(s.sessions, id)
+	return nil
+}
+
+// Len returns the number of stored sessions, including expired ones not yet swept
+func (s *MemoryStore) Len() int {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	return len(s.sessions)
+}
+
+// RedisStore keeps sessions in Redis under the key <prefix><id>, so that they are
+// shared by all instances
+type RedisStore struct {
+	client cache.RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a store using the RedisClient adapter of the cache starter
+func NewRedisStore(client cache.RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// Name returns the component name
+func (s *RedisStore) Name() string {
+	return StoreName
+}
+
+// Init is a no-op
+func (s *RedisStore) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Load returns the data of a session
+func (s *RedisStore) Load(ctx context.Context, id string) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.prefix+id)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// Save stores the data of a session with the TTL as expiration
+func (s *RedisStore) Save(ctx context.Context, id string, data []byte, ttl time.Duration) error {
+	return s.client.Set(ctx, s.prefix+id, data, ttl)
+}
+
+// Delete removes a session
+func (s *RedisStore) Delete(ctx context.Context, id string) error {
+	return s.client.Del(ctx, s.prefix+id)
+}
+
+// WarmUp checks that Redis is reachable
+func (s *RedisStore) WarmUp(ctx context.Context) error {
+	return cache.Ping(ctx, s.client)
+}
+
+// Ensure that the stores implement Store
+var (
+	_ Store                     = (*MemoryStore)(nil)
+	_ Store                     = (*RedisStore)(nil)
+	_ container.WarmUpComponent = (*RedisStore)(nil)
+)
@@ -0,0 +1,275 @@
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+	"unicode"
+
+	"github.com/01fortes/goboot/pkg/starters/internal/sqldialect"
+)
+
+// Supported SQL dialects
+const (
+	DialectPostgres = sqldialect.Postgres
+	DialectMySQL    = sqldialect.MySQL
+	DialectSQLite   = sqldialect.SQLite
+)
+
+// Queryer is satisfied by *sql.DB, *sql.Tx and *sql.Conn
+type Queryer interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryContext(ctx context.Context, query string, args ...any) (*sql.Rows, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+// Named replaces the :name parameters of a query with the dialect's placeholders
+// and returns the matching arguments, taken from a map[string]any or from the
+// fields of a struct by column name. Quoted text and :: casts are left alone.
+//
+//	query, args, err := sqlrepo.Named(sqlrepo.DialectPostgres,
+//		"UPDATE orders SET status = :status WHERE id = :id", order)
+func Named(dialect, query string, arg any) (string, []any, error) {
+	lookup, err := namedValues(arg)
+	if err != nil {
+		return "", nil, err
+	}
+
+	var out strings.Builder
+	var args []any
+	var quote rune
+	runes := []rune(query)
+	for i := 0; i < len(runes); i++ {
+		r := runes[i]
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case r == ':' && i+1 < len(runes) && runes[i+1] == ':':
+			out.WriteString("::")
+			i++
+			continue
+		case r == ':' && i+1 < len(runes) && isNameRune(runes[i+1]):
+			end := i + 1
+			for end < len(runes) && isNameRune(runes[end]) {
+				end++
+			}
+			name := string(runes[i+1 : end])
+			value, ok := lookup(name)
+			if !ok {
+				return "", nil, fmt.Errorf("no value for query parameter :%s", name)
+			}
+			args = append(args, value)
+			out.WriteString(sqldialect.Placeholder(dialect, len(args)))
+			i = end - 1
+			continue
+		}
+		out.WriteRune(r)
+	}
+	return out.String(), args, nil
+}
+
+func isNameRune(r rune) bool {
+	return r == '_' || unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+// namedValues returns the lookup of the parameters of a map or struct argument
+func namedValues(arg any) (func(string) (any, bool), error) {
+	if arg == nil {
+		return func(string) (any, bool) { return nil, false }, nil
+	}
+	if values, ok := arg.(map[string]any); ok {
+		return func(name string) (any, bool) {
+			value, ok := values[name]
+			return value, ok
+		}, nil
+	}
+
+	rv := reflect.ValueOf(arg)
+	for rv.Kind() == reflect.Pointer && !rv.IsNil() {
+		rv = rv.Elem()
+	}
+	if rv.Kind() != reflect.Struct {
+		return nil, fmt.Errorf("named query arguments must be a map[string]any or a struct, got %T", arg)
+	}
+	columns := columnsOf(rv.Type())
+	return func(name string) (any, bool) {
+		index, ok := columns[name]
+		if !ok {
+			return nil, false
+		}
+		return rv.FieldByIndex(index).Interface(), true
+	}, nil
+}
+
+// columnCache holds the column to field index mapping of struct types
+var columnCache sync.Map // reflect.Type -> map[string][]int
+
+// columnsOf maps the columns of a struct type to field indexes: the db tag of a
+// field, else its snake_case name. Fields tagged db:"-" are skipped and embedded
+// structs contribute their columns.
+func columnsOf(t reflect.Type) map[string][]int {
+	if cached, ok := columnCache.Load(t); ok {
+		return cached.(map[string][]int)
+	}
+
+	columns := make(map[string][]int)
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		tag, _, _ := strings.Cut(field.Tag.Get("db"), ",")
+		if !field.IsExported() || tag == "-" {
+			continue
+		}
+		if field.Anonymous && field.Type.Kind() == reflect.Struct && tag == "" {
+			for column, index := range columnsOf(field.Type) {
+				if _, ok := columns[column]; !ok {
+					columns[column] = append([]int{i}, index...)
+				}
+			}
+			continue
+		}
+		if tag == "" {
+			tag = snakeCase(field.Name)
+		}
+		columns[tag] = []int{i}
+	}
+	columnCache.Store(t, columns)
+	return columns
+}
+
+// snakeCase converts a Go name to snake_case, e.g. CreatedAt to created_at and
+// UserID to user_id
+func snakeCase(name string) string {
+	runes := []rune(name)
+	var out strings.Builder
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			// Start a word at a lower to upper change and at the last capital of an acronym
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				out.WriteByte('_')
+			}
+			r = unicode.ToLower(r)
+		}
+		out.WriteRune(r)
+	}
+	return out.String()
+}
+
+// ScanAll scans every row into a T: a struct whose fields match the columns by
+// column name, or a single-column scalar. Columns without a field are ignored.
+func ScanAll[T any](rows *sql.Rows) ([]T, error) {
+	defer rows.Close()
+
+	columns, err := rows.Columns()
+	if err != nil {
+		return nil, err
+	}
+	var result []T
+	for rows.Next() {
+		var item T
+		if err := scanRow(rows, columns, &item); err != nil {
+			return nil, err
+		}
+		result = append(result, item)
+	}
+	return result, rows.Err()
+}
+
+// ScanOne scans the first row into a T like ScanAll; it returns sql.ErrNoRows if
+// there is none
+func ScanOne[T any](rows *sql.Rows) (T, error) {
+	defer rows.Close()
+
+	var item T
+	columns, err := rows.Columns()
+	if err != nil {
+		return item, err
+	}
+	if !rows.Next() {
+		if err := rows.Err(); err != nil {
+			return item, err
+		}
+		return item, sql.ErrNoRows
+	}
+	if err := scanRow(rows, columns, &item); err != nil {
+		return item, err
+	}
+	return item, rows.Err()
+}
+
+// scanRow scans the current row into target
+func scanRow(rows *sql.Rows, columns []string, target any) error {
+	rv := reflect.ValueOf(target).Elem()
+	if rv.Kind() != reflect.Struct || rv.Type().Implements(scannerType) || reflect.PointerTo(rv.Type()).Implements(scannerType) || rv.Type() == timeType {
+		if len(columns) != 1 {
+			return fmt.Errorf("scan %d columns into %s: a scalar needs exactly one column", len(columns), rv.Type())
+		}
+		return rows.Scan(target)
+	}
+
+	fields := columnsOf(rv.Type())
+	destinations := make([]any, len(columns))
+	for i, column := range columns {
+		index, ok := fields[strings.ToLower(column)]
+		if !ok {
+			destinations[i] = new(any)
+			continue
+		}
+		destinations[i] = rv.FieldByIndex(index).Addr().Interface()
+	}
+	return rows.Scan(destinations...)
+}
+
+var (
+	scannerType = reflect.TypeOf((*sql.Scanner)(nil)).Elem()
+	timeType    = reflect.TypeOf(time.Time{})
+)
+
+// Select runs a named query within the transaction of the context, if any, and
+// scans all rows into T
+//
+//	orders, err := sqlrepo.Select[Order](ctx, txManager,
+//		"SELECT * FROM orders WHERE customer_id = :customer", map[string]any{"customer": id})
+func Select[T any](ctx context.Context, m *TxManager, query string, arg any) ([]T, error) {
+	query, args, err := Named(m.dialect, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	rows, err := m.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		return nil, err
+	}
+	return ScanAll[T](rows)
+}
+
+// Get runs a named query like Select and scans the first row into T; it returns
+// sql.ErrNoRows if there is none
+func Get[T any](ctx context.Context, m *TxManager, query string, arg any) (T, error) {
+	query, args, err := Named(m.dialect, query, arg)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	rows, err := m.Conn(ctx).QueryContext(ctx, query, args...)
+	if err != nil {
+		var zero T
+		return zero, err
+	}
+	return ScanOne[T](rows)
+}
+
+// Exec runs a named statement within the transaction of the context, if any
+func (m *TxManager) Exec(ctx context.Context, query string, arg any) (sql.Result, error) {
+	query, args, err := Named(m.dialect, query, arg)
+	if err != nil {
+		return nil, err
+	}
+	return m.Conn(ctx).ExecContext(ctx, query, args...)
+}
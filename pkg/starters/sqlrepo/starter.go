@@ -0,0 +1,51 @@
+package sqlrepo
+
+import (
+	"database/sql"
+	"fmt"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertySQL holds the transaction manager configuration: sql.*
+const PropertySQL = "sql"
+
+// Config configures the transaction manager
+type Config struct {
+	// Dialect selects the placeholders of named queries: postgres, mysql or sqlite
+	Dialect string `yaml:"dialect"`
+	// TxTimeout is the default timeout of new transactions (default none)
+	TxTimeout time.Duration `yaml:"tx-timeout"`
+}
+
+// Starter registers a TxManager when sql.enabled is true. It needs the *sql.DB of
+// the datasource starter or an instance registered in the setup block:
+//
+//	builder.RegisterInstance("db", db)
+//
+// Repositories get the txManager component and run their queries through it, so
+// that they join the transaction of the calling service.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"SQLStarter",
+		container.PropertyCondition(PropertySQL+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertySQL, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertySQL, err)
+			}
+			switch config.Dialect {
+			case DialectPostgres, DialectMySQL, DialectSQLite:
+			default:
+				return fmt.Errorf("unsupported %s.dialect %q", PropertySQL, config.Dialect)
+			}
+
+			var db *sql.DB
+			if err := builder.GetComponent(&db); err != nil {
+				return fmt.Errorf("transaction manager requires a *sql.DB: %w", err)
+			}
+			return builder.RegisterComponent(NewTxManager(db, config.Dialect, config.TxTimeout))
+		},
+	)
+}
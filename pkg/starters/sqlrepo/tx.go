@@ -0,0 +1,321 @@
+// Package sqlrepo shares transaction handling between the data-access components
+// of an application: a TxManager running functions within transactions carried by
+// the context, and helpers running named queries and scanning rows into structs
+package sqlrepo
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"log/slog"
+	"sync/atomic"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/datasource"
+)
+
+// Propagation decides how WithinTx behaves when the context already carries a transaction
+type Propagation int
+
+const (
+	// PropagationRequired joins the current transaction or begins one (default)
+	PropagationRequired Propagation = iota
+	// PropagationRequiresNew always begins an independent transaction
+	PropagationRequiresNew
+	// PropagationNested runs within a savepoint of the current transaction, so
+	// that a failure only rolls the function's changes back, or begins one
+	PropagationNested
+	// PropagationMandatory joins the current transaction and fails without one
+	PropagationMandatory
+	// PropagationSupports joins the current transaction or runs without one
+	PropagationSupports
+	// PropagationNever fails if there is a current transaction
+	PropagationNever
+)
+
+// Errors of WithinTx
+var (
+	// ErrNoTransaction is returned by PropagationMandatory without a current transaction
+	ErrNoTransaction = container.ErrorWithCode("NO_TRANSACTION", "a transaction is required")
+	// ErrExistingTransaction is returned by PropagationNever within a transaction
+	ErrExistingTransaction = container.ErrorWithCode("EXISTING_TRANSACTION", "no transaction is allowed")
+	// ErrRollbackOnly is returned when a function that joined the transaction failed,
+	// so the transaction was rolled back although the outermost function succeeded
+	ErrRollbackOnly = container.ErrorWithCode("TRANSACTION_ROLLBACK_ONLY", "the transaction was marked rollback-only and rolled back")
+)
+
+// txConfig holds the options of WithinTx
+type txConfig struct {
+	propagation Propagation
+	isolation   sql.IsolationLevel
+	readOnly    bool
+	timeout     time.Duration
+}
+
+// TxOption configures WithinTx
+type TxOption func(*txConfig)
+
+// WithPropagation sets how the function relates to the current transaction
+func WithPropagation(propagation Propagation) TxOption {
+	return func(c *txConfig) { c.propagation = propagation }
+}
+
+// WithIsolation sets the isolation level of a new transaction
+func WithIsolation(level sql.IsolationLevel) TxOption {
+	return func(c *txConfig) { c.isolation = level }
+}
+
+// ReadOnly begins new transactions read-only, on a replica if the dataSource
+// component routes reads
+func ReadOnly() TxOption {
+	return func(c *txConfig) { c.readOnly = true }
+}
+
+// WithTimeout bounds a new transaction; it is rolled back when the timeout expires
+func WithTimeout(timeout time.Duration) TxOption {
+	return func(c *txConfig) { c.timeout = timeout }
+}
+
+// txState is the transaction carried by a context
+type txState struct {
+	tx           *sql.Tx
+	rollbackOnly atomic.Bool
+	savepoints   atomic.Int64
+	afterCommit  []func()
+}
+
+// txKey is the context key of the current transaction
+type txKey struct{}
+
+// TxFromContext returns the transaction of the context
+func TxFromContext(ctx context.Context) (*sql.Tx, bool) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		return nil, false
+	}
+	return state.tx, true
+}
+
+// AfterCommit runs fn once the current transaction committed, e.g. to publish
+// events about its changes; without a transaction fn runs immediately
+func AfterCommit(ctx context.Context, fn func()) {
+	state, ok := ctx.Value(txKey{}).(*txState)
+	if !ok {
+		fn()
+		return
+	}
+	state.afterCommit = append(state.afterCommit, fn)
+}
+
+// TxManager runs functions within transactions of a *sql.DB. The transaction is
+// carried by the context passed to the function, so the repositories it calls
+// use it through Conn or the query helpers and nested WithinTx calls join it.
+type TxManager struct {
+	db      *sql.DB
+	router  Router
+	dialect string
+	timeout time.Duration
+	logger  *slog.Logger
+}
+
+// NewTxManager creates a transaction manager for db; the dialect selects the
+// placeholders of named queries and timeout is the default transaction timeout
+// (0 means none)
+func NewTxManager(db *sql.DB, dialect string, timeout time.Duration) *TxManager {
+	return &TxManager{db: db, dialect: dialect, timeout: timeout, logger: slog.Default()}
+}
+
+// Name returns the component name
+func (m *TxManager) Name() string {
+	return "txManager"
+}
+
+// Router selects the database of the operations of a context, e.g. the
+// datasource.RoutingDataSource
+type Router interface {
+	DB(ctx context.Context) *sql.DB
+}
+
+// Init routes the operations through the dataSource component if it is a Router
+func (m *TxManager) Init(ctx container.ApplicationContext) error {
+	if !ctx.HasComponent(datasource.DataSourceName) {
+		return nil
+	}
+	component, err := ctx.GetComponentByName(datasource.DataSourceName)
+	if err != nil {
+		return err
+	}
+	if router, ok := component.(Router); ok {
+		m.router = router
+	}
+	return nil
+}
+
+// DB returns the database
+func (m *TxManager) DB() *sql.DB {
+	return m.db
+}
+
+// Dialect returns the SQL dialect of named queries
+func (m *TxManager) Dialect() string {
+	return m.dialect
+}
+
+// Conn returns the transaction of the context, else the database; with a Router,
+// a replica if the context is read-only (see datasource.WithReadOnly)
+func (m *TxManager) Conn(ctx context.Context) Queryer {
+	if tx, ok := TxFromContext(ctx); ok {
+		return tx
+	}
+	return m.route(ctx, false)
+}
+
+// route returns the database of the context; read-only transactions go to a
+// replica like read-only contexts
+func (m *TxManager) route(ctx context.Context, readOnly bool) *sql.DB {
+	if m.router == nil {
+		return m.db
+	}
+	if readOnly {
+		ctx = datasource.WithReadOnly(ctx)
+	}
+	return m.router.DB(ctx)
+}
+
+// WithinTx runs fn within a transaction as selected by the propagation, committing
+// it if fn succeeds and rolling it back if fn fails or panics. A function joining
+// the current transaction that fails marks it rollback-only.
+//
+//	err := txManager.WithinTx(ctx, func(ctx context.Context, tx *sql.Tx) error {
+//		if err := orders.Insert(ctx, order); err != nil {
+//			return err
+//		}
+//		return stock.Reserve(ctx, order.Items)
+//	})
+func (m *TxManager) WithinTx(ctx context.Context, fn func(ctx context.Context, tx *sql.Tx) error, opts ...TxOption) error {
+	config := txConfig{timeout: m.timeout}
+	for _, opt := range opts {
+		opt(&config)
+	}
+	current, active := ctx.Value(txKey{}).(*txState)
+
+	switch config.propagation {
+	case PropagationRequired:
+		if active {
+			return m.join(ctx, current, fn)
+		}
+	case PropagationRequiresNew:
+	case PropagationNested:
+		if active {
+			return m.savepoint(ctx, current, fn)
+		}
+	case PropagationMandatory:
+		if !active {
+			return ErrNoTransaction
+		}
+		return m.join(ctx, current, fn)
+	case PropagationSupports:
+		if active {
+			return m.join(ctx, current, fn)
+		}
+		return fn(ctx, nil)
+	case PropagationNever:
+		if active {
+			return ErrExistingTransaction
+		}
+		return fn(ctx, nil)
+	default:
+		return fmt.Errorf("unknown transaction propagation %d", config.propagation)
+	}
+	return m.begin(ctx, config, fn)
+}
+
+// begin runs fn within a new transaction
+func (m *TxManager) begin(ctx context.Context, config txConfig, fn func(context.Context, *sql.Tx) error) (err error) {
+	if config.timeout > 0 {
+		var cancel context.CancelFunc
+		ctx, cancel = context.WithTimeout(ctx, config.timeout)
+		defer cancel()
+	}
+	tx, err := m.route(ctx, config.readOnly).BeginTx(ctx, &sql.TxOptions{Isolation: config.isolation, ReadOnly: config.readOnly})
+	if err != nil {
+		return fmt.Errorf("begin transaction: %w", err)
+	}
+	state := &txState{tx: tx}
+
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			m.rollback(ctx, tx)
+			panic(recovered)
+		}
+	}()
+	if err := fn(context.WithValue(ctx, txKey{}, state), tx); err != nil {
+		m.rollback(ctx, tx)
+		return err
+	}
+	if state.rollbackOnly.Load() {
+		m.rollback(ctx, tx)
+		return ErrRollbackOnly
+	}
+	if err := tx.Commit(); err != nil {
+		return fmt.Errorf("commit transaction: %w", err)
+	}
+	for _, hook := range state.afterCommit {
+		hook()
+	}
+	return nil
+}
+
+// join runs fn within the current transaction, marking it rollback-only if fn fails
+func (m *TxManager) join(ctx context.Context, state *txState, fn func(context.Context, *sql.Tx) error) error {
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			state.rollbackOnly.Store(true)
+			panic(recovered)
+		}
+	}()
+	if err := fn(ctx, state.tx); err != nil {
+		state.rollbackOnly.Store(true)
+		return err
+	}
+	return nil
+}
+
+// savepoint runs fn within a savepoint of the current transaction, rolling back to
+// it if fn fails
+func (m *TxManager) savepoint(ctx context.Context, state *txState, fn func(context.Context, *sql.Tx) error) error {
+	name := fmt.Sprintf("sp_%d", state.savepoints.Add(1))
+	if _, err := state.tx.ExecContext(ctx, "SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("create savepoint: %w", err)
+	}
+
+	rollback := func() {
+		if _, err := state.tx.ExecContext(ctx, "ROLLBACK TO SAVEPOINT "+name); err != nil {
+			// The transaction can't be trusted anymore
+			state.rollbackOnly.Store(true)
+			m.logger.ErrorContext(ctx, "Failed to roll back to savepoint", "savepoint", name, "error", err)
+		}
+	}
+	defer func() {
+		if recovered := recover(); recovered != nil {
+			rollback()
+			panic(recovered)
+		}
+	}()
+	if err := fn(ctx, state.tx); err != nil {
+		rollback()
+		return err
+	}
+	if _, err := state.tx.ExecContext(ctx, "RELEASE SAVEPOINT "+name); err != nil {
+		return fmt.Errorf("release savepoint: %w", err)
+	}
+	return nil
+}
+
+func (m *TxManager) rollback(ctx context.Context, tx *sql.Tx) {
+	if err := tx.Rollback(); err != nil && !errors.Is(err, sql.ErrTxDone) {
+		m.logger.ErrorContext(ctx, "Failed to roll back transaction", "error", err)
+	}
+}
@@ -0,0 +1,34 @@
+// Package sqlxdb registers a *sqlx.DB over the *sql.DB of the datasource starter,
+// for applications standardized on sqlx
+package sqlxdb
+
+import (
+	"database/sql"
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/datasource"
+	"github.com/jmoiron/sqlx"
+)
+
+// SqlxName is the *sqlx.DB instance
+const SqlxName = "sqlxDB"
+
+// Starter registers a *sqlx.DB over the datasource's *sql.DB when
+// datasource.sqlx.enabled is true, so that it shares the pool, the health check
+// and the pool metrics of the DataSource component. Register it after
+// datasource.Starter.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"SqlxStarter",
+		container.PropertyCondition(datasource.PropertyDataSource+".sqlx.enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			db, err := container.GetComponentAs[*sql.DB](builder, datasource.DBName)
+			if err != nil {
+				return fmt.Errorf("sqlx requires the datasource: %w", err)
+			}
+			driver := container.NewVariableHelper(builder).GetString(datasource.PropertyDataSource+".driver", "")
+			return builder.RegisterInstance(SqlxName, sqlx.NewDb(db, driver))
+		},
+	)
+}
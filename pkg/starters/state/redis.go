@@ -0,0 +1,42 @@
+// Package state provides Redis and SQL implementations of container.StateStore,
+// keeping the state of stateful components across restarts of any instance:
+//
+//	cfg := container.DefaultConfig()
+//	cfg.StateStore = state.NewSQLStore(db, "component_state", state.DialectPostgres)
+package state
+
+import (
+	"context"
+	"errors"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/cache"
+)
+
+// RedisStore keeps the state of each component under the key <prefix><name>
+type RedisStore struct {
+	client cache.RedisClient
+	prefix string
+}
+
+// NewRedisStore creates a store using the RedisClient adapter of the cache starter
+func NewRedisStore(client cache.RedisClient, prefix string) *RedisStore {
+	return &RedisStore{client: client, prefix: prefix}
+}
+
+// LoadState returns the saved state of a component, nil if there is none
+func (s *RedisStore) LoadState(ctx context.Context, name string) ([]byte, error) {
+	data, err := s.client.Get(ctx, s.prefix+name)
+	if errors.Is(err, cache.ErrNotFound) {
+		return nil, nil
+	}
+	return data, err
+}
+
+// SaveState stores the state of a component without expiration
+func (s *RedisStore) SaveState(ctx context.Context, name string, data []byte) error {
+	return s.client.Set(ctx, s.prefix+name, data, 0)
+}
+
+// Ensure that RedisStore implements container.StateStore
+var _ container.StateStore = (*RedisStore)(nil)
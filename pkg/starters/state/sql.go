@@ -0,0 +1,85 @@
+package state
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/internal/sqldialect"
+)
+
+// Supported SQL dialects
+const (
+	DialectPostgres = sqldialect.Postgres
+	DialectMySQL    = sqldialect.MySQL
+	DialectSQLite   = sqldialect.SQLite
+)
+
+// SQLStore keeps the state of each component in a row of a table; create it with Schema
+type SQLStore struct {
+	db      *sql.DB
+	table   string
+	dialect string
+}
+
+// NewSQLStore creates a store using the dialect's placeholders and upsert syntax
+func NewSQLStore(db *sql.DB, table, dialect string) *SQLStore {
+	return &SQLStore{db: db, table: table, dialect: dialect}
+}
+
+// LoadState returns the saved state of a component, nil if there is none
+func (s *SQLStore) LoadState(ctx context.Context, name string) ([]byte, error) {
+	query := fmt.Sprintf("SELECT data FROM %s WHERE name = %s", s.table, sqldialect.Placeholder(s.dialect, 1))
+	var data []byte
+	err := s.db.QueryRowContext(ctx, query, name).Scan(&data)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("load state of %s: %w", name, err)
+	}
+	return data, nil
+}
+
+// SaveState inserts or replaces the state of a component
+func (s *SQLStore) SaveState(ctx context.Context, name string, data []byte) error {
+	var query string
+	switch s.dialect {
+	case DialectMySQL:
+		query = fmt.Sprintf("INSERT INTO %s (name, data, saved_at) VALUES (?, ?, ?) ON DUPLICATE KEY UPDATE data = VALUES(data), saved_at = VALUES(saved_at)", s.table)
+	default:
+		query = fmt.Sprintf("INSERT INTO %s (name, data, saved_at) VALUES (%s, %s, %s) ON CONFLICT (name) DO UPDATE SET data = excluded.data, saved_at = excluded.saved_at",
+			s.table, sqldialect.Placeholder(s.dialect, 1), sqldialect.Placeholder(s.dialect, 2), sqldialect.Placeholder(s.dialect, 3))
+	}
+	if _, err := s.db.ExecContext(ctx, query, name, data, time.Now().UTC()); err != nil {
+		return fmt.Errorf("save state of %s: %w", name, err)
+	}
+	return nil
+}
+
+// Schema returns the DDL creating the state table for a dialect
+func Schema(table, dialect string) (string, error) {
+	var data, timestamp string
+	switch dialect {
+	case DialectPostgres:
+		data, timestamp = "BYTEA", "TIMESTAMPTZ"
+	case DialectMySQL:
+		data, timestamp = "LONGBLOB", "DATETIME(6)"
+	case DialectSQLite:
+		data, timestamp = "BLOB", "TIMESTAMP"
+	default:
+		return "", fmt.Errorf("unsupported state dialect %q", dialect)
+	}
+
+	return fmt.Sprintf(`CREATE TABLE IF NOT EXISTS %s (
+	name VARCHAR(255) PRIMARY KEY,
+	data %s NOT NULL,
+	saved_at %s NOT NULL
+)`, table, data, timestamp), nil
+}
+
+// Ensure that SQLStore implements container.StateStore
+var _ container.StateStore = (*SQLStore)(nil)
@@ -0,0 +1,40 @@
+// Package storage stores blobs in S3-compatible object storage (AWS S3, MinIO,
+// Cloudflare R2, ...) behind the BlobStore abstraction
+package storage
+
+import (
+	"context"
+	"errors"
+	"io"
+	"time"
+)
+
+// ErrNotFound is returned when an object doesn't exist
+var ErrNotFound = errors.New("object not found")
+
+// BlobStoreName is the BlobStore component
+const BlobStoreName = "blobStore"
+
+// Object describes a stored object
+type Object struct {
+	Key          string
+	Size         int64
+	ContentType  string
+	ETag         string
+	LastModified time.Time
+}
+
+// BlobStore stores objects by key
+type BlobStore interface {
+	// Put stores the content of body under key, replacing any existing object
+	Put(ctx context.Context, key string, body io.Reader, contentType string) error
+	// Get returns the content of an object, which the caller closes, or ErrNotFound
+	Get(ctx context.Context, key string) (io.ReadCloser, Object, error)
+	// Delete removes an object; deleting a missing object succeeds
+	Delete(ctx context.Context, key string) error
+	// List returns the objects whose key starts with prefix, sorted by key
+	List(ctx context.Context, prefix string) ([]Object, error)
+	// SignedURL returns a URL granting method (GET or PUT) on key to anyone holding
+	// it until expiry elapses
+	SignedURL(ctx context.Context, method, key string, expiry time.Duration) (string, error)
+}
@@ -0,0 +1,386 @@
+package storage
+
+import (
+	"bytes"
+	"context"
+	"encoding/xml"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/internal/awsv4"
+)
+
+// S3Config configures the S3 client: storage.s3.*
+type S3Config struct {
+	// Endpoint is the URL of the service, e.g. http://localhost:9000 for MinIO
+	// (default https://s3.<region>.amazonaws.com)
+	Endpoint string `yaml:"endpoint"`
+	// Region signs the requests (default AWS_REGION, else us-east-1)
+	Region string `yaml:"region"`
+	Bucket string `yaml:"bucket"`
+	// AccessKey, SecretKey and SessionToken default to AWS_ACCESS_KEY_ID,
+	// AWS_SECRET_ACCESS_KEY and AWS_SESSION_TOKEN
+	AccessKey    string `yaml:"access-key"`
+	SecretKey    string `yaml:"secret-key"`
+	SessionToken string `yaml:"session-token"`
+	// PathStyle addresses the bucket in the path (endpoint/bucket/key) instead of
+	// the host name (bucket.endpoint/key), as MinIO usually requires
+	PathStyle bool `yaml:"path-style"`
+	// SignedURLExpiry is the default lifetime of signed URLs (default 15m)
+	SignedURLExpiry time.Duration `yaml:"signed-url-expiry"`
+	// HealthTimeout bounds health checks (default 2s)
+	HealthTimeout time.Duration `yaml:"health-timeout"`
+}
+
+func (c S3Config) withDefaults() S3Config {
+	if c.Region == "" {
+		c.Region = os.Getenv("AWS_REGION")
+	}
+	if c.Region == "" {
+		c.Region = "us-east-1"
+	}
+	if c.Endpoint == "" {
+		c.Endpoint = "https://s3." + c.Region + ".amazonaws.com"
+	}
+	if c.AccessKey == "" && c.SecretKey == "" {
+		env := awsv4.CredentialsFromEnv()
+		c.AccessKey, c.SecretKey = env.AccessKey, env.SecretKey
+		if c.SessionToken == "" {
+			c.SessionToken = env.SessionToken
+		}
+	}
+	if c.SignedURLExpiry <= 0 {
+		c.SignedURLExpiry = 15 * time.Minute
+	}
+	if c.HealthTimeout <= 0 {
+		c.HealthTimeout = 2 * time.Second
+	}
+	return c
+}
+
+// maxSignedURLExpiry is the longest lifetime S3 accepts for signed URLs
+const maxSignedURLExpiry = 7 * 24 * time.Hour
+
+// S3Error is an error response of the service
+type S3Error struct {
+	StatusCode int
+	Code       string `xml:"Code"`
+	Message    string `xml:"Message"`
+}
+
+// Error returns the status and the S3 error code
+func (e *S3Error) Error() string {
+	if e.Code == "" {
+		return fmt.Sprintf("s3: status %d", e.StatusCode)
+	}
+	return fmt.Sprintf("s3: status %d: %s: %s", e.StatusCode, e.Code, e.Message)
+}
+
+// S3Store is a BlobStore keeping the objects in a bucket of an S3-compatible
+// service. Requests are signed with AWS Signature Version 4; their duration is
+// bounded by the context.
+type S3Store struct {
+	config   S3Config
+	endpoint *url.URL
+	signer   awsv4.Signer
+	client   *http.Client
+	now      func() time.Time
+}
+
+// NewS3Store creates a store for the bucket of the configuration
+func NewS3Store(config S3Config) (*S3Store, error) {
+	config = config.withDefaults()
+	if config.Bucket == "" {
+		return nil, fmt.Errorf("storage.s3.bucket is required")
+	}
+	if config.AccessKey == "" || config.SecretKey == "" {
+		return nil, fmt.Errorf("storage.s3 credentials are required: set access-key and secret-key or AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY")
+	}
+	endpoint, err := url.Parse(config.Endpoint)
+	if err != nil || endpoint.Host == "" {
+		return nil, fmt.Errorf("invalid storage.s3.endpoint %q", config.Endpoint)
+	}
+
+	return &S3Store{
+		config:   config,
+		endpoint: endpoint,
+		signer: awsv4.Signer{
+			Credentials: awsv4.Credentials{AccessKey: config.AccessKey, SecretKey: config.SecretKey, SessionToken: config.SessionToken},
+			Region:      config.Region,
+			Service:     "s3",
+		},
+		client: &http.Client{},
+		now:    time.Now,
+	}, nil
+}
+
+// Name returns the component name
+func (s *S3Store) Name() string {
+	return BlobStoreName
+}
+
+// Init is a no-op
+func (s *S3Store) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Bucket returns the name of the bucket
+func (s *S3Store) Bucket() string {
+	return s.config.Bucket
+}
+
+// Put uploads an object. The size of body is taken from its Len method or by
+// seeking; other readers are buffered in memory first.
+func (s *S3Store) Put(ctx context.Context, key string, body io.Reader, contentType string) error {
+	body, size, err := sized(body)
+	if err != nil {
+		return err
+	}
+	req, err := s.request(ctx, http.MethodPut, key, nil, body)
+	if err != nil {
+		return err
+	}
+	req.ContentLength = size
+	if contentType != "" {
+		req.Header.Set("Content-Type", contentType)
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// Get downloads an object
+func (s *S3Store) Get(ctx context.Context, key string) (io.ReadCloser, Object, error) {
+	req, err := s.request(ctx, http.MethodGet, key, nil, nil)
+	if err != nil {
+		return nil, Object{}, err
+	}
+	resp, err := s.do(req)
+	if err != nil {
+		return nil, Object{}, err
+	}
+
+	object := Object{
+		Key:         key,
+		Size:        resp.ContentLength,
+		ContentType: resp.Header.Get("Content-Type"),
+		ETag:        strings.Trim(resp.Header.Get("ETag"), `"`),
+	}
+	if modified, err := http.ParseTime(resp.Header.Get("Last-Modified")); err == nil {
+		object.LastModified = modified
+	}
+	return resp.Body, object, nil
+}
+
+// Delete removes an object
+func (s *S3Store) Delete(ctx context.Context, key string) error {
+	req, err := s.request(ctx, http.MethodDelete, key, nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return nil
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// listResult is a page of ListObjectsV2
+type listResult struct {
+	IsTruncated           bool   `xml:"IsTruncated"`
+	NextContinuationToken string `xml:"NextContinuationToken"`
+	Contents              []struct {
+		Key          string    `xml:"Key"`
+		Size         int64     `xml:"Size"`
+		ETag         string    `xml:"ETag"`
+		LastModified time.Time `xml:"LastModified"`
+	} `xml:"Contents"`
+}
+
+// List returns the objects under a prefix, following the pages of the listing.
+// Content types aren't part of listings and are left empty.
+func (s *S3Store) List(ctx context.Context, prefix string) ([]Object, error) {
+	var objects []Object
+	token := ""
+	for {
+		query := url.Values{"list-type": {"2"}, "prefix": {prefix}}
+		if token != "" {
+			query.Set("continuation-token", token)
+		}
+		req, err := s.request(ctx, http.MethodGet, "", query, nil)
+		if err != nil {
+			return nil, err
+		}
+		resp, err := s.do(req)
+		if err != nil {
+			return nil, err
+		}
+		var page listResult
+		err = xml.NewDecoder(resp.Body).Decode(&page)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("decode s3 listing: %w", err)
+		}
+
+		for _, content := range page.Contents {
+			objects = append(objects, Object{
+				Key:          content.Key,
+				Size:         content.Size,
+				ETag:         strings.Trim(content.ETag, `"`),
+				LastModified: content.LastModified,
+			})
+		}
+		if !page.IsTruncated || page.NextContinuationToken == "" {
+			return objects, nil
+		}
+		token = page.NextContinuationToken
+	}
+}
+
+// SignedURL returns a presigned URL for GET or PUT on key; a zero expiry uses
+// storage.s3.signed-url-expiry
+func (s *S3Store) SignedURL(_ context.Context, method, key string, expiry time.Duration) (string, error) {
+	method = strings.ToUpper(method)
+	if method != http.MethodGet && method != http.MethodPut {
+		return "", fmt.Errorf("signed URLs support GET and PUT, not %s", method)
+	}
+	if expiry <= 0 {
+		expiry = s.config.SignedURLExpiry
+	}
+	if expiry > maxSignedURLExpiry {
+		return "", fmt.Errorf("signed URLs expire within %s", maxSignedURLExpiry)
+	}
+	return s.signer.Presign(method, s.objectURL(key, nil), expiry, s.now()), nil
+}
+
+// CheckHealth checks that the bucket is reachable with the credentials
+func (s *S3Store) CheckHealth(ctx context.Context) container.Health {
+	ctx, cancel := context.WithTimeout(ctx, s.config.HealthTimeout)
+	defer cancel()
+
+	details := map[string]interface{}{"endpoint": s.endpoint.Host, "bucket": s.config.Bucket}
+	if err := s.headBucket(ctx); err != nil {
+		details["error"] = err.Error()
+		return container.Health{Status: container.HealthDown, Details: details}
+	}
+	return container.Health{Status: container.HealthUp, Details: details}
+}
+
+// WarmUp checks that the bucket is reachable with the credentials
+func (s *S3Store) WarmUp(ctx context.Context) error {
+	return s.headBucket(ctx)
+}
+
+func (s *S3Store) headBucket(ctx context.Context) error {
+	req, err := s.request(ctx, http.MethodHead, "", nil, nil)
+	if err != nil {
+		return err
+	}
+	resp, err := s.do(req)
+	if errors.Is(err, ErrNotFound) {
+		return fmt.Errorf("bucket %s not found", s.config.Bucket)
+	}
+	if err != nil {
+		return err
+	}
+	return resp.Body.Close()
+}
+
+// objectURL returns the URL of a key, or of the bucket for an empty key
+func (s *S3Store) objectURL(key string, query url.Values) *url.URL {
+	u := *s.endpoint
+	path := strings.TrimSuffix(u.Path, "/")
+	if s.config.PathStyle {
+		path += "/" + s.config.Bucket
+	} else {
+		u.Host = s.config.Bucket + "." + u.Host
+	}
+	path += "/" + key
+
+	u.Path = path
+	segments := strings.Split(path, "/")
+	for i, segment := range segments {
+		segments[i] = awsv4.URIEncode(segment)
+	}
+	u.RawPath = strings.Join(segments, "/")
+	u.RawQuery = awsv4.CanonicalQuery(query)
+	return &u
+}
+
+// request creates a signed request
+func (s *S3Store) request(ctx context.Context, method, key string, query url.Values, body io.Reader) (*http.Request, error) {
+	req, err := http.NewRequestWithContext(ctx, method, s.objectURL(key, query).String(), body)
+	if err != nil {
+		return nil, err
+	}
+	s.signer.Sign(req, awsv4.UnsignedPayload, s.now())
+	return req, nil
+}
+
+// do sends a request, returning ErrNotFound for 404 and an S3Error for other
+// failures
+func (s *S3Store) do(req *http.Request) (*http.Response, error) {
+	resp, err := s.client.Do(req)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode < 300 {
+		return resp, nil
+	}
+	defer resp.Body.Close()
+
+	if resp.StatusCode == http.StatusNotFound {
+		return nil, ErrNotFound
+	}
+	s3Err := &S3Error{StatusCode: resp.StatusCode}
+	data, _ := io.ReadAll(io.LimitReader(resp.Body, 64<<10))
+	_ = xml.Unmarshal(data, s3Err)
+	return nil, s3Err
+}
+
+// sized returns body with its size, buffering readers of unknown size
+func sized(body io.Reader) (io.Reader, int64, error) {
+	switch b := body.(type) {
+	case nil:
+		return http.NoBody, 0, nil
+	case interface{ Len() int }:
+		return body, int64(b.Len()), nil
+	case io.Seeker:
+		current, err := b.Seek(0, io.SeekCurrent)
+		if err != nil {
+			break
+		}
+		end, err := b.Seek(0, io.SeekEnd)
+		if err != nil {
+			break
+		}
+		if _, err := b.Seek(current, io.SeekStart); err != nil {
+			return nil, 0, err
+		}
+		return body, end - current, nil
+	}
+	data, err := io.ReadAll(body)
+	if err != nil {
+		return nil, 0, err
+	}
+	return bytes.NewReader(data), int64(len(data)), nil
+}
+
+// Ensure that S3Store implements BlobStore, HealthIndicator and WarmUpComponent
+var (
+	_ BlobStore                 = (*S3Store)(nil)
+	_ container.HealthIndicator = (*S3Store)(nil)
+	_ container.WarmUpComponent = (*S3Store)(nil)
+)
@@ -0,0 +1,43 @@
+package storage
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyS3 holds the S3 configuration: storage.s3.*
+const PropertyS3 = "storage.s3"
+
+// Starter registers an S3Store as the blobStore component when
+// storage.s3.enabled is true, unless a blobStore is already registered:
+//
+//	storage:
+//	  s3:
+//	    enabled: true
+//	    endpoint: http://localhost:9000
+//	    bucket: uploads
+//	    path-style: true
+//
+// The credentials default to AWS_ACCESS_KEY_ID and AWS_SECRET_ACCESS_KEY. The
+// store reports the reachability of the bucket as its health.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"StorageStarter",
+		container.PropertyCondition(PropertyS3+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			if builder.HasComponent(BlobStoreName) {
+				return nil
+			}
+			var config S3Config
+			if err := builder.GetVariableAs(PropertyS3, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyS3, err)
+			}
+			store, err := NewS3Store(config)
+			if err != nil {
+				return err
+			}
+			return builder.RegisterComponent(store)
+		},
+	)
+}
@@ -0,0 +1,285 @@
+// Package templates renders html/template and text/template templates loaded
+// from a directory or an embed.FS
+package templates
+
+import (
+	"bytes"
+	htmltemplate "html/template"
+	"io"
+	"io/fs"
+	"log/slog"
+	"net/http"
+	"os"
+	"path"
+	"sort"
+	"strings"
+	"sync"
+	texttemplate "text/template"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Config configures the renderer: templates.*
+type Config struct {
+	// Directory holds the templates (default templates)
+	Directory string `yaml:"directory"`
+	// Layouts is the subdirectory whose templates are parsed with every template,
+	// e.g. base layouts and partials (default layouts)
+	Layouts string `yaml:"layouts"`
+	// HTMLExtensions are parsed with html/template (default .html, .gohtml)
+	HTMLExtensions []string `yaml:"html-extensions"`
+	// TextExtensions are parsed with text/template (default .txt, .tmpl)
+	TextExtensions []string `yaml:"text-extensions"`
+	// Reload parses the templates again on every render (default true in the dev profile)
+	Reload *bool `yaml:"reload"`
+}
+
+func (c Config) withDefaults() Config {
+	if c.Directory == "" {
+		c.Directory = "templates"
+	}
+	if c.Layouts == "" {
+		c.Layouts = "layouts"
+	}
+	if len(c.HTMLExtensions) == 0 {
+		c.HTMLExtensions = []string{".html", ".gohtml"}
+	}
+	if len(c.TextExtensions) == 0 {
+		c.TextExtensions = []string{".txt", ".tmpl"}
+	}
+	return c
+}
+
+// TemplateFuncProvider is implemented by components contributing functions to all
+// templates
+type TemplateFuncProvider interface {
+	TemplateFuncs() map[string]any
+}
+
+// executor is a parsed html or text template
+type executor interface {
+	Execute(w io.Writer, data any) error
+}
+
+// TemplateRenderer renders the templates of a directory by their path relative to
+// it, e.g. orders/list.html. The templates of the layouts subdirectory are parsed
+// with every template of the same kind, so pages can {{template "base" .}} a
+// shared layout.
+type TemplateRenderer struct {
+	fsys   fs.FS
+	config Config
+	reload bool
+	logger *slog.Logger
+
+	mu        sync.RWMutex
+	funcs     map[string]any
+	templates map[string]executor
+}
+
+// NewTemplateRenderer creates a renderer reading the templates from fsys, e.g. an
+// embed.FS; with a nil fsys they are read from the working directory
+func NewTemplateRenderer(fsys fs.FS, config Config) *TemplateRenderer {
+	config = config.withDefaults()
+	return &TemplateRenderer{
+		fsys:   fsys,
+		config: config,
+		reload: config.Reload != nil && *config.Reload,
+		logger: slog.Default(),
+	}
+}
+
+// Name returns the component name
+func (r *TemplateRenderer) Name() string {
+	return "templateRenderer"
+}
+
+// Init collects the functions of TemplateFuncProvider components and parses the templates
+func (r *TemplateRenderer) Init(ctx container.ApplicationContext) error {
+	if r.config.Reload == nil {
+		r.reload = container.ProfileCondition("dev")(ctx)
+	}
+
+	names := ctx.GetComponentNames()
+	if finder, ok := ctx.(container.ComponentFinder); ok {
+		names = names[:0]
+		for _, info := range finder.FindComponents(func(info container.ComponentInfo) bool {
+			_, ok := info.Component.(TemplateFuncProvider)
+			return ok
+		}) {
+			names = append(names, info.Name)
+		}
+	}
+	sort.Strings(names)
+
+	funcs := make(map[string]any)
+	for _, name := range names {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if provider, ok := comp.(TemplateFuncProvider); ok {
+			for key, fn := range provider.TemplateFuncs() {
+				funcs[key] = fn
+			}
+		}
+	}
+
+	r.mu.Lock()
+	r.funcs = funcs
+	r.mu.Unlock()
+	return r.Reload()
+}
+
+// Reload parses all templates again
+func (r *TemplateRenderer) Reload() error {
+	templates, err := r.parse()
+	if err != nil {
+		return err
+	}
+	r.mu.Lock()
+	r.templates = templates
+	r.mu.Unlock()
+	return nil
+}
+
+// Names returns the names of the templates, sorted
+func (r *TemplateRenderer) Names() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	names := make([]string, 0, len(r.templates))
+	for name := range r.templates {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+// Render executes the named template with data
+func (r *TemplateRenderer) Render(w io.Writer, name string, data any) error {
+	if r.reload {
+		if err := r.Reload(); err != nil {
+			return err
+		}
+	}
+
+	r.mu.RLock()
+	tmpl, ok := r.templates[name]
+	r.mu.RUnlock()
+	if !ok {
+		return container.ErrorWithCode("TEMPLATE_NOT_FOUND", "template %s not found", name)
+	}
+	return tmpl.Execute(w, data)
+}
+
+// RenderString executes the named template and returns its output
+func (r *TemplateRenderer) RenderString(name string, data any) (string, error) {
+	var buf bytes.Buffer
+	if err := r.Render(&buf, name, data); err != nil {
+		return "", err
+	}
+	return buf.String(), nil
+}
+
+// HTML writes the named template as response. The template is rendered before
+// anything is written, so a failure results in a 500 response.
+func (r *TemplateRenderer) HTML(w http.ResponseWriter, status int, name string, data any) {
+	var buf bytes.Buffer
+	if err := r.Render(&buf, name, data); err != nil {
+		r.logger.Error("Template rendering failed", "template", name, "error", err)
+		http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+		return
+	}
+	w.Header().Set("Content-Type", "text/html; charset=utf-8")
+	w.WriteHeader(status)
+	_, _ = buf.WriteTo(w)
+}
+
+// Handler returns a handler rendering the named template with the data returned
+// by model, to be used as the Handler of a web.Route
+func (r *TemplateRenderer) Handler(name string, model func(*http.Request) (any, error)) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		var data any
+		if model != nil {
+			var err error
+			if data, err = model(req); err != nil {
+				r.logger.Error("Template model failed", "template", name, "error", err)
+				http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+				return
+			}
+		}
+		r.HTML(w, http.StatusOK, name, data)
+	})
+}
+
+// parse parses every template of the directory together with the layouts
+func (r *TemplateRenderer) parse() (map[string]executor, error) {
+	fsys := r.fsys
+	if fsys == nil {
+		fsys = os.DirFS(".")
+	}
+	root, err := fs.Sub(fsys, r.config.Directory)
+	if err != nil {
+		return nil, err
+	}
+
+	var pages, layouts []string
+	err = fs.WalkDir(root, ".", func(name string, entry fs.DirEntry, err error) error {
+		if err != nil || entry.IsDir() || r.kind(name) == "" {
+			return err
+		}
+		if strings.HasPrefix(name, r.config.Layouts+"/") {
+			layouts = append(layouts, name)
+		} else {
+			pages = append(pages, name)
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, container.ErrorWithCode("TEMPLATE_LOAD", "read templates in %s: %v", r.config.Directory, err)
+	}
+
+	r.mu.RLock()
+	funcs := r.funcs
+	r.mu.RUnlock()
+
+	templates := make(map[string]executor, len(pages))
+	for _, page := range pages {
+		// Layouts are parsed first so that the page overrides their blocks
+		var files []string
+		for _, layout := range layouts {
+			if r.kind(layout) == r.kind(page) {
+				files = append(files, layout)
+			}
+		}
+		files = append(files, page)
+		var tmpl executor
+		var err error
+		if r.kind(page) == "html" {
+			tmpl, err = htmltemplate.New(path.Base(page)).Funcs(funcs).ParseFS(root, files...)
+		} else {
+			tmpl, err = texttemplate.New(path.Base(page)).Funcs(funcs).ParseFS(root, files...)
+		}
+		if err != nil {
+			return nil, container.ErrorWithCode("TEMPLATE_PARSE", "parse template %s: %v", page, err)
+		}
+		templates[page] = tmpl
+	}
+	return templates, nil
+}
+
+// kind returns html or text for the template files, empty for other files
+func (r *TemplateRenderer) kind(name string) string {
+	ext := path.Ext(name)
+	for _, candidate := range r.config.HTMLExtensions {
+		if ext == candidate {
+			return "html"
+		}
+	}
+	for _, candidate := range r.config.TextExtensions {
+		if ext == candidate {
+			return "text"
+		}
+	}
+	return ""
+}
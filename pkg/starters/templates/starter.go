@@ -0,0 +1,33 @@
+package templates
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyTemplates holds the renderer configuration: templates.*
+const PropertyTemplates = "templates"
+
+// Starter registers a TemplateRenderer reading templates.directory when
+// templates.enabled is true. In the dev profile the templates are parsed again on
+// every render, so edits show up without a restart. Handlers render HTML responses
+// with it:
+//
+//	web.Route{Method: "GET", Path: "/orders", Handler: renderer.Handler("orders/list.html", h.listOrders)}
+//
+// To embed the templates in the binary, register NewTemplateRenderer with an
+// embed.FS instead.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"TemplatesStarter",
+		container.PropertyCondition(PropertyTemplates+".enabled", "true"),
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertyTemplates, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyTemplates, err)
+			}
+			return builder.RegisterComponent(NewTemplateRenderer(nil, config))
+		},
+	)
+}
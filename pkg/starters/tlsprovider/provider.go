@@ -0,0 +1,223 @@
+// Package tlsprovider manages server certificates and hands out *tls.Config values
+// whose certificates are reloaded without restarting listeners
+package tlsprovider
+
+import (
+	"bytes"
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"log/slog"
+	"os"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Certificate sources
+const (
+	SourceFile = "file"
+	SourcePEM  = "pem"
+	SourceACME = "acme"
+)
+
+// CertificateGetter provides certificates per TLS handshake. *autocert.Manager from
+// golang.org/x/crypto/acme/autocert implements it; register one as an instance to use ACME.
+type CertificateGetter interface {
+	GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error)
+}
+
+// Config configures the TLS provider
+type Config struct {
+	// Source is file (default), pem or acme
+	Source string `yaml:"source"`
+	// CertFile and KeyFile are PEM files for the file source
+	CertFile string `yaml:"cert-file"`
+	KeyFile  string `yaml:"key-file"`
+	// CertPEM and KeyPEM are PEM contents for the pem source
+	CertPEM string `yaml:"cert-pem"`
+	KeyPEM  string `yaml:"key-pem"`
+	// ClientCAFile enables mutual TLS with the given CA bundle
+	ClientCAFile string `yaml:"client-ca-file"`
+	// MinVersion is "1.2" (default) or "1.3"
+	MinVersion string `yaml:"min-version"`
+	// ReloadInterval is how often certificate files are checked for renewal
+	ReloadInterval time.Duration `yaml:"reload-interval"`
+}
+
+// TLSProvider loads the server certificate and keeps it fresh. Servers obtain a
+// *tls.Config from TLSConfig; renewed certificates are picked up on the next handshake.
+type TLSProvider struct {
+	config Config
+	acme   CertificateGetter
+	logger *slog.Logger
+
+	mu       sync.RWMutex
+	cert     *tls.Certificate
+	certMod  time.Time
+	keyMod   time.Time
+	clientCA *x509.CertPool
+	stop     context.CancelFunc
+}
+
+// New creates a TLS provider; acme is only required for the acme source
+func New(config Config, acme CertificateGetter) *TLSProvider {
+	if config.Source == "" {
+		config.Source = SourceFile
+	}
+	if config.ReloadInterval <= 0 {
+		config.ReloadInterval = time.Minute
+	}
+	return &TLSProvider{config: config, acme: acme, logger: slog.Default()}
+}
+
+// Name returns the component name
+func (p *TLSProvider) Name() string {
+	return "tlsProvider"
+}
+
+// Init loads the initial certificate
+func (p *TLSProvider) Init(container.ApplicationContext) error {
+	if p.config.ClientCAFile != "" {
+		pem, err := os.ReadFile(p.config.ClientCAFile)
+		if err != nil {
+			return fmt.Errorf("read client CA file: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(pem) {
+			return fmt.Errorf("no certificates found in %s", p.config.ClientCAFile)
+		}
+		p.clientCA = pool
+	}
+
+	switch p.config.Source {
+	case SourceFile:
+		_, err := p.reloadFiles()
+		return err
+	case SourcePEM:
+		cert, err := tls.X509KeyPair([]byte(p.config.CertPEM), []byte(p.config.KeyPEM))
+		if err != nil {
+			return fmt.Errorf("parse PEM certificate: %w", err)
+		}
+		p.setCertificate(&cert)
+		return nil
+	case SourceACME:
+		if p.acme == nil {
+			return fmt.Errorf("acme source requires a CertificateGetter component")
+		}
+		return nil
+	default:
+		return fmt.Errorf("unknown certificate source %q", p.config.Source)
+	}
+}
+
+// Start is a no-op; file watching happens in Run
+func (p *TLSProvider) Start(context.Context) {}
+
+// Stop is a no-op; Run returns when the container context is cancelled
+func (p *TLSProvider) Stop(context.Context) {}
+
+// Run watches certificate files for renewal
+func (p *TLSProvider) Run(ctx context.Context) {
+	if p.config.Source != SourceFile {
+		return
+	}
+
+	ticker := time.NewTicker(p.config.ReloadInterval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := p.reloadFiles()
+			if err != nil {
+				p.logger.Error("Failed to reload certificate, keeping the current one", "error", err)
+			} else if reloaded {
+				p.logger.Info("Certificate reloaded", "cert_file", p.config.CertFile)
+			}
+		}
+	}
+}
+
+// TLSConfig returns a server TLS configuration that always presents the current certificate
+func (p *TLSProvider) TLSConfig() *tls.Config {
+	config := &tls.Config{
+		MinVersion:     tls.VersionTLS12,
+		GetCertificate: p.GetCertificate,
+	}
+	if p.config.MinVersion == "1.3" {
+		config.MinVersion = tls.VersionTLS13
+	}
+	if p.clientCA != nil {
+		config.ClientCAs = p.clientCA
+		config.ClientAuth = tls.RequireAndVerifyClientCert
+	}
+	return config
+}
+
+// GetCertificate returns the current certificate for a handshake
+func (p *TLSProvider) GetCertificate(hello *tls.ClientHelloInfo) (*tls.Certificate, error) {
+	if p.config.Source == SourceACME {
+		return p.acme.GetCertificate(hello)
+	}
+
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.cert == nil {
+		return nil, fmt.Errorf("no certificate loaded")
+	}
+	return p.cert, nil
+}
+
+// Certificate returns the currently loaded certificate (nil for ACME)
+func (p *TLSProvider) Certificate() *tls.Certificate {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.cert
+}
+
+// reloadFiles loads the certificate files if they changed since the last load
+func (p *TLSProvider) reloadFiles() (bool, error) {
+	certInfo, err := os.Stat(p.config.CertFile)
+	if err != nil {
+		return false, fmt.Errorf("stat certificate file: %w", err)
+	}
+	keyInfo, err := os.Stat(p.config.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("stat key file: %w", err)
+	}
+
+	p.mu.RLock()
+	unchanged := p.cert != nil && certInfo.ModTime().Equal(p.certMod) && keyInfo.ModTime().Equal(p.keyMod)
+	p.mu.RUnlock()
+	if unchanged {
+		return false, nil
+	}
+
+	cert, err := tls.LoadX509KeyPair(p.config.CertFile, p.config.KeyFile)
+	if err != nil {
+		return false, fmt.Errorf("load certificate: %w", err)
+	}
+
+	p.mu.Lock()
+	changed := p.cert == nil || !bytes.Equal(p.cert.Certificate[0], cert.Certificate[0])
+	p.cert = &cert
+	p.certMod = certInfo.ModTime()
+	p.keyMod = keyInfo.ModTime()
+	p.mu.Unlock()
+
+	return changed, nil
+}
+
+func (p *TLSProvider) setCertificate(cert *tls.Certificate) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.cert = cert
+}
+
+// Ensure that TLSProvider implements container.BackgroundComponent
+var _ container.BackgroundComponent = (*TLSProvider)(nil)
@@ -0,0 +1,36 @@
+package tlsprovider
+
+import (
+	"fmt"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyTLS holds the provider configuration: server.tls.*
+const PropertyTLS = "server.tls"
+
+// Starter registers a TLSProvider component when server.tls is configured.
+// HTTP and gRPC servers look it up by type and use TLSConfig for their listeners.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"TLSProviderStarter",
+		func(ctx container.ApplicationContext) bool {
+			return container.NewVariableHelper(ctx).HasSection(PropertyTLS)
+		},
+		func(builder container.ContextBuilder) error {
+			var config Config
+			if err := builder.GetVariableAs(PropertyTLS, &config); err != nil {
+				return fmt.Errorf("invalid %s configuration: %w", PropertyTLS, err)
+			}
+
+			var acme CertificateGetter
+			if config.Source == SourceACME {
+				if err := builder.GetComponent(&acme); err != nil {
+					return fmt.Errorf("acme certificate source: %w", err)
+				}
+			}
+
+			return builder.RegisterComponent(New(config, acme))
+		},
+	)
+}
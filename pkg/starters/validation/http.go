@@ -0,0 +1,80 @@
+package validation
+
+import (
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+)
+
+// MaxBodySize limits the request bodies decoded by DecodeJSON
+const MaxBodySize = 1 << 20
+
+// BindError is a request body that can't be decoded
+type BindError struct {
+	Err error
+}
+
+// Error describes the decoding failure
+func (e *BindError) Error() string {
+	return "invalid request body: " + e.Err.Error()
+}
+
+// Unwrap returns the decoding error
+func (e *BindError) Unwrap() error {
+	return e.Err
+}
+
+// DecodeJSON decodes the JSON body of a request into target, rejecting unknown
+// fields, and validates it. It returns a *BindError for malformed bodies and
+// ValidationErrors for invalid values; WriteError turns both into responses:
+//
+//	var req CreateUser
+//	if err := h.validator.DecodeJSON(r, &req); err != nil {
+//		validation.WriteError(w, err)
+//		return
+//	}
+func (v *Validator) DecodeJSON(r *http.Request, target any) error {
+	decoder := json.NewDecoder(io.LimitReader(r.Body, MaxBodySize))
+	decoder.DisallowUnknownFields()
+	if err := decoder.Decode(target); err != nil {
+		if errors.Is(err, io.EOF) {
+			err = errors.New("empty body")
+		}
+		return &BindError{Err: err}
+	}
+	if decoder.More() {
+		return &BindError{Err: errors.New("unexpected data after the JSON value")}
+	}
+	return v.Struct(target)
+}
+
+// errorResponse is the body of a 400 response
+type errorResponse struct {
+	Error      string           `json:"error"`
+	Message    string           `json:"message"`
+	Violations ValidationErrors `json:"violations,omitempty"`
+}
+
+// WriteError responds to a DecodeJSON error with 400 and a JSON body listing the
+// violations; other errors get a 500
+func WriteError(w http.ResponseWriter, err error) {
+	status := http.StatusBadRequest
+	response := errorResponse{Error: http.StatusText(status), Message: err.Error()}
+
+	var violations ValidationErrors
+	var bindError *BindError
+	switch {
+	case errors.As(err, &violations):
+		response.Message = "validation failed"
+		response.Violations = violations
+	case errors.As(err, &bindError):
+	default:
+		status = http.StatusInternalServerError
+		response = errorResponse{Error: http.StatusText(status), Message: http.StatusText(status)}
+	}
+
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(status)
+	_ = json.NewEncoder(w).Encode(response)
+}
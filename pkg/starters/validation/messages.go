@@ -0,0 +1,55 @@
+package validation
+
+import (
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// messages describe the common rules; %s is the parameter
+var messages = map[string]string{
+	"required": "is required",
+	"min":      "must be at least %s",
+	"max":      "must be at most %s",
+	"len":      "must have a length of %s",
+	"eq":       "must be %s",
+	"ne":       "must not be %s",
+	"gt":       "must be greater than %s",
+	"gte":      "must be at least %s",
+	"lt":       "must be less than %s",
+	"lte":      "must be at most %s",
+	"oneof":    "must be one of [%s]",
+	"email":    "must be an email address",
+	"url":      "must be a URL",
+	"uri":      "must be a URI",
+	"uuid":     "must be a UUID",
+	"ip":       "must be an IP address",
+	"hostname": "must be a host name",
+	"alpha":    "must contain letters only",
+	"alphanum": "must contain letters and digits only",
+	"numeric":  "must be numeric",
+	"datetime": "must be a date in the format %s",
+}
+
+// message describes a failed rule
+func message(rule, param string, kind reflect.Kind) string {
+	format, ok := messages[rule]
+	if !ok {
+		return "failed the " + rule + " rule"
+	}
+	if !strings.Contains(format, "%s") {
+		return format
+	}
+	text := fmt.Sprintf(format, param)
+	// Sizes of strings and collections are lengths
+	switch rule {
+	case "min", "max", "gt", "gte", "lt", "lte":
+		switch kind {
+		case reflect.String:
+			text += " characters"
+		case reflect.Slice, reflect.Map, reflect.Array:
+			text += " items"
+		}
+	}
+	return text
+}
@@ -0,0 +1,27 @@
+package validation
+
+import (
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyEnabled registers the shared Validator (default true)
+const PropertyEnabled = "validation.enabled"
+
+// Starter registers the shared Validator unless validation.enabled is false or a
+// component named validator already exists. Being the container's StructValidator,
+// it validates the structs bound with container.RegisterConfigProperties; handlers
+// inject it to validate request bodies with DecodeJSON.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"ValidationStarter",
+		func(ctx container.ApplicationContext) bool {
+			return container.NewVariableHelper(ctx).GetBool(PropertyEnabled, true)
+		},
+		func(builder container.ContextBuilder) error {
+			if builder.HasComponent(container.StructValidatorName) {
+				return nil
+			}
+			return builder.RegisterComponent(NewValidator())
+		},
+	)
+}
@@ -0,0 +1,147 @@
+// Package validation validates structs with the validate tags of
+// go-playground/validator:
+//
+//	type CreateUser struct {
+//		Email string   `json:"email" validate:"required,email"`
+//		Age   int      `json:"age" validate:"omitempty,gte=18"`
+//		Roles []string `json:"roles" validate:"max=5,dive,oneof=admin user"`
+//	}
+package validation
+
+import (
+	"errors"
+	"reflect"
+	"strings"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/go-playground/validator/v10"
+)
+
+// FieldError is a field failing a rule
+type FieldError struct {
+	// Field is the path of the field by its JSON (or YAML) names, e.g. items[0].name
+	Field   string `json:"field"`
+	Rule    string `json:"rule"`
+	Param   string `json:"param,omitempty"`
+	Message string `json:"message"`
+}
+
+// Error returns the field and the message
+func (e FieldError) Error() string {
+	if e.Field == "" {
+		return e.Message
+	}
+	return e.Field + " " + e.Message
+}
+
+// ValidationErrors are all the field errors of a value
+type ValidationErrors []FieldError
+
+// Error joins the field errors
+func (e ValidationErrors) Error() string {
+	parts := make([]string, len(e))
+	for i, fieldError := range e {
+		parts[i] = fieldError.Error()
+	}
+	return strings.Join(parts, "; ")
+}
+
+// Rule checks a field against the parameter of its tag, e.g. 3 in min=3
+type Rule func(value reflect.Value, param string) bool
+
+// Validator validates structs with their validate tags. It is the container's
+// StructValidator, so bound configuration structs are validated too.
+type Validator struct {
+	validate *validator.Validate
+}
+
+// NewValidator creates a validator naming fields by their JSON or YAML names
+func NewValidator() *Validator {
+	validate := validator.New(validator.WithRequiredStructEnabled())
+	validate.RegisterTagNameFunc(fieldName)
+	return &Validator{validate: validate}
+}
+
+// Name returns the component name
+func (v *Validator) Name() string {
+	return container.StructValidatorName
+}
+
+// Init is a no-op
+func (v *Validator) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Engine returns the underlying go-playground validator, e.g. to register struct
+// level validations or aliases
+func (v *Validator) Engine() *validator.Validate {
+	return v.validate
+}
+
+// RegisterRule adds a rule usable in validate tags, or replaces a builtin one
+func (v *Validator) RegisterRule(name string, rule Rule) error {
+	return v.validate.RegisterValidation(name, func(field validator.FieldLevel) bool {
+		return rule(field.Field(), field.Param())
+	})
+}
+
+// Struct validates a struct or a pointer to one, including nested structs, and
+// returns ValidationErrors listing every failed field
+func (v *Validator) Struct(value any) error {
+	return translate(v.validate.Struct(value))
+}
+
+// ValidateStruct implements container.StructValidator
+func (v *Validator) ValidateStruct(value any) error {
+	return v.Struct(value)
+}
+
+// Var validates a single value with a tag, e.g. v.Var(email, "required,email")
+func (v *Validator) Var(value any, tag string) error {
+	return translate(v.validate.Var(value, tag))
+}
+
+// translate turns the errors of go-playground/validator into ValidationErrors
+func translate(err error) error {
+	var fieldErrors validator.ValidationErrors
+	if !errors.As(err, &fieldErrors) {
+		return err
+	}
+
+	result := make(ValidationErrors, len(fieldErrors))
+	for i, fieldError := range fieldErrors {
+		result[i] = FieldError{
+			Field:   fieldPath(fieldError.Namespace()),
+			Rule:    fieldError.Tag(),
+			Param:   fieldError.Param(),
+			Message: message(fieldError.Tag(), fieldError.Param(), fieldError.Kind()),
+		}
+	}
+	return result
+}
+
+// fieldPath drops the struct type from a namespace, e.g. CreateUser.items[0].name
+func fieldPath(namespace string) string {
+	if _, path, found := strings.Cut(namespace, "."); found {
+		return path
+	}
+	// A Var error has no namespace
+	return ""
+}
+
+// fieldName returns the JSON name of a field, else its YAML name, else its Go name
+func fieldName(field reflect.StructField) string {
+	for _, key := range []string{"json", "yaml"} {
+		name, _, _ := strings.Cut(field.Tag.Get(key), ",")
+		if name == "-" {
+			return "-"
+		}
+		if name != "" {
+			return name
+		}
+	}
+	return field.Name
+}
+
+// Ensure that Validator implements container.StructValidator
+var _ container.StructValidator = (*Validator)(nil)
@@ -0,0 +1,144 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyCORS holds the CORS policy: server.cors.*
+const PropertyCORS = "server.cors"
+
+// CORSConfig is a CORS policy, applied when server.cors.enabled is true
+type CORSConfig struct {
+	// AllowedOrigins are origins allowed to call the server; * allows any origin and
+	// a *. subdomain wildcard allows its subdomains, e.g. https://*.example.com
+	AllowedOrigins []string `yaml:"allowed-origins"`
+	// AllowedMethods are the methods allowed in preflights (default GET, HEAD, POST)
+	AllowedMethods []string `yaml:"allowed-methods"`
+	// AllowedHeaders are the request headers allowed in preflights; * allows the
+	// requested ones (default *)
+	AllowedHeaders []string `yaml:"allowed-headers"`
+	// ExposedHeaders are response headers readable by scripts
+	ExposedHeaders []string `yaml:"exposed-headers"`
+	// AllowCredentials allows cookies and authorization headers; the origin is then
+	// echoed instead of *
+	AllowCredentials bool `yaml:"allow-credentials"`
+	// MaxAge is how long browsers cache preflight responses (default 10m)
+	MaxAge time.Duration `yaml:"max-age"`
+}
+
+func (c CORSConfig) withDefaults() CORSConfig {
+	if len(c.AllowedMethods) == 0 {
+		c.AllowedMethods = []string{http.MethodGet, http.MethodHead, http.MethodPost}
+	}
+	if len(c.AllowedHeaders) == 0 {
+		c.AllowedHeaders = []string{"*"}
+	}
+	if c.MaxAge <= 0 {
+		c.MaxAge = 10 * time.Minute
+	}
+	return c
+}
+
+// CORS applies a CORS policy to every response and answers preflight requests
+type CORS struct {
+	config CORSConfig
+}
+
+// NewCORS creates the CORS middleware
+func NewCORS(config CORSConfig) *CORS {
+	return &CORS{config: config.withDefaults()}
+}
+
+// Name returns the component name
+func (c *CORS) Name() string {
+	return "cors"
+}
+
+// Init is a no-op
+func (c *CORS) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Order returns OrderCORS
+func (c *CORS) Order() int {
+	return OrderCORS
+}
+
+// Wrap adds the CORS headers of allowed origins. Preflight requests are answered
+// with 204 without calling next, so they don't need to be authenticated.
+func (c *CORS) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		origin := r.Header.Get("Origin")
+		header := w.Header()
+		header.Add("Vary", "Origin")
+		if origin == "" || !c.allowed(origin) {
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		if c.config.AllowCredentials || !c.allowsAny() {
+			header.Set("Access-Control-Allow-Origin", origin)
+		} else {
+			header.Set("Access-Control-Allow-Origin", "*")
+		}
+		if c.config.AllowCredentials {
+			header.Set("Access-Control-Allow-Credentials", "true")
+		}
+
+		requestedMethod := r.Header.Get("Access-Control-Request-Method")
+		if r.Method != http.MethodOptions || requestedMethod == "" {
+			if len(c.config.ExposedHeaders) > 0 {
+				header.Set("Access-Control-Expose-Headers", strings.Join(c.config.ExposedHeaders, ", "))
+			}
+			next.ServeHTTP(w, r)
+			return
+		}
+
+		header.Add("Vary", "Access-Control-Request-Method")
+		header.Add("Vary", "Access-Control-Request-Headers")
+		header.Set("Access-Control-Allow-Methods", strings.Join(c.config.AllowedMethods, ", "))
+		allowedHeaders := strings.Join(c.config.AllowedHeaders, ", ")
+		if allowedHeaders == "*" {
+			allowedHeaders = r.Header.Get("Access-Control-Request-Headers")
+		}
+		if allowedHeaders != "" {
+			header.Set("Access-Control-Allow-Headers", allowedHeaders)
+		}
+		header.Set("Access-Control-Max-Age", strconv.Itoa(int(c.config.MaxAge.Seconds())))
+		w.WriteHeader(http.StatusNoContent)
+	})
+}
+
+// allowed reports whether the policy allows the origin
+func (c *CORS) allowed(origin string) bool {
+	for _, allowed := range c.config.AllowedOrigins {
+		if allowed == "*" || strings.EqualFold(allowed, origin) {
+			return true
+		}
+		if scheme, domain, ok := strings.Cut(allowed, "://*."); ok {
+			if rest, found := strings.CutPrefix(strings.ToLower(origin), strings.ToLower(scheme)+"://"); found &&
+				strings.HasSuffix(rest, "."+strings.ToLower(domain)) {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// allowsAny reports whether the policy allows any origin
+func (c *CORS) allowsAny() bool {
+	for _, allowed := range c.config.AllowedOrigins {
+		if allowed == "*" {
+			return true
+		}
+	}
+	return false
+}
+
+// Ensure that CORS implements Middleware
+var _ Middleware = (*CORS)(nil)
@@ -0,0 +1,36 @@
+// Package web runs an HTTP server that serves the routes declared by HTTPHandler components
+package web
+
+import (
+	"net/http"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Route describes a single endpoint of an HTTPHandler
+type Route struct {
+	// Method is the HTTP method (GET, POST, ...)
+	Method string
+	// Path is an http.ServeMux pattern; a trailing slash matches the whole subtree.
+	// Path parameters are documented with braces, e.g. /users/{id}
+	Path string
+	// Handler serves the route
+	Handler http.Handler
+	// Summary, Description and Tags document the route in the OpenAPI document
+	Summary     string
+	Description string
+	Tags        []string
+	// Request and Response are example values whose types describe the JSON
+	// request and response bodies (nil if the route has no body)
+	Request  any
+	Response any
+	// Status is the documented success status code (200 if unset)
+	Status int
+}
+
+// HTTPHandler is a component that contributes routes to the web server
+type HTTPHandler interface {
+	container.Component
+	// Routes returns the routes served by this component
+	Routes() []Route
+}
@@ -0,0 +1,146 @@
+package web
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// PropertyHeaders holds the security headers: server.headers.*
+const PropertyHeaders = "server.headers"
+
+// HeadersConfig configures the security headers added to every response. An empty
+// value leaves a header out; server.headers.enabled=false disables all of them.
+type HeadersConfig struct {
+	// HSTSMaxAge is the Strict-Transport-Security max-age sent over TLS (default 1
+	// year); a negative value leaves the header out
+	HSTSMaxAge time.Duration `yaml:"hsts-max-age"`
+	// HSTSIncludeSubdomains adds includeSubDomains to Strict-Transport-Security
+	HSTSIncludeSubdomains bool `yaml:"hsts-include-subdomains"`
+	// FrameOptions is the X-Frame-Options header (default DENY)
+	FrameOptions string `yaml:"frame-options"`
+	// ContentTypeOptions is the X-Content-Type-Options header (default nosniff)
+	ContentTypeOptions string `yaml:"content-type-options"`
+	// ReferrerPolicy is the Referrer-Policy header (default strict-origin-when-cross-origin)
+	ReferrerPolicy string `yaml:"referrer-policy"`
+	// ContentSecurityPolicy is the Content-Security-Policy header (default none)
+	ContentSecurityPolicy string `yaml:"content-security-policy"`
+	// Custom are further headers by name
+	Custom map[string]string `yaml:"custom"`
+}
+
+func (c HeadersConfig) withDefaults() HeadersConfig {
+	if c.HSTSMaxAge == 0 {
+		c.HSTSMaxAge = 365 * 24 * time.Hour
+	}
+	if c.FrameOptions == "" {
+		c.FrameOptions = "DENY"
+	}
+	if c.ContentTypeOptions == "" {
+		c.ContentTypeOptions = "nosniff"
+	}
+	if c.ReferrerPolicy == "" {
+		c.ReferrerPolicy = "strict-origin-when-cross-origin"
+	}
+	return c
+}
+
+// SecurityHeaders adds HSTS, frame options, CSP and the other configured headers
+// to every response, unless the handler set them
+type SecurityHeaders struct {
+	headers map[string]string
+	hsts    string
+}
+
+// NewSecurityHeaders creates the security headers middleware
+func NewSecurityHeaders(config HeadersConfig) *SecurityHeaders {
+	config = config.withDefaults()
+	headers := map[string]string{
+		"X-Frame-Options":         config.FrameOptions,
+		"X-Content-Type-Options":  config.ContentTypeOptions,
+		"Referrer-Policy":         config.ReferrerPolicy,
+		"Content-Security-Policy": config.ContentSecurityPolicy,
+	}
+	for name, value := range config.Custom {
+		headers[http.CanonicalHeaderKey(name)] = value
+	}
+	for name, value := range headers {
+		if value == "" {
+			delete(headers, name)
+		}
+	}
+
+	var hsts string
+	if config.HSTSMaxAge > 0 {
+		hsts = "max-age=" + strconv.Itoa(int(config.HSTSMaxAge.Seconds()))
+		if config.HSTSIncludeSubdomains {
+			hsts += "; includeSubDomains"
+		}
+	}
+	return &SecurityHeaders{headers: headers, hsts: hsts}
+}
+
+// Name returns the component name
+func (h *SecurityHeaders) Name() string {
+	return "securityHeaders"
+}
+
+// Init is a no-op
+func (h *SecurityHeaders) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Order returns OrderHeaders
+func (h *SecurityHeaders) Order() int {
+	return OrderHeaders
+}
+
+// Wrap sets the headers before calling next. Strict-Transport-Security is only
+// sent over TLS, as browsers ignore it otherwise.
+func (h *SecurityHeaders) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		header := w.Header()
+		for name, value := range h.headers {
+			header.Set(name, value)
+		}
+		if h.hsts != "" && (r.TLS != nil || r.Header.Get("X-Forwarded-Proto") == "https") {
+			header.Set("Strict-Transport-Security", h.hsts)
+		}
+		next.ServeHTTP(w, r)
+	})
+}
+
+// registerHeaders registers the CORS middleware when server.cors.enabled is true
+// and the security headers unless server.headers.enabled is false, each unless a
+// component of the same name replaces it
+func registerHeaders(builder container.ContextBuilder) error {
+	vars := container.NewVariableHelper(builder)
+
+	if vars.GetBool(PropertyCORS+".enabled", false) && !builder.HasComponent("cors") {
+		var config CORSConfig
+		if err := builder.GetVariableAs(PropertyCORS, &config); err != nil {
+			return container.ConfigurationError("cannot bind "+PropertyCORS, err)
+		}
+		if err := builder.RegisterComponent(NewCORS(config)); err != nil {
+			return err
+		}
+	}
+
+	if vars.GetBool(PropertyHeaders+".enabled", true) && !builder.HasComponent("securityHeaders") {
+		var config HeadersConfig
+		if vars.HasSection(PropertyHeaders) {
+			if err := builder.GetVariableAs(PropertyHeaders, &config); err != nil {
+				return container.ConfigurationError("cannot bind "+PropertyHeaders, err)
+			}
+		}
+		if err := builder.RegisterComponent(NewSecurityHeaders(config)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ensure that SecurityHeaders implements Middleware
+var _ Middleware = (*SecurityHeaders)(nil)
@@ -0,0 +1,67 @@
+package web
+
+import (
+	"encoding/json"
+	"net/http"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Management endpoint properties: server.management.*
+const (
+	// PropertyComponentsEnabled serves the component report (default false)
+	PropertyComponentsEnabled = "server.management.components.enabled"
+	// PropertyComponentsPath is the component report path (default /components)
+	PropertyComponentsPath = "server.management.components.path"
+)
+
+// componentJSON is the JSON form of a container.ComponentReport
+type componentJSON struct {
+	Name            string                   `json:"name"`
+	Type            string                   `json:"type"`
+	State           container.ComponentState `json:"state"`
+	Dependencies    []string                 `json:"dependencies"`
+	Dependents      []string                 `json:"dependents"`
+	InitDurationMs  float64                  `json:"initDurationMs"`
+	StartDurationMs float64                  `json:"startDurationMs"`
+	Background      bool                     `json:"background"`
+	Scheduled       bool                     `json:"scheduled"`
+}
+
+// componentsHandler serves the live component report of the container
+func componentsHandler(inspector container.ContainerInspector) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		reports := inspector.GetComponentReport()
+		components := make([]componentJSON, len(reports))
+		for i, report := range reports {
+			components[i] = componentJSON{
+				Name:            report.Name,
+				Type:            report.Type,
+				State:           report.State,
+				Dependencies:    emptyIfNil(report.Dependencies),
+				Dependents:      emptyIfNil(report.Dependents),
+				InitDurationMs:  milliseconds(report.InitDuration),
+				StartDurationMs: milliseconds(report.StartDuration),
+				Background:      report.Background,
+				Scheduled:       report.Scheduled,
+			}
+		}
+
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]any{"components": components})
+	})
+}
+
+func milliseconds(d time.Duration) float64 {
+	return float64(d) / float64(time.Millisecond)
+}
+
+func emptyIfNil(values []string) []string {
+	if values == nil {
+		return []string{}
+	}
+	return values
+}
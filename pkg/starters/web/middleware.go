@@ -0,0 +1,110 @@
+package web
+
+import (
+	"net/http"
+	"sort"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Orders of the default middlewares; lower orders wrap higher ones
+const (
+	OrderAccessLog = 100
+	OrderMetrics   = 200
+	OrderHeaders   = 210
+	OrderCORS      = 220
+	OrderSession   = 230
+	OrderSecurity  = 250
+	OrderRecovery  = 300
+)
+
+// Middleware is a component wrapping the handler of every request. The server
+// applies all Middleware components by Order, the lowest being the outermost.
+type Middleware interface {
+	container.Component
+	// Order positions the middleware; ties are broken by component name
+	Order() int
+	// Wrap returns a handler calling next
+	Wrap(next http.Handler) http.Handler
+}
+
+// applyMiddlewares wraps handler with the registered Middleware components
+func applyMiddlewares(ctx container.ApplicationContext, handler http.Handler) http.Handler {
+	names := ctx.GetComponentNames()
+	if finder, ok := ctx.(container.ComponentFinder); ok {
+		names = names[:0]
+		for _, info := range finder.FindComponents(func(info container.ComponentInfo) bool {
+			_, ok := info.Component.(Middleware)
+			return ok
+		}) {
+			names = append(names, info.Name)
+		}
+	}
+	sort.Strings(names)
+
+	var middlewares []Middleware
+	for _, name := range names {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if middleware, ok := comp.(Middleware); ok {
+			middlewares = append(middlewares, middleware)
+		}
+	}
+	sort.SliceStable(middlewares, func(i, j int) bool {
+		return middlewares[i].Order() < middlewares[j].Order()
+	})
+
+	for i := len(middlewares) - 1; i >= 0; i-- {
+		handler = middlewares[i].Wrap(handler)
+	}
+	return handler
+}
+
+// responseRecorder records the status and size of a response
+type responseRecorder struct {
+	http.ResponseWriter
+	status int
+	bytes  int64
+}
+
+func newResponseRecorder(w http.ResponseWriter) *responseRecorder {
+	return &responseRecorder{ResponseWriter: w}
+}
+
+func (w *responseRecorder) WriteHeader(status int) {
+	if w.status == 0 {
+		w.status = status
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *responseRecorder) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.status = http.StatusOK
+	}
+	n, err := w.ResponseWriter.Write(data)
+	w.bytes += int64(n)
+	return n, err
+}
+
+// Flush supports streaming responses
+func (w *responseRecorder) Flush() {
+	if flusher, ok := w.ResponseWriter.(http.Flusher); ok {
+		flusher.Flush()
+	}
+}
+
+// Unwrap lets http.ResponseController reach the underlying writer
+func (w *responseRecorder) Unwrap() http.ResponseWriter {
+	return w.ResponseWriter
+}
+
+// Status returns the response status, 200 if nothing was written
+func (w *responseRecorder) Status() int {
+	if w.status == 0 {
+		return http.StatusOK
+	}
+	return w.status
+}
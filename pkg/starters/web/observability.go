@@ -0,0 +1,320 @@
+package web
+
+import (
+	"crypto/rand"
+	"encoding/hex"
+	"encoding/json"
+	"log/slog"
+	"net/http"
+	"runtime/debug"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Observability properties: server.observability.*
+const (
+	// PropertyAccessLogEnabled logs every request (default true)
+	PropertyAccessLogEnabled = "server.observability.access-log.enabled"
+	// PropertyAccessLogExclude lists path prefixes that aren't logged, e.g. /health
+	PropertyAccessLogExclude = "server.observability.access-log.exclude"
+	// PropertyRecoveryEnabled turns handler panics into 500 responses (default true)
+	PropertyRecoveryEnabled = "server.observability.recovery.enabled"
+	// PropertyMetricsEnabled records request metrics by route (default true)
+	PropertyMetricsEnabled = "server.observability.metrics.enabled"
+	// PropertyMetricsPath serves the request metrics as JSON (default not served)
+	PropertyMetricsPath = "server.observability.metrics.path"
+)
+
+// unmatchedRoute labels the metrics of requests no route served, e.g. 404s and static assets
+const unmatchedRoute = "unmatched"
+
+// AccessLog logs every request with its route, status, size and duration. Records
+// are logged with the request context, so a propagation.NewLogHandler adds the
+// correlation ID.
+type AccessLog struct {
+	exclude []string
+	logger  *slog.Logger
+}
+
+// NewAccessLog creates the access log middleware; requests whose path starts with
+// one of exclude aren't logged
+func NewAccessLog(exclude []string) *AccessLog {
+	return &AccessLog{exclude: exclude, logger: slog.Default()}
+}
+
+// Name returns the component name
+func (l *AccessLog) Name() string {
+	return "accessLog"
+}
+
+// Init is a no-op
+func (l *AccessLog) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Order returns OrderAccessLog
+func (l *AccessLog) Order() int {
+	return OrderAccessLog
+}
+
+// Wrap logs the requests served by next; server errors are logged at Error level
+// and client errors at Warn level
+func (l *AccessLog) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		for _, prefix := range l.exclude {
+			if strings.HasPrefix(r.URL.Path, prefix) {
+				next.ServeHTTP(w, r)
+				return
+			}
+		}
+
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+		next.ServeHTTP(recorder, r)
+
+		level := slog.LevelInfo
+		switch status := recorder.Status(); {
+		case status >= 500:
+			level = slog.LevelError
+		case status >= 400:
+			level = slog.LevelWarn
+		}
+		l.logger.LogAttrs(r.Context(), level, "HTTP request",
+			slog.String("method", r.Method),
+			slog.String("path", r.URL.Path),
+			slog.String("route", MatchedRoute(r)),
+			slog.Int("status", recorder.Status()),
+			slog.Int64("bytes", recorder.bytes),
+			slog.Int64("time_ms", time.Since(start).Milliseconds()),
+			slog.String("remote", r.RemoteAddr))
+	})
+}
+
+// Recovery turns panics of handlers into 500 responses carrying an error ID, which
+// is logged with the panic and its stack so that reports can be matched to logs
+type Recovery struct {
+	logger *slog.Logger
+}
+
+// NewRecovery creates the recovery middleware
+func NewRecovery() *Recovery {
+	return &Recovery{logger: slog.Default()}
+}
+
+// Name returns the component name
+func (m *Recovery) Name() string {
+	return "recovery"
+}
+
+// Init is a no-op
+func (m *Recovery) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Order returns OrderRecovery
+func (m *Recovery) Order() int {
+	return OrderRecovery
+}
+
+// Wrap recovers the panics of next. http.ErrAbortHandler is passed on, so that the
+// server aborts the response.
+func (m *Recovery) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		recorder := newResponseRecorder(w)
+		defer func() {
+			recovered := recover()
+			if recovered == nil {
+				return
+			}
+			if recovered == http.ErrAbortHandler {
+				panic(recovered)
+			}
+
+			errorID := newErrorID()
+			m.logger.ErrorContext(r.Context(), "Panic in HTTP handler",
+				"error_id", errorID,
+				"method", r.Method,
+				"path", r.URL.Path,
+				"error", recovered,
+				"stack", string(debug.Stack()))
+
+			// The status can't be changed once the handler wrote the response
+			if recorder.status != 0 {
+				return
+			}
+			w.Header().Set("Content-Type", "application/json")
+			w.WriteHeader(http.StatusInternalServerError)
+			_ = json.NewEncoder(w).Encode(map[string]string{
+				"error":   http.StatusText(http.StatusInternalServerError),
+				"errorId": errorID,
+			})
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+// newErrorID returns a random ID of 16 hex characters
+func newErrorID() string {
+	var id [8]byte
+	_, _ = rand.Read(id[:])
+	return hex.EncodeToString(id[:])
+}
+
+// RouteMetrics are the request metrics of one route
+type RouteMetrics struct {
+	Method string `json:"method"`
+	// Route is the path of the route, or unmatched for requests no route served
+	Route    string `json:"route"`
+	Requests int64  `json:"requests"`
+	// ClientErrors and ServerErrors count 4xx and 5xx responses
+	ClientErrors  int64         `json:"clientErrors"`
+	ServerErrors  int64         `json:"serverErrors"`
+	TotalDuration time.Duration `json:"totalDurationNs"`
+	MaxDuration   time.Duration `json:"maxDurationNs"`
+	RequestBytes  int64         `json:"requestBytes"`
+	ResponseBytes int64         `json:"responseBytes"`
+}
+
+// HTTPMetrics records request counts, durations and sizes by route
+type HTTPMetrics struct {
+	mu     sync.Mutex
+	routes map[string]*RouteMetrics
+}
+
+// NewHTTPMetrics creates the metrics middleware
+func NewHTTPMetrics() *HTTPMetrics {
+	return &HTTPMetrics{routes: make(map[string]*RouteMetrics)}
+}
+
+// Name returns the component name
+func (m *HTTPMetrics) Name() string {
+	return "httpMetrics"
+}
+
+// Init is a no-op
+func (m *HTTPMetrics) Init(container.ApplicationContext) error {
+	return nil
+}
+
+// Order returns OrderMetrics
+func (m *HTTPMetrics) Order() int {
+	return OrderMetrics
+}
+
+// Wrap records the requests served by next
+func (m *HTTPMetrics) Wrap(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		start := time.Now()
+		recorder := newResponseRecorder(w)
+		defer func() {
+			// A panic passing through is a server error
+			status := recorder.Status()
+			if recovered := recover(); recovered != nil {
+				defer panic(recovered)
+				status = http.StatusInternalServerError
+			}
+			m.record(r, status, recorder.bytes, time.Since(start))
+		}()
+		next.ServeHTTP(recorder, r)
+	})
+}
+
+func (m *HTTPMetrics) record(r *http.Request, status int, responseBytes int64, duration time.Duration) {
+	route := MatchedRoute(r)
+	if route == "" {
+		route = unmatchedRoute
+	}
+	key := r.Method + " " + route
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	metrics, ok := m.routes[key]
+	if !ok {
+		metrics = &RouteMetrics{Method: r.Method, Route: route}
+		m.routes[key] = metrics
+	}
+	metrics.Requests++
+	switch {
+	case status >= 500:
+		metrics.ServerErrors++
+	case status >= 400:
+		metrics.ClientErrors++
+	}
+	metrics.TotalDuration += duration
+	if duration > metrics.MaxDuration {
+		metrics.MaxDuration = duration
+	}
+	if r.ContentLength > 0 {
+		metrics.RequestBytes += r.ContentLength
+	}
+	metrics.ResponseBytes += responseBytes
+}
+
+// Snapshot returns the metrics of all routes sorted by route and method
+func (m *HTTPMetrics) Snapshot() []RouteMetrics {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	result := make([]RouteMetrics, 0, len(m.routes))
+	for _, metrics := range m.routes {
+		result = append(result, *metrics)
+	}
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Route != result[j].Route {
+			return result[i].Route < result[j].Route
+		}
+		return result[i].Method < result[j].Method
+	})
+	return result
+}
+
+// Handler serves the snapshot as JSON
+func (m *HTTPMetrics) Handler() http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(map[string]any{"routes": m.Snapshot()})
+	})
+}
+
+// registerObservability registers the default middlewares that are enabled and
+// not replaced by a component of the same name
+func registerObservability(builder container.ContextBuilder) error {
+	vars := container.NewVariableHelper(builder)
+
+	if vars.GetBool(PropertyAccessLogEnabled, true) && !builder.HasComponent("accessLog") {
+		var exclude []string
+		if builder.HasVariable(PropertyAccessLogExclude) {
+			if err := builder.GetVariableAs(PropertyAccessLogExclude, &exclude); err != nil {
+				return err
+			}
+		}
+		if err := builder.RegisterComponent(NewAccessLog(exclude)); err != nil {
+			return err
+		}
+	}
+	if vars.GetBool(PropertyMetricsEnabled, true) && !builder.HasComponent("httpMetrics") {
+		if err := builder.RegisterComponent(NewHTTPMetrics()); err != nil {
+			return err
+		}
+	}
+	if vars.GetBool(PropertyRecoveryEnabled, true) && !builder.HasComponent("recovery") {
+		if err := builder.RegisterComponent(NewRecovery()); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Ensure that the default middlewares implement Middleware
+var (
+	_ Middleware = (*AccessLog)(nil)
+	_ Middleware = (*Recovery)(nil)
+	_ Middleware = (*HTTPMetrics)(nil)
+)
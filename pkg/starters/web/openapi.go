@@ -0,0 +1,242 @@
+package web
+
+import (
+	"encoding/json"
+	"html"
+	"net/http"
+	"reflect"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// OpenAPI properties: server.openapi.*
+const (
+	// PropertyOpenAPIEnabled serves the generated OpenAPI 3 document (default false)
+	PropertyOpenAPIEnabled = "server.openapi.enabled"
+	// PropertyOpenAPIPath is the document path (default /openapi.json)
+	PropertyOpenAPIPath = "server.openapi.path"
+	// PropertyOpenAPITitle and PropertyOpenAPIVersion fill the document info
+	PropertyOpenAPITitle   = "server.openapi.title"
+	PropertyOpenAPIVersion = "server.openapi.version"
+	// PropertySwaggerUIEnabled serves a Swagger UI page for the document (default false)
+	PropertySwaggerUIEnabled = "server.openapi.swagger-ui.enabled"
+	// PropertySwaggerUIPath is the Swagger UI path (default /swagger-ui)
+	PropertySwaggerUIPath = "server.openapi.swagger-ui.path"
+)
+
+// Schema is a JSON schema as used by OpenAPI 3
+type Schema struct {
+	Ref                  string             `json:"$ref,omitempty"`
+	Type                 string             `json:"type,omitempty"`
+	Format               string             `json:"format,omitempty"`
+	Items                *Schema            `json:"items,omitempty"`
+	Properties           map[string]*Schema `json:"properties,omitempty"`
+	AdditionalProperties *Schema            `json:"additionalProperties,omitempty"`
+	Required             []string           `json:"required,omitempty"`
+	Nullable             bool               `json:"nullable,omitempty"`
+}
+
+// OpenAPI builds an OpenAPI 3 document from routes
+func OpenAPI(title, version string, routes []Route) map[string]any {
+	gen := &schemaGenerator{schemas: make(map[string]*Schema)}
+	paths := make(map[string]map[string]any)
+
+	for _, route := range routes {
+		operation := map[string]any{}
+		if route.Summary != "" {
+			operation["summary"] = route.Summary
+		}
+		if route.Description != "" {
+			operation["description"] = route.Description
+		}
+		if len(route.Tags) > 0 {
+			operation["tags"] = route.Tags
+		}
+		if params := pathParameters(route.Path); len(params) > 0 {
+			operation["parameters"] = params
+		}
+		if route.Request != nil {
+			operation["requestBody"] = map[string]any{
+				"required": true,
+				"content":  jsonContent(gen.schema(reflect.TypeOf(route.Request))),
+			}
+		}
+
+		status := route.Status
+		if status == 0 {
+			status = http.StatusOK
+		}
+		response := map[string]any{"description": http.StatusText(status)}
+		if route.Response != nil {
+			response["content"] = jsonContent(gen.schema(reflect.TypeOf(route.Response)))
+		}
+		operation["responses"] = map[string]any{strconv.Itoa(status): response}
+
+		if paths[route.Path] == nil {
+			paths[route.Path] = make(map[string]any)
+		}
+		paths[route.Path][strings.ToLower(route.Method)] = operation
+	}
+
+	doc := map[string]any{
+		"openapi": "3.0.3",
+		"info":    map[string]any{"title": title, "version": version},
+		"paths":   paths,
+	}
+	if len(gen.schemas) > 0 {
+		doc["components"] = map[string]any{"schemas": gen.schemas}
+	}
+	return doc
+}
+
+func jsonContent(schema *Schema) map[string]any {
+	return map[string]any{"application/json": map[string]any{"schema": schema}}
+}
+
+var pathParameterPattern = regexp.MustCompile(`\{([^}]+)\}`)
+
+func pathParameters(path string) []map[string]any {
+	var params []map[string]any
+	for _, match := range pathParameterPattern.FindAllStringSubmatch(path, -1) {
+		params = append(params, map[string]any{
+			"name":     match[1],
+			"in":       "path",
+			"required": true,
+			"schema":   &Schema{Type: "string"},
+		})
+	}
+	return params
+}
+
+// schemaGenerator derives schemas from Go types; named structs become
+// reusable components referenced with $ref so that recursive types terminate
+type schemaGenerator struct {
+	schemas map[string]*Schema
+}
+
+var timeType = reflect.TypeOf(time.Time{})
+
+func (g *schemaGenerator) schema(t reflect.Type) *Schema {
+	if t.Kind() == reflect.Pointer {
+		s := g.schema(t.Elem())
+		if s.Ref == "" {
+			s.Nullable = true
+		}
+		return s
+	}
+	if t == timeType {
+		return &Schema{Type: "string", Format: "date-time"}
+	}
+
+	switch t.Kind() {
+	case reflect.Bool:
+		return &Schema{Type: "boolean"}
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32:
+		return &Schema{Type: "integer", Format: "int32"}
+	case reflect.Int64, reflect.Uint64:
+		return &Schema{Type: "integer", Format: "int64"}
+	case reflect.Float32:
+		return &Schema{Type: "number", Format: "float"}
+	case reflect.Float64:
+		return &Schema{Type: "number", Format: "double"}
+	case reflect.String:
+		return &Schema{Type: "string"}
+	case reflect.Slice, reflect.Array:
+		if t.Elem().Kind() == reflect.Uint8 {
+			return &Schema{Type: "string", Format: "byte"}
+		}
+		return &Schema{Type: "array", Items: g.schema(t.Elem())}
+	case reflect.Map:
+		return &Schema{Type: "object", AdditionalProperties: g.schema(t.Elem())}
+	case reflect.Struct:
+		if t.Name() == "" {
+			return g.structSchema(t)
+		}
+		name := t.Name()
+		if _, ok := g.schemas[name]; !ok {
+			// Reserve the name before descending into fields to stop recursion
+			g.schemas[name] = &Schema{}
+			*g.schemas[name] = *g.structSchema(t)
+		}
+		return &Schema{Ref: "#/components/schemas/" + name}
+	default:
+		return &Schema{}
+	}
+}
+
+// structSchema follows encoding/json field naming rules
+func (g *schemaGenerator) structSchema(t reflect.Type) *Schema {
+	s := &Schema{Type: "object", Properties: make(map[string]*Schema)}
+
+	for i := 0; i < t.NumField(); i++ {
+		field := t.Field(i)
+		if !field.IsExported() {
+			continue
+		}
+
+		name, opts, _ := strings.Cut(field.Tag.Get("json"), ",")
+		if name == "-" && opts == "" {
+			continue
+		}
+
+		// Embedded structs without a name are flattened, as encoding/json does
+		if field.Anonymous && name == "" {
+			embedded := field.Type
+			if embedded.Kind() == reflect.Pointer {
+				embedded = embedded.Elem()
+			}
+			if embedded.Kind() == reflect.Struct {
+				inner := g.structSchema(embedded)
+				for k, v := range inner.Properties {
+					s.Properties[k] = v
+				}
+				s.Required = append(s.Required, inner.Required...)
+				continue
+			}
+		}
+
+		if name == "" {
+			name = field.Name
+		}
+		s.Properties[name] = g.schema(field.Type)
+		if !strings.Contains(opts, "omitempty") && field.Type.Kind() != reflect.Pointer {
+			s.Required = append(s.Required, name)
+		}
+	}
+
+	return s
+}
+
+// openAPIHandler serves the document generated from the router's current routes
+func openAPIHandler(router *Router, title, version string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "application/json")
+		enc := json.NewEncoder(w)
+		enc.SetIndent("", "  ")
+		_ = enc.Encode(OpenAPI(title, version, router.Routes()))
+	})
+}
+
+// swaggerUIHandler serves a Swagger UI page loading its assets from a CDN
+func swaggerUIHandler(title, specPath string) http.Handler {
+	page := `<!DOCTYPE html>
+<html>
+<head>
+  <meta charset="utf-8">
+  <title>` + html.EscapeString(title) + `</title>
+  <link rel="stylesheet" href="https://unpkg.com/swagger-ui-dist@5/swagger-ui.css">
+</head>
+<body>
+  <div id="swagger-ui"></div>
+  <script src="https://unpkg.com/swagger-ui-dist@5/swagger-ui-bundle.js"></script>
+  <script>window.ui = SwaggerUIBundle({url: ` + strconv.Quote(specPath) + `, dom_id: "#swagger-ui"});</script>
+</body>
+</html>
+`
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		w.Header().Set("Content-Type", "text/html; charset=utf-8")
+		_, _ = w.Write([]byte(page))
+	})
+}
@@ -0,0 +1,127 @@
+package web
+
+import (
+	"context"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+)
+
+// Router dispatches requests by path and method
+type Router struct {
+	mu      sync.RWMutex
+	mux     *http.ServeMux
+	methods map[string]map[string]Route
+	routes  []Route
+}
+
+// NewRouter creates an empty router
+func NewRouter() *Router {
+	return &Router{
+		mux:     http.NewServeMux(),
+		methods: make(map[string]map[string]Route),
+	}
+}
+
+// Handle registers a route; registering the same method and path twice replaces the handler
+func (r *Router) Handle(route Route) {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	path := muxPattern(route.Path)
+	method := strings.ToUpper(route.Method)
+
+	route.Method = method
+	routes, ok := r.methods[path]
+	if !ok {
+		routes = make(map[string]Route)
+		r.methods[path] = routes
+		r.mux.Handle(path, r.dispatcher(path))
+	}
+	routes[method] = route
+	r.routes = append(r.routes, route)
+}
+
+// Routes returns all registered routes sorted by path and method
+func (r *Router) Routes() []Route {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	routes := make([]Route, len(r.routes))
+	copy(routes, r.routes)
+	sort.SliceStable(routes, func(i, j int) bool {
+		if routes[i].Path != routes[j].Path {
+			return routes[i].Path < routes[j].Path
+		}
+		return routes[i].Method < routes[j].Method
+	})
+	return routes
+}
+
+// ServeHTTP implements http.Handler
+func (r *Router) ServeHTTP(w http.ResponseWriter, req *http.Request) {
+	r.mux.ServeHTTP(w, req)
+}
+
+// dispatcher selects the handler of a path by request method
+func (r *Router) dispatcher(path string) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, req *http.Request) {
+		r.mu.RLock()
+		routes := r.methods[path]
+		route, ok := routes[req.Method]
+		if !ok && req.Method == http.MethodHead {
+			route, ok = routes[http.MethodGet]
+		}
+		allowed := make([]string, 0, len(routes))
+		for method := range routes {
+			allowed = append(allowed, method)
+		}
+		r.mu.RUnlock()
+
+		if !ok {
+			sort.Strings(allowed)
+			w.Header().Set("Allow", strings.Join(allowed, ", "))
+			http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+			return
+		}
+		if match, ok := req.Context().Value(routeMatchKey{}).(*routeMatch); ok {
+			match.path = route.Path
+		}
+		route.Handler.ServeHTTP(w, req)
+	})
+}
+
+// routeMatchKey is the context key of the routeMatch of a request
+type routeMatchKey struct{}
+
+// routeMatch receives the path of the route serving a request
+type routeMatch struct {
+	path string
+}
+
+// trackRoutes lets middlewares find the route that served a request
+func trackRoutes(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		next.ServeHTTP(w, r.WithContext(context.WithValue(r.Context(), routeMatchKey{}, &routeMatch{})))
+	})
+}
+
+// MatchedRoute returns the path of the route that served the request, e.g.
+// /users/{id}, or an empty string if no route matched. Middlewares call it after
+// the request was served.
+func MatchedRoute(r *http.Request) string {
+	if match, ok := r.Context().Value(routeMatchKey{}).(*routeMatch); ok {
+		return match.path
+	}
+	return ""
+}
+
+// muxPattern strips documented path parameters, which http.ServeMux doesn't
+// support: /users/{id} is served by the /users/ subtree
+func muxPattern(path string) string {
+	if i := strings.Index(path, "{"); i >= 0 {
+		return path[:strings.LastIndex(path[:i], "/")+1]
+	}
+	return path
+}
@@ -0,0 +1,238 @@
+package web
+
+import (
+	"context"
+	"crypto/tls"
+	"errors"
+	"fmt"
+	"log/slog"
+	"net"
+	"net/http"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+	"github.com/01fortes/goboot/pkg/starters/propagation"
+	"github.com/01fortes/goboot/pkg/starters/tlsprovider"
+)
+
+// Server properties: server.*
+const (
+	// PropertyEnabled disables the server when false (default true)
+	PropertyEnabled = "server.enabled"
+	// PropertyAddress is the listen host (default all interfaces)
+	PropertyAddress = "server.address"
+	// PropertyPort is the listen port (default 8080, 0 picks a free port)
+	PropertyPort = "server.port"
+	// PropertyReadHeaderTimeout bounds reading request headers (default 10s)
+	PropertyReadHeaderTimeout = "server.read-header-timeout"
+)
+
+// Server is the HTTP server component. During Init it collects the routes of
+// every HTTPHandler component; when a TLSProvider is registered it serves HTTPS.
+// When a propagation.Propagator is registered, request contexts carry the
+// correlation ID, tenant ID and baggage of the request headers. Middleware
+// components wrap every request.
+type Server struct {
+	router *Router
+	logger *slog.Logger
+
+	mu       sync.Mutex
+	server   *http.Server
+	listener net.Listener
+}
+
+// NewServer creates a server with an empty router
+func NewServer() *Server {
+	return &Server{router: NewRouter(), logger: slog.Default()}
+}
+
+// Name returns the component name
+func (s *Server) Name() string {
+	return "webServer"
+}
+
+// Router returns the router serving all routes
+func (s *Server) Router() *Router {
+	return s.router
+}
+
+// Init collects routes and configures the server
+func (s *Server) Init(ctx container.ApplicationContext) error {
+	vars := container.NewVariableHelper(ctx)
+
+	// Init also runs during dependency discovery, so start from a fresh router
+	s.router = NewRouter()
+	for _, handler := range findHandlers(ctx) {
+		for _, route := range handler.Routes() {
+			s.router.Handle(route)
+		}
+	}
+
+	if vars.GetBool(PropertyOpenAPIEnabled, false) {
+		title := vars.GetString(PropertyOpenAPITitle, "API")
+		version := vars.GetString(PropertyOpenAPIVersion, "1.0.0")
+		specPath := vars.GetString(PropertyOpenAPIPath, "/openapi.json")
+
+		// Documentation endpoints are served directly so they don't document themselves
+		s.router.mux.Handle(specPath, openAPIHandler(s.router, title, version))
+		if vars.GetBool(PropertySwaggerUIEnabled, false) {
+			s.router.mux.Handle(vars.GetString(PropertySwaggerUIPath, "/swagger-ui"), swaggerUIHandler(title, specPath))
+		}
+	}
+
+	if vars.GetBool(PropertyComponentsEnabled, false) {
+		if inspector, ok := ctx.(container.ContainerInspector); ok {
+			s.router.mux.Handle(vars.GetString(PropertyComponentsPath, "/components"), componentsHandler(inspector))
+		}
+	}
+
+	if vars.GetBool(PropertyStaticEnabled, false) {
+		if err := s.mountStatic(ctx); err != nil {
+			return err
+		}
+	}
+
+	readHeaderTimeout := 10 * time.Second
+	if ctx.HasVariable(PropertyReadHeaderTimeout) {
+		if err := ctx.GetVariableAs(PropertyReadHeaderTimeout, &readHeaderTimeout); err != nil {
+			return fmt.Errorf("invalid %s: %w", PropertyReadHeaderTimeout, err)
+		}
+	}
+
+	if path := vars.GetString(PropertyMetricsPath, ""); path != "" && ctx.HasComponent("httpMetrics") {
+		metrics, err := container.GetComponentAs[*HTTPMetrics](ctx, "httpMetrics")
+		if err != nil {
+			return err
+		}
+		s.router.mux.Handle(path, metrics.Handler())
+	}
+
+	// Propagation runs first so that the middlewares log with the correlation ID
+	handler := applyMiddlewares(ctx, s.scopeHandler(ctx, s.router))
+	if ctx.HasComponent("propagator") {
+		comp, err := ctx.GetComponentByName("propagator")
+		if err != nil {
+			return err
+		}
+		if propagator, ok := comp.(*propagation.Propagator); ok {
+			handler = propagator.Middleware(handler)
+		}
+	}
+
+	server := &http.Server{
+		Addr:              net.JoinHostPort(vars.GetString(PropertyAddress, ""), vars.GetString(PropertyPort, "8080")),
+		Handler:           trackRoutes(handler),
+		ReadHeaderTimeout: readHeaderTimeout,
+	}
+
+	if ctx.HasComponent("tlsProvider") {
+		comp, err := ctx.GetComponentByName("tlsProvider")
+		if err != nil {
+			return err
+		}
+		if provider, ok := comp.(*tlsprovider.TLSProvider); ok {
+			server.TLSConfig = provider.TLSConfig()
+		}
+	}
+
+	s.mu.Lock()
+	s.server = server
+	s.mu.Unlock()
+	return nil
+}
+
+// Start binds the listener and serves requests in the background
+func (s *Server) Start(context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	listener, err := net.Listen("tcp", s.server.Addr)
+	if err != nil {
+		// Start can't return an error; a panic is reported by the lifecycle manager
+		panic(fmt.Errorf("listen on %s: %w", s.server.Addr, err))
+	}
+	if s.server.TLSConfig != nil {
+		listener = tls.NewListener(listener, s.server.TLSConfig)
+	}
+	s.listener = listener
+
+	s.logger.Info("Web server listening", "address", listener.Addr().String(), "tls", s.server.TLSConfig != nil)
+
+	server := s.server
+	go func() {
+		if err := server.Serve(listener); err != nil && !errors.Is(err, http.ErrServerClosed) {
+			s.logger.Error("Web server failed", "error", err)
+		}
+	}()
+}
+
+// Stop gracefully shuts the server down
+func (s *Server) Stop(ctx context.Context) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return
+	}
+	if err := s.server.Shutdown(ctx); err != nil {
+		s.logger.Error("Web server shutdown failed", "error", err)
+	}
+	s.listener = nil
+}
+
+// Addr returns the bound address, or nil if the server isn't running
+func (s *Server) Addr() net.Addr {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if s.listener == nil {
+		return nil
+	}
+	return s.listener.Addr()
+}
+
+// findHandlers returns registered HTTPHandler components sorted by name
+func findHandlers(ctx container.ApplicationContext) []HTTPHandler {
+	names := ctx.GetComponentNames()
+	if finder, ok := ctx.(container.ComponentFinder); ok {
+		names = names[:0]
+		for _, info := range finder.FindComponents(func(info container.ComponentInfo) bool {
+			_, ok := info.Component.(HTTPHandler)
+			return ok
+		}) {
+			names = append(names, info.Name)
+		}
+	}
+	sort.Strings(names)
+
+	var result []HTTPHandler
+	for _, name := range names {
+		comp, err := ctx.GetComponentByName(name)
+		if err != nil {
+			continue
+		}
+		if handler, ok := comp.(HTTPHandler); ok {
+			result = append(result, handler)
+		}
+	}
+	return result
+}
+
+// Ensure that Server implements container.LifecycleComponent
+var _ container.LifecycleComponent = (*Server)(nil)
+
+// scopeHandler opens a container scope for every request, so handlers can get
+// request-scoped components with container.GetScoped(r.Context(), name)
+func (s *Server) scopeHandler(app container.ApplicationContext, next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		scope := container.NewScope(r.Context(), app)
+		defer func() {
+			if err := scope.Close(); err != nil {
+				s.logger.Error("Failed to close request scope", "path", r.URL.Path, "error", err)
+			}
+		}()
+		next.ServeHTTP(w, r.WithContext(scope.Context()))
+	})
+}
@@ -0,0 +1,36 @@
+package web
+
+import (
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Starter registers the web server unless server.enabled is false. Routes come
+// from HTTPHandler components; set server.openapi.enabled to serve an OpenAPI 3
+// document describing them and server.openapi.swagger-ui.enabled for a Swagger UI.
+// server.management.components.enabled serves a JSON report of all components with
+// their states, dependencies, dependents and timings. server.static.enabled serves
+// the assets of a directory or an embed.FS, with an optional SPA fallback.
+//
+// The starter also registers the AccessLog, HTTPMetrics and Recovery middlewares
+// unless disabled with server.observability.*.enabled; register a component of
+// the same name (accessLog, httpMetrics, recovery) to replace one. Responses get
+// the security headers of server.headers.* (HSTS, frame options, CSP, ...) unless
+// server.headers.enabled is false, and the CORS policy of server.cors.* when
+// server.cors.enabled is true; preflights are answered before authentication.
+func Starter() container.Starter {
+	return container.NewConditionalStarter(
+		"WebStarter",
+		func(ctx container.ApplicationContext) bool {
+			return container.NewVariableHelper(ctx).GetBool(PropertyEnabled, true)
+		},
+		func(builder container.ContextBuilder) error {
+			if err := registerObservability(builder); err != nil {
+				return err
+			}
+			if err := registerHeaders(builder); err != nil {
+				return err
+			}
+			return builder.RegisterComponent(NewServer())
+		},
+	)
+}
@@ -0,0 +1,252 @@
+package web
+
+import (
+	"bytes"
+	"compress/gzip"
+	"fmt"
+	"io"
+	"io/fs"
+	"mime"
+	"net/http"
+	"os"
+	"path"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/01fortes/goboot/pkg/container"
+)
+
+// Static asset properties: server.static.*
+const (
+	// PropertyStaticEnabled serves static assets (default false)
+	PropertyStaticEnabled = "server.static.enabled"
+	// PropertyStaticPath is the URL path the assets are served below (default /)
+	PropertyStaticPath = "server.static.path"
+	// PropertyStaticDirectory is the directory holding the assets (default static),
+	// unless a StaticFSName instance is registered
+	PropertyStaticDirectory = "server.static.directory"
+	// PropertyStaticCacheMaxAge is the Cache-Control max-age of assets (default 1h);
+	// index.html is always revalidated
+	PropertyStaticCacheMaxAge = "server.static.cache-max-age"
+	// PropertyStaticCompression gzips text assets for clients accepting it and serves
+	// precompressed .gz files (default true)
+	PropertyStaticCompression = "server.static.compression"
+	// PropertyStaticSPA serves index.html for unknown paths without file extension,
+	// so that a single-page application handles its own routes (default false)
+	PropertyStaticSPA = "server.static.spa"
+)
+
+// StaticFSName is the name of an fs.FS instance, e.g. an embed.FS, served instead
+// of server.static.directory:
+//
+//	//go:embed dist
+//	var dist embed.FS
+//
+//	assets, _ := fs.Sub(dist, "dist")
+//	builder.RegisterInstance(web.StaticFSName, assets)
+const StaticFSName = "staticFS"
+
+// staticIndex is served for directories and as SPA fallback
+const staticIndex = "index.html"
+
+// staticHandler serves the files of fsys below prefix
+type staticHandler struct {
+	fsys        fs.FS
+	prefix      string
+	maxAge      time.Duration
+	compression bool
+	spa         bool
+}
+
+func (h *staticHandler) ServeHTTP(w http.ResponseWriter, r *http.Request) {
+	if r.Method != http.MethodGet && r.Method != http.MethodHead {
+		w.Header().Set("Allow", "GET, HEAD")
+		http.Error(w, http.StatusText(http.StatusMethodNotAllowed), http.StatusMethodNotAllowed)
+		return
+	}
+
+	name := strings.TrimPrefix(path.Clean("/"+strings.TrimPrefix(r.URL.Path, h.prefix)), "/")
+	if name == "" {
+		name = staticIndex
+	}
+	file, info, err := h.open(name)
+	if err == nil && info.IsDir() {
+		file.Close()
+		name = path.Join(name, staticIndex)
+		file, info, err = h.open(name)
+	}
+	if err != nil && h.spa && path.Ext(name) == "" {
+		name = staticIndex
+		file, info, err = h.open(name)
+	}
+	if err != nil {
+		http.NotFound(w, r)
+		return
+	}
+	defer file.Close()
+
+	if path.Base(name) == staticIndex {
+		w.Header().Set("Cache-Control", "no-cache")
+	} else if h.maxAge > 0 {
+		w.Header().Set("Cache-Control", "public, max-age="+strconv.Itoa(int(h.maxAge.Seconds())))
+	}
+
+	contentType := mime.TypeByExtension(path.Ext(name))
+	if contentType != "" {
+		w.Header().Set("Content-Type", contentType)
+	}
+
+	if h.compression && acceptsGzip(r) {
+		w.Header().Add("Vary", "Accept-Encoding")
+		if gz, gzInfo, err := h.open(name + ".gz"); err == nil {
+			defer gz.Close()
+			w.Header().Set("Content-Encoding", "gzip")
+			serveFile(w, r, gzInfo, gz)
+			return
+		}
+		if compressible(contentType) {
+			w.Header().Set("Content-Encoding", "gzip")
+			// Ranges of the uncompressed content can't be served compressed
+			r.Header.Del("Range")
+			gzw := &gzipResponseWriter{ResponseWriter: w}
+			defer gzw.Close()
+			serveFile(gzw, r, info, file)
+			return
+		}
+	}
+	serveFile(w, r, info, file)
+}
+
+// open opens a regular file or directory of the handler's file system
+func (h *staticHandler) open(name string) (fs.File, fs.FileInfo, error) {
+	if !fs.ValidPath(name) {
+		return nil, nil, fs.ErrNotExist
+	}
+	file, err := h.fsys.Open(name)
+	if err != nil {
+		return nil, nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, nil, err
+	}
+	return file, info, nil
+}
+
+// serveFile serves a file with conditional request and range support
+func serveFile(w http.ResponseWriter, r *http.Request, info fs.FileInfo, file fs.File) {
+	content, ok := file.(io.ReadSeeker)
+	if !ok {
+		data, err := io.ReadAll(file)
+		if err != nil {
+			http.Error(w, http.StatusText(http.StatusInternalServerError), http.StatusInternalServerError)
+			return
+		}
+		content = bytes.NewReader(data)
+	}
+	http.ServeContent(w, r, info.Name(), info.ModTime(), content)
+}
+
+// acceptsGzip reports whether the client accepts gzip encoded responses
+func acceptsGzip(r *http.Request) bool {
+	for _, encoding := range strings.Split(r.Header.Get("Accept-Encoding"), ",") {
+		name, params, _ := strings.Cut(strings.TrimSpace(encoding), ";")
+		if strings.EqualFold(name, "gzip") && strings.ReplaceAll(params, " ", "") != "q=0" {
+			return true
+		}
+	}
+	return false
+}
+
+// compressible reports whether content of a type benefits from compression
+func compressible(contentType string) bool {
+	mediaType, _, _ := strings.Cut(contentType, ";")
+	switch mediaType {
+	case "application/javascript", "text/javascript", "application/json", "application/xml",
+		"image/svg+xml", "application/wasm", "application/manifest+json":
+		return true
+	}
+	return strings.HasPrefix(mediaType, "text/")
+}
+
+// gzipResponseWriter compresses the body; the length of the compressed body is unknown
+type gzipResponseWriter struct {
+	http.ResponseWriter
+	writer *gzip.Writer
+	status int
+}
+
+func (w *gzipResponseWriter) WriteHeader(status int) {
+	w.status = status
+	w.Header().Del("Content-Length")
+	w.Header().Del("Accept-Ranges")
+	if status == http.StatusNotModified || status == http.StatusNoContent {
+		w.Header().Del("Content-Encoding")
+	}
+	w.ResponseWriter.WriteHeader(status)
+}
+
+func (w *gzipResponseWriter) Write(data []byte) (int, error) {
+	if w.status == 0 {
+		w.WriteHeader(http.StatusOK)
+	}
+	if w.writer == nil {
+		w.writer = gzip.NewWriter(w.ResponseWriter)
+	}
+	return w.writer.Write(data)
+}
+
+// Close flushes the compressed body
+func (w *gzipResponseWriter) Close() error {
+	if w.writer == nil {
+		return nil
+	}
+	return w.writer.Close()
+}
+
+// mountStatic serves the static assets below server.static.path
+func (s *Server) mountStatic(ctx container.ApplicationContext) error {
+	vars := container.NewVariableHelper(ctx)
+	prefix := vars.GetString(PropertyStaticPath, "/")
+	if !strings.HasSuffix(prefix, "/") {
+		prefix += "/"
+	}
+	if _, exists := s.router.methods[prefix]; exists {
+		return fmt.Errorf("%s %s conflicts with a route", PropertyStaticPath, prefix)
+	}
+
+	handler := &staticHandler{
+		prefix:      prefix,
+		maxAge:      time.Hour,
+		compression: vars.GetBool(PropertyStaticCompression, true),
+		spa:         vars.GetBool(PropertyStaticSPA, false),
+	}
+	if ctx.HasVariable(PropertyStaticCacheMaxAge) {
+		if err := ctx.GetVariableAs(PropertyStaticCacheMaxAge, &handler.maxAge); err != nil {
+			return fmt.Errorf("invalid %s: %w", PropertyStaticCacheMaxAge, err)
+		}
+	}
+
+	if ctx.HasComponent(StaticFSName) {
+		fsys, err := container.GetComponentAs[fs.FS](ctx, StaticFSName)
+		if err != nil {
+			return err
+		}
+		handler.fsys = fsys
+	} else {
+		directory := vars.GetString(PropertyStaticDirectory, "static")
+		info, err := os.Stat(directory)
+		if err != nil {
+			return fmt.Errorf("invalid %s: %w", PropertyStaticDirectory, err)
+		}
+		if !info.IsDir() {
+			return fmt.Errorf("invalid %s: %s is not a directory", PropertyStaticDirectory, directory)
+		}
+		handler.fsys = os.DirFS(directory)
+	}
+
+	s.router.mux.Handle(prefix, handler)
+	return nil
+}
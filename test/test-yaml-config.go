@@ -90,8 +90,8 @@ func main() {
 	if profilesEnv != "" {
 		fmt.Printf("Active profiles from environment: %s\n\n", profilesEnv)
 	} else {
-		fmt.Println("No active profiles set in environment. Using default configuration only.\n")
-		fmt.Println("To set profiles, use: export GO_BOOT_ACTIVE_PROFILES=dev,local\n")
+		fmt.Print("No active profiles set in environment. Using default configuration only.\n\n")
+		fmt.Print("To set profiles, use: export GO_BOOT_ACTIVE_PROFILES=dev,local\n\n")
 	}
 
 	// Create and start the application